@@ -79,7 +79,8 @@ if __name__ == '__main__':
     sentry_client = setup_sentry(env.sentry_credentials, env.version)
 
     AidServiceConnection = MessageClient(
-        env.aid_service_grpc_address, flags, load_state=True)
+        env.aid_service_grpc_address, flags, load_state=True,
+        authToken=env.aid_service_auth_token)
     CrsClient = CrsClient(env.crs_grpc_address)
 
     pipeline = Pipeline(env, AidServiceConnection, CrsClient)
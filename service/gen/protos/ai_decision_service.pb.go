@@ -1,27 +1,13 @@
 // Code generated by protoc-gen-go. DO NOT EDIT.
 // source: ai_decision_service.proto
 
-/*
-Package protos is a generated protocol buffer package.
-
-It is generated from these files:
-	ai_decision_service.proto
-
-It has these top-level messages:
-	Message
-	MessageCreateRequest
-	MessageListRequest
-	State
-	StateSaveRequest
-	StateGetRequest
-	StateListRequest
-*/
 package protos
 
 import proto "github.com/golang/protobuf/proto"
 import fmt "fmt"
 import math "math"
-import google_protobuf "github.com/golang/protobuf/ptypes/timestamp"
+import timestamp "github.com/golang/protobuf/ptypes/timestamp"
+import field_mask "google.golang.org/genproto/protobuf/field_mask"
 
 import (
 	context "golang.org/x/net/context"
@@ -39,19 +25,72 @@ var _ = math.Inf
 // proto package needs to be updated.
 const _ = proto.ProtoPackageIsVersion2 // please upgrade the proto package
 
+type RunStatus int32
+
+const (
+	RunStatus_RUN_STATUS_UNSPECIFIED RunStatus = 0
+	RunStatus_RUN_RUNNING            RunStatus = 1
+	RunStatus_RUN_SUCCEEDED          RunStatus = 2
+	RunStatus_RUN_FAILED             RunStatus = 3
+)
+
+var RunStatus_name = map[int32]string{
+	0: "RUN_STATUS_UNSPECIFIED",
+	1: "RUN_RUNNING",
+	2: "RUN_SUCCEEDED",
+	3: "RUN_FAILED",
+}
+var RunStatus_value = map[string]int32{
+	"RUN_STATUS_UNSPECIFIED": 0,
+	"RUN_RUNNING":            1,
+	"RUN_SUCCEEDED":          2,
+	"RUN_FAILED":             3,
+}
+
+func (x RunStatus) String() string {
+	return proto.EnumName(RunStatus_name, int32(x))
+}
+func (RunStatus) EnumDescriptor() ([]byte, []int) {
+	return fileDescriptor_ai_decision_service_e434f06e2ed91ff7, []int{0}
+}
+
 type Message struct {
-	Message        string                     `protobuf:"bytes,1,opt,name=message" json:"message,omitempty"`
-	AppId          int32                      `protobuf:"varint,2,opt,name=app_id,json=appId" json:"app_id,omitempty"`
-	Type           string                     `protobuf:"bytes,3,opt,name=type" json:"type,omitempty"`
-	Version        int32                      `protobuf:"varint,4,opt,name=version" json:"version,omitempty"`
-	Data           []byte                     `protobuf:"bytes,5,opt,name=data,proto3" json:"data,omitempty"`
-	GenerationTime *google_protobuf.Timestamp `protobuf:"bytes,6,opt,name=generation_time,json=generationTime" json:"generation_time,omitempty"`
+	Message        string               `protobuf:"bytes,1,opt,name=message,proto3" json:"message,omitempty"`
+	AppId          int32                `protobuf:"varint,2,opt,name=app_id,proto3" json:"app_id,omitempty"`
+	Type           string               `protobuf:"bytes,3,opt,name=type,proto3" json:"type,omitempty"`
+	Version        int32                `protobuf:"varint,4,opt,name=version,proto3" json:"version,omitempty"`
+	Data           []byte               `protobuf:"bytes,5,opt,name=data,proto3" json:"data,omitempty"`
+	GenerationTime *timestamp.Timestamp `protobuf:"bytes,6,opt,name=generation_time,proto3" json:"generation_time,omitempty"`
+	// id of the run that created the message, 0 if created outside a run
+	RunId                int32    `protobuf:"varint,7,opt,name=run_id,proto3" json:"run_id,omitempty"`
+	XXX_NoUnkeyedLiteral struct{} `json:"-"`
+	XXX_unrecognized     []byte   `json:"-"`
+	XXX_sizecache        int32    `json:"-"`
+}
+
+func (m *Message) Reset()         { *m = Message{} }
+func (m *Message) String() string { return proto.CompactTextString(m) }
+func (*Message) ProtoMessage()    {}
+func (*Message) Descriptor() ([]byte, []int) {
+	return fileDescriptor_ai_decision_service_e434f06e2ed91ff7, []int{0}
+}
+func (m *Message) XXX_Unmarshal(b []byte) error {
+	return xxx_messageInfo_Message.Unmarshal(m, b)
+}
+func (m *Message) XXX_Marshal(b []byte, deterministic bool) ([]byte, error) {
+	return xxx_messageInfo_Message.Marshal(b, m, deterministic)
+}
+func (dst *Message) XXX_Merge(src proto.Message) {
+	xxx_messageInfo_Message.Merge(dst, src)
+}
+func (m *Message) XXX_Size() int {
+	return xxx_messageInfo_Message.Size(m)
+}
+func (m *Message) XXX_DiscardUnknown() {
+	xxx_messageInfo_Message.DiscardUnknown(m)
 }
 
-func (m *Message) Reset()                    { *m = Message{} }
-func (m *Message) String() string            { return proto.CompactTextString(m) }
-func (*Message) ProtoMessage()               {}
-func (*Message) Descriptor() ([]byte, []int) { return fileDescriptor0, []int{0} }
+var xxx_messageInfo_Message proto.InternalMessageInfo
 
 func (m *Message) GetMessage() string {
 	if m != nil {
@@ -88,33 +127,64 @@ func (m *Message) GetData() []byte {
 	return nil
 }
 
-func (m *Message) GetGenerationTime() *google_protobuf.Timestamp {
+func (m *Message) GetGenerationTime() *timestamp.Timestamp {
 	if m != nil {
 		return m.GenerationTime
 	}
 	return nil
 }
 
+func (m *Message) GetRunId() int32 {
+	if m != nil {
+		return m.RunId
+	}
+	return 0
+}
+
 type MessageCreateRequest struct {
-	AppId int32 `protobuf:"varint,1,opt,name=app_id,json=appId" json:"app_id,omitempty"`
+	AppId int32 `protobuf:"varint,1,opt,name=app_id,proto3" json:"app_id,omitempty"`
 	// type + version together MUST uniquely identify a template. Furthermore, message data MUST
 	// be compatible with all previous versions of a template type.
-	Type    string `protobuf:"bytes,2,opt,name=type" json:"type,omitempty"`
-	Version int32  `protobuf:"varint,3,opt,name=version" json:"version,omitempty"`
+	Type    string `protobuf:"bytes,2,opt,name=type,proto3" json:"type,omitempty"`
+	Version int32  `protobuf:"varint,3,opt,name=version,proto3" json:"version,omitempty"`
 	// The decision not to use explicit messages was made to enable analytics to use the service in a data driven fashion
 	// with minimal chance for logical updates. Thus the RPC uses an ambiguous format for data.
 	// Initially we intended this to  be a map<string,any>. However, working with protobuf any is super cumbersome
 	// making it more convenient to pass around a rendered json blob.
 	// The downside is producers need to unmarshal the json themselves which adds a bit of overhead.
 	// We should be able to abstract this away with client wrappings though.
-	Data           []byte                     `protobuf:"bytes,4,opt,name=data,proto3" json:"data,omitempty"`
-	GenerationTime *google_protobuf.Timestamp `protobuf:"bytes,5,opt,name=generation_time,json=generationTime" json:"generation_time,omitempty"`
+	Data           []byte               `protobuf:"bytes,4,opt,name=data,proto3" json:"data,omitempty"`
+	GenerationTime *timestamp.Timestamp `protobuf:"bytes,5,opt,name=generation_time,proto3" json:"generation_time,omitempty"`
+	// id of the run creating the message, see AIDecisionRunService. 0 if created outside a run
+	RunId                int32    `protobuf:"varint,6,opt,name=run_id,proto3" json:"run_id,omitempty"`
+	XXX_NoUnkeyedLiteral struct{} `json:"-"`
+	XXX_unrecognized     []byte   `json:"-"`
+	XXX_sizecache        int32    `json:"-"`
+}
+
+func (m *MessageCreateRequest) Reset()         { *m = MessageCreateRequest{} }
+func (m *MessageCreateRequest) String() string { return proto.CompactTextString(m) }
+func (*MessageCreateRequest) ProtoMessage()    {}
+func (*MessageCreateRequest) Descriptor() ([]byte, []int) {
+	return fileDescriptor_ai_decision_service_e434f06e2ed91ff7, []int{1}
+}
+func (m *MessageCreateRequest) XXX_Unmarshal(b []byte) error {
+	return xxx_messageInfo_MessageCreateRequest.Unmarshal(m, b)
+}
+func (m *MessageCreateRequest) XXX_Marshal(b []byte, deterministic bool) ([]byte, error) {
+	return xxx_messageInfo_MessageCreateRequest.Marshal(b, m, deterministic)
+}
+func (dst *MessageCreateRequest) XXX_Merge(src proto.Message) {
+	xxx_messageInfo_MessageCreateRequest.Merge(dst, src)
+}
+func (m *MessageCreateRequest) XXX_Size() int {
+	return xxx_messageInfo_MessageCreateRequest.Size(m)
+}
+func (m *MessageCreateRequest) XXX_DiscardUnknown() {
+	xxx_messageInfo_MessageCreateRequest.DiscardUnknown(m)
 }
 
-func (m *MessageCreateRequest) Reset()                    { *m = MessageCreateRequest{} }
-func (m *MessageCreateRequest) String() string            { return proto.CompactTextString(m) }
-func (*MessageCreateRequest) ProtoMessage()               {}
-func (*MessageCreateRequest) Descriptor() ([]byte, []int) { return fileDescriptor0, []int{1} }
+var xxx_messageInfo_MessageCreateRequest proto.InternalMessageInfo
 
 func (m *MessageCreateRequest) GetAppId() int32 {
 	if m != nil {
@@ -144,28 +214,60 @@ func (m *MessageCreateRequest) GetData() []byte {
 	return nil
 }
 
-func (m *MessageCreateRequest) GetGenerationTime() *google_protobuf.Timestamp {
+func (m *MessageCreateRequest) GetGenerationTime() *timestamp.Timestamp {
 	if m != nil {
 		return m.GenerationTime
 	}
 	return nil
 }
 
+func (m *MessageCreateRequest) GetRunId() int32 {
+	if m != nil {
+		return m.RunId
+	}
+	return 0
+}
+
 type MessageListRequest struct {
-	AppId int32  `protobuf:"varint,1,opt,name=app_id,json=appId" json:"app_id,omitempty"`
-	Type  string `protobuf:"bytes,2,opt,name=type" json:"type,omitempty"`
+	AppId int32  `protobuf:"varint,1,opt,name=app_id,proto3" json:"app_id,omitempty"`
+	Type  string `protobuf:"bytes,2,opt,name=type,proto3" json:"type,omitempty"`
 	// version range to include
-	MinVersion int32 `protobuf:"varint,3,opt,name=min_version,json=minVersion" json:"min_version,omitempty"`
-	MaxVersion int32 `protobuf:"varint,4,opt,name=max_version,json=maxVersion" json:"max_version,omitempty"`
+	MinVersion int32 `protobuf:"varint,3,opt,name=min_version,proto3" json:"min_version,omitempty"`
+	MaxVersion int32 `protobuf:"varint,4,opt,name=max_version,proto3" json:"max_version,omitempty"`
 	// generation time range to include
-	GenerationTimeFrom *google_protobuf.Timestamp `protobuf:"bytes,5,opt,name=generation_time_from,json=generationTimeFrom" json:"generation_time_from,omitempty"`
-	GenerationTimeTo   *google_protobuf.Timestamp `protobuf:"bytes,6,opt,name=generation_time_to,json=generationTimeTo" json:"generation_time_to,omitempty"`
+	GenerationTimeFrom *timestamp.Timestamp `protobuf:"bytes,5,opt,name=generation_time_from,proto3" json:"generation_time_from,omitempty"`
+	GenerationTimeTo   *timestamp.Timestamp `protobuf:"bytes,6,opt,name=generation_time_to,proto3" json:"generation_time_to,omitempty"`
+	// read_mask lists the fields of Message to send, all fields if empty.
+	// The data of the messages is not read if neither data nor message are in the mask.
+	ReadMask             *field_mask.FieldMask `protobuf:"bytes,7,opt,name=read_mask,proto3" json:"read_mask,omitempty"`
+	XXX_NoUnkeyedLiteral struct{}              `json:"-"`
+	XXX_unrecognized     []byte                `json:"-"`
+	XXX_sizecache        int32                 `json:"-"`
+}
+
+func (m *MessageListRequest) Reset()         { *m = MessageListRequest{} }
+func (m *MessageListRequest) String() string { return proto.CompactTextString(m) }
+func (*MessageListRequest) ProtoMessage()    {}
+func (*MessageListRequest) Descriptor() ([]byte, []int) {
+	return fileDescriptor_ai_decision_service_e434f06e2ed91ff7, []int{2}
+}
+func (m *MessageListRequest) XXX_Unmarshal(b []byte) error {
+	return xxx_messageInfo_MessageListRequest.Unmarshal(m, b)
+}
+func (m *MessageListRequest) XXX_Marshal(b []byte, deterministic bool) ([]byte, error) {
+	return xxx_messageInfo_MessageListRequest.Marshal(b, m, deterministic)
+}
+func (dst *MessageListRequest) XXX_Merge(src proto.Message) {
+	xxx_messageInfo_MessageListRequest.Merge(dst, src)
+}
+func (m *MessageListRequest) XXX_Size() int {
+	return xxx_messageInfo_MessageListRequest.Size(m)
+}
+func (m *MessageListRequest) XXX_DiscardUnknown() {
+	xxx_messageInfo_MessageListRequest.DiscardUnknown(m)
 }
 
-func (m *MessageListRequest) Reset()                    { *m = MessageListRequest{} }
-func (m *MessageListRequest) String() string            { return proto.CompactTextString(m) }
-func (*MessageListRequest) ProtoMessage()               {}
-func (*MessageListRequest) Descriptor() ([]byte, []int) { return fileDescriptor0, []int{2} }
+var xxx_messageInfo_MessageListRequest proto.InternalMessageInfo
 
 func (m *MessageListRequest) GetAppId() int32 {
 	if m != nil {
@@ -195,31 +297,70 @@ func (m *MessageListRequest) GetMaxVersion() int32 {
 	return 0
 }
 
-func (m *MessageListRequest) GetGenerationTimeFrom() *google_protobuf.Timestamp {
+func (m *MessageListRequest) GetGenerationTimeFrom() *timestamp.Timestamp {
 	if m != nil {
 		return m.GenerationTimeFrom
 	}
 	return nil
 }
 
-func (m *MessageListRequest) GetGenerationTimeTo() *google_protobuf.Timestamp {
+func (m *MessageListRequest) GetGenerationTimeTo() *timestamp.Timestamp {
 	if m != nil {
 		return m.GenerationTimeTo
 	}
 	return nil
 }
 
+func (m *MessageListRequest) GetReadMask() *field_mask.FieldMask {
+	if m != nil {
+		return m.ReadMask
+	}
+	return nil
+}
+
 type State struct {
-	AppId          int32                      `protobuf:"varint,1,opt,name=app_id,json=appId" json:"app_id,omitempty"`
-	Keyword        string                     `protobuf:"bytes,2,opt,name=keyword" json:"keyword,omitempty"`
-	Data           []byte                     `protobuf:"bytes,3,opt,name=data,proto3" json:"data,omitempty"`
-	GenerationTime *google_protobuf.Timestamp `protobuf:"bytes,4,opt,name=generation_time,json=generationTime" json:"generation_time,omitempty"`
+	AppId          int32                `protobuf:"varint,1,opt,name=app_id,proto3" json:"app_id,omitempty"`
+	Keyword        string               `protobuf:"bytes,2,opt,name=keyword,proto3" json:"keyword,omitempty"`
+	Data           []byte               `protobuf:"bytes,3,opt,name=data,proto3" json:"data,omitempty"`
+	GenerationTime *timestamp.Timestamp `protobuf:"bytes,4,opt,name=generation_time,proto3" json:"generation_time,omitempty"`
+	// revision is incremented every time the state is saved
+	Revision int32 `protobuf:"varint,5,opt,name=revision,proto3" json:"revision,omitempty"`
+	// chunked is set if the state was saved with SaveChunked, the data is then only available with GetChunked
+	Chunked bool `protobuf:"varint,6,opt,name=chunked,proto3" json:"chunked,omitempty"`
+	// content type of the data as registered for the keyword, empty for unregistered keywords
+	ContentType string `protobuf:"bytes,7,opt,name=content_type,proto3" json:"content_type,omitempty"`
+	// schema version of the data, 0 for unregistered keywords
+	SchemaVersion int32 `protobuf:"varint,8,opt,name=schema_version,proto3" json:"schema_version,omitempty"`
+	// id of the run that saved the state, 0 if saved outside a run
+	RunId                int32    `protobuf:"varint,9,opt,name=run_id,proto3" json:"run_id,omitempty"`
+	XXX_NoUnkeyedLiteral struct{} `json:"-"`
+	XXX_unrecognized     []byte   `json:"-"`
+	XXX_sizecache        int32    `json:"-"`
+}
+
+func (m *State) Reset()         { *m = State{} }
+func (m *State) String() string { return proto.CompactTextString(m) }
+func (*State) ProtoMessage()    {}
+func (*State) Descriptor() ([]byte, []int) {
+	return fileDescriptor_ai_decision_service_e434f06e2ed91ff7, []int{3}
+}
+func (m *State) XXX_Unmarshal(b []byte) error {
+	return xxx_messageInfo_State.Unmarshal(m, b)
+}
+func (m *State) XXX_Marshal(b []byte, deterministic bool) ([]byte, error) {
+	return xxx_messageInfo_State.Marshal(b, m, deterministic)
+}
+func (dst *State) XXX_Merge(src proto.Message) {
+	xxx_messageInfo_State.Merge(dst, src)
+}
+func (m *State) XXX_Size() int {
+	return xxx_messageInfo_State.Size(m)
+}
+func (m *State) XXX_DiscardUnknown() {
+	xxx_messageInfo_State.DiscardUnknown(m)
 }
 
-func (m *State) Reset()                    { *m = State{} }
-func (m *State) String() string            { return proto.CompactTextString(m) }
-func (*State) ProtoMessage()               {}
-func (*State) Descriptor() ([]byte, []int) { return fileDescriptor0, []int{3} }
+var xxx_messageInfo_State proto.InternalMessageInfo
 
 func (m *State) GetAppId() int32 {
 	if m != nil {
@@ -242,24 +383,93 @@ func (m *State) GetData() []byte {
 	return nil
 }
 
-func (m *State) GetGenerationTime() *google_protobuf.Timestamp {
+func (m *State) GetGenerationTime() *timestamp.Timestamp {
 	if m != nil {
 		return m.GenerationTime
 	}
 	return nil
 }
 
-type StateSaveRequest struct {
-	AppId          int32                      `protobuf:"varint,1,opt,name=app_id,json=appId" json:"app_id,omitempty"`
-	Keyword        string                     `protobuf:"bytes,2,opt,name=keyword" json:"keyword,omitempty"`
-	Data           []byte                     `protobuf:"bytes,3,opt,name=data,proto3" json:"data,omitempty"`
-	GenerationTime *google_protobuf.Timestamp `protobuf:"bytes,4,opt,name=generation_time,json=generationTime" json:"generation_time,omitempty"`
+func (m *State) GetRevision() int32 {
+	if m != nil {
+		return m.Revision
+	}
+	return 0
+}
+
+func (m *State) GetChunked() bool {
+	if m != nil {
+		return m.Chunked
+	}
+	return false
+}
+
+func (m *State) GetContentType() string {
+	if m != nil {
+		return m.ContentType
+	}
+	return ""
+}
+
+func (m *State) GetSchemaVersion() int32 {
+	if m != nil {
+		return m.SchemaVersion
+	}
+	return 0
+}
+
+func (m *State) GetRunId() int32 {
+	if m != nil {
+		return m.RunId
+	}
+	return 0
 }
 
-func (m *StateSaveRequest) Reset()                    { *m = StateSaveRequest{} }
-func (m *StateSaveRequest) String() string            { return proto.CompactTextString(m) }
-func (*StateSaveRequest) ProtoMessage()               {}
-func (*StateSaveRequest) Descriptor() ([]byte, []int) { return fileDescriptor0, []int{4} }
+type StateSaveRequest struct {
+	AppId          int32                `protobuf:"varint,1,opt,name=app_id,proto3" json:"app_id,omitempty"`
+	Keyword        string               `protobuf:"bytes,2,opt,name=keyword,proto3" json:"keyword,omitempty"`
+	Data           []byte               `protobuf:"bytes,3,opt,name=data,proto3" json:"data,omitempty"`
+	GenerationTime *timestamp.Timestamp `protobuf:"bytes,4,opt,name=generation_time,proto3" json:"generation_time,omitempty"`
+	// revision of the state the caller expects to overwrite, 0 if the state is expected not to exist.
+	// The save is aborted if the stored revision does not match.
+	ExpectedRevision int32 `protobuf:"varint,5,opt,name=expected_revision,proto3" json:"expected_revision,omitempty"`
+	// force overwrites the state regardless of its revision (last write wins)
+	Force bool `protobuf:"varint,6,opt,name=force,proto3" json:"force,omitempty"`
+	// schema version of the data, must match the version registered for the keyword if set
+	SchemaVersion int32 `protobuf:"varint,7,opt,name=schema_version,proto3" json:"schema_version,omitempty"`
+	// fencing token of the lease held for the app, the save is rejected if the lease expired or was acquired by
+	// another holder since. 0 saves without a lease.
+	FencingToken int64 `protobuf:"varint,8,opt,name=fencing_token,proto3" json:"fencing_token,omitempty"`
+	// id of the run saving the state, see AIDecisionRunService. 0 if saved outside a run
+	RunId                int32    `protobuf:"varint,9,opt,name=run_id,proto3" json:"run_id,omitempty"`
+	XXX_NoUnkeyedLiteral struct{} `json:"-"`
+	XXX_unrecognized     []byte   `json:"-"`
+	XXX_sizecache        int32    `json:"-"`
+}
+
+func (m *StateSaveRequest) Reset()         { *m = StateSaveRequest{} }
+func (m *StateSaveRequest) String() string { return proto.CompactTextString(m) }
+func (*StateSaveRequest) ProtoMessage()    {}
+func (*StateSaveRequest) Descriptor() ([]byte, []int) {
+	return fileDescriptor_ai_decision_service_e434f06e2ed91ff7, []int{4}
+}
+func (m *StateSaveRequest) XXX_Unmarshal(b []byte) error {
+	return xxx_messageInfo_StateSaveRequest.Unmarshal(m, b)
+}
+func (m *StateSaveRequest) XXX_Marshal(b []byte, deterministic bool) ([]byte, error) {
+	return xxx_messageInfo_StateSaveRequest.Marshal(b, m, deterministic)
+}
+func (dst *StateSaveRequest) XXX_Merge(src proto.Message) {
+	xxx_messageInfo_StateSaveRequest.Merge(dst, src)
+}
+func (m *StateSaveRequest) XXX_Size() int {
+	return xxx_messageInfo_StateSaveRequest.Size(m)
+}
+func (m *StateSaveRequest) XXX_DiscardUnknown() {
+	xxx_messageInfo_StateSaveRequest.DiscardUnknown(m)
+}
+
+var xxx_messageInfo_StateSaveRequest proto.InternalMessageInfo
 
 func (m *StateSaveRequest) GetAppId() int32 {
 	if m != nil {
@@ -282,269 +492,2755 @@ func (m *StateSaveRequest) GetData() []byte {
 	return nil
 }
 
-func (m *StateSaveRequest) GetGenerationTime() *google_protobuf.Timestamp {
+func (m *StateSaveRequest) GetGenerationTime() *timestamp.Timestamp {
 	if m != nil {
 		return m.GenerationTime
 	}
 	return nil
 }
 
-type StateGetRequest struct {
-	AppId          int32                      `protobuf:"varint,1,opt,name=app_id,json=appId" json:"app_id,omitempty"`
-	Keyword        string                     `protobuf:"bytes,2,opt,name=keyword" json:"keyword,omitempty"`
-	GenerationTime *google_protobuf.Timestamp `protobuf:"bytes,3,opt,name=generation_time,json=generationTime" json:"generation_time,omitempty"`
+func (m *StateSaveRequest) GetExpectedRevision() int32 {
+	if m != nil {
+		return m.ExpectedRevision
+	}
+	return 0
 }
 
-func (m *StateGetRequest) Reset()                    { *m = StateGetRequest{} }
-func (m *StateGetRequest) String() string            { return proto.CompactTextString(m) }
-func (*StateGetRequest) ProtoMessage()               {}
-func (*StateGetRequest) Descriptor() ([]byte, []int) { return fileDescriptor0, []int{5} }
+func (m *StateSaveRequest) GetForce() bool {
+	if m != nil {
+		return m.Force
+	}
+	return false
+}
 
-func (m *StateGetRequest) GetAppId() int32 {
+func (m *StateSaveRequest) GetSchemaVersion() int32 {
 	if m != nil {
-		return m.AppId
+		return m.SchemaVersion
 	}
 	return 0
 }
 
-func (m *StateGetRequest) GetKeyword() string {
+func (m *StateSaveRequest) GetFencingToken() int64 {
 	if m != nil {
-		return m.Keyword
+		return m.FencingToken
 	}
-	return ""
+	return 0
 }
 
-func (m *StateGetRequest) GetGenerationTime() *google_protobuf.Timestamp {
+func (m *StateSaveRequest) GetRunId() int32 {
 	if m != nil {
-		return m.GenerationTime
+		return m.RunId
 	}
-	return nil
+	return 0
 }
 
-type StateListRequest struct {
-	AppId   int32  `protobuf:"varint,1,opt,name=app_id,json=appId" json:"app_id,omitempty"`
-	Keyword string `protobuf:"bytes,2,opt,name=keyword" json:"keyword,omitempty"`
-	// generation time range to include
-	GenerationTimeFrom *google_protobuf.Timestamp `protobuf:"bytes,3,opt,name=generation_time_from,json=generationTimeFrom" json:"generation_time_from,omitempty"`
-	GenerationTimeTo   *google_protobuf.Timestamp `protobuf:"bytes,4,opt,name=generation_time_to,json=generationTimeTo" json:"generation_time_to,omitempty"`
+// StateSaveChunk is a part of a state uploaded with SaveChunked.
+// The first chunk carries the state without data, the data is the concatenation of the data of all chunks.
+// The last chunk carries the checksum of the whole data.
+type StateSaveChunk struct {
+	State *StateSaveRequest `protobuf:"bytes,1,opt,name=state,proto3" json:"state,omitempty"`
+	Data  []byte            `protobuf:"bytes,2,opt,name=data,proto3" json:"data,omitempty"`
+	// hex encoded SHA-256 checksum of the whole data
+	Checksum             string   `protobuf:"bytes,3,opt,name=checksum,proto3" json:"checksum,omitempty"`
+	XXX_NoUnkeyedLiteral struct{} `json:"-"`
+	XXX_unrecognized     []byte   `json:"-"`
+	XXX_sizecache        int32    `json:"-"`
+}
+
+func (m *StateSaveChunk) Reset()         { *m = StateSaveChunk{} }
+func (m *StateSaveChunk) String() string { return proto.CompactTextString(m) }
+func (*StateSaveChunk) ProtoMessage()    {}
+func (*StateSaveChunk) Descriptor() ([]byte, []int) {
+	return fileDescriptor_ai_decision_service_e434f06e2ed91ff7, []int{5}
+}
+func (m *StateSaveChunk) XXX_Unmarshal(b []byte) error {
+	return xxx_messageInfo_StateSaveChunk.Unmarshal(m, b)
+}
+func (m *StateSaveChunk) XXX_Marshal(b []byte, deterministic bool) ([]byte, error) {
+	return xxx_messageInfo_StateSaveChunk.Marshal(b, m, deterministic)
+}
+func (dst *StateSaveChunk) XXX_Merge(src proto.Message) {
+	xxx_messageInfo_StateSaveChunk.Merge(dst, src)
+}
+func (m *StateSaveChunk) XXX_Size() int {
+	return xxx_messageInfo_StateSaveChunk.Size(m)
+}
+func (m *StateSaveChunk) XXX_DiscardUnknown() {
+	xxx_messageInfo_StateSaveChunk.DiscardUnknown(m)
 }
 
-func (m *StateListRequest) Reset()                    { *m = StateListRequest{} }
-func (m *StateListRequest) String() string            { return proto.CompactTextString(m) }
-func (*StateListRequest) ProtoMessage()               {}
-func (*StateListRequest) Descriptor() ([]byte, []int) { return fileDescriptor0, []int{6} }
+var xxx_messageInfo_StateSaveChunk proto.InternalMessageInfo
 
-func (m *StateListRequest) GetAppId() int32 {
+func (m *StateSaveChunk) GetState() *StateSaveRequest {
 	if m != nil {
-		return m.AppId
+		return m.State
 	}
-	return 0
+	return nil
 }
 
-func (m *StateListRequest) GetKeyword() string {
+func (m *StateSaveChunk) GetData() []byte {
 	if m != nil {
-		return m.Keyword
+		return m.Data
+	}
+	return nil
+}
+
+func (m *StateSaveChunk) GetChecksum() string {
+	if m != nil {
+		return m.Checksum
 	}
 	return ""
 }
 
-func (m *StateListRequest) GetGenerationTimeFrom() *google_protobuf.Timestamp {
+// StateChunk is a part of a state downloaded with GetChunked, see StateSaveChunk
+type StateChunk struct {
+	State *State `protobuf:"bytes,1,opt,name=state,proto3" json:"state,omitempty"`
+	Data  []byte `protobuf:"bytes,2,opt,name=data,proto3" json:"data,omitempty"`
+	// hex encoded SHA-256 checksum of the whole data
+	Checksum             string   `protobuf:"bytes,3,opt,name=checksum,proto3" json:"checksum,omitempty"`
+	XXX_NoUnkeyedLiteral struct{} `json:"-"`
+	XXX_unrecognized     []byte   `json:"-"`
+	XXX_sizecache        int32    `json:"-"`
+}
+
+func (m *StateChunk) Reset()         { *m = StateChunk{} }
+func (m *StateChunk) String() string { return proto.CompactTextString(m) }
+func (*StateChunk) ProtoMessage()    {}
+func (*StateChunk) Descriptor() ([]byte, []int) {
+	return fileDescriptor_ai_decision_service_e434f06e2ed91ff7, []int{6}
+}
+func (m *StateChunk) XXX_Unmarshal(b []byte) error {
+	return xxx_messageInfo_StateChunk.Unmarshal(m, b)
+}
+func (m *StateChunk) XXX_Marshal(b []byte, deterministic bool) ([]byte, error) {
+	return xxx_messageInfo_StateChunk.Marshal(b, m, deterministic)
+}
+func (dst *StateChunk) XXX_Merge(src proto.Message) {
+	xxx_messageInfo_StateChunk.Merge(dst, src)
+}
+func (m *StateChunk) XXX_Size() int {
+	return xxx_messageInfo_StateChunk.Size(m)
+}
+func (m *StateChunk) XXX_DiscardUnknown() {
+	xxx_messageInfo_StateChunk.DiscardUnknown(m)
+}
+
+var xxx_messageInfo_StateChunk proto.InternalMessageInfo
+
+func (m *StateChunk) GetState() *State {
 	if m != nil {
-		return m.GenerationTimeFrom
+		return m.State
 	}
 	return nil
 }
 
-func (m *StateListRequest) GetGenerationTimeTo() *google_protobuf.Timestamp {
+func (m *StateChunk) GetData() []byte {
 	if m != nil {
-		return m.GenerationTimeTo
+		return m.Data
 	}
 	return nil
 }
 
-func init() {
-	proto.RegisterType((*Message)(nil), "callstats.ai_decision.Message")
-	proto.RegisterType((*MessageCreateRequest)(nil), "callstats.ai_decision.MessageCreateRequest")
-	proto.RegisterType((*MessageListRequest)(nil), "callstats.ai_decision.MessageListRequest")
-	proto.RegisterType((*State)(nil), "callstats.ai_decision.State")
-	proto.RegisterType((*StateSaveRequest)(nil), "callstats.ai_decision.StateSaveRequest")
-	proto.RegisterType((*StateGetRequest)(nil), "callstats.ai_decision.StateGetRequest")
-	proto.RegisterType((*StateListRequest)(nil), "callstats.ai_decision.StateListRequest")
+func (m *StateChunk) GetChecksum() string {
+	if m != nil {
+		return m.Checksum
+	}
+	return ""
 }
 
-// Reference imports to suppress errors if they are not otherwise used.
-var _ context.Context
-var _ grpc.ClientConn
-
-// This is a compile-time assertion to ensure that this generated file
-// is compatible with the grpc package it is being compiled against.
-const _ = grpc.SupportPackageIsVersion4
-
-// Client API for AIDecisionMessageService service
-
-type AIDecisionMessageServiceClient interface {
-	Create(ctx context.Context, in *MessageCreateRequest, opts ...grpc.CallOption) (*Message, error)
-	List(ctx context.Context, in *MessageListRequest, opts ...grpc.CallOption) (AIDecisionMessageService_ListClient, error)
+type StateGetRequest struct {
+	AppId                int32                `protobuf:"varint,1,opt,name=app_id,proto3" json:"app_id,omitempty"`
+	Keyword              string               `protobuf:"bytes,2,opt,name=keyword,proto3" json:"keyword,omitempty"`
+	GenerationTime       *timestamp.Timestamp `protobuf:"bytes,3,opt,name=generation_time,proto3" json:"generation_time,omitempty"`
+	XXX_NoUnkeyedLiteral struct{}             `json:"-"`
+	XXX_unrecognized     []byte               `json:"-"`
+	XXX_sizecache        int32                `json:"-"`
 }
 
-type aIDecisionMessageServiceClient struct {
-	cc *grpc.ClientConn
+func (m *StateGetRequest) Reset()         { *m = StateGetRequest{} }
+func (m *StateGetRequest) String() string { return proto.CompactTextString(m) }
+func (*StateGetRequest) ProtoMessage()    {}
+func (*StateGetRequest) Descriptor() ([]byte, []int) {
+	return fileDescriptor_ai_decision_service_e434f06e2ed91ff7, []int{7}
 }
-
-func NewAIDecisionMessageServiceClient(cc *grpc.ClientConn) AIDecisionMessageServiceClient {
-	return &aIDecisionMessageServiceClient{cc}
+func (m *StateGetRequest) XXX_Unmarshal(b []byte) error {
+	return xxx_messageInfo_StateGetRequest.Unmarshal(m, b)
+}
+func (m *StateGetRequest) XXX_Marshal(b []byte, deterministic bool) ([]byte, error) {
+	return xxx_messageInfo_StateGetRequest.Marshal(b, m, deterministic)
+}
+func (dst *StateGetRequest) XXX_Merge(src proto.Message) {
+	xxx_messageInfo_StateGetRequest.Merge(dst, src)
+}
+func (m *StateGetRequest) XXX_Size() int {
+	return xxx_messageInfo_StateGetRequest.Size(m)
+}
+func (m *StateGetRequest) XXX_DiscardUnknown() {
+	xxx_messageInfo_StateGetRequest.DiscardUnknown(m)
 }
 
-func (c *aIDecisionMessageServiceClient) Create(ctx context.Context, in *MessageCreateRequest, opts ...grpc.CallOption) (*Message, error) {
-	out := new(Message)
-	err := grpc.Invoke(ctx, "/callstats.ai_decision.AIDecisionMessageService/Create", in, out, c.cc, opts...)
-	if err != nil {
-		return nil, err
+var xxx_messageInfo_StateGetRequest proto.InternalMessageInfo
+
+func (m *StateGetRequest) GetAppId() int32 {
+	if m != nil {
+		return m.AppId
 	}
-	return out, nil
+	return 0
 }
 
-func (c *aIDecisionMessageServiceClient) List(ctx context.Context, in *MessageListRequest, opts ...grpc.CallOption) (AIDecisionMessageService_ListClient, error) {
-	stream, err := grpc.NewClientStream(ctx, &_AIDecisionMessageService_serviceDesc.Streams[0], c.cc, "/callstats.ai_decision.AIDecisionMessageService/List", opts...)
-	if err != nil {
-		return nil, err
-	}
-	x := &aIDecisionMessageServiceListClient{stream}
-	if err := x.ClientStream.SendMsg(in); err != nil {
-		return nil, err
+func (m *StateGetRequest) GetKeyword() string {
+	if m != nil {
+		return m.Keyword
 	}
-	if err := x.ClientStream.CloseSend(); err != nil {
-		return nil, err
+	return ""
+}
+
+func (m *StateGetRequest) GetGenerationTime() *timestamp.Timestamp {
+	if m != nil {
+		return m.GenerationTime
 	}
-	return x, nil
+	return nil
 }
 
-type AIDecisionMessageService_ListClient interface {
-	Recv() (*Message, error)
-	grpc.ClientStream
+type StateGetLatestRequest struct {
+	AppId                int32    `protobuf:"varint,1,opt,name=app_id,proto3" json:"app_id,omitempty"`
+	Keyword              string   `protobuf:"bytes,2,opt,name=keyword,proto3" json:"keyword,omitempty"`
+	XXX_NoUnkeyedLiteral struct{} `json:"-"`
+	XXX_unrecognized     []byte   `json:"-"`
+	XXX_sizecache        int32    `json:"-"`
 }
 
-type aIDecisionMessageServiceListClient struct {
-	grpc.ClientStream
+func (m *StateGetLatestRequest) Reset()         { *m = StateGetLatestRequest{} }
+func (m *StateGetLatestRequest) String() string { return proto.CompactTextString(m) }
+func (*StateGetLatestRequest) ProtoMessage()    {}
+func (*StateGetLatestRequest) Descriptor() ([]byte, []int) {
+	return fileDescriptor_ai_decision_service_e434f06e2ed91ff7, []int{8}
+}
+func (m *StateGetLatestRequest) XXX_Unmarshal(b []byte) error {
+	return xxx_messageInfo_StateGetLatestRequest.Unmarshal(m, b)
+}
+func (m *StateGetLatestRequest) XXX_Marshal(b []byte, deterministic bool) ([]byte, error) {
+	return xxx_messageInfo_StateGetLatestRequest.Marshal(b, m, deterministic)
+}
+func (dst *StateGetLatestRequest) XXX_Merge(src proto.Message) {
+	xxx_messageInfo_StateGetLatestRequest.Merge(dst, src)
+}
+func (m *StateGetLatestRequest) XXX_Size() int {
+	return xxx_messageInfo_StateGetLatestRequest.Size(m)
+}
+func (m *StateGetLatestRequest) XXX_DiscardUnknown() {
+	xxx_messageInfo_StateGetLatestRequest.DiscardUnknown(m)
 }
 
-func (x *aIDecisionMessageServiceListClient) Recv() (*Message, error) {
-	m := new(Message)
-	if err := x.ClientStream.RecvMsg(m); err != nil {
-		return nil, err
+var xxx_messageInfo_StateGetLatestRequest proto.InternalMessageInfo
+
+func (m *StateGetLatestRequest) GetAppId() int32 {
+	if m != nil {
+		return m.AppId
 	}
-	return m, nil
+	return 0
 }
 
-// Server API for AIDecisionMessageService service
+func (m *StateGetLatestRequest) GetKeyword() string {
+	if m != nil {
+		return m.Keyword
+	}
+	return ""
+}
 
-type AIDecisionMessageServiceServer interface {
-	Create(context.Context, *MessageCreateRequest) (*Message, error)
-	List(*MessageListRequest, AIDecisionMessageService_ListServer) error
+type StateGetAsOfRequest struct {
+	AppId   int32  `protobuf:"varint,1,opt,name=app_id,proto3" json:"app_id,omitempty"`
+	Keyword string `protobuf:"bytes,2,opt,name=keyword,proto3" json:"keyword,omitempty"`
+	// the newest state generated at or before as_of is returned
+	AsOf                 *timestamp.Timestamp `protobuf:"bytes,3,opt,name=as_of,proto3" json:"as_of,omitempty"`
+	XXX_NoUnkeyedLiteral struct{}             `json:"-"`
+	XXX_unrecognized     []byte               `json:"-"`
+	XXX_sizecache        int32                `json:"-"`
 }
 
-func RegisterAIDecisionMessageServiceServer(s *grpc.Server, srv AIDecisionMessageServiceServer) {
-	s.RegisterService(&_AIDecisionMessageService_serviceDesc, srv)
+func (m *StateGetAsOfRequest) Reset()         { *m = StateGetAsOfRequest{} }
+func (m *StateGetAsOfRequest) String() string { return proto.CompactTextString(m) }
+func (*StateGetAsOfRequest) ProtoMessage()    {}
+func (*StateGetAsOfRequest) Descriptor() ([]byte, []int) {
+	return fileDescriptor_ai_decision_service_e434f06e2ed91ff7, []int{9}
+}
+func (m *StateGetAsOfRequest) XXX_Unmarshal(b []byte) error {
+	return xxx_messageInfo_StateGetAsOfRequest.Unmarshal(m, b)
+}
+func (m *StateGetAsOfRequest) XXX_Marshal(b []byte, deterministic bool) ([]byte, error) {
+	return xxx_messageInfo_StateGetAsOfRequest.Marshal(b, m, deterministic)
+}
+func (dst *StateGetAsOfRequest) XXX_Merge(src proto.Message) {
+	xxx_messageInfo_StateGetAsOfRequest.Merge(dst, src)
+}
+func (m *StateGetAsOfRequest) XXX_Size() int {
+	return xxx_messageInfo_StateGetAsOfRequest.Size(m)
+}
+func (m *StateGetAsOfRequest) XXX_DiscardUnknown() {
+	xxx_messageInfo_StateGetAsOfRequest.DiscardUnknown(m)
 }
 
-func _AIDecisionMessageService_Create_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
-	in := new(MessageCreateRequest)
-	if err := dec(in); err != nil {
-		return nil, err
-	}
-	if interceptor == nil {
-		return srv.(AIDecisionMessageServiceServer).Create(ctx, in)
-	}
-	info := &grpc.UnaryServerInfo{
-		Server:     srv,
-		FullMethod: "/callstats.ai_decision.AIDecisionMessageService/Create",
-	}
-	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
-		return srv.(AIDecisionMessageServiceServer).Create(ctx, req.(*MessageCreateRequest))
+var xxx_messageInfo_StateGetAsOfRequest proto.InternalMessageInfo
+
+func (m *StateGetAsOfRequest) GetAppId() int32 {
+	if m != nil {
+		return m.AppId
 	}
-	return interceptor(ctx, in, info, handler)
+	return 0
 }
 
-func _AIDecisionMessageService_List_Handler(srv interface{}, stream grpc.ServerStream) error {
-	m := new(MessageListRequest)
-	if err := stream.RecvMsg(m); err != nil {
-		return err
+func (m *StateGetAsOfRequest) GetKeyword() string {
+	if m != nil {
+		return m.Keyword
 	}
-	return srv.(AIDecisionMessageServiceServer).List(m, &aIDecisionMessageServiceListServer{stream})
+	return ""
 }
 
-type AIDecisionMessageService_ListServer interface {
-	Send(*Message) error
-	grpc.ServerStream
+func (m *StateGetAsOfRequest) GetAsOf() *timestamp.Timestamp {
+	if m != nil {
+		return m.AsOf
+	}
+	return nil
 }
 
-type aIDecisionMessageServiceListServer struct {
+type StateListRequest struct {
+	AppId   int32  `protobuf:"varint,1,opt,name=app_id,proto3" json:"app_id,omitempty"`
+	Keyword string `protobuf:"bytes,2,opt,name=keyword,proto3" json:"keyword,omitempty"`
+	// generation time range to include
+	GenerationTimeFrom *timestamp.Timestamp `protobuf:"bytes,3,opt,name=generation_time_from,proto3" json:"generation_time_from,omitempty"`
+	GenerationTimeTo   *timestamp.Timestamp `protobuf:"bytes,4,opt,name=generation_time_to,proto3" json:"generation_time_to,omitempty"`
+	// read_mask lists the fields of State to send, all fields if empty.
+	// The data of the states is not read if data is not in the mask.
+	ReadMask             *field_mask.FieldMask `protobuf:"bytes,5,opt,name=read_mask,proto3" json:"read_mask,omitempty"`
+	XXX_NoUnkeyedLiteral struct{}              `json:"-"`
+	XXX_unrecognized     []byte                `json:"-"`
+	XXX_sizecache        int32                 `json:"-"`
+}
+
+func (m *StateListRequest) Reset()         { *m = StateListRequest{} }
+func (m *StateListRequest) String() string { return proto.CompactTextString(m) }
+func (*StateListRequest) ProtoMessage()    {}
+func (*StateListRequest) Descriptor() ([]byte, []int) {
+	return fileDescriptor_ai_decision_service_e434f06e2ed91ff7, []int{10}
+}
+func (m *StateListRequest) XXX_Unmarshal(b []byte) error {
+	return xxx_messageInfo_StateListRequest.Unmarshal(m, b)
+}
+func (m *StateListRequest) XXX_Marshal(b []byte, deterministic bool) ([]byte, error) {
+	return xxx_messageInfo_StateListRequest.Marshal(b, m, deterministic)
+}
+func (dst *StateListRequest) XXX_Merge(src proto.Message) {
+	xxx_messageInfo_StateListRequest.Merge(dst, src)
+}
+func (m *StateListRequest) XXX_Size() int {
+	return xxx_messageInfo_StateListRequest.Size(m)
+}
+func (m *StateListRequest) XXX_DiscardUnknown() {
+	xxx_messageInfo_StateListRequest.DiscardUnknown(m)
+}
+
+var xxx_messageInfo_StateListRequest proto.InternalMessageInfo
+
+func (m *StateListRequest) GetAppId() int32 {
+	if m != nil {
+		return m.AppId
+	}
+	return 0
+}
+
+func (m *StateListRequest) GetKeyword() string {
+	if m != nil {
+		return m.Keyword
+	}
+	return ""
+}
+
+func (m *StateListRequest) GetGenerationTimeFrom() *timestamp.Timestamp {
+	if m != nil {
+		return m.GenerationTimeFrom
+	}
+	return nil
+}
+
+func (m *StateListRequest) GetGenerationTimeTo() *timestamp.Timestamp {
+	if m != nil {
+		return m.GenerationTimeTo
+	}
+	return nil
+}
+
+func (m *StateListRequest) GetReadMask() *field_mask.FieldMask {
+	if m != nil {
+		return m.ReadMask
+	}
+	return nil
+}
+
+type StateDeleteRequest struct {
+	AppId          int32                `protobuf:"varint,1,opt,name=app_id,proto3" json:"app_id,omitempty"`
+	Keyword        string               `protobuf:"bytes,2,opt,name=keyword,proto3" json:"keyword,omitempty"`
+	GenerationTime *timestamp.Timestamp `protobuf:"bytes,3,opt,name=generation_time,proto3" json:"generation_time,omitempty"`
+	// dry_run counts the states that would be deleted without deleting them
+	DryRun               bool     `protobuf:"varint,4,opt,name=dry_run,proto3" json:"dry_run,omitempty"`
+	XXX_NoUnkeyedLiteral struct{} `json:"-"`
+	XXX_unrecognized     []byte   `json:"-"`
+	XXX_sizecache        int32    `json:"-"`
+}
+
+func (m *StateDeleteRequest) Reset()         { *m = StateDeleteRequest{} }
+func (m *StateDeleteRequest) String() string { return proto.CompactTextString(m) }
+func (*StateDeleteRequest) ProtoMessage()    {}
+func (*StateDeleteRequest) Descriptor() ([]byte, []int) {
+	return fileDescriptor_ai_decision_service_e434f06e2ed91ff7, []int{11}
+}
+func (m *StateDeleteRequest) XXX_Unmarshal(b []byte) error {
+	return xxx_messageInfo_StateDeleteRequest.Unmarshal(m, b)
+}
+func (m *StateDeleteRequest) XXX_Marshal(b []byte, deterministic bool) ([]byte, error) {
+	return xxx_messageInfo_StateDeleteRequest.Marshal(b, m, deterministic)
+}
+func (dst *StateDeleteRequest) XXX_Merge(src proto.Message) {
+	xxx_messageInfo_StateDeleteRequest.Merge(dst, src)
+}
+func (m *StateDeleteRequest) XXX_Size() int {
+	return xxx_messageInfo_StateDeleteRequest.Size(m)
+}
+func (m *StateDeleteRequest) XXX_DiscardUnknown() {
+	xxx_messageInfo_StateDeleteRequest.DiscardUnknown(m)
+}
+
+var xxx_messageInfo_StateDeleteRequest proto.InternalMessageInfo
+
+func (m *StateDeleteRequest) GetAppId() int32 {
+	if m != nil {
+		return m.AppId
+	}
+	return 0
+}
+
+func (m *StateDeleteRequest) GetKeyword() string {
+	if m != nil {
+		return m.Keyword
+	}
+	return ""
+}
+
+func (m *StateDeleteRequest) GetGenerationTime() *timestamp.Timestamp {
+	if m != nil {
+		return m.GenerationTime
+	}
+	return nil
+}
+
+func (m *StateDeleteRequest) GetDryRun() bool {
+	if m != nil {
+		return m.DryRun
+	}
+	return false
+}
+
+type StateDeleteRangeRequest struct {
+	AppId   int32  `protobuf:"varint,1,opt,name=app_id,proto3" json:"app_id,omitempty"`
+	Keyword string `protobuf:"bytes,2,opt,name=keyword,proto3" json:"keyword,omitempty"`
+	// generation time range to delete, both ends inclusive
+	GenerationTimeFrom *timestamp.Timestamp `protobuf:"bytes,3,opt,name=generation_time_from,proto3" json:"generation_time_from,omitempty"`
+	GenerationTimeTo   *timestamp.Timestamp `protobuf:"bytes,4,opt,name=generation_time_to,proto3" json:"generation_time_to,omitempty"`
+	// dry_run counts the states that would be deleted without deleting them
+	DryRun               bool     `protobuf:"varint,5,opt,name=dry_run,proto3" json:"dry_run,omitempty"`
+	XXX_NoUnkeyedLiteral struct{} `json:"-"`
+	XXX_unrecognized     []byte   `json:"-"`
+	XXX_sizecache        int32    `json:"-"`
+}
+
+func (m *StateDeleteRangeRequest) Reset()         { *m = StateDeleteRangeRequest{} }
+func (m *StateDeleteRangeRequest) String() string { return proto.CompactTextString(m) }
+func (*StateDeleteRangeRequest) ProtoMessage()    {}
+func (*StateDeleteRangeRequest) Descriptor() ([]byte, []int) {
+	return fileDescriptor_ai_decision_service_e434f06e2ed91ff7, []int{12}
+}
+func (m *StateDeleteRangeRequest) XXX_Unmarshal(b []byte) error {
+	return xxx_messageInfo_StateDeleteRangeRequest.Unmarshal(m, b)
+}
+func (m *StateDeleteRangeRequest) XXX_Marshal(b []byte, deterministic bool) ([]byte, error) {
+	return xxx_messageInfo_StateDeleteRangeRequest.Marshal(b, m, deterministic)
+}
+func (dst *StateDeleteRangeRequest) XXX_Merge(src proto.Message) {
+	xxx_messageInfo_StateDeleteRangeRequest.Merge(dst, src)
+}
+func (m *StateDeleteRangeRequest) XXX_Size() int {
+	return xxx_messageInfo_StateDeleteRangeRequest.Size(m)
+}
+func (m *StateDeleteRangeRequest) XXX_DiscardUnknown() {
+	xxx_messageInfo_StateDeleteRangeRequest.DiscardUnknown(m)
+}
+
+var xxx_messageInfo_StateDeleteRangeRequest proto.InternalMessageInfo
+
+func (m *StateDeleteRangeRequest) GetAppId() int32 {
+	if m != nil {
+		return m.AppId
+	}
+	return 0
+}
+
+func (m *StateDeleteRangeRequest) GetKeyword() string {
+	if m != nil {
+		return m.Keyword
+	}
+	return ""
+}
+
+func (m *StateDeleteRangeRequest) GetGenerationTimeFrom() *timestamp.Timestamp {
+	if m != nil {
+		return m.GenerationTimeFrom
+	}
+	return nil
+}
+
+func (m *StateDeleteRangeRequest) GetGenerationTimeTo() *timestamp.Timestamp {
+	if m != nil {
+		return m.GenerationTimeTo
+	}
+	return nil
+}
+
+func (m *StateDeleteRangeRequest) GetDryRun() bool {
+	if m != nil {
+		return m.DryRun
+	}
+	return false
+}
+
+type StateDeleteResponse struct {
+	// number of deleted states, or states that would be deleted for dry runs
+	Deleted              int32    `protobuf:"varint,1,opt,name=deleted,proto3" json:"deleted,omitempty"`
+	DryRun               bool     `protobuf:"varint,2,opt,name=dry_run,proto3" json:"dry_run,omitempty"`
+	XXX_NoUnkeyedLiteral struct{} `json:"-"`
+	XXX_unrecognized     []byte   `json:"-"`
+	XXX_sizecache        int32    `json:"-"`
+}
+
+func (m *StateDeleteResponse) Reset()         { *m = StateDeleteResponse{} }
+func (m *StateDeleteResponse) String() string { return proto.CompactTextString(m) }
+func (*StateDeleteResponse) ProtoMessage()    {}
+func (*StateDeleteResponse) Descriptor() ([]byte, []int) {
+	return fileDescriptor_ai_decision_service_e434f06e2ed91ff7, []int{13}
+}
+func (m *StateDeleteResponse) XXX_Unmarshal(b []byte) error {
+	return xxx_messageInfo_StateDeleteResponse.Unmarshal(m, b)
+}
+func (m *StateDeleteResponse) XXX_Marshal(b []byte, deterministic bool) ([]byte, error) {
+	return xxx_messageInfo_StateDeleteResponse.Marshal(b, m, deterministic)
+}
+func (dst *StateDeleteResponse) XXX_Merge(src proto.Message) {
+	xxx_messageInfo_StateDeleteResponse.Merge(dst, src)
+}
+func (m *StateDeleteResponse) XXX_Size() int {
+	return xxx_messageInfo_StateDeleteResponse.Size(m)
+}
+func (m *StateDeleteResponse) XXX_DiscardUnknown() {
+	xxx_messageInfo_StateDeleteResponse.DiscardUnknown(m)
+}
+
+var xxx_messageInfo_StateDeleteResponse proto.InternalMessageInfo
+
+func (m *StateDeleteResponse) GetDeleted() int32 {
+	if m != nil {
+		return m.Deleted
+	}
+	return 0
+}
+
+func (m *StateDeleteResponse) GetDryRun() bool {
+	if m != nil {
+		return m.DryRun
+	}
+	return false
+}
+
+// Keyword describes a state keyword registered in the keyword registry and its use by an app
+type Keyword struct {
+	Keyword string `protobuf:"bytes,1,opt,name=keyword,proto3" json:"keyword,omitempty"`
+	// content type of the data, e.g. application/json, application/python-pickle or application/x-npy
+	ContentType   string `protobuf:"bytes,2,opt,name=content_type,proto3" json:"content_type,omitempty"`
+	SchemaVersion int32  `protobuf:"varint,3,opt,name=schema_version,proto3" json:"schema_version,omitempty"`
+	// maximum size of the data in bytes, 0 if unlimited
+	MaxSize int32  `protobuf:"varint,4,opt,name=max_size,proto3" json:"max_size,omitempty"`
+	Owner   string `protobuf:"bytes,5,opt,name=owner,proto3" json:"owner,omitempty"`
+	// registered is false for keywords in use but missing from the registry
+	Registered bool `protobuf:"varint,6,opt,name=registered,proto3" json:"registered,omitempty"`
+	// number of states saved by the app and generation time of the newest one
+	StateCount           int32                `protobuf:"varint,7,opt,name=state_count,proto3" json:"state_count,omitempty"`
+	LatestGenerationTime *timestamp.Timestamp `protobuf:"bytes,8,opt,name=latest_generation_time,proto3" json:"latest_generation_time,omitempty"`
+	XXX_NoUnkeyedLiteral struct{}             `json:"-"`
+	XXX_unrecognized     []byte               `json:"-"`
+	XXX_sizecache        int32                `json:"-"`
+}
+
+func (m *Keyword) Reset()         { *m = Keyword{} }
+func (m *Keyword) String() string { return proto.CompactTextString(m) }
+func (*Keyword) ProtoMessage()    {}
+func (*Keyword) Descriptor() ([]byte, []int) {
+	return fileDescriptor_ai_decision_service_e434f06e2ed91ff7, []int{14}
+}
+func (m *Keyword) XXX_Unmarshal(b []byte) error {
+	return xxx_messageInfo_Keyword.Unmarshal(m, b)
+}
+func (m *Keyword) XXX_Marshal(b []byte, deterministic bool) ([]byte, error) {
+	return xxx_messageInfo_Keyword.Marshal(b, m, deterministic)
+}
+func (dst *Keyword) XXX_Merge(src proto.Message) {
+	xxx_messageInfo_Keyword.Merge(dst, src)
+}
+func (m *Keyword) XXX_Size() int {
+	return xxx_messageInfo_Keyword.Size(m)
+}
+func (m *Keyword) XXX_DiscardUnknown() {
+	xxx_messageInfo_Keyword.DiscardUnknown(m)
+}
+
+var xxx_messageInfo_Keyword proto.InternalMessageInfo
+
+func (m *Keyword) GetKeyword() string {
+	if m != nil {
+		return m.Keyword
+	}
+	return ""
+}
+
+func (m *Keyword) GetContentType() string {
+	if m != nil {
+		return m.ContentType
+	}
+	return ""
+}
+
+func (m *Keyword) GetSchemaVersion() int32 {
+	if m != nil {
+		return m.SchemaVersion
+	}
+	return 0
+}
+
+func (m *Keyword) GetMaxSize() int32 {
+	if m != nil {
+		return m.MaxSize
+	}
+	return 0
+}
+
+func (m *Keyword) GetOwner() string {
+	if m != nil {
+		return m.Owner
+	}
+	return ""
+}
+
+func (m *Keyword) GetRegistered() bool {
+	if m != nil {
+		return m.Registered
+	}
+	return false
+}
+
+func (m *Keyword) GetStateCount() int32 {
+	if m != nil {
+		return m.StateCount
+	}
+	return 0
+}
+
+func (m *Keyword) GetLatestGenerationTime() *timestamp.Timestamp {
+	if m != nil {
+		return m.LatestGenerationTime
+	}
+	return nil
+}
+
+type KeywordListRequest struct {
+	AppId                int32    `protobuf:"varint,1,opt,name=app_id,proto3" json:"app_id,omitempty"`
+	XXX_NoUnkeyedLiteral struct{} `json:"-"`
+	XXX_unrecognized     []byte   `json:"-"`
+	XXX_sizecache        int32    `json:"-"`
+}
+
+func (m *KeywordListRequest) Reset()         { *m = KeywordListRequest{} }
+func (m *KeywordListRequest) String() string { return proto.CompactTextString(m) }
+func (*KeywordListRequest) ProtoMessage()    {}
+func (*KeywordListRequest) Descriptor() ([]byte, []int) {
+	return fileDescriptor_ai_decision_service_e434f06e2ed91ff7, []int{15}
+}
+func (m *KeywordListRequest) XXX_Unmarshal(b []byte) error {
+	return xxx_messageInfo_KeywordListRequest.Unmarshal(m, b)
+}
+func (m *KeywordListRequest) XXX_Marshal(b []byte, deterministic bool) ([]byte, error) {
+	return xxx_messageInfo_KeywordListRequest.Marshal(b, m, deterministic)
+}
+func (dst *KeywordListRequest) XXX_Merge(src proto.Message) {
+	xxx_messageInfo_KeywordListRequest.Merge(dst, src)
+}
+func (m *KeywordListRequest) XXX_Size() int {
+	return xxx_messageInfo_KeywordListRequest.Size(m)
+}
+func (m *KeywordListRequest) XXX_DiscardUnknown() {
+	xxx_messageInfo_KeywordListRequest.DiscardUnknown(m)
+}
+
+var xxx_messageInfo_KeywordListRequest proto.InternalMessageInfo
+
+func (m *KeywordListRequest) GetAppId() int32 {
+	if m != nil {
+		return m.AppId
+	}
+	return 0
+}
+
+// CompactionPolicy defines how the state history of a keyword is compacted, rules set to 0 are disabled.
+// The newest keep_last states per app are always kept, other states are deleted if older than delete_after_days,
+// thinned to the newest state per day if older than keep_daily_after_days, and deleted otherwise if keep_last is set.
+type CompactionPolicy struct {
+	Keyword              string   `protobuf:"bytes,1,opt,name=keyword,proto3" json:"keyword,omitempty"`
+	KeepLast             int32    `protobuf:"varint,2,opt,name=keep_last,proto3" json:"keep_last,omitempty"`
+	KeepDailyAfterDays   int32    `protobuf:"varint,3,opt,name=keep_daily_after_days,proto3" json:"keep_daily_after_days,omitempty"`
+	DeleteAfterDays      int32    `protobuf:"varint,4,opt,name=delete_after_days,proto3" json:"delete_after_days,omitempty"`
+	XXX_NoUnkeyedLiteral struct{} `json:"-"`
+	XXX_unrecognized     []byte   `json:"-"`
+	XXX_sizecache        int32    `json:"-"`
+}
+
+func (m *CompactionPolicy) Reset()         { *m = CompactionPolicy{} }
+func (m *CompactionPolicy) String() string { return proto.CompactTextString(m) }
+func (*CompactionPolicy) ProtoMessage()    {}
+func (*CompactionPolicy) Descriptor() ([]byte, []int) {
+	return fileDescriptor_ai_decision_service_e434f06e2ed91ff7, []int{16}
+}
+func (m *CompactionPolicy) XXX_Unmarshal(b []byte) error {
+	return xxx_messageInfo_CompactionPolicy.Unmarshal(m, b)
+}
+func (m *CompactionPolicy) XXX_Marshal(b []byte, deterministic bool) ([]byte, error) {
+	return xxx_messageInfo_CompactionPolicy.Marshal(b, m, deterministic)
+}
+func (dst *CompactionPolicy) XXX_Merge(src proto.Message) {
+	xxx_messageInfo_CompactionPolicy.Merge(dst, src)
+}
+func (m *CompactionPolicy) XXX_Size() int {
+	return xxx_messageInfo_CompactionPolicy.Size(m)
+}
+func (m *CompactionPolicy) XXX_DiscardUnknown() {
+	xxx_messageInfo_CompactionPolicy.DiscardUnknown(m)
+}
+
+var xxx_messageInfo_CompactionPolicy proto.InternalMessageInfo
+
+func (m *CompactionPolicy) GetKeyword() string {
+	if m != nil {
+		return m.Keyword
+	}
+	return ""
+}
+
+func (m *CompactionPolicy) GetKeepLast() int32 {
+	if m != nil {
+		return m.KeepLast
+	}
+	return 0
+}
+
+func (m *CompactionPolicy) GetKeepDailyAfterDays() int32 {
+	if m != nil {
+		return m.KeepDailyAfterDays
+	}
+	return 0
+}
+
+func (m *CompactionPolicy) GetDeleteAfterDays() int32 {
+	if m != nil {
+		return m.DeleteAfterDays
+	}
+	return 0
+}
+
+type CompactionPolicyListRequest struct {
+	XXX_NoUnkeyedLiteral struct{} `json:"-"`
+	XXX_unrecognized     []byte   `json:"-"`
+	XXX_sizecache        int32    `json:"-"`
+}
+
+func (m *CompactionPolicyListRequest) Reset()         { *m = CompactionPolicyListRequest{} }
+func (m *CompactionPolicyListRequest) String() string { return proto.CompactTextString(m) }
+func (*CompactionPolicyListRequest) ProtoMessage()    {}
+func (*CompactionPolicyListRequest) Descriptor() ([]byte, []int) {
+	return fileDescriptor_ai_decision_service_e434f06e2ed91ff7, []int{17}
+}
+func (m *CompactionPolicyListRequest) XXX_Unmarshal(b []byte) error {
+	return xxx_messageInfo_CompactionPolicyListRequest.Unmarshal(m, b)
+}
+func (m *CompactionPolicyListRequest) XXX_Marshal(b []byte, deterministic bool) ([]byte, error) {
+	return xxx_messageInfo_CompactionPolicyListRequest.Marshal(b, m, deterministic)
+}
+func (dst *CompactionPolicyListRequest) XXX_Merge(src proto.Message) {
+	xxx_messageInfo_CompactionPolicyListRequest.Merge(dst, src)
+}
+func (m *CompactionPolicyListRequest) XXX_Size() int {
+	return xxx_messageInfo_CompactionPolicyListRequest.Size(m)
+}
+func (m *CompactionPolicyListRequest) XXX_DiscardUnknown() {
+	xxx_messageInfo_CompactionPolicyListRequest.DiscardUnknown(m)
+}
+
+var xxx_messageInfo_CompactionPolicyListRequest proto.InternalMessageInfo
+
+type CompactRequest struct {
+	// keyword to compact, all keywords with a compaction policy if empty
+	Keyword string `protobuf:"bytes,1,opt,name=keyword,proto3" json:"keyword,omitempty"`
+	// dry_run reports the states that would be deleted without deleting them
+	DryRun               bool     `protobuf:"varint,2,opt,name=dry_run,proto3" json:"dry_run,omitempty"`
+	XXX_NoUnkeyedLiteral struct{} `json:"-"`
+	XXX_unrecognized     []byte   `json:"-"`
+	XXX_sizecache        int32    `json:"-"`
+}
+
+func (m *CompactRequest) Reset()         { *m = CompactRequest{} }
+func (m *CompactRequest) String() string { return proto.CompactTextString(m) }
+func (*CompactRequest) ProtoMessage()    {}
+func (*CompactRequest) Descriptor() ([]byte, []int) {
+	return fileDescriptor_ai_decision_service_e434f06e2ed91ff7, []int{18}
+}
+func (m *CompactRequest) XXX_Unmarshal(b []byte) error {
+	return xxx_messageInfo_CompactRequest.Unmarshal(m, b)
+}
+func (m *CompactRequest) XXX_Marshal(b []byte, deterministic bool) ([]byte, error) {
+	return xxx_messageInfo_CompactRequest.Marshal(b, m, deterministic)
+}
+func (dst *CompactRequest) XXX_Merge(src proto.Message) {
+	xxx_messageInfo_CompactRequest.Merge(dst, src)
+}
+func (m *CompactRequest) XXX_Size() int {
+	return xxx_messageInfo_CompactRequest.Size(m)
+}
+func (m *CompactRequest) XXX_DiscardUnknown() {
+	xxx_messageInfo_CompactRequest.DiscardUnknown(m)
+}
+
+var xxx_messageInfo_CompactRequest proto.InternalMessageInfo
+
+func (m *CompactRequest) GetKeyword() string {
+	if m != nil {
+		return m.Keyword
+	}
+	return ""
+}
+
+func (m *CompactRequest) GetDryRun() bool {
+	if m != nil {
+		return m.DryRun
+	}
+	return false
+}
+
+// Compaction reports the number of states deleted for an app and keyword
+type Compaction struct {
+	AppId                int32    `protobuf:"varint,1,opt,name=app_id,proto3" json:"app_id,omitempty"`
+	Keyword              string   `protobuf:"bytes,2,opt,name=keyword,proto3" json:"keyword,omitempty"`
+	Deleted              int32    `protobuf:"varint,3,opt,name=deleted,proto3" json:"deleted,omitempty"`
+	XXX_NoUnkeyedLiteral struct{} `json:"-"`
+	XXX_unrecognized     []byte   `json:"-"`
+	XXX_sizecache        int32    `json:"-"`
+}
+
+func (m *Compaction) Reset()         { *m = Compaction{} }
+func (m *Compaction) String() string { return proto.CompactTextString(m) }
+func (*Compaction) ProtoMessage()    {}
+func (*Compaction) Descriptor() ([]byte, []int) {
+	return fileDescriptor_ai_decision_service_e434f06e2ed91ff7, []int{19}
+}
+func (m *Compaction) XXX_Unmarshal(b []byte) error {
+	return xxx_messageInfo_Compaction.Unmarshal(m, b)
+}
+func (m *Compaction) XXX_Marshal(b []byte, deterministic bool) ([]byte, error) {
+	return xxx_messageInfo_Compaction.Marshal(b, m, deterministic)
+}
+func (dst *Compaction) XXX_Merge(src proto.Message) {
+	xxx_messageInfo_Compaction.Merge(dst, src)
+}
+func (m *Compaction) XXX_Size() int {
+	return xxx_messageInfo_Compaction.Size(m)
+}
+func (m *Compaction) XXX_DiscardUnknown() {
+	xxx_messageInfo_Compaction.DiscardUnknown(m)
+}
+
+var xxx_messageInfo_Compaction proto.InternalMessageInfo
+
+func (m *Compaction) GetAppId() int32 {
+	if m != nil {
+		return m.AppId
+	}
+	return 0
+}
+
+func (m *Compaction) GetKeyword() string {
+	if m != nil {
+		return m.Keyword
+	}
+	return ""
+}
+
+func (m *Compaction) GetDeleted() int32 {
+	if m != nil {
+		return m.Deleted
+	}
+	return 0
+}
+
+// Lease is a named per-app lease serializing work on an app, e.g. pipeline runs.
+// Every acquisition issues a new fencing token greater than all previously issued tokens.
+type Lease struct {
+	AppId                int32                `protobuf:"varint,1,opt,name=app_id,proto3" json:"app_id,omitempty"`
+	Name                 string               `protobuf:"bytes,2,opt,name=name,proto3" json:"name,omitempty"`
+	Holder               string               `protobuf:"bytes,3,opt,name=holder,proto3" json:"holder,omitempty"`
+	FencingToken         int64                `protobuf:"varint,4,opt,name=fencing_token,proto3" json:"fencing_token,omitempty"`
+	ExpiresAt            *timestamp.Timestamp `protobuf:"bytes,5,opt,name=expires_at,proto3" json:"expires_at,omitempty"`
+	XXX_NoUnkeyedLiteral struct{}             `json:"-"`
+	XXX_unrecognized     []byte               `json:"-"`
+	XXX_sizecache        int32                `json:"-"`
+}
+
+func (m *Lease) Reset()         { *m = Lease{} }
+func (m *Lease) String() string { return proto.CompactTextString(m) }
+func (*Lease) ProtoMessage()    {}
+func (*Lease) Descriptor() ([]byte, []int) {
+	return fileDescriptor_ai_decision_service_e434f06e2ed91ff7, []int{20}
+}
+func (m *Lease) XXX_Unmarshal(b []byte) error {
+	return xxx_messageInfo_Lease.Unmarshal(m, b)
+}
+func (m *Lease) XXX_Marshal(b []byte, deterministic bool) ([]byte, error) {
+	return xxx_messageInfo_Lease.Marshal(b, m, deterministic)
+}
+func (dst *Lease) XXX_Merge(src proto.Message) {
+	xxx_messageInfo_Lease.Merge(dst, src)
+}
+func (m *Lease) XXX_Size() int {
+	return xxx_messageInfo_Lease.Size(m)
+}
+func (m *Lease) XXX_DiscardUnknown() {
+	xxx_messageInfo_Lease.DiscardUnknown(m)
+}
+
+var xxx_messageInfo_Lease proto.InternalMessageInfo
+
+func (m *Lease) GetAppId() int32 {
+	if m != nil {
+		return m.AppId
+	}
+	return 0
+}
+
+func (m *Lease) GetName() string {
+	if m != nil {
+		return m.Name
+	}
+	return ""
+}
+
+func (m *Lease) GetHolder() string {
+	if m != nil {
+		return m.Holder
+	}
+	return ""
+}
+
+func (m *Lease) GetFencingToken() int64 {
+	if m != nil {
+		return m.FencingToken
+	}
+	return 0
+}
+
+func (m *Lease) GetExpiresAt() *timestamp.Timestamp {
+	if m != nil {
+		return m.ExpiresAt
+	}
+	return nil
+}
+
+type LeaseAcquireRequest struct {
+	AppId int32  `protobuf:"varint,1,opt,name=app_id,proto3" json:"app_id,omitempty"`
+	Name  string `protobuf:"bytes,2,opt,name=name,proto3" json:"name,omitempty"`
+	// holder identifies the caller, a holder may reacquire its own unexpired lease
+	Holder               string   `protobuf:"bytes,3,opt,name=holder,proto3" json:"holder,omitempty"`
+	TtlSeconds           int32    `protobuf:"varint,4,opt,name=ttl_seconds,proto3" json:"ttl_seconds,omitempty"`
+	XXX_NoUnkeyedLiteral struct{} `json:"-"`
+	XXX_unrecognized     []byte   `json:"-"`
+	XXX_sizecache        int32    `json:"-"`
+}
+
+func (m *LeaseAcquireRequest) Reset()         { *m = LeaseAcquireRequest{} }
+func (m *LeaseAcquireRequest) String() string { return proto.CompactTextString(m) }
+func (*LeaseAcquireRequest) ProtoMessage()    {}
+func (*LeaseAcquireRequest) Descriptor() ([]byte, []int) {
+	return fileDescriptor_ai_decision_service_e434f06e2ed91ff7, []int{21}
+}
+func (m *LeaseAcquireRequest) XXX_Unmarshal(b []byte) error {
+	return xxx_messageInfo_LeaseAcquireRequest.Unmarshal(m, b)
+}
+func (m *LeaseAcquireRequest) XXX_Marshal(b []byte, deterministic bool) ([]byte, error) {
+	return xxx_messageInfo_LeaseAcquireRequest.Marshal(b, m, deterministic)
+}
+func (dst *LeaseAcquireRequest) XXX_Merge(src proto.Message) {
+	xxx_messageInfo_LeaseAcquireRequest.Merge(dst, src)
+}
+func (m *LeaseAcquireRequest) XXX_Size() int {
+	return xxx_messageInfo_LeaseAcquireRequest.Size(m)
+}
+func (m *LeaseAcquireRequest) XXX_DiscardUnknown() {
+	xxx_messageInfo_LeaseAcquireRequest.DiscardUnknown(m)
+}
+
+var xxx_messageInfo_LeaseAcquireRequest proto.InternalMessageInfo
+
+func (m *LeaseAcquireRequest) GetAppId() int32 {
+	if m != nil {
+		return m.AppId
+	}
+	return 0
+}
+
+func (m *LeaseAcquireRequest) GetName() string {
+	if m != nil {
+		return m.Name
+	}
+	return ""
+}
+
+func (m *LeaseAcquireRequest) GetHolder() string {
+	if m != nil {
+		return m.Holder
+	}
+	return ""
+}
+
+func (m *LeaseAcquireRequest) GetTtlSeconds() int32 {
+	if m != nil {
+		return m.TtlSeconds
+	}
+	return 0
+}
+
+type LeaseRenewRequest struct {
+	AppId                int32    `protobuf:"varint,1,opt,name=app_id,proto3" json:"app_id,omitempty"`
+	Name                 string   `protobuf:"bytes,2,opt,name=name,proto3" json:"name,omitempty"`
+	Holder               string   `protobuf:"bytes,3,opt,name=holder,proto3" json:"holder,omitempty"`
+	FencingToken         int64    `protobuf:"varint,4,opt,name=fencing_token,proto3" json:"fencing_token,omitempty"`
+	TtlSeconds           int32    `protobuf:"varint,5,opt,name=ttl_seconds,proto3" json:"ttl_seconds,omitempty"`
+	XXX_NoUnkeyedLiteral struct{} `json:"-"`
+	XXX_unrecognized     []byte   `json:"-"`
+	XXX_sizecache        int32    `json:"-"`
+}
+
+func (m *LeaseRenewRequest) Reset()         { *m = LeaseRenewRequest{} }
+func (m *LeaseRenewRequest) String() string { return proto.CompactTextString(m) }
+func (*LeaseRenewRequest) ProtoMessage()    {}
+func (*LeaseRenewRequest) Descriptor() ([]byte, []int) {
+	return fileDescriptor_ai_decision_service_e434f06e2ed91ff7, []int{22}
+}
+func (m *LeaseRenewRequest) XXX_Unmarshal(b []byte) error {
+	return xxx_messageInfo_LeaseRenewRequest.Unmarshal(m, b)
+}
+func (m *LeaseRenewRequest) XXX_Marshal(b []byte, deterministic bool) ([]byte, error) {
+	return xxx_messageInfo_LeaseRenewRequest.Marshal(b, m, deterministic)
+}
+func (dst *LeaseRenewRequest) XXX_Merge(src proto.Message) {
+	xxx_messageInfo_LeaseRenewRequest.Merge(dst, src)
+}
+func (m *LeaseRenewRequest) XXX_Size() int {
+	return xxx_messageInfo_LeaseRenewRequest.Size(m)
+}
+func (m *LeaseRenewRequest) XXX_DiscardUnknown() {
+	xxx_messageInfo_LeaseRenewRequest.DiscardUnknown(m)
+}
+
+var xxx_messageInfo_LeaseRenewRequest proto.InternalMessageInfo
+
+func (m *LeaseRenewRequest) GetAppId() int32 {
+	if m != nil {
+		return m.AppId
+	}
+	return 0
+}
+
+func (m *LeaseRenewRequest) GetName() string {
+	if m != nil {
+		return m.Name
+	}
+	return ""
+}
+
+func (m *LeaseRenewRequest) GetHolder() string {
+	if m != nil {
+		return m.Holder
+	}
+	return ""
+}
+
+func (m *LeaseRenewRequest) GetFencingToken() int64 {
+	if m != nil {
+		return m.FencingToken
+	}
+	return 0
+}
+
+func (m *LeaseRenewRequest) GetTtlSeconds() int32 {
+	if m != nil {
+		return m.TtlSeconds
+	}
+	return 0
+}
+
+type LeaseReleaseRequest struct {
+	AppId                int32    `protobuf:"varint,1,opt,name=app_id,proto3" json:"app_id,omitempty"`
+	Name                 string   `protobuf:"bytes,2,opt,name=name,proto3" json:"name,omitempty"`
+	Holder               string   `protobuf:"bytes,3,opt,name=holder,proto3" json:"holder,omitempty"`
+	FencingToken         int64    `protobuf:"varint,4,opt,name=fencing_token,proto3" json:"fencing_token,omitempty"`
+	XXX_NoUnkeyedLiteral struct{} `json:"-"`
+	XXX_unrecognized     []byte   `json:"-"`
+	XXX_sizecache        int32    `json:"-"`
+}
+
+func (m *LeaseReleaseRequest) Reset()         { *m = LeaseReleaseRequest{} }
+func (m *LeaseReleaseRequest) String() string { return proto.CompactTextString(m) }
+func (*LeaseReleaseRequest) ProtoMessage()    {}
+func (*LeaseReleaseRequest) Descriptor() ([]byte, []int) {
+	return fileDescriptor_ai_decision_service_e434f06e2ed91ff7, []int{23}
+}
+func (m *LeaseReleaseRequest) XXX_Unmarshal(b []byte) error {
+	return xxx_messageInfo_LeaseReleaseRequest.Unmarshal(m, b)
+}
+func (m *LeaseReleaseRequest) XXX_Marshal(b []byte, deterministic bool) ([]byte, error) {
+	return xxx_messageInfo_LeaseReleaseRequest.Marshal(b, m, deterministic)
+}
+func (dst *LeaseReleaseRequest) XXX_Merge(src proto.Message) {
+	xxx_messageInfo_LeaseReleaseRequest.Merge(dst, src)
+}
+func (m *LeaseReleaseRequest) XXX_Size() int {
+	return xxx_messageInfo_LeaseReleaseRequest.Size(m)
+}
+func (m *LeaseReleaseRequest) XXX_DiscardUnknown() {
+	xxx_messageInfo_LeaseReleaseRequest.DiscardUnknown(m)
+}
+
+var xxx_messageInfo_LeaseReleaseRequest proto.InternalMessageInfo
+
+func (m *LeaseReleaseRequest) GetAppId() int32 {
+	if m != nil {
+		return m.AppId
+	}
+	return 0
+}
+
+func (m *LeaseReleaseRequest) GetName() string {
+	if m != nil {
+		return m.Name
+	}
+	return ""
+}
+
+func (m *LeaseReleaseRequest) GetHolder() string {
+	if m != nil {
+		return m.Holder
+	}
+	return ""
+}
+
+func (m *LeaseReleaseRequest) GetFencingToken() int64 {
+	if m != nil {
+		return m.FencingToken
+	}
+	return 0
+}
+
+type LeaseReleaseResponse struct {
+	XXX_NoUnkeyedLiteral struct{} `json:"-"`
+	XXX_unrecognized     []byte   `json:"-"`
+	XXX_sizecache        int32    `json:"-"`
+}
+
+func (m *LeaseReleaseResponse) Reset()         { *m = LeaseReleaseResponse{} }
+func (m *LeaseReleaseResponse) String() string { return proto.CompactTextString(m) }
+func (*LeaseReleaseResponse) ProtoMessage()    {}
+func (*LeaseReleaseResponse) Descriptor() ([]byte, []int) {
+	return fileDescriptor_ai_decision_service_e434f06e2ed91ff7, []int{24}
+}
+func (m *LeaseReleaseResponse) XXX_Unmarshal(b []byte) error {
+	return xxx_messageInfo_LeaseReleaseResponse.Unmarshal(m, b)
+}
+func (m *LeaseReleaseResponse) XXX_Marshal(b []byte, deterministic bool) ([]byte, error) {
+	return xxx_messageInfo_LeaseReleaseResponse.Marshal(b, m, deterministic)
+}
+func (dst *LeaseReleaseResponse) XXX_Merge(src proto.Message) {
+	xxx_messageInfo_LeaseReleaseResponse.Merge(dst, src)
+}
+func (m *LeaseReleaseResponse) XXX_Size() int {
+	return xxx_messageInfo_LeaseReleaseResponse.Size(m)
+}
+func (m *LeaseReleaseResponse) XXX_DiscardUnknown() {
+	xxx_messageInfo_LeaseReleaseResponse.DiscardUnknown(m)
+}
+
+var xxx_messageInfo_LeaseReleaseResponse proto.InternalMessageInfo
+
+// Run is an analytics pipeline run for an app. Messages and states created during the run are tagged with its id.
+type Run struct {
+	Id       int32     `protobuf:"varint,1,opt,name=id,proto3" json:"id,omitempty"`
+	AppId    int32     `protobuf:"varint,2,opt,name=app_id,proto3" json:"app_id,omitempty"`
+	Pipeline string    `protobuf:"bytes,3,opt,name=pipeline,proto3" json:"pipeline,omitempty"`
+	Status   RunStatus `protobuf:"varint,4,opt,name=status,proto3,enum=callstats.ai_decision.RunStatus" json:"status,omitempty"`
+	// error of failed runs
+	Error      string               `protobuf:"bytes,5,opt,name=error,proto3" json:"error,omitempty"`
+	StartedAt  *timestamp.Timestamp `protobuf:"bytes,6,opt,name=started_at,proto3" json:"started_at,omitempty"`
+	FinishedAt *timestamp.Timestamp `protobuf:"bytes,7,opt,name=finished_at,proto3" json:"finished_at,omitempty"`
+	// counters reported by the pipeline, e.g. processed conferences
+	Counters map[string]int64 `protobuf:"bytes,8,rep,name=counters,proto3" json:"counters,omitempty" protobuf_key:"bytes,1,opt,name=key,proto3" protobuf_val:"varint,2,opt,name=value,proto3"`
+	// number of messages and states tagged with the run
+	MessageCount         int32    `protobuf:"varint,9,opt,name=message_count,proto3" json:"message_count,omitempty"`
+	StateCount           int32    `protobuf:"varint,10,opt,name=state_count,proto3" json:"state_count,omitempty"`
+	XXX_NoUnkeyedLiteral struct{} `json:"-"`
+	XXX_unrecognized     []byte   `json:"-"`
+	XXX_sizecache        int32    `json:"-"`
+}
+
+func (m *Run) Reset()         { *m = Run{} }
+func (m *Run) String() string { return proto.CompactTextString(m) }
+func (*Run) ProtoMessage()    {}
+func (*Run) Descriptor() ([]byte, []int) {
+	return fileDescriptor_ai_decision_service_e434f06e2ed91ff7, []int{25}
+}
+func (m *Run) XXX_Unmarshal(b []byte) error {
+	return xxx_messageInfo_Run.Unmarshal(m, b)
+}
+func (m *Run) XXX_Marshal(b []byte, deterministic bool) ([]byte, error) {
+	return xxx_messageInfo_Run.Marshal(b, m, deterministic)
+}
+func (dst *Run) XXX_Merge(src proto.Message) {
+	xxx_messageInfo_Run.Merge(dst, src)
+}
+func (m *Run) XXX_Size() int {
+	return xxx_messageInfo_Run.Size(m)
+}
+func (m *Run) XXX_DiscardUnknown() {
+	xxx_messageInfo_Run.DiscardUnknown(m)
+}
+
+var xxx_messageInfo_Run proto.InternalMessageInfo
+
+func (m *Run) GetId() int32 {
+	if m != nil {
+		return m.Id
+	}
+	return 0
+}
+
+func (m *Run) GetAppId() int32 {
+	if m != nil {
+		return m.AppId
+	}
+	return 0
+}
+
+func (m *Run) GetPipeline() string {
+	if m != nil {
+		return m.Pipeline
+	}
+	return ""
+}
+
+func (m *Run) GetStatus() RunStatus {
+	if m != nil {
+		return m.Status
+	}
+	return RunStatus_RUN_STATUS_UNSPECIFIED
+}
+
+func (m *Run) GetError() string {
+	if m != nil {
+		return m.Error
+	}
+	return ""
+}
+
+func (m *Run) GetStartedAt() *timestamp.Timestamp {
+	if m != nil {
+		return m.StartedAt
+	}
+	return nil
+}
+
+func (m *Run) GetFinishedAt() *timestamp.Timestamp {
+	if m != nil {
+		return m.FinishedAt
+	}
+	return nil
+}
+
+func (m *Run) GetCounters() map[string]int64 {
+	if m != nil {
+		return m.Counters
+	}
+	return nil
+}
+
+func (m *Run) GetMessageCount() int32 {
+	if m != nil {
+		return m.MessageCount
+	}
+	return 0
+}
+
+func (m *Run) GetStateCount() int32 {
+	if m != nil {
+		return m.StateCount
+	}
+	return 0
+}
+
+type RunStartRequest struct {
+	AppId                int32    `protobuf:"varint,1,opt,name=app_id,proto3" json:"app_id,omitempty"`
+	Pipeline             string   `protobuf:"bytes,2,opt,name=pipeline,proto3" json:"pipeline,omitempty"`
+	XXX_NoUnkeyedLiteral struct{} `json:"-"`
+	XXX_unrecognized     []byte   `json:"-"`
+	XXX_sizecache        int32    `json:"-"`
+}
+
+func (m *RunStartRequest) Reset()         { *m = RunStartRequest{} }
+func (m *RunStartRequest) String() string { return proto.CompactTextString(m) }
+func (*RunStartRequest) ProtoMessage()    {}
+func (*RunStartRequest) Descriptor() ([]byte, []int) {
+	return fileDescriptor_ai_decision_service_e434f06e2ed91ff7, []int{26}
+}
+func (m *RunStartRequest) XXX_Unmarshal(b []byte) error {
+	return xxx_messageInfo_RunStartRequest.Unmarshal(m, b)
+}
+func (m *RunStartRequest) XXX_Marshal(b []byte, deterministic bool) ([]byte, error) {
+	return xxx_messageInfo_RunStartRequest.Marshal(b, m, deterministic)
+}
+func (dst *RunStartRequest) XXX_Merge(src proto.Message) {
+	xxx_messageInfo_RunStartRequest.Merge(dst, src)
+}
+func (m *RunStartRequest) XXX_Size() int {
+	return xxx_messageInfo_RunStartRequest.Size(m)
+}
+func (m *RunStartRequest) XXX_DiscardUnknown() {
+	xxx_messageInfo_RunStartRequest.DiscardUnknown(m)
+}
+
+var xxx_messageInfo_RunStartRequest proto.InternalMessageInfo
+
+func (m *RunStartRequest) GetAppId() int32 {
+	if m != nil {
+		return m.AppId
+	}
+	return 0
+}
+
+func (m *RunStartRequest) GetPipeline() string {
+	if m != nil {
+		return m.Pipeline
+	}
+	return ""
+}
+
+type RunFinishRequest struct {
+	Id int32 `protobuf:"varint,1,opt,name=id,proto3" json:"id,omitempty"`
+	// status must be RUN_SUCCEEDED or RUN_FAILED
+	Status               RunStatus        `protobuf:"varint,2,opt,name=status,proto3,enum=callstats.ai_decision.RunStatus" json:"status,omitempty"`
+	Error                string           `protobuf:"bytes,3,opt,name=error,proto3" json:"error,omitempty"`
+	Counters             map[string]int64 `protobuf:"bytes,4,rep,name=counters,proto3" json:"counters,omitempty" protobuf_key:"bytes,1,opt,name=key,proto3" protobuf_val:"varint,2,opt,name=value,proto3"`
+	XXX_NoUnkeyedLiteral struct{}         `json:"-"`
+	XXX_unrecognized     []byte           `json:"-"`
+	XXX_sizecache        int32            `json:"-"`
+}
+
+func (m *RunFinishRequest) Reset()         { *m = RunFinishRequest{} }
+func (m *RunFinishRequest) String() string { return proto.CompactTextString(m) }
+func (*RunFinishRequest) ProtoMessage()    {}
+func (*RunFinishRequest) Descriptor() ([]byte, []int) {
+	return fileDescriptor_ai_decision_service_e434f06e2ed91ff7, []int{27}
+}
+func (m *RunFinishRequest) XXX_Unmarshal(b []byte) error {
+	return xxx_messageInfo_RunFinishRequest.Unmarshal(m, b)
+}
+func (m *RunFinishRequest) XXX_Marshal(b []byte, deterministic bool) ([]byte, error) {
+	return xxx_messageInfo_RunFinishRequest.Marshal(b, m, deterministic)
+}
+func (dst *RunFinishRequest) XXX_Merge(src proto.Message) {
+	xxx_messageInfo_RunFinishRequest.Merge(dst, src)
+}
+func (m *RunFinishRequest) XXX_Size() int {
+	return xxx_messageInfo_RunFinishRequest.Size(m)
+}
+func (m *RunFinishRequest) XXX_DiscardUnknown() {
+	xxx_messageInfo_RunFinishRequest.DiscardUnknown(m)
+}
+
+var xxx_messageInfo_RunFinishRequest proto.InternalMessageInfo
+
+func (m *RunFinishRequest) GetId() int32 {
+	if m != nil {
+		return m.Id
+	}
+	return 0
+}
+
+func (m *RunFinishRequest) GetStatus() RunStatus {
+	if m != nil {
+		return m.Status
+	}
+	return RunStatus_RUN_STATUS_UNSPECIFIED
+}
+
+func (m *RunFinishRequest) GetError() string {
+	if m != nil {
+		return m.Error
+	}
+	return ""
+}
+
+func (m *RunFinishRequest) GetCounters() map[string]int64 {
+	if m != nil {
+		return m.Counters
+	}
+	return nil
+}
+
+type RunListRequest struct {
+	AppId int32 `protobuf:"varint,1,opt,name=app_id,proto3" json:"app_id,omitempty"`
+	// filters, empty or unspecified to include all
+	Pipeline string    `protobuf:"bytes,2,opt,name=pipeline,proto3" json:"pipeline,omitempty"`
+	Status   RunStatus `protobuf:"varint,3,opt,name=status,proto3,enum=callstats.ai_decision.RunStatus" json:"status,omitempty"`
+	// start time range to include
+	StartedFrom *timestamp.Timestamp `protobuf:"bytes,4,opt,name=started_from,proto3" json:"started_from,omitempty"`
+	StartedTo   *timestamp.Timestamp `protobuf:"bytes,5,opt,name=started_to,proto3" json:"started_to,omitempty"`
+	// without_messages only includes finished runs that produced no messages
+	WithoutMessages      bool     `protobuf:"varint,6,opt,name=without_messages,proto3" json:"without_messages,omitempty"`
+	XXX_NoUnkeyedLiteral struct{} `json:"-"`
+	XXX_unrecognized     []byte   `json:"-"`
+	XXX_sizecache        int32    `json:"-"`
+}
+
+func (m *RunListRequest) Reset()         { *m = RunListRequest{} }
+func (m *RunListRequest) String() string { return proto.CompactTextString(m) }
+func (*RunListRequest) ProtoMessage()    {}
+func (*RunListRequest) Descriptor() ([]byte, []int) {
+	return fileDescriptor_ai_decision_service_e434f06e2ed91ff7, []int{28}
+}
+func (m *RunListRequest) XXX_Unmarshal(b []byte) error {
+	return xxx_messageInfo_RunListRequest.Unmarshal(m, b)
+}
+func (m *RunListRequest) XXX_Marshal(b []byte, deterministic bool) ([]byte, error) {
+	return xxx_messageInfo_RunListRequest.Marshal(b, m, deterministic)
+}
+func (dst *RunListRequest) XXX_Merge(src proto.Message) {
+	xxx_messageInfo_RunListRequest.Merge(dst, src)
+}
+func (m *RunListRequest) XXX_Size() int {
+	return xxx_messageInfo_RunListRequest.Size(m)
+}
+func (m *RunListRequest) XXX_DiscardUnknown() {
+	xxx_messageInfo_RunListRequest.DiscardUnknown(m)
+}
+
+var xxx_messageInfo_RunListRequest proto.InternalMessageInfo
+
+func (m *RunListRequest) GetAppId() int32 {
+	if m != nil {
+		return m.AppId
+	}
+	return 0
+}
+
+func (m *RunListRequest) GetPipeline() string {
+	if m != nil {
+		return m.Pipeline
+	}
+	return ""
+}
+
+func (m *RunListRequest) GetStatus() RunStatus {
+	if m != nil {
+		return m.Status
+	}
+	return RunStatus_RUN_STATUS_UNSPECIFIED
+}
+
+func (m *RunListRequest) GetStartedFrom() *timestamp.Timestamp {
+	if m != nil {
+		return m.StartedFrom
+	}
+	return nil
+}
+
+func (m *RunListRequest) GetStartedTo() *timestamp.Timestamp {
+	if m != nil {
+		return m.StartedTo
+	}
+	return nil
+}
+
+func (m *RunListRequest) GetWithoutMessages() bool {
+	if m != nil {
+		return m.WithoutMessages
+	}
+	return false
+}
+
+type RunLastSuccessfulRequest struct {
+	// app to get the last successful run of, 0 for all apps
+	AppId int32 `protobuf:"varint,1,opt,name=app_id,proto3" json:"app_id,omitempty"`
+	// pipeline filter, empty to include all
+	Pipeline             string   `protobuf:"bytes,2,opt,name=pipeline,proto3" json:"pipeline,omitempty"`
+	XXX_NoUnkeyedLiteral struct{} `json:"-"`
+	XXX_unrecognized     []byte   `json:"-"`
+	XXX_sizecache        int32    `json:"-"`
+}
+
+func (m *RunLastSuccessfulRequest) Reset()         { *m = RunLastSuccessfulRequest{} }
+func (m *RunLastSuccessfulRequest) String() string { return proto.CompactTextString(m) }
+func (*RunLastSuccessfulRequest) ProtoMessage()    {}
+func (*RunLastSuccessfulRequest) Descriptor() ([]byte, []int) {
+	return fileDescriptor_ai_decision_service_e434f06e2ed91ff7, []int{29}
+}
+func (m *RunLastSuccessfulRequest) XXX_Unmarshal(b []byte) error {
+	return xxx_messageInfo_RunLastSuccessfulRequest.Unmarshal(m, b)
+}
+func (m *RunLastSuccessfulRequest) XXX_Marshal(b []byte, deterministic bool) ([]byte, error) {
+	return xxx_messageInfo_RunLastSuccessfulRequest.Marshal(b, m, deterministic)
+}
+func (dst *RunLastSuccessfulRequest) XXX_Merge(src proto.Message) {
+	xxx_messageInfo_RunLastSuccessfulRequest.Merge(dst, src)
+}
+func (m *RunLastSuccessfulRequest) XXX_Size() int {
+	return xxx_messageInfo_RunLastSuccessfulRequest.Size(m)
+}
+func (m *RunLastSuccessfulRequest) XXX_DiscardUnknown() {
+	xxx_messageInfo_RunLastSuccessfulRequest.DiscardUnknown(m)
+}
+
+var xxx_messageInfo_RunLastSuccessfulRequest proto.InternalMessageInfo
+
+func (m *RunLastSuccessfulRequest) GetAppId() int32 {
+	if m != nil {
+		return m.AppId
+	}
+	return 0
+}
+
+func (m *RunLastSuccessfulRequest) GetPipeline() string {
+	if m != nil {
+		return m.Pipeline
+	}
+	return ""
+}
+
+// AuditEvent is a mutation of templates, messages or states, recorded in the transaction of the mutation
+type AuditEvent struct {
+	Id         int64                `protobuf:"varint,1,opt,name=id,proto3" json:"id,omitempty"`
+	OccurredAt *timestamp.Timestamp `protobuf:"bytes,2,opt,name=occurred_at,proto3" json:"occurred_at,omitempty"`
+	// actor of the mutation: the subject, user:<id> or app:<id> of the token of the call, system for the service
+	// itself, or the user running the command line
+	Actor string `protobuf:"bytes,3,opt,name=actor,proto3" json:"actor,omitempty"`
+	// full method of the call, or cli:<flag> for the command line
+	Rpc string `protobuf:"bytes,4,opt,name=rpc,proto3" json:"rpc,omitempty"`
+	// app of the mutated rows, 0 for templates
+	AppId int32 `protobuf:"varint,5,opt,name=app_id,proto3" json:"app_id,omitempty"`
+	// template.create, message.create, message.delete, state.save, state.delete or state.compact
+	Action string `protobuf:"bytes,6,opt,name=action,proto3" json:"action,omitempty"`
+	// type:version of templates, id of messages, keyword@saved_at of states, keyword of compactions
+	Target string `protobuf:"bytes,7,opt,name=target,proto3" json:"target,omitempty"`
+	// number of mutated rows, the number of deleted states of compactions
+	Count int32 `protobuf:"varint,8,opt,name=count,proto3" json:"count,omitempty"`
+	// hex encoded SHA-256 of the data before and after the mutation, empty if created or deleted
+	BeforeHash string `protobuf:"bytes,9,opt,name=before_hash,proto3" json:"before_hash,omitempty"`
+	AfterHash  string `protobuf:"bytes,10,opt,name=after_hash,proto3" json:"after_hash,omitempty"`
+	// reason sent with the call in the x-audit-reason metadata, or with the -reason flag of the command line
+	Reason               string   `protobuf:"bytes,11,opt,name=reason,proto3" json:"reason,omitempty"`
+	XXX_NoUnkeyedLiteral struct{} `json:"-"`
+	XXX_unrecognized     []byte   `json:"-"`
+	XXX_sizecache        int32    `json:"-"`
+}
+
+func (m *AuditEvent) Reset()         { *m = AuditEvent{} }
+func (m *AuditEvent) String() string { return proto.CompactTextString(m) }
+func (*AuditEvent) ProtoMessage()    {}
+func (*AuditEvent) Descriptor() ([]byte, []int) {
+	return fileDescriptor_ai_decision_service_e434f06e2ed91ff7, []int{30}
+}
+func (m *AuditEvent) XXX_Unmarshal(b []byte) error {
+	return xxx_messageInfo_AuditEvent.Unmarshal(m, b)
+}
+func (m *AuditEvent) XXX_Marshal(b []byte, deterministic bool) ([]byte, error) {
+	return xxx_messageInfo_AuditEvent.Marshal(b, m, deterministic)
+}
+func (dst *AuditEvent) XXX_Merge(src proto.Message) {
+	xxx_messageInfo_AuditEvent.Merge(dst, src)
+}
+func (m *AuditEvent) XXX_Size() int {
+	return xxx_messageInfo_AuditEvent.Size(m)
+}
+func (m *AuditEvent) XXX_DiscardUnknown() {
+	xxx_messageInfo_AuditEvent.DiscardUnknown(m)
+}
+
+var xxx_messageInfo_AuditEvent proto.InternalMessageInfo
+
+func (m *AuditEvent) GetId() int64 {
+	if m != nil {
+		return m.Id
+	}
+	return 0
+}
+
+func (m *AuditEvent) GetOccurredAt() *timestamp.Timestamp {
+	if m != nil {
+		return m.OccurredAt
+	}
+	return nil
+}
+
+func (m *AuditEvent) GetActor() string {
+	if m != nil {
+		return m.Actor
+	}
+	return ""
+}
+
+func (m *AuditEvent) GetRpc() string {
+	if m != nil {
+		return m.Rpc
+	}
+	return ""
+}
+
+func (m *AuditEvent) GetAppId() int32 {
+	if m != nil {
+		return m.AppId
+	}
+	return 0
+}
+
+func (m *AuditEvent) GetAction() string {
+	if m != nil {
+		return m.Action
+	}
+	return ""
+}
+
+func (m *AuditEvent) GetTarget() string {
+	if m != nil {
+		return m.Target
+	}
+	return ""
+}
+
+func (m *AuditEvent) GetCount() int32 {
+	if m != nil {
+		return m.Count
+	}
+	return 0
+}
+
+func (m *AuditEvent) GetBeforeHash() string {
+	if m != nil {
+		return m.BeforeHash
+	}
+	return ""
+}
+
+func (m *AuditEvent) GetAfterHash() string {
+	if m != nil {
+		return m.AfterHash
+	}
+	return ""
+}
+
+func (m *AuditEvent) GetReason() string {
+	if m != nil {
+		return m.Reason
+	}
+	return ""
+}
+
+type AuditEventListRequest struct {
+	// app to list the events of, 0 for all apps
+	AppId int32 `protobuf:"varint,1,opt,name=app_id,proto3" json:"app_id,omitempty"`
+	// filters, empty to include all
+	Actor  string `protobuf:"bytes,2,opt,name=actor,proto3" json:"actor,omitempty"`
+	Action string `protobuf:"bytes,3,opt,name=action,proto3" json:"action,omitempty"`
+	Target string `protobuf:"bytes,4,opt,name=target,proto3" json:"target,omitempty"`
+	// occurrence time range to include
+	OccurredFrom *timestamp.Timestamp `protobuf:"bytes,5,opt,name=occurred_from,proto3" json:"occurred_from,omitempty"`
+	OccurredTo   *timestamp.Timestamp `protobuf:"bytes,6,opt,name=occurred_to,proto3" json:"occurred_to,omitempty"`
+	// newest events to list, 0 for all
+	Limit                int32    `protobuf:"varint,7,opt,name=limit,proto3" json:"limit,omitempty"`
+	XXX_NoUnkeyedLiteral struct{} `json:"-"`
+	XXX_unrecognized     []byte   `json:"-"`
+	XXX_sizecache        int32    `json:"-"`
+}
+
+func (m *AuditEventListRequest) Reset()         { *m = AuditEventListRequest{} }
+func (m *AuditEventListRequest) String() string { return proto.CompactTextString(m) }
+func (*AuditEventListRequest) ProtoMessage()    {}
+func (*AuditEventListRequest) Descriptor() ([]byte, []int) {
+	return fileDescriptor_ai_decision_service_e434f06e2ed91ff7, []int{31}
+}
+func (m *AuditEventListRequest) XXX_Unmarshal(b []byte) error {
+	return xxx_messageInfo_AuditEventListRequest.Unmarshal(m, b)
+}
+func (m *AuditEventListRequest) XXX_Marshal(b []byte, deterministic bool) ([]byte, error) {
+	return xxx_messageInfo_AuditEventListRequest.Marshal(b, m, deterministic)
+}
+func (dst *AuditEventListRequest) XXX_Merge(src proto.Message) {
+	xxx_messageInfo_AuditEventListRequest.Merge(dst, src)
+}
+func (m *AuditEventListRequest) XXX_Size() int {
+	return xxx_messageInfo_AuditEventListRequest.Size(m)
+}
+func (m *AuditEventListRequest) XXX_DiscardUnknown() {
+	xxx_messageInfo_AuditEventListRequest.DiscardUnknown(m)
+}
+
+var xxx_messageInfo_AuditEventListRequest proto.InternalMessageInfo
+
+func (m *AuditEventListRequest) GetAppId() int32 {
+	if m != nil {
+		return m.AppId
+	}
+	return 0
+}
+
+func (m *AuditEventListRequest) GetActor() string {
+	if m != nil {
+		return m.Actor
+	}
+	return ""
+}
+
+func (m *AuditEventListRequest) GetAction() string {
+	if m != nil {
+		return m.Action
+	}
+	return ""
+}
+
+func (m *AuditEventListRequest) GetTarget() string {
+	if m != nil {
+		return m.Target
+	}
+	return ""
+}
+
+func (m *AuditEventListRequest) GetOccurredFrom() *timestamp.Timestamp {
+	if m != nil {
+		return m.OccurredFrom
+	}
+	return nil
+}
+
+func (m *AuditEventListRequest) GetOccurredTo() *timestamp.Timestamp {
+	if m != nil {
+		return m.OccurredTo
+	}
+	return nil
+}
+
+func (m *AuditEventListRequest) GetLimit() int32 {
+	if m != nil {
+		return m.Limit
+	}
+	return 0
+}
+
+func init() {
+	proto.RegisterType((*Message)(nil), "callstats.ai_decision.Message")
+	proto.RegisterType((*MessageCreateRequest)(nil), "callstats.ai_decision.MessageCreateRequest")
+	proto.RegisterType((*MessageListRequest)(nil), "callstats.ai_decision.MessageListRequest")
+	proto.RegisterType((*State)(nil), "callstats.ai_decision.State")
+	proto.RegisterType((*StateSaveRequest)(nil), "callstats.ai_decision.StateSaveRequest")
+	proto.RegisterType((*StateSaveChunk)(nil), "callstats.ai_decision.StateSaveChunk")
+	proto.RegisterType((*StateChunk)(nil), "callstats.ai_decision.StateChunk")
+	proto.RegisterType((*StateGetRequest)(nil), "callstats.ai_decision.StateGetRequest")
+	proto.RegisterType((*StateGetLatestRequest)(nil), "callstats.ai_decision.StateGetLatestRequest")
+	proto.RegisterType((*StateGetAsOfRequest)(nil), "callstats.ai_decision.StateGetAsOfRequest")
+	proto.RegisterType((*StateListRequest)(nil), "callstats.ai_decision.StateListRequest")
+	proto.RegisterType((*StateDeleteRequest)(nil), "callstats.ai_decision.StateDeleteRequest")
+	proto.RegisterType((*StateDeleteRangeRequest)(nil), "callstats.ai_decision.StateDeleteRangeRequest")
+	proto.RegisterType((*StateDeleteResponse)(nil), "callstats.ai_decision.StateDeleteResponse")
+	proto.RegisterType((*Keyword)(nil), "callstats.ai_decision.Keyword")
+	proto.RegisterType((*KeywordListRequest)(nil), "callstats.ai_decision.KeywordListRequest")
+	proto.RegisterType((*CompactionPolicy)(nil), "callstats.ai_decision.CompactionPolicy")
+	proto.RegisterType((*CompactionPolicyListRequest)(nil), "callstats.ai_decision.CompactionPolicyListRequest")
+	proto.RegisterType((*CompactRequest)(nil), "callstats.ai_decision.CompactRequest")
+	proto.RegisterType((*Compaction)(nil), "callstats.ai_decision.Compaction")
+	proto.RegisterType((*Lease)(nil), "callstats.ai_decision.Lease")
+	proto.RegisterType((*LeaseAcquireRequest)(nil), "callstats.ai_decision.LeaseAcquireRequest")
+	proto.RegisterType((*LeaseRenewRequest)(nil), "callstats.ai_decision.LeaseRenewRequest")
+	proto.RegisterType((*LeaseReleaseRequest)(nil), "callstats.ai_decision.LeaseReleaseRequest")
+	proto.RegisterType((*LeaseReleaseResponse)(nil), "callstats.ai_decision.LeaseReleaseResponse")
+	proto.RegisterType((*Run)(nil), "callstats.ai_decision.Run")
+	proto.RegisterMapType((map[string]int64)(nil), "callstats.ai_decision.Run.CountersEntry")
+	proto.RegisterType((*RunStartRequest)(nil), "callstats.ai_decision.RunStartRequest")
+	proto.RegisterType((*RunFinishRequest)(nil), "callstats.ai_decision.RunFinishRequest")
+	proto.RegisterMapType((map[string]int64)(nil), "callstats.ai_decision.RunFinishRequest.CountersEntry")
+	proto.RegisterType((*RunListRequest)(nil), "callstats.ai_decision.RunListRequest")
+	proto.RegisterType((*RunLastSuccessfulRequest)(nil), "callstats.ai_decision.RunLastSuccessfulRequest")
+	proto.RegisterType((*AuditEvent)(nil), "callstats.ai_decision.AuditEvent")
+	proto.RegisterType((*AuditEventListRequest)(nil), "callstats.ai_decision.AuditEventListRequest")
+	proto.RegisterEnum("callstats.ai_decision.RunStatus", RunStatus_name, RunStatus_value)
+}
+
+// Reference imports to suppress errors if they are not otherwise used.
+var _ context.Context
+var _ grpc.ClientConn
+
+// This is a compile-time assertion to ensure that this generated file
+// is compatible with the grpc package it is being compiled against.
+const _ = grpc.SupportPackageIsVersion4
+
+// AIDecisionMessageServiceClient is the client API for AIDecisionMessageService service.
+//
+// For semantics around ctx use and closing/ending streaming RPCs, please refer to https://godoc.org/google.golang.org/grpc#ClientConn.NewStream.
+type AIDecisionMessageServiceClient interface {
+	Create(ctx context.Context, in *MessageCreateRequest, opts ...grpc.CallOption) (*Message, error)
+	List(ctx context.Context, in *MessageListRequest, opts ...grpc.CallOption) (AIDecisionMessageService_ListClient, error)
+}
+
+type aIDecisionMessageServiceClient struct {
+	cc *grpc.ClientConn
+}
+
+func NewAIDecisionMessageServiceClient(cc *grpc.ClientConn) AIDecisionMessageServiceClient {
+	return &aIDecisionMessageServiceClient{cc}
+}
+
+func (c *aIDecisionMessageServiceClient) Create(ctx context.Context, in *MessageCreateRequest, opts ...grpc.CallOption) (*Message, error) {
+	out := new(Message)
+	err := c.cc.Invoke(ctx, "/callstats.ai_decision.AIDecisionMessageService/Create", in, out, opts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *aIDecisionMessageServiceClient) List(ctx context.Context, in *MessageListRequest, opts ...grpc.CallOption) (AIDecisionMessageService_ListClient, error) {
+	stream, err := c.cc.NewStream(ctx, &_AIDecisionMessageService_serviceDesc.Streams[0], "/callstats.ai_decision.AIDecisionMessageService/List", opts...)
+	if err != nil {
+		return nil, err
+	}
+	x := &aIDecisionMessageServiceListClient{stream}
+	if err := x.ClientStream.SendMsg(in); err != nil {
+		return nil, err
+	}
+	if err := x.ClientStream.CloseSend(); err != nil {
+		return nil, err
+	}
+	return x, nil
+}
+
+type AIDecisionMessageService_ListClient interface {
+	Recv() (*Message, error)
+	grpc.ClientStream
+}
+
+type aIDecisionMessageServiceListClient struct {
+	grpc.ClientStream
+}
+
+func (x *aIDecisionMessageServiceListClient) Recv() (*Message, error) {
+	m := new(Message)
+	if err := x.ClientStream.RecvMsg(m); err != nil {
+		return nil, err
+	}
+	return m, nil
+}
+
+// AIDecisionMessageServiceServer is the server API for AIDecisionMessageService service.
+type AIDecisionMessageServiceServer interface {
+	Create(context.Context, *MessageCreateRequest) (*Message, error)
+	List(*MessageListRequest, AIDecisionMessageService_ListServer) error
+}
+
+func RegisterAIDecisionMessageServiceServer(s *grpc.Server, srv AIDecisionMessageServiceServer) {
+	s.RegisterService(&_AIDecisionMessageService_serviceDesc, srv)
+}
+
+func _AIDecisionMessageService_Create_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(MessageCreateRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(AIDecisionMessageServiceServer).Create(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: "/callstats.ai_decision.AIDecisionMessageService/Create",
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(AIDecisionMessageServiceServer).Create(ctx, req.(*MessageCreateRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _AIDecisionMessageService_List_Handler(srv interface{}, stream grpc.ServerStream) error {
+	m := new(MessageListRequest)
+	if err := stream.RecvMsg(m); err != nil {
+		return err
+	}
+	return srv.(AIDecisionMessageServiceServer).List(m, &aIDecisionMessageServiceListServer{stream})
+}
+
+type AIDecisionMessageService_ListServer interface {
+	Send(*Message) error
+	grpc.ServerStream
+}
+
+type aIDecisionMessageServiceListServer struct {
+	grpc.ServerStream
+}
+
+func (x *aIDecisionMessageServiceListServer) Send(m *Message) error {
+	return x.ServerStream.SendMsg(m)
+}
+
+var _AIDecisionMessageService_serviceDesc = grpc.ServiceDesc{
+	ServiceName: "callstats.ai_decision.AIDecisionMessageService",
+	HandlerType: (*AIDecisionMessageServiceServer)(nil),
+	Methods: []grpc.MethodDesc{
+		{
+			MethodName: "Create",
+			Handler:    _AIDecisionMessageService_Create_Handler,
+		},
+	},
+	Streams: []grpc.StreamDesc{
+		{
+			StreamName:    "List",
+			Handler:       _AIDecisionMessageService_List_Handler,
+			ServerStreams: true,
+		},
+	},
+	Metadata: "ai_decision_service.proto",
+}
+
+// AIDecisionStateServiceClient is the client API for AIDecisionStateService service.
+//
+// For semantics around ctx use and closing/ending streaming RPCs, please refer to https://godoc.org/google.golang.org/grpc#ClientConn.NewStream.
+type AIDecisionStateServiceClient interface {
+	Save(ctx context.Context, in *StateSaveRequest, opts ...grpc.CallOption) (*State, error)
+	Get(ctx context.Context, in *StateGetRequest, opts ...grpc.CallOption) (*State, error)
+	GetLatest(ctx context.Context, in *StateGetLatestRequest, opts ...grpc.CallOption) (*State, error)
+	GetAsOf(ctx context.Context, in *StateGetAsOfRequest, opts ...grpc.CallOption) (*State, error)
+	SaveChunked(ctx context.Context, opts ...grpc.CallOption) (AIDecisionStateService_SaveChunkedClient, error)
+	GetChunked(ctx context.Context, in *StateGetRequest, opts ...grpc.CallOption) (AIDecisionStateService_GetChunkedClient, error)
+	List(ctx context.Context, in *StateListRequest, opts ...grpc.CallOption) (AIDecisionStateService_ListClient, error)
+	ListKeywords(ctx context.Context, in *KeywordListRequest, opts ...grpc.CallOption) (AIDecisionStateService_ListKeywordsClient, error)
+	Delete(ctx context.Context, in *StateDeleteRequest, opts ...grpc.CallOption) (*StateDeleteResponse, error)
+	DeleteRange(ctx context.Context, in *StateDeleteRangeRequest, opts ...grpc.CallOption) (*StateDeleteResponse, error)
+	// admin RPCs for the compaction of state history
+	SetCompactionPolicy(ctx context.Context, in *CompactionPolicy, opts ...grpc.CallOption) (*CompactionPolicy, error)
+	ListCompactionPolicies(ctx context.Context, in *CompactionPolicyListRequest, opts ...grpc.CallOption) (AIDecisionStateService_ListCompactionPoliciesClient, error)
+	Compact(ctx context.Context, in *CompactRequest, opts ...grpc.CallOption) (AIDecisionStateService_CompactClient, error)
+}
+
+type aIDecisionStateServiceClient struct {
+	cc *grpc.ClientConn
+}
+
+func NewAIDecisionStateServiceClient(cc *grpc.ClientConn) AIDecisionStateServiceClient {
+	return &aIDecisionStateServiceClient{cc}
+}
+
+func (c *aIDecisionStateServiceClient) Save(ctx context.Context, in *StateSaveRequest, opts ...grpc.CallOption) (*State, error) {
+	out := new(State)
+	err := c.cc.Invoke(ctx, "/callstats.ai_decision.AIDecisionStateService/Save", in, out, opts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *aIDecisionStateServiceClient) Get(ctx context.Context, in *StateGetRequest, opts ...grpc.CallOption) (*State, error) {
+	out := new(State)
+	err := c.cc.Invoke(ctx, "/callstats.ai_decision.AIDecisionStateService/Get", in, out, opts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *aIDecisionStateServiceClient) GetLatest(ctx context.Context, in *StateGetLatestRequest, opts ...grpc.CallOption) (*State, error) {
+	out := new(State)
+	err := c.cc.Invoke(ctx, "/callstats.ai_decision.AIDecisionStateService/GetLatest", in, out, opts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *aIDecisionStateServiceClient) GetAsOf(ctx context.Context, in *StateGetAsOfRequest, opts ...grpc.CallOption) (*State, error) {
+	out := new(State)
+	err := c.cc.Invoke(ctx, "/callstats.ai_decision.AIDecisionStateService/GetAsOf", in, out, opts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *aIDecisionStateServiceClient) SaveChunked(ctx context.Context, opts ...grpc.CallOption) (AIDecisionStateService_SaveChunkedClient, error) {
+	stream, err := c.cc.NewStream(ctx, &_AIDecisionStateService_serviceDesc.Streams[0], "/callstats.ai_decision.AIDecisionStateService/SaveChunked", opts...)
+	if err != nil {
+		return nil, err
+	}
+	x := &aIDecisionStateServiceSaveChunkedClient{stream}
+	return x, nil
+}
+
+type AIDecisionStateService_SaveChunkedClient interface {
+	Send(*StateSaveChunk) error
+	CloseAndRecv() (*State, error)
+	grpc.ClientStream
+}
+
+type aIDecisionStateServiceSaveChunkedClient struct {
+	grpc.ClientStream
+}
+
+func (x *aIDecisionStateServiceSaveChunkedClient) Send(m *StateSaveChunk) error {
+	return x.ClientStream.SendMsg(m)
+}
+
+func (x *aIDecisionStateServiceSaveChunkedClient) CloseAndRecv() (*State, error) {
+	if err := x.ClientStream.CloseSend(); err != nil {
+		return nil, err
+	}
+	m := new(State)
+	if err := x.ClientStream.RecvMsg(m); err != nil {
+		return nil, err
+	}
+	return m, nil
+}
+
+func (c *aIDecisionStateServiceClient) GetChunked(ctx context.Context, in *StateGetRequest, opts ...grpc.CallOption) (AIDecisionStateService_GetChunkedClient, error) {
+	stream, err := c.cc.NewStream(ctx, &_AIDecisionStateService_serviceDesc.Streams[1], "/callstats.ai_decision.AIDecisionStateService/GetChunked", opts...)
+	if err != nil {
+		return nil, err
+	}
+	x := &aIDecisionStateServiceGetChunkedClient{stream}
+	if err := x.ClientStream.SendMsg(in); err != nil {
+		return nil, err
+	}
+	if err := x.ClientStream.CloseSend(); err != nil {
+		return nil, err
+	}
+	return x, nil
+}
+
+type AIDecisionStateService_GetChunkedClient interface {
+	Recv() (*StateChunk, error)
+	grpc.ClientStream
+}
+
+type aIDecisionStateServiceGetChunkedClient struct {
+	grpc.ClientStream
+}
+
+func (x *aIDecisionStateServiceGetChunkedClient) Recv() (*StateChunk, error) {
+	m := new(StateChunk)
+	if err := x.ClientStream.RecvMsg(m); err != nil {
+		return nil, err
+	}
+	return m, nil
+}
+
+func (c *aIDecisionStateServiceClient) List(ctx context.Context, in *StateListRequest, opts ...grpc.CallOption) (AIDecisionStateService_ListClient, error) {
+	stream, err := c.cc.NewStream(ctx, &_AIDecisionStateService_serviceDesc.Streams[2], "/callstats.ai_decision.AIDecisionStateService/List", opts...)
+	if err != nil {
+		return nil, err
+	}
+	x := &aIDecisionStateServiceListClient{stream}
+	if err := x.ClientStream.SendMsg(in); err != nil {
+		return nil, err
+	}
+	if err := x.ClientStream.CloseSend(); err != nil {
+		return nil, err
+	}
+	return x, nil
+}
+
+type AIDecisionStateService_ListClient interface {
+	Recv() (*State, error)
+	grpc.ClientStream
+}
+
+type aIDecisionStateServiceListClient struct {
+	grpc.ClientStream
+}
+
+func (x *aIDecisionStateServiceListClient) Recv() (*State, error) {
+	m := new(State)
+	if err := x.ClientStream.RecvMsg(m); err != nil {
+		return nil, err
+	}
+	return m, nil
+}
+
+func (c *aIDecisionStateServiceClient) ListKeywords(ctx context.Context, in *KeywordListRequest, opts ...grpc.CallOption) (AIDecisionStateService_ListKeywordsClient, error) {
+	stream, err := c.cc.NewStream(ctx, &_AIDecisionStateService_serviceDesc.Streams[3], "/callstats.ai_decision.AIDecisionStateService/ListKeywords", opts...)
+	if err != nil {
+		return nil, err
+	}
+	x := &aIDecisionStateServiceListKeywordsClient{stream}
+	if err := x.ClientStream.SendMsg(in); err != nil {
+		return nil, err
+	}
+	if err := x.ClientStream.CloseSend(); err != nil {
+		return nil, err
+	}
+	return x, nil
+}
+
+type AIDecisionStateService_ListKeywordsClient interface {
+	Recv() (*Keyword, error)
+	grpc.ClientStream
+}
+
+type aIDecisionStateServiceListKeywordsClient struct {
+	grpc.ClientStream
+}
+
+func (x *aIDecisionStateServiceListKeywordsClient) Recv() (*Keyword, error) {
+	m := new(Keyword)
+	if err := x.ClientStream.RecvMsg(m); err != nil {
+		return nil, err
+	}
+	return m, nil
+}
+
+func (c *aIDecisionStateServiceClient) Delete(ctx context.Context, in *StateDeleteRequest, opts ...grpc.CallOption) (*StateDeleteResponse, error) {
+	out := new(StateDeleteResponse)
+	err := c.cc.Invoke(ctx, "/callstats.ai_decision.AIDecisionStateService/Delete", in, out, opts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *aIDecisionStateServiceClient) DeleteRange(ctx context.Context, in *StateDeleteRangeRequest, opts ...grpc.CallOption) (*StateDeleteResponse, error) {
+	out := new(StateDeleteResponse)
+	err := c.cc.Invoke(ctx, "/callstats.ai_decision.AIDecisionStateService/DeleteRange", in, out, opts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *aIDecisionStateServiceClient) SetCompactionPolicy(ctx context.Context, in *CompactionPolicy, opts ...grpc.CallOption) (*CompactionPolicy, error) {
+	out := new(CompactionPolicy)
+	err := c.cc.Invoke(ctx, "/callstats.ai_decision.AIDecisionStateService/SetCompactionPolicy", in, out, opts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *aIDecisionStateServiceClient) ListCompactionPolicies(ctx context.Context, in *CompactionPolicyListRequest, opts ...grpc.CallOption) (AIDecisionStateService_ListCompactionPoliciesClient, error) {
+	stream, err := c.cc.NewStream(ctx, &_AIDecisionStateService_serviceDesc.Streams[4], "/callstats.ai_decision.AIDecisionStateService/ListCompactionPolicies", opts...)
+	if err != nil {
+		return nil, err
+	}
+	x := &aIDecisionStateServiceListCompactionPoliciesClient{stream}
+	if err := x.ClientStream.SendMsg(in); err != nil {
+		return nil, err
+	}
+	if err := x.ClientStream.CloseSend(); err != nil {
+		return nil, err
+	}
+	return x, nil
+}
+
+type AIDecisionStateService_ListCompactionPoliciesClient interface {
+	Recv() (*CompactionPolicy, error)
+	grpc.ClientStream
+}
+
+type aIDecisionStateServiceListCompactionPoliciesClient struct {
+	grpc.ClientStream
+}
+
+func (x *aIDecisionStateServiceListCompactionPoliciesClient) Recv() (*CompactionPolicy, error) {
+	m := new(CompactionPolicy)
+	if err := x.ClientStream.RecvMsg(m); err != nil {
+		return nil, err
+	}
+	return m, nil
+}
+
+func (c *aIDecisionStateServiceClient) Compact(ctx context.Context, in *CompactRequest, opts ...grpc.CallOption) (AIDecisionStateService_CompactClient, error) {
+	stream, err := c.cc.NewStream(ctx, &_AIDecisionStateService_serviceDesc.Streams[5], "/callstats.ai_decision.AIDecisionStateService/Compact", opts...)
+	if err != nil {
+		return nil, err
+	}
+	x := &aIDecisionStateServiceCompactClient{stream}
+	if err := x.ClientStream.SendMsg(in); err != nil {
+		return nil, err
+	}
+	if err := x.ClientStream.CloseSend(); err != nil {
+		return nil, err
+	}
+	return x, nil
+}
+
+type AIDecisionStateService_CompactClient interface {
+	Recv() (*Compaction, error)
+	grpc.ClientStream
+}
+
+type aIDecisionStateServiceCompactClient struct {
+	grpc.ClientStream
+}
+
+func (x *aIDecisionStateServiceCompactClient) Recv() (*Compaction, error) {
+	m := new(Compaction)
+	if err := x.ClientStream.RecvMsg(m); err != nil {
+		return nil, err
+	}
+	return m, nil
+}
+
+// AIDecisionStateServiceServer is the server API for AIDecisionStateService service.
+type AIDecisionStateServiceServer interface {
+	Save(context.Context, *StateSaveRequest) (*State, error)
+	Get(context.Context, *StateGetRequest) (*State, error)
+	GetLatest(context.Context, *StateGetLatestRequest) (*State, error)
+	GetAsOf(context.Context, *StateGetAsOfRequest) (*State, error)
+	SaveChunked(AIDecisionStateService_SaveChunkedServer) error
+	GetChunked(*StateGetRequest, AIDecisionStateService_GetChunkedServer) error
+	List(*StateListRequest, AIDecisionStateService_ListServer) error
+	ListKeywords(*KeywordListRequest, AIDecisionStateService_ListKeywordsServer) error
+	Delete(context.Context, *StateDeleteRequest) (*StateDeleteResponse, error)
+	DeleteRange(context.Context, *StateDeleteRangeRequest) (*StateDeleteResponse, error)
+	// admin RPCs for the compaction of state history
+	SetCompactionPolicy(context.Context, *CompactionPolicy) (*CompactionPolicy, error)
+	ListCompactionPolicies(*CompactionPolicyListRequest, AIDecisionStateService_ListCompactionPoliciesServer) error
+	Compact(*CompactRequest, AIDecisionStateService_CompactServer) error
+}
+
+func RegisterAIDecisionStateServiceServer(s *grpc.Server, srv AIDecisionStateServiceServer) {
+	s.RegisterService(&_AIDecisionStateService_serviceDesc, srv)
+}
+
+func _AIDecisionStateService_Save_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(StateSaveRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(AIDecisionStateServiceServer).Save(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: "/callstats.ai_decision.AIDecisionStateService/Save",
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(AIDecisionStateServiceServer).Save(ctx, req.(*StateSaveRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _AIDecisionStateService_Get_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(StateGetRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(AIDecisionStateServiceServer).Get(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: "/callstats.ai_decision.AIDecisionStateService/Get",
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(AIDecisionStateServiceServer).Get(ctx, req.(*StateGetRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _AIDecisionStateService_GetLatest_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(StateGetLatestRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(AIDecisionStateServiceServer).GetLatest(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: "/callstats.ai_decision.AIDecisionStateService/GetLatest",
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(AIDecisionStateServiceServer).GetLatest(ctx, req.(*StateGetLatestRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _AIDecisionStateService_GetAsOf_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(StateGetAsOfRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(AIDecisionStateServiceServer).GetAsOf(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: "/callstats.ai_decision.AIDecisionStateService/GetAsOf",
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(AIDecisionStateServiceServer).GetAsOf(ctx, req.(*StateGetAsOfRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _AIDecisionStateService_SaveChunked_Handler(srv interface{}, stream grpc.ServerStream) error {
+	return srv.(AIDecisionStateServiceServer).SaveChunked(&aIDecisionStateServiceSaveChunkedServer{stream})
+}
+
+type AIDecisionStateService_SaveChunkedServer interface {
+	SendAndClose(*State) error
+	Recv() (*StateSaveChunk, error)
 	grpc.ServerStream
 }
 
-func (x *aIDecisionMessageServiceListServer) Send(m *Message) error {
+type aIDecisionStateServiceSaveChunkedServer struct {
+	grpc.ServerStream
+}
+
+func (x *aIDecisionStateServiceSaveChunkedServer) SendAndClose(m *State) error {
 	return x.ServerStream.SendMsg(m)
 }
 
-var _AIDecisionMessageService_serviceDesc = grpc.ServiceDesc{
-	ServiceName: "callstats.ai_decision.AIDecisionMessageService",
-	HandlerType: (*AIDecisionMessageServiceServer)(nil),
+func (x *aIDecisionStateServiceSaveChunkedServer) Recv() (*StateSaveChunk, error) {
+	m := new(StateSaveChunk)
+	if err := x.ServerStream.RecvMsg(m); err != nil {
+		return nil, err
+	}
+	return m, nil
+}
+
+func _AIDecisionStateService_GetChunked_Handler(srv interface{}, stream grpc.ServerStream) error {
+	m := new(StateGetRequest)
+	if err := stream.RecvMsg(m); err != nil {
+		return err
+	}
+	return srv.(AIDecisionStateServiceServer).GetChunked(m, &aIDecisionStateServiceGetChunkedServer{stream})
+}
+
+type AIDecisionStateService_GetChunkedServer interface {
+	Send(*StateChunk) error
+	grpc.ServerStream
+}
+
+type aIDecisionStateServiceGetChunkedServer struct {
+	grpc.ServerStream
+}
+
+func (x *aIDecisionStateServiceGetChunkedServer) Send(m *StateChunk) error {
+	return x.ServerStream.SendMsg(m)
+}
+
+func _AIDecisionStateService_List_Handler(srv interface{}, stream grpc.ServerStream) error {
+	m := new(StateListRequest)
+	if err := stream.RecvMsg(m); err != nil {
+		return err
+	}
+	return srv.(AIDecisionStateServiceServer).List(m, &aIDecisionStateServiceListServer{stream})
+}
+
+type AIDecisionStateService_ListServer interface {
+	Send(*State) error
+	grpc.ServerStream
+}
+
+type aIDecisionStateServiceListServer struct {
+	grpc.ServerStream
+}
+
+func (x *aIDecisionStateServiceListServer) Send(m *State) error {
+	return x.ServerStream.SendMsg(m)
+}
+
+func _AIDecisionStateService_ListKeywords_Handler(srv interface{}, stream grpc.ServerStream) error {
+	m := new(KeywordListRequest)
+	if err := stream.RecvMsg(m); err != nil {
+		return err
+	}
+	return srv.(AIDecisionStateServiceServer).ListKeywords(m, &aIDecisionStateServiceListKeywordsServer{stream})
+}
+
+type AIDecisionStateService_ListKeywordsServer interface {
+	Send(*Keyword) error
+	grpc.ServerStream
+}
+
+type aIDecisionStateServiceListKeywordsServer struct {
+	grpc.ServerStream
+}
+
+func (x *aIDecisionStateServiceListKeywordsServer) Send(m *Keyword) error {
+	return x.ServerStream.SendMsg(m)
+}
+
+func _AIDecisionStateService_Delete_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(StateDeleteRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(AIDecisionStateServiceServer).Delete(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: "/callstats.ai_decision.AIDecisionStateService/Delete",
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(AIDecisionStateServiceServer).Delete(ctx, req.(*StateDeleteRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _AIDecisionStateService_DeleteRange_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(StateDeleteRangeRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(AIDecisionStateServiceServer).DeleteRange(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: "/callstats.ai_decision.AIDecisionStateService/DeleteRange",
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(AIDecisionStateServiceServer).DeleteRange(ctx, req.(*StateDeleteRangeRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _AIDecisionStateService_SetCompactionPolicy_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(CompactionPolicy)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(AIDecisionStateServiceServer).SetCompactionPolicy(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: "/callstats.ai_decision.AIDecisionStateService/SetCompactionPolicy",
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(AIDecisionStateServiceServer).SetCompactionPolicy(ctx, req.(*CompactionPolicy))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _AIDecisionStateService_ListCompactionPolicies_Handler(srv interface{}, stream grpc.ServerStream) error {
+	m := new(CompactionPolicyListRequest)
+	if err := stream.RecvMsg(m); err != nil {
+		return err
+	}
+	return srv.(AIDecisionStateServiceServer).ListCompactionPolicies(m, &aIDecisionStateServiceListCompactionPoliciesServer{stream})
+}
+
+type AIDecisionStateService_ListCompactionPoliciesServer interface {
+	Send(*CompactionPolicy) error
+	grpc.ServerStream
+}
+
+type aIDecisionStateServiceListCompactionPoliciesServer struct {
+	grpc.ServerStream
+}
+
+func (x *aIDecisionStateServiceListCompactionPoliciesServer) Send(m *CompactionPolicy) error {
+	return x.ServerStream.SendMsg(m)
+}
+
+func _AIDecisionStateService_Compact_Handler(srv interface{}, stream grpc.ServerStream) error {
+	m := new(CompactRequest)
+	if err := stream.RecvMsg(m); err != nil {
+		return err
+	}
+	return srv.(AIDecisionStateServiceServer).Compact(m, &aIDecisionStateServiceCompactServer{stream})
+}
+
+type AIDecisionStateService_CompactServer interface {
+	Send(*Compaction) error
+	grpc.ServerStream
+}
+
+type aIDecisionStateServiceCompactServer struct {
+	grpc.ServerStream
+}
+
+func (x *aIDecisionStateServiceCompactServer) Send(m *Compaction) error {
+	return x.ServerStream.SendMsg(m)
+}
+
+var _AIDecisionStateService_serviceDesc = grpc.ServiceDesc{
+	ServiceName: "callstats.ai_decision.AIDecisionStateService",
+	HandlerType: (*AIDecisionStateServiceServer)(nil),
 	Methods: []grpc.MethodDesc{
 		{
-			MethodName: "Create",
-			Handler:    _AIDecisionMessageService_Create_Handler,
+			MethodName: "Save",
+			Handler:    _AIDecisionStateService_Save_Handler,
+		},
+		{
+			MethodName: "Get",
+			Handler:    _AIDecisionStateService_Get_Handler,
+		},
+		{
+			MethodName: "GetLatest",
+			Handler:    _AIDecisionStateService_GetLatest_Handler,
+		},
+		{
+			MethodName: "GetAsOf",
+			Handler:    _AIDecisionStateService_GetAsOf_Handler,
+		},
+		{
+			MethodName: "Delete",
+			Handler:    _AIDecisionStateService_Delete_Handler,
+		},
+		{
+			MethodName: "DeleteRange",
+			Handler:    _AIDecisionStateService_DeleteRange_Handler,
+		},
+		{
+			MethodName: "SetCompactionPolicy",
+			Handler:    _AIDecisionStateService_SetCompactionPolicy_Handler,
 		},
 	},
 	Streams: []grpc.StreamDesc{
+		{
+			StreamName:    "SaveChunked",
+			Handler:       _AIDecisionStateService_SaveChunked_Handler,
+			ClientStreams: true,
+		},
+		{
+			StreamName:    "GetChunked",
+			Handler:       _AIDecisionStateService_GetChunked_Handler,
+			ServerStreams: true,
+		},
 		{
 			StreamName:    "List",
-			Handler:       _AIDecisionMessageService_List_Handler,
+			Handler:       _AIDecisionStateService_List_Handler,
+			ServerStreams: true,
+		},
+		{
+			StreamName:    "ListKeywords",
+			Handler:       _AIDecisionStateService_ListKeywords_Handler,
+			ServerStreams: true,
+		},
+		{
+			StreamName:    "ListCompactionPolicies",
+			Handler:       _AIDecisionStateService_ListCompactionPolicies_Handler,
+			ServerStreams: true,
+		},
+		{
+			StreamName:    "Compact",
+			Handler:       _AIDecisionStateService_Compact_Handler,
 			ServerStreams: true,
 		},
 	},
 	Metadata: "ai_decision_service.proto",
 }
 
-// Client API for AIDecisionStateService service
+// AIDecisionLeaseServiceClient is the client API for AIDecisionLeaseService service.
+//
+// For semantics around ctx use and closing/ending streaming RPCs, please refer to https://godoc.org/google.golang.org/grpc#ClientConn.NewStream.
+type AIDecisionLeaseServiceClient interface {
+	// Acquire fails with FailedPrecondition if the lease is held by another holder
+	Acquire(ctx context.Context, in *LeaseAcquireRequest, opts ...grpc.CallOption) (*Lease, error)
+	// Renew and Release fail with FailedPrecondition if the lease expired or was acquired by another holder
+	Renew(ctx context.Context, in *LeaseRenewRequest, opts ...grpc.CallOption) (*Lease, error)
+	Release(ctx context.Context, in *LeaseReleaseRequest, opts ...grpc.CallOption) (*LeaseReleaseResponse, error)
+}
 
-type AIDecisionStateServiceClient interface {
-	Save(ctx context.Context, in *StateSaveRequest, opts ...grpc.CallOption) (*State, error)
-	Get(ctx context.Context, in *StateGetRequest, opts ...grpc.CallOption) (*State, error)
-	List(ctx context.Context, in *StateListRequest, opts ...grpc.CallOption) (AIDecisionStateService_ListClient, error)
+type aIDecisionLeaseServiceClient struct {
+	cc *grpc.ClientConn
 }
 
-type aIDecisionStateServiceClient struct {
+func NewAIDecisionLeaseServiceClient(cc *grpc.ClientConn) AIDecisionLeaseServiceClient {
+	return &aIDecisionLeaseServiceClient{cc}
+}
+
+func (c *aIDecisionLeaseServiceClient) Acquire(ctx context.Context, in *LeaseAcquireRequest, opts ...grpc.CallOption) (*Lease, error) {
+	out := new(Lease)
+	err := c.cc.Invoke(ctx, "/callstats.ai_decision.AIDecisionLeaseService/Acquire", in, out, opts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *aIDecisionLeaseServiceClient) Renew(ctx context.Context, in *LeaseRenewRequest, opts ...grpc.CallOption) (*Lease, error) {
+	out := new(Lease)
+	err := c.cc.Invoke(ctx, "/callstats.ai_decision.AIDecisionLeaseService/Renew", in, out, opts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *aIDecisionLeaseServiceClient) Release(ctx context.Context, in *LeaseReleaseRequest, opts ...grpc.CallOption) (*LeaseReleaseResponse, error) {
+	out := new(LeaseReleaseResponse)
+	err := c.cc.Invoke(ctx, "/callstats.ai_decision.AIDecisionLeaseService/Release", in, out, opts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+// AIDecisionLeaseServiceServer is the server API for AIDecisionLeaseService service.
+type AIDecisionLeaseServiceServer interface {
+	// Acquire fails with FailedPrecondition if the lease is held by another holder
+	Acquire(context.Context, *LeaseAcquireRequest) (*Lease, error)
+	// Renew and Release fail with FailedPrecondition if the lease expired or was acquired by another holder
+	Renew(context.Context, *LeaseRenewRequest) (*Lease, error)
+	Release(context.Context, *LeaseReleaseRequest) (*LeaseReleaseResponse, error)
+}
+
+func RegisterAIDecisionLeaseServiceServer(s *grpc.Server, srv AIDecisionLeaseServiceServer) {
+	s.RegisterService(&_AIDecisionLeaseService_serviceDesc, srv)
+}
+
+func _AIDecisionLeaseService_Acquire_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(LeaseAcquireRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(AIDecisionLeaseServiceServer).Acquire(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: "/callstats.ai_decision.AIDecisionLeaseService/Acquire",
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(AIDecisionLeaseServiceServer).Acquire(ctx, req.(*LeaseAcquireRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _AIDecisionLeaseService_Renew_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(LeaseRenewRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(AIDecisionLeaseServiceServer).Renew(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: "/callstats.ai_decision.AIDecisionLeaseService/Renew",
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(AIDecisionLeaseServiceServer).Renew(ctx, req.(*LeaseRenewRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _AIDecisionLeaseService_Release_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(LeaseReleaseRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(AIDecisionLeaseServiceServer).Release(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: "/callstats.ai_decision.AIDecisionLeaseService/Release",
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(AIDecisionLeaseServiceServer).Release(ctx, req.(*LeaseReleaseRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+var _AIDecisionLeaseService_serviceDesc = grpc.ServiceDesc{
+	ServiceName: "callstats.ai_decision.AIDecisionLeaseService",
+	HandlerType: (*AIDecisionLeaseServiceServer)(nil),
+	Methods: []grpc.MethodDesc{
+		{
+			MethodName: "Acquire",
+			Handler:    _AIDecisionLeaseService_Acquire_Handler,
+		},
+		{
+			MethodName: "Renew",
+			Handler:    _AIDecisionLeaseService_Renew_Handler,
+		},
+		{
+			MethodName: "Release",
+			Handler:    _AIDecisionLeaseService_Release_Handler,
+		},
+	},
+	Streams:  []grpc.StreamDesc{},
+	Metadata: "ai_decision_service.proto",
+}
+
+// AIDecisionRunServiceClient is the client API for AIDecisionRunService service.
+//
+// For semantics around ctx use and closing/ending streaming RPCs, please refer to https://godoc.org/google.golang.org/grpc#ClientConn.NewStream.
+type AIDecisionRunServiceClient interface {
+	Start(ctx context.Context, in *RunStartRequest, opts ...grpc.CallOption) (*Run, error)
+	// Finish fails with FailedPrecondition if the run is already finished
+	Finish(ctx context.Context, in *RunFinishRequest, opts ...grpc.CallOption) (*Run, error)
+	List(ctx context.Context, in *RunListRequest, opts ...grpc.CallOption) (AIDecisionRunService_ListClient, error)
+	// LastSuccessful returns the newest successful run of each app
+	LastSuccessful(ctx context.Context, in *RunLastSuccessfulRequest, opts ...grpc.CallOption) (AIDecisionRunService_LastSuccessfulClient, error)
+}
+
+type aIDecisionRunServiceClient struct {
 	cc *grpc.ClientConn
 }
 
-func NewAIDecisionStateServiceClient(cc *grpc.ClientConn) AIDecisionStateServiceClient {
-	return &aIDecisionStateServiceClient{cc}
+func NewAIDecisionRunServiceClient(cc *grpc.ClientConn) AIDecisionRunServiceClient {
+	return &aIDecisionRunServiceClient{cc}
 }
 
-func (c *aIDecisionStateServiceClient) Save(ctx context.Context, in *StateSaveRequest, opts ...grpc.CallOption) (*State, error) {
-	out := new(State)
-	err := grpc.Invoke(ctx, "/callstats.ai_decision.AIDecisionStateService/Save", in, out, c.cc, opts...)
+func (c *aIDecisionRunServiceClient) Start(ctx context.Context, in *RunStartRequest, opts ...grpc.CallOption) (*Run, error) {
+	out := new(Run)
+	err := c.cc.Invoke(ctx, "/callstats.ai_decision.AIDecisionRunService/Start", in, out, opts...)
 	if err != nil {
 		return nil, err
 	}
 	return out, nil
 }
 
-func (c *aIDecisionStateServiceClient) Get(ctx context.Context, in *StateGetRequest, opts ...grpc.CallOption) (*State, error) {
-	out := new(State)
-	err := grpc.Invoke(ctx, "/callstats.ai_decision.AIDecisionStateService/Get", in, out, c.cc, opts...)
+func (c *aIDecisionRunServiceClient) Finish(ctx context.Context, in *RunFinishRequest, opts ...grpc.CallOption) (*Run, error) {
+	out := new(Run)
+	err := c.cc.Invoke(ctx, "/callstats.ai_decision.AIDecisionRunService/Finish", in, out, opts...)
 	if err != nil {
 		return nil, err
 	}
 	return out, nil
 }
 
-func (c *aIDecisionStateServiceClient) List(ctx context.Context, in *StateListRequest, opts ...grpc.CallOption) (AIDecisionStateService_ListClient, error) {
-	stream, err := grpc.NewClientStream(ctx, &_AIDecisionStateService_serviceDesc.Streams[0], c.cc, "/callstats.ai_decision.AIDecisionStateService/List", opts...)
+func (c *aIDecisionRunServiceClient) List(ctx context.Context, in *RunListRequest, opts ...grpc.CallOption) (AIDecisionRunService_ListClient, error) {
+	stream, err := c.cc.NewStream(ctx, &_AIDecisionRunService_serviceDesc.Streams[0], "/callstats.ai_decision.AIDecisionRunService/List", opts...)
 	if err != nil {
 		return nil, err
 	}
-	x := &aIDecisionStateServiceListClient{stream}
+	x := &aIDecisionRunServiceListClient{stream}
 	if err := x.ClientStream.SendMsg(in); err != nil {
 		return nil, err
 	}
@@ -554,151 +3250,387 @@ func (c *aIDecisionStateServiceClient) List(ctx context.Context, in *StateListRe
 	return x, nil
 }
 
-type AIDecisionStateService_ListClient interface {
-	Recv() (*State, error)
+type AIDecisionRunService_ListClient interface {
+	Recv() (*Run, error)
 	grpc.ClientStream
 }
 
-type aIDecisionStateServiceListClient struct {
+type aIDecisionRunServiceListClient struct {
 	grpc.ClientStream
 }
 
-func (x *aIDecisionStateServiceListClient) Recv() (*State, error) {
-	m := new(State)
+func (x *aIDecisionRunServiceListClient) Recv() (*Run, error) {
+	m := new(Run)
 	if err := x.ClientStream.RecvMsg(m); err != nil {
 		return nil, err
 	}
 	return m, nil
 }
 
-// Server API for AIDecisionStateService service
+func (c *aIDecisionRunServiceClient) LastSuccessful(ctx context.Context, in *RunLastSuccessfulRequest, opts ...grpc.CallOption) (AIDecisionRunService_LastSuccessfulClient, error) {
+	stream, err := c.cc.NewStream(ctx, &_AIDecisionRunService_serviceDesc.Streams[1], "/callstats.ai_decision.AIDecisionRunService/LastSuccessful", opts...)
+	if err != nil {
+		return nil, err
+	}
+	x := &aIDecisionRunServiceLastSuccessfulClient{stream}
+	if err := x.ClientStream.SendMsg(in); err != nil {
+		return nil, err
+	}
+	if err := x.ClientStream.CloseSend(); err != nil {
+		return nil, err
+	}
+	return x, nil
+}
+
+type AIDecisionRunService_LastSuccessfulClient interface {
+	Recv() (*Run, error)
+	grpc.ClientStream
+}
 
-type AIDecisionStateServiceServer interface {
-	Save(context.Context, *StateSaveRequest) (*State, error)
-	Get(context.Context, *StateGetRequest) (*State, error)
-	List(*StateListRequest, AIDecisionStateService_ListServer) error
+type aIDecisionRunServiceLastSuccessfulClient struct {
+	grpc.ClientStream
 }
 
-func RegisterAIDecisionStateServiceServer(s *grpc.Server, srv AIDecisionStateServiceServer) {
-	s.RegisterService(&_AIDecisionStateService_serviceDesc, srv)
+func (x *aIDecisionRunServiceLastSuccessfulClient) Recv() (*Run, error) {
+	m := new(Run)
+	if err := x.ClientStream.RecvMsg(m); err != nil {
+		return nil, err
+	}
+	return m, nil
 }
 
-func _AIDecisionStateService_Save_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
-	in := new(StateSaveRequest)
+// AIDecisionRunServiceServer is the server API for AIDecisionRunService service.
+type AIDecisionRunServiceServer interface {
+	Start(context.Context, *RunStartRequest) (*Run, error)
+	// Finish fails with FailedPrecondition if the run is already finished
+	Finish(context.Context, *RunFinishRequest) (*Run, error)
+	List(*RunListRequest, AIDecisionRunService_ListServer) error
+	// LastSuccessful returns the newest successful run of each app
+	LastSuccessful(*RunLastSuccessfulRequest, AIDecisionRunService_LastSuccessfulServer) error
+}
+
+func RegisterAIDecisionRunServiceServer(s *grpc.Server, srv AIDecisionRunServiceServer) {
+	s.RegisterService(&_AIDecisionRunService_serviceDesc, srv)
+}
+
+func _AIDecisionRunService_Start_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(RunStartRequest)
 	if err := dec(in); err != nil {
 		return nil, err
 	}
 	if interceptor == nil {
-		return srv.(AIDecisionStateServiceServer).Save(ctx, in)
+		return srv.(AIDecisionRunServiceServer).Start(ctx, in)
 	}
 	info := &grpc.UnaryServerInfo{
 		Server:     srv,
-		FullMethod: "/callstats.ai_decision.AIDecisionStateService/Save",
+		FullMethod: "/callstats.ai_decision.AIDecisionRunService/Start",
 	}
 	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
-		return srv.(AIDecisionStateServiceServer).Save(ctx, req.(*StateSaveRequest))
+		return srv.(AIDecisionRunServiceServer).Start(ctx, req.(*RunStartRequest))
 	}
 	return interceptor(ctx, in, info, handler)
 }
 
-func _AIDecisionStateService_Get_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
-	in := new(StateGetRequest)
+func _AIDecisionRunService_Finish_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(RunFinishRequest)
 	if err := dec(in); err != nil {
 		return nil, err
 	}
 	if interceptor == nil {
-		return srv.(AIDecisionStateServiceServer).Get(ctx, in)
+		return srv.(AIDecisionRunServiceServer).Finish(ctx, in)
 	}
 	info := &grpc.UnaryServerInfo{
 		Server:     srv,
-		FullMethod: "/callstats.ai_decision.AIDecisionStateService/Get",
+		FullMethod: "/callstats.ai_decision.AIDecisionRunService/Finish",
 	}
 	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
-		return srv.(AIDecisionStateServiceServer).Get(ctx, req.(*StateGetRequest))
+		return srv.(AIDecisionRunServiceServer).Finish(ctx, req.(*RunFinishRequest))
 	}
 	return interceptor(ctx, in, info, handler)
 }
 
-func _AIDecisionStateService_List_Handler(srv interface{}, stream grpc.ServerStream) error {
-	m := new(StateListRequest)
+func _AIDecisionRunService_List_Handler(srv interface{}, stream grpc.ServerStream) error {
+	m := new(RunListRequest)
 	if err := stream.RecvMsg(m); err != nil {
 		return err
 	}
-	return srv.(AIDecisionStateServiceServer).List(m, &aIDecisionStateServiceListServer{stream})
+	return srv.(AIDecisionRunServiceServer).List(m, &aIDecisionRunServiceListServer{stream})
 }
 
-type AIDecisionStateService_ListServer interface {
-	Send(*State) error
+type AIDecisionRunService_ListServer interface {
+	Send(*Run) error
 	grpc.ServerStream
 }
 
-type aIDecisionStateServiceListServer struct {
+type aIDecisionRunServiceListServer struct {
 	grpc.ServerStream
 }
 
-func (x *aIDecisionStateServiceListServer) Send(m *State) error {
+func (x *aIDecisionRunServiceListServer) Send(m *Run) error {
 	return x.ServerStream.SendMsg(m)
 }
 
-var _AIDecisionStateService_serviceDesc = grpc.ServiceDesc{
-	ServiceName: "callstats.ai_decision.AIDecisionStateService",
-	HandlerType: (*AIDecisionStateServiceServer)(nil),
+func _AIDecisionRunService_LastSuccessful_Handler(srv interface{}, stream grpc.ServerStream) error {
+	m := new(RunLastSuccessfulRequest)
+	if err := stream.RecvMsg(m); err != nil {
+		return err
+	}
+	return srv.(AIDecisionRunServiceServer).LastSuccessful(m, &aIDecisionRunServiceLastSuccessfulServer{stream})
+}
+
+type AIDecisionRunService_LastSuccessfulServer interface {
+	Send(*Run) error
+	grpc.ServerStream
+}
+
+type aIDecisionRunServiceLastSuccessfulServer struct {
+	grpc.ServerStream
+}
+
+func (x *aIDecisionRunServiceLastSuccessfulServer) Send(m *Run) error {
+	return x.ServerStream.SendMsg(m)
+}
+
+var _AIDecisionRunService_serviceDesc = grpc.ServiceDesc{
+	ServiceName: "callstats.ai_decision.AIDecisionRunService",
+	HandlerType: (*AIDecisionRunServiceServer)(nil),
 	Methods: []grpc.MethodDesc{
 		{
-			MethodName: "Save",
-			Handler:    _AIDecisionStateService_Save_Handler,
+			MethodName: "Start",
+			Handler:    _AIDecisionRunService_Start_Handler,
 		},
 		{
-			MethodName: "Get",
-			Handler:    _AIDecisionStateService_Get_Handler,
+			MethodName: "Finish",
+			Handler:    _AIDecisionRunService_Finish_Handler,
 		},
 	},
 	Streams: []grpc.StreamDesc{
 		{
 			StreamName:    "List",
-			Handler:       _AIDecisionStateService_List_Handler,
+			Handler:       _AIDecisionRunService_List_Handler,
+			ServerStreams: true,
+		},
+		{
+			StreamName:    "LastSuccessful",
+			Handler:       _AIDecisionRunService_LastSuccessful_Handler,
+			ServerStreams: true,
+		},
+	},
+	Metadata: "ai_decision_service.proto",
+}
+
+// AIDecisionAuditServiceClient is the client API for AIDecisionAuditService service.
+//
+// For semantics around ctx use and closing/ending streaming RPCs, please refer to https://godoc.org/google.golang.org/grpc#ClientConn.NewStream.
+type AIDecisionAuditServiceClient interface {
+	// List returns the audit events newest first, it requires a token with the internal scope
+	List(ctx context.Context, in *AuditEventListRequest, opts ...grpc.CallOption) (AIDecisionAuditService_ListClient, error)
+}
+
+type aIDecisionAuditServiceClient struct {
+	cc *grpc.ClientConn
+}
+
+func NewAIDecisionAuditServiceClient(cc *grpc.ClientConn) AIDecisionAuditServiceClient {
+	return &aIDecisionAuditServiceClient{cc}
+}
+
+func (c *aIDecisionAuditServiceClient) List(ctx context.Context, in *AuditEventListRequest, opts ...grpc.CallOption) (AIDecisionAuditService_ListClient, error) {
+	stream, err := c.cc.NewStream(ctx, &_AIDecisionAuditService_serviceDesc.Streams[0], "/callstats.ai_decision.AIDecisionAuditService/List", opts...)
+	if err != nil {
+		return nil, err
+	}
+	x := &aIDecisionAuditServiceListClient{stream}
+	if err := x.ClientStream.SendMsg(in); err != nil {
+		return nil, err
+	}
+	if err := x.ClientStream.CloseSend(); err != nil {
+		return nil, err
+	}
+	return x, nil
+}
+
+type AIDecisionAuditService_ListClient interface {
+	Recv() (*AuditEvent, error)
+	grpc.ClientStream
+}
+
+type aIDecisionAuditServiceListClient struct {
+	grpc.ClientStream
+}
+
+func (x *aIDecisionAuditServiceListClient) Recv() (*AuditEvent, error) {
+	m := new(AuditEvent)
+	if err := x.ClientStream.RecvMsg(m); err != nil {
+		return nil, err
+	}
+	return m, nil
+}
+
+// AIDecisionAuditServiceServer is the server API for AIDecisionAuditService service.
+type AIDecisionAuditServiceServer interface {
+	// List returns the audit events newest first, it requires a token with the internal scope
+	List(*AuditEventListRequest, AIDecisionAuditService_ListServer) error
+}
+
+func RegisterAIDecisionAuditServiceServer(s *grpc.Server, srv AIDecisionAuditServiceServer) {
+	s.RegisterService(&_AIDecisionAuditService_serviceDesc, srv)
+}
+
+func _AIDecisionAuditService_List_Handler(srv interface{}, stream grpc.ServerStream) error {
+	m := new(AuditEventListRequest)
+	if err := stream.RecvMsg(m); err != nil {
+		return err
+	}
+	return srv.(AIDecisionAuditServiceServer).List(m, &aIDecisionAuditServiceListServer{stream})
+}
+
+type AIDecisionAuditService_ListServer interface {
+	Send(*AuditEvent) error
+	grpc.ServerStream
+}
+
+type aIDecisionAuditServiceListServer struct {
+	grpc.ServerStream
+}
+
+func (x *aIDecisionAuditServiceListServer) Send(m *AuditEvent) error {
+	return x.ServerStream.SendMsg(m)
+}
+
+var _AIDecisionAuditService_serviceDesc = grpc.ServiceDesc{
+	ServiceName: "callstats.ai_decision.AIDecisionAuditService",
+	HandlerType: (*AIDecisionAuditServiceServer)(nil),
+	Methods:     []grpc.MethodDesc{},
+	Streams: []grpc.StreamDesc{
+		{
+			StreamName:    "List",
+			Handler:       _AIDecisionAuditService_List_Handler,
 			ServerStreams: true,
 		},
 	},
 	Metadata: "ai_decision_service.proto",
 }
 
-func init() { proto.RegisterFile("ai_decision_service.proto", fileDescriptor0) }
-
-var fileDescriptor0 = []byte{
-	// 541 bytes of a gzipped FileDescriptorProto
-	0x1f, 0x8b, 0x08, 0x00, 0x00, 0x00, 0x00, 0x00, 0x02, 0xff, 0xc4, 0x95, 0xcf, 0x6e, 0xd3, 0x40,
-	0x10, 0xc6, 0xb5, 0xb1, 0x9d, 0x88, 0x29, 0xa2, 0xd5, 0xaa, 0x45, 0x26, 0x42, 0xd4, 0xca, 0x01,
-	0x02, 0x48, 0x6e, 0x15, 0x9e, 0x00, 0x8a, 0x28, 0x15, 0xad, 0x90, 0x9c, 0xc2, 0x81, 0x8b, 0xb5,
-	0x8d, 0xa7, 0xd1, 0x8a, 0x38, 0x6b, 0xbc, 0xdb, 0xd0, 0x3e, 0x00, 0xe2, 0xcc, 0x11, 0x89, 0xc7,
-	0xe0, 0x05, 0xb8, 0xf0, 0x12, 0xbc, 0x0c, 0x5a, 0x7b, 0x4d, 0xfe, 0xc8, 0x76, 0x92, 0x0a, 0xa9,
-	0x27, 0xef, 0x5a, 0xdf, 0x8e, 0x7f, 0x33, 0xf3, 0xed, 0x18, 0xee, 0x31, 0x1e, 0x46, 0x38, 0xe0,
-	0x92, 0x8b, 0x71, 0x28, 0x31, 0x9d, 0xf0, 0x01, 0xfa, 0x49, 0x2a, 0x94, 0xa0, 0x3b, 0x03, 0x36,
-	0x1a, 0x49, 0xc5, 0x94, 0xf4, 0x67, 0x44, 0xed, 0xdd, 0xa1, 0x10, 0xc3, 0x11, 0xee, 0x65, 0xa2,
-	0xb3, 0x8b, 0xf3, 0x3d, 0xc5, 0x63, 0x94, 0x8a, 0xc5, 0x49, 0x7e, 0xae, 0xf3, 0x8b, 0x40, 0xeb,
-	0x04, 0xa5, 0x64, 0x43, 0xa4, 0x2e, 0xb4, 0xe2, 0x7c, 0xe9, 0x12, 0x8f, 0x74, 0x6f, 0x05, 0xc5,
-	0x96, 0xee, 0x40, 0x93, 0x25, 0x49, 0xc8, 0x23, 0xb7, 0xe1, 0x91, 0xae, 0x13, 0x38, 0x2c, 0x49,
-	0x8e, 0x22, 0x4a, 0xc1, 0x56, 0x57, 0x09, 0xba, 0x56, 0xa6, 0xce, 0xd6, 0x3a, 0xc8, 0x04, 0x53,
-	0xfd, 0x71, 0xd7, 0xce, 0xb4, 0xc5, 0x56, 0xab, 0x23, 0xa6, 0x98, 0xeb, 0x78, 0xa4, 0x7b, 0x3b,
-	0xc8, 0xd6, 0xf4, 0x00, 0x36, 0x87, 0x38, 0xc6, 0x94, 0x29, 0x9d, 0x92, 0x86, 0x73, 0x9b, 0x1e,
-	0xe9, 0x6e, 0xf4, 0xda, 0x7e, 0x4e, 0xee, 0x17, 0xe4, 0xfe, 0x69, 0x41, 0x1e, 0xdc, 0x99, 0x1e,
-	0xd1, 0x2f, 0x3b, 0x3f, 0x09, 0x6c, 0x9b, 0x1c, 0x0e, 0x52, 0x64, 0x0a, 0x03, 0xfc, 0x74, 0x81,
-	0x52, 0xcd, 0x60, 0x93, 0x32, 0xec, 0x46, 0x39, 0xb6, 0x55, 0x8e, 0x6d, 0xd7, 0x63, 0x3b, 0x6b,
-	0x63, 0x7f, 0x6f, 0x00, 0x35, 0xd8, 0xc7, 0x5c, 0xaa, 0x6b, 0x40, 0xef, 0xc2, 0x46, 0xcc, 0xc7,
-	0xe1, 0x3c, 0x38, 0xc4, 0x7c, 0xfc, 0xde, 0xb0, 0x6b, 0x01, 0xbb, 0x0c, 0xe7, 0x1b, 0x02, 0x31,
-	0xbb, 0x2c, 0x04, 0xc7, 0xb0, 0xbd, 0x90, 0x48, 0x78, 0x9e, 0x8a, 0x78, 0x85, 0x6c, 0xe8, 0x7c,
-	0x36, 0xaf, 0x52, 0x11, 0xd3, 0xd7, 0x40, 0x17, 0xa3, 0x29, 0xb1, 0x42, 0x43, 0xb7, 0xe6, 0x63,
-	0x9d, 0x8a, 0xce, 0x37, 0x02, 0x4e, 0x5f, 0x31, 0x85, 0x55, 0xe5, 0x70, 0xa1, 0xf5, 0x11, 0xaf,
-	0x3e, 0x8b, 0x34, 0x32, 0x15, 0x29, 0xb6, 0xff, 0xfa, 0x65, 0xd5, 0xf7, 0xcb, 0x5e, 0xbb, 0x5f,
-	0x3f, 0x08, 0x6c, 0x65, 0x4c, 0x7d, 0x36, 0x59, 0x66, 0xb1, 0x1b, 0xc0, 0xfb, 0x4a, 0x60, 0x33,
-	0xc3, 0x3b, 0x44, 0x75, 0x6d, 0xba, 0x12, 0x12, 0x6b, 0x6d, 0x92, 0x3f, 0x45, 0xa1, 0x56, 0xb0,
-	0x75, 0x35, 0x4a, 0x95, 0x35, 0xad, 0xff, 0x68, 0x4d, 0x7b, 0x7d, 0x6b, 0xf6, 0x7e, 0x13, 0x70,
-	0x9f, 0x1f, 0xbd, 0x34, 0x13, 0xd6, 0x5c, 0xe0, 0x7e, 0x3e, 0x8c, 0xe9, 0x3b, 0x68, 0xe6, 0x23,
-	0x88, 0x3e, 0xf5, 0x4b, 0x27, 0xb2, 0x5f, 0x36, 0xa8, 0xda, 0x0f, 0xea, 0xc5, 0xb4, 0x0f, 0xb6,
-	0xae, 0x25, 0x7d, 0x5c, 0xaf, 0x9b, 0xa9, 0xf7, 0xb2, 0x90, 0xfb, 0xa4, 0xf7, 0xa5, 0x01, 0x77,
-	0xa7, 0x89, 0xe4, 0xce, 0x36, 0x69, 0x9c, 0x80, 0xad, 0x4d, 0x4e, 0x1f, 0x55, 0x04, 0x59, 0xbc,
-	0x06, 0xed, 0xfb, 0x75, 0x42, 0xfa, 0x06, 0xac, 0x43, 0x54, 0xf4, 0x61, 0x9d, 0x68, 0xea, 0xda,
-	0x25, 0xc1, 0xde, 0x9a, 0x5a, 0xd4, 0xb2, 0xcd, 0x56, 0xa2, 0x36, 0xdc, 0x3e, 0x79, 0xf1, 0x04,
-	0x3c, 0x2e, 0x2a, 0x34, 0xe6, 0x27, 0xfb, 0xa1, 0x99, 0x59, 0x43, 0x9e, 0xe5, 0xcf, 0x67, 0x7f,
-	0x03, 0x00, 0x00, 0xff, 0xff, 0xd5, 0x39, 0x04, 0xac, 0x8a, 0x07, 0x00, 0x00,
+func init() {
+	proto.RegisterFile("ai_decision_service.proto", fileDescriptor_ai_decision_service_e434f06e2ed91ff7)
+}
+
+var fileDescriptor_ai_decision_service_e434f06e2ed91ff7 = []byte{
+	// 1827 bytes of a gzipped FileDescriptorProto
+	0x1f, 0x8b, 0x08, 0x00, 0x00, 0x00, 0x00, 0x00, 0x02, 0xff, 0xcc, 0x59, 0xcd, 0x6e, 0x1b, 0xc9,
+	0x11, 0xce, 0x90, 0x1c, 0xfe, 0x14, 0x45, 0x8a, 0x6a, 0xfd, 0x84, 0x66, 0x76, 0x37, 0xf2, 0x20,
+	0x9b, 0x95, 0xed, 0x84, 0x62, 0x68, 0x64, 0x63, 0x18, 0x41, 0x00, 0x45, 0x92, 0x05, 0xc1, 0xb6,
+	0xbc, 0x20, 0x2d, 0x24, 0xde, 0x3d, 0x0c, 0x5a, 0xc3, 0x22, 0x39, 0x10, 0x39, 0xc3, 0x9d, 0xee,
+	0x91, 0xac, 0x24, 0x0f, 0x92, 0x43, 0x4e, 0x79, 0x8f, 0x5c, 0x17, 0x08, 0x10, 0xe4, 0x94, 0x4b,
+	0x0e, 0x41, 0xf6, 0x09, 0xf2, 0x06, 0x01, 0x82, 0xe9, 0xee, 0xa1, 0x86, 0xa4, 0xe6, 0x47, 0x8b,
+	0x2c, 0xb0, 0x27, 0x19, 0x3d, 0xd5, 0x55, 0x5f, 0x7d, 0x55, 0x5d, 0x3f, 0x34, 0x3c, 0xa0, 0xb6,
+	0x39, 0x40, 0xcb, 0x66, 0xb6, 0xeb, 0x98, 0x0c, 0xbd, 0x2b, 0xdb, 0xc2, 0xf6, 0xcc, 0x73, 0xb9,
+	0x4b, 0xb6, 0x2d, 0x3a, 0x99, 0x30, 0x4e, 0x39, 0x6b, 0x47, 0x84, 0x5a, 0x3f, 0x1c, 0xb9, 0xee,
+	0x68, 0x82, 0xfb, 0x42, 0xe8, 0xc2, 0x1f, 0xee, 0x73, 0x7b, 0x8a, 0x8c, 0xd3, 0xe9, 0x4c, 0xde,
+	0x6b, 0xed, 0x2e, 0x0b, 0x0c, 0x6d, 0x9c, 0x0c, 0xcc, 0x29, 0x65, 0x97, 0x52, 0xc2, 0xf8, 0x93,
+	0x06, 0xa5, 0xd7, 0xc8, 0x18, 0x1d, 0x21, 0x59, 0x87, 0xd2, 0x54, 0xfe, 0xb3, 0xa9, 0xed, 0x6a,
+	0x7b, 0x15, 0x52, 0x87, 0x22, 0x9d, 0xcd, 0x4c, 0x7b, 0xd0, 0xcc, 0xed, 0x6a, 0x7b, 0x3a, 0x59,
+	0x83, 0x02, 0xbf, 0x99, 0x61, 0x33, 0x2f, 0xbe, 0xae, 0x43, 0xe9, 0x0a, 0xbd, 0x00, 0x48, 0xb3,
+	0x10, 0x7e, 0x1e, 0x50, 0x4e, 0x9b, 0xfa, 0xae, 0xb6, 0xb7, 0x46, 0x9e, 0xc2, 0xfa, 0x08, 0x1d,
+	0xf4, 0x28, 0x0f, 0xfc, 0x09, 0x90, 0x35, 0x8b, 0xbb, 0xda, 0x5e, 0xb5, 0xdb, 0x6a, 0x4b, 0x54,
+	0xed, 0x10, 0x55, 0xfb, 0x6d, 0x08, 0x3b, 0xb0, 0xe8, 0xf9, 0x4e, 0x60, 0xb1, 0x14, 0xa8, 0x34,
+	0xfe, 0xa8, 0xc1, 0x96, 0x82, 0x77, 0xe8, 0x21, 0xe5, 0xd8, 0xc3, 0x2f, 0x7d, 0x64, 0x3c, 0x02,
+	0x4d, 0x5b, 0x80, 0x96, 0x5b, 0x86, 0x96, 0x5f, 0x80, 0x56, 0x88, 0x83, 0xa6, 0xdf, 0x03, 0x5a,
+	0x51, 0x40, 0xfb, 0xaf, 0x06, 0x44, 0x41, 0x7b, 0x65, 0x33, 0x9e, 0x0d, 0xd8, 0x26, 0x54, 0xa7,
+	0xb6, 0x63, 0x2e, 0x82, 0x0b, 0x0e, 0xe9, 0x7b, 0x73, 0x91, 0xcc, 0x67, 0xb0, 0xb5, 0x84, 0xd1,
+	0x1c, 0x7a, 0xee, 0x34, 0x03, 0xd0, 0x4f, 0x81, 0x2c, 0xdf, 0xe4, 0x6e, 0x06, 0xee, 0x7f, 0x0a,
+	0x15, 0x0f, 0xa9, 0xcc, 0x8e, 0x66, 0x29, 0x46, 0xfc, 0x45, 0x90, 0x40, 0xaf, 0x29, 0xbb, 0x34,
+	0xfe, 0xa6, 0x81, 0xde, 0xe7, 0x94, 0xe3, 0x8a, 0xcb, 0xeb, 0x50, 0xba, 0xc4, 0x9b, 0x6b, 0xd7,
+	0x1b, 0x28, 0xaf, 0x43, 0xf6, 0xf3, 0x71, 0xec, 0x17, 0x52, 0xc1, 0x35, 0xa0, 0xec, 0xe1, 0x95,
+	0x48, 0xfb, 0xa6, 0x1e, 0x5a, 0xb1, 0xc6, 0xbe, 0x73, 0x89, 0x32, 0x20, 0x65, 0xb2, 0x05, 0x6b,
+	0x96, 0xeb, 0x70, 0x74, 0xb8, 0x29, 0x18, 0x2f, 0x09, 0xdb, 0x3b, 0x50, 0x67, 0xd6, 0x18, 0xa7,
+	0x74, 0xce, 0x6f, 0x59, 0x5c, 0xbf, 0x0d, 0x67, 0x45, 0x84, 0xf3, 0xdf, 0x1a, 0x34, 0x84, 0x3b,
+	0x7d, 0x7a, 0x15, 0x9b, 0x65, 0xdf, 0x86, 0x67, 0x0f, 0x60, 0x03, 0xdf, 0xcf, 0xd0, 0xe2, 0x38,
+	0x30, 0x97, 0x5c, 0xac, 0x81, 0x3e, 0x74, 0x3d, 0x0b, 0x95, 0x83, 0xab, 0xae, 0x88, 0x47, 0x42,
+	0xb6, 0xa1, 0x36, 0x44, 0xc7, 0xb2, 0x9d, 0x91, 0xc9, 0xdd, 0x4b, 0x94, 0x1e, 0xe6, 0x57, 0x3c,
+	0x1c, 0x43, 0x7d, 0xee, 0xe0, 0x61, 0xc0, 0x1c, 0xf9, 0x14, 0x74, 0x16, 0x9c, 0x08, 0xef, 0xaa,
+	0xdd, 0x4f, 0xda, 0x77, 0x96, 0x99, 0xf6, 0x0a, 0x2d, 0xa1, 0xd7, 0x39, 0xe1, 0x75, 0x03, 0xca,
+	0xd6, 0x18, 0xad, 0x4b, 0xe6, 0x4f, 0x65, 0x65, 0x30, 0xde, 0x01, 0x88, 0x3b, 0xd2, 0xca, 0x93,
+	0x45, 0x2b, 0x1f, 0x24, 0x59, 0x49, 0x55, 0x3d, 0x82, 0x75, 0x21, 0x78, 0x82, 0x3c, 0x73, 0x90,
+	0xee, 0x08, 0x4b, 0x3e, 0x2d, 0x2c, 0xc6, 0x33, 0xd8, 0x0e, 0x0d, 0xbd, 0xa2, 0x1c, 0x59, 0x66,
+	0x73, 0x06, 0x85, 0xcd, 0xf0, 0xe6, 0x01, 0x7b, 0x33, 0xcc, 0x0c, 0xf3, 0x11, 0xe8, 0x94, 0x99,
+	0xee, 0x30, 0x03, 0xb8, 0x7f, 0x84, 0xc9, 0x9a, 0x54, 0x79, 0x56, 0x0c, 0xc4, 0x95, 0x94, 0xfc,
+	0x37, 0x2c, 0x29, 0x85, 0xfb, 0x95, 0x14, 0x3d, 0xb5, 0xa4, 0xfc, 0x1e, 0x88, 0xf0, 0xea, 0x08,
+	0x27, 0xc8, 0xf1, 0x5b, 0x8d, 0x6f, 0xa0, 0x65, 0xe0, 0xdd, 0x98, 0x9e, 0x2f, 0x0b, 0x6e, 0xd9,
+	0xf8, 0x8b, 0x06, 0xdf, 0x8f, 0x5a, 0xa7, 0xce, 0x08, 0xbf, 0xc3, 0xd4, 0x46, 0xf0, 0xeb, 0x02,
+	0xff, 0x2f, 0x60, 0x33, 0x0a, 0x1f, 0xd9, 0xcc, 0x75, 0x98, 0x68, 0xea, 0x03, 0x71, 0x12, 0xc1,
+	0x1e, 0x5e, 0xcc, 0x89, 0x8b, 0x7f, 0xd7, 0xa0, 0xf4, 0x52, 0x7a, 0x13, 0x75, 0x4c, 0x8e, 0x00,
+	0xcb, 0x45, 0x35, 0x17, 0x53, 0x54, 0x65, 0x27, 0x6b, 0x40, 0x39, 0xe8, 0x64, 0xcc, 0xfe, 0x1d,
+	0xaa, 0x36, 0x56, 0x03, 0xdd, 0xbd, 0x76, 0xd0, 0x13, 0x20, 0x2b, 0x84, 0x00, 0x78, 0x38, 0xb2,
+	0x19, 0x47, 0x6f, 0x5e, 0xb7, 0x37, 0xa1, 0x2a, 0xea, 0x83, 0x69, 0xb9, 0xbe, 0xc3, 0x55, 0x4d,
+	0x7b, 0x0e, 0x3b, 0x13, 0xf1, 0xec, 0xcc, 0xe5, 0xd0, 0x96, 0x53, 0x5f, 0xc7, 0x8f, 0x80, 0x28,
+	0x7f, 0x12, 0x9e, 0x87, 0x31, 0x83, 0xc6, 0xa1, 0x3b, 0x9d, 0x51, 0x2b, 0x50, 0xfd, 0x99, 0x3b,
+	0xb1, 0xad, 0x9b, 0x55, 0xf7, 0x37, 0xa0, 0x72, 0x89, 0x38, 0x33, 0x27, 0x94, 0x71, 0x35, 0x04,
+	0x7d, 0x08, 0xdb, 0xe2, 0x68, 0x40, 0xed, 0xc9, 0x8d, 0x49, 0x87, 0x1c, 0x3d, 0x73, 0x40, 0x6f,
+	0x98, 0xa2, 0xe0, 0x01, 0x6c, 0x48, 0xbe, 0xa3, 0x9f, 0x04, 0x17, 0xc6, 0x87, 0xf0, 0x83, 0x65,
+	0x8b, 0x11, 0x80, 0x46, 0x17, 0xea, 0xea, 0x73, 0x08, 0x79, 0x05, 0xce, 0x4a, 0xec, 0x7e, 0x05,
+	0x70, 0xab, 0x32, 0x3d, 0x4d, 0x23, 0xc9, 0x20, 0xd0, 0x1a, 0x7f, 0x00, 0xfd, 0x15, 0x52, 0x86,
+	0x77, 0x8d, 0x2d, 0x0e, 0x9d, 0x86, 0xf1, 0xae, 0x43, 0x71, 0xec, 0x4e, 0x06, 0xe8, 0xa9, 0xd1,
+	0x6f, 0xa5, 0xe3, 0x14, 0x44, 0xc7, 0x69, 0x03, 0xe0, 0xfb, 0x99, 0xed, 0x21, 0x33, 0x29, 0x4f,
+	0x9f, 0x54, 0x8c, 0xdf, 0xc2, 0xa6, 0xb0, 0x7e, 0x60, 0x7d, 0xe9, 0xdb, 0x5e, 0xd2, 0x6c, 0x97,
+	0x80, 0x65, 0x13, 0xaa, 0x9c, 0x4f, 0x4c, 0x86, 0x96, 0xeb, 0x0c, 0x42, 0xaa, 0xa7, 0xb0, 0x21,
+	0x34, 0xf7, 0xd0, 0xc1, 0xeb, 0x6f, 0xa6, 0x37, 0xc6, 0xc7, 0x25, 0x73, 0xa2, 0x51, 0x1b, 0x9f,
+	0x2b, 0x47, 0x7a, 0x38, 0x91, 0x7f, 0xfe, 0x7f, 0x06, 0x8d, 0x1d, 0xd8, 0x5a, 0xd4, 0x2d, 0x1f,
+	0xb6, 0xf1, 0x9f, 0x1c, 0xe4, 0x7b, 0xbe, 0x43, 0x00, 0x72, 0x73, 0x03, 0xcb, 0x03, 0x7b, 0x03,
+	0xca, 0x33, 0x7b, 0x86, 0x13, 0xdb, 0x09, 0x87, 0xf6, 0x0e, 0x14, 0x83, 0xc7, 0xe6, 0x4b, 0xa2,
+	0xea, 0xdd, 0xdd, 0x98, 0x6e, 0xdc, 0xf3, 0x9d, 0xbe, 0x90, 0x0b, 0x5e, 0x30, 0x7a, 0x9e, 0x1b,
+	0xbe, 0xe0, 0x36, 0x00, 0xe3, 0xd4, 0x0b, 0xa6, 0x15, 0xca, 0x33, 0x4c, 0x95, 0xfb, 0x50, 0x1d,
+	0xda, 0x8e, 0xcd, 0xc6, 0xf2, 0x42, 0x29, 0xf5, 0xc2, 0x73, 0x28, 0x8b, 0x42, 0x80, 0x1e, 0x6b,
+	0x96, 0x77, 0xf3, 0x7b, 0xd5, 0xee, 0x5e, 0x3c, 0xc6, 0xf6, 0xa1, 0x12, 0x3d, 0x76, 0xb8, 0x77,
+	0x13, 0x50, 0xa8, 0x36, 0x18, 0x55, 0x4c, 0x2a, 0xe1, 0x80, 0x1d, 0xad, 0x30, 0x10, 0x1c, 0xb6,
+	0xf6, 0xa1, 0xb6, 0x78, 0xb9, 0x0a, 0xf9, 0x4b, 0xbc, 0x51, 0x2f, 0xad, 0x06, 0xfa, 0x15, 0x9d,
+	0xf8, 0x32, 0x56, 0xf9, 0xe7, 0xb9, 0x67, 0x9a, 0xf1, 0x14, 0xd6, 0x25, 0x2b, 0x5e, 0x6c, 0xcb,
+	0x8d, 0xf2, 0x2d, 0x87, 0x81, 0x7f, 0x6a, 0xd0, 0xe8, 0xf9, 0xce, 0x0b, 0x41, 0x41, 0x78, 0x2d,
+	0x1a, 0xb2, 0xdb, 0x80, 0xe4, 0xee, 0x1b, 0x10, 0x19, 0xd1, 0x93, 0x08, 0x5f, 0x05, 0xc1, 0xd7,
+	0xcf, 0xe3, 0x55, 0x2c, 0xe0, 0x58, 0x24, 0xef, 0xfe, 0x84, 0x7c, 0xad, 0x41, 0xbd, 0xe7, 0x3b,
+	0x49, 0x33, 0xc8, 0x0a, 0x21, 0x11, 0x7f, 0xf3, 0x19, 0xfd, 0xed, 0xc0, 0x5a, 0x98, 0x71, 0xa2,
+	0xa7, 0xa6, 0xf7, 0xc6, 0x48, 0x8e, 0x72, 0x37, 0xc3, 0xc6, 0xd4, 0x84, 0xc6, 0xb5, 0xcd, 0xc7,
+	0xae, 0xcf, 0x4d, 0x95, 0x3e, 0x4c, 0xf6, 0x26, 0xe3, 0x97, 0xd0, 0x0c, 0x3c, 0xa4, 0x8c, 0xf7,
+	0x7d, 0xcb, 0x42, 0xc6, 0x86, 0xfe, 0x24, 0x7b, 0xf0, 0xff, 0xa5, 0x01, 0x1c, 0xf8, 0x03, 0x9b,
+	0x1f, 0x5f, 0xa1, 0x13, 0x0d, 0x7b, 0x3e, 0x78, 0x16, 0xae, 0x65, 0xf9, 0x9e, 0x27, 0x9f, 0x45,
+	0x2e, 0x15, 0x63, 0x0d, 0x74, 0x6a, 0xf1, 0x79, 0xd4, 0xab, 0x90, 0xf7, 0x66, 0x56, 0xb3, 0x10,
+	0x56, 0x12, 0x85, 0x44, 0x9f, 0x97, 0x01, 0xd1, 0x11, 0x9a, 0xc5, 0xf0, 0x3b, 0xa7, 0xde, 0x08,
+	0xb9, 0xda, 0x89, 0x6a, 0xa0, 0xcb, 0x97, 0x50, 0x0e, 0x9f, 0xc7, 0x05, 0x0e, 0x5d, 0x0f, 0xcd,
+	0x31, 0x65, 0xe3, 0x66, 0x25, 0xec, 0xd4, 0xb2, 0x81, 0x89, 0x33, 0x08, 0xf5, 0x78, 0x48, 0x99,
+	0xeb, 0x34, 0xab, 0xc2, 0xbf, 0xbf, 0x6a, 0xb0, 0x7d, 0xeb, 0x5f, 0x52, 0x1e, 0xcc, 0xd1, 0xcf,
+	0x4b, 0x9f, 0x02, 0x98, 0x5f, 0x02, 0x28, 0x1d, 0xfa, 0x19, 0xd4, 0xe6, 0xec, 0x64, 0xdc, 0x7a,
+	0xa3, 0x84, 0x66, 0x5a, 0x77, 0x6b, 0xa0, 0x4f, 0xec, 0xa9, 0xad, 0x06, 0x8e, 0xc7, 0x5f, 0x40,
+	0xe5, 0x36, 0xe5, 0x5a, 0xb0, 0xd3, 0x3b, 0x3f, 0x33, 0xfb, 0x6f, 0x0f, 0xde, 0x9e, 0xf7, 0xcd,
+	0xf3, 0xb3, 0xfe, 0x67, 0xc7, 0x87, 0xa7, 0x2f, 0x4e, 0x8f, 0x8f, 0x1a, 0xdf, 0x23, 0xeb, 0x50,
+	0x0d, 0xbe, 0xf5, 0xce, 0xcf, 0xce, 0x4e, 0xcf, 0x4e, 0x1a, 0x1a, 0xd9, 0x80, 0x9a, 0x10, 0x3e,
+	0x3f, 0x3c, 0x3c, 0x3e, 0x3e, 0x3a, 0x3e, 0x6a, 0xe4, 0x48, 0x1d, 0x20, 0x38, 0x7a, 0x71, 0x70,
+	0xfa, 0xea, 0xf8, 0xa8, 0x91, 0xef, 0x7e, 0xa5, 0x41, 0xf3, 0xe0, 0xf4, 0x48, 0xe5, 0xb6, 0xfa,
+	0xd5, 0xa0, 0x2f, 0x7f, 0xe2, 0x21, 0xe7, 0x50, 0x94, 0xbf, 0x6d, 0x90, 0x27, 0x31, 0x6f, 0xe1,
+	0xae, 0x5f, 0x40, 0x5a, 0x1f, 0x25, 0x0b, 0x93, 0x3e, 0x14, 0x82, 0x88, 0x90, 0x47, 0xc9, 0x72,
+	0x91, 0xa8, 0xa5, 0xa9, 0xec, 0x68, 0xdd, 0xaf, 0x2a, 0xb0, 0x73, 0xeb, 0x88, 0x5c, 0x0c, 0x95,
+	0x1b, 0xaf, 0xa1, 0x10, 0xec, 0x88, 0x24, 0xeb, 0x16, 0xd9, 0x4a, 0x5e, 0x04, 0x5f, 0x42, 0xfe,
+	0x04, 0x39, 0xf9, 0x71, 0x92, 0xd0, 0xed, 0x12, 0x98, 0xa2, 0xec, 0x37, 0x50, 0x99, 0xef, 0x71,
+	0xe4, 0x27, 0x29, 0x2a, 0x17, 0xd6, 0xbd, 0x14, 0xc5, 0x7d, 0x28, 0xa9, 0x35, 0x8f, 0x3c, 0x4e,
+	0x51, 0x1b, 0xd9, 0x05, 0x53, 0x94, 0xbe, 0x85, 0xea, 0x7c, 0x47, 0xc7, 0x01, 0xf9, 0x38, 0x8d,
+	0x50, 0x21, 0x98, 0xac, 0x73, 0x4f, 0x23, 0xef, 0x00, 0x4e, 0x90, 0x87, 0x4a, 0xb3, 0xf2, 0xfa,
+	0x30, 0x49, 0x4e, 0x28, 0xeb, 0x68, 0xe4, 0x8d, 0x4a, 0xb5, 0xc4, 0xd0, 0x47, 0x13, 0x2d, 0x11,
+	0x6b, 0x47, 0x23, 0x5f, 0xc0, 0x5a, 0x20, 0xae, 0xa6, 0x78, 0x16, 0x9b, 0xc3, 0xab, 0x63, 0x7e,
+	0xeb, 0xa3, 0x64, 0xd1, 0x8e, 0x46, 0x4c, 0x28, 0xca, 0x1d, 0x89, 0x3c, 0x4a, 0x82, 0xb1, 0xb0,
+	0x84, 0xb6, 0x1e, 0x67, 0x11, 0x55, 0x2b, 0x97, 0x0d, 0xd5, 0xc8, 0x0e, 0x49, 0xda, 0x19, 0xae,
+	0x46, 0x96, 0xcd, 0x7b, 0x99, 0x1a, 0xc1, 0x66, 0x1f, 0xf9, 0xca, 0x1e, 0x13, 0x17, 0x88, 0x65,
+	0xc1, 0x56, 0x56, 0x41, 0x72, 0x0d, 0x3b, 0x01, 0xcb, 0x4b, 0xe7, 0x36, 0x32, 0xd2, 0xcd, 0xa8,
+	0x22, 0x1a, 0xa4, 0xac, 0x66, 0x3b, 0x1a, 0x39, 0x87, 0x92, 0x3a, 0x8d, 0x7d, 0x08, 0x8b, 0x5b,
+	0x53, 0xeb, 0x61, 0xb2, 0x98, 0xed, 0x3a, 0x1d, 0xad, 0xfb, 0xe7, 0x5c, 0xb4, 0x90, 0x89, 0x01,
+	0x3b, 0x2c, 0x64, 0x7d, 0x28, 0xa9, 0x85, 0x24, 0xf6, 0x4d, 0xdf, 0xb1, 0xb5, 0xb4, 0x3e, 0x48,
+	0x92, 0x25, 0x6f, 0x40, 0x17, 0xbb, 0x08, 0xd9, 0x4b, 0x12, 0x8b, 0xae, 0x2b, 0x29, 0x0a, 0x2f,
+	0xa0, 0xa4, 0x36, 0x82, 0x64, 0x94, 0x8b, 0x2b, 0x49, 0xeb, 0x49, 0x26, 0x59, 0x99, 0x5d, 0xdd,
+	0xaf, 0x73, 0xb0, 0x75, 0x4b, 0x52, 0xd0, 0x1e, 0x15, 0x45, 0x2f, 0xc5, 0x4f, 0xbf, 0x5e, 0x7c,
+	0x79, 0x5e, 0x1a, 0x94, 0x5b, 0xad, 0x78, 0x39, 0xf2, 0x1a, 0x8a, 0x72, 0x2c, 0x25, 0x9f, 0x64,
+	0x1c, 0x5c, 0x13, 0xd5, 0xbd, 0x54, 0xc5, 0xe8, 0xe3, 0x78, 0x99, 0x68, 0x2a, 0x26, 0xa8, 0x12,
+	0xb5, 0xa2, 0xbe, 0x38, 0xfc, 0x91, 0xfd, 0x04, 0xb5, 0x77, 0x8d, 0x89, 0xc9, 0x06, 0xba, 0x2c,
+	0x9a, 0x86, 0x62, 0x96, 0x0a, 0x39, 0x7e, 0xa7, 0xfc, 0x88, 0x6b, 0x57, 0x77, 0x0e, 0x5e, 0xad,
+	0x87, 0xa9, 0xd2, 0x1d, 0xed, 0xd7, 0x8f, 0x61, 0xd7, 0x76, 0x63, 0x04, 0xd5, 0x7f, 0x3c, 0x7d,
+	0x5e, 0x14, 0x23, 0x13, 0xbb, 0x90, 0x7f, 0x9f, 0xfe, 0x6f, 0x00, 0xda, 0x32, 0x4f, 0xf5, 0x9e,
+	0x1a, 0x00, 0x00,
 }
@@ -3,6 +3,7 @@
 
 import sys
 _b=sys.version_info[0]<3 and (lambda x:x) or (lambda x:x.encode('latin1'))
+from google.protobuf.internal import enum_type_wrapper
 from google.protobuf import descriptor as _descriptor
 from google.protobuf import message as _message
 from google.protobuf import reflection as _reflection
@@ -14,15 +15,53 @@ _sym_db = _symbol_database.Default()
 
 
 from google.protobuf import timestamp_pb2 as google_dot_protobuf_dot_timestamp__pb2
+from google.protobuf import field_mask_pb2 as google_dot_protobuf_dot_field__mask__pb2
 
 
 DESCRIPTOR = _descriptor.FileDescriptor(
   name='ai_decision_service.proto',
   package='callstats.ai_decision',
   syntax='proto3',
-  serialized_pb=_b('\n\x19\x61i_decision_service.proto\x12\x15\x63\x61llstats.ai_decision\x1a\x1fgoogle/protobuf/timestamp.proto\"\x8c\x01\n\x07Message\x12\x0f\n\x07message\x18\x01 \x01(\t\x12\x0e\n\x06\x61pp_id\x18\x02 \x01(\x05\x12\x0c\n\x04type\x18\x03 \x01(\t\x12\x0f\n\x07version\x18\x04 \x01(\x05\x12\x0c\n\x04\x64\x61ta\x18\x05 \x01(\x0c\x12\x33\n\x0fgeneration_time\x18\x06 \x01(\x0b\x32\x1a.google.protobuf.Timestamp\"\x88\x01\n\x14MessageCreateRequest\x12\x0e\n\x06\x61pp_id\x18\x01 \x01(\x05\x12\x0c\n\x04type\x18\x02 \x01(\t\x12\x0f\n\x07version\x18\x03 \x01(\x05\x12\x0c\n\x04\x64\x61ta\x18\x04 \x01(\x0c\x12\x33\n\x0fgeneration_time\x18\x05 \x01(\x0b\x32\x1a.google.protobuf.Timestamp\"\xce\x01\n\x12MessageListRequest\x12\x0e\n\x06\x61pp_id\x18\x01 \x01(\x05\x12\x0c\n\x04type\x18\x02 \x01(\t\x12\x13\n\x0bmin_version\x18\x03 \x01(\x05\x12\x13\n\x0bmax_version\x18\x04 \x01(\x05\x12\x38\n\x14generation_time_from\x18\x05 \x01(\x0b\x32\x1a.google.protobuf.Timestamp\x12\x36\n\x12generation_time_to\x18\x06 \x01(\x0b\x32\x1a.google.protobuf.Timestamp\"k\n\x05State\x12\x0e\n\x06\x61pp_id\x18\x01 \x01(\x05\x12\x0f\n\x07keyword\x18\x02 \x01(\t\x12\x0c\n\x04\x64\x61ta\x18\x03 \x01(\x0c\x12\x33\n\x0fgeneration_time\x18\x04 \x01(\x0b\x32\x1a.google.protobuf.Timestamp\"v\n\x10StateSaveRequest\x12\x0e\n\x06\x61pp_id\x18\x01 \x01(\x05\x12\x0f\n\x07keyword\x18\x02 \x01(\t\x12\x0c\n\x04\x64\x61ta\x18\x03 \x01(\x0c\x12\x33\n\x0fgeneration_time\x18\x04 \x01(\x0b\x32\x1a.google.protobuf.Timestamp\"g\n\x0fStateGetRequest\x12\x0e\n\x06\x61pp_id\x18\x01 \x01(\x05\x12\x0f\n\x07keyword\x18\x02 \x01(\t\x12\x33\n\x0fgeneration_time\x18\x03 \x01(\x0b\x32\x1a.google.protobuf.Timestamp\"\xa5\x01\n\x10StateListRequest\x12\x0e\n\x06\x61pp_id\x18\x01 \x01(\x05\x12\x0f\n\x07keyword\x18\x02 \x01(\t\x12\x38\n\x14generation_time_from\x18\x03 \x01(\x0b\x32\x1a.google.protobuf.Timestamp\x12\x36\n\x12generation_time_to\x18\x04 \x01(\x0b\x32\x1a.google.protobuf.Timestamp2\xc6\x01\n\x18\x41IDecisionMessageService\x12U\n\x06\x43reate\x12+.callstats.ai_decision.MessageCreateRequest\x1a\x1e.callstats.ai_decision.Message\x12S\n\x04List\x12).callstats.ai_decision.MessageListRequest\x1a\x1e.callstats.ai_decision.Message0\x01\x32\x85\x02\n\x16\x41IDecisionStateService\x12M\n\x04Save\x12\'.callstats.ai_decision.StateSaveRequest\x1a\x1c.callstats.ai_decision.State\x12K\n\x03Get\x12&.callstats.ai_decision.StateGetRequest\x1a\x1c.callstats.ai_decision.State\x12O\n\x04List\x12\'.callstats.ai_decision.StateListRequest\x1a\x1c.callstats.ai_decision.State0\x01\x42*\n io.callstats.ai_decision.serviceZ\x06protosb\x06proto3')
+  serialized_pb=_b('\n\x19\x61i_decision_service.proto\x12\x15\x63\x61llstats.ai_decision\x1a\x1fgoogle/protobuf/timestamp.proto\x1a google/protobuf/field_mask.proto\"\x9c\x01\n\x07Message\x12\x0f\n\x07message\x18\x01 \x01(\t\x12\x0e\n\x06\x61pp_id\x18\x02 \x01(\x05\x12\x0c\n\x04type\x18\x03 \x01(\t\x12\x0f\n\x07version\x18\x04 \x01(\x05\x12\x0c\n\x04\x64\x61ta\x18\x05 \x01(\x0c\x12\x33\n\x0fgeneration_time\x18\x06 \x01(\x0b\x32\x1a.google.protobuf.Timestamp\x12\x0e\n\x06run_id\x18\x07 \x01(\x05\"\x98\x01\n\x14MessageCreateRequest\x12\x0e\n\x06\x61pp_id\x18\x01 \x01(\x05\x12\x0c\n\x04type\x18\x02 \x01(\t\x12\x0f\n\x07version\x18\x03 \x01(\x05\x12\x0c\n\x04\x64\x61ta\x18\x04 \x01(\x0c\x12\x33\n\x0fgeneration_time\x18\x05 \x01(\x0b\x32\x1a.google.protobuf.Timestamp\x12\x0e\n\x06run_id\x18\x06 \x01(\x05\"\xfd\x01\n\x12MessageListRequest\x12\x0e\n\x06\x61pp_id\x18\x01 \x01(\x05\x12\x0c\n\x04type\x18\x02 \x01(\t\x12\x13\n\x0bmin_version\x18\x03 \x01(\x05\x12\x13\n\x0bmax_version\x18\x04 \x01(\x05\x12\x38\n\x14generation_time_from\x18\x05 \x01(\x0b\x32\x1a.google.protobuf.Timestamp\x12\x36\n\x12generation_time_to\x18\x06 \x01(\x0b\x32\x1a.google.protobuf.Timestamp\x12-\n\tread_mask\x18\x07 \x01(\x0b\x32\x1a.google.protobuf.FieldMask\"\xcc\x01\n\x05State\x12\x0e\n\x06\x61pp_id\x18\x01 \x01(\x05\x12\x0f\n\x07keyword\x18\x02 \x01(\t\x12\x0c\n\x04\x64\x61ta\x18\x03 \x01(\x0c\x12\x33\n\x0fgeneration_time\x18\x04 \x01(\x0b\x32\x1a.google.protobuf.Timestamp\x12\x10\n\x08revision\x18\x05 \x01(\x05\x12\x0f\n\x07\x63hunked\x18\x06 \x01(\x08\x12\x14\n\x0c\x63ontent_type\x18\x07 \x01(\t\x12\x16\n\x0eschema_version\x18\x08 \x01(\x05\x12\x0e\n\x06run_id\x18\t \x01(\x05\"\xdf\x01\n\x10StateSaveRequest\x12\x0e\n\x06\x61pp_id\x18\x01 \x01(\x05\x12\x0f\n\x07keyword\x18\x02 \x01(\t\x12\x0c\n\x04\x64\x61ta\x18\x03 \x01(\x0c\x12\x33\n\x0fgeneration_time\x18\x04 \x01(\x0b\x32\x1a.google.protobuf.Timestamp\x12\x19\n\x11\x65xpected_revision\x18\x05 \x01(\x05\x12\r\n\x05\x66orce\x18\x06 \x01(\x08\x12\x16\n\x0eschema_version\x18\x07 \x01(\x05\x12\x15\n\rfencing_token\x18\x08 \x01(\x03\x12\x0e\n\x06run_id\x18\t \x01(\x05\"h\n\x0eStateSaveChunk\x12\x36\n\x05state\x18\x01 \x01(\x0b\x32\'.callstats.ai_decision.StateSaveRequest\x12\x0c\n\x04\x64\x61ta\x18\x02 \x01(\x0c\x12\x10\n\x08\x63hecksum\x18\x03 \x01(\t\"Y\n\nStateChunk\x12+\n\x05state\x18\x01 \x01(\x0b\x32\x1c.callstats.ai_decision.State\x12\x0c\n\x04\x64\x61ta\x18\x02 \x01(\x0c\x12\x10\n\x08\x63hecksum\x18\x03 \x01(\t\"g\n\x0fStateGetRequest\x12\x0e\n\x06\x61pp_id\x18\x01 \x01(\x05\x12\x0f\n\x07keyword\x18\x02 \x01(\t\x12\x33\n\x0fgeneration_time\x18\x03 \x01(\x0b\x32\x1a.google.protobuf.Timestamp\"8\n\x15StateGetLatestRequest\x12\x0e\n\x06\x61pp_id\x18\x01 \x01(\x05\x12\x0f\n\x07keyword\x18\x02 \x01(\t\"a\n\x13StateGetAsOfRequest\x12\x0e\n\x06\x61pp_id\x18\x01 \x01(\x05\x12\x0f\n\x07keyword\x18\x02 \x01(\t\x12)\n\x05\x61s_of\x18\x03 \x01(\x0b\x32\x1a.google.protobuf.Timestamp\"\xd4\x01\n\x10StateListRequest\x12\x0e\n\x06\x61pp_id\x18\x01 \x01(\x05\x12\x0f\n\x07keyword\x18\x02 \x01(\t\x12\x38\n\x14generation_time_from\x18\x03 \x01(\x0b\x32\x1a.google.protobuf.Timestamp\x12\x36\n\x12generation_time_to\x18\x04 \x01(\x0b\x32\x1a.google.protobuf.Timestamp\x12-\n\tread_mask\x18\x05 \x01(\x0b\x32\x1a.google.protobuf.FieldMask\"{\n\x12StateDeleteRequest\x12\x0e\n\x06\x61pp_id\x18\x01 \x01(\x05\x12\x0f\n\x07keyword\x18\x02 \x01(\t\x12\x33\n\x0fgeneration_time\x18\x03 \x01(\x0b\x32\x1a.google.protobuf.Timestamp\x12\x0f\n\x07\x64ry_run\x18\x04 \x01(\x08\"\xbd\x01\n\x17StateDeleteRangeRequest\x12\x0e\n\x06\x61pp_id\x18\x01 \x01(\x05\x12\x0f\n\x07keyword\x18\x02 \x01(\t\x12\x38\n\x14generation_time_from\x18\x03 \x01(\x0b\x32\x1a.google.protobuf.Timestamp\x12\x36\n\x12generation_time_to\x18\x04 \x01(\x0b\x32\x1a.google.protobuf.Timestamp\x12\x0f\n\x07\x64ry_run\x18\x05 \x01(\x08\"7\n\x13StateDeleteResponse\x12\x0f\n\x07\x64\x65leted\x18\x01 \x01(\x05\x12\x0f\n\x07\x64ry_run\x18\x02 \x01(\x08\"\xce\x01\n\x07Keyword\x12\x0f\n\x07keyword\x18\x01 \x01(\t\x12\x14\n\x0c\x63ontent_type\x18\x02 \x01(\t\x12\x16\n\x0eschema_version\x18\x03 \x01(\x05\x12\x10\n\x08max_size\x18\x04 \x01(\x05\x12\r\n\x05owner\x18\x05 \x01(\t\x12\x12\n\nregistered\x18\x06 \x01(\x08\x12\x13\n\x0bstate_count\x18\x07 \x01(\x05\x12:\n\x16latest_generation_time\x18\x08 \x01(\x0b\x32\x1a.google.protobuf.Timestamp\"$\n\x12KeywordListRequest\x12\x0e\n\x06\x61pp_id\x18\x01 \x01(\x05\"p\n\x10\x43ompactionPolicy\x12\x0f\n\x07keyword\x18\x01 \x01(\t\x12\x11\n\tkeep_last\x18\x02 \x01(\x05\x12\x1d\n\x15keep_daily_after_days\x18\x03 \x01(\x05\x12\x19\n\x11\x64\x65lete_after_days\x18\x04 \x01(\x05\"\x1d\n\x1b\x43ompactionPolicyListRequest\"2\n\x0e\x43ompactRequest\x12\x0f\n\x07keyword\x18\x01 \x01(\t\x12\x0f\n\x07\x64ry_run\x18\x02 \x01(\x08\">\n\nCompaction\x12\x0e\n\x06\x61pp_id\x18\x01 \x01(\x05\x12\x0f\n\x07keyword\x18\x02 \x01(\t\x12\x0f\n\x07\x64\x65leted\x18\x03 \x01(\x05\"|\n\x05Lease\x12\x0e\n\x06\x61pp_id\x18\x01 \x01(\x05\x12\x0c\n\x04name\x18\x02 \x01(\t\x12\x0e\n\x06holder\x18\x03 \x01(\t\x12\x15\n\rfencing_token\x18\x04 \x01(\x03\x12.\n\nexpires_at\x18\x05 \x01(\x0b\x32\x1a.google.protobuf.Timestamp\"X\n\x13LeaseAcquireRequest\x12\x0e\n\x06\x61pp_id\x18\x01 \x01(\x05\x12\x0c\n\x04name\x18\x02 \x01(\t\x12\x0e\n\x06holder\x18\x03 \x01(\t\x12\x13\n\x0bttl_seconds\x18\x04 \x01(\x05\"m\n\x11LeaseRenewRequest\x12\x0e\n\x06\x61pp_id\x18\x01 \x01(\x05\x12\x0c\n\x04name\x18\x02 \x01(\t\x12\x0e\n\x06holder\x18\x03 \x01(\t\x12\x15\n\rfencing_token\x18\x04 \x01(\x03\x12\x13\n\x0bttl_seconds\x18\x05 \x01(\x05\"Z\n\x13LeaseReleaseRequest\x12\x0e\n\x06\x61pp_id\x18\x01 \x01(\x05\x12\x0c\n\x04name\x18\x02 \x01(\t\x12\x0e\n\x06holder\x18\x03 \x01(\t\x12\x15\n\rfencing_token\x18\x04 \x01(\x03\"\x16\n\x14LeaseReleaseResponse\"\xee\x02\n\x03Run\x12\n\n\x02id\x18\x01 \x01(\x05\x12\x0e\n\x06\x61pp_id\x18\x02 \x01(\x05\x12\x10\n\x08pipeline\x18\x03 \x01(\t\x12\x30\n\x06status\x18\x04 \x01(\x0e\x32 .callstats.ai_decision.RunStatus\x12\r\n\x05\x65rror\x18\x05 \x01(\t\x12.\n\nstarted_at\x18\x06 \x01(\x0b\x32\x1a.google.protobuf.Timestamp\x12/\n\x0b\x66inished_at\x18\x07 \x01(\x0b\x32\x1a.google.protobuf.Timestamp\x12:\n\x08\x63ounters\x18\x08 \x03(\x0b\x32(.callstats.ai_decision.Run.CountersEntry\x12\x15\n\rmessage_count\x18\t \x01(\x05\x12\x13\n\x0bstate_count\x18\n \x01(\x05\x1a/\n\rCountersEntry\x12\x0b\n\x03key\x18\x01 \x01(\t\x12\r\n\x05value\x18\x02 \x01(\x03:\x02\x38\x01\"3\n\x0fRunStartRequest\x12\x0e\n\x06\x61pp_id\x18\x01 \x01(\x05\x12\x10\n\x08pipeline\x18\x02 \x01(\t\"\xd9\x01\n\x10RunFinishRequest\x12\n\n\x02id\x18\x01 \x01(\x05\x12\x30\n\x06status\x18\x02 \x01(\x0e\x32 .callstats.ai_decision.RunStatus\x12\r\n\x05\x65rror\x18\x03 \x01(\t\x12G\n\x08\x63ounters\x18\x04 \x03(\x0b\x32\x35.callstats.ai_decision.RunFinishRequest.CountersEntry\x1a/\n\rCountersEntry\x12\x0b\n\x03key\x18\x01 \x01(\t\x12\r\n\x05value\x18\x02 \x01(\x03:\x02\x38\x01\"\xe0\x01\n\x0eRunListRequest\x12\x0e\n\x06\x61pp_id\x18\x01 \x01(\x05\x12\x10\n\x08pipeline\x18\x02 \x01(\t\x12\x30\n\x06status\x18\x03 \x01(\x0e\x32 .callstats.ai_decision.RunStatus\x12\x30\n\x0cstarted_from\x18\x04 \x01(\x0b\x32\x1a.google.protobuf.Timestamp\x12.\n\nstarted_to\x18\x05 \x01(\x0b\x32\x1a.google.protobuf.Timestamp\x12\x18\n\x10without_messages\x18\x06 \x01(\x08\"<\n\x18RunLastSuccessfulRequest\x12\x0e\n\x06\x61pp_id\x18\x01 \x01(\x05\x12\x10\n\x08pipeline\x18\x02 \x01(\t\"\xdd\x01\n\nAuditEvent\x12\n\n\x02id\x18\x01 \x01(\x03\x12/\n\x0boccurred_at\x18\x02 \x01(\x0b\x32\x1a.google.protobuf.Timestamp\x12\r\n\x05\x61\x63tor\x18\x03 \x01(\t\x12\x0b\n\x03rpc\x18\x04 \x01(\t\x12\x0e\n\x06\x61pp_id\x18\x05 \x01(\x05\x12\x0e\n\x06\x61\x63tion\x18\x06 \x01(\t\x12\x0e\n\x06target\x18\x07 \x01(\t\x12\r\n\x05\x63ount\x18\x08 \x01(\x05\x12\x13\n\x0b\x62\x65\x66ore_hash\x18\t \x01(\t\x12\x12\n\nafter_hash\x18\n \x01(\t\x12\x0e\n\x06reason\x18\x0b \x01(\t\"\xc9\x01\n\x15\x41uditEventListRequest\x12\x0e\n\x06\x61pp_id\x18\x01 \x01(\x05\x12\r\n\x05\x61\x63tor\x18\x02 \x01(\t\x12\x0e\n\x06\x61\x63tion\x18\x03 \x01(\t\x12\x0e\n\x06target\x18\x04 \x01(\t\x12\x31\n\roccurred_from\x18\x05 \x01(\x0b\x32\x1a.google.protobuf.Timestamp\x12/\n\x0boccurred_to\x18\x06 \x01(\x0b\x32\x1a.google.protobuf.Timestamp\x12\r\n\x05limit\x18\x07 \x01(\x05*[\n\tRunStatus\x12\x1a\n\x16RUN_STATUS_UNSPECIFIED\x10\x00\x12\x0f\n\x0bRUN_RUNNING\x10\x01\x12\x11\n\rRUN_SUCCEEDED\x10\x02\x12\x0e\n\nRUN_FAILED\x10\x03\x32\xc6\x01\n\x18\x41IDecisionMessageService\x12U\n\x06\x43reate\x12+.callstats.ai_decision.MessageCreateRequest\x1a\x1e.callstats.ai_decision.Message\x12S\n\x04List\x12).callstats.ai_decision.MessageListRequest\x1a\x1e.callstats.ai_decision.Message0\x01\x32\xc6\t\n\x16\x41IDecisionStateService\x12M\n\x04Save\x12\'.callstats.ai_decision.StateSaveRequest\x1a\x1c.callstats.ai_decision.State\x12K\n\x03Get\x12&.callstats.ai_decision.StateGetRequest\x1a\x1c.callstats.ai_decision.State\x12W\n\tGetLatest\x12,.callstats.ai_decision.StateGetLatestRequest\x1a\x1c.callstats.ai_decision.State\x12S\n\x07GetAsOf\x12*.callstats.ai_decision.StateGetAsOfRequest\x1a\x1c.callstats.ai_decision.State\x12T\n\x0bSaveChunked\x12%.callstats.ai_decision.StateSaveChunk\x1a\x1c.callstats.ai_decision.State(\x01\x12Y\n\nGetChunked\x12&.callstats.ai_decision.StateGetRequest\x1a!.callstats.ai_decision.StateChunk0\x01\x12O\n\x04List\x12\'.callstats.ai_decision.StateListRequest\x1a\x1c.callstats.ai_decision.State0\x01\x12[\n\x0cListKeywords\x12).callstats.ai_decision.KeywordListRequest\x1a\x1e.callstats.ai_decision.Keyword0\x01\x12_\n\x06\x44\x65lete\x12).callstats.ai_decision.StateDeleteRequest\x1a*.callstats.ai_decision.StateDeleteResponse\x12i\n\x0b\x44\x65leteRange\x12..callstats.ai_decision.StateDeleteRangeRequest\x1a*.callstats.ai_decision.StateDeleteResponse\x12g\n\x13SetCompactionPolicy\x12\'.callstats.ai_decision.CompactionPolicy\x1a\'.callstats.ai_decision.CompactionPolicy\x12w\n\x16ListCompactionPolicies\x12\x32.callstats.ai_decision.CompactionPolicyListRequest\x1a\'.callstats.ai_decision.CompactionPolicy0\x01\x12U\n\x07\x43ompact\x12%.callstats.ai_decision.CompactRequest\x1a!.callstats.ai_decision.Compaction0\x01\x32\xa2\x02\n\x16\x41IDecisionLeaseService\x12S\n\x07\x41\x63quire\x12*.callstats.ai_decision.LeaseAcquireRequest\x1a\x1c.callstats.ai_decision.Lease\x12O\n\x05Renew\x12(.callstats.ai_decision.LeaseRenewRequest\x1a\x1c.callstats.ai_decision.Lease\x12\x62\n\x07Release\x12*.callstats.ai_decision.LeaseReleaseRequest\x1a+.callstats.ai_decision.LeaseReleaseResponse2\xe0\x02\n\x14\x41IDecisionRunService\x12K\n\x05Start\x12&.callstats.ai_decision.RunStartRequest\x1a\x1a.callstats.ai_decision.Run\x12M\n\x06\x46inish\x12\'.callstats.ai_decision.RunFinishRequest\x1a\x1a.callstats.ai_decision.Run\x12K\n\x04List\x12%.callstats.ai_decision.RunListRequest\x1a\x1a.callstats.ai_decision.Run0\x01\x12_\n\x0eLastSuccessful\x12/.callstats.ai_decision.RunLastSuccessfulRequest\x1a\x1a.callstats.ai_decision.Run0\x01\x32s\n\x16\x41IDecisionAuditService\x12Y\n\x04List\x12,.callstats.ai_decision.AuditEventListRequest\x1a!.callstats.ai_decision.AuditEvent0\x01\x42*\n io.callstats.ai_decision.serviceZ\x06protosb\x06proto3')
   ,
-  dependencies=[google_dot_protobuf_dot_timestamp__pb2.DESCRIPTOR,])
+  dependencies=[google_dot_protobuf_dot_timestamp__pb2.DESCRIPTOR,google_dot_protobuf_dot_field__mask__pb2.DESCRIPTOR,])
+
+
+_RUNSTATUS = _descriptor.EnumDescriptor(
+  name='RunStatus',
+  full_name='callstats.ai_decision.RunStatus',
+  filename=None,
+  file=DESCRIPTOR,
+  values=[
+    _descriptor.EnumValueDescriptor(
+      name='RUN_STATUS_UNSPECIFIED', index=0, number=0,
+      options=None,
+      type=None),
+    _descriptor.EnumValueDescriptor(
+      name='RUN_RUNNING', index=1, number=1,
+      options=None,
+      type=None),
+    _descriptor.EnumValueDescriptor(
+      name='RUN_SUCCEEDED', index=2, number=2,
+      options=None,
+      type=None),
+    _descriptor.EnumValueDescriptor(
+      name='RUN_FAILED', index=3, number=3,
+      options=None,
+      type=None),
+  ],
+  containing_type=None,
+  options=None,
+  serialized_start=4480,
+  serialized_end=4571,
+)
+_sym_db.RegisterEnumDescriptor(_RUNSTATUS)
+
+RunStatus = enum_type_wrapper.EnumTypeWrapper(_RUNSTATUS)
+RUN_STATUS_UNSPECIFIED = 0
+RUN_RUNNING = 1
+RUN_SUCCEEDED = 2
+RUN_FAILED = 3
 
 
 
@@ -76,6 +115,13 @@ _MESSAGE = _descriptor.Descriptor(
       message_type=None, enum_type=None, containing_type=None,
       is_extension=False, extension_scope=None,
       options=None, file=DESCRIPTOR),
+    _descriptor.FieldDescriptor(
+      name='run_id', full_name='callstats.ai_decision.Message.run_id', index=6,
+      number=7, type=5, cpp_type=1, label=1,
+      has_default_value=False, default_value=0,
+      message_type=None, enum_type=None, containing_type=None,
+      is_extension=False, extension_scope=None,
+      options=None, file=DESCRIPTOR),
   ],
   extensions=[
   ],
@@ -88,8 +134,8 @@ _MESSAGE = _descriptor.Descriptor(
   extension_ranges=[],
   oneofs=[
   ],
-  serialized_start=86,
-  serialized_end=226,
+  serialized_start=120,
+  serialized_end=276,
 )
 
 
@@ -135,6 +181,13 @@ _MESSAGECREATEREQUEST = _descriptor.Descriptor(
       message_type=None, enum_type=None, containing_type=None,
       is_extension=False, extension_scope=None,
       options=None, file=DESCRIPTOR),
+    _descriptor.FieldDescriptor(
+      name='run_id', full_name='callstats.ai_decision.MessageCreateRequest.run_id', index=5,
+      number=6, type=5, cpp_type=1, label=1,
+      has_default_value=False, default_value=0,
+      message_type=None, enum_type=None, containing_type=None,
+      is_extension=False, extension_scope=None,
+      options=None, file=DESCRIPTOR),
   ],
   extensions=[
   ],
@@ -147,8 +200,8 @@ _MESSAGECREATEREQUEST = _descriptor.Descriptor(
   extension_ranges=[],
   oneofs=[
   ],
-  serialized_start=229,
-  serialized_end=365,
+  serialized_start=279,
+  serialized_end=431,
 )
 
 
@@ -201,6 +254,13 @@ _MESSAGELISTREQUEST = _descriptor.Descriptor(
       message_type=None, enum_type=None, containing_type=None,
       is_extension=False, extension_scope=None,
       options=None, file=DESCRIPTOR),
+    _descriptor.FieldDescriptor(
+      name='read_mask', full_name='callstats.ai_decision.MessageListRequest.read_mask', index=6,
+      number=7, type=11, cpp_type=10, label=1,
+      has_default_value=False, default_value=None,
+      message_type=None, enum_type=None, containing_type=None,
+      is_extension=False, extension_scope=None,
+      options=None, file=DESCRIPTOR),
   ],
   extensions=[
   ],
@@ -213,8 +273,8 @@ _MESSAGELISTREQUEST = _descriptor.Descriptor(
   extension_ranges=[],
   oneofs=[
   ],
-  serialized_start=368,
-  serialized_end=574,
+  serialized_start=434,
+  serialized_end=687,
 )
 
 
@@ -253,6 +313,41 @@ _STATE = _descriptor.Descriptor(
       message_type=None, enum_type=None, containing_type=None,
       is_extension=False, extension_scope=None,
       options=None, file=DESCRIPTOR),
+    _descriptor.FieldDescriptor(
+      name='revision', full_name='callstats.ai_decision.State.revision', index=4,
+      number=5, type=5, cpp_type=1, label=1,
+      has_default_value=False, default_value=0,
+      message_type=None, enum_type=None, containing_type=None,
+      is_extension=False, extension_scope=None,
+      options=None, file=DESCRIPTOR),
+    _descriptor.FieldDescriptor(
+      name='chunked', full_name='callstats.ai_decision.State.chunked', index=5,
+      number=6, type=8, cpp_type=7, label=1,
+      has_default_value=False, default_value=False,
+      message_type=None, enum_type=None, containing_type=None,
+      is_extension=False, extension_scope=None,
+      options=None, file=DESCRIPTOR),
+    _descriptor.FieldDescriptor(
+      name='content_type', full_name='callstats.ai_decision.State.content_type', index=6,
+      number=7, type=9, cpp_type=9, label=1,
+      has_default_value=False, default_value=_b("").decode('utf-8'),
+      message_type=None, enum_type=None, containing_type=None,
+      is_extension=False, extension_scope=None,
+      options=None, file=DESCRIPTOR),
+    _descriptor.FieldDescriptor(
+      name='schema_version', full_name='callstats.ai_decision.State.schema_version', index=7,
+      number=8, type=5, cpp_type=1, label=1,
+      has_default_value=False, default_value=0,
+      message_type=None, enum_type=None, containing_type=None,
+      is_extension=False, extension_scope=None,
+      options=None, file=DESCRIPTOR),
+    _descriptor.FieldDescriptor(
+      name='run_id', full_name='callstats.ai_decision.State.run_id', index=8,
+      number=9, type=5, cpp_type=1, label=1,
+      has_default_value=False, default_value=0,
+      message_type=None, enum_type=None, containing_type=None,
+      is_extension=False, extension_scope=None,
+      options=None, file=DESCRIPTOR),
   ],
   extensions=[
   ],
@@ -265,8 +360,8 @@ _STATE = _descriptor.Descriptor(
   extension_ranges=[],
   oneofs=[
   ],
-  serialized_start=576,
-  serialized_end=683,
+  serialized_start=690,
+  serialized_end=894,
 )
 
 
@@ -305,6 +400,131 @@ _STATESAVEREQUEST = _descriptor.Descriptor(
       message_type=None, enum_type=None, containing_type=None,
       is_extension=False, extension_scope=None,
       options=None, file=DESCRIPTOR),
+    _descriptor.FieldDescriptor(
+      name='expected_revision', full_name='callstats.ai_decision.StateSaveRequest.expected_revision', index=4,
+      number=5, type=5, cpp_type=1, label=1,
+      has_default_value=False, default_value=0,
+      message_type=None, enum_type=None, containing_type=None,
+      is_extension=False, extension_scope=None,
+      options=None, file=DESCRIPTOR),
+    _descriptor.FieldDescriptor(
+      name='force', full_name='callstats.ai_decision.StateSaveRequest.force', index=5,
+      number=6, type=8, cpp_type=7, label=1,
+      has_default_value=False, default_value=False,
+      message_type=None, enum_type=None, containing_type=None,
+      is_extension=False, extension_scope=None,
+      options=None, file=DESCRIPTOR),
+    _descriptor.FieldDescriptor(
+      name='schema_version', full_name='callstats.ai_decision.StateSaveRequest.schema_version', index=6,
+      number=7, type=5, cpp_type=1, label=1,
+      has_default_value=False, default_value=0,
+      message_type=None, enum_type=None, containing_type=None,
+      is_extension=False, extension_scope=None,
+      options=None, file=DESCRIPTOR),
+    _descriptor.FieldDescriptor(
+      name='fencing_token', full_name='callstats.ai_decision.StateSaveRequest.fencing_token', index=7,
+      number=8, type=3, cpp_type=2, label=1,
+      has_default_value=False, default_value=0,
+      message_type=None, enum_type=None, containing_type=None,
+      is_extension=False, extension_scope=None,
+      options=None, file=DESCRIPTOR),
+    _descriptor.FieldDescriptor(
+      name='run_id', full_name='callstats.ai_decision.StateSaveRequest.run_id', index=8,
+      number=9, type=5, cpp_type=1, label=1,
+      has_default_value=False, default_value=0,
+      message_type=None, enum_type=None, containing_type=None,
+      is_extension=False, extension_scope=None,
+      options=None, file=DESCRIPTOR),
+  ],
+  extensions=[
+  ],
+  nested_types=[],
+  enum_types=[
+  ],
+  options=None,
+  is_extendable=False,
+  syntax='proto3',
+  extension_ranges=[],
+  oneofs=[
+  ],
+  serialized_start=897,
+  serialized_end=1120,
+)
+
+
+_STATESAVECHUNK = _descriptor.Descriptor(
+  name='StateSaveChunk',
+  full_name='callstats.ai_decision.StateSaveChunk',
+  filename=None,
+  file=DESCRIPTOR,
+  containing_type=None,
+  fields=[
+    _descriptor.FieldDescriptor(
+      name='state', full_name='callstats.ai_decision.StateSaveChunk.state', index=0,
+      number=1, type=11, cpp_type=10, label=1,
+      has_default_value=False, default_value=None,
+      message_type=None, enum_type=None, containing_type=None,
+      is_extension=False, extension_scope=None,
+      options=None, file=DESCRIPTOR),
+    _descriptor.FieldDescriptor(
+      name='data', full_name='callstats.ai_decision.StateSaveChunk.data', index=1,
+      number=2, type=12, cpp_type=9, label=1,
+      has_default_value=False, default_value=_b(""),
+      message_type=None, enum_type=None, containing_type=None,
+      is_extension=False, extension_scope=None,
+      options=None, file=DESCRIPTOR),
+    _descriptor.FieldDescriptor(
+      name='checksum', full_name='callstats.ai_decision.StateSaveChunk.checksum', index=2,
+      number=3, type=9, cpp_type=9, label=1,
+      has_default_value=False, default_value=_b("").decode('utf-8'),
+      message_type=None, enum_type=None, containing_type=None,
+      is_extension=False, extension_scope=None,
+      options=None, file=DESCRIPTOR),
+  ],
+  extensions=[
+  ],
+  nested_types=[],
+  enum_types=[
+  ],
+  options=None,
+  is_extendable=False,
+  syntax='proto3',
+  extension_ranges=[],
+  oneofs=[
+  ],
+  serialized_start=1122,
+  serialized_end=1226,
+)
+
+
+_STATECHUNK = _descriptor.Descriptor(
+  name='StateChunk',
+  full_name='callstats.ai_decision.StateChunk',
+  filename=None,
+  file=DESCRIPTOR,
+  containing_type=None,
+  fields=[
+    _descriptor.FieldDescriptor(
+      name='state', full_name='callstats.ai_decision.StateChunk.state', index=0,
+      number=1, type=11, cpp_type=10, label=1,
+      has_default_value=False, default_value=None,
+      message_type=None, enum_type=None, containing_type=None,
+      is_extension=False, extension_scope=None,
+      options=None, file=DESCRIPTOR),
+    _descriptor.FieldDescriptor(
+      name='data', full_name='callstats.ai_decision.StateChunk.data', index=1,
+      number=2, type=12, cpp_type=9, label=1,
+      has_default_value=False, default_value=_b(""),
+      message_type=None, enum_type=None, containing_type=None,
+      is_extension=False, extension_scope=None,
+      options=None, file=DESCRIPTOR),
+    _descriptor.FieldDescriptor(
+      name='checksum', full_name='callstats.ai_decision.StateChunk.checksum', index=2,
+      number=3, type=9, cpp_type=9, label=1,
+      has_default_value=False, default_value=_b("").decode('utf-8'),
+      message_type=None, enum_type=None, containing_type=None,
+      is_extension=False, extension_scope=None,
+      options=None, file=DESCRIPTOR),
   ],
   extensions=[
   ],
@@ -317,8 +537,8 @@ _STATESAVEREQUEST = _descriptor.Descriptor(
   extension_ranges=[],
   oneofs=[
   ],
-  serialized_start=685,
-  serialized_end=803,
+  serialized_start=1228,
+  serialized_end=1317,
 )
 
 
@@ -362,8 +582,91 @@ _STATEGETREQUEST = _descriptor.Descriptor(
   extension_ranges=[],
   oneofs=[
   ],
-  serialized_start=805,
-  serialized_end=908,
+  serialized_start=1319,
+  serialized_end=1422,
+)
+
+
+_STATEGETLATESTREQUEST = _descriptor.Descriptor(
+  name='StateGetLatestRequest',
+  full_name='callstats.ai_decision.StateGetLatestRequest',
+  filename=None,
+  file=DESCRIPTOR,
+  containing_type=None,
+  fields=[
+    _descriptor.FieldDescriptor(
+      name='app_id', full_name='callstats.ai_decision.StateGetLatestRequest.app_id', index=0,
+      number=1, type=5, cpp_type=1, label=1,
+      has_default_value=False, default_value=0,
+      message_type=None, enum_type=None, containing_type=None,
+      is_extension=False, extension_scope=None,
+      options=None, file=DESCRIPTOR),
+    _descriptor.FieldDescriptor(
+      name='keyword', full_name='callstats.ai_decision.StateGetLatestRequest.keyword', index=1,
+      number=2, type=9, cpp_type=9, label=1,
+      has_default_value=False, default_value=_b("").decode('utf-8'),
+      message_type=None, enum_type=None, containing_type=None,
+      is_extension=False, extension_scope=None,
+      options=None, file=DESCRIPTOR),
+  ],
+  extensions=[
+  ],
+  nested_types=[],
+  enum_types=[
+  ],
+  options=None,
+  is_extendable=False,
+  syntax='proto3',
+  extension_ranges=[],
+  oneofs=[
+  ],
+  serialized_start=1424,
+  serialized_end=1480,
+)
+
+
+_STATEGETASOFREQUEST = _descriptor.Descriptor(
+  name='StateGetAsOfRequest',
+  full_name='callstats.ai_decision.StateGetAsOfRequest',
+  filename=None,
+  file=DESCRIPTOR,
+  containing_type=None,
+  fields=[
+    _descriptor.FieldDescriptor(
+      name='app_id', full_name='callstats.ai_decision.StateGetAsOfRequest.app_id', index=0,
+      number=1, type=5, cpp_type=1, label=1,
+      has_default_value=False, default_value=0,
+      message_type=None, enum_type=None, containing_type=None,
+      is_extension=False, extension_scope=None,
+      options=None, file=DESCRIPTOR),
+    _descriptor.FieldDescriptor(
+      name='keyword', full_name='callstats.ai_decision.StateGetAsOfRequest.keyword', index=1,
+      number=2, type=9, cpp_type=9, label=1,
+      has_default_value=False, default_value=_b("").decode('utf-8'),
+      message_type=None, enum_type=None, containing_type=None,
+      is_extension=False, extension_scope=None,
+      options=None, file=DESCRIPTOR),
+    _descriptor.FieldDescriptor(
+      name='as_of', full_name='callstats.ai_decision.StateGetAsOfRequest.as_of', index=2,
+      number=3, type=11, cpp_type=10, label=1,
+      has_default_value=False, default_value=None,
+      message_type=None, enum_type=None, containing_type=None,
+      is_extension=False, extension_scope=None,
+      options=None, file=DESCRIPTOR),
+  ],
+  extensions=[
+  ],
+  nested_types=[],
+  enum_types=[
+  ],
+  options=None,
+  is_extendable=False,
+  syntax='proto3',
+  extension_ranges=[],
+  oneofs=[
+  ],
+  serialized_start=1482,
+  serialized_end=1579,
 )
 
 
@@ -402,6 +705,13 @@ _STATELISTREQUEST = _descriptor.Descriptor(
       message_type=None, enum_type=None, containing_type=None,
       is_extension=False, extension_scope=None,
       options=None, file=DESCRIPTOR),
+    _descriptor.FieldDescriptor(
+      name='read_mask', full_name='callstats.ai_decision.StateListRequest.read_mask', index=4,
+      number=5, type=11, cpp_type=10, label=1,
+      has_default_value=False, default_value=None,
+      message_type=None, enum_type=None, containing_type=None,
+      is_extension=False, extension_scope=None,
+      options=None, file=DESCRIPTOR),
   ],
   extensions=[
   ],
@@ -414,51 +724,1226 @@ _STATELISTREQUEST = _descriptor.Descriptor(
   extension_ranges=[],
   oneofs=[
   ],
-  serialized_start=911,
-  serialized_end=1076,
+  serialized_start=1582,
+  serialized_end=1794,
 )
 
-_MESSAGE.fields_by_name['generation_time'].message_type = google_dot_protobuf_dot_timestamp__pb2._TIMESTAMP
-_MESSAGECREATEREQUEST.fields_by_name['generation_time'].message_type = google_dot_protobuf_dot_timestamp__pb2._TIMESTAMP
-_MESSAGELISTREQUEST.fields_by_name['generation_time_from'].message_type = google_dot_protobuf_dot_timestamp__pb2._TIMESTAMP
-_MESSAGELISTREQUEST.fields_by_name['generation_time_to'].message_type = google_dot_protobuf_dot_timestamp__pb2._TIMESTAMP
-_STATE.fields_by_name['generation_time'].message_type = google_dot_protobuf_dot_timestamp__pb2._TIMESTAMP
-_STATESAVEREQUEST.fields_by_name['generation_time'].message_type = google_dot_protobuf_dot_timestamp__pb2._TIMESTAMP
-_STATEGETREQUEST.fields_by_name['generation_time'].message_type = google_dot_protobuf_dot_timestamp__pb2._TIMESTAMP
-_STATELISTREQUEST.fields_by_name['generation_time_from'].message_type = google_dot_protobuf_dot_timestamp__pb2._TIMESTAMP
-_STATELISTREQUEST.fields_by_name['generation_time_to'].message_type = google_dot_protobuf_dot_timestamp__pb2._TIMESTAMP
-DESCRIPTOR.message_types_by_name['Message'] = _MESSAGE
-DESCRIPTOR.message_types_by_name['MessageCreateRequest'] = _MESSAGECREATEREQUEST
-DESCRIPTOR.message_types_by_name['MessageListRequest'] = _MESSAGELISTREQUEST
-DESCRIPTOR.message_types_by_name['State'] = _STATE
-DESCRIPTOR.message_types_by_name['StateSaveRequest'] = _STATESAVEREQUEST
-DESCRIPTOR.message_types_by_name['StateGetRequest'] = _STATEGETREQUEST
-DESCRIPTOR.message_types_by_name['StateListRequest'] = _STATELISTREQUEST
-_sym_db.RegisterFileDescriptor(DESCRIPTOR)
-
-Message = _reflection.GeneratedProtocolMessageType('Message', (_message.Message,), dict(
-  DESCRIPTOR = _MESSAGE,
-  __module__ = 'ai_decision_service_pb2'
-  # @@protoc_insertion_point(class_scope:callstats.ai_decision.Message)
-  ))
-_sym_db.RegisterMessage(Message)
-
-MessageCreateRequest = _reflection.GeneratedProtocolMessageType('MessageCreateRequest', (_message.Message,), dict(
-  DESCRIPTOR = _MESSAGECREATEREQUEST,
-  __module__ = 'ai_decision_service_pb2'
-  # @@protoc_insertion_point(class_scope:callstats.ai_decision.MessageCreateRequest)
-  ))
-_sym_db.RegisterMessage(MessageCreateRequest)
-
-MessageListRequest = _reflection.GeneratedProtocolMessageType('MessageListRequest', (_message.Message,), dict(
-  DESCRIPTOR = _MESSAGELISTREQUEST,
-  __module__ = 'ai_decision_service_pb2'
-  # @@protoc_insertion_point(class_scope:callstats.ai_decision.MessageListRequest)
-  ))
-_sym_db.RegisterMessage(MessageListRequest)
 
-State = _reflection.GeneratedProtocolMessageType('State', (_message.Message,), dict(
-  DESCRIPTOR = _STATE,
+_STATEDELETEREQUEST = _descriptor.Descriptor(
+  name='StateDeleteRequest',
+  full_name='callstats.ai_decision.StateDeleteRequest',
+  filename=None,
+  file=DESCRIPTOR,
+  containing_type=None,
+  fields=[
+    _descriptor.FieldDescriptor(
+      name='app_id', full_name='callstats.ai_decision.StateDeleteRequest.app_id', index=0,
+      number=1, type=5, cpp_type=1, label=1,
+      has_default_value=False, default_value=0,
+      message_type=None, enum_type=None, containing_type=None,
+      is_extension=False, extension_scope=None,
+      options=None, file=DESCRIPTOR),
+    _descriptor.FieldDescriptor(
+      name='keyword', full_name='callstats.ai_decision.StateDeleteRequest.keyword', index=1,
+      number=2, type=9, cpp_type=9, label=1,
+      has_default_value=False, default_value=_b("").decode('utf-8'),
+      message_type=None, enum_type=None, containing_type=None,
+      is_extension=False, extension_scope=None,
+      options=None, file=DESCRIPTOR),
+    _descriptor.FieldDescriptor(
+      name='generation_time', full_name='callstats.ai_decision.StateDeleteRequest.generation_time', index=2,
+      number=3, type=11, cpp_type=10, label=1,
+      has_default_value=False, default_value=None,
+      message_type=None, enum_type=None, containing_type=None,
+      is_extension=False, extension_scope=None,
+      options=None, file=DESCRIPTOR),
+    _descriptor.FieldDescriptor(
+      name='dry_run', full_name='callstats.ai_decision.StateDeleteRequest.dry_run', index=3,
+      number=4, type=8, cpp_type=7, label=1,
+      has_default_value=False, default_value=False,
+      message_type=None, enum_type=None, containing_type=None,
+      is_extension=False, extension_scope=None,
+      options=None, file=DESCRIPTOR),
+  ],
+  extensions=[
+  ],
+  nested_types=[],
+  enum_types=[
+  ],
+  options=None,
+  is_extendable=False,
+  syntax='proto3',
+  extension_ranges=[],
+  oneofs=[
+  ],
+  serialized_start=1796,
+  serialized_end=1919,
+)
+
+
+_STATEDELETERANGEREQUEST = _descriptor.Descriptor(
+  name='StateDeleteRangeRequest',
+  full_name='callstats.ai_decision.StateDeleteRangeRequest',
+  filename=None,
+  file=DESCRIPTOR,
+  containing_type=None,
+  fields=[
+    _descriptor.FieldDescriptor(
+      name='app_id', full_name='callstats.ai_decision.StateDeleteRangeRequest.app_id', index=0,
+      number=1, type=5, cpp_type=1, label=1,
+      has_default_value=False, default_value=0,
+      message_type=None, enum_type=None, containing_type=None,
+      is_extension=False, extension_scope=None,
+      options=None, file=DESCRIPTOR),
+    _descriptor.FieldDescriptor(
+      name='keyword', full_name='callstats.ai_decision.StateDeleteRangeRequest.keyword', index=1,
+      number=2, type=9, cpp_type=9, label=1,
+      has_default_value=False, default_value=_b("").decode('utf-8'),
+      message_type=None, enum_type=None, containing_type=None,
+      is_extension=False, extension_scope=None,
+      options=None, file=DESCRIPTOR),
+    _descriptor.FieldDescriptor(
+      name='generation_time_from', full_name='callstats.ai_decision.StateDeleteRangeRequest.generation_time_from', index=2,
+      number=3, type=11, cpp_type=10, label=1,
+      has_default_value=False, default_value=None,
+      message_type=None, enum_type=None, containing_type=None,
+      is_extension=False, extension_scope=None,
+      options=None, file=DESCRIPTOR),
+    _descriptor.FieldDescriptor(
+      name='generation_time_to', full_name='callstats.ai_decision.StateDeleteRangeRequest.generation_time_to', index=3,
+      number=4, type=11, cpp_type=10, label=1,
+      has_default_value=False, default_value=None,
+      message_type=None, enum_type=None, containing_type=None,
+      is_extension=False, extension_scope=None,
+      options=None, file=DESCRIPTOR),
+    _descriptor.FieldDescriptor(
+      name='dry_run', full_name='callstats.ai_decision.StateDeleteRangeRequest.dry_run', index=4,
+      number=5, type=8, cpp_type=7, label=1,
+      has_default_value=False, default_value=False,
+      message_type=None, enum_type=None, containing_type=None,
+      is_extension=False, extension_scope=None,
+      options=None, file=DESCRIPTOR),
+  ],
+  extensions=[
+  ],
+  nested_types=[],
+  enum_types=[
+  ],
+  options=None,
+  is_extendable=False,
+  syntax='proto3',
+  extension_ranges=[],
+  oneofs=[
+  ],
+  serialized_start=1922,
+  serialized_end=2111,
+)
+
+
+_STATEDELETERESPONSE = _descriptor.Descriptor(
+  name='StateDeleteResponse',
+  full_name='callstats.ai_decision.StateDeleteResponse',
+  filename=None,
+  file=DESCRIPTOR,
+  containing_type=None,
+  fields=[
+    _descriptor.FieldDescriptor(
+      name='deleted', full_name='callstats.ai_decision.StateDeleteResponse.deleted', index=0,
+      number=1, type=5, cpp_type=1, label=1,
+      has_default_value=False, default_value=0,
+      message_type=None, enum_type=None, containing_type=None,
+      is_extension=False, extension_scope=None,
+      options=None, file=DESCRIPTOR),
+    _descriptor.FieldDescriptor(
+      name='dry_run', full_name='callstats.ai_decision.StateDeleteResponse.dry_run', index=1,
+      number=2, type=8, cpp_type=7, label=1,
+      has_default_value=False, default_value=False,
+      message_type=None, enum_type=None, containing_type=None,
+      is_extension=False, extension_scope=None,
+      options=None, file=DESCRIPTOR),
+  ],
+  extensions=[
+  ],
+  nested_types=[],
+  enum_types=[
+  ],
+  options=None,
+  is_extendable=False,
+  syntax='proto3',
+  extension_ranges=[],
+  oneofs=[
+  ],
+  serialized_start=2113,
+  serialized_end=2168,
+)
+
+
+_KEYWORD = _descriptor.Descriptor(
+  name='Keyword',
+  full_name='callstats.ai_decision.Keyword',
+  filename=None,
+  file=DESCRIPTOR,
+  containing_type=None,
+  fields=[
+    _descriptor.FieldDescriptor(
+      name='keyword', full_name='callstats.ai_decision.Keyword.keyword', index=0,
+      number=1, type=9, cpp_type=9, label=1,
+      has_default_value=False, default_value=_b("").decode('utf-8'),
+      message_type=None, enum_type=None, containing_type=None,
+      is_extension=False, extension_scope=None,
+      options=None, file=DESCRIPTOR),
+    _descriptor.FieldDescriptor(
+      name='content_type', full_name='callstats.ai_decision.Keyword.content_type', index=1,
+      number=2, type=9, cpp_type=9, label=1,
+      has_default_value=False, default_value=_b("").decode('utf-8'),
+      message_type=None, enum_type=None, containing_type=None,
+      is_extension=False, extension_scope=None,
+      options=None, file=DESCRIPTOR),
+    _descriptor.FieldDescriptor(
+      name='schema_version', full_name='callstats.ai_decision.Keyword.schema_version', index=2,
+      number=3, type=5, cpp_type=1, label=1,
+      has_default_value=False, default_value=0,
+      message_type=None, enum_type=None, containing_type=None,
+      is_extension=False, extension_scope=None,
+      options=None, file=DESCRIPTOR),
+    _descriptor.FieldDescriptor(
+      name='max_size', full_name='callstats.ai_decision.Keyword.max_size', index=3,
+      number=4, type=5, cpp_type=1, label=1,
+      has_default_value=False, default_value=0,
+      message_type=None, enum_type=None, containing_type=None,
+      is_extension=False, extension_scope=None,
+      options=None, file=DESCRIPTOR),
+    _descriptor.FieldDescriptor(
+      name='owner', full_name='callstats.ai_decision.Keyword.owner', index=4,
+      number=5, type=9, cpp_type=9, label=1,
+      has_default_value=False, default_value=_b("").decode('utf-8'),
+      message_type=None, enum_type=None, containing_type=None,
+      is_extension=False, extension_scope=None,
+      options=None, file=DESCRIPTOR),
+    _descriptor.FieldDescriptor(
+      name='registered', full_name='callstats.ai_decision.Keyword.registered', index=5,
+      number=6, type=8, cpp_type=7, label=1,
+      has_default_value=False, default_value=False,
+      message_type=None, enum_type=None, containing_type=None,
+      is_extension=False, extension_scope=None,
+      options=None, file=DESCRIPTOR),
+    _descriptor.FieldDescriptor(
+      name='state_count', full_name='callstats.ai_decision.Keyword.state_count', index=6,
+      number=7, type=5, cpp_type=1, label=1,
+      has_default_value=False, default_value=0,
+      message_type=None, enum_type=None, containing_type=None,
+      is_extension=False, extension_scope=None,
+      options=None, file=DESCRIPTOR),
+    _descriptor.FieldDescriptor(
+      name='latest_generation_time', full_name='callstats.ai_decision.Keyword.latest_generation_time', index=7,
+      number=8, type=11, cpp_type=10, label=1,
+      has_default_value=False, default_value=None,
+      message_type=None, enum_type=None, containing_type=None,
+      is_extension=False, extension_scope=None,
+      options=None, file=DESCRIPTOR),
+  ],
+  extensions=[
+  ],
+  nested_types=[],
+  enum_types=[
+  ],
+  options=None,
+  is_extendable=False,
+  syntax='proto3',
+  extension_ranges=[],
+  oneofs=[
+  ],
+  serialized_start=2171,
+  serialized_end=2377,
+)
+
+
+_KEYWORDLISTREQUEST = _descriptor.Descriptor(
+  name='KeywordListRequest',
+  full_name='callstats.ai_decision.KeywordListRequest',
+  filename=None,
+  file=DESCRIPTOR,
+  containing_type=None,
+  fields=[
+    _descriptor.FieldDescriptor(
+      name='app_id', full_name='callstats.ai_decision.KeywordListRequest.app_id', index=0,
+      number=1, type=5, cpp_type=1, label=1,
+      has_default_value=False, default_value=0,
+      message_type=None, enum_type=None, containing_type=None,
+      is_extension=False, extension_scope=None,
+      options=None, file=DESCRIPTOR),
+  ],
+  extensions=[
+  ],
+  nested_types=[],
+  enum_types=[
+  ],
+  options=None,
+  is_extendable=False,
+  syntax='proto3',
+  extension_ranges=[],
+  oneofs=[
+  ],
+  serialized_start=2379,
+  serialized_end=2415,
+)
+
+
+_COMPACTIONPOLICY = _descriptor.Descriptor(
+  name='CompactionPolicy',
+  full_name='callstats.ai_decision.CompactionPolicy',
+  filename=None,
+  file=DESCRIPTOR,
+  containing_type=None,
+  fields=[
+    _descriptor.FieldDescriptor(
+      name='keyword', full_name='callstats.ai_decision.CompactionPolicy.keyword', index=0,
+      number=1, type=9, cpp_type=9, label=1,
+      has_default_value=False, default_value=_b("").decode('utf-8'),
+      message_type=None, enum_type=None, containing_type=None,
+      is_extension=False, extension_scope=None,
+      options=None, file=DESCRIPTOR),
+    _descriptor.FieldDescriptor(
+      name='keep_last', full_name='callstats.ai_decision.CompactionPolicy.keep_last', index=1,
+      number=2, type=5, cpp_type=1, label=1,
+      has_default_value=False, default_value=0,
+      message_type=None, enum_type=None, containing_type=None,
+      is_extension=False, extension_scope=None,
+      options=None, file=DESCRIPTOR),
+    _descriptor.FieldDescriptor(
+      name='keep_daily_after_days', full_name='callstats.ai_decision.CompactionPolicy.keep_daily_after_days', index=2,
+      number=3, type=5, cpp_type=1, label=1,
+      has_default_value=False, default_value=0,
+      message_type=None, enum_type=None, containing_type=None,
+      is_extension=False, extension_scope=None,
+      options=None, file=DESCRIPTOR),
+    _descriptor.FieldDescriptor(
+      name='delete_after_days', full_name='callstats.ai_decision.CompactionPolicy.delete_after_days', index=3,
+      number=4, type=5, cpp_type=1, label=1,
+      has_default_value=False, default_value=0,
+      message_type=None, enum_type=None, containing_type=None,
+      is_extension=False, extension_scope=None,
+      options=None, file=DESCRIPTOR),
+  ],
+  extensions=[
+  ],
+  nested_types=[],
+  enum_types=[
+  ],
+  options=None,
+  is_extendable=False,
+  syntax='proto3',
+  extension_ranges=[],
+  oneofs=[
+  ],
+  serialized_start=2417,
+  serialized_end=2529,
+)
+
+
+_COMPACTIONPOLICYLISTREQUEST = _descriptor.Descriptor(
+  name='CompactionPolicyListRequest',
+  full_name='callstats.ai_decision.CompactionPolicyListRequest',
+  filename=None,
+  file=DESCRIPTOR,
+  containing_type=None,
+  fields=[
+  ],
+  extensions=[
+  ],
+  nested_types=[],
+  enum_types=[
+  ],
+  options=None,
+  is_extendable=False,
+  syntax='proto3',
+  extension_ranges=[],
+  oneofs=[
+  ],
+  serialized_start=2531,
+  serialized_end=2560,
+)
+
+
+_COMPACTREQUEST = _descriptor.Descriptor(
+  name='CompactRequest',
+  full_name='callstats.ai_decision.CompactRequest',
+  filename=None,
+  file=DESCRIPTOR,
+  containing_type=None,
+  fields=[
+    _descriptor.FieldDescriptor(
+      name='keyword', full_name='callstats.ai_decision.CompactRequest.keyword', index=0,
+      number=1, type=9, cpp_type=9, label=1,
+      has_default_value=False, default_value=_b("").decode('utf-8'),
+      message_type=None, enum_type=None, containing_type=None,
+      is_extension=False, extension_scope=None,
+      options=None, file=DESCRIPTOR),
+    _descriptor.FieldDescriptor(
+      name='dry_run', full_name='callstats.ai_decision.CompactRequest.dry_run', index=1,
+      number=2, type=8, cpp_type=7, label=1,
+      has_default_value=False, default_value=False,
+      message_type=None, enum_type=None, containing_type=None,
+      is_extension=False, extension_scope=None,
+      options=None, file=DESCRIPTOR),
+  ],
+  extensions=[
+  ],
+  nested_types=[],
+  enum_types=[
+  ],
+  options=None,
+  is_extendable=False,
+  syntax='proto3',
+  extension_ranges=[],
+  oneofs=[
+  ],
+  serialized_start=2562,
+  serialized_end=2612,
+)
+
+
+_COMPACTION = _descriptor.Descriptor(
+  name='Compaction',
+  full_name='callstats.ai_decision.Compaction',
+  filename=None,
+  file=DESCRIPTOR,
+  containing_type=None,
+  fields=[
+    _descriptor.FieldDescriptor(
+      name='app_id', full_name='callstats.ai_decision.Compaction.app_id', index=0,
+      number=1, type=5, cpp_type=1, label=1,
+      has_default_value=False, default_value=0,
+      message_type=None, enum_type=None, containing_type=None,
+      is_extension=False, extension_scope=None,
+      options=None, file=DESCRIPTOR),
+    _descriptor.FieldDescriptor(
+      name='keyword', full_name='callstats.ai_decision.Compaction.keyword', index=1,
+      number=2, type=9, cpp_type=9, label=1,
+      has_default_value=False, default_value=_b("").decode('utf-8'),
+      message_type=None, enum_type=None, containing_type=None,
+      is_extension=False, extension_scope=None,
+      options=None, file=DESCRIPTOR),
+    _descriptor.FieldDescriptor(
+      name='deleted', full_name='callstats.ai_decision.Compaction.deleted', index=2,
+      number=3, type=5, cpp_type=1, label=1,
+      has_default_value=False, default_value=0,
+      message_type=None, enum_type=None, containing_type=None,
+      is_extension=False, extension_scope=None,
+      options=None, file=DESCRIPTOR),
+  ],
+  extensions=[
+  ],
+  nested_types=[],
+  enum_types=[
+  ],
+  options=None,
+  is_extendable=False,
+  syntax='proto3',
+  extension_ranges=[],
+  oneofs=[
+  ],
+  serialized_start=2614,
+  serialized_end=2676,
+)
+
+
+_LEASE = _descriptor.Descriptor(
+  name='Lease',
+  full_name='callstats.ai_decision.Lease',
+  filename=None,
+  file=DESCRIPTOR,
+  containing_type=None,
+  fields=[
+    _descriptor.FieldDescriptor(
+      name='app_id', full_name='callstats.ai_decision.Lease.app_id', index=0,
+      number=1, type=5, cpp_type=1, label=1,
+      has_default_value=False, default_value=0,
+      message_type=None, enum_type=None, containing_type=None,
+      is_extension=False, extension_scope=None,
+      options=None, file=DESCRIPTOR),
+    _descriptor.FieldDescriptor(
+      name='name', full_name='callstats.ai_decision.Lease.name', index=1,
+      number=2, type=9, cpp_type=9, label=1,
+      has_default_value=False, default_value=_b("").decode('utf-8'),
+      message_type=None, enum_type=None, containing_type=None,
+      is_extension=False, extension_scope=None,
+      options=None, file=DESCRIPTOR),
+    _descriptor.FieldDescriptor(
+      name='holder', full_name='callstats.ai_decision.Lease.holder', index=2,
+      number=3, type=9, cpp_type=9, label=1,
+      has_default_value=False, default_value=_b("").decode('utf-8'),
+      message_type=None, enum_type=None, containing_type=None,
+      is_extension=False, extension_scope=None,
+      options=None, file=DESCRIPTOR),
+    _descriptor.FieldDescriptor(
+      name='fencing_token', full_name='callstats.ai_decision.Lease.fencing_token', index=3,
+      number=4, type=3, cpp_type=2, label=1,
+      has_default_value=False, default_value=0,
+      message_type=None, enum_type=None, containing_type=None,
+      is_extension=False, extension_scope=None,
+      options=None, file=DESCRIPTOR),
+    _descriptor.FieldDescriptor(
+      name='expires_at', full_name='callstats.ai_decision.Lease.expires_at', index=4,
+      number=5, type=11, cpp_type=10, label=1,
+      has_default_value=False, default_value=None,
+      message_type=None, enum_type=None, containing_type=None,
+      is_extension=False, extension_scope=None,
+      options=None, file=DESCRIPTOR),
+  ],
+  extensions=[
+  ],
+  nested_types=[],
+  enum_types=[
+  ],
+  options=None,
+  is_extendable=False,
+  syntax='proto3',
+  extension_ranges=[],
+  oneofs=[
+  ],
+  serialized_start=2678,
+  serialized_end=2802,
+)
+
+
+_LEASEACQUIREREQUEST = _descriptor.Descriptor(
+  name='LeaseAcquireRequest',
+  full_name='callstats.ai_decision.LeaseAcquireRequest',
+  filename=None,
+  file=DESCRIPTOR,
+  containing_type=None,
+  fields=[
+    _descriptor.FieldDescriptor(
+      name='app_id', full_name='callstats.ai_decision.LeaseAcquireRequest.app_id', index=0,
+      number=1, type=5, cpp_type=1, label=1,
+      has_default_value=False, default_value=0,
+      message_type=None, enum_type=None, containing_type=None,
+      is_extension=False, extension_scope=None,
+      options=None, file=DESCRIPTOR),
+    _descriptor.FieldDescriptor(
+      name='name', full_name='callstats.ai_decision.LeaseAcquireRequest.name', index=1,
+      number=2, type=9, cpp_type=9, label=1,
+      has_default_value=False, default_value=_b("").decode('utf-8'),
+      message_type=None, enum_type=None, containing_type=None,
+      is_extension=False, extension_scope=None,
+      options=None, file=DESCRIPTOR),
+    _descriptor.FieldDescriptor(
+      name='holder', full_name='callstats.ai_decision.LeaseAcquireRequest.holder', index=2,
+      number=3, type=9, cpp_type=9, label=1,
+      has_default_value=False, default_value=_b("").decode('utf-8'),
+      message_type=None, enum_type=None, containing_type=None,
+      is_extension=False, extension_scope=None,
+      options=None, file=DESCRIPTOR),
+    _descriptor.FieldDescriptor(
+      name='ttl_seconds', full_name='callstats.ai_decision.LeaseAcquireRequest.ttl_seconds', index=3,
+      number=4, type=5, cpp_type=1, label=1,
+      has_default_value=False, default_value=0,
+      message_type=None, enum_type=None, containing_type=None,
+      is_extension=False, extension_scope=None,
+      options=None, file=DESCRIPTOR),
+  ],
+  extensions=[
+  ],
+  nested_types=[],
+  enum_types=[
+  ],
+  options=None,
+  is_extendable=False,
+  syntax='proto3',
+  extension_ranges=[],
+  oneofs=[
+  ],
+  serialized_start=2804,
+  serialized_end=2892,
+)
+
+
+_LEASERENEWREQUEST = _descriptor.Descriptor(
+  name='LeaseRenewRequest',
+  full_name='callstats.ai_decision.LeaseRenewRequest',
+  filename=None,
+  file=DESCRIPTOR,
+  containing_type=None,
+  fields=[
+    _descriptor.FieldDescriptor(
+      name='app_id', full_name='callstats.ai_decision.LeaseRenewRequest.app_id', index=0,
+      number=1, type=5, cpp_type=1, label=1,
+      has_default_value=False, default_value=0,
+      message_type=None, enum_type=None, containing_type=None,
+      is_extension=False, extension_scope=None,
+      options=None, file=DESCRIPTOR),
+    _descriptor.FieldDescriptor(
+      name='name', full_name='callstats.ai_decision.LeaseRenewRequest.name', index=1,
+      number=2, type=9, cpp_type=9, label=1,
+      has_default_value=False, default_value=_b("").decode('utf-8'),
+      message_type=None, enum_type=None, containing_type=None,
+      is_extension=False, extension_scope=None,
+      options=None, file=DESCRIPTOR),
+    _descriptor.FieldDescriptor(
+      name='holder', full_name='callstats.ai_decision.LeaseRenewRequest.holder', index=2,
+      number=3, type=9, cpp_type=9, label=1,
+      has_default_value=False, default_value=_b("").decode('utf-8'),
+      message_type=None, enum_type=None, containing_type=None,
+      is_extension=False, extension_scope=None,
+      options=None, file=DESCRIPTOR),
+    _descriptor.FieldDescriptor(
+      name='fencing_token', full_name='callstats.ai_decision.LeaseRenewRequest.fencing_token', index=3,
+      number=4, type=3, cpp_type=2, label=1,
+      has_default_value=False, default_value=0,
+      message_type=None, enum_type=None, containing_type=None,
+      is_extension=False, extension_scope=None,
+      options=None, file=DESCRIPTOR),
+    _descriptor.FieldDescriptor(
+      name='ttl_seconds', full_name='callstats.ai_decision.LeaseRenewRequest.ttl_seconds', index=4,
+      number=5, type=5, cpp_type=1, label=1,
+      has_default_value=False, default_value=0,
+      message_type=None, enum_type=None, containing_type=None,
+      is_extension=False, extension_scope=None,
+      options=None, file=DESCRIPTOR),
+  ],
+  extensions=[
+  ],
+  nested_types=[],
+  enum_types=[
+  ],
+  options=None,
+  is_extendable=False,
+  syntax='proto3',
+  extension_ranges=[],
+  oneofs=[
+  ],
+  serialized_start=2894,
+  serialized_end=3003,
+)
+
+
+_LEASERELEASEREQUEST = _descriptor.Descriptor(
+  name='LeaseReleaseRequest',
+  full_name='callstats.ai_decision.LeaseReleaseRequest',
+  filename=None,
+  file=DESCRIPTOR,
+  containing_type=None,
+  fields=[
+    _descriptor.FieldDescriptor(
+      name='app_id', full_name='callstats.ai_decision.LeaseReleaseRequest.app_id', index=0,
+      number=1, type=5, cpp_type=1, label=1,
+      has_default_value=False, default_value=0,
+      message_type=None, enum_type=None, containing_type=None,
+      is_extension=False, extension_scope=None,
+      options=None, file=DESCRIPTOR),
+    _descriptor.FieldDescriptor(
+      name='name', full_name='callstats.ai_decision.LeaseReleaseRequest.name', index=1,
+      number=2, type=9, cpp_type=9, label=1,
+      has_default_value=False, default_value=_b("").decode('utf-8'),
+      message_type=None, enum_type=None, containing_type=None,
+      is_extension=False, extension_scope=None,
+      options=None, file=DESCRIPTOR),
+    _descriptor.FieldDescriptor(
+      name='holder', full_name='callstats.ai_decision.LeaseReleaseRequest.holder', index=2,
+      number=3, type=9, cpp_type=9, label=1,
+      has_default_value=False, default_value=_b("").decode('utf-8'),
+      message_type=None, enum_type=None, containing_type=None,
+      is_extension=False, extension_scope=None,
+      options=None, file=DESCRIPTOR),
+    _descriptor.FieldDescriptor(
+      name='fencing_token', full_name='callstats.ai_decision.LeaseReleaseRequest.fencing_token', index=3,
+      number=4, type=3, cpp_type=2, label=1,
+      has_default_value=False, default_value=0,
+      message_type=None, enum_type=None, containing_type=None,
+      is_extension=False, extension_scope=None,
+      options=None, file=DESCRIPTOR),
+  ],
+  extensions=[
+  ],
+  nested_types=[],
+  enum_types=[
+  ],
+  options=None,
+  is_extendable=False,
+  syntax='proto3',
+  extension_ranges=[],
+  oneofs=[
+  ],
+  serialized_start=3005,
+  serialized_end=3095,
+)
+
+
+_LEASERELEASERESPONSE = _descriptor.Descriptor(
+  name='LeaseReleaseResponse',
+  full_name='callstats.ai_decision.LeaseReleaseResponse',
+  filename=None,
+  file=DESCRIPTOR,
+  containing_type=None,
+  fields=[
+  ],
+  extensions=[
+  ],
+  nested_types=[],
+  enum_types=[
+  ],
+  options=None,
+  is_extendable=False,
+  syntax='proto3',
+  extension_ranges=[],
+  oneofs=[
+  ],
+  serialized_start=3097,
+  serialized_end=3119,
+)
+
+
+_RUN = _descriptor.Descriptor(
+  name='Run',
+  full_name='callstats.ai_decision.Run',
+  filename=None,
+  file=DESCRIPTOR,
+  containing_type=None,
+  fields=[
+    _descriptor.FieldDescriptor(
+      name='id', full_name='callstats.ai_decision.Run.id', index=0,
+      number=1, type=5, cpp_type=1, label=1,
+      has_default_value=False, default_value=0,
+      message_type=None, enum_type=None, containing_type=None,
+      is_extension=False, extension_scope=None,
+      options=None, file=DESCRIPTOR),
+    _descriptor.FieldDescriptor(
+      name='app_id', full_name='callstats.ai_decision.Run.app_id', index=1,
+      number=2, type=5, cpp_type=1, label=1,
+      has_default_value=False, default_value=0,
+      message_type=None, enum_type=None, containing_type=None,
+      is_extension=False, extension_scope=None,
+      options=None, file=DESCRIPTOR),
+    _descriptor.FieldDescriptor(
+      name='pipeline', full_name='callstats.ai_decision.Run.pipeline', index=2,
+      number=3, type=9, cpp_type=9, label=1,
+      has_default_value=False, default_value=_b("").decode('utf-8'),
+      message_type=None, enum_type=None, containing_type=None,
+      is_extension=False, extension_scope=None,
+      options=None, file=DESCRIPTOR),
+    _descriptor.FieldDescriptor(
+      name='status', full_name='callstats.ai_decision.Run.status', index=3,
+      number=4, type=14, cpp_type=8, label=1,
+      has_default_value=False, default_value=0,
+      message_type=None, enum_type=None, containing_type=None,
+      is_extension=False, extension_scope=None,
+      options=None, file=DESCRIPTOR),
+    _descriptor.FieldDescriptor(
+      name='error', full_name='callstats.ai_decision.Run.error', index=4,
+      number=5, type=9, cpp_type=9, label=1,
+      has_default_value=False, default_value=_b("").decode('utf-8'),
+      message_type=None, enum_type=None, containing_type=None,
+      is_extension=False, extension_scope=None,
+      options=None, file=DESCRIPTOR),
+    _descriptor.FieldDescriptor(
+      name='started_at', full_name='callstats.ai_decision.Run.started_at', index=5,
+      number=6, type=11, cpp_type=10, label=1,
+      has_default_value=False, default_value=None,
+      message_type=None, enum_type=None, containing_type=None,
+      is_extension=False, extension_scope=None,
+      options=None, file=DESCRIPTOR),
+    _descriptor.FieldDescriptor(
+      name='finished_at', full_name='callstats.ai_decision.Run.finished_at', index=6,
+      number=7, type=11, cpp_type=10, label=1,
+      has_default_value=False, default_value=None,
+      message_type=None, enum_type=None, containing_type=None,
+      is_extension=False, extension_scope=None,
+      options=None, file=DESCRIPTOR),
+    _descriptor.FieldDescriptor(
+      name='counters', full_name='callstats.ai_decision.Run.counters', index=7,
+      number=8, type=11, cpp_type=10, label=3,
+      has_default_value=False, default_value=[],
+      message_type=None, enum_type=None, containing_type=None,
+      is_extension=False, extension_scope=None,
+      options=None, file=DESCRIPTOR),
+    _descriptor.FieldDescriptor(
+      name='message_count', full_name='callstats.ai_decision.Run.message_count', index=8,
+      number=9, type=5, cpp_type=1, label=1,
+      has_default_value=False, default_value=0,
+      message_type=None, enum_type=None, containing_type=None,
+      is_extension=False, extension_scope=None,
+      options=None, file=DESCRIPTOR),
+    _descriptor.FieldDescriptor(
+      name='state_count', full_name='callstats.ai_decision.Run.state_count', index=9,
+      number=10, type=5, cpp_type=1, label=1,
+      has_default_value=False, default_value=0,
+      message_type=None, enum_type=None, containing_type=None,
+      is_extension=False, extension_scope=None,
+      options=None, file=DESCRIPTOR),
+  ],
+  extensions=[
+  ],
+  nested_types=[],
+  enum_types=[
+  ],
+  options=None,
+  is_extendable=False,
+  syntax='proto3',
+  extension_ranges=[],
+  oneofs=[
+  ],
+  serialized_start=3122,
+  serialized_end=3488,
+)
+
+
+_RUNSTARTREQUEST = _descriptor.Descriptor(
+  name='RunStartRequest',
+  full_name='callstats.ai_decision.RunStartRequest',
+  filename=None,
+  file=DESCRIPTOR,
+  containing_type=None,
+  fields=[
+    _descriptor.FieldDescriptor(
+      name='app_id', full_name='callstats.ai_decision.RunStartRequest.app_id', index=0,
+      number=1, type=5, cpp_type=1, label=1,
+      has_default_value=False, default_value=0,
+      message_type=None, enum_type=None, containing_type=None,
+      is_extension=False, extension_scope=None,
+      options=None, file=DESCRIPTOR),
+    _descriptor.FieldDescriptor(
+      name='pipeline', full_name='callstats.ai_decision.RunStartRequest.pipeline', index=1,
+      number=2, type=9, cpp_type=9, label=1,
+      has_default_value=False, default_value=_b("").decode('utf-8'),
+      message_type=None, enum_type=None, containing_type=None,
+      is_extension=False, extension_scope=None,
+      options=None, file=DESCRIPTOR),
+  ],
+  extensions=[
+  ],
+  nested_types=[],
+  enum_types=[
+  ],
+  options=None,
+  is_extendable=False,
+  syntax='proto3',
+  extension_ranges=[],
+  oneofs=[
+  ],
+  serialized_start=3490,
+  serialized_end=3541,
+)
+
+
+_RUNFINISHREQUEST = _descriptor.Descriptor(
+  name='RunFinishRequest',
+  full_name='callstats.ai_decision.RunFinishRequest',
+  filename=None,
+  file=DESCRIPTOR,
+  containing_type=None,
+  fields=[
+    _descriptor.FieldDescriptor(
+      name='id', full_name='callstats.ai_decision.RunFinishRequest.id', index=0,
+      number=1, type=5, cpp_type=1, label=1,
+      has_default_value=False, default_value=0,
+      message_type=None, enum_type=None, containing_type=None,
+      is_extension=False, extension_scope=None,
+      options=None, file=DESCRIPTOR),
+    _descriptor.FieldDescriptor(
+      name='status', full_name='callstats.ai_decision.RunFinishRequest.status', index=1,
+      number=2, type=14, cpp_type=8, label=1,
+      has_default_value=False, default_value=0,
+      message_type=None, enum_type=None, containing_type=None,
+      is_extension=False, extension_scope=None,
+      options=None, file=DESCRIPTOR),
+    _descriptor.FieldDescriptor(
+      name='error', full_name='callstats.ai_decision.RunFinishRequest.error', index=2,
+      number=3, type=9, cpp_type=9, label=1,
+      has_default_value=False, default_value=_b("").decode('utf-8'),
+      message_type=None, enum_type=None, containing_type=None,
+      is_extension=False, extension_scope=None,
+      options=None, file=DESCRIPTOR),
+    _descriptor.FieldDescriptor(
+      name='counters', full_name='callstats.ai_decision.RunFinishRequest.counters', index=3,
+      number=4, type=11, cpp_type=10, label=3,
+      has_default_value=False, default_value=[],
+      message_type=None, enum_type=None, containing_type=None,
+      is_extension=False, extension_scope=None,
+      options=None, file=DESCRIPTOR),
+  ],
+  extensions=[
+  ],
+  nested_types=[],
+  enum_types=[
+  ],
+  options=None,
+  is_extendable=False,
+  syntax='proto3',
+  extension_ranges=[],
+  oneofs=[
+  ],
+  serialized_start=3544,
+  serialized_end=3761,
+)
+
+
+_RUNLISTREQUEST = _descriptor.Descriptor(
+  name='RunListRequest',
+  full_name='callstats.ai_decision.RunListRequest',
+  filename=None,
+  file=DESCRIPTOR,
+  containing_type=None,
+  fields=[
+    _descriptor.FieldDescriptor(
+      name='app_id', full_name='callstats.ai_decision.RunListRequest.app_id', index=0,
+      number=1, type=5, cpp_type=1, label=1,
+      has_default_value=False, default_value=0,
+      message_type=None, enum_type=None, containing_type=None,
+      is_extension=False, extension_scope=None,
+      options=None, file=DESCRIPTOR),
+    _descriptor.FieldDescriptor(
+      name='pipeline', full_name='callstats.ai_decision.RunListRequest.pipeline', index=1,
+      number=2, type=9, cpp_type=9, label=1,
+      has_default_value=False, default_value=_b("").decode('utf-8'),
+      message_type=None, enum_type=None, containing_type=None,
+      is_extension=False, extension_scope=None,
+      options=None, file=DESCRIPTOR),
+    _descriptor.FieldDescriptor(
+      name='status', full_name='callstats.ai_decision.RunListRequest.status', index=2,
+      number=3, type=14, cpp_type=8, label=1,
+      has_default_value=False, default_value=0,
+      message_type=None, enum_type=None, containing_type=None,
+      is_extension=False, extension_scope=None,
+      options=None, file=DESCRIPTOR),
+    _descriptor.FieldDescriptor(
+      name='started_from', full_name='callstats.ai_decision.RunListRequest.started_from', index=3,
+      number=4, type=11, cpp_type=10, label=1,
+      has_default_value=False, default_value=None,
+      message_type=None, enum_type=None, containing_type=None,
+      is_extension=False, extension_scope=None,
+      options=None, file=DESCRIPTOR),
+    _descriptor.FieldDescriptor(
+      name='started_to', full_name='callstats.ai_decision.RunListRequest.started_to', index=4,
+      number=5, type=11, cpp_type=10, label=1,
+      has_default_value=False, default_value=None,
+      message_type=None, enum_type=None, containing_type=None,
+      is_extension=False, extension_scope=None,
+      options=None, file=DESCRIPTOR),
+    _descriptor.FieldDescriptor(
+      name='without_messages', full_name='callstats.ai_decision.RunListRequest.without_messages', index=5,
+      number=6, type=8, cpp_type=7, label=1,
+      has_default_value=False, default_value=False,
+      message_type=None, enum_type=None, containing_type=None,
+      is_extension=False, extension_scope=None,
+      options=None, file=DESCRIPTOR),
+  ],
+  extensions=[
+  ],
+  nested_types=[],
+  enum_types=[
+  ],
+  options=None,
+  is_extendable=False,
+  syntax='proto3',
+  extension_ranges=[],
+  oneofs=[
+  ],
+  serialized_start=3764,
+  serialized_end=3988,
+)
+
+
+_RUNLASTSUCCESSFULREQUEST = _descriptor.Descriptor(
+  name='RunLastSuccessfulRequest',
+  full_name='callstats.ai_decision.RunLastSuccessfulRequest',
+  filename=None,
+  file=DESCRIPTOR,
+  containing_type=None,
+  fields=[
+    _descriptor.FieldDescriptor(
+      name='app_id', full_name='callstats.ai_decision.RunLastSuccessfulRequest.app_id', index=0,
+      number=1, type=5, cpp_type=1, label=1,
+      has_default_value=False, default_value=0,
+      message_type=None, enum_type=None, containing_type=None,
+      is_extension=False, extension_scope=None,
+      options=None, file=DESCRIPTOR),
+    _descriptor.FieldDescriptor(
+      name='pipeline', full_name='callstats.ai_decision.RunLastSuccessfulRequest.pipeline', index=1,
+      number=2, type=9, cpp_type=9, label=1,
+      has_default_value=False, default_value=_b("").decode('utf-8'),
+      message_type=None, enum_type=None, containing_type=None,
+      is_extension=False, extension_scope=None,
+      options=None, file=DESCRIPTOR),
+  ],
+  extensions=[
+  ],
+  nested_types=[],
+  enum_types=[
+  ],
+  options=None,
+  is_extendable=False,
+  syntax='proto3',
+  extension_ranges=[],
+  oneofs=[
+  ],
+  serialized_start=3990,
+  serialized_end=4050,
+)
+
+
+_AUDITEVENT = _descriptor.Descriptor(
+  name='AuditEvent',
+  full_name='callstats.ai_decision.AuditEvent',
+  filename=None,
+  file=DESCRIPTOR,
+  containing_type=None,
+  fields=[
+    _descriptor.FieldDescriptor(
+      name='id', full_name='callstats.ai_decision.AuditEvent.id', index=0,
+      number=1, type=3, cpp_type=2, label=1,
+      has_default_value=False, default_value=0,
+      message_type=None, enum_type=None, containing_type=None,
+      is_extension=False, extension_scope=None,
+      options=None, file=DESCRIPTOR),
+    _descriptor.FieldDescriptor(
+      name='occurred_at', full_name='callstats.ai_decision.AuditEvent.occurred_at', index=1,
+      number=2, type=11, cpp_type=10, label=1,
+      has_default_value=False, default_value=None,
+      message_type=None, enum_type=None, containing_type=None,
+      is_extension=False, extension_scope=None,
+      options=None, file=DESCRIPTOR),
+    _descriptor.FieldDescriptor(
+      name='actor', full_name='callstats.ai_decision.AuditEvent.actor', index=2,
+      number=3, type=9, cpp_type=9, label=1,
+      has_default_value=False, default_value=_b("").decode('utf-8'),
+      message_type=None, enum_type=None, containing_type=None,
+      is_extension=False, extension_scope=None,
+      options=None, file=DESCRIPTOR),
+    _descriptor.FieldDescriptor(
+      name='rpc', full_name='callstats.ai_decision.AuditEvent.rpc', index=3,
+      number=4, type=9, cpp_type=9, label=1,
+      has_default_value=False, default_value=_b("").decode('utf-8'),
+      message_type=None, enum_type=None, containing_type=None,
+      is_extension=False, extension_scope=None,
+      options=None, file=DESCRIPTOR),
+    _descriptor.FieldDescriptor(
+      name='app_id', full_name='callstats.ai_decision.AuditEvent.app_id', index=4,
+      number=5, type=5, cpp_type=1, label=1,
+      has_default_value=False, default_value=0,
+      message_type=None, enum_type=None, containing_type=None,
+      is_extension=False, extension_scope=None,
+      options=None, file=DESCRIPTOR),
+    _descriptor.FieldDescriptor(
+      name='action', full_name='callstats.ai_decision.AuditEvent.action', index=5,
+      number=6, type=9, cpp_type=9, label=1,
+      has_default_value=False, default_value=_b("").decode('utf-8'),
+      message_type=None, enum_type=None, containing_type=None,
+      is_extension=False, extension_scope=None,
+      options=None, file=DESCRIPTOR),
+    _descriptor.FieldDescriptor(
+      name='target', full_name='callstats.ai_decision.AuditEvent.target', index=6,
+      number=7, type=9, cpp_type=9, label=1,
+      has_default_value=False, default_value=_b("").decode('utf-8'),
+      message_type=None, enum_type=None, containing_type=None,
+      is_extension=False, extension_scope=None,
+      options=None, file=DESCRIPTOR),
+    _descriptor.FieldDescriptor(
+      name='count', full_name='callstats.ai_decision.AuditEvent.count', index=7,
+      number=8, type=5, cpp_type=1, label=1,
+      has_default_value=False, default_value=0,
+      message_type=None, enum_type=None, containing_type=None,
+      is_extension=False, extension_scope=None,
+      options=None, file=DESCRIPTOR),
+    _descriptor.FieldDescriptor(
+      name='before_hash', full_name='callstats.ai_decision.AuditEvent.before_hash', index=8,
+      number=9, type=9, cpp_type=9, label=1,
+      has_default_value=False, default_value=_b("").decode('utf-8'),
+      message_type=None, enum_type=None, containing_type=None,
+      is_extension=False, extension_scope=None,
+      options=None, file=DESCRIPTOR),
+    _descriptor.FieldDescriptor(
+      name='after_hash', full_name='callstats.ai_decision.AuditEvent.after_hash', index=9,
+      number=10, type=9, cpp_type=9, label=1,
+      has_default_value=False, default_value=_b("").decode('utf-8'),
+      message_type=None, enum_type=None, containing_type=None,
+      is_extension=False, extension_scope=None,
+      options=None, file=DESCRIPTOR),
+    _descriptor.FieldDescriptor(
+      name='reason', full_name='callstats.ai_decision.AuditEvent.reason', index=10,
+      number=11, type=9, cpp_type=9, label=1,
+      has_default_value=False, default_value=_b("").decode('utf-8'),
+      message_type=None, enum_type=None, containing_type=None,
+      is_extension=False, extension_scope=None,
+      options=None, file=DESCRIPTOR),
+  ],
+  extensions=[
+  ],
+  nested_types=[],
+  enum_types=[
+  ],
+  options=None,
+  is_extendable=False,
+  syntax='proto3',
+  extension_ranges=[],
+  oneofs=[
+  ],
+  serialized_start=4053,
+  serialized_end=4274,
+)
+
+
+_AUDITEVENTLISTREQUEST = _descriptor.Descriptor(
+  name='AuditEventListRequest',
+  full_name='callstats.ai_decision.AuditEventListRequest',
+  filename=None,
+  file=DESCRIPTOR,
+  containing_type=None,
+  fields=[
+    _descriptor.FieldDescriptor(
+      name='app_id', full_name='callstats.ai_decision.AuditEventListRequest.app_id', index=0,
+      number=1, type=5, cpp_type=1, label=1,
+      has_default_value=False, default_value=0,
+      message_type=None, enum_type=None, containing_type=None,
+      is_extension=False, extension_scope=None,
+      options=None, file=DESCRIPTOR),
+    _descriptor.FieldDescriptor(
+      name='actor', full_name='callstats.ai_decision.AuditEventListRequest.actor', index=1,
+      number=2, type=9, cpp_type=9, label=1,
+      has_default_value=False, default_value=_b("").decode('utf-8'),
+      message_type=None, enum_type=None, containing_type=None,
+      is_extension=False, extension_scope=None,
+      options=None, file=DESCRIPTOR),
+    _descriptor.FieldDescriptor(
+      name='action', full_name='callstats.ai_decision.AuditEventListRequest.action', index=2,
+      number=3, type=9, cpp_type=9, label=1,
+      has_default_value=False, default_value=_b("").decode('utf-8'),
+      message_type=None, enum_type=None, containing_type=None,
+      is_extension=False, extension_scope=None,
+      options=None, file=DESCRIPTOR),
+    _descriptor.FieldDescriptor(
+      name='target', full_name='callstats.ai_decision.AuditEventListRequest.target', index=3,
+      number=4, type=9, cpp_type=9, label=1,
+      has_default_value=False, default_value=_b("").decode('utf-8'),
+      message_type=None, enum_type=None, containing_type=None,
+      is_extension=False, extension_scope=None,
+      options=None, file=DESCRIPTOR),
+    _descriptor.FieldDescriptor(
+      name='occurred_from', full_name='callstats.ai_decision.AuditEventListRequest.occurred_from', index=4,
+      number=5, type=11, cpp_type=10, label=1,
+      has_default_value=False, default_value=None,
+      message_type=None, enum_type=None, containing_type=None,
+      is_extension=False, extension_scope=None,
+      options=None, file=DESCRIPTOR),
+    _descriptor.FieldDescriptor(
+      name='occurred_to', full_name='callstats.ai_decision.AuditEventListRequest.occurred_to', index=5,
+      number=6, type=11, cpp_type=10, label=1,
+      has_default_value=False, default_value=None,
+      message_type=None, enum_type=None, containing_type=None,
+      is_extension=False, extension_scope=None,
+      options=None, file=DESCRIPTOR),
+    _descriptor.FieldDescriptor(
+      name='limit', full_name='callstats.ai_decision.AuditEventListRequest.limit', index=6,
+      number=7, type=5, cpp_type=1, label=1,
+      has_default_value=False, default_value=0,
+      message_type=None, enum_type=None, containing_type=None,
+      is_extension=False, extension_scope=None,
+      options=None, file=DESCRIPTOR),
+  ],
+  extensions=[
+  ],
+  nested_types=[],
+  enum_types=[
+  ],
+  options=None,
+  is_extendable=False,
+  syntax='proto3',
+  extension_ranges=[],
+  oneofs=[
+  ],
+  serialized_start=4277,
+  serialized_end=4478,
+)
+
+_MESSAGE.fields_by_name['generation_time'].message_type = google_dot_protobuf_dot_timestamp__pb2._TIMESTAMP
+_MESSAGECREATEREQUEST.fields_by_name['generation_time'].message_type = google_dot_protobuf_dot_timestamp__pb2._TIMESTAMP
+_MESSAGELISTREQUEST.fields_by_name['generation_time_from'].message_type = google_dot_protobuf_dot_timestamp__pb2._TIMESTAMP
+_MESSAGELISTREQUEST.fields_by_name['generation_time_to'].message_type = google_dot_protobuf_dot_timestamp__pb2._TIMESTAMP
+_MESSAGELISTREQUEST.fields_by_name['read_mask'].message_type = google_dot_protobuf_dot_field__mask__pb2._FIELDMASK
+_STATE.fields_by_name['generation_time'].message_type = google_dot_protobuf_dot_timestamp__pb2._TIMESTAMP
+_STATESAVEREQUEST.fields_by_name['generation_time'].message_type = google_dot_protobuf_dot_timestamp__pb2._TIMESTAMP
+_STATESAVECHUNK.fields_by_name['state'].message_type = _STATESAVEREQUEST
+_STATECHUNK.fields_by_name['state'].message_type = _STATE
+_STATEGETREQUEST.fields_by_name['generation_time'].message_type = google_dot_protobuf_dot_timestamp__pb2._TIMESTAMP
+_STATEGETASOFREQUEST.fields_by_name['as_of'].message_type = google_dot_protobuf_dot_timestamp__pb2._TIMESTAMP
+_STATELISTREQUEST.fields_by_name['generation_time_from'].message_type = google_dot_protobuf_dot_timestamp__pb2._TIMESTAMP
+_STATELISTREQUEST.fields_by_name['generation_time_to'].message_type = google_dot_protobuf_dot_timestamp__pb2._TIMESTAMP
+_STATELISTREQUEST.fields_by_name['read_mask'].message_type = google_dot_protobuf_dot_field__mask__pb2._FIELDMASK
+_STATEDELETEREQUEST.fields_by_name['generation_time'].message_type = google_dot_protobuf_dot_timestamp__pb2._TIMESTAMP
+_STATEDELETERANGEREQUEST.fields_by_name['generation_time_from'].message_type = google_dot_protobuf_dot_timestamp__pb2._TIMESTAMP
+_STATEDELETERANGEREQUEST.fields_by_name['generation_time_to'].message_type = google_dot_protobuf_dot_timestamp__pb2._TIMESTAMP
+_KEYWORD.fields_by_name['latest_generation_time'].message_type = google_dot_protobuf_dot_timestamp__pb2._TIMESTAMP
+_LEASE.fields_by_name['expires_at'].message_type = google_dot_protobuf_dot_timestamp__pb2._TIMESTAMP
+_RUN.fields_by_name['status'].enum_type = _RUNSTATUS
+_RUN.fields_by_name['started_at'].message_type = google_dot_protobuf_dot_timestamp__pb2._TIMESTAMP
+_RUN.fields_by_name['finished_at'].message_type = google_dot_protobuf_dot_timestamp__pb2._TIMESTAMP
+_RUN.fields_by_name['counters'].message_type = _RUN_COUNTERSENTRY
+_RUNFINISHREQUEST.fields_by_name['status'].enum_type = _RUNSTATUS
+_RUNFINISHREQUEST.fields_by_name['counters'].message_type = _RUNFINISHREQUEST_COUNTERSENTRY
+_RUNLISTREQUEST.fields_by_name['status'].enum_type = _RUNSTATUS
+_RUNLISTREQUEST.fields_by_name['started_from'].message_type = google_dot_protobuf_dot_timestamp__pb2._TIMESTAMP
+_RUNLISTREQUEST.fields_by_name['started_to'].message_type = google_dot_protobuf_dot_timestamp__pb2._TIMESTAMP
+_AUDITEVENT.fields_by_name['occurred_at'].message_type = google_dot_protobuf_dot_timestamp__pb2._TIMESTAMP
+_AUDITEVENTLISTREQUEST.fields_by_name['occurred_from'].message_type = google_dot_protobuf_dot_timestamp__pb2._TIMESTAMP
+_AUDITEVENTLISTREQUEST.fields_by_name['occurred_to'].message_type = google_dot_protobuf_dot_timestamp__pb2._TIMESTAMP
+DESCRIPTOR.message_types_by_name['Message'] = _MESSAGE
+DESCRIPTOR.message_types_by_name['MessageCreateRequest'] = _MESSAGECREATEREQUEST
+DESCRIPTOR.message_types_by_name['MessageListRequest'] = _MESSAGELISTREQUEST
+DESCRIPTOR.message_types_by_name['State'] = _STATE
+DESCRIPTOR.message_types_by_name['StateSaveRequest'] = _STATESAVEREQUEST
+DESCRIPTOR.message_types_by_name['StateSaveChunk'] = _STATESAVECHUNK
+DESCRIPTOR.message_types_by_name['StateChunk'] = _STATECHUNK
+DESCRIPTOR.message_types_by_name['StateGetRequest'] = _STATEGETREQUEST
+DESCRIPTOR.message_types_by_name['StateGetLatestRequest'] = _STATEGETLATESTREQUEST
+DESCRIPTOR.message_types_by_name['StateGetAsOfRequest'] = _STATEGETASOFREQUEST
+DESCRIPTOR.message_types_by_name['StateListRequest'] = _STATELISTREQUEST
+DESCRIPTOR.message_types_by_name['StateDeleteRequest'] = _STATEDELETEREQUEST
+DESCRIPTOR.message_types_by_name['StateDeleteRangeRequest'] = _STATEDELETERANGEREQUEST
+DESCRIPTOR.message_types_by_name['StateDeleteResponse'] = _STATEDELETERESPONSE
+DESCRIPTOR.message_types_by_name['Keyword'] = _KEYWORD
+DESCRIPTOR.message_types_by_name['KeywordListRequest'] = _KEYWORDLISTREQUEST
+DESCRIPTOR.message_types_by_name['CompactionPolicy'] = _COMPACTIONPOLICY
+DESCRIPTOR.message_types_by_name['CompactionPolicyListRequest'] = _COMPACTIONPOLICYLISTREQUEST
+DESCRIPTOR.message_types_by_name['CompactRequest'] = _COMPACTREQUEST
+DESCRIPTOR.message_types_by_name['Compaction'] = _COMPACTION
+DESCRIPTOR.message_types_by_name['Lease'] = _LEASE
+DESCRIPTOR.message_types_by_name['LeaseAcquireRequest'] = _LEASEACQUIREREQUEST
+DESCRIPTOR.message_types_by_name['LeaseRenewRequest'] = _LEASERENEWREQUEST
+DESCRIPTOR.message_types_by_name['LeaseReleaseRequest'] = _LEASERELEASEREQUEST
+DESCRIPTOR.message_types_by_name['LeaseReleaseResponse'] = _LEASERELEASERESPONSE
+DESCRIPTOR.message_types_by_name['Run'] = _RUN
+DESCRIPTOR.message_types_by_name['RunStartRequest'] = _RUNSTARTREQUEST
+DESCRIPTOR.message_types_by_name['RunFinishRequest'] = _RUNFINISHREQUEST
+DESCRIPTOR.message_types_by_name['RunListRequest'] = _RUNLISTREQUEST
+DESCRIPTOR.message_types_by_name['RunLastSuccessfulRequest'] = _RUNLASTSUCCESSFULREQUEST
+DESCRIPTOR.message_types_by_name['AuditEvent'] = _AUDITEVENT
+DESCRIPTOR.message_types_by_name['AuditEventListRequest'] = _AUDITEVENTLISTREQUEST
+DESCRIPTOR.enum_types_by_name['RunStatus'] = _RUNSTATUS
+_sym_db.RegisterFileDescriptor(DESCRIPTOR)
+
+Message = _reflection.GeneratedProtocolMessageType('Message', (_message.Message,), dict(
+  DESCRIPTOR = _MESSAGE,
+  __module__ = 'ai_decision_service_pb2'
+  # @@protoc_insertion_point(class_scope:callstats.ai_decision.Message)
+  ))
+_sym_db.RegisterMessage(Message)
+
+MessageCreateRequest = _reflection.GeneratedProtocolMessageType('MessageCreateRequest', (_message.Message,), dict(
+  DESCRIPTOR = _MESSAGECREATEREQUEST,
+  __module__ = 'ai_decision_service_pb2'
+  # @@protoc_insertion_point(class_scope:callstats.ai_decision.MessageCreateRequest)
+  ))
+_sym_db.RegisterMessage(MessageCreateRequest)
+
+MessageListRequest = _reflection.GeneratedProtocolMessageType('MessageListRequest', (_message.Message,), dict(
+  DESCRIPTOR = _MESSAGELISTREQUEST,
+  __module__ = 'ai_decision_service_pb2'
+  # @@protoc_insertion_point(class_scope:callstats.ai_decision.MessageListRequest)
+  ))
+_sym_db.RegisterMessage(MessageListRequest)
+
+State = _reflection.GeneratedProtocolMessageType('State', (_message.Message,), dict(
+  DESCRIPTOR = _STATE,
   __module__ = 'ai_decision_service_pb2'
   # @@protoc_insertion_point(class_scope:callstats.ai_decision.State)
   ))
@@ -471,6 +1956,20 @@ StateSaveRequest = _reflection.GeneratedProtocolMessageType('StateSaveRequest',
   ))
 _sym_db.RegisterMessage(StateSaveRequest)
 
+StateSaveChunk = _reflection.GeneratedProtocolMessageType('StateSaveChunk', (_message.Message,), dict(
+  DESCRIPTOR = _STATESAVECHUNK,
+  __module__ = 'ai_decision_service_pb2'
+  # @@protoc_insertion_point(class_scope:callstats.ai_decision.StateSaveChunk)
+  ))
+_sym_db.RegisterMessage(StateSaveChunk)
+
+StateChunk = _reflection.GeneratedProtocolMessageType('StateChunk', (_message.Message,), dict(
+  DESCRIPTOR = _STATECHUNK,
+  __module__ = 'ai_decision_service_pb2'
+  # @@protoc_insertion_point(class_scope:callstats.ai_decision.StateChunk)
+  ))
+_sym_db.RegisterMessage(StateChunk)
+
 StateGetRequest = _reflection.GeneratedProtocolMessageType('StateGetRequest', (_message.Message,), dict(
   DESCRIPTOR = _STATEGETREQUEST,
   __module__ = 'ai_decision_service_pb2'
@@ -478,6 +1977,20 @@ StateGetRequest = _reflection.GeneratedProtocolMessageType('StateGetRequest', (_
   ))
 _sym_db.RegisterMessage(StateGetRequest)
 
+StateGetLatestRequest = _reflection.GeneratedProtocolMessageType('StateGetLatestRequest', (_message.Message,), dict(
+  DESCRIPTOR = _STATEGETLATESTREQUEST,
+  __module__ = 'ai_decision_service_pb2'
+  # @@protoc_insertion_point(class_scope:callstats.ai_decision.StateGetLatestRequest)
+  ))
+_sym_db.RegisterMessage(StateGetLatestRequest)
+
+StateGetAsOfRequest = _reflection.GeneratedProtocolMessageType('StateGetAsOfRequest', (_message.Message,), dict(
+  DESCRIPTOR = _STATEGETASOFREQUEST,
+  __module__ = 'ai_decision_service_pb2'
+  # @@protoc_insertion_point(class_scope:callstats.ai_decision.StateGetAsOfRequest)
+  ))
+_sym_db.RegisterMessage(StateGetAsOfRequest)
+
 StateListRequest = _reflection.GeneratedProtocolMessageType('StateListRequest', (_message.Message,), dict(
   DESCRIPTOR = _STATELISTREQUEST,
   __module__ = 'ai_decision_service_pb2'
@@ -485,6 +1998,153 @@ StateListRequest = _reflection.GeneratedProtocolMessageType('StateListRequest',
   ))
 _sym_db.RegisterMessage(StateListRequest)
 
+StateDeleteRequest = _reflection.GeneratedProtocolMessageType('StateDeleteRequest', (_message.Message,), dict(
+  DESCRIPTOR = _STATEDELETEREQUEST,
+  __module__ = 'ai_decision_service_pb2'
+  # @@protoc_insertion_point(class_scope:callstats.ai_decision.StateDeleteRequest)
+  ))
+_sym_db.RegisterMessage(StateDeleteRequest)
+
+StateDeleteRangeRequest = _reflection.GeneratedProtocolMessageType('StateDeleteRangeRequest', (_message.Message,), dict(
+  DESCRIPTOR = _STATEDELETERANGEREQUEST,
+  __module__ = 'ai_decision_service_pb2'
+  # @@protoc_insertion_point(class_scope:callstats.ai_decision.StateDeleteRangeRequest)
+  ))
+_sym_db.RegisterMessage(StateDeleteRangeRequest)
+
+StateDeleteResponse = _reflection.GeneratedProtocolMessageType('StateDeleteResponse', (_message.Message,), dict(
+  DESCRIPTOR = _STATEDELETERESPONSE,
+  __module__ = 'ai_decision_service_pb2'
+  # @@protoc_insertion_point(class_scope:callstats.ai_decision.StateDeleteResponse)
+  ))
+_sym_db.RegisterMessage(StateDeleteResponse)
+
+Keyword = _reflection.GeneratedProtocolMessageType('Keyword', (_message.Message,), dict(
+  DESCRIPTOR = _KEYWORD,
+  __module__ = 'ai_decision_service_pb2'
+  # @@protoc_insertion_point(class_scope:callstats.ai_decision.Keyword)
+  ))
+_sym_db.RegisterMessage(Keyword)
+
+KeywordListRequest = _reflection.GeneratedProtocolMessageType('KeywordListRequest', (_message.Message,), dict(
+  DESCRIPTOR = _KEYWORDLISTREQUEST,
+  __module__ = 'ai_decision_service_pb2'
+  # @@protoc_insertion_point(class_scope:callstats.ai_decision.KeywordListRequest)
+  ))
+_sym_db.RegisterMessage(KeywordListRequest)
+
+CompactionPolicy = _reflection.GeneratedProtocolMessageType('CompactionPolicy', (_message.Message,), dict(
+  DESCRIPTOR = _COMPACTIONPOLICY,
+  __module__ = 'ai_decision_service_pb2'
+  # @@protoc_insertion_point(class_scope:callstats.ai_decision.CompactionPolicy)
+  ))
+_sym_db.RegisterMessage(CompactionPolicy)
+
+CompactionPolicyListRequest = _reflection.GeneratedProtocolMessageType('CompactionPolicyListRequest', (_message.Message,), dict(
+  DESCRIPTOR = _COMPACTIONPOLICYLISTREQUEST,
+  __module__ = 'ai_decision_service_pb2'
+  # @@protoc_insertion_point(class_scope:callstats.ai_decision.CompactionPolicyListRequest)
+  ))
+_sym_db.RegisterMessage(CompactionPolicyListRequest)
+
+CompactRequest = _reflection.GeneratedProtocolMessageType('CompactRequest', (_message.Message,), dict(
+  DESCRIPTOR = _COMPACTREQUEST,
+  __module__ = 'ai_decision_service_pb2'
+  # @@protoc_insertion_point(class_scope:callstats.ai_decision.CompactRequest)
+  ))
+_sym_db.RegisterMessage(CompactRequest)
+
+Compaction = _reflection.GeneratedProtocolMessageType('Compaction', (_message.Message,), dict(
+  DESCRIPTOR = _COMPACTION,
+  __module__ = 'ai_decision_service_pb2'
+  # @@protoc_insertion_point(class_scope:callstats.ai_decision.Compaction)
+  ))
+_sym_db.RegisterMessage(Compaction)
+
+Lease = _reflection.GeneratedProtocolMessageType('Lease', (_message.Message,), dict(
+  DESCRIPTOR = _LEASE,
+  __module__ = 'ai_decision_service_pb2'
+  # @@protoc_insertion_point(class_scope:callstats.ai_decision.Lease)
+  ))
+_sym_db.RegisterMessage(Lease)
+
+LeaseAcquireRequest = _reflection.GeneratedProtocolMessageType('LeaseAcquireRequest', (_message.Message,), dict(
+  DESCRIPTOR = _LEASEACQUIREREQUEST,
+  __module__ = 'ai_decision_service_pb2'
+  # @@protoc_insertion_point(class_scope:callstats.ai_decision.LeaseAcquireRequest)
+  ))
+_sym_db.RegisterMessage(LeaseAcquireRequest)
+
+LeaseRenewRequest = _reflection.GeneratedProtocolMessageType('LeaseRenewRequest', (_message.Message,), dict(
+  DESCRIPTOR = _LEASERENEWREQUEST,
+  __module__ = 'ai_decision_service_pb2'
+  # @@protoc_insertion_point(class_scope:callstats.ai_decision.LeaseRenewRequest)
+  ))
+_sym_db.RegisterMessage(LeaseRenewRequest)
+
+LeaseReleaseRequest = _reflection.GeneratedProtocolMessageType('LeaseReleaseRequest', (_message.Message,), dict(
+  DESCRIPTOR = _LEASERELEASEREQUEST,
+  __module__ = 'ai_decision_service_pb2'
+  # @@protoc_insertion_point(class_scope:callstats.ai_decision.LeaseReleaseRequest)
+  ))
+_sym_db.RegisterMessage(LeaseReleaseRequest)
+
+LeaseReleaseResponse = _reflection.GeneratedProtocolMessageType('LeaseReleaseResponse', (_message.Message,), dict(
+  DESCRIPTOR = _LEASERELEASERESPONSE,
+  __module__ = 'ai_decision_service_pb2'
+  # @@protoc_insertion_point(class_scope:callstats.ai_decision.LeaseReleaseResponse)
+  ))
+_sym_db.RegisterMessage(LeaseReleaseResponse)
+
+Run = _reflection.GeneratedProtocolMessageType('Run', (_message.Message,), dict(
+  DESCRIPTOR = _RUN,
+  __module__ = 'ai_decision_service_pb2'
+  # @@protoc_insertion_point(class_scope:callstats.ai_decision.Run)
+  ))
+_sym_db.RegisterMessage(Run)
+
+RunStartRequest = _reflection.GeneratedProtocolMessageType('RunStartRequest', (_message.Message,), dict(
+  DESCRIPTOR = _RUNSTARTREQUEST,
+  __module__ = 'ai_decision_service_pb2'
+  # @@protoc_insertion_point(class_scope:callstats.ai_decision.RunStartRequest)
+  ))
+_sym_db.RegisterMessage(RunStartRequest)
+
+RunFinishRequest = _reflection.GeneratedProtocolMessageType('RunFinishRequest', (_message.Message,), dict(
+  DESCRIPTOR = _RUNFINISHREQUEST,
+  __module__ = 'ai_decision_service_pb2'
+  # @@protoc_insertion_point(class_scope:callstats.ai_decision.RunFinishRequest)
+  ))
+_sym_db.RegisterMessage(RunFinishRequest)
+
+RunListRequest = _reflection.GeneratedProtocolMessageType('RunListRequest', (_message.Message,), dict(
+  DESCRIPTOR = _RUNLISTREQUEST,
+  __module__ = 'ai_decision_service_pb2'
+  # @@protoc_insertion_point(class_scope:callstats.ai_decision.RunListRequest)
+  ))
+_sym_db.RegisterMessage(RunListRequest)
+
+RunLastSuccessfulRequest = _reflection.GeneratedProtocolMessageType('RunLastSuccessfulRequest', (_message.Message,), dict(
+  DESCRIPTOR = _RUNLASTSUCCESSFULREQUEST,
+  __module__ = 'ai_decision_service_pb2'
+  # @@protoc_insertion_point(class_scope:callstats.ai_decision.RunLastSuccessfulRequest)
+  ))
+_sym_db.RegisterMessage(RunLastSuccessfulRequest)
+
+AuditEvent = _reflection.GeneratedProtocolMessageType('AuditEvent', (_message.Message,), dict(
+  DESCRIPTOR = _AUDITEVENT,
+  __module__ = 'ai_decision_service_pb2'
+  # @@protoc_insertion_point(class_scope:callstats.ai_decision.AuditEvent)
+  ))
+_sym_db.RegisterMessage(AuditEvent)
+
+AuditEventListRequest = _reflection.GeneratedProtocolMessageType('AuditEventListRequest', (_message.Message,), dict(
+  DESCRIPTOR = _AUDITEVENTLISTREQUEST,
+  __module__ = 'ai_decision_service_pb2'
+  # @@protoc_insertion_point(class_scope:callstats.ai_decision.AuditEventListRequest)
+  ))
+_sym_db.RegisterMessage(AuditEventListRequest)
+
 
 DESCRIPTOR.has_options = True
 DESCRIPTOR._options = _descriptor._ParseOptions(descriptor_pb2.FileOptions(), _b('\n io.callstats.ai_decision.serviceZ\006protos'))
@@ -495,8 +2155,8 @@ _AIDECISIONMESSAGESERVICE = _descriptor.ServiceDescriptor(
   file=DESCRIPTOR,
   index=0,
   options=None,
-  serialized_start=1079,
-  serialized_end=1277,
+  serialized_start=4574,
+  serialized_end=4772,
   methods=[
   _descriptor.MethodDescriptor(
     name='Create',
@@ -528,8 +2188,8 @@ _AIDECISIONSTATESERVICE = _descriptor.ServiceDescriptor(
   file=DESCRIPTOR,
   index=1,
   options=None,
-  serialized_start=1280,
-  serialized_end=1541,
+  serialized_start=4775,
+  serialized_end=5997,
   methods=[
   _descriptor.MethodDescriptor(
     name='Save',
@@ -549,18 +2209,225 @@ _AIDECISIONSTATESERVICE = _descriptor.ServiceDescriptor(
     output_type=_STATE,
     options=None,
   ),
+  _descriptor.MethodDescriptor(
+    name='GetLatest',
+    full_name='callstats.ai_decision.AIDecisionStateService.GetLatest',
+    index=2,
+    containing_service=None,
+    input_type=_STATEGETLATESTREQUEST,
+    output_type=_STATE,
+    options=None,
+  ),
+  _descriptor.MethodDescriptor(
+    name='GetAsOf',
+    full_name='callstats.ai_decision.AIDecisionStateService.GetAsOf',
+    index=3,
+    containing_service=None,
+    input_type=_STATEGETASOFREQUEST,
+    output_type=_STATE,
+    options=None,
+  ),
+  _descriptor.MethodDescriptor(
+    name='SaveChunked',
+    full_name='callstats.ai_decision.AIDecisionStateService.SaveChunked',
+    index=4,
+    containing_service=None,
+    input_type=_STATESAVECHUNK,
+    output_type=_STATE,
+    options=None,
+  ),
+  _descriptor.MethodDescriptor(
+    name='GetChunked',
+    full_name='callstats.ai_decision.AIDecisionStateService.GetChunked',
+    index=5,
+    containing_service=None,
+    input_type=_STATEGETREQUEST,
+    output_type=_STATECHUNK,
+    options=None,
+  ),
   _descriptor.MethodDescriptor(
     name='List',
     full_name='callstats.ai_decision.AIDecisionStateService.List',
-    index=2,
+    index=6,
     containing_service=None,
     input_type=_STATELISTREQUEST,
     output_type=_STATE,
     options=None,
   ),
+  _descriptor.MethodDescriptor(
+    name='ListKeywords',
+    full_name='callstats.ai_decision.AIDecisionStateService.ListKeywords',
+    index=7,
+    containing_service=None,
+    input_type=_KEYWORDLISTREQUEST,
+    output_type=_KEYWORD,
+    options=None,
+  ),
+  _descriptor.MethodDescriptor(
+    name='Delete',
+    full_name='callstats.ai_decision.AIDecisionStateService.Delete',
+    index=8,
+    containing_service=None,
+    input_type=_STATEDELETEREQUEST,
+    output_type=_STATEDELETERESPONSE,
+    options=None,
+  ),
+  _descriptor.MethodDescriptor(
+    name='DeleteRange',
+    full_name='callstats.ai_decision.AIDecisionStateService.DeleteRange',
+    index=9,
+    containing_service=None,
+    input_type=_STATEDELETERANGEREQUEST,
+    output_type=_STATEDELETERESPONSE,
+    options=None,
+  ),
+  _descriptor.MethodDescriptor(
+    name='SetCompactionPolicy',
+    full_name='callstats.ai_decision.AIDecisionStateService.SetCompactionPolicy',
+    index=10,
+    containing_service=None,
+    input_type=_COMPACTIONPOLICY,
+    output_type=_COMPACTIONPOLICY,
+    options=None,
+  ),
+  _descriptor.MethodDescriptor(
+    name='ListCompactionPolicies',
+    full_name='callstats.ai_decision.AIDecisionStateService.ListCompactionPolicies',
+    index=11,
+    containing_service=None,
+    input_type=_COMPACTIONPOLICYLISTREQUEST,
+    output_type=_COMPACTIONPOLICY,
+    options=None,
+  ),
+  _descriptor.MethodDescriptor(
+    name='Compact',
+    full_name='callstats.ai_decision.AIDecisionStateService.Compact',
+    index=12,
+    containing_service=None,
+    input_type=_COMPACTREQUEST,
+    output_type=_COMPACTION,
+    options=None,
+  ),
 ])
 _sym_db.RegisterServiceDescriptor(_AIDECISIONSTATESERVICE)
 
 DESCRIPTOR.services_by_name['AIDecisionStateService'] = _AIDECISIONSTATESERVICE
 
+
+_AIDECISIONLEASESERVICE = _descriptor.ServiceDescriptor(
+  name='AIDecisionLeaseService',
+  full_name='callstats.ai_decision.AIDecisionLeaseService',
+  file=DESCRIPTOR,
+  index=2,
+  options=None,
+  serialized_start=6000,
+  serialized_end=6290,
+  methods=[
+  _descriptor.MethodDescriptor(
+    name='Acquire',
+    full_name='callstats.ai_decision.AIDecisionLeaseService.Acquire',
+    index=0,
+    containing_service=None,
+    input_type=_LEASEACQUIREREQUEST,
+    output_type=_LEASE,
+    options=None,
+  ),
+  _descriptor.MethodDescriptor(
+    name='Renew',
+    full_name='callstats.ai_decision.AIDecisionLeaseService.Renew',
+    index=1,
+    containing_service=None,
+    input_type=_LEASERENEWREQUEST,
+    output_type=_LEASE,
+    options=None,
+  ),
+  _descriptor.MethodDescriptor(
+    name='Release',
+    full_name='callstats.ai_decision.AIDecisionLeaseService.Release',
+    index=2,
+    containing_service=None,
+    input_type=_LEASERELEASEREQUEST,
+    output_type=_LEASERELEASERESPONSE,
+    options=None,
+  ),
+])
+_sym_db.RegisterServiceDescriptor(_AIDECISIONLEASESERVICE)
+
+DESCRIPTOR.services_by_name['AIDecisionLeaseService'] = _AIDECISIONLEASESERVICE
+
+
+_AIDECISIONRUNSERVICE = _descriptor.ServiceDescriptor(
+  name='AIDecisionRunService',
+  full_name='callstats.ai_decision.AIDecisionRunService',
+  file=DESCRIPTOR,
+  index=3,
+  options=None,
+  serialized_start=6293,
+  serialized_end=6645,
+  methods=[
+  _descriptor.MethodDescriptor(
+    name='Start',
+    full_name='callstats.ai_decision.AIDecisionRunService.Start',
+    index=0,
+    containing_service=None,
+    input_type=_RUNSTARTREQUEST,
+    output_type=_RUN,
+    options=None,
+  ),
+  _descriptor.MethodDescriptor(
+    name='Finish',
+    full_name='callstats.ai_decision.AIDecisionRunService.Finish',
+    index=1,
+    containing_service=None,
+    input_type=_RUNFINISHREQUEST,
+    output_type=_RUN,
+    options=None,
+  ),
+  _descriptor.MethodDescriptor(
+    name='List',
+    full_name='callstats.ai_decision.AIDecisionRunService.List',
+    index=2,
+    containing_service=None,
+    input_type=_RUNLISTREQUEST,
+    output_type=_RUN,
+    options=None,
+  ),
+  _descriptor.MethodDescriptor(
+    name='LastSuccessful',
+    full_name='callstats.ai_decision.AIDecisionRunService.LastSuccessful',
+    index=3,
+    containing_service=None,
+    input_type=_RUNLASTSUCCESSFULREQUEST,
+    output_type=_RUN,
+    options=None,
+  ),
+])
+_sym_db.RegisterServiceDescriptor(_AIDECISIONRUNSERVICE)
+
+DESCRIPTOR.services_by_name['AIDecisionRunService'] = _AIDECISIONRUNSERVICE
+
+
+_AIDECISIONAUDITSERVICE = _descriptor.ServiceDescriptor(
+  name='AIDecisionAuditService',
+  full_name='callstats.ai_decision.AIDecisionAuditService',
+  file=DESCRIPTOR,
+  index=4,
+  options=None,
+  serialized_start=6647,
+  serialized_end=6762,
+  methods=[
+  _descriptor.MethodDescriptor(
+    name='List',
+    full_name='callstats.ai_decision.AIDecisionAuditService.List',
+    index=0,
+    containing_service=None,
+    input_type=_AUDITEVENTLISTREQUEST,
+    output_type=_AUDITEVENT,
+    options=None,
+  ),
+])
+_sym_db.RegisterServiceDescriptor(_AIDECISIONAUDITSERVICE)
+
+DESCRIPTOR.services_by_name['AIDecisionAuditService'] = _AIDECISIONAUDITSERVICE
+
 # @@protoc_insertion_point(module_scope)
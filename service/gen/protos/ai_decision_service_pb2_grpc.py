@@ -83,11 +83,61 @@ class AIDecisionStateServiceStub(object):
         request_serializer=ai__decision__service__pb2.StateGetRequest.SerializeToString,
         response_deserializer=ai__decision__service__pb2.State.FromString,
         )
+    self.GetLatest = channel.unary_unary(
+        '/callstats.ai_decision.AIDecisionStateService/GetLatest',
+        request_serializer=ai__decision__service__pb2.StateGetLatestRequest.SerializeToString,
+        response_deserializer=ai__decision__service__pb2.State.FromString,
+        )
+    self.GetAsOf = channel.unary_unary(
+        '/callstats.ai_decision.AIDecisionStateService/GetAsOf',
+        request_serializer=ai__decision__service__pb2.StateGetAsOfRequest.SerializeToString,
+        response_deserializer=ai__decision__service__pb2.State.FromString,
+        )
+    self.SaveChunked = channel.stream_unary(
+        '/callstats.ai_decision.AIDecisionStateService/SaveChunked',
+        request_serializer=ai__decision__service__pb2.StateSaveChunk.SerializeToString,
+        response_deserializer=ai__decision__service__pb2.State.FromString,
+        )
+    self.GetChunked = channel.unary_stream(
+        '/callstats.ai_decision.AIDecisionStateService/GetChunked',
+        request_serializer=ai__decision__service__pb2.StateGetRequest.SerializeToString,
+        response_deserializer=ai__decision__service__pb2.StateChunk.FromString,
+        )
     self.List = channel.unary_stream(
         '/callstats.ai_decision.AIDecisionStateService/List',
         request_serializer=ai__decision__service__pb2.StateListRequest.SerializeToString,
         response_deserializer=ai__decision__service__pb2.State.FromString,
         )
+    self.ListKeywords = channel.unary_stream(
+        '/callstats.ai_decision.AIDecisionStateService/ListKeywords',
+        request_serializer=ai__decision__service__pb2.KeywordListRequest.SerializeToString,
+        response_deserializer=ai__decision__service__pb2.Keyword.FromString,
+        )
+    self.Delete = channel.unary_unary(
+        '/callstats.ai_decision.AIDecisionStateService/Delete',
+        request_serializer=ai__decision__service__pb2.StateDeleteRequest.SerializeToString,
+        response_deserializer=ai__decision__service__pb2.StateDeleteResponse.FromString,
+        )
+    self.DeleteRange = channel.unary_unary(
+        '/callstats.ai_decision.AIDecisionStateService/DeleteRange',
+        request_serializer=ai__decision__service__pb2.StateDeleteRangeRequest.SerializeToString,
+        response_deserializer=ai__decision__service__pb2.StateDeleteResponse.FromString,
+        )
+    self.SetCompactionPolicy = channel.unary_unary(
+        '/callstats.ai_decision.AIDecisionStateService/SetCompactionPolicy',
+        request_serializer=ai__decision__service__pb2.CompactionPolicy.SerializeToString,
+        response_deserializer=ai__decision__service__pb2.CompactionPolicy.FromString,
+        )
+    self.ListCompactionPolicies = channel.unary_stream(
+        '/callstats.ai_decision.AIDecisionStateService/ListCompactionPolicies',
+        request_serializer=ai__decision__service__pb2.CompactionPolicyListRequest.SerializeToString,
+        response_deserializer=ai__decision__service__pb2.CompactionPolicy.FromString,
+        )
+    self.Compact = channel.unary_stream(
+        '/callstats.ai_decision.AIDecisionStateService/Compact',
+        request_serializer=ai__decision__service__pb2.CompactRequest.SerializeToString,
+        response_deserializer=ai__decision__service__pb2.Compaction.FromString,
+        )
 
 
 class AIDecisionStateServiceServicer(object):
@@ -108,6 +158,34 @@ class AIDecisionStateServiceServicer(object):
     context.set_details('Method not implemented!')
     raise NotImplementedError('Method not implemented!')
 
+  def GetLatest(self, request, context):
+    # missing associated documentation comment in .proto file
+    pass
+    context.set_code(grpc.StatusCode.UNIMPLEMENTED)
+    context.set_details('Method not implemented!')
+    raise NotImplementedError('Method not implemented!')
+
+  def GetAsOf(self, request, context):
+    # missing associated documentation comment in .proto file
+    pass
+    context.set_code(grpc.StatusCode.UNIMPLEMENTED)
+    context.set_details('Method not implemented!')
+    raise NotImplementedError('Method not implemented!')
+
+  def SaveChunked(self, request_iterator, context):
+    # missing associated documentation comment in .proto file
+    pass
+    context.set_code(grpc.StatusCode.UNIMPLEMENTED)
+    context.set_details('Method not implemented!')
+    raise NotImplementedError('Method not implemented!')
+
+  def GetChunked(self, request, context):
+    # missing associated documentation comment in .proto file
+    pass
+    context.set_code(grpc.StatusCode.UNIMPLEMENTED)
+    context.set_details('Method not implemented!')
+    raise NotImplementedError('Method not implemented!')
+
   def List(self, request, context):
     # missing associated documentation comment in .proto file
     pass
@@ -115,6 +193,48 @@ class AIDecisionStateServiceServicer(object):
     context.set_details('Method not implemented!')
     raise NotImplementedError('Method not implemented!')
 
+  def ListKeywords(self, request, context):
+    # missing associated documentation comment in .proto file
+    pass
+    context.set_code(grpc.StatusCode.UNIMPLEMENTED)
+    context.set_details('Method not implemented!')
+    raise NotImplementedError('Method not implemented!')
+
+  def Delete(self, request, context):
+    # missing associated documentation comment in .proto file
+    pass
+    context.set_code(grpc.StatusCode.UNIMPLEMENTED)
+    context.set_details('Method not implemented!')
+    raise NotImplementedError('Method not implemented!')
+
+  def DeleteRange(self, request, context):
+    # missing associated documentation comment in .proto file
+    pass
+    context.set_code(grpc.StatusCode.UNIMPLEMENTED)
+    context.set_details('Method not implemented!')
+    raise NotImplementedError('Method not implemented!')
+
+  def SetCompactionPolicy(self, request, context):
+    """ admin RPCs for the compaction of state history
+    """
+    context.set_code(grpc.StatusCode.UNIMPLEMENTED)
+    context.set_details('Method not implemented!')
+    raise NotImplementedError('Method not implemented!')
+
+  def ListCompactionPolicies(self, request, context):
+    # missing associated documentation comment in .proto file
+    pass
+    context.set_code(grpc.StatusCode.UNIMPLEMENTED)
+    context.set_details('Method not implemented!')
+    raise NotImplementedError('Method not implemented!')
+
+  def Compact(self, request, context):
+    # missing associated documentation comment in .proto file
+    pass
+    context.set_code(grpc.StatusCode.UNIMPLEMENTED)
+    context.set_details('Method not implemented!')
+    raise NotImplementedError('Method not implemented!')
+
 
 def add_AIDecisionStateServiceServicer_to_server(servicer, server):
   rpc_method_handlers = {
@@ -128,12 +248,273 @@ def add_AIDecisionStateServiceServicer_to_server(servicer, server):
           request_deserializer=ai__decision__service__pb2.StateGetRequest.FromString,
           response_serializer=ai__decision__service__pb2.State.SerializeToString,
       ),
+      'GetLatest': grpc.unary_unary_rpc_method_handler(
+          servicer.GetLatest,
+          request_deserializer=ai__decision__service__pb2.StateGetLatestRequest.FromString,
+          response_serializer=ai__decision__service__pb2.State.SerializeToString,
+      ),
+      'GetAsOf': grpc.unary_unary_rpc_method_handler(
+          servicer.GetAsOf,
+          request_deserializer=ai__decision__service__pb2.StateGetAsOfRequest.FromString,
+          response_serializer=ai__decision__service__pb2.State.SerializeToString,
+      ),
+      'SaveChunked': grpc.stream_unary_rpc_method_handler(
+          servicer.SaveChunked,
+          request_deserializer=ai__decision__service__pb2.StateSaveChunk.FromString,
+          response_serializer=ai__decision__service__pb2.State.SerializeToString,
+      ),
+      'GetChunked': grpc.unary_stream_rpc_method_handler(
+          servicer.GetChunked,
+          request_deserializer=ai__decision__service__pb2.StateGetRequest.FromString,
+          response_serializer=ai__decision__service__pb2.StateChunk.SerializeToString,
+      ),
       'List': grpc.unary_stream_rpc_method_handler(
           servicer.List,
           request_deserializer=ai__decision__service__pb2.StateListRequest.FromString,
           response_serializer=ai__decision__service__pb2.State.SerializeToString,
       ),
+      'ListKeywords': grpc.unary_stream_rpc_method_handler(
+          servicer.ListKeywords,
+          request_deserializer=ai__decision__service__pb2.KeywordListRequest.FromString,
+          response_serializer=ai__decision__service__pb2.Keyword.SerializeToString,
+      ),
+      'Delete': grpc.unary_unary_rpc_method_handler(
+          servicer.Delete,
+          request_deserializer=ai__decision__service__pb2.StateDeleteRequest.FromString,
+          response_serializer=ai__decision__service__pb2.StateDeleteResponse.SerializeToString,
+      ),
+      'DeleteRange': grpc.unary_unary_rpc_method_handler(
+          servicer.DeleteRange,
+          request_deserializer=ai__decision__service__pb2.StateDeleteRangeRequest.FromString,
+          response_serializer=ai__decision__service__pb2.StateDeleteResponse.SerializeToString,
+      ),
+      'SetCompactionPolicy': grpc.unary_unary_rpc_method_handler(
+          servicer.SetCompactionPolicy,
+          request_deserializer=ai__decision__service__pb2.CompactionPolicy.FromString,
+          response_serializer=ai__decision__service__pb2.CompactionPolicy.SerializeToString,
+      ),
+      'ListCompactionPolicies': grpc.unary_stream_rpc_method_handler(
+          servicer.ListCompactionPolicies,
+          request_deserializer=ai__decision__service__pb2.CompactionPolicyListRequest.FromString,
+          response_serializer=ai__decision__service__pb2.CompactionPolicy.SerializeToString,
+      ),
+      'Compact': grpc.unary_stream_rpc_method_handler(
+          servicer.Compact,
+          request_deserializer=ai__decision__service__pb2.CompactRequest.FromString,
+          response_serializer=ai__decision__service__pb2.Compaction.SerializeToString,
+      ),
   }
   generic_handler = grpc.method_handlers_generic_handler(
       'callstats.ai_decision.AIDecisionStateService', rpc_method_handlers)
   server.add_generic_rpc_handlers((generic_handler,))
+
+
+class AIDecisionLeaseServiceStub(object):
+  # missing associated documentation comment in .proto file
+  pass
+
+  def __init__(self, channel):
+    """Constructor.
+
+    Args:
+      channel: A grpc.Channel.
+    """
+    self.Acquire = channel.unary_unary(
+        '/callstats.ai_decision.AIDecisionLeaseService/Acquire',
+        request_serializer=ai__decision__service__pb2.LeaseAcquireRequest.SerializeToString,
+        response_deserializer=ai__decision__service__pb2.Lease.FromString,
+        )
+    self.Renew = channel.unary_unary(
+        '/callstats.ai_decision.AIDecisionLeaseService/Renew',
+        request_serializer=ai__decision__service__pb2.LeaseRenewRequest.SerializeToString,
+        response_deserializer=ai__decision__service__pb2.Lease.FromString,
+        )
+    self.Release = channel.unary_unary(
+        '/callstats.ai_decision.AIDecisionLeaseService/Release',
+        request_serializer=ai__decision__service__pb2.LeaseReleaseRequest.SerializeToString,
+        response_deserializer=ai__decision__service__pb2.LeaseReleaseResponse.FromString,
+        )
+
+
+class AIDecisionLeaseServiceServicer(object):
+  # missing associated documentation comment in .proto file
+  pass
+
+  def Acquire(self, request, context):
+    """ Acquire fails with FailedPrecondition if the lease is held by another holder
+    """
+    context.set_code(grpc.StatusCode.UNIMPLEMENTED)
+    context.set_details('Method not implemented!')
+    raise NotImplementedError('Method not implemented!')
+
+  def Renew(self, request, context):
+    """ Renew and Release fail with FailedPrecondition if the lease expired or was acquired by another holder
+    """
+    context.set_code(grpc.StatusCode.UNIMPLEMENTED)
+    context.set_details('Method not implemented!')
+    raise NotImplementedError('Method not implemented!')
+
+  def Release(self, request, context):
+    # missing associated documentation comment in .proto file
+    pass
+    context.set_code(grpc.StatusCode.UNIMPLEMENTED)
+    context.set_details('Method not implemented!')
+    raise NotImplementedError('Method not implemented!')
+
+
+def add_AIDecisionLeaseServiceServicer_to_server(servicer, server):
+  rpc_method_handlers = {
+      'Acquire': grpc.unary_unary_rpc_method_handler(
+          servicer.Acquire,
+          request_deserializer=ai__decision__service__pb2.LeaseAcquireRequest.FromString,
+          response_serializer=ai__decision__service__pb2.Lease.SerializeToString,
+      ),
+      'Renew': grpc.unary_unary_rpc_method_handler(
+          servicer.Renew,
+          request_deserializer=ai__decision__service__pb2.LeaseRenewRequest.FromString,
+          response_serializer=ai__decision__service__pb2.Lease.SerializeToString,
+      ),
+      'Release': grpc.unary_unary_rpc_method_handler(
+          servicer.Release,
+          request_deserializer=ai__decision__service__pb2.LeaseReleaseRequest.FromString,
+          response_serializer=ai__decision__service__pb2.LeaseReleaseResponse.SerializeToString,
+      ),
+  }
+  generic_handler = grpc.method_handlers_generic_handler(
+      'callstats.ai_decision.AIDecisionLeaseService', rpc_method_handlers)
+  server.add_generic_rpc_handlers((generic_handler,))
+
+
+class AIDecisionRunServiceStub(object):
+  # missing associated documentation comment in .proto file
+  pass
+
+  def __init__(self, channel):
+    """Constructor.
+
+    Args:
+      channel: A grpc.Channel.
+    """
+    self.Start = channel.unary_unary(
+        '/callstats.ai_decision.AIDecisionRunService/Start',
+        request_serializer=ai__decision__service__pb2.RunStartRequest.SerializeToString,
+        response_deserializer=ai__decision__service__pb2.Run.FromString,
+        )
+    self.Finish = channel.unary_unary(
+        '/callstats.ai_decision.AIDecisionRunService/Finish',
+        request_serializer=ai__decision__service__pb2.RunFinishRequest.SerializeToString,
+        response_deserializer=ai__decision__service__pb2.Run.FromString,
+        )
+    self.List = channel.unary_stream(
+        '/callstats.ai_decision.AIDecisionRunService/List',
+        request_serializer=ai__decision__service__pb2.RunListRequest.SerializeToString,
+        response_deserializer=ai__decision__service__pb2.Run.FromString,
+        )
+    self.LastSuccessful = channel.unary_stream(
+        '/callstats.ai_decision.AIDecisionRunService/LastSuccessful',
+        request_serializer=ai__decision__service__pb2.RunLastSuccessfulRequest.SerializeToString,
+        response_deserializer=ai__decision__service__pb2.Run.FromString,
+        )
+
+
+class AIDecisionRunServiceServicer(object):
+  # missing associated documentation comment in .proto file
+  pass
+
+  def Start(self, request, context):
+    # missing associated documentation comment in .proto file
+    pass
+    context.set_code(grpc.StatusCode.UNIMPLEMENTED)
+    context.set_details('Method not implemented!')
+    raise NotImplementedError('Method not implemented!')
+
+  def Finish(self, request, context):
+    """ Finish fails with FailedPrecondition if the run is already finished
+    """
+    context.set_code(grpc.StatusCode.UNIMPLEMENTED)
+    context.set_details('Method not implemented!')
+    raise NotImplementedError('Method not implemented!')
+
+  def List(self, request, context):
+    # missing associated documentation comment in .proto file
+    pass
+    context.set_code(grpc.StatusCode.UNIMPLEMENTED)
+    context.set_details('Method not implemented!')
+    raise NotImplementedError('Method not implemented!')
+
+  def LastSuccessful(self, request, context):
+    """ LastSuccessful returns the newest successful run of each app
+    """
+    context.set_code(grpc.StatusCode.UNIMPLEMENTED)
+    context.set_details('Method not implemented!')
+    raise NotImplementedError('Method not implemented!')
+
+
+def add_AIDecisionRunServiceServicer_to_server(servicer, server):
+  rpc_method_handlers = {
+      'Start': grpc.unary_unary_rpc_method_handler(
+          servicer.Start,
+          request_deserializer=ai__decision__service__pb2.RunStartRequest.FromString,
+          response_serializer=ai__decision__service__pb2.Run.SerializeToString,
+      ),
+      'Finish': grpc.unary_unary_rpc_method_handler(
+          servicer.Finish,
+          request_deserializer=ai__decision__service__pb2.RunFinishRequest.FromString,
+          response_serializer=ai__decision__service__pb2.Run.SerializeToString,
+      ),
+      'List': grpc.unary_stream_rpc_method_handler(
+          servicer.List,
+          request_deserializer=ai__decision__service__pb2.RunListRequest.FromString,
+          response_serializer=ai__decision__service__pb2.Run.SerializeToString,
+      ),
+      'LastSuccessful': grpc.unary_stream_rpc_method_handler(
+          servicer.LastSuccessful,
+          request_deserializer=ai__decision__service__pb2.RunLastSuccessfulRequest.FromString,
+          response_serializer=ai__decision__service__pb2.Run.SerializeToString,
+      ),
+  }
+  generic_handler = grpc.method_handlers_generic_handler(
+      'callstats.ai_decision.AIDecisionRunService', rpc_method_handlers)
+  server.add_generic_rpc_handlers((generic_handler,))
+
+
+class AIDecisionAuditServiceStub(object):
+  # missing associated documentation comment in .proto file
+  pass
+
+  def __init__(self, channel):
+    """Constructor.
+
+    Args:
+      channel: A grpc.Channel.
+    """
+    self.List = channel.unary_stream(
+        '/callstats.ai_decision.AIDecisionAuditService/List',
+        request_serializer=ai__decision__service__pb2.AuditEventListRequest.SerializeToString,
+        response_deserializer=ai__decision__service__pb2.AuditEvent.FromString,
+        )
+
+
+class AIDecisionAuditServiceServicer(object):
+  # missing associated documentation comment in .proto file
+  pass
+
+  def List(self, request, context):
+    """ List returns the audit events newest first, it requires a token with the internal scope
+    """
+    context.set_code(grpc.StatusCode.UNIMPLEMENTED)
+    context.set_details('Method not implemented!')
+    raise NotImplementedError('Method not implemented!')
+
+
+def add_AIDecisionAuditServiceServicer_to_server(servicer, server):
+  rpc_method_handlers = {
+      'List': grpc.unary_stream_rpc_method_handler(
+          servicer.List,
+          request_deserializer=ai__decision__service__pb2.AuditEventListRequest.FromString,
+          response_serializer=ai__decision__service__pb2.AuditEvent.SerializeToString,
+      ),
+  }
+  generic_handler = grpc.method_handlers_generic_handler(
+      'callstats.ai_decision.AIDecisionAuditService', rpc_method_handlers)
+  server.add_generic_rpc_handlers((generic_handler,))
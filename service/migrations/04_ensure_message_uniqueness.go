@@ -8,7 +8,7 @@ import (
 )
 
 func init() {
-	migrations.Register(func(logger log.Logger, opts *migrations.Options) migrations.Migration {
+	register(func(logger log.Logger, opts *migrations.Options) migrations.Migration {
 		return migrations.Migration{
 			Version: 4,
 			Up: func(db migrations.DB) error {
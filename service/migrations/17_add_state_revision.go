@@ -0,0 +1,34 @@
+package migrations
+
+import (
+	"fmt"
+
+	"github.com/callstats-io/go-common/log"
+	"github.com/callstats-io/go-common/postgres/migrations"
+)
+
+func init() {
+	register(func(logger log.Logger, opts *migrations.Options) migrations.Migration {
+		return migrations.Migration{
+			Version: 17,
+			Up: func(db migrations.DB) error {
+				logger.Info("adding revision to aid_analytics_states...")
+				_, err := db.Exec(fmt.Sprintf(`
+					SET ROLE '%s';
+					ALTER TABLE aid_analytics_states ADD COLUMN revision INTEGER NOT NULL DEFAULT 1;
+					`, opts.RootRole))
+
+				return err
+			},
+			Down: func(db migrations.DB) error {
+				logger.Warn("dropping revision from aid_analytics_states...")
+				_, err := db.Exec(fmt.Sprintf(`
+					SET ROLE '%s';
+					ALTER TABLE aid_analytics_states DROP COLUMN IF EXISTS revision;
+				`, opts.RootRole))
+
+				return err
+			},
+		}
+	})
+}
@@ -0,0 +1,34 @@
+package migrations
+
+import (
+	"fmt"
+
+	"github.com/callstats-io/go-common/log"
+	"github.com/callstats-io/go-common/postgres/migrations"
+)
+
+func init() {
+	register(func(logger log.Logger, opts *migrations.Options) migrations.Migration {
+		return migrations.Migration{
+			Version: 18,
+			Up: func(db migrations.DB) error {
+				logger.Info("adding latest state index to aid_analytics_states...")
+				_, err := db.Exec(fmt.Sprintf(`
+					SET ROLE '%s';
+					CREATE INDEX aid_analytics_states_latest_idx ON aid_analytics_states (app_id, keyword, saved_at DESC);
+					`, opts.RootRole))
+
+				return err
+			},
+			Down: func(db migrations.DB) error {
+				logger.Warn("dropping latest state index from aid_analytics_states...")
+				_, err := db.Exec(fmt.Sprintf(`
+					SET ROLE '%s';
+					DROP INDEX IF EXISTS aid_analytics_states_latest_idx;
+				`, opts.RootRole))
+
+				return err
+			},
+		}
+	})
+}
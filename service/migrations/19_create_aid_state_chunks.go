@@ -0,0 +1,46 @@
+package migrations
+
+import (
+	"fmt"
+
+	"github.com/callstats-io/go-common/log"
+	"github.com/callstats-io/go-common/postgres/migrations"
+)
+
+func init() {
+	register(func(logger log.Logger, opts *migrations.Options) migrations.Migration {
+		return migrations.Migration{
+			Version: 19,
+			Up: func(db migrations.DB) error {
+				logger.Info("creating table aid_analytics_state_chunks...")
+				_, err := db.Exec(fmt.Sprintf(`
+					SET ROLE '%s';
+					ALTER TABLE aid_analytics_states
+						ADD COLUMN chunk_count INTEGER NOT NULL DEFAULT 0,
+						ADD COLUMN checksum TEXT;
+					CREATE TABLE aid_analytics_state_chunks(
+						state_id		INTEGER NOT NULL REFERENCES aid_analytics_states(id) ON DELETE CASCADE,
+						seq				INTEGER NOT NULL,
+						data			BYTEA NOT NULL,
+						PRIMARY KEY(state_id, seq)
+					);
+					GRANT SELECT ON aid_analytics_state_chunks TO %s;
+					`, opts.RootRole, readRole(opts)))
+
+				return err
+			},
+			Down: func(db migrations.DB) error {
+				logger.Warn("dropping table aid_analytics_state_chunks...")
+				_, err := db.Exec(fmt.Sprintf(`
+					SET ROLE '%s';
+					DROP TABLE IF EXISTS aid_analytics_state_chunks;
+					ALTER TABLE aid_analytics_states
+						DROP COLUMN IF EXISTS chunk_count,
+						DROP COLUMN IF EXISTS checksum;
+				`, opts.RootRole))
+
+				return err
+			},
+		}
+	})
+}
@@ -0,0 +1,48 @@
+package migrations
+
+import (
+	"fmt"
+
+	"github.com/callstats-io/go-common/log"
+	"github.com/callstats-io/go-common/postgres/migrations"
+)
+
+// The codec columns are only dropped while no payload is compressed, compressed payloads would become unreadable.
+func init() {
+	register(func(logger log.Logger, opts *migrations.Options) migrations.Migration {
+		return migrations.Migration{
+			Version: 20,
+			Up: func(db migrations.DB) error {
+				logger.Info("adding payload codecs...")
+				_, err := db.Exec(fmt.Sprintf(`
+					SET ROLE '%s';
+					ALTER TABLE aid_analytics_states ADD COLUMN codec TEXT NOT NULL DEFAULT 'none';
+					ALTER TABLE aid_analytics_state_chunks ADD COLUMN codec TEXT NOT NULL DEFAULT 'none';
+					ALTER TABLE messages ADD COLUMN codec TEXT NOT NULL DEFAULT 'none';
+					`, opts.RootRole))
+
+				return err
+			},
+			Down: func(db migrations.DB) error {
+				logger.Warn("dropping payload codecs...")
+				_, err := db.Exec(fmt.Sprintf(`
+					SET ROLE '%s';
+					DO $$
+					BEGIN
+						IF EXISTS (SELECT 1 FROM aid_analytics_states WHERE codec <> 'none')
+							OR EXISTS (SELECT 1 FROM aid_analytics_state_chunks WHERE codec <> 'none')
+							OR EXISTS (SELECT 1 FROM messages WHERE codec <> 'none') THEN
+							RAISE EXCEPTION 'compressed payloads exist, decompress them before dropping their codecs';
+						END IF;
+					END
+					$$;
+					ALTER TABLE aid_analytics_states DROP COLUMN IF EXISTS codec;
+					ALTER TABLE aid_analytics_state_chunks DROP COLUMN IF EXISTS codec;
+					ALTER TABLE messages DROP COLUMN IF EXISTS codec;
+				`, opts.RootRole))
+
+				return err
+			},
+		}
+	})
+}
@@ -0,0 +1,49 @@
+package migrations
+
+import (
+	"fmt"
+
+	"github.com/callstats-io/go-common/log"
+	"github.com/callstats-io/go-common/postgres/migrations"
+)
+
+func init() {
+	register(func(logger log.Logger, opts *migrations.Options) migrations.Migration {
+		return migrations.Migration{
+			Version: 21,
+			Up: func(db migrations.DB) error {
+				logger.Info("creating table aid_analytics_keywords...")
+				_, err := db.Exec(fmt.Sprintf(`
+					SET ROLE '%s';
+					CREATE TABLE aid_analytics_keywords(
+						keyword			TEXT NOT NULL,
+						content_type	TEXT NOT NULL,
+						schema_version	INTEGER NOT NULL DEFAULT 1,
+						max_size		INTEGER NOT NULL DEFAULT 0,
+						owner			TEXT NOT NULL,
+						created_at		TIMESTAMP WITH TIME ZONE NOT NULL DEFAULT now(),
+						PRIMARY KEY(keyword)
+					);
+					GRANT SELECT ON aid_analytics_keywords TO %s;
+					ALTER TABLE aid_analytics_states ADD COLUMN schema_version INTEGER NOT NULL DEFAULT 0;
+					INSERT INTO aid_analytics_keywords (keyword, content_type, schema_version, max_size, owner)
+					VALUES
+						('latest_dates', 'application/json', 1, 1048576, 'ai-decision-pipeline'),
+						('latest_date', 'application/json', 1, 1048576, 'ai-decision-pipeline');
+					`, opts.RootRole, readRole(opts)))
+
+				return err
+			},
+			Down: func(db migrations.DB) error {
+				logger.Warn("dropping table aid_analytics_keywords...")
+				_, err := db.Exec(fmt.Sprintf(`
+					SET ROLE '%s';
+					ALTER TABLE aid_analytics_states DROP COLUMN IF EXISTS schema_version;
+					DROP TABLE IF EXISTS aid_analytics_keywords;
+				`, opts.RootRole))
+
+				return err
+			},
+		}
+	})
+}
@@ -0,0 +1,42 @@
+package migrations
+
+import (
+	"fmt"
+
+	"github.com/callstats-io/go-common/log"
+	"github.com/callstats-io/go-common/postgres/migrations"
+)
+
+func init() {
+	register(func(logger log.Logger, opts *migrations.Options) migrations.Migration {
+		return migrations.Migration{
+			Version: 22,
+			Up: func(db migrations.DB) error {
+				logger.Info("creating table aid_analytics_compaction_policies...")
+				_, err := db.Exec(fmt.Sprintf(`
+					SET ROLE '%s';
+					CREATE TABLE aid_analytics_compaction_policies(
+						keyword					TEXT NOT NULL,
+						keep_last				INTEGER NOT NULL DEFAULT 0,
+						keep_daily_after_days	INTEGER NOT NULL DEFAULT 0,
+						delete_after_days		INTEGER NOT NULL DEFAULT 0,
+						updated_at				TIMESTAMP WITH TIME ZONE NOT NULL DEFAULT now(),
+						PRIMARY KEY(keyword)
+					);
+					GRANT SELECT ON aid_analytics_compaction_policies TO %s;
+					`, opts.RootRole, readRole(opts)))
+
+				return err
+			},
+			Down: func(db migrations.DB) error {
+				logger.Warn("dropping table aid_analytics_compaction_policies...")
+				_, err := db.Exec(fmt.Sprintf(`
+					SET ROLE '%s';
+					DROP TABLE IF EXISTS aid_analytics_compaction_policies;
+				`, opts.RootRole))
+
+				return err
+			},
+		}
+	})
+}
@@ -0,0 +1,44 @@
+package migrations
+
+import (
+	"fmt"
+
+	"github.com/callstats-io/go-common/log"
+	"github.com/callstats-io/go-common/postgres/migrations"
+)
+
+func init() {
+	register(func(logger log.Logger, opts *migrations.Options) migrations.Migration {
+		return migrations.Migration{
+			Version: 23,
+			Up: func(db migrations.DB) error {
+				logger.Info("creating table aid_leases...")
+				_, err := db.Exec(fmt.Sprintf(`
+					SET ROLE '%s';
+					CREATE SEQUENCE aid_lease_fencing_tokens;
+					CREATE TABLE aid_leases(
+						app_id			INTEGER NOT NULL,
+						name			TEXT NOT NULL,
+						holder			TEXT NOT NULL,
+						fencing_token	BIGINT NOT NULL,
+						expires_at		TIMESTAMP WITH TIME ZONE NOT NULL,
+						PRIMARY KEY(app_id, name)
+					);
+					GRANT SELECT ON aid_leases TO %s;
+					`, opts.RootRole, readRole(opts)))
+
+				return err
+			},
+			Down: func(db migrations.DB) error {
+				logger.Warn("dropping table aid_leases...")
+				_, err := db.Exec(fmt.Sprintf(`
+					SET ROLE '%s';
+					DROP TABLE IF EXISTS aid_leases;
+					DROP SEQUENCE IF EXISTS aid_lease_fencing_tokens;
+				`, opts.RootRole))
+
+				return err
+			},
+		}
+	})
+}
@@ -0,0 +1,52 @@
+package migrations
+
+import (
+	"fmt"
+
+	"github.com/callstats-io/go-common/log"
+	"github.com/callstats-io/go-common/postgres/migrations"
+)
+
+func init() {
+	register(func(logger log.Logger, opts *migrations.Options) migrations.Migration {
+		return migrations.Migration{
+			Version: 24,
+			Up: func(db migrations.DB) error {
+				logger.Info("creating table aid_runs...")
+				_, err := db.Exec(fmt.Sprintf(`
+					SET ROLE '%s';
+					CREATE TABLE aid_runs(
+						id				SERIAL,
+						app_id			INTEGER NOT NULL,
+						pipeline		TEXT NOT NULL,
+						status			TEXT NOT NULL,
+						error			TEXT,
+						counters		JSONB,
+						started_at		TIMESTAMP WITH TIME ZONE NOT NULL DEFAULT now(),
+						finished_at		TIMESTAMP WITH TIME ZONE,
+						PRIMARY KEY(id)
+					);
+					CREATE INDEX aid_runs_app_idx ON aid_runs (app_id, status, started_at DESC);
+					GRANT SELECT ON aid_runs TO %s;
+					ALTER TABLE messages ADD COLUMN run_id INTEGER REFERENCES aid_runs (id) ON DELETE SET NULL;
+					CREATE INDEX messages_run_idx ON messages (run_id);
+					ALTER TABLE aid_analytics_states ADD COLUMN run_id INTEGER REFERENCES aid_runs (id) ON DELETE SET NULL;
+					CREATE INDEX aid_analytics_states_run_idx ON aid_analytics_states (run_id);
+					`, opts.RootRole, readRole(opts)))
+
+				return err
+			},
+			Down: func(db migrations.DB) error {
+				logger.Warn("dropping table aid_runs...")
+				_, err := db.Exec(fmt.Sprintf(`
+					SET ROLE '%s';
+					ALTER TABLE aid_analytics_states DROP COLUMN IF EXISTS run_id;
+					ALTER TABLE messages DROP COLUMN IF EXISTS run_id;
+					DROP TABLE IF EXISTS aid_runs;
+				`, opts.RootRole))
+
+				return err
+			},
+		}
+	})
+}
@@ -0,0 +1,155 @@
+package migrations
+
+import (
+	"fmt"
+
+	"github.com/callstats-io/go-common/log"
+	"github.com/callstats-io/go-common/postgres/migrations"
+)
+
+// Messages and states are partitioned by month of generated_at and saved_at with table inheritance,
+// declarative partitioning cannot carry the primary keys, unique constraints and foreign keys the service relies on.
+// Partitions are named <table>_yYYYYmMM and created by aid_create_partition, existing rows are moved into them.
+// Rows inserted into the parent tables are still read, but are never dropped by retention.
+// The foreign key of aid_analytics_state_chunks to the states is dropped as it cannot reference the partitions,
+// chunks of deleted states are deleted by the aid_delete_state_chunks trigger of the states instead. Nothing rejects
+// chunks of states that do not exist until migration 27 adds a check on insert.
+func init() {
+	register(func(logger log.Logger, opts *migrations.Options) migrations.Migration {
+		return migrations.Migration{
+			Version: 25,
+			Up: func(db migrations.DB) error {
+				logger.Info("partitioning messages and aid_analytics_states by month...")
+				_, err := db.Exec(fmt.Sprintf(`
+					SET ROLE '%s';
+					CREATE FUNCTION aid_delete_state_chunks() RETURNS TRIGGER AS $$
+					BEGIN
+						DELETE FROM aid_analytics_state_chunks WHERE state_id = OLD.id;
+						RETURN OLD;
+					END
+					$$ LANGUAGE plpgsql;
+					ALTER TABLE aid_analytics_state_chunks DROP CONSTRAINT aid_analytics_state_chunks_state_id_fkey;
+
+					CREATE FUNCTION aid_create_partition(parent TEXT, part_month DATE) RETURNS TEXT AS $$
+					DECLARE
+						first_day DATE := date_trunc('month', part_month::timestamp)::date;
+						child TEXT := parent || to_char(first_day, '"_y"YYYY"m"MM');
+						from_at TIMESTAMPTZ := first_day::timestamp AT TIME ZONE 'UTC';
+						to_at TIMESTAMPTZ := (first_day + interval '1 month') AT TIME ZONE 'UTC';
+					BEGIN
+						IF to_regclass(child) IS NOT NULL THEN
+							RETURN child;
+						END IF;
+						PERFORM pg_advisory_xact_lock(hashtext(child));
+						IF to_regclass(child) IS NOT NULL THEN
+							RETURN child;
+						END IF;
+
+						IF parent = 'messages' THEN
+							EXECUTE format('CREATE TABLE %%1$I (
+									PRIMARY KEY(id),
+									CHECK (generated_at >= %%2$L AND generated_at < %%3$L),
+									CONSTRAINT %%4$I UNIQUE(app_id, generated_at, template_id),
+									CONSTRAINT messages_template_id_fkey FOREIGN KEY (template_id) REFERENCES message_templates(id),
+									CONSTRAINT %%5$I FOREIGN KEY (run_id) REFERENCES aid_runs(id) ON DELETE SET NULL
+								) INHERITS (messages)',
+								child, from_at, to_at, child || '_message_uniqueness_idx', child || '_run_id_fkey');
+							EXECUTE format('CREATE INDEX %%I ON %%I (app_id, template_id, generated_at)', child || '_template_idx', child);
+							EXECUTE format('CREATE INDEX %%I ON %%I (run_id)', child || '_run_idx', child);
+						ELSIF parent = 'aid_analytics_states' THEN
+							EXECUTE format('CREATE TABLE %%1$I (
+									PRIMARY KEY(id),
+									CHECK (saved_at >= %%2$L AND saved_at < %%3$L),
+									CONSTRAINT %%4$I UNIQUE(app_id, saved_at, keyword),
+									CONSTRAINT %%5$I FOREIGN KEY (run_id) REFERENCES aid_runs(id) ON DELETE SET NULL
+								) INHERITS (aid_analytics_states)',
+								child, from_at, to_at, child || '_keyword_idx', child || '_run_id_fkey');
+							EXECUTE format('CREATE INDEX %%I ON %%I (app_id, keyword, saved_at DESC)', child || '_latest_idx', child);
+							EXECUTE format('CREATE INDEX %%I ON %%I (run_id)', child || '_run_idx', child);
+							EXECUTE format('CREATE TRIGGER %%I AFTER DELETE ON %%I
+								FOR EACH ROW EXECUTE PROCEDURE aid_delete_state_chunks()', child || '_chunks_trigger', child);
+						ELSE
+							RAISE EXCEPTION 'table %% is not partitioned', parent;
+						END IF;
+						EXECUTE format('GRANT SELECT ON %%I TO %%I', child, '%[2]s');
+						RETURN child;
+					END
+					$$ LANGUAGE plpgsql SECURITY DEFINER;
+
+					CREATE FUNCTION aid_drop_partition(parent TEXT, part_month DATE) RETURNS TEXT AS $$
+					DECLARE
+						child TEXT := parent || to_char(date_trunc('month', part_month::timestamp), '"_y"YYYY"m"MM');
+					BEGIN
+						IF parent NOT IN ('messages', 'aid_analytics_states') THEN
+							RAISE EXCEPTION 'table %% is not partitioned', parent;
+						END IF;
+						IF to_regclass(child) IS NULL THEN
+							RETURN NULL;
+						END IF;
+						IF parent = 'aid_analytics_states' THEN
+							EXECUTE format('DELETE FROM aid_analytics_state_chunks WHERE state_id IN (SELECT id FROM %%I)', child);
+						END IF;
+						EXECUTE format('DROP TABLE %%I', child);
+						RETURN child;
+					END
+					$$ LANGUAGE plpgsql SECURITY DEFINER;
+
+					DO $$
+					DECLARE
+						part_month DATE;
+					BEGIN
+						FOR part_month IN SELECT DISTINCT date_trunc('month', generated_at AT TIME ZONE 'UTC')::date FROM ONLY messages LOOP
+							EXECUTE format('WITH moved AS (
+									DELETE FROM ONLY messages WHERE generated_at >= $1 AND generated_at < $2 RETURNING *
+								) INSERT INTO %%I SELECT * FROM moved', aid_create_partition('messages', part_month))
+								USING part_month::timestamp AT TIME ZONE 'UTC', (part_month + interval '1 month') AT TIME ZONE 'UTC';
+						END LOOP;
+						FOR part_month IN SELECT DISTINCT date_trunc('month', saved_at AT TIME ZONE 'UTC')::date FROM ONLY aid_analytics_states LOOP
+							EXECUTE format('WITH moved AS (
+									DELETE FROM ONLY aid_analytics_states WHERE saved_at >= $1 AND saved_at < $2 RETURNING *
+								) INSERT INTO %%I SELECT * FROM moved', aid_create_partition('aid_analytics_states', part_month))
+								USING part_month::timestamp AT TIME ZONE 'UTC', (part_month + interval '1 month') AT TIME ZONE 'UTC';
+						END LOOP;
+					END
+					$$;
+					CREATE TRIGGER aid_analytics_states_chunks_trigger AFTER DELETE ON aid_analytics_states
+						FOR EACH ROW EXECUTE PROCEDURE aid_delete_state_chunks();
+					CREATE INDEX messages_template_idx ON messages (app_id, template_id, generated_at);
+					`, opts.RootRole, readRole(opts)))
+
+				return err
+			},
+			Down: func(db migrations.DB) error {
+				logger.Warn("moving partitioned messages and aid_analytics_states back into their tables...")
+				_, err := db.Exec(fmt.Sprintf(`
+					SET ROLE '%s';
+					DROP INDEX IF EXISTS messages_template_idx;
+					DO $$
+					DECLARE
+						parent TEXT;
+						child TEXT;
+					BEGIN
+						FOR parent, child IN SELECT p.relname, c.relname FROM pg_inherits i
+							JOIN pg_class p ON p.oid = i.inhparent
+							JOIN pg_class c ON c.oid = i.inhrelid
+							WHERE i.inhparent IN ('messages'::regclass, 'aid_analytics_states'::regclass) LOOP
+							EXECUTE format('DROP TRIGGER IF EXISTS %%I ON %%I', child || '_chunks_trigger', child);
+							EXECUTE format('WITH moved AS (DELETE FROM %%I RETURNING *) INSERT INTO %%I SELECT * FROM moved', child, parent);
+							EXECUTE format('DROP TABLE %%I', child);
+						END LOOP;
+					END
+					$$;
+					DROP FUNCTION IF EXISTS aid_drop_partition(TEXT, DATE);
+					DROP FUNCTION IF EXISTS aid_create_partition(TEXT, DATE);
+					DROP TRIGGER IF EXISTS aid_analytics_states_chunks_trigger ON aid_analytics_states;
+					DROP FUNCTION IF EXISTS aid_delete_state_chunks();
+					DELETE FROM aid_analytics_state_chunks WHERE state_id NOT IN (SELECT id FROM aid_analytics_states);
+					ALTER TABLE aid_analytics_state_chunks ADD CONSTRAINT aid_analytics_state_chunks_state_id_fkey
+						FOREIGN KEY (state_id) REFERENCES aid_analytics_states(id) ON DELETE CASCADE;
+				`, opts.RootRole))
+
+				return err
+			},
+		}
+	})
+}
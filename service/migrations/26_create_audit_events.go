@@ -0,0 +1,61 @@
+package migrations
+
+import (
+	"fmt"
+
+	"github.com/callstats-io/go-common/log"
+	"github.com/callstats-io/go-common/postgres/migrations"
+)
+
+func init() {
+	register(func(logger log.Logger, opts *migrations.Options) migrations.Migration {
+		return migrations.Migration{
+			Version: 26,
+			Up: func(db migrations.DB) error {
+				logger.Info("creating table audit_events...")
+				_, err := db.Exec(fmt.Sprintf(`
+					SET ROLE '%s';
+					CREATE TABLE audit_events(
+						id				BIGSERIAL,
+						occurred_at		TIMESTAMP WITH TIME ZONE NOT NULL DEFAULT now(),
+						actor			TEXT,
+						rpc				TEXT,
+						app_id			INTEGER NOT NULL DEFAULT 0,
+						action			TEXT NOT NULL,
+						target			TEXT,
+						count			INTEGER NOT NULL DEFAULT 1,
+						before_hash		TEXT,
+						after_hash		TEXT,
+						reason			TEXT,
+						PRIMARY KEY(id)
+					);
+					CREATE INDEX audit_events_app_idx ON audit_events (app_id, occurred_at DESC);
+					CREATE INDEX audit_events_target_idx ON audit_events (target);
+					CREATE INDEX audit_events_actor_idx ON audit_events (actor, occurred_at DESC);
+					CREATE FUNCTION aid_audit_events_append_only() RETURNS TRIGGER AS $$
+					BEGIN
+						RAISE EXCEPTION 'audit_events is append-only';
+					END
+					$$ LANGUAGE plpgsql;
+					CREATE TRIGGER audit_events_append_only BEFORE UPDATE OR DELETE ON audit_events
+						FOR EACH ROW EXECUTE PROCEDURE aid_audit_events_append_only();
+					CREATE TRIGGER audit_events_no_truncate BEFORE TRUNCATE ON audit_events
+						FOR EACH STATEMENT EXECUTE PROCEDURE aid_audit_events_append_only();
+					GRANT SELECT ON audit_events TO %s;
+					`, opts.RootRole, readRole(opts)))
+
+				return err
+			},
+			Down: func(db migrations.DB) error {
+				logger.Warn("dropping table audit_events...")
+				_, err := db.Exec(fmt.Sprintf(`
+					SET ROLE '%s';
+					DROP TABLE IF EXISTS audit_events;
+					DROP FUNCTION IF EXISTS aid_audit_events_append_only();
+				`, opts.RootRole))
+
+				return err
+			},
+		}
+	})
+}
@@ -0,0 +1,51 @@
+package migrations
+
+import (
+	"fmt"
+
+	"github.com/callstats-io/go-common/log"
+	"github.com/callstats-io/go-common/postgres/migrations"
+)
+
+// The foreign key of chunks to their states was dropped by the partitioning of the states, a foreign key cannot
+// reference the partitions. Chunks are checked to reference a state on insert instead, the state is locked as by a
+// foreign key so that it cannot be deleted before the chunk is committed. Missing states are raised as foreign key
+// violations of the aid_check_state_chunk_state check, so that they are classified like a violated foreign key.
+func init() {
+	register(func(logger log.Logger, opts *migrations.Options) migrations.Migration {
+		return migrations.Migration{
+			Version: 27,
+			Up: func(db migrations.DB) error {
+				logger.Info("checking states of aid_analytics_state_chunks...")
+				_, err := db.Exec(fmt.Sprintf(`
+					SET ROLE '%s';
+					CREATE FUNCTION aid_check_state_chunk_state() RETURNS TRIGGER AS $$
+					BEGIN
+						PERFORM 1 FROM aid_analytics_states WHERE id = NEW.state_id FOR KEY SHARE;
+						IF NOT FOUND THEN
+							RAISE EXCEPTION 'state %% of chunk does not exist', NEW.state_id
+								USING ERRCODE = 'foreign_key_violation',
+									CONSTRAINT = 'aid_check_state_chunk_state';
+						END IF;
+						RETURN NEW;
+					END
+					$$ LANGUAGE plpgsql;
+					CREATE TRIGGER aid_analytics_state_chunks_state_trigger BEFORE INSERT OR UPDATE OF state_id
+						ON aid_analytics_state_chunks FOR EACH ROW EXECUTE PROCEDURE aid_check_state_chunk_state();
+					`, opts.RootRole))
+
+				return err
+			},
+			Down: func(db migrations.DB) error {
+				logger.Warn("dropping state check of aid_analytics_state_chunks...")
+				_, err := db.Exec(fmt.Sprintf(`
+					SET ROLE '%s';
+					DROP TRIGGER IF EXISTS aid_analytics_state_chunks_state_trigger ON aid_analytics_state_chunks;
+					DROP FUNCTION IF EXISTS aid_check_state_chunk_state();
+				`, opts.RootRole))
+
+				return err
+			},
+		}
+	})
+}
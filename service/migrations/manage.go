@@ -0,0 +1,282 @@
+package migrations
+
+import (
+	"context"
+	"fmt"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/callstats-io/go-common/log"
+	"github.com/callstats-io/go-common/postgres"
+	"github.com/callstats-io/go-common/postgres/migrations"
+	"github.com/go-pg/pg"
+)
+
+// Status describes the migrations of a database
+type Status struct {
+	// Version is the version of the last applied migration, 0 if none is applied
+	Version int64
+	Applied []*AppliedMigration
+	// Pending are the versions of the registered migrations above the version
+	Pending []int64
+}
+
+// AppliedMigration is a registered migration applied to a database
+type AppliedMigration struct {
+	Version   int64
+	AppliedAt time.Time // zero if applied before the migrations table recorded times
+}
+
+// PlannedMigration is a pending migration with the statements its up migration executes
+type PlannedMigration struct {
+	Version    int64
+	Statements []string
+}
+
+// Verification is the result of applying a migration up, down and up again
+type Verification struct {
+	Version int64
+	// Problem describes why the migration is irreversible, empty if it is reversible
+	Problem string
+}
+
+// Irreversible returns true if the down migration does not undo the up migration
+func (v *Verification) Irreversible() bool {
+	return v.Problem != ""
+}
+
+// record returns the statements executed by a migration function without executing them
+func record(migrate func(migrations.DB) error) ([]string, error) {
+	r := &recorder{}
+	if err := migrate(r); err != nil {
+		return nil, err
+	}
+	return r.statements, nil
+}
+
+// isNoOp returns true if the statements only set the role
+func isNoOp(statements []string) bool {
+	for _, statement := range statements {
+		for _, s := range strings.Split(statement, ";") {
+			s = strings.TrimSpace(s)
+			if s != "" && !strings.HasPrefix(strings.ToUpper(s), "SET ROLE") {
+				return false
+			}
+		}
+	}
+	return true
+}
+
+// CurrentStatus returns the applied and pending migrations of the database of the client.
+// The migrations table has to be created with the init command first.
+func CurrentStatus(ctx context.Context, client postgres.Client, logger log.Logger, opts *migrations.Options) (*Status, error) {
+	db, err := client.DB(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	var versions []struct {
+		Version   int64
+		AppliedAt time.Time
+	}
+	if _, err := db.Query(&versions, `
+		SELECT version, max(created_at) AS applied_at FROM gopg_migrations GROUP BY version`); err != nil {
+		return nil, err
+	}
+	status := &Status{}
+	if _, err := db.QueryOne(pg.Scan(&status.Version), `
+		SELECT coalesce((SELECT version FROM gopg_migrations ORDER BY id DESC LIMIT 1), 0)`); err != nil {
+		return nil, err
+	}
+	appliedAt := make(map[int64]time.Time, len(versions))
+	for _, v := range versions {
+		appliedAt[v.Version] = v.AppliedAt
+	}
+	for _, mig := range sortedMigrations(logger, opts) {
+		if mig.Version <= status.Version {
+			status.Applied = append(status.Applied, &AppliedMigration{Version: mig.Version, AppliedAt: appliedAt[mig.Version]})
+		} else {
+			status.Pending = append(status.Pending, mig.Version)
+		}
+	}
+	return status, nil
+}
+
+// Plan returns the statements the pending migrations of the database of the client would execute when migrating up
+func Plan(ctx context.Context, client postgres.Client, logger log.Logger, opts *migrations.Options) ([]*PlannedMigration, error) {
+	status, err := CurrentStatus(ctx, client, logger, opts)
+	if err != nil {
+		return nil, err
+	}
+
+	var plan []*PlannedMigration
+	for _, mig := range sortedMigrations(logger, opts) {
+		if mig.Version <= status.Version {
+			continue
+		}
+		statements, err := record(mig.Up)
+		if err != nil {
+			return nil, fmt.Errorf("migration %d: %s", mig.Version, err)
+		}
+		plan = append(plan, &PlannedMigration{Version: mig.Version, Statements: statements})
+	}
+	return plan, nil
+}
+
+// Verify applies each migration up, down and up again in a scratch schema of the database of the client
+// and reports the migrations whose down migration does not undo the up migration: downs that fail or do nothing,
+// and downs after which the schema or the row counts of its tables differ from before the up migration.
+// The scratch schema is dropped afterwards, the migrations of the database itself are not touched.
+func Verify(ctx context.Context, client postgres.Client, logger log.Logger, opts *migrations.Options) ([]*Verification, error) {
+	db, err := client.DB(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	// a single connection keeps the search path of the scratch schema for all statements
+	connOpts := *db.DB.Options()
+	connOpts.PoolSize = 1
+	conn := pg.Connect(&connOpts).WithContext(ctx)
+	defer conn.Close()
+
+	schema := fmt.Sprintf("aid_verify_%d", time.Now().UnixNano())
+	if _, err := conn.Exec("CREATE SCHEMA ? AUTHORIZATION ?", pg.F(schema), pg.F(opts.RootRole)); err != nil {
+		return nil, err
+	}
+	defer func() {
+		if _, err := conn.Exec("SET ROLE ?; DROP SCHEMA ? CASCADE", pg.F(opts.RootRole), pg.F(schema)); err != nil {
+			logger.Error("failed to drop scratch schema", log.String("schema", schema), log.Error(err))
+		}
+	}()
+	if _, err := conn.Exec("SET search_path TO ?", pg.F(schema)); err != nil {
+		return nil, err
+	}
+
+	var verifications []*Verification
+	for _, mig := range sortedMigrations(logger, opts) {
+		v := &Verification{Version: mig.Version}
+		verifications = append(verifications, v)
+
+		before, err := snapshot(conn, schema)
+		if err != nil {
+			return verifications, err
+		}
+		if err := mig.Up(conn); err != nil {
+			return verifications, fmt.Errorf("migration %d: up failed: %s", mig.Version, err)
+		}
+		after, err := snapshot(conn, schema)
+		if err != nil {
+			return verifications, err
+		}
+
+		ups, err := record(mig.Up)
+		if err != nil {
+			return verifications, err
+		}
+		downs, err := record(mig.Down)
+		if err != nil {
+			return verifications, err
+		}
+		if isNoOp(downs) && !isNoOp(ups) {
+			v.Problem = "down does nothing"
+			continue
+		}
+
+		if err := mig.Down(conn); err != nil {
+			v.Problem = fmt.Sprintf("down failed: %s", err)
+			return verifications, nil
+		}
+		reverted, err := snapshot(conn, schema)
+		if err != nil {
+			return verifications, err
+		}
+		if diff := snapshotDiff(before, reverted); diff != "" {
+			v.Problem = fmt.Sprintf("down does not restore %s", diff)
+		}
+		if err := mig.Up(conn); err != nil {
+			v.Problem = fmt.Sprintf("up after down failed: %s", err)
+			return verifications, nil
+		}
+		reapplied, err := snapshot(conn, schema)
+		if err != nil {
+			return verifications, err
+		}
+		if diff := snapshotDiff(after, reapplied); diff != "" && v.Problem == "" {
+			v.Problem = fmt.Sprintf("up after down differs in %s", diff)
+		}
+	}
+	return verifications, nil
+}
+
+// snapshotQuery describes the tables, columns, indexes, constraints, functions and triggers of a schema, one per row
+const snapshotQuery = `
+	SELECT 'column ' || table_name || '.' || column_name || ' ' || data_type || ' ' || is_nullable || ' ' || coalesce(column_default, '')
+	FROM information_schema.columns WHERE table_schema = ?0
+	UNION ALL
+	SELECT 'index ' || indexdef FROM pg_indexes WHERE schemaname = ?0
+	UNION ALL
+	SELECT 'constraint ' || conrelid::regclass || '.' || conname || ' ' || pg_get_constraintdef(oid)
+	FROM pg_constraint WHERE connamespace = ?0::regnamespace
+	UNION ALL
+	SELECT 'function ' || oid::regprocedure FROM pg_proc WHERE pronamespace = ?0::regnamespace
+	UNION ALL
+	SELECT 'trigger ' || tgrelid::regclass || '.' || tgname FROM pg_trigger t
+	JOIN pg_class c ON c.oid = t.tgrelid WHERE c.relnamespace = ?0::regnamespace AND NOT t.tgisinternal
+	UNION ALL
+	SELECT 'sequence ' || sequence_name FROM information_schema.sequences WHERE sequence_schema = ?0
+	ORDER BY 1`
+
+// snapshot returns the description of a schema and the row count of each of its tables
+func snapshot(db *pg.DB, schema string) (map[string]bool, error) {
+	var rows pg.Strings
+	if _, err := db.Query(&rows, snapshotQuery, schema); err != nil {
+		return nil, err
+	}
+	var tables pg.Strings
+	if _, err := db.Query(&tables, `
+		SELECT table_name FROM information_schema.tables
+		WHERE table_schema = ? AND table_type = 'BASE TABLE' ORDER BY table_name`, schema); err != nil {
+		return nil, err
+	}
+	for _, table := range tables {
+		var count int
+		if _, err := db.QueryOne(pg.Scan(&count), "SELECT count(*) FROM ONLY ?", pg.F(table)); err != nil {
+			return nil, err
+		}
+		rows = append(rows, fmt.Sprintf("rows %s %d", table, count))
+	}
+
+	s := make(map[string]bool, len(rows))
+	for _, row := range rows {
+		s[row] = true
+	}
+	return s, nil
+}
+
+// snapshotDiff returns the first missing and the first unexpected row of a snapshot compared to the expected snapshot,
+// empty if both are equal
+func snapshotDiff(expected, actual map[string]bool) string {
+	var missing, unexpected []string
+	for row := range expected {
+		if !actual[row] {
+			missing = append(missing, row)
+		}
+	}
+	for row := range actual {
+		if !expected[row] {
+			unexpected = append(unexpected, row)
+		}
+	}
+	sort.Strings(missing)
+	sort.Strings(unexpected)
+
+	var diffs []string
+	if len(missing) > 0 {
+		diffs = append(diffs, fmt.Sprintf("missing %q", missing[0]))
+	}
+	if len(unexpected) > 0 {
+		diffs = append(diffs, fmt.Sprintf("unexpected %q", unexpected[0]))
+	}
+	return strings.Join(diffs, ", ")
+}
@@ -1,13 +1,63 @@
 package migrations
 
-import "github.com/callstats-io/go-common/postgres/migrations"
+import (
+	"fmt"
+	"sort"
+
+	"github.com/callstats-io/go-common/log"
+	"github.com/callstats-io/go-common/postgres/migrations"
+	"github.com/go-pg/pg/orm"
+)
 
 // MetaKeyReadRole is the key under which db access role should be found
 const MetaKeyReadRole = "readRole"
 
+// generators contains the migrations registered by this package, see UpStatements
+var generators []migrations.MigrationGeneratorFunc
+
+// register registers the migration on the default migrator and keeps it for UpStatements
+func register(genFunc migrations.MigrationGeneratorFunc) {
+	generators = append(generators, genFunc)
+	migrations.Register(genFunc)
+}
+
 func readRole(opts *migrations.Options) string {
 	if opts.Meta[MetaKeyReadRole] != "" {
 		return opts.Meta[MetaKeyReadRole].(string)
 	}
 	return opts.RootRole
 }
+
+// recorder records the queries executed by migrations instead of executing them.
+// Migrations only execute plain queries, all other methods of the embedded DB are unset.
+type recorder struct {
+	migrations.DB
+	statements []string
+}
+
+func (r *recorder) Exec(query interface{}, params ...interface{}) (orm.Result, error) {
+	r.statements = append(r.statements, fmt.Sprint(query))
+	return nil, nil
+}
+
+// sortedMigrations returns all registered migrations in version order
+func sortedMigrations(logger log.Logger, opts *migrations.Options) []migrations.Migration {
+	migs := make([]migrations.Migration, len(generators))
+	for i, genFunc := range generators {
+		migs[i] = genFunc(logger, opts)
+	}
+	sort.Slice(migs, func(i, j int) bool { return migs[i].Version < migs[j].Version })
+	return migs
+}
+
+// UpStatements returns the queries of all up migrations in version order without executing them,
+// e.g. to seed storages that cannot be migrated with the data inserted by the migrations.
+func UpStatements(logger log.Logger, opts *migrations.Options) ([]string, error) {
+	r := &recorder{}
+	for _, mig := range sortedMigrations(logger, opts) {
+		if err := mig.Up(r); err != nil {
+			return nil, fmt.Errorf("migration %d: %s", mig.Version, err)
+		}
+	}
+	return r.statements, nil
+}
@@ -3,4 +3,5 @@
 protoc -I=$GOPATH/src/github.com/callstats-io/ai-decision/service/protos \
     -I=$GOPATH/src/github.com/callstats-io/ai-decision/service/vendor \
     --go_out=plugins=grpc:$GOPATH/src/github.com/callstats-io/ai-decision/service/gen/protos/ \
+    --grpc-gateway_out=grpc_api_configuration=$GOPATH/src/github.com/callstats-io/ai-decision/service/protos/ai_decision_service_gateway.yaml:$GOPATH/src/github.com/callstats-io/ai-decision/service/gen/protos/ \
     $GOPATH/src/github.com/callstats-io/ai-decision/service/protos/*.proto
@@ -2,8 +2,10 @@ package config
 
 import (
 	"fmt"
+	"net/url"
 	"os"
 	"strconv"
+	"strings"
 )
 
 var (
@@ -19,13 +21,75 @@ type Config struct {
 	ServiceName    string
 	GRPCPort       int
 	HTTPStatusPort int
+	// The REST/JSON gateway to the gRPC services is served on the gateway port, 0 disables the gateway
+	GatewayPort int
+	// Server reflection is registered on the gRPC server if enabled, by default in local mode only.
+	// The gRPC health service runs the status checks every interval in seconds, 0 only reports the services as serving.
+	GRPCReflection          bool
+	GRPCHealthCheckInterval int
 
 	VaultPostgresCredsPath     string
 	PostgresConnectionTemplate string
 	PostgresRootRole           string
 	PostgresReadOnlyRole       string
 
+	// Local mode is meant for development. Vault is skipped and postgres is connected to with the plain DSN, if any.
+	// The environment, service name and ports are optional and the postgres roles default to the user of the DSN.
+	Local       bool
+	PostgresDSN string
+
+	// List and Get reads go to a read replica if a connection template, or a DSN in local mode, is set.
+	// The replica credentials are read from the primary creds path unless a read creds path is set.
+	// Reads of apps written to within the max staleness in seconds go to the primary, 0 always reads the replica.
+	PostgresReadConnectionTemplate string
+	VaultPostgresReadCredsPath     string
+	PostgresReadDSN                string
+	PostgresReadMaxStaleness       int
+
 	FlowdockToken string
+
+	// Calls are authorized by JWTs signed with the secret, the secret is mandatory unless authorization is disabled.
+	// Authorization is disabled explicitly, or in local mode without a secret.
+	// The signing method is one of the HMAC methods HS256, HS384 and HS512.
+	AuthJWTSecret     string
+	AuthSigningMethod string
+	AuthDisabled      bool
+
+	// Calls are traced if an exporter is set, spans are written to stdout or sent to the OpenCensus agent at the address
+	TracingExporter     string
+	TracingAgentAddress string
+
+	// Notification rates are per hour, 0 disables the respective rate limit as by default.
+	// Quiet hours are given as "HH:MM-HH:MM" in the timezone of the app.
+	NotificationAppRate          int
+	NotificationAppBurst         int
+	NotificationDestinationRate  int
+	NotificationDestinationBurst int
+	NotificationQuietHours       string
+	NotificationTimezone         string
+	NotificationAppTimezones     string
+
+	// Payloads of at least the threshold in bytes are stored compressed, 0 disables compression.
+	// Existing rows are recompressed every interval in minutes, 0 disables recompression as by default.
+	StorageCompressionThreshold  int
+	StorageRecompressionInterval int
+
+	// Messages and states are partitioned by month. Partitions of the current and next month are created every interval
+	// in minutes, 0 disables partition maintenance. Partitions older than the retention in months are dropped, 0 keeps all.
+	StoragePartitionInterval  int
+	StoragePartitionRetention int
+
+	// States are compacted by the compaction policies of their keywords every interval in minutes, 0 disables compaction
+	StateCompactionInterval int
+
+	// StateStrictKeywords rejects states with keywords missing from the keyword registry
+	StateStrictKeywords bool
+
+	// Apps and keywords without a saved state within the max age in minutes are flagged stale every interval in
+	// minutes, 0 disables the check. Keyword max ages override the max age as "keyword=minutes,...".
+	FreshnessCheckInterval  int
+	FreshnessMaxAge         int
+	FreshnessKeywordMaxAges string
 }
 
 // FromEnv reads the service settings from environment variables
@@ -46,20 +110,104 @@ func FromEnv() (config *Config, err error) {
 	}()
 
 	config = &Config{
-		Env:                        mustRead(EnvEnv),
-		ServiceName:                mustRead(EnvServiceName),
-		GRPCPort:                   mustReadInt(EnvGRPCPort),
-		HTTPStatusPort:             mustReadInt(EnvStatusPort),
-		VaultPostgresCredsPath:     mustRead(EnvVaultPostgresCredsPath),
-		PostgresConnectionTemplate: mustRead(EnvPostgresConnectionTemplate),
-		PostgresRootRole:           mustRead(EnvPostgresRootRole),
-		PostgresReadOnlyRole:       mustRead(EnvPostgresReadOnlyRole),
-		FlowdockToken:              os.Getenv(EnvFlowdockToken),
+		FlowdockToken: os.Getenv(EnvFlowdockToken),
+
+		AuthSigningMethod: readOneOfOrDefault(EnvAuthSigningMethod, DefaultAuthSigningMethod, "HS256", "HS384", "HS512"),
+		AuthDisabled:      readBoolOrDefault(EnvAuthDisabled, false),
+
+		TracingExporter:     readOneOfOrDefault(EnvTracingExporter, "", TracingExporterStdout, TracingExporterAgent),
+		TracingAgentAddress: readOrDefault(EnvTracingAgentAddress, DefaultTracingAgentAddress),
+
+		NotificationAppRate:          readIntOrDefault(EnvNotificationAppRate, DefaultNotificationAppRate),
+		NotificationAppBurst:         readIntOrDefault(EnvNotificationAppBurst, DefaultNotificationAppBurst),
+		NotificationDestinationRate:  readIntOrDefault(EnvNotificationDestinationRate, DefaultNotificationDestinationRate),
+		NotificationDestinationBurst: readIntOrDefault(EnvNotificationDestinationBurst, DefaultNotificationDestinationBurst),
+		NotificationQuietHours:       os.Getenv(EnvNotificationQuietHours),
+		NotificationTimezone:         readOrDefault(EnvNotificationTimezone, DefaultNotificationTimezone),
+		NotificationAppTimezones:     os.Getenv(EnvNotificationAppTimezones),
+
+		StorageCompressionThreshold:  readIntOrDefault(EnvStorageCompressionThreshold, DefaultStorageCompressionThreshold),
+		StorageRecompressionInterval: readIntOrDefault(EnvStorageRecompressionInterval, DefaultStorageRecompressionInterval),
+		StoragePartitionInterval:     readIntOrDefault(EnvStoragePartitionInterval, DefaultStoragePartitionInterval),
+		StoragePartitionRetention:    readIntOrDefault(EnvStoragePartitionRetention, DefaultStoragePartitionRetention),
+
+		GRPCHealthCheckInterval: readIntOrDefault(EnvGRPCHealthCheckInterval, DefaultGRPCHealthCheckInterval),
+
+		StateStrictKeywords:     readBoolOrDefault(EnvStateStrictKeywords, false),
+		StateCompactionInterval: readIntOrDefault(EnvStateCompactionInterval, DefaultStateCompactionInterval),
+
+		FreshnessCheckInterval:  readIntOrDefault(EnvFreshnessCheckInterval, DefaultFreshnessCheckInterval),
+		FreshnessMaxAge:         readIntOrDefault(EnvFreshnessMaxAge, DefaultFreshnessMaxAge),
+		FreshnessKeywordMaxAges: os.Getenv(EnvFreshnessKeywordMaxAges),
+
+		PostgresReadConnectionTemplate: os.Getenv(EnvPostgresReadConnectionTemplate),
+		VaultPostgresReadCredsPath:     os.Getenv(EnvVaultPostgresReadCredsPath),
+		PostgresReadMaxStaleness:       readIntOrDefault(EnvPostgresReadMaxStaleness, DefaultPostgresReadMaxStaleness),
 	}
 
+	config.PostgresDSN = os.Getenv(EnvPostgresDSN)
+	config.Local = readBoolOrDefault(EnvLocal, false) || config.PostgresDSN != ""
+	config.GRPCReflection = readBoolOrDefault(EnvGRPCReflection, config.Local)
+	if config.Local {
+		user := dsnUser(EnvPostgresDSN, config.PostgresDSN)
+		config.PostgresReadDSN = os.Getenv(EnvPostgresReadDSN)
+		dsnUser(EnvPostgresReadDSN, config.PostgresReadDSN)
+		config.Env = readOrDefault(EnvEnv, DefaultLocalEnv)
+		config.ServiceName = readOrDefault(EnvServiceName, DefaultLocalServiceName)
+		config.GRPCPort = readIntOrDefault(EnvGRPCPort, DefaultLocalGRPCPort)
+		config.HTTPStatusPort = readIntOrDefault(EnvStatusPort, DefaultLocalStatusPort)
+		config.GatewayPort = readIntOrDefault(EnvGatewayPort, DefaultLocalGatewayPort)
+		config.PostgresRootRole = readOrDefault(EnvPostgresRootRole, user)
+		config.PostgresReadOnlyRole = readOrDefault(EnvPostgresReadOnlyRole, config.PostgresRootRole)
+		config.AuthJWTSecret = os.Getenv(EnvAuthJWTSecret)
+		config.AuthDisabled = config.AuthDisabled || config.AuthJWTSecret == ""
+		return
+	}
+
+	config.Env = mustRead(EnvEnv)
+	config.ServiceName = mustRead(EnvServiceName)
+	config.GRPCPort = mustReadInt(EnvGRPCPort)
+	config.HTTPStatusPort = mustReadInt(EnvStatusPort)
+	config.GatewayPort = readIntOrDefault(EnvGatewayPort, 0)
+	config.VaultPostgresCredsPath = mustRead(EnvVaultPostgresCredsPath)
+	config.PostgresConnectionTemplate = mustRead(EnvPostgresConnectionTemplate)
+	config.PostgresRootRole = mustRead(EnvPostgresRootRole)
+	config.PostgresReadOnlyRole = mustRead(EnvPostgresReadOnlyRole)
+	if !config.AuthDisabled {
+		config.AuthJWTSecret = mustRead(EnvAuthJWTSecret)
+	}
 	return
 }
 
+// dsnUser returns the user of the postgres DSN of an environment variable, panics if the DSN is invalid
+func dsnUser(envVar string, dsn string) string {
+	if dsn == "" {
+		return ""
+	}
+	parsed, err := url.Parse(dsn)
+	if err != nil || parsed.Host == "" {
+		panic(fmt.Errorf("invalid postgres DSN for environment variable %s", envVar))
+	}
+	if parsed.User == nil {
+		return ""
+	}
+	return parsed.User.Username()
+}
+
+// readOneOfOrDefault returns the value of an environment variable, one of the given values, or the default if not set
+func readOneOfOrDefault(envVar string, def string, values ...string) string {
+	s := os.Getenv(envVar)
+	if s == "" {
+		return def
+	}
+	for _, v := range values {
+		if s == v {
+			return s
+		}
+	}
+	panic(fmt.Errorf("invalid value %s for environment variable %s, must be one of %s", s, envVar, strings.Join(values, ", ")))
+}
+
 func mustRead(envVar string) string {
 	s := os.Getenv(envVar)
 	if s == "" {
@@ -76,3 +224,34 @@ func mustReadInt(envVar string) int {
 	}
 	return i
 }
+
+func readOrDefault(envVar string, def string) string {
+	if s := os.Getenv(envVar); s != "" {
+		return s
+	}
+	return def
+}
+
+func readIntOrDefault(envVar string, def int) int {
+	s := os.Getenv(envVar)
+	if s == "" {
+		return def
+	}
+	i, err := strconv.Atoi(s)
+	if err != nil {
+		panic(fmt.Errorf("invalid integer %s for environment variable %s", s, envVar))
+	}
+	return i
+}
+
+func readBoolOrDefault(envVar string, def bool) bool {
+	s := os.Getenv(envVar)
+	if s == "" {
+		return def
+	}
+	b, err := strconv.ParseBool(s)
+	if err != nil {
+		panic(fmt.Errorf("invalid boolean %s for environment variable %s", s, envVar))
+	}
+	return b
+}
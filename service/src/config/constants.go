@@ -6,9 +6,84 @@ const (
 	EnvServiceName                = "SERVICE_NAME"
 	EnvGRPCPort                   = "GRPC_PORT"
 	EnvStatusPort                 = "HTTP_PORT"
+	EnvGatewayPort                = "GATEWAY_PORT"
+	EnvGRPCReflection             = "GRPC_REFLECTION"
+	EnvGRPCHealthCheckInterval    = "GRPC_HEALTH_CHECK_INTERVAL"
 	EnvVaultPostgresCredsPath     = "VAULT_POSTGRES_CREDS_PATH"
 	EnvPostgresConnectionTemplate = "POSTGRES_CONN_TMPL"
 	EnvPostgresRootRole           = "POSTGRES_ROOT_ROLE"
 	EnvPostgresReadOnlyRole       = "POSTGRES_READ_ONLY_ROLE"
 	EnvFlowdockToken              = "FLOWDOCK_TOKEN"
+	EnvAuthJWTSecret              = "AUTH_JWT_SECRET"
+	EnvAuthSigningMethod          = "AUTH_SIGNING_METHOD"
+	EnvAuthDisabled               = "AUTH_DISABLED"
+	EnvTracingExporter            = "TRACING_EXPORTER"
+	EnvTracingAgentAddress        = "TRACING_AGENT_ADDRESS"
+
+	EnvLocal       = "LOCAL"
+	EnvPostgresDSN = "POSTGRES_DSN"
+
+	EnvPostgresReadConnectionTemplate = "POSTGRES_READ_CONN_TMPL"
+	EnvVaultPostgresReadCredsPath     = "VAULT_POSTGRES_READ_CREDS_PATH"
+	EnvPostgresReadDSN                = "POSTGRES_READ_DSN"
+	EnvPostgresReadMaxStaleness       = "POSTGRES_READ_MAX_STALENESS"
+
+	EnvNotificationAppRate          = "NOTIFICATION_APP_RATE"
+	EnvNotificationAppBurst         = "NOTIFICATION_APP_BURST"
+	EnvNotificationDestinationRate  = "NOTIFICATION_DESTINATION_RATE"
+	EnvNotificationDestinationBurst = "NOTIFICATION_DESTINATION_BURST"
+	EnvNotificationQuietHours       = "NOTIFICATION_QUIET_HOURS"
+	EnvNotificationTimezone         = "NOTIFICATION_TIMEZONE"
+	EnvNotificationAppTimezones     = "NOTIFICATION_APP_TIMEZONES"
+
+	EnvStorageCompressionThreshold  = "STORAGE_COMPRESSION_THRESHOLD"
+	EnvStorageRecompressionInterval = "STORAGE_RECOMPRESSION_INTERVAL"
+	EnvStoragePartitionInterval     = "STORAGE_PARTITION_INTERVAL"
+	EnvStoragePartitionRetention    = "STORAGE_PARTITION_RETENTION"
+
+	EnvStateStrictKeywords     = "STATE_STRICT_KEYWORDS"
+	EnvStateCompactionInterval = "STATE_COMPACTION_INTERVAL"
+
+	EnvFreshnessCheckInterval  = "FRESHNESS_CHECK_INTERVAL"
+	EnvFreshnessMaxAge         = "FRESHNESS_MAX_AGE"
+	EnvFreshnessKeywordMaxAges = "FRESHNESS_KEYWORD_MAX_AGES"
+)
+
+// Tracing exporters
+const (
+	TracingExporterStdout = "stdout"
+	TracingExporterAgent  = "agent"
+)
+
+// Defaults for optional environment variables
+const (
+	DefaultLocalEnv         = "local"
+	DefaultLocalServiceName = "ai_decision_service"
+	DefaultLocalGRPCPort    = 13050
+	DefaultLocalStatusPort  = 13051
+	DefaultLocalGatewayPort = 13052
+
+	DefaultGRPCHealthCheckInterval = 10
+
+	DefaultAuthSigningMethod = "HS256"
+
+	DefaultTracingAgentAddress = "localhost:55678"
+
+	DefaultPostgresReadMaxStaleness = 30
+
+	DefaultNotificationAppRate          = 0
+	DefaultNotificationAppBurst         = 5
+	DefaultNotificationDestinationRate  = 0
+	DefaultNotificationDestinationBurst = 20
+	DefaultNotificationTimezone         = "UTC"
+
+	DefaultStorageCompressionThreshold  = 1024
+	DefaultStorageRecompressionInterval = 0
+	DefaultStoragePartitionInterval     = 60
+	DefaultStoragePartitionRetention    = 0
+
+	DefaultStateCompactionInterval = 60
+
+	DefaultFreshnessCheckInterval = 10
+	DefaultFreshnessMaxAge        = 26 * 60
 )
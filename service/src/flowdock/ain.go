@@ -2,6 +2,7 @@ package flowdock
 
 import (
 	"bytes"
+	"context"
 	"html"
 	"time"
 )
@@ -52,7 +53,7 @@ func (c *Client) buildAiNotificationMessage(appID int32, messageType string, ren
 }
 
 // SendAiNotificationMessage sends an AI Notification to AID flowdock inbox
-func (c *Client) SendAiNotificationMessage(appID int32, messageType string, renderedMsg string) error {
+func (c *Client) SendAiNotificationMessage(ctx context.Context, appID int32, messageType string, renderedMsg string) error {
 	if c.FlowdockToken == "" {
 		return nil
 	}
@@ -60,5 +61,5 @@ func (c *Client) SendAiNotificationMessage(appID int32, messageType string, rend
 	if err != nil {
 		return err
 	}
-	return c.sendMessage(msg)
+	return c.sendMessage(ctx, msg)
 }
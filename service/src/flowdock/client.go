@@ -2,12 +2,15 @@ package flowdock
 
 import (
 	"bytes"
+	"context"
 	"errors"
 	"io/ioutil"
 	"net/http"
 	"strings"
 	"text/template"
 	"time"
+
+	"github.com/callstats-io/ai-decision/service/src/tracing"
 )
 
 const (
@@ -25,7 +28,8 @@ type Client struct {
 func NewClient(flowdockToken string) *Client {
 	timeout := time.Duration(5 * time.Second)
 	httpClient := http.Client{
-		Timeout: timeout,
+		Timeout:   timeout,
+		Transport: tracing.NewTransport(),
 	}
 	return &Client{
 		FlowdockToken: flowdockToken,
@@ -45,14 +49,14 @@ func buildMessage(mTemplate string, variables interface{}) (*bytes.Buffer, error
 	return message, err
 }
 
-func (c *Client) sendMessage(message *bytes.Buffer) error {
+func (c *Client) sendMessage(ctx context.Context, message *bytes.Buffer) error {
 	request, err := http.NewRequest("POST", flowdockMessagesURI, message)
 	if err != nil {
 		return err
 	}
 	request.Header.Set("Content-type", "application/json")
 
-	resp, err := c.HTTPClient.Do(request)
+	resp, err := c.HTTPClient.Do(request.WithContext(ctx))
 	if err != nil {
 		return err
 	}
@@ -56,7 +56,7 @@ func TestMessageSendSuccess(t *testing.T) {
 	cli := flowdock.NewClient(flow_token)
 	cli.HTTPClient = httpClient
 
-	err := cli.SendAiNotificationMessage(1, "type", "msg")
+	err := cli.SendAiNotificationMessage(context.Background(), 1, "type", "msg")
 	mustBeNil(err)
 }
 
@@ -73,7 +73,7 @@ func TestMessageSendFailed(t *testing.T) {
 	cli := flowdock.NewClient("secretflowtoken")
 	cli.HTTPClient = httpClient
 
-	err := cli.SendAiNotificationMessage(1, "type", "msg")
+	err := cli.SendAiNotificationMessage(context.Background(), 1, "type", "msg")
 	if err.Error() != failure_message {
 		panic("wrong error handling")
 	}
@@ -0,0 +1,196 @@
+package freshness_test
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/callstats-io/ai-decision/service/src/freshness"
+	"github.com/callstats-io/ai-decision/service/src/storage"
+	"github.com/stretchr/testify/require"
+)
+
+type fakeStorage struct {
+	freshness []*storage.StateFreshness
+	err       error
+	since     []*time.Time
+	leaseErr  error
+	holders   []string
+}
+
+func (s *fakeStorage) StateFreshness(ctx context.Context, since *time.Time) ([]*storage.StateFreshness, error) {
+	s.since = append(s.since, since)
+	return s.freshness, s.err
+}
+
+func (s *fakeStorage) AcquireLease(ctx context.Context, lease *storage.AidLease, ttl time.Duration) error {
+	s.holders = append(s.holders, lease.Holder)
+	return s.leaseErr
+}
+
+type sentNotification struct {
+	AppID       int32
+	MessageType string
+}
+
+type fakeNotifier struct {
+	sent []sentNotification
+	err  error
+}
+
+func (n *fakeNotifier) SendAiNotificationMessage(_ context.Context, appID int32, messageType string, renderedMsg string) error {
+	if n.err != nil {
+		return n.err
+	}
+	n.sent = append(n.sent, sentNotification{AppID: appID, MessageType: messageType})
+	return nil
+}
+
+func TestParseKeywordMaxAges(t *testing.T) {
+	assert := require.New(t)
+
+	maxAges, err := freshness.ParseKeywordMaxAges("")
+	assert.Nil(err)
+	assert.Empty(maxAges)
+
+	maxAges, err = freshness.ParseKeywordMaxAges("latest_dates=60, model=0")
+	assert.Nil(err)
+	assert.Equal(map[string]time.Duration{"latest_dates": time.Hour, "model": 0}, maxAges)
+
+	_, err = freshness.ParseKeywordMaxAges("latest_dates")
+	assert.NotNil(err)
+	_, err = freshness.ParseKeywordMaxAges("latest_dates=soon")
+	assert.NotNil(err)
+}
+
+func TestMonitorCheck(t *testing.T) {
+	assert := require.New(t)
+
+	now := time.Date(2018, 6, 1, 12, 0, 0, 0, time.UTC)
+	s := &fakeStorage{freshness: []*storage.StateFreshness{
+		{AppID: 1, Keyword: "latest_dates", LatestSavedAt: now.Add(-2 * time.Hour)},
+		{AppID: 1, Keyword: "model", LatestSavedAt: now.Add(-48 * time.Hour)},
+		{AppID: 2, Keyword: "latest_dates", LatestSavedAt: now.Add(-30 * time.Minute)},
+	}}
+	n := &fakeNotifier{}
+	m, err := freshness.NewMonitor(s, n, &freshness.Options{
+		MaxAge:         24 * time.Hour,
+		KeywordMaxAges: map[string]time.Duration{"latest_dates": time.Hour, "model": 0},
+	})
+	assert.Nil(err)
+	m.WithClock(func() time.Time { return now })
+
+	// no report before the first check
+	w := httptest.NewRecorder()
+	m.ServeHTTP(w, httptest.NewRequest("GET", "/freshness", nil))
+	assert.Equal(503, w.Code)
+
+	report, err := m.Check(context.Background())
+	assert.Nil(err)
+	assert.Equal(&freshness.Report{CheckedAt: now, Entries: []*freshness.Entry{
+		{AppID: 1, Keyword: "latest_dates", LatestSavedAt: now.Add(-2 * time.Hour), AgeSeconds: 7200, MaxAgeSeconds: 3600, Stale: true},
+		{AppID: 2, Keyword: "latest_dates", LatestSavedAt: now.Add(-30 * time.Minute), AgeSeconds: 1800, MaxAgeSeconds: 3600},
+	}}, report)
+	assert.Equal([]sentNotification{{AppID: 1, MessageType: freshness.StaleMessageType}}, n.sent)
+
+	w = httptest.NewRecorder()
+	m.ServeHTTP(w, httptest.NewRequest("GET", "/freshness", nil))
+	assert.Equal(200, w.Code)
+	served := &freshness.Report{}
+	assert.Nil(json.Unmarshal(w.Body.Bytes(), served))
+	assert.Len(served.Entries, 2)
+	assert.True(served.Entries[0].Stale)
+
+	// stale entries are alerted only once, later checks only scan the states saved within the largest max age
+	_, err = m.Check(context.Background())
+	assert.Nil(err)
+	assert.Len(n.sent, 1)
+	assert.Nil(s.since[0])
+	assert.Equal(now.Add(-24*time.Hour), *s.since[1])
+
+	// the latest saves of older states are kept from previous checks
+	s.freshness = s.freshness[:1]
+	report, err = m.Check(context.Background())
+	assert.Nil(err)
+	assert.Len(report.Entries, 2)
+
+	// recovered entries are alerted once
+	s.freshness[0].LatestSavedAt = now
+	_, err = m.Check(context.Background())
+	assert.Nil(err)
+	assert.Equal([]sentNotification{
+		{AppID: 1, MessageType: freshness.StaleMessageType},
+		{AppID: 1, MessageType: freshness.RecoveredMessageType},
+	}, n.sent)
+}
+
+func TestMonitorCheckFailedAlert(t *testing.T) {
+	assert := require.New(t)
+
+	now := time.Now()
+	s := &fakeStorage{freshness: []*storage.StateFreshness{
+		{AppID: 1, Keyword: "latest_dates", LatestSavedAt: now.Add(-2 * time.Hour)},
+	}}
+	n := &fakeNotifier{err: errors.New("EXPECTED NOTIFICATION TEST ERROR")}
+	m, err := freshness.NewMonitor(s, n, &freshness.Options{MaxAge: time.Hour})
+	assert.Nil(err)
+
+	_, err = m.Check(context.Background())
+	assert.Nil(err)
+	assert.Empty(n.sent)
+
+	// failed alerts are retried on the next check
+	n.err = nil
+	_, err = m.Check(context.Background())
+	assert.Nil(err)
+	assert.Equal([]sentNotification{{AppID: 1, MessageType: freshness.StaleMessageType}}, n.sent)
+}
+
+func TestMonitorCheckLease(t *testing.T) {
+	assert := require.New(t)
+
+	now := time.Now()
+	s := &fakeStorage{
+		freshness: []*storage.StateFreshness{{AppID: 1, Keyword: "latest_dates", LatestSavedAt: now.Add(-2 * time.Hour)}},
+		leaseErr:  storage.ErrLeaseHeld,
+	}
+	n := &fakeNotifier{}
+	m, err := freshness.NewMonitor(s, n, &freshness.Options{MaxAge: time.Hour, Holder: "instance-1"})
+	assert.Nil(err)
+
+	// instances without the lease report, but do not alert
+	report, err := m.Check(context.Background())
+	assert.Nil(err)
+	assert.True(report.Entries[0].Stale)
+	assert.Empty(n.sent)
+	assert.Equal([]string{"instance-1"}, s.holders)
+
+	// alerts already sent by the previous holder are not repeated once the lease is acquired
+	s.leaseErr = nil
+	_, err = m.Check(context.Background())
+	assert.Nil(err)
+	assert.Empty(n.sent)
+	s.freshness[0].LatestSavedAt = now
+	_, err = m.Check(context.Background())
+	assert.Nil(err)
+	assert.Equal([]sentNotification{{AppID: 1, MessageType: freshness.RecoveredMessageType}}, n.sent)
+}
+
+func TestMonitorCheckStorageError(t *testing.T) {
+	assert := require.New(t)
+
+	m, err := freshness.NewMonitor(&fakeStorage{err: errors.New("EXPECTED STORAGE TEST ERROR")}, &fakeNotifier{}, &freshness.Options{})
+	assert.Nil(err)
+	_, err = m.Check(context.Background())
+	assert.EqualError(err, "EXPECTED STORAGE TEST ERROR")
+
+	// no states is not an error
+	m, err = freshness.NewMonitor(&fakeStorage{err: storage.ErrNotFound}, &fakeNotifier{}, &freshness.Options{})
+	assert.Nil(err)
+	report, err := m.Check(context.Background())
+	assert.Nil(err)
+	assert.Empty(report.Entries)
+}
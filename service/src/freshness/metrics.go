@@ -0,0 +1,55 @@
+package freshness
+
+import (
+	"github.com/prometheus/client_golang/prometheus"
+)
+
+// metric labels
+const (
+	LabelAppID   = "app_id"
+	LabelKeyword = "keyword"
+)
+
+var (
+	ageGauge   *prometheus.GaugeVec
+	staleGauge *prometheus.GaugeVec
+)
+
+// registerMetrics initializes the freshness metrics and registers them to Prometheus.
+// Already registered metrics are reused to allow multiple monitors, e.g. in tests.
+func registerMetrics() error {
+	ageGauge = prometheus.NewGaugeVec(
+		prometheus.GaugeOpts{
+			Namespace: "aid",
+			Subsystem: "freshness",
+			Name:      "age_seconds",
+			Help:      "Seconds since a state was last saved by app and keyword.",
+		},
+		[]string{LabelAppID, LabelKeyword},
+	)
+	if err := prometheus.Register(ageGauge); err != nil {
+		are, ok := err.(prometheus.AlreadyRegisteredError)
+		if !ok {
+			return err
+		}
+		ageGauge = are.ExistingCollector.(*prometheus.GaugeVec)
+	}
+
+	staleGauge = prometheus.NewGaugeVec(
+		prometheus.GaugeOpts{
+			Namespace: "aid",
+			Subsystem: "freshness",
+			Name:      "stale",
+			Help:      "1 if no state was saved within the max age of the keyword by app and keyword, 0 otherwise.",
+		},
+		[]string{LabelAppID, LabelKeyword},
+	)
+	if err := prometheus.Register(staleGauge); err != nil {
+		are, ok := err.(prometheus.AlreadyRegisteredError)
+		if !ok {
+			return err
+		}
+		staleGauge = are.ExistingCollector.(*prometheus.GaugeVec)
+	}
+	return nil
+}
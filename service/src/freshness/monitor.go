@@ -0,0 +1,313 @@
+package freshness
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"os"
+	"sort"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/callstats-io/ai-decision/service/src/notification"
+	"github.com/callstats-io/ai-decision/service/src/storage"
+	"github.com/callstats-io/go-common/log"
+	"github.com/callstats-io/go-common/response"
+)
+
+// notification message types of freshness alerts
+const (
+	StaleMessageType     = "StalePipeline"
+	RecoveredMessageType = "RecoveredPipeline"
+)
+
+// default options
+const (
+	DefaultInterval = 10 * time.Minute
+)
+
+// LeaseName is the name of the lease of the instance sending the alerts, all apps share the lease of app 0
+const LeaseName = "freshness-alerts"
+
+// Storage defines the interface the monitor expects from applicable storages
+type Storage interface {
+	StateFreshness(ctx context.Context, since *time.Time) ([]*storage.StateFreshness, error)
+	AcquireLease(ctx context.Context, lease *storage.AidLease, ttl time.Duration) error
+}
+
+// Options contains the max ages after which states are considered stale.
+// KeywordMaxAges overrides MaxAge by keyword, a non-positive max age disables the check of a keyword.
+// Holder identifies the instance in the lease of the alerts, the host name and process id by default.
+type Options struct {
+	MaxAge         time.Duration
+	KeywordMaxAges map[string]time.Duration
+	Interval       time.Duration
+	Holder         string
+}
+
+// Entry defines the freshness of the states of an app and keyword
+type Entry struct {
+	AppID         int32     `json:"appID"`
+	Keyword       string    `json:"keyword"`
+	LatestSavedAt time.Time `json:"latestSavedAt"`
+	AgeSeconds    int64     `json:"ageSeconds"`
+	MaxAgeSeconds int64     `json:"maxAgeSeconds"`
+	Stale         bool      `json:"stale"`
+}
+
+// Report contains the result of a freshness check
+type Report struct {
+	CheckedAt time.Time `json:"checkedAt"`
+	Entries   []*Entry  `json:"entries"`
+}
+
+// entryKey identifies an entry across checks
+type entryKey struct {
+	appID   int32
+	keyword string
+}
+
+// Monitor periodically checks when states were last saved, flags apps and keywords without a save within
+// their max age and alerts through the notifier once an app and keyword becomes stale or recovers.
+// Every instance checks and reports the freshness, alerts are only sent by the instance holding the lease.
+// The first check scans all states, later checks only the states saved within the largest max age: the latest
+// saves of older apps and keywords are kept from previous checks, until the service restarts.
+type Monitor struct {
+	storage  Storage
+	notifier notification.Notifier
+	opts     *Options
+	now      func() time.Time
+
+	lock   sync.Mutex
+	report *Report
+	latest map[entryKey]time.Time
+	stale  map[entryKey]bool
+}
+
+var _ = http.Handler(&Monitor{})
+
+// NewMonitor returns a new Monitor checking the states of the storage
+func NewMonitor(storage Storage, notifier notification.Notifier, opts *Options) (*Monitor, error) {
+	if err := registerMetrics(); err != nil {
+		return nil, err
+	}
+	if opts.Interval <= 0 {
+		opts.Interval = DefaultInterval
+	}
+	if opts.Holder == "" {
+		hostname, _ := os.Hostname()
+		opts.Holder = fmt.Sprintf("%s-%d", hostname, os.Getpid())
+	}
+	return &Monitor{
+		storage:  storage,
+		notifier: notifier,
+		opts:     opts,
+		now:      time.Now,
+		stale:    map[entryKey]bool{},
+	}, nil
+}
+
+// WithClock sets the time source of this monitor. Mainly used in tests.
+func (m *Monitor) WithClock(now func() time.Time) *Monitor {
+	m.now = now
+	return m
+}
+
+// ParseKeywordMaxAges parses a comma separated list of max ages in minutes by keyword, e.g. "latest_dates=60,model=0"
+func ParseKeywordMaxAges(s string) (map[string]time.Duration, error) {
+	maxAges := map[string]time.Duration{}
+	if s == "" {
+		return maxAges, nil
+	}
+	for _, part := range strings.Split(s, ",") {
+		kv := strings.SplitN(strings.TrimSpace(part), "=", 2)
+		if len(kv) != 2 || kv[0] == "" {
+			return nil, fmt.Errorf("invalid keyword max age %q, expected keyword=minutes", part)
+		}
+		minutes, err := strconv.Atoi(kv[1])
+		if err != nil {
+			return nil, fmt.Errorf("invalid minutes in keyword max age %q", part)
+		}
+		maxAges[kv[0]] = time.Duration(minutes) * time.Minute
+	}
+	return maxAges, nil
+}
+
+// KeywordMaxAge returns the max age of the keyword, a non-positive max age means the keyword is not checked
+func (o *Options) KeywordMaxAge(keyword string) time.Duration {
+	if maxAge, ok := o.KeywordMaxAges[keyword]; ok {
+		return maxAge
+	}
+	return o.MaxAge
+}
+
+// maxMaxAge returns the largest max age of all keywords
+func (o *Options) maxMaxAge() time.Duration {
+	maxAge := o.MaxAge
+	for _, a := range o.KeywordMaxAges {
+		if a > maxAge {
+			maxAge = a
+		}
+	}
+	return maxAge
+}
+
+// Run checks the freshness every interval until the context is done
+func (m *Monitor) Run(ctx context.Context) {
+	ticker := time.NewTicker(m.opts.Interval)
+	defer ticker.Stop()
+	for {
+		if _, err := m.Check(ctx); err != nil {
+			log.FromContext(ctx).Warn("failed to check state freshness", log.Error(err))
+		}
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+		}
+	}
+}
+
+// Check updates the freshness gauges and report and alerts on apps and keywords that became stale or recovered
+func (m *Monitor) Check(ctx context.Context) (*Report, error) {
+	now := m.now()
+	m.lock.Lock()
+	var since *time.Time
+	if m.latest != nil {
+		t := now.Add(-m.opts.maxMaxAge())
+		since = &t
+	}
+	m.lock.Unlock()
+
+	freshness, err := m.storage.StateFreshness(ctx, since)
+	if err == storage.ErrNotFound {
+		freshness = nil
+	} else if err != nil {
+		return nil, err
+	}
+	alerting := m.holdsLease(ctx)
+
+	m.lock.Lock()
+	defer m.lock.Unlock()
+
+	if m.latest == nil {
+		m.latest = map[entryKey]time.Time{}
+	}
+	for _, f := range freshness {
+		m.latest[entryKey{appID: f.AppID, keyword: f.Keyword}] = f.LatestSavedAt
+	}
+	keys := make([]entryKey, 0, len(m.latest))
+	for key := range m.latest {
+		keys = append(keys, key)
+	}
+	sort.Slice(keys, func(i, j int) bool {
+		if keys[i].appID != keys[j].appID {
+			return keys[i].appID < keys[j].appID
+		}
+		return keys[i].keyword < keys[j].keyword
+	})
+
+	// reset to drop the gauges of unchecked keywords
+	ageGauge.Reset()
+	staleGauge.Reset()
+
+	report := &Report{CheckedAt: now, Entries: []*Entry{}}
+	checked := map[entryKey]bool{}
+	for _, key := range keys {
+		f := &storage.StateFreshness{AppID: key.appID, Keyword: key.keyword, LatestSavedAt: m.latest[key]}
+		maxAge := m.opts.KeywordMaxAge(f.Keyword)
+		if maxAge <= 0 {
+			continue
+		}
+		age := now.Sub(f.LatestSavedAt)
+		entry := &Entry{
+			AppID:         f.AppID,
+			Keyword:       f.Keyword,
+			LatestSavedAt: f.LatestSavedAt,
+			AgeSeconds:    int64(age / time.Second),
+			MaxAgeSeconds: int64(maxAge / time.Second),
+			Stale:         age > maxAge,
+		}
+		report.Entries = append(report.Entries, entry)
+
+		appID := strconv.Itoa(int(f.AppID))
+		ageGauge.WithLabelValues(appID, f.Keyword).Set(age.Seconds())
+		if entry.Stale {
+			staleGauge.WithLabelValues(appID, f.Keyword).Set(1)
+		} else {
+			staleGauge.WithLabelValues(appID, f.Keyword).Set(0)
+		}
+		m.alert(ctx, entry, maxAge, alerting)
+		checked[entryKey{appID: f.AppID, keyword: f.Keyword}] = true
+	}
+	for key := range m.stale {
+		if !checked[key] {
+			delete(m.stale, key)
+		}
+	}
+	m.report = report
+	return report, nil
+}
+
+// holdsLease acquires or renews the lease of the alerts, it expires after two intervals without a check
+func (m *Monitor) holdsLease(ctx context.Context) bool {
+	lease := &storage.AidLease{Name: LeaseName, Holder: m.opts.Holder}
+	err := m.storage.AcquireLease(ctx, lease, 2*m.opts.Interval)
+	if err != nil && err != storage.ErrLeaseHeld {
+		log.FromContext(ctx).Warn("failed to acquire the freshness alert lease", log.Error(err))
+	}
+	return err == nil
+}
+
+// alert notifies once an entry changes between stale and fresh, failed alerts are retried on the next check.
+// Instances not holding the lease only track the changes, so that they do not repeat alerts once they acquire it.
+func (m *Monitor) alert(ctx context.Context, entry *Entry, maxAge time.Duration, alerting bool) {
+	key := entryKey{appID: entry.AppID, keyword: entry.Keyword}
+	if m.stale[key] == entry.Stale {
+		return
+	}
+	if !alerting {
+		m.markStale(key, entry.Stale)
+		return
+	}
+
+	messageType := StaleMessageType
+	msg := fmt.Sprintf("No state saved for keyword %q since %s, max age is %s.",
+		entry.Keyword, entry.LatestSavedAt.UTC().Format(time.RFC3339), maxAge)
+	if !entry.Stale {
+		messageType = RecoveredMessageType
+		msg = fmt.Sprintf("States are saved again for keyword %q, latest at %s.",
+			entry.Keyword, entry.LatestSavedAt.UTC().Format(time.RFC3339))
+	}
+	if err := m.notifier.SendAiNotificationMessage(ctx, entry.AppID, messageType, msg); err != nil {
+		log.FromContext(ctx).Warn("failed to send freshness alert",
+			log.Int(LabelAppID, int(entry.AppID)), log.String(LabelKeyword, entry.Keyword), log.Error(err))
+		return
+	}
+	m.markStale(key, entry.Stale)
+}
+
+func (m *Monitor) markStale(key entryKey, stale bool) {
+	if stale {
+		m.stale[key] = true
+	} else {
+		delete(m.stale, key)
+	}
+}
+
+// ServeHTTP responds with the report of the last check, or service unavailable if no check has finished yet
+func (m *Monitor) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	m.lock.Lock()
+	report := m.report
+	m.lock.Unlock()
+
+	if report == nil {
+		response.RequiredServiceUnavailable(w, nil)
+		return
+	}
+	payload, _ := json.Marshal(report)
+	response.OK(w, payload)
+}
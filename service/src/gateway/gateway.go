@@ -0,0 +1,212 @@
+package gateway
+
+import (
+	"context"
+	"net"
+	"net/http"
+	"strings"
+	"time"
+
+	"github.com/callstats-io/ai-decision/service/gen/protos"
+	sgrpc "github.com/callstats-io/ai-decision/service/src/grpc"
+	"github.com/callstats-io/ai-decision/service/src/tracing"
+	"github.com/golang/protobuf/proto"
+	"github.com/golang/protobuf/ptypes"
+	"github.com/grpc-ecosystem/grpc-gateway/runtime"
+	"google.golang.org/genproto/protobuf/field_mask"
+	"google.golang.org/grpc"
+)
+
+// Server serves the message, state and audit services as JSON over HTTP by calling the gRPC server,
+// requests are validated and errors are mapped by the services as for gRPC clients.
+// Unary RPCs are routed by the HTTP rules of protos/ai_decision_service_gateway.yaml,
+// server streaming List RPCs are served as pages, see listHandler.
+type Server struct {
+	conn       *grpc.ClientConn
+	httpServer *http.Server
+}
+
+// NewServer builds a new Server calling the gRPC server at endpoint, e.g. localhost:13050
+func NewServer(ctx context.Context, endpoint string) (*Server, error) {
+	conn, err := grpc.DialContext(ctx, endpoint, grpc.WithInsecure())
+	if err != nil {
+		return nil, err
+	}
+	handler, err := newHandler(ctx, conn)
+	if err != nil {
+		conn.Close()
+		return nil, err
+	}
+	return &Server{
+		conn: conn,
+		httpServer: &http.Server{
+			Handler:        handler,
+			ReadTimeout:    5 * time.Second,
+			WriteTimeout:   30 * time.Second,
+			IdleTimeout:    60 * time.Second,
+			MaxHeaderBytes: 16384,
+		},
+	}, nil
+}
+
+// newHandler returns the handler of all gateway routes calling the gRPC services over conn
+func newHandler(ctx context.Context, conn *grpc.ClientConn) (http.Handler, error) {
+	// field names as in the proto files and zero values included, as in the Python clients
+	mux := runtime.NewServeMux(
+		runtime.WithMarshalerOption(runtime.MIMEWildcard, &runtime.JSONPb{OrigName: true, EmitDefaults: true}),
+		runtime.WithIncomingHeaderMatcher(headerMatcher),
+	)
+	if err := protos.RegisterAIDecisionMessageServiceHandler(ctx, mux, conn); err != nil {
+		return nil, err
+	}
+	if err := protos.RegisterAIDecisionStateServiceHandler(ctx, mux, conn); err != nil {
+		return nil, err
+	}
+
+	messages := protos.NewAIDecisionMessageServiceClient(conn)
+	states := protos.NewAIDecisionStateServiceClient(conn)
+	audit := protos.NewAIDecisionAuditServiceClient(conn)
+	mux.Handle("GET", pattern("/v1/apps/{app_id}/messages"), listHandler(mux, &list{
+		field: "messages",
+		key: func(msg proto.Message) *pageKey {
+			m := msg.(*protos.Message)
+			return &pageKey{Time: timestampKey(m.GenerationTime), Name: m.Type, Number: int64(m.Version)}
+		},
+		open: func(ctx context.Context, r *http.Request, pathParams map[string]string, last *pageKey) (recvFunc, error) {
+			req := &protos.MessageListRequest{}
+			if err := populate(req, r, pathParams); err != nil {
+				return nil, err
+			}
+			req.ReadMask = withPaths(req.ReadMask, "generation_time", "type", "version")
+			if last != nil {
+				req.GenerationTimeFrom, _ = ptypes.TimestampProto(last.Time)
+			}
+			stream, err := messages.List(ctx, req)
+			if err != nil {
+				return nil, err
+			}
+			return func() (proto.Message, error) { return stream.Recv() }, nil
+		},
+	}))
+	mux.Handle("GET", pattern("/v1/apps/{app_id}/states"), listHandler(mux, &list{
+		field: "states",
+		key: func(msg proto.Message) *pageKey {
+			st := msg.(*protos.State)
+			return &pageKey{Time: timestampKey(st.GenerationTime), Name: st.Keyword}
+		},
+		open: func(ctx context.Context, r *http.Request, pathParams map[string]string, last *pageKey) (recvFunc, error) {
+			req := &protos.StateListRequest{}
+			if err := populate(req, r, pathParams); err != nil {
+				return nil, err
+			}
+			req.ReadMask = withPaths(req.ReadMask, "generation_time", "keyword")
+			if last != nil {
+				req.GenerationTimeFrom, _ = ptypes.TimestampProto(last.Time)
+			}
+			stream, err := states.List(ctx, req)
+			if err != nil {
+				return nil, err
+			}
+			return func() (proto.Message, error) { return stream.Recv() }, nil
+		},
+	}))
+	// keywords and compaction policies cannot be bounded by the last key, but are few
+	mux.Handle("GET", pattern("/v1/apps/{app_id}/keywords"), listHandler(mux, &list{
+		field: "keywords",
+		key: func(msg proto.Message) *pageKey {
+			return &pageKey{Name: msg.(*protos.Keyword).Keyword}
+		},
+		open: func(ctx context.Context, r *http.Request, pathParams map[string]string, last *pageKey) (recvFunc, error) {
+			req := &protos.KeywordListRequest{}
+			if err := populate(req, r, pathParams); err != nil {
+				return nil, err
+			}
+			stream, err := states.ListKeywords(ctx, req)
+			if err != nil {
+				return nil, err
+			}
+			return func() (proto.Message, error) { return stream.Recv() }, nil
+		},
+	}))
+	mux.Handle("GET", pattern("/v1/compaction-policies"), listHandler(mux, &list{
+		field: "compaction_policies",
+		key: func(msg proto.Message) *pageKey {
+			return &pageKey{Name: msg.(*protos.CompactionPolicy).Keyword}
+		},
+		open: func(ctx context.Context, r *http.Request, pathParams map[string]string, last *pageKey) (recvFunc, error) {
+			req := &protos.CompactionPolicyListRequest{}
+			if err := populate(req, r, pathParams); err != nil {
+				return nil, err
+			}
+			stream, err := states.ListCompactionPolicies(ctx, req)
+			if err != nil {
+				return nil, err
+			}
+			return func() (proto.Message, error) { return stream.Recv() }, nil
+		},
+	}))
+	// audit events are listed newest first
+	mux.Handle("GET", pattern("/v1/audit-events"), listHandler(mux, &list{
+		field: "audit_events",
+		key: func(msg proto.Message) *pageKey {
+			e := msg.(*protos.AuditEvent)
+			return &pageKey{Time: timestampKey(e.OccurredAt), Number: e.Id}
+		},
+		descending: true,
+		open: func(ctx context.Context, r *http.Request, pathParams map[string]string, last *pageKey) (recvFunc, error) {
+			req := &protos.AuditEventListRequest{}
+			if err := populate(req, r, pathParams); err != nil {
+				return nil, err
+			}
+			if last != nil {
+				req.OccurredTo, _ = ptypes.TimestampProto(last.Time)
+			}
+			stream, err := audit.List(ctx, req)
+			if err != nil {
+				return nil, err
+			}
+			return func() (proto.Message, error) { return stream.Recv() }, nil
+		},
+	}))
+	return mux, nil
+}
+
+// withPaths returns a read mask with the paths added, e.g. the fields of the page keys of a list.
+// An empty mask reads all fields and is returned as is.
+func withPaths(mask *field_mask.FieldMask, paths ...string) *field_mask.FieldMask {
+	if mask == nil || len(mask.Paths) == 0 {
+		return mask
+	}
+	for _, path := range paths {
+		found := false
+		for _, p := range mask.Paths {
+			found = found || p == path
+		}
+		if !found {
+			mask.Paths = append(mask.Paths, path)
+		}
+	}
+	return mask
+}
+
+// headerMatcher forwards the trace context and the audit reason of requests to the gRPC server in addition to the
+// default headers
+func headerMatcher(key string) (string, bool) {
+	if strings.EqualFold(key, tracing.TraceparentHeader) {
+		return tracing.TraceparentHeader, true
+	}
+	if strings.EqualFold(key, sgrpc.AuditReasonKey) {
+		return sgrpc.AuditReasonKey, true
+	}
+	return runtime.DefaultHeaderMatcher(key)
+}
+
+// Serve starts serving HTTP requests
+func (s *Server) Serve(ctx context.Context, listener net.Listener) error {
+	go func() {
+		<-ctx.Done()
+		s.httpServer.Close()
+		s.conn.Close()
+	}()
+	return s.httpServer.Serve(listener)
+}
@@ -0,0 +1,208 @@
+package gateway_test
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"io/ioutil"
+	"net"
+	"net/http"
+	"os"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/callstats-io/ai-decision/service/src/flowdock"
+	"github.com/callstats-io/ai-decision/service/src/gateway"
+	sgrpc "github.com/callstats-io/ai-decision/service/src/grpc"
+	"github.com/callstats-io/ai-decision/service/src/service"
+	"github.com/callstats-io/ai-decision/service/src/storage"
+	"github.com/callstats-io/ai-decision/service/src/storage/mocks"
+	"github.com/stretchr/testify/require"
+)
+
+var (
+	testCtx, testCtxCancel = context.WithCancel(context.Background())
+	testGatewayURL         string
+	mockStorage            *mocks.Storage
+)
+
+func mustBeNil(err error) {
+	if err != nil {
+		panic(err)
+	}
+}
+
+func suiteSetup() {
+	mockStorage = mocks.NewMockedStorage()
+	messageService, err := service.NewAIDecisionMessageService(mockStorage, flowdock.NewClient(""))
+	mustBeNil(err)
+	stateService, err := service.NewAIDecisionStateService(mockStorage)
+	mustBeNil(err)
+	leaseService, err := service.NewAIDecisionLeaseService(mockStorage)
+	mustBeNil(err)
+	runService, err := service.NewAIDecisionRunService(mockStorage)
+	mustBeNil(err)
+	auditService, err := service.NewAIDecisionAuditService(mockStorage)
+	mustBeNil(err)
+	grpcServer, err := sgrpc.NewServer(testCtx, nil, messageService, stateService, leaseService, runService, auditService)
+	mustBeNil(err)
+	grpcListener, err := net.Listen("tcp", "localhost:0")
+	mustBeNil(err)
+	go grpcServer.Serve(testCtx, grpcListener)
+
+	gatewayServer, err := gateway.NewServer(testCtx, grpcListener.Addr().String())
+	mustBeNil(err)
+	gatewayListener, err := net.Listen("tcp", "localhost:0")
+	mustBeNil(err)
+	go gatewayServer.Serve(testCtx, gatewayListener)
+	testGatewayURL = "http://" + gatewayListener.Addr().String()
+}
+
+func TestMain(m *testing.M) {
+	os.Exit(func() int {
+		suiteSetup()
+		defer testCtxCancel()
+		return m.Run()
+	}())
+}
+
+// request sends an HTTP request to the gateway and returns the status code and the decoded JSON response
+func request(t *testing.T, method, path, body string) (int, map[string]interface{}) {
+	assert := require.New(t)
+	req, err := http.NewRequest(method, testGatewayURL+path, strings.NewReader(body))
+	assert.Nil(err)
+	res, err := http.DefaultClient.Do(req)
+	assert.Nil(err)
+	defer res.Body.Close()
+	b, err := ioutil.ReadAll(res.Body)
+	assert.Nil(err)
+	var decoded map[string]interface{}
+	assert.Nil(json.Unmarshal(b, &decoded), string(b))
+	return res.StatusCode, decoded
+}
+
+func TestGatewayUnary(t *testing.T) {
+	defer mockStorage.Reset()
+	assert := require.New(t)
+
+	// validated by the service
+	code, res := request(t, "POST", "/v1/messages", `{"app_id": 0, "type": "growth"}`)
+	assert.Equal(http.StatusBadRequest, code)
+	assert.Contains(res["error"], "app_id")
+
+	mockStorage.MockGetLatestStateError(storage.ErrNotFound)
+	code, _ = request(t, "GET", "/v1/apps/1/states/forecast/latest", "")
+	assert.Equal(http.StatusNotFound, code)
+
+	mockStorage.Reset()
+	mockStorage.MockSavedStates([]*storage.AidAnalyticsState{
+		{AppID: 1, Keyword: "forecast", Data: []byte(`{}`), SavedAt: time.Now(), Revision: 3},
+	})
+	code, res = request(t, "GET", "/v1/apps/1/states/forecast/latest", "")
+	assert.Equal(http.StatusOK, code)
+	assert.Equal("forecast", res["keyword"])
+	assert.Equal(float64(3), res["revision"])
+	assert.Equal(float64(0), res["run_id"]) // zero values are included
+}
+
+func TestGatewayListPages(t *testing.T) {
+	defer mockStorage.Reset()
+	assert := require.New(t)
+
+	var states []*storage.AidAnalyticsState
+	for i := 0; i < 5; i++ {
+		states = append(states, &storage.AidAnalyticsState{AppID: 1, Keyword: "forecast", Data: []byte(`{}`), SavedAt: time.Now(), Revision: int32(i + 1)})
+	}
+	mockStorage.MockSavedStates(states)
+
+	var revisions []float64
+	token := ""
+	for pages := 0; pages < 3; pages++ {
+		code, res := request(t, "GET", fmt.Sprintf("/v1/apps/1/states?keyword=forecast&page_size=2&page_token=%s", token), "")
+		assert.Equal(http.StatusOK, code)
+		for _, state := range res["states"].([]interface{}) {
+			revisions = append(revisions, state.(map[string]interface{})["revision"].(float64))
+		}
+		token = res["next_page_token"].(string)
+		if pages < 2 {
+			assert.NotEmpty(token)
+		}
+		if pages == 0 {
+			// pages do not shift when states are added before the token
+			mockStorage.MockSavedStates(append([]*storage.AidAnalyticsState{
+				{AppID: 1, Keyword: "forecast", Data: []byte(`{}`), SavedAt: states[0].SavedAt.Add(-time.Second), Revision: 9},
+			}, states...))
+		}
+	}
+	assert.Empty(token)
+	assert.Equal([]float64{1, 2, 3, 4, 5}, revisions)
+	// the stream of the last page starts at the last state of the previous page
+	assert.True(states[3].SavedAt.Equal(*mockStorage.ListedFrom()))
+
+	// the read mask is a query parameter of its paths
+	code, res := request(t, "GET", "/v1/apps/1/states?keyword=forecast&read_mask.paths=revision&read_mask.paths=generation_time", "")
+	assert.Equal(http.StatusOK, code)
+	for _, state := range res["states"].([]interface{}) {
+		assert.Empty(state.(map[string]interface{})["data"])
+		assert.NotEmpty(state.(map[string]interface{})["generation_time"])
+	}
+	assert.Equal(1, mockStorage.ListStatesWithoutDataCalls())
+
+	code, _ = request(t, "GET", "/v1/apps/1/states?keyword=forecast&page_size=0", "")
+	assert.Equal(http.StatusBadRequest, code)
+	code, _ = request(t, "GET", "/v1/apps/1/states?keyword=forecast&page_token=x", "")
+	assert.Equal(http.StatusBadRequest, code)
+
+	// errors of the stream are mapped as for unary RPCs
+	mockStorage.MockListStatesError(storage.ErrNotFound)
+	code, _ = request(t, "GET", "/v1/apps/1/states?keyword=forecast", "")
+	assert.Equal(http.StatusNotFound, code)
+}
+
+func TestGatewayAudit(t *testing.T) {
+	defer mockStorage.Reset()
+	assert := require.New(t)
+
+	// the reason header is forwarded as the audit reason of the call
+	mockStorage.MockDeletedStates(1)
+	req, err := http.NewRequest("DELETE", testGatewayURL+"/v1/apps/1/states/forecast?generation_time=2018-09-01T00:00:00Z", nil)
+	assert.Nil(err)
+	req.Header.Set("X-Audit-Reason", "ticket 42")
+	res, err := http.DefaultClient.Do(req)
+	assert.Nil(err)
+	res.Body.Close()
+	assert.Equal(http.StatusOK, res.StatusCode)
+	assert.Equal("ticket 42", mockStorage.LastAudit().Reason)
+	assert.Equal("/callstats.ai_decision.AIDecisionStateService/Delete", mockStorage.LastAudit().RPC)
+
+	mockStorage.MockAuditEvents([]*storage.AuditEvent{
+		{ID: 1, OccurredAt: time.Now(), AppID: 1, Action: storage.AuditStateDelete, Target: "forecast@2018-09-01T00:00:00Z", Reason: "ticket 42"},
+	})
+	code, body := request(t, "GET", "/v1/audit-events?app_id=1&action=state.delete", "")
+	assert.Equal(http.StatusOK, code)
+	events := body["audit_events"].([]interface{})
+	assert.Len(events, 1)
+	assert.Equal("ticket 42", events[0].(map[string]interface{})["reason"])
+	assert.Equal(storage.AuditStateDelete, mockStorage.ListedAuditFilter().Action)
+
+	// audit events are paged newest first, events of the same time by id
+	now := time.Now()
+	mockStorage.MockAuditEvents([]*storage.AuditEvent{
+		{ID: 3, OccurredAt: now, Action: storage.AuditStateDelete},
+		{ID: 2, OccurredAt: now, Action: storage.AuditStateDelete},
+		{ID: 1, OccurredAt: now.Add(-time.Second), Action: storage.AuditStateDelete},
+	})
+	code, body = request(t, "GET", "/v1/audit-events?page_size=1", "")
+	assert.Equal(http.StatusOK, code)
+	// int64 fields are strings in JSON
+	assert.Equal("3", body["audit_events"].([]interface{})[0].(map[string]interface{})["id"])
+	code, body = request(t, "GET", "/v1/audit-events?page_size=2&page_token="+body["next_page_token"].(string), "")
+	assert.Equal(http.StatusOK, code)
+	events = body["audit_events"].([]interface{})
+	assert.Len(events, 2)
+	assert.Equal("2", events[0].(map[string]interface{})["id"])
+	assert.Equal("1", events[1].(map[string]interface{})["id"])
+	assert.Empty(body["next_page_token"])
+	assert.True(now.Equal(*mockStorage.ListedAuditFilter().To))
+}
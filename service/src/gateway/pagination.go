@@ -0,0 +1,220 @@
+package gateway
+
+import (
+	"context"
+	"encoding/base64"
+	"encoding/json"
+	"io"
+	"net/http"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/golang/protobuf/proto"
+	"github.com/golang/protobuf/ptypes"
+	"github.com/golang/protobuf/ptypes/timestamp"
+	"github.com/grpc-ecosystem/grpc-gateway/runtime"
+	"github.com/grpc-ecosystem/grpc-gateway/utilities"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+)
+
+// Pagination query parameters and limits
+const (
+	pageSizeParam   = "page_size"
+	pageTokenParam  = "page_token"
+	DefaultPageSize = 100
+	MaxPageSize     = 1000
+)
+
+// recvFunc receives the next message of a server stream, io.EOF at the end of the stream
+type recvFunc func() (proto.Message, error)
+
+// openFunc opens the server stream of a List RPC for an HTTP request and its path parameters.
+// The stream may start at the key of the last message of the previous page, nil for the first page.
+type openFunc func(ctx context.Context, r *http.Request, pathParams map[string]string, last *pageKey) (recvFunc, error)
+
+// page is the JSON response of a List RPC. The messages are set under the field of the list, e.g.
+// {"messages": [...], "next_page_token": "..."}. The next page token is empty on the last page.
+type page map[string]interface{}
+
+// pageKey is the position of a message in the order of its list, e.g. the generation time, type and version of
+// messages. The key of the last message of a page is the token of the next page.
+type pageKey struct {
+	Time   time.Time `json:"t"`
+	Name   string    `json:"n,omitempty"`
+	Number int64     `json:"i,omitempty"`
+}
+
+// before returns true if the key is before the other key in ascending order, names in byte order
+func (k *pageKey) before(other *pageKey) bool {
+	switch {
+	case !k.Time.Equal(other.Time):
+		return k.Time.Before(other.Time)
+	case k.Name != other.Name:
+		return k.Name < other.Name
+	default:
+		return k.Number < other.Number
+	}
+}
+
+// list is a server streaming List RPC served as pages
+type list struct {
+	// field is the field of the messages of a page, e.g. messages
+	field string
+	// key returns the page key of a message
+	key func(msg proto.Message) *pageKey
+	// descending is set if the messages are streamed in descending order of their keys
+	descending bool
+	// open opens the stream, starting at the last key if the RPC can be bounded by it
+	open openFunc
+}
+
+// listHandler serves a server streaming List RPC as pages of page_size messages, DefaultPageSize if not set.
+// The page_token of the next page, the key of the last message of the page, is returned with each page but the last.
+// The stream of the next page starts at the key of its token and messages up to the key are skipped, so pages do not
+// shift when messages are added or deleted. All other query parameters are fields of the List request. The stream is
+// canceled once the page is full.
+func listHandler(mux *runtime.ServeMux, l *list) runtime.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request, pathParams map[string]string) {
+		ctx, cancel := context.WithCancel(r.Context())
+		defer cancel()
+		_, outbound := runtime.MarshalerForRequest(mux, r)
+		ctx, err := runtime.AnnotateContext(ctx, mux, r)
+		if err != nil {
+			runtime.HTTPError(ctx, mux, outbound, w, r, err)
+			return
+		}
+
+		size, start, err := pageParams(r)
+		if err != nil {
+			runtime.HTTPError(ctx, mux, outbound, w, r, err)
+			return
+		}
+		recv, err := l.open(ctx, r, pathParams, start)
+		if err != nil {
+			runtime.HTTPError(ctx, mux, outbound, w, r, err)
+			return
+		}
+
+		items := []json.RawMessage{}
+		var last *pageKey
+		next := ""
+		for {
+			msg, err := recv()
+			if err == io.EOF || (start != nil && status.Code(err) == codes.NotFound) {
+				// the messages of the next page may have been deleted since the previous page
+				break
+			}
+			if err != nil {
+				runtime.HTTPError(ctx, mux, outbound, w, r, err)
+				return
+			}
+			key := l.key(msg)
+			if start != nil && !l.after(key, start) {
+				continue
+			}
+			if len(items) == size {
+				if next, err = encodePageToken(last); err != nil {
+					runtime.HTTPError(ctx, mux, outbound, w, r, err)
+					return
+				}
+				break
+			}
+			b, err := outbound.Marshal(msg)
+			if err != nil {
+				runtime.HTTPError(ctx, mux, outbound, w, r, err)
+				return
+			}
+			items = append(items, b)
+			last = key
+		}
+
+		b, err := json.Marshal(page{l.field: items, "next_page_token": next})
+		if err != nil {
+			runtime.HTTPError(ctx, mux, outbound, w, r, err)
+			return
+		}
+		w.Header().Set("Content-Type", outbound.ContentType())
+		w.Write(b)
+	}
+}
+
+// after returns true if the key is after the last key in the order of the list
+func (l *list) after(key, last *pageKey) bool {
+	if l.descending {
+		return key.before(last)
+	}
+	return last.before(key)
+}
+
+// pageParams returns the page size and the key of the last message of the previous page of a request
+func pageParams(r *http.Request) (int, *pageKey, error) {
+	size := DefaultPageSize
+	if s := r.URL.Query().Get(pageSizeParam); s != "" {
+		var err error
+		if size, err = strconv.Atoi(s); err != nil || size <= 0 || size > MaxPageSize {
+			return 0, nil, status.Errorf(codes.InvalidArgument, "%s must be between 1 and %d", pageSizeParam, MaxPageSize)
+		}
+	}
+	var last *pageKey
+	if s := r.URL.Query().Get(pageTokenParam); s != "" {
+		b, err := base64.RawURLEncoding.DecodeString(s)
+		if err == nil {
+			last = &pageKey{}
+			err = json.Unmarshal(b, last)
+		}
+		if err != nil {
+			return 0, nil, status.Errorf(codes.InvalidArgument, "invalid %s", pageTokenParam)
+		}
+	}
+	return size, last, nil
+}
+
+// encodePageToken returns the page token of the page following the message of a key
+func encodePageToken(key *pageKey) (string, error) {
+	b, err := json.Marshal(key)
+	if err != nil {
+		return "", err
+	}
+	return base64.RawURLEncoding.EncodeToString(b), nil
+}
+
+// timestampKey returns the time of a timestamp of a page key, the zero time if the timestamp is invalid
+func timestampKey(ts *timestamp.Timestamp) time.Time {
+	t, _ := ptypes.Timestamp(ts)
+	return t
+}
+
+// populate sets the fields of a List request from the path parameters and the query parameters of an HTTP request
+func populate(req proto.Message, r *http.Request, pathParams map[string]string) error {
+	for field, value := range pathParams {
+		if err := runtime.PopulateFieldFromPath(req, field, value); err != nil {
+			return status.Errorf(codes.InvalidArgument, "%s: %s", field, err)
+		}
+	}
+	query := r.URL.Query()
+	query.Del(pageSizeParam)
+	query.Del(pageTokenParam)
+	if err := runtime.PopulateQueryParameters(req, query, utilities.NewDoubleArray(nil)); err != nil {
+		return status.Errorf(codes.InvalidArgument, "%s", err)
+	}
+	return nil
+}
+
+// pattern returns the pattern of a path template of literal segments and {field} variables, e.g. /v1/apps/{app_id}/states,
+// as the generated routes compile the templates of their HTTP rules
+func pattern(template string) runtime.Pattern {
+	var ops []int
+	var pool []string
+	for _, segment := range strings.Split(strings.Trim(template, "/"), "/") {
+		if strings.HasPrefix(segment, "{") && strings.HasSuffix(segment, "}") {
+			ops = append(ops, int(utilities.OpPush), 0, int(utilities.OpConcatN), 1, int(utilities.OpCapture), len(pool))
+			pool = append(pool, strings.Trim(segment, "{}"))
+		} else {
+			ops = append(ops, int(utilities.OpLitPush), len(pool))
+			pool = append(pool, segment)
+		}
+	}
+	return runtime.MustPattern(runtime.NewPattern(1, ops, pool, ""))
+}
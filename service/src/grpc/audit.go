@@ -0,0 +1,80 @@
+package grpc
+
+import (
+	"fmt"
+
+	"github.com/callstats-io/ai-decision/service/src/storage"
+	"github.com/callstats-io/go-common/auth"
+	grpc_utils "github.com/callstats-io/go-common/grpc"
+	context "golang.org/x/net/context"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/metadata"
+)
+
+// AuditReasonKey is the metadata key of the reason of the mutations of a call, recorded with their audit events.
+// The gateway forwards the X-Audit-Reason header of HTTP requests as such.
+const AuditReasonKey = "x-audit-reason"
+
+// actorInternal is the actor of calls with internal tokens without a subject or user claim
+const actorInternal = "internal"
+
+// ActorAnonymous is the actor of the calls of servers without an Authorizer
+const ActorAnonymous = "anonymous"
+
+// auditor sets the audit of the mutations of calls, see callAudit
+type auditor struct {
+	// authorized is true if the claims of calls are set by an Authorizer
+	authorized bool
+}
+
+// UnaryServerInterceptor sets the audit of the mutations of unary calls
+func (a *auditor) UnaryServerInterceptor(ctx context.Context, req interface{}, info *grpc.UnaryServerInfo,
+	handler grpc.UnaryHandler) (interface{}, error) {
+	return handler(a.callAudit(ctx, info.FullMethod), req)
+}
+
+// StreamServerInterceptor sets the audit of the mutations of streaming calls
+func (a *auditor) StreamServerInterceptor(srv interface{}, stream grpc.ServerStream, info *grpc.StreamServerInfo,
+	handler grpc.StreamHandler) error {
+	wrapped := grpc_utils.WrapServerStream(stream)
+	wrapped.WrappedContext = a.callAudit(stream.Context(), info.FullMethod)
+	return handler(srv, wrapped)
+}
+
+// callAudit returns the context of a call with the audit of its mutations, see storage.WithAudit.
+// The actor is taken from the claims set by the Authorizer and the reason from the AuditReasonKey metadata.
+// Calls whose token names no actor have none, their mutations fail with storage.ErrNoAuditActor.
+// Without an Authorizer calls have no claims and are audited as ActorAnonymous.
+func (a *auditor) callAudit(ctx context.Context, fullMethod string) context.Context {
+	audit := storage.Audit{
+		Actor: ActorAnonymous,
+		RPC:   fullMethod,
+	}
+	if a.authorized {
+		audit.Actor = claimsActor(auth.EndpointClaimsFromContext(ctx))
+	}
+	md, _ := metadata.FromIncomingContext(ctx)
+	if values := md[AuditReasonKey]; len(values) > 0 {
+		audit.Reason = values[0]
+	}
+	return storage.WithAudit(ctx, audit)
+}
+
+// claimsActor returns the actor of the claims of a call, the subject of the token if set, otherwise its user or app.
+// Returns an empty actor if there are no claims or none of them names an actor.
+func claimsActor(claims *auth.EndpointClaims) string {
+	switch {
+	case claims == nil:
+		return ""
+	case claims.Subject != "":
+		return claims.Subject
+	case claims.UserID != "":
+		return "user:" + claims.UserID
+	case claims.AppID != 0:
+		return fmt.Sprintf("app:%d", claims.AppID)
+	case claims.ValidateAuthScopes([]string{ScopeInternal}) == nil:
+		return actorInternal
+	default:
+		return ""
+	}
+}
@@ -0,0 +1,101 @@
+package grpc_test
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/callstats-io/ai-decision/service/gen/protos"
+	sgrpc "github.com/callstats-io/ai-decision/service/src/grpc"
+	"github.com/callstats-io/ai-decision/service/src/storage"
+	"github.com/callstats-io/go-common/auth"
+	"github.com/golang/protobuf/ptypes"
+	"github.com/stretchr/testify/require"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/metadata"
+	"google.golang.org/grpc/status"
+)
+
+func TestAudit(t *testing.T) {
+	defer mockStorage.Reset()
+	internal := []string{sgrpc.ScopeInternal}
+	subject := &auth.EndpointClaims{Scope: internal}
+	subject.Subject = "compliance-bot"
+
+	tests := []struct {
+		Description string
+		Ctx         context.Context
+		ExpActor    string
+		ExpReason   string
+	}{
+		{"subject", metadata.AppendToOutgoingContext(withToken(t, testSecret, subject), sgrpc.AuditReasonKey, "ticket 42"),
+			"compliance-bot", "ticket 42"},
+		{"user", withToken(t, testSecret, &auth.EndpointClaims{AppID: 1, UserID: "jane"}), "user:jane", ""},
+		{"app", withToken(t, testSecret, &auth.EndpointClaims{AppID: 1}), "app:1", ""},
+		{"internal", withToken(t, testSecret, &auth.EndpointClaims{Scope: internal}), "internal", ""},
+	}
+	for _, test := range tests {
+		t.Run(test.Description, func(t *testing.T) {
+			assert := require.New(t)
+			_, err := testStateClient.DeleteRange(test.Ctx, &protos.StateDeleteRangeRequest{
+				AppId:              1,
+				Keyword:            "forecast",
+				GenerationTimeFrom: ptypes.TimestampNow(),
+				GenerationTimeTo:   ptypes.TimestampNow(),
+			})
+			assert.Nil(err)
+			assert.Equal(storage.Audit{
+				Actor:  test.ExpActor,
+				RPC:    "/callstats.ai_decision.AIDecisionStateService/DeleteRange",
+				Reason: test.ExpReason,
+			}, mockStorage.LastAudit())
+		})
+	}
+}
+
+func TestAuditWithoutAuthorizer(t *testing.T) {
+	defer mockStorage.Reset()
+	assert := require.New(t)
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+	_, conn := serveTest(ctx, nil)
+	defer conn.Close()
+	client := protos.NewAIDecisionStateServiceClient(conn)
+
+	// calls without a token are audited as anonymous, tokens are not verified
+	for _, callCtx := range []context.Context{
+		context.Background(),
+		withToken(t, []byte("other"), &auth.EndpointClaims{AppID: 1, UserID: "jane"}),
+	} {
+		_, err := client.DeleteRange(callCtx, &protos.StateDeleteRangeRequest{
+			AppId:              1,
+			Keyword:            "forecast",
+			GenerationTimeFrom: ptypes.TimestampNow(),
+			GenerationTimeTo:   ptypes.TimestampNow(),
+		})
+		assert.Nil(err)
+		assert.Equal(sgrpc.ActorAnonymous, mockStorage.LastAudit().Actor)
+	}
+}
+
+func TestAuditListRequiresInternalScope(t *testing.T) {
+	defer mockStorage.Reset()
+	assert := require.New(t)
+	mockStorage.MockAuditEvents([]*storage.AuditEvent{
+		{ID: 1, OccurredAt: time.Now(), Actor: "app:1", AppID: 1, Action: storage.AuditMessageDelete, Target: "7", Count: 1},
+	})
+	client := protos.NewAIDecisionAuditServiceClient(testConn)
+
+	stream, err := client.List(withToken(t, testSecret, &auth.EndpointClaims{AppID: 1}), &protos.AuditEventListRequest{AppId: 1})
+	assert.Nil(err)
+	_, err = stream.Recv()
+	assert.Equal(codes.PermissionDenied, status.Code(err))
+
+	stream, err = client.List(withToken(t, testSecret, &auth.EndpointClaims{Scope: []string{sgrpc.ScopeInternal}}),
+		&protos.AuditEventListRequest{AppId: 1})
+	assert.Nil(err)
+	event, err := stream.Recv()
+	assert.Nil(err)
+	assert.Equal("app:1", event.Actor)
+	assert.Equal("7", event.Target)
+}
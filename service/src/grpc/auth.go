@@ -0,0 +1,167 @@
+package grpc
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/callstats-io/ai-decision/service/gen/protos"
+	"github.com/callstats-io/go-common/auth"
+	grpc_utils "github.com/callstats-io/go-common/grpc"
+	"github.com/callstats-io/go-common/log"
+	context "golang.org/x/net/context"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/metadata"
+	"google.golang.org/grpc/status"
+)
+
+// ScopeInternal is the scope of the tokens of internal services, e.g. the analytics pipelines.
+// Internal tokens may call for any app and call the methods of requests without an app_id, e.g. Compact.
+const ScopeInternal = "aid:internal"
+
+// The token is sent in the authorization metadata of a call as "Bearer <token>",
+// the gateway forwards the Authorization header of HTTP requests as such
+const (
+	authorizationKey = "authorization"
+	bearerPrefix     = "Bearer "
+)
+
+// log keys of denied calls
+const (
+	logKeyMethod     = "method"
+	logKeyReason     = "reason"
+	logKeyAppID      = "appID"
+	logKeyTokenAppID = "tokenAppID"
+)
+
+// Authorizer authorizes calls by the JWT of the call. The token must be signed with the secret and the signing method.
+// A token with the app ID claim of an app may only call with requests of that app, tokens with ScopeInternal may call
+// with requests of any app or without an app. Denied calls are logged with their reason and fail with Unauthenticated
+// if the token is missing or invalid, PermissionDenied otherwise. The claims of authorized calls are set on the
+// context of the call, see auth.EndpointClaimsFromContext. Health checks are not authorized.
+type Authorizer struct {
+	secret []byte
+	method auth.SigningMethod
+}
+
+// NewAuthorizer builds a new Authorizer of tokens signed with the secret and the signing method
+func NewAuthorizer(secret []byte, method auth.SigningMethod) *Authorizer {
+	return &Authorizer{secret: secret, method: method}
+}
+
+// UnaryServerInterceptor authorizes unary calls by their token and the app of their request
+func (a *Authorizer) UnaryServerInterceptor(ctx context.Context, req interface{}, info *grpc.UnaryServerInfo,
+	handler grpc.UnaryHandler) (interface{}, error) {
+	if isHealthMethod(info.FullMethod) {
+		return handler(ctx, req)
+	}
+	claims, err := a.authenticate(ctx, info.FullMethod)
+	if err != nil {
+		return nil, err
+	}
+	appID, ok := requestApp(req)
+	if err := authorize(ctx, info.FullMethod, claims, appID, ok); err != nil {
+		return nil, err
+	}
+	return handler(auth.WithEndpointClaims(ctx, claims), req)
+}
+
+// StreamServerInterceptor authorizes streaming calls by their token and the app of every received message.
+// Messages without an app, e.g. the chunks of SaveChunked following the first one, are authorized by the
+// previous messages of the stream.
+func (a *Authorizer) StreamServerInterceptor(srv interface{}, stream grpc.ServerStream, info *grpc.StreamServerInfo,
+	handler grpc.StreamHandler) error {
+	claims, err := a.authenticate(stream.Context(), info.FullMethod)
+	if err != nil {
+		return err
+	}
+	wrapped := grpc_utils.WrapServerStream(stream)
+	wrapped.WrappedContext = auth.WithEndpointClaims(wrapped.WrappedContext, claims)
+	return handler(srv, &authorizedStream{WrappedServerStream: wrapped, method: info.FullMethod, claims: claims})
+}
+
+// authenticate returns the verified claims of the token of a call
+func (a *Authorizer) authenticate(ctx context.Context, method string) (*auth.EndpointClaims, error) {
+	md, _ := metadata.FromIncomingContext(ctx)
+	token := ""
+	if values := md[authorizationKey]; len(values) > 0 {
+		if !strings.HasPrefix(values[0], bearerPrefix) {
+			return nil, deny(ctx, codes.Unauthenticated, method, nil, 0, "authorization is not a bearer token")
+		}
+		token = strings.TrimPrefix(values[0], bearerPrefix)
+	}
+	claims := &auth.EndpointClaims{}
+	if err := claims.ParseAndVerify(ctx, a.secret, a.method, token); err != nil {
+		return nil, deny(ctx, codes.Unauthenticated, method, nil, 0, err.Error())
+	}
+	return claims, nil
+}
+
+// authorize checks that the claims allow a call with a request of the app, if the request has an app at all
+func authorize(ctx context.Context, method string, claims *auth.EndpointClaims, appID int32, hasApp bool) error {
+	if claims.ValidateAuthScopes([]string{ScopeInternal}) == nil {
+		return nil
+	}
+	if !hasApp {
+		return deny(ctx, codes.PermissionDenied, method, claims, appID, fmt.Sprintf("%s requires the %s scope", method, ScopeInternal))
+	}
+	// a token without an app claim must not match requests of app 0, e.g. runs of all apps
+	if claims.AppID == 0 || claims.ValidateAppID(int(appID)) != nil {
+		return deny(ctx, codes.PermissionDenied, method, claims, appID, fmt.Sprintf("token is not valid for app_id %d", appID))
+	}
+	return nil
+}
+
+// deny logs a denied call with its reason and returns the error of the call
+func deny(ctx context.Context, code codes.Code, method string, claims *auth.EndpointClaims, appID int32, reason string) error {
+	logger := log.FromContext(ctx).With(log.String(logKeyMethod, method), log.String(logKeyReason, reason))
+	if claims != nil {
+		logger = logger.With(log.Int(logKeyAppID, int(appID)), log.Int(logKeyTokenAppID, claims.AppID))
+	}
+	logger.Warn("denied call")
+	return status.Error(code, reason)
+}
+
+type appRequest interface {
+	GetAppId() int32
+}
+
+// requestApp returns the app of a request and whether the request has an app
+func requestApp(req interface{}) (int32, bool) {
+	switch r := req.(type) {
+	case *protos.AuditEventListRequest:
+		// audit events name the actors of all apps, they are only listed with internal tokens
+		return 0, false
+	case appRequest:
+		return r.GetAppId(), true
+	case *protos.StateSaveChunk:
+		if r.GetState() != nil {
+			return r.GetState().GetAppId(), true
+		}
+	}
+	return 0, false
+}
+
+// authorizedStream authorizes every message received on a stream
+type authorizedStream struct {
+	*grpc_utils.WrappedServerStream
+	method     string
+	claims     *auth.EndpointClaims
+	authorized bool
+}
+
+// RecvMsg receives the next message and authorizes it
+func (s *authorizedStream) RecvMsg(m interface{}) error {
+	if err := s.WrappedServerStream.RecvMsg(m); err != nil {
+		return err
+	}
+	appID, ok := requestApp(m)
+	if !ok && s.authorized {
+		return nil
+	}
+	if err := authorize(s.Context(), s.method, s.claims, appID, ok); err != nil {
+		return err
+	}
+	s.authorized = true
+	return nil
+}
@@ -0,0 +1,168 @@
+package grpc_test
+
+import (
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"net"
+	"os"
+	"testing"
+	"time"
+
+	"github.com/callstats-io/ai-decision/service/gen/protos"
+	"github.com/callstats-io/ai-decision/service/src/flowdock"
+	sgrpc "github.com/callstats-io/ai-decision/service/src/grpc"
+	"github.com/callstats-io/ai-decision/service/src/service"
+	"github.com/callstats-io/ai-decision/service/src/storage"
+	"github.com/callstats-io/ai-decision/service/src/storage/mocks"
+	"github.com/callstats-io/go-common/auth"
+	jwt "github.com/dgrijalva/jwt-go"
+	"github.com/golang/protobuf/ptypes"
+	"github.com/stretchr/testify/require"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/metadata"
+	"google.golang.org/grpc/status"
+)
+
+var (
+	testCtx, testCtxCancel = context.WithCancel(context.Background())
+	testSecret             = []byte("secret")
+	testServer             *sgrpc.Server
+	testConn               *grpc.ClientConn
+	testStateClient        protos.AIDecisionStateServiceClient
+	mockStorage            *mocks.Storage
+)
+
+func mustBeNil(err error) {
+	if err != nil {
+		panic(err)
+	}
+}
+
+// serveTest serves the services on the mock storage until the context is done and returns the connection to them
+func serveTest(ctx context.Context, authorizer *sgrpc.Authorizer) (*sgrpc.Server, *grpc.ClientConn) {
+	messageService, err := service.NewAIDecisionMessageService(mockStorage, flowdock.NewClient(""))
+	mustBeNil(err)
+	stateService, err := service.NewAIDecisionStateService(mockStorage)
+	mustBeNil(err)
+	leaseService, err := service.NewAIDecisionLeaseService(mockStorage)
+	mustBeNil(err)
+	runService, err := service.NewAIDecisionRunService(mockStorage)
+	mustBeNil(err)
+	auditService, err := service.NewAIDecisionAuditService(mockStorage)
+	mustBeNil(err)
+	server, err := sgrpc.NewServer(ctx, authorizer, messageService, stateService, leaseService, runService, auditService)
+	mustBeNil(err)
+	server.WithReflection()
+	listener, err := net.Listen("tcp", "localhost:0")
+	mustBeNil(err)
+	go server.Serve(ctx, listener)
+
+	conn, err := grpc.Dial(listener.Addr().String(), grpc.WithInsecure())
+	mustBeNil(err)
+	return server, conn
+}
+
+func suiteSetup() {
+	mockStorage = mocks.NewMockedStorage()
+	testServer, testConn = serveTest(testCtx, sgrpc.NewAuthorizer(testSecret, auth.SigningMethodHS256))
+	testStateClient = protos.NewAIDecisionStateServiceClient(testConn)
+}
+
+func TestMain(m *testing.M) {
+	os.Exit(func() int {
+		suiteSetup()
+		defer testCtxCancel()
+		return m.Run()
+	}())
+}
+
+// withToken returns a context sending a token of the claims signed with the secret
+func withToken(t *testing.T, secret []byte, claims *auth.EndpointClaims) context.Context {
+	token, err := jwt.NewWithClaims(jwt.SigningMethodHS256, claims).SignedString(secret)
+	require.Nil(t, err)
+	return metadata.AppendToOutgoingContext(context.Background(), "authorization", "Bearer "+token)
+}
+
+func TestAuthorizerUnary(t *testing.T) {
+	defer mockStorage.Reset()
+	mockStorage.MockGetLatestStateError(storage.ErrNotFound)
+	expired := &auth.EndpointClaims{AppID: 1}
+	expired.ExpiresAt = time.Now().Add(-time.Minute).Unix()
+
+	tests := []struct {
+		Description string
+		Ctx         context.Context
+		AppID       int32
+		ExpCode     codes.Code
+	}{
+		{"no token", context.Background(), 1, codes.Unauthenticated},
+		{"not a bearer token", metadata.AppendToOutgoingContext(context.Background(), "authorization", "Basic x"), 1, codes.Unauthenticated},
+		{"invalid signature", withToken(t, []byte("other"), &auth.EndpointClaims{AppID: 1}), 1, codes.Unauthenticated},
+		{"expired token", withToken(t, testSecret, expired), 1, codes.Unauthenticated},
+		{"other app", withToken(t, testSecret, &auth.EndpointClaims{AppID: 1}), 2, codes.PermissionDenied},
+		{"no app claim", withToken(t, testSecret, &auth.EndpointClaims{}), 0, codes.PermissionDenied},
+		// authorized calls reach the service
+		{"same app", withToken(t, testSecret, &auth.EndpointClaims{AppID: 1}), 1, codes.NotFound},
+		{"internal", withToken(t, testSecret, &auth.EndpointClaims{Scope: []string{sgrpc.ScopeInternal}}), 2, codes.NotFound},
+	}
+	for _, test := range tests {
+		t.Run(test.Description, func(t *testing.T) {
+			_, err := testStateClient.GetLatest(test.Ctx, &protos.StateGetLatestRequest{AppId: test.AppID, Keyword: "forecast"})
+			require.Equal(t, test.ExpCode, status.Code(err), "%v", err)
+		})
+	}
+}
+
+func TestAuthorizerWithoutApp(t *testing.T) {
+	defer mockStorage.Reset()
+	assert := require.New(t)
+	policy := &protos.CompactionPolicy{Keyword: "forecast", KeepLast: 7}
+
+	_, err := testStateClient.SetCompactionPolicy(withToken(t, testSecret, &auth.EndpointClaims{AppID: 1}), policy)
+	assert.Equal(codes.PermissionDenied, status.Code(err))
+
+	_, err = testStateClient.SetCompactionPolicy(withToken(t, testSecret, &auth.EndpointClaims{Scope: []string{sgrpc.ScopeInternal}}), policy)
+	assert.NotContains([]codes.Code{codes.Unauthenticated, codes.PermissionDenied}, status.Code(err))
+
+	stream, err := testStateClient.Compact(withToken(t, testSecret, &auth.EndpointClaims{AppID: 1}), &protos.CompactRequest{DryRun: true})
+	assert.Nil(err)
+	_, err = stream.Recv()
+	assert.Equal(codes.PermissionDenied, status.Code(err))
+}
+
+func TestAuthorizerStream(t *testing.T) {
+	defer mockStorage.Reset()
+	data := []byte(`{}`)
+	sum := sha256.Sum256(data)
+
+	tests := []struct {
+		Description string
+		AppID       int32
+		ExpCode     codes.Code
+	}{
+		{"same app", 1, codes.OK},
+		{"other app", 2, codes.PermissionDenied},
+	}
+	for _, test := range tests {
+		t.Run(test.Description, func(t *testing.T) {
+			assert := require.New(t)
+			mockStorage.MockSavedStates([]*storage.AidAnalyticsState{
+				{ID: 1, AppID: test.AppID, Keyword: "forecast", SavedAt: time.Now(), Revision: 1, ChunkCount: 1},
+			})
+			stream, err := testStateClient.SaveChunked(withToken(t, testSecret, &auth.EndpointClaims{AppID: 1}))
+			assert.Nil(err)
+			// the chunks following the first one have no app and are authorized by the first one
+			chunks := []*protos.StateSaveChunk{
+				{State: &protos.StateSaveRequest{AppId: test.AppID, Keyword: "forecast", GenerationTime: ptypes.TimestampNow()}},
+				{Data: data, Checksum: hex.EncodeToString(sum[:])},
+			}
+			for _, chunk := range chunks {
+				stream.Send(chunk)
+			}
+			_, err = stream.CloseAndRecv()
+			assert.Equal(test.ExpCode, status.Code(err), "%v", err)
+		})
+	}
+}
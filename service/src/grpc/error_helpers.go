@@ -1,8 +1,13 @@
 package grpc
 
 import (
+	"strings"
+	"time"
+
 	"github.com/callstats-io/go-common/log"
+	"github.com/golang/protobuf/ptypes"
 	context "golang.org/x/net/context"
+	"google.golang.org/genproto/googleapis/rpc/errdetails"
 	"google.golang.org/grpc/codes"
 	"google.golang.org/grpc/status"
 )
@@ -19,14 +24,75 @@ func ErrInvalidArgument(ctx context.Context, err error) error {
 	return status.Error(codes.InvalidArgument, err.Error())
 }
 
+// ErrBadRequest logs the field violations of a request and returns them with gRPC error code InvalidArgument and
+// a BadRequest detail. The message lists every violation as "field: description".
+func ErrBadRequest(ctx context.Context, violations []*errdetails.BadRequest_FieldViolation) error {
+	descriptions := make([]string, len(violations))
+	for i, v := range violations {
+		descriptions[i] = v.Field + ": " + v.Description
+	}
+	msg := strings.Join(descriptions, "; ")
+	log.FromContext(ctx).Error("invalid argument", log.String("violations", msg))
+	st := status.New(codes.InvalidArgument, msg)
+	if withViolations, detailErr := st.WithDetails(&errdetails.BadRequest{FieldViolations: violations}); detailErr == nil {
+		st = withViolations
+	}
+	return st.Err()
+}
+
 // ErrNotFound logs and wraps the given error with gRPC error code NotFound
 func ErrNotFound(ctx context.Context, err error) error {
 	log.FromContext(ctx).Error("not found", log.Error(err))
 	return status.Error(codes.NotFound, err.Error())
 }
 
+// ErrUnauthenticated logs and wraps the given error with gRPC error code Unauthenticated
+func ErrUnauthenticated(ctx context.Context, err error) error {
+	log.FromContext(ctx).Error("unauthenticated", log.Error(err))
+	return status.Error(codes.Unauthenticated, err.Error())
+}
+
 // ErrFailedPrecondition logs and wraps the given error with gRPC error code FailedPrecondition
 func ErrFailedPrecondition(ctx context.Context, err error) error {
 	log.FromContext(ctx).Error("failed precondition", log.Error(err))
 	return status.Error(codes.FailedPrecondition, err.Error())
 }
+
+// ErrAborted logs and wraps the given error with gRPC error code Aborted
+func ErrAborted(ctx context.Context, err error) error {
+	log.FromContext(ctx).Error("aborted", log.Error(err))
+	return status.Error(codes.Aborted, err.Error())
+}
+
+// ErrDataLoss logs and wraps the given error with gRPC error code DataLoss
+func ErrDataLoss(ctx context.Context, err error) error {
+	log.FromContext(ctx).Error("data loss", log.Error(err))
+	return status.Error(codes.DataLoss, err.Error())
+}
+
+// ErrAlreadyExists logs and wraps the given error with gRPC error code AlreadyExists
+func ErrAlreadyExists(ctx context.Context, err error) error {
+	log.FromContext(ctx).Error("already exists", log.Error(err))
+	return status.Error(codes.AlreadyExists, err.Error())
+}
+
+// ErrRetryable logs and wraps the given error with the gRPC error code and a RetryInfo detail,
+// hinting the client to retry the request after the retry delay
+func ErrRetryable(ctx context.Context, code codes.Code, err error, retryDelay time.Duration) error {
+	log.FromContext(ctx).Error("retryable", log.String("code", code.String()), log.Error(err))
+	st := status.New(code, err.Error())
+	if withRetry, detailErr := st.WithDetails(&errdetails.RetryInfo{RetryDelay: ptypes.DurationProto(retryDelay)}); detailErr == nil {
+		st = withRetry
+	}
+	return st.Err()
+}
+
+// ErrWithInfo logs and wraps the given error with the gRPC error code and an ErrorInfo detail of the reason
+func ErrWithInfo(ctx context.Context, code codes.Code, err error, info *ErrorInfo) error {
+	log.FromContext(ctx).Error("failed", log.String("code", code.String()), log.String("reason", info.Reason), log.Error(err))
+	st := status.New(code, err.Error())
+	if withInfo, detailErr := st.WithDetails(info); detailErr == nil {
+		st = withInfo
+	}
+	return st.Err()
+}
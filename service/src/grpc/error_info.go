@@ -0,0 +1,28 @@
+package grpc
+
+import (
+	"github.com/golang/protobuf/proto"
+)
+
+// ErrorDomain is the domain of the ErrorInfo details of the service
+const ErrorDomain = "ai-decision.callstats.io"
+
+// ErrorInfo is the google.rpc.ErrorInfo error detail, which is missing from the vendored genproto.
+// It is wire compatible with google.rpc.ErrorInfo, so clients unpack it with their googleapis protos.
+type ErrorInfo struct {
+	// Reason is the UPPER_SNAKE_CASE reason of the error, unique within the domain
+	Reason string `protobuf:"bytes,1,opt,name=reason,proto3" json:"reason,omitempty"`
+	// Domain is the logical grouping of the reason, ErrorDomain for errors of the service
+	Domain string `protobuf:"bytes,2,opt,name=domain,proto3" json:"domain,omitempty"`
+	// Metadata holds the details of the error, e.g. the template type and version of a render error
+	Metadata map[string]string `protobuf:"bytes,3,rep,name=metadata,proto3" json:"metadata,omitempty" protobuf_key:"bytes,1,opt,name=key,proto3" protobuf_val:"bytes,2,opt,name=value,proto3"`
+}
+
+func (m *ErrorInfo) Reset()         { *m = ErrorInfo{} }
+func (m *ErrorInfo) String() string { return proto.CompactTextString(m) }
+func (*ErrorInfo) ProtoMessage()    {}
+
+func init() {
+	proto.RegisterType((*ErrorInfo)(nil), "google.rpc.ErrorInfo")
+	proto.RegisterMapType((map[string]string)(nil), "google.rpc.ErrorInfo.MetadataEntry")
+}
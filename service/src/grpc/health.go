@@ -0,0 +1,97 @@
+package grpc
+
+import (
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/callstats-io/go-common/log"
+	context "golang.org/x/net/context"
+	"google.golang.org/grpc/codes"
+	healthpb "google.golang.org/grpc/health/grpc_health_v1"
+	"google.golang.org/grpc/status"
+)
+
+// healthMethodPrefix is the prefix of the methods of the health service, which are neither authorized nor traced
+// as they are called by probes
+const healthMethodPrefix = "/grpc.health.v1.Health/"
+
+// StatusCheck returns an error if a dependency of the services is unavailable, e.g. postgres
+type StatusCheck func(ctx context.Context) error
+
+// healthServer implements the grpc.health.v1.Health service with the serving status of the server,
+// the empty service name, and of every registered service
+type healthServer struct {
+	lock     sync.Mutex
+	statuses map[string]healthpb.HealthCheckResponse_ServingStatus
+}
+
+func newHealthServer(services []string) *healthServer {
+	h := &healthServer{statuses: map[string]healthpb.HealthCheckResponse_ServingStatus{}}
+	h.setStatus(append(services, ""), healthpb.HealthCheckResponse_SERVING)
+	return h
+}
+
+// Check returns the serving status of a service, NotFound if the service is not registered
+func (h *healthServer) Check(ctx context.Context, req *healthpb.HealthCheckRequest) (*healthpb.HealthCheckResponse, error) {
+	h.lock.Lock()
+	defer h.lock.Unlock()
+	st, ok := h.statuses[req.Service]
+	if !ok {
+		return nil, status.Errorf(codes.NotFound, "unknown service %s", req.Service)
+	}
+	return &healthpb.HealthCheckResponse{Status: st}, nil
+}
+
+func (h *healthServer) setStatus(services []string, st healthpb.HealthCheckResponse_ServingStatus) {
+	h.lock.Lock()
+	defer h.lock.Unlock()
+	for _, service := range services {
+		h.statuses[service] = st
+	}
+}
+
+func (h *healthServer) services() []string {
+	h.lock.Lock()
+	defer h.lock.Unlock()
+	services := make([]string, 0, len(h.statuses))
+	for service := range h.statuses {
+		services = append(services, service)
+	}
+	return services
+}
+
+// runChecks sets the serving status of all services by the checks every interval until the context is done.
+// All services are NOT_SERVING while any check fails, as all of them depend on the storage.
+func (h *healthServer) runChecks(ctx context.Context, interval time.Duration, checks []StatusCheck) {
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+			h.check(ctx, interval, checks)
+		}
+	}
+}
+
+// check runs the checks, each within the interval, and sets the serving status of all services
+func (h *healthServer) check(ctx context.Context, timeout time.Duration, checks []StatusCheck) {
+	st := healthpb.HealthCheckResponse_SERVING
+	for _, check := range checks {
+		checkCtx, cancel := context.WithTimeout(ctx, timeout)
+		err := check(checkCtx)
+		cancel()
+		if err != nil {
+			log.FromContext(ctx).Error("failed health check", log.Error(err))
+			st = healthpb.HealthCheckResponse_NOT_SERVING
+			break
+		}
+	}
+	h.setStatus(h.services(), st)
+}
+
+func isHealthMethod(fullMethod string) bool {
+	return strings.HasPrefix(fullMethod, healthMethodPrefix)
+}
@@ -0,0 +1,76 @@
+package grpc_test
+
+import (
+	"context"
+	"errors"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	sgrpc "github.com/callstats-io/ai-decision/service/src/grpc"
+	"github.com/callstats-io/go-common/auth"
+	"github.com/stretchr/testify/require"
+	"google.golang.org/grpc/codes"
+	healthpb "google.golang.org/grpc/health/grpc_health_v1"
+	rpb "google.golang.org/grpc/reflection/grpc_reflection_v1alpha"
+	"google.golang.org/grpc/status"
+)
+
+func TestHealth(t *testing.T) {
+	assert := require.New(t)
+	client := healthpb.NewHealthClient(testConn)
+	stateService := "callstats.ai_decision.AIDecisionStateService"
+
+	// health checks are not authorized
+	for _, service := range []string{"", stateService} {
+		res, err := client.Check(context.Background(), &healthpb.HealthCheckRequest{Service: service})
+		assert.Nil(err)
+		assert.Equal(healthpb.HealthCheckResponse_SERVING, res.Status)
+	}
+	_, err := client.Check(context.Background(), &healthpb.HealthCheckRequest{Service: "unknown"})
+	assert.Equal(codes.NotFound, status.Code(err))
+
+	// all services are not serving while a check fails
+	var failing atomic.Value
+	failing.Store(true)
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+	testServer.WithHealthChecks(ctx, 10*time.Millisecond,
+		func(context.Context) error { return nil },
+		func(context.Context) error {
+			if failing.Load().(bool) {
+				return errors.New("postgres is down")
+			}
+			return nil
+		})
+	for _, service := range []string{"", stateService} {
+		res, err := client.Check(context.Background(), &healthpb.HealthCheckRequest{Service: service})
+		assert.Nil(err)
+		assert.Equal(healthpb.HealthCheckResponse_NOT_SERVING, res.Status)
+	}
+
+	// serving again once the checks pass
+	failing.Store(false)
+	time.Sleep(50 * time.Millisecond)
+	res, err := client.Check(context.Background(), &healthpb.HealthCheckRequest{Service: stateService})
+	assert.Nil(err)
+	assert.Equal(healthpb.HealthCheckResponse_SERVING, res.Status)
+}
+
+func TestReflection(t *testing.T) {
+	assert := require.New(t)
+	client := rpb.NewServerReflectionClient(testConn)
+
+	stream, err := client.ServerReflectionInfo(withToken(t, testSecret, &auth.EndpointClaims{Scope: []string{sgrpc.ScopeInternal}}))
+	assert.Nil(err)
+	assert.Nil(stream.Send(&rpb.ServerReflectionRequest{MessageRequest: &rpb.ServerReflectionRequest_ListServices{}}))
+	res, err := stream.Recv()
+	assert.Nil(err)
+	var services []string
+	for _, service := range res.GetListServicesResponse().Service {
+		services = append(services, service.Name)
+	}
+	assert.Contains(services, "callstats.ai_decision.AIDecisionMessageService")
+	assert.Contains(services, "grpc.health.v1.Health")
+	assert.Nil(stream.CloseSend())
+}
@@ -2,6 +2,7 @@ package grpc
 
 import (
 	"net"
+	"time"
 
 	context "golang.org/x/net/context"
 
@@ -10,26 +11,45 @@ import (
 	"github.com/callstats-io/go-common/grpc/metrics"
 	"github.com/callstats-io/go-common/grpc/panic"
 	"google.golang.org/grpc"
+	healthpb "google.golang.org/grpc/health/grpc_health_v1"
+	"google.golang.org/grpc/reflection"
 )
 
 // Server thin wrapper for grpcServer
 type Server struct {
 	grpcServer *grpc.Server
+	health     *healthServer
 }
 
-// NewServer builds new Server
-func NewServer(ctx context.Context, msrv protos.AIDecisionMessageServiceServer, ssrv protos.AIDecisionStateServiceServer) (*Server, error) {
+// NewServer builds new Server, calls are authorized by the authorizer unless it is nil.
+// Calls are traced as server spans if tracing is enabled, see tracing.Start.
+// The grpc.health.v1.Health service reports all services as SERVING, see WithHealthChecks.
+// The mutations of calls are audited with the actor of their claims, or ActorAnonymous without an authorizer, their
+// method and their AuditReasonKey metadata.
+func NewServer(ctx context.Context, authorizer *Authorizer, msrv protos.AIDecisionMessageServiceServer, ssrv protos.AIDecisionStateServiceServer,
+	lsrv protos.AIDecisionLeaseServiceServer, rsrv protos.AIDecisionRunServiceServer, asrv protos.AIDecisionAuditServiceServer) (*Server, error) {
 	s := &Server{}
+	unary := []grpc.UnaryServerInterceptor{
+		tracingUnaryServerInterceptor,
+		metrics.UnaryServerInterceptor,
+		panic.UnaryServerInterceptor(panic.LoggingRecovery(ctx)),
+	}
+	stream := []grpc.StreamServerInterceptor{
+		tracingStreamServerInterceptor,
+		metrics.StreamServerInterceptor,
+		panic.StreamServerInterceptor(panic.LoggingRecovery(ctx)),
+	}
+	if authorizer != nil {
+		unary = append(unary, authorizer.UnaryServerInterceptor)
+		stream = append(stream, authorizer.StreamServerInterceptor)
+	}
+	// the audit of a call is set after its claims
+	audit := &auditor{authorized: authorizer != nil}
+	unary = append(unary, audit.UnaryServerInterceptor)
+	stream = append(stream, audit.StreamServerInterceptor)
 	s.grpcServer = grpc.NewServer(
-		grpc.UnaryInterceptor(grpc_utils.ChainUnaryServerInterceptors(
-			metrics.UnaryServerInterceptor,
-			panic.UnaryServerInterceptor(panic.LoggingRecovery(ctx)),
-		)),
-		grpc.StreamInterceptor(
-			grpc_utils.ChainStreamServer(
-				metrics.StreamServerInterceptor,
-				panic.StreamServerInterceptor(panic.LoggingRecovery(ctx))),
-		),
+		grpc.UnaryInterceptor(grpc_utils.ChainUnaryServerInterceptors(unary...)),
+		grpc.StreamInterceptor(grpc_utils.ChainStreamServer(stream...)),
 	)
 	err := metrics.Register(ctx)
 	if err != nil {
@@ -38,13 +58,38 @@ func NewServer(ctx context.Context, msrv protos.AIDecisionMessageServiceServer,
 
 	protos.RegisterAIDecisionMessageServiceServer(s.grpcServer, msrv)
 	protos.RegisterAIDecisionStateServiceServer(s.grpcServer, ssrv)
+	protos.RegisterAIDecisionLeaseServiceServer(s.grpcServer, lsrv)
+	protos.RegisterAIDecisionRunServiceServer(s.grpcServer, rsrv)
+	protos.RegisterAIDecisionAuditServiceServer(s.grpcServer, asrv)
+
+	var services []string
+	for service := range s.grpcServer.GetServiceInfo() {
+		services = append(services, service)
+	}
+	s.health = newHealthServer(services)
+	healthpb.RegisterHealthServer(s.grpcServer, s.health)
 	return s, nil
 }
 
+// WithHealthChecks runs the status checks once and then every interval until the context is done.
+// The health service reports all services as NOT_SERVING while any check fails.
+func (s *Server) WithHealthChecks(ctx context.Context, interval time.Duration, checks ...StatusCheck) *Server {
+	s.health.check(ctx, interval, checks)
+	go s.health.runChecks(ctx, interval, checks)
+	return s
+}
+
+// WithReflection registers the server reflection service, e.g. for grpcurl to list and call the services
+func (s *Server) WithReflection() *Server {
+	reflection.Register(s.grpcServer)
+	return s
+}
+
 // Serve starts serving grpc requests
 func (s *Server) Serve(ctx context.Context, listener net.Listener) error {
 	go func() {
 		<-ctx.Done()
+		s.health.setStatus(s.health.services(), healthpb.HealthCheckResponse_NOT_SERVING)
 		s.grpcServer.GracefulStop()
 	}()
 	return s.grpcServer.Serve(listener)
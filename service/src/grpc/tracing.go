@@ -0,0 +1,70 @@
+package grpc
+
+import (
+	"strings"
+
+	"github.com/callstats-io/ai-decision/service/src/tracing"
+	grpc_utils "github.com/callstats-io/go-common/grpc"
+	"go.opencensus.io/trace"
+	context "golang.org/x/net/context"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/metadata"
+	"google.golang.org/grpc/status"
+)
+
+// tracingUnaryServerInterceptor traces unary calls as server spans, see startServerSpan
+func tracingUnaryServerInterceptor(ctx context.Context, req interface{}, info *grpc.UnaryServerInfo,
+	handler grpc.UnaryHandler) (interface{}, error) {
+	ctx, span := startServerSpan(ctx, info.FullMethod)
+	resp, err := handler(ctx, req)
+	endServerSpan(span, err)
+	return resp, err
+}
+
+// tracingStreamServerInterceptor traces streaming calls as server spans, see startServerSpan
+func tracingStreamServerInterceptor(srv interface{}, stream grpc.ServerStream, info *grpc.StreamServerInfo,
+	handler grpc.StreamHandler) error {
+	ctx, span := startServerSpan(stream.Context(), info.FullMethod)
+	wrapped := grpc_utils.WrapServerStream(stream)
+	wrapped.WrappedContext = ctx
+	err := handler(srv, wrapped)
+	endServerSpan(span, err)
+	return err
+}
+
+// startServerSpan starts the server span of a call, continuing the trace of the traceparent metadata of the call if any.
+// Health checks are not traced.
+func startServerSpan(ctx context.Context, fullMethod string) (context.Context, *trace.Span) {
+	if !tracing.Enabled() || isHealthMethod(fullMethod) {
+		return ctx, nil
+	}
+	name := strings.TrimPrefix(fullMethod, "/")
+	service, method := name, ""
+	if i := strings.LastIndex(name, "/"); i >= 0 {
+		service, method = name[:i], name[i+1:]
+	}
+	var span *trace.Span
+	md, _ := metadata.FromIncomingContext(ctx)
+	if parent, ok := tracing.FromMetadata(md); ok {
+		ctx, span = trace.StartSpanWithRemoteParent(ctx, name, parent, trace.WithSpanKind(trace.SpanKindServer))
+	} else {
+		ctx, span = trace.StartSpan(ctx, name, trace.WithSpanKind(trace.SpanKindServer))
+	}
+	span.AddAttributes(
+		trace.StringAttribute("rpc.system", "grpc"),
+		trace.StringAttribute("rpc.service", service),
+		trace.StringAttribute("rpc.method", method),
+	)
+	return ctx, span
+}
+
+// endServerSpan ends the span with the status of the call, the gRPC codes are the OpenCensus status codes
+func endServerSpan(span *trace.Span, err error) {
+	if span == nil {
+		return
+	}
+	s := status.Convert(err)
+	span.AddAttributes(trace.Int64Attribute("rpc.grpc.status_code", int64(s.Code())))
+	span.SetStatus(trace.Status{Code: int32(s.Code()), Message: s.Message()})
+	span.End()
+}
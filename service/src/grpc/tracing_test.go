@@ -0,0 +1,62 @@
+package grpc_test
+
+import (
+	"context"
+	"sync"
+	"testing"
+
+	"github.com/callstats-io/ai-decision/service/gen/protos"
+	sgrpc "github.com/callstats-io/ai-decision/service/src/grpc"
+	"github.com/callstats-io/ai-decision/service/src/storage"
+	"github.com/callstats-io/ai-decision/service/src/tracing"
+	"github.com/callstats-io/go-common/auth"
+	"github.com/stretchr/testify/require"
+	"go.opencensus.io/trace"
+	"google.golang.org/grpc/metadata"
+)
+
+type memoryExporter struct {
+	lock  sync.Mutex
+	spans []*trace.SpanData
+}
+
+func (e *memoryExporter) ExportSpan(sd *trace.SpanData) {
+	e.lock.Lock()
+	defer e.lock.Unlock()
+	e.spans = append(e.spans, sd)
+}
+
+func TestTracing(t *testing.T) {
+	defer mockStorage.Reset()
+	assert := require.New(t)
+	mockStorage.MockGetLatestStateError(storage.ErrNotFound)
+
+	exporter := &memoryExporter{}
+	tracing.Start(exporter)
+	defer tracing.Stop()
+
+	// the trace context of the client is continued, also for denied calls
+	parent := "00-4bf92f3577b34da6a3ce929d0e0e4736-00f067aa0ba902b7-01"
+	callCtx := metadata.AppendToOutgoingContext(withToken(t, testSecret, &auth.EndpointClaims{Scope: []string{sgrpc.ScopeInternal}}),
+		tracing.TraceparentHeader, parent)
+	_, err := testStateClient.GetLatest(callCtx, &protos.StateGetLatestRequest{AppId: 1, Keyword: "forecast"})
+	assert.NotNil(err)
+	_, err = testStateClient.GetLatest(metadata.AppendToOutgoingContext(context.Background(), tracing.TraceparentHeader, parent),
+		&protos.StateGetLatestRequest{AppId: 1, Keyword: "forecast"})
+	assert.NotNil(err)
+
+	exporter.lock.Lock()
+	defer exporter.lock.Unlock()
+	assert.Len(exporter.spans, 2)
+	for i, expCode := range []int64{5, 16} { // NotFound, Unauthenticated
+		span := exporter.spans[i]
+		assert.Equal("callstats.ai_decision.AIDecisionStateService/GetLatest", span.Name)
+		assert.Equal(trace.SpanKindServer, span.SpanKind)
+		assert.Equal("4bf92f3577b34da6a3ce929d0e0e4736", span.TraceID.String())
+		assert.Equal("00f067aa0ba902b7", span.ParentSpanID.String())
+		assert.Equal("GetLatest", span.Attributes["rpc.method"])
+		assert.Equal(expCode, span.Attributes["rpc.grpc.status_code"])
+		assert.Equal(int32(expCode), span.Code)
+		assert.NotEmpty(span.Message)
+	}
+}
@@ -35,9 +35,10 @@ type Status struct {
 
 // InternalRequestRouter handles status requests
 type InternalRequestRouter struct {
-	statusHandler  http.Handler
-	metricsHandler http.Handler
-	checkers       []StatusChecker
+	statusHandler    http.Handler
+	metricsHandler   http.Handler
+	freshnessHandler http.Handler
+	checkers         []StatusChecker
 }
 
 // NewInternalRequestRouter returns a new status handler
@@ -49,12 +50,25 @@ func NewInternalRequestRouter(metricsHandler http.Handler, checkers ...StatusChe
 	}
 	return sh
 }
+
+// WithFreshnessHandler serves the state freshness report at /freshness
+func (s *InternalRequestRouter) WithFreshnessHandler(h http.Handler) *InternalRequestRouter {
+	s.freshnessHandler = h
+	return s
+}
+
 func (s *InternalRequestRouter) ServeHTTP(w http.ResponseWriter, r *http.Request) {
 	switch r.RequestURI {
 	case "/status":
 		s.statusHandler.ServeHTTP(w, r)
 	case metrics.InternalMetricsPath:
 		s.metricsHandler.ServeHTTP(w, r)
+	case "/freshness":
+		if s.freshnessHandler == nil {
+			response.NotFound(w, nil)
+			return
+		}
+		s.freshnessHandler.ServeHTTP(w, r)
 	default:
 		response.NotFound(w, nil)
 	}
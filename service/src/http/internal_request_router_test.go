@@ -5,6 +5,7 @@ import (
 	"encoding/json"
 	"errors"
 
+	gohttp "net/http"
 	"net/http/httptest"
 	"testing"
 
@@ -47,3 +48,20 @@ func TestStatus(t *testing.T) {
 		})
 	}
 }
+
+func TestFreshness(t *testing.T) {
+	assert := require.New(t)
+
+	w := httptest.NewRecorder()
+	http.NewInternalRequestRouter(nil).ServeHTTP(w, httptest.NewRequest("GET", "/freshness", nil))
+	assert.Equal(404, w.Code)
+
+	called := false
+	w = httptest.NewRecorder()
+	http.NewInternalRequestRouter(nil).
+		WithFreshnessHandler(gohttp.HandlerFunc(func(w gohttp.ResponseWriter, r *gohttp.Request) {
+			called = true
+		})).
+		ServeHTTP(w, httptest.NewRequest("GET", "/freshness", nil))
+	assert.True(called)
+}
@@ -1,24 +1,37 @@
 package main
 
 import (
+	"bufio"
 	"flag"
+	"fmt"
+	"io"
 	"net"
 	"os"
+	"os/user"
 	"strconv"
 	"strings"
+	"text/tabwriter"
+	"time"
 
 	"github.com/callstats-io/ai-decision/service/src/config"
 	"github.com/callstats-io/ai-decision/service/src/flowdock"
+	"github.com/callstats-io/ai-decision/service/src/freshness"
+	"github.com/callstats-io/ai-decision/service/src/gateway"
 	"github.com/callstats-io/ai-decision/service/src/grpc"
 	"github.com/callstats-io/ai-decision/service/src/http"
+	"github.com/callstats-io/ai-decision/service/src/notification"
 	"github.com/callstats-io/ai-decision/service/src/service"
 	"github.com/callstats-io/ai-decision/service/src/storage"
+	"github.com/callstats-io/ai-decision/service/src/tracing"
 	"github.com/callstats-io/go-common/app"
+	"github.com/callstats-io/go-common/auth"
 	"github.com/callstats-io/go-common/log"
 	"github.com/callstats-io/go-common/metrics"
 	"github.com/callstats-io/go-common/postgres"
 	"github.com/callstats-io/go-common/postgres/migrations"
+	"github.com/callstats-io/go-common/vault"
 	raven "github.com/getsentry/raven-go"
+	"go.opencensus.io/trace"
 
 	"context"
 
@@ -28,11 +41,51 @@ import (
 
 var (
 	cmdRunServer = flag.Bool("server", true, "Start server for services")
-	cmdMigrate   = flag.String("migrate", "", "Run migrations, value should be a supported command for go-pg/migrations (e.g. init, up, down).")
+	cmdMigrate   = flag.String("migrate", "", "Run migrations, value should be a supported command for go-pg/migrations (e.g. init, up, down), or status, plan or verify to print the applied and pending migrations, the SQL of the pending migrations or the irreversible migrations.")
 	cmdDryRun    = flag.Bool("dry-run", false, "Read-only mode")
 	deleteList   = flag.String("delete", "", "Comma separated list of notifications to delete")
+	cmdExport    = flag.String("export", "", "Export message templates, messages and states to a JSONL file")
+	exportApps   = flag.String("export-apps", "", "Comma separated list of apps to export, all if empty")
+	exportFrom   = flag.String("export-from", "", "Export messages and states from this date (2006-01-02) or time (RFC 3339) on")
+	exportTo     = flag.String("export-to", "", "Export messages and states before this date (2006-01-02) or time (RFC 3339)")
+	cmdImport    = flag.String("import", "", "Import message templates, messages and states from a JSONL file written by -export")
+	importApps   = flag.String("import-apps", "", "Comma separated list of app id mappings from:to to import apps under other ids, e.g. 1234:1")
+	cmdAudit     = flag.Bool("audit", false, "Print the audit events of template, message and state mutations matching the audit flags, newest first")
+	auditApp     = flag.Int("audit-app", 0, "App to print the audit events of, all if 0")
+	auditActor   = flag.String("audit-actor", "", "Actor to print the audit events of, all if empty")
+	auditAction  = flag.String("audit-action", "", "Action to print the audit events of, e.g. message.delete, all if empty")
+	auditTarget  = flag.String("audit-target", "", "Target to print the audit events of, e.g. a message id, all if empty")
+	auditFrom    = flag.String("audit-from", "", "Print audit events from this date (2006-01-02) or time (RFC 3339) on")
+	auditTo      = flag.String("audit-to", "", "Print audit events up to this date (2006-01-02) or time (RFC 3339)")
+	auditLimit   = flag.Int("audit-limit", 0, "Newest audit events to print, all if 0")
+	cliActor     = flag.String("actor", "", "Actor the deletions and imports are audited with, the current user if empty")
+	cliReason    = flag.String("reason", "", "Reason the deletions and imports are audited with")
+	storageType  = flag.String("storage", storageTypePostgres, "Storage of the server, postgres or memory (not persisted, seeded with the message templates of the migrations)")
 )
 
+// Migration commands run by this service instead of go-pg/migrations
+const (
+	migrateStatus = "status"
+	migratePlan   = "plan"
+	migrateVerify = "verify"
+)
+
+// Storage types
+const (
+	storageTypePostgres = "postgres"
+	storageTypeMemory   = "memory"
+)
+
+// serviceStorage defines the storage of all services
+type serviceStorage interface {
+	service.MessageStorage
+	service.StateStorage
+	service.LeaseStorage
+	service.RunStorage
+	service.AuditStorage
+	freshness.Storage
+}
+
 func main() {
 	os.Exit(Serve())
 }
@@ -72,28 +125,30 @@ func Start(ctx context.Context) {
 	}
 
 	app := app.NewApp(ctx)
-	postgresClient, err := postgres.NewStandardClient(app.Context(), app.VaultClient(), &postgres.Options{
-		ConnectionTemplate: settings.PostgresConnectionTemplate,
-	})
-	if err != nil {
-		logger.Panic("Failed to create postgres client", log.Error(err))
+	var postgresClient postgres.Client
+	switch *storageType {
+	case storageTypePostgres:
+		postgresClient, err = newPostgresClient(app, settings)
+		if err != nil {
+			logger.Panic("Failed to create postgres client", log.Error(err))
+		}
+	case storageTypeMemory:
+		if *cmdMigrate != "" || *deleteList != "" || *cmdExport != "" || *cmdImport != "" || *cmdAudit {
+			logger.Panic("Migrations, deletions, exports, imports and audits require postgres storage", log.String("storage", *storageType))
+		}
+	default:
+		logger.Panic("Unknown storage", log.String("storage", *storageType))
 	}
 
 	if *cmdMigrate != "" {
 		logger.Info("Run migrations")
-		migrationOptions := &migrations.Options{
-			RootRole: settings.PostgresRootRole,
-			Meta: map[string]interface{}{
-				defined_migrations.MetaKeyReadRole: settings.PostgresReadOnlyRole,
-			},
-		}
-		if err := migrations.Migrate(app.Context(), postgresClient, *cmdMigrate, migrationOptions); err != nil {
+		if err := migrate(app.Context(), logger, postgresClient, *cmdMigrate, migrationOptions(settings)); err != nil {
 			logger.Panic("Failed to run migrations", log.Error(err))
 		}
 	}
 
 	if *deleteList != "" {
-		deletionCtx := app.Context()
+		deletionCtx := storage.WithAudit(app.Context(), cliAudit("delete"))
 		idList := parseStringFlag(string(*deleteList))
 		if *cmdDryRun {
 			logger.Info("Read-only mode of deletion running, no message will be deleted.")
@@ -105,43 +160,290 @@ func Start(ctx context.Context) {
 		}
 	}
 
+	if *cmdExport != "" {
+		logger.Info("Export data", log.String("file", *cmdExport))
+		counts, err := exportData(app.Context(), storage.NewPostgres(postgresClient), *cmdExport)
+		if err != nil {
+			logger.Panic("Failed to export data", log.Error(err))
+		}
+		logger.Info("Exported data", log.Int("templates", counts.Templates), log.Int("messages", counts.Messages), log.Int("states", counts.States))
+	}
+
+	if *cmdImport != "" {
+		logger.Info("Import data", log.String("file", *cmdImport))
+		importCtx := storage.WithAudit(app.Context(), cliAudit("import"))
+		counts, err := importData(importCtx, storage.NewPostgres(postgresClient), *cmdImport)
+		if err != nil {
+			logger.Panic("Failed to import data", log.Error(err))
+		}
+		logger.Info("Imported data", log.Int("templates", counts.Templates), log.Int("messages", counts.Messages), log.Int("states", counts.States))
+	}
+
+	if *cmdAudit {
+		if err := printAuditEvents(app.Context(), storage.NewPostgres(postgresClient), os.Stdout); err != nil {
+			logger.Panic("Failed to print audit events", log.Error(err))
+		}
+	}
+
 	if *cmdRunServer {
 		logger.Info("Run server")
 
+		if settings.TracingExporter != "" {
+			exporter, err := tracingExporter(settings)
+			if err != nil {
+				logger.Panic("Failed to create tracing exporter", log.Error(err))
+			}
+			tracing.Start(exporter)
+			defer tracing.Stop()
+			logger.Info("Tracing calls", log.String("exporter", settings.TracingExporter))
+		}
+
 		grpcLn, err := net.Listen("tcp", ":"+strconv.Itoa(settings.GRPCPort))
 		if err != nil {
 			logger.Panic("Failed to start gRPC listener", log.Int("grpcPort", settings.GRPCPort), log.Error(err))
 		}
 
-		storage := storage.NewPostgres(postgresClient)
+		serviceStorage, statusCheckers := newServiceStorage(app, logger, settings, postgresClient)
 		flowdockClient := flowdock.NewClient(settings.FlowdockToken)
-		messageService, err := service.NewAIDecisionMessageService(storage, flowdockClient)
+		notifier, err := notification.NewDispatcher(notificationOptions(logger, settings), map[string]notification.Notifier{
+			"flowdock": flowdockClient,
+		})
+		if err != nil {
+			logger.Panic("Error creating a new notification dispatcher", log.Error(err))
+		}
+		go notifier.Run(app.Context())
+
+		messageService, err := service.NewAIDecisionMessageService(serviceStorage, notifier)
 		if err != nil {
 			logger.Panic("Error creating a new ai-decision message service", log.Error(err))
 		}
 
-		stateService, err := service.NewAIDecisionStateService(storage)
+		stateService, err := service.NewAIDecisionStateService(serviceStorage)
 		if err != nil {
 			logger.Panic("Error creating a new ai-decision state service", log.Error(err))
 		}
+		stateService.WithStrictKeywords(settings.StateStrictKeywords)
 
-		app.WithHTTPPort(settings.HTTPStatusPort).
-			ServeHTTP(http.NewInternalRequestRouter(metrics.PrometheusEndpointWithoutCompression(), postgresStatusCheck(postgresClient)))
+		leaseService, err := service.NewAIDecisionLeaseService(serviceStorage)
+		if err != nil {
+			logger.Panic("Error creating a new ai-decision lease service", log.Error(err))
+		}
+
+		runService, err := service.NewAIDecisionRunService(serviceStorage)
+		if err != nil {
+			logger.Panic("Error creating a new ai-decision run service", log.Error(err))
+		}
+
+		auditService, err := service.NewAIDecisionAuditService(serviceStorage)
+		if err != nil {
+			logger.Panic("Error creating a new ai-decision audit service", log.Error(err))
+		}
+
+		router := http.NewInternalRequestRouter(metrics.PrometheusEndpointWithoutCompression(), statusCheckers...)
+		if settings.FreshnessCheckInterval > 0 {
+			monitor, err := freshness.NewMonitor(serviceStorage, notifier, freshnessOptions(logger, settings))
+			if err != nil {
+				logger.Panic("Error creating a new freshness monitor", log.Error(err))
+			}
+			go monitor.Run(app.Context())
+			router.WithFreshnessHandler(monitor)
+		}
+		app.WithHTTPPort(settings.HTTPStatusPort).ServeHTTP(router)
 
-		grpcServer, err := grpc.NewServer(ctx, messageService, stateService)
+		var authorizer *grpc.Authorizer
+		if settings.AuthDisabled {
+			logger.Warn("Calls are not authorized and their mutations are audited as " + grpc.ActorAnonymous + ", unset " + config.EnvAuthDisabled + " and set " + config.EnvAuthJWTSecret + " to authorize calls")
+		} else {
+			authorizer = grpc.NewAuthorizer([]byte(settings.AuthJWTSecret), auth.SigningMethodFromString(settings.AuthSigningMethod))
+		}
+		grpcServer, err := grpc.NewServer(ctx, authorizer, messageService, stateService, leaseService, runService, auditService)
 		if err != nil {
 			logger.Panic("Error creating a new gRPC server", log.Error(err))
 		}
+		if settings.GRPCHealthCheckInterval > 0 {
+			healthChecks := make([]grpc.StatusCheck, 0, len(statusCheckers))
+			for _, check := range statusCheckers {
+				healthChecks = append(healthChecks, grpc.StatusCheck(check))
+			}
+			grpcServer.WithHealthChecks(app.Context(), time.Duration(settings.GRPCHealthCheckInterval)*time.Second, healthChecks...)
+		}
+		if settings.GRPCReflection {
+			grpcServer.WithReflection()
+		}
 
 		go func() {
 			logger.Info("Starting GRPC server", log.Int("grpcPort", settings.GRPCPort))
 			grpcServer.Serve(app.Context(), grpcLn)
 		}()
 
+		if settings.GatewayPort > 0 {
+			gatewayLn, err := net.Listen("tcp", ":"+strconv.Itoa(settings.GatewayPort))
+			if err != nil {
+				logger.Panic("Failed to start gateway listener", log.Int("gatewayPort", settings.GatewayPort), log.Error(err))
+			}
+			gatewayServer, err := gateway.NewServer(app.Context(), "localhost:"+strconv.Itoa(settings.GRPCPort))
+			if err != nil {
+				logger.Panic("Error creating a new gateway server", log.Error(err))
+			}
+			go func() {
+				logger.Info("Starting gateway server", log.Int("gatewayPort", settings.GatewayPort))
+				gatewayServer.Serve(app.Context(), gatewayLn)
+			}()
+		}
+
 		<-app.Context().Done()
 	}
 }
 
+// newPostgresClient returns a client connecting with the plain DSN in local mode, otherwise with credentials from Vault
+func newPostgresClient(a *app.App, settings *config.Config) (postgres.Client, error) {
+	if !settings.Local {
+		return postgres.NewStandardClient(a.Context(), a.VaultClient(), &postgres.Options{
+			ConnectionTemplate: settings.PostgresConnectionTemplate,
+		})
+	}
+	if settings.PostgresDSN == "" {
+		return nil, fmt.Errorf("local mode requires %s unless the storage is %s", config.EnvPostgresDSN, storageTypeMemory)
+	}
+	return postgres.NewStaticClient(a.Context(), &postgres.Options{ConnectionTemplate: settings.PostgresDSN})
+}
+
+// newPostgresReadClient returns a client connecting to the read replica, or nil if no read replica is configured.
+// Outside of local mode, the credentials are read from Vault with the primary credentials unless a read creds path is set.
+func newPostgresReadClient(a *app.App, settings *config.Config) (postgres.Client, error) {
+	if settings.Local {
+		if settings.PostgresReadDSN == "" {
+			return nil, nil
+		}
+		return postgres.NewStaticClient(a.Context(), &postgres.Options{ConnectionTemplate: settings.PostgresReadDSN})
+	}
+	if settings.PostgresReadConnectionTemplate == "" {
+		return nil, nil
+	}
+
+	var vaultClient vault.PostgresClient = a.VaultClient()
+	if settings.VaultPostgresReadCredsPath != "" {
+		opts, err := vault.OptionsFromEnv()
+		if err != nil {
+			return nil, err
+		}
+		opts.PostgresCredsReadPath = settings.VaultPostgresReadCredsPath
+		standardClient, err := vault.NewStandardClient(a.Context(), opts)
+		if err != nil {
+			return nil, err
+		}
+		if vaultClient, err = vault.NewAutoRenewClient(a.Context(), standardClient); err != nil {
+			return nil, err
+		}
+	}
+	return postgres.NewStandardClient(a.Context(), vaultClient, &postgres.Options{
+		ConnectionTemplate: settings.PostgresReadConnectionTemplate,
+	})
+}
+
+// newServiceStorage returns the storage of the services with its status checks.
+// Without a postgres client, the storage is in memory and seeded with the data inserted by the migrations.
+func newServiceStorage(a *app.App, logger log.Logger, settings *config.Config, postgresClient postgres.Client) (serviceStorage, []http.StatusChecker) {
+	if postgresClient == nil {
+		logger.Warn("Using memory storage, nothing is persisted")
+		statements, err := defined_migrations.UpStatements(logger, migrationOptions(settings))
+		if err != nil {
+			logger.Panic("Failed to read migrations", log.Error(err))
+		}
+		memoryStorage := storage.NewMemory()
+		if err := memoryStorage.Seed(statements); err != nil {
+			logger.Panic("Failed to seed memory storage", log.Error(err))
+		}
+		return memoryStorage, nil
+	}
+
+	postgresStorage := storage.NewPostgres(postgresClient).WithCompressionThreshold(settings.StorageCompressionThreshold)
+	readClient, err := newPostgresReadClient(a, settings)
+	if err != nil {
+		logger.Panic("Failed to create postgres read client", log.Error(err))
+	}
+	if readClient != nil {
+		logger.Info("Reading from read replica", log.Int("maxStaleness", settings.PostgresReadMaxStaleness))
+		postgresStorage.WithReadReplica(readClient, time.Duration(settings.PostgresReadMaxStaleness)*time.Second)
+	}
+	if settings.StorageRecompressionInterval > 0 {
+		interval := time.Duration(settings.StorageRecompressionInterval) * time.Minute
+		go postgresStorage.RunRecompression(a.Context(), interval, storage.DefaultRecompressionBatchSize)
+	}
+	if settings.StateCompactionInterval > 0 {
+		interval := time.Duration(settings.StateCompactionInterval) * time.Minute
+		go postgresStorage.RunCompaction(a.Context(), interval)
+	}
+	if settings.StoragePartitionInterval > 0 {
+		interval := time.Duration(settings.StoragePartitionInterval) * time.Minute
+		go postgresStorage.RunPartitionMaintenance(a.Context(), interval, settings.StoragePartitionRetention)
+	}
+	return postgresStorage, []http.StatusChecker{postgresStatusCheck(postgresClient)}
+}
+
+func migrationOptions(settings *config.Config) *migrations.Options {
+	return &migrations.Options{
+		RootRole: settings.PostgresRootRole,
+		Meta: map[string]interface{}{
+			defined_migrations.MetaKeyReadRole: settings.PostgresReadOnlyRole,
+		},
+	}
+}
+
+// migrate runs a migration command. The status, plan and verify commands print their result to stdout,
+// all other commands are run by go-pg/migrations.
+func migrate(ctx context.Context, logger log.Logger, client postgres.Client, cmd string, opts *migrations.Options) error {
+	switch cmd {
+	case migrateStatus:
+		status, err := defined_migrations.CurrentStatus(ctx, client, logger, opts)
+		if err != nil {
+			return err
+		}
+		fmt.Printf("version %d\n", status.Version)
+		for _, m := range status.Applied {
+			if m.AppliedAt.IsZero() {
+				fmt.Printf("applied %d\n", m.Version)
+			} else {
+				fmt.Printf("applied %d at %s\n", m.Version, m.AppliedAt.Format(time.RFC3339))
+			}
+		}
+		for _, version := range status.Pending {
+			fmt.Printf("pending %d\n", version)
+		}
+	case migratePlan:
+		plan, err := defined_migrations.Plan(ctx, client, logger, opts)
+		if err != nil {
+			return err
+		}
+		for _, m := range plan {
+			fmt.Printf("-- migration %d\n", m.Version)
+			for _, statement := range m.Statements {
+				fmt.Println(strings.TrimSpace(statement))
+			}
+		}
+	case migrateVerify:
+		verifications, err := defined_migrations.Verify(ctx, client, logger, opts)
+		irreversible := 0
+		for _, v := range verifications {
+			if v.Irreversible() {
+				irreversible++
+				fmt.Printf("irreversible %d: %s\n", v.Version, v.Problem)
+			} else {
+				fmt.Printf("reversible %d\n", v.Version)
+			}
+		}
+		if err != nil {
+			return err
+		}
+		if irreversible > 0 {
+			return fmt.Errorf("%d irreversible migrations", irreversible)
+		}
+	default:
+		return migrations.Migrate(ctx, client, cmd, opts)
+	}
+	return nil
+}
+
 func postgresStatusCheck(postgresClient postgres.Client) func(context.Context) error {
 	return func(ctx context.Context) error {
 		// check connection to postgres works
@@ -153,6 +455,167 @@ func postgresStatusCheck(postgresClient postgres.Client) func(context.Context) e
 	}
 }
 
+// tracingExporter returns the span exporter of the settings
+func tracingExporter(settings *config.Config) (trace.Exporter, error) {
+	if settings.TracingExporter == config.TracingExporterAgent {
+		return tracing.NewAgentExporter(settings.TracingAgentAddress, settings.ServiceName)
+	}
+	return tracing.NewStdoutExporter(os.Stdout), nil
+}
+
+func notificationOptions(logger log.Logger, settings *config.Config) *notification.Options {
+	quietHours, err := notification.ParseQuietHours(settings.NotificationQuietHours)
+	if err != nil {
+		logger.Panic("Invalid notification quiet hours", log.Error(err))
+	}
+	timezones, err := notification.ParseTimezones(settings.NotificationTimezone, settings.NotificationAppTimezones)
+	if err != nil {
+		logger.Panic("Invalid notification timezones", log.Error(err))
+	}
+	return &notification.Options{
+		AppRate:          settings.NotificationAppRate,
+		AppBurst:         settings.NotificationAppBurst,
+		DestinationRate:  settings.NotificationDestinationRate,
+		DestinationBurst: settings.NotificationDestinationBurst,
+		QuietHours:       quietHours,
+		Timezones:        timezones,
+	}
+}
+
+func freshnessOptions(logger log.Logger, settings *config.Config) *freshness.Options {
+	keywordMaxAges, err := freshness.ParseKeywordMaxAges(settings.FreshnessKeywordMaxAges)
+	if err != nil {
+		logger.Panic("Invalid freshness keyword max ages", log.Error(err))
+	}
+	return &freshness.Options{
+		MaxAge:         time.Duration(settings.FreshnessMaxAge) * time.Minute,
+		KeywordMaxAges: keywordMaxAges,
+		Interval:       time.Duration(settings.FreshnessCheckInterval) * time.Minute,
+	}
+}
+
+// exportData exports the data selected by the export flags to a file
+func exportData(ctx context.Context, s *storage.Postgres, path string) (*storage.TransferCounts, error) {
+	filter := &storage.TransferFilter{}
+	if *exportApps != "" {
+		filter.AppIDs = parseStringFlag(*exportApps)
+	}
+	var err error
+	if filter.From, err = parseTimeFlag(*exportFrom); err != nil {
+		return nil, fmt.Errorf("export-from: %s", err)
+	}
+	if filter.To, err = parseTimeFlag(*exportTo); err != nil {
+		return nil, fmt.Errorf("export-to: %s", err)
+	}
+
+	f, err := os.Create(path)
+	if err != nil {
+		return nil, err
+	}
+	w := bufio.NewWriter(f)
+	counts, err := s.Export(ctx, w, filter)
+	if err == nil {
+		err = w.Flush()
+	}
+	if closeErr := f.Close(); err == nil {
+		err = closeErr
+	}
+	return counts, err
+}
+
+// importData imports a file written by exportData with the apps mapped by the import flags
+func importData(ctx context.Context, s *storage.Postgres, path string) (*storage.TransferCounts, error) {
+	appIDs, err := parseAppIDMappingFlag(*importApps)
+	if err != nil {
+		return nil, fmt.Errorf("import-apps: %s", err)
+	}
+	f, err := os.Open(path)
+	if err != nil {
+		return nil, err
+	}
+	defer f.Close()
+	return s.Import(ctx, bufio.NewReader(f), appIDs)
+}
+
+// cliAudit returns the audit of the mutations of a command line flag, made by the actor flag or the current user
+func cliAudit(cmd string) storage.Audit {
+	actor := *cliActor
+	if actor == "" {
+		if u, err := user.Current(); err == nil {
+			actor = u.Username
+		}
+	}
+	return storage.Audit{Actor: actor, RPC: "cli:" + cmd, Reason: *cliReason}
+}
+
+// printAuditEvents prints the audit events selected by the audit flags as tab separated columns with a header
+func printAuditEvents(ctx context.Context, s *storage.Postgres, out io.Writer) error {
+	filter := &storage.AuditFilter{
+		AppID:  int32(*auditApp),
+		Actor:  *auditActor,
+		Action: *auditAction,
+		Target: *auditTarget,
+		Limit:  *auditLimit,
+	}
+	var err error
+	if filter.From, err = parseTimeFlag(*auditFrom); err != nil {
+		return fmt.Errorf("audit-from: %s", err)
+	}
+	if filter.To, err = parseTimeFlag(*auditTo); err != nil {
+		return fmt.Errorf("audit-to: %s", err)
+	}
+
+	events, err := s.ListAuditEvents(ctx, filter)
+	if err != nil && err != storage.ErrNotFound {
+		return err
+	}
+	w := tabwriter.NewWriter(out, 0, 8, 2, ' ', 0)
+	fmt.Fprintln(w, "OCCURRED AT\tACTOR\tRPC\tAPP\tACTION\tTARGET\tCOUNT\tBEFORE\tAFTER\tREASON")
+	for _, e := range events {
+		fmt.Fprintf(w, "%s\t%s\t%s\t%d\t%s\t%s\t%d\t%s\t%s\t%s\n", e.OccurredAt.UTC().Format(time.RFC3339),
+			e.Actor, e.RPC, e.AppID, e.Action, e.Target, e.Count, e.BeforeHash, e.AfterHash, e.Reason)
+	}
+	return w.Flush()
+}
+
+// parseTimeFlag parses a date or RFC 3339 time flag, nil if empty
+func parseTimeFlag(str string) (*time.Time, error) {
+	if str == "" {
+		return nil, nil
+	}
+	t, err := time.Parse("2006-01-02", str)
+	if err != nil {
+		if t, err = time.Parse(time.RFC3339, str); err != nil {
+			return nil, err
+		}
+	}
+	return &t, nil
+}
+
+// parseAppIDMappingFlag converts a comma separated list of from:to app ids to a map
+func parseAppIDMappingFlag(str string) (map[int32]int32, error) {
+	mapping := map[int32]int32{}
+	if str == "" {
+		return mapping, nil
+	}
+	for _, pair := range strings.Split(str, ",") {
+		ids := strings.Split(pair, ":")
+		if len(ids) != 2 {
+			return nil, fmt.Errorf("invalid mapping %q", pair)
+		}
+		from, err := strconv.Atoi(ids[0])
+		if err != nil {
+			return nil, err
+		}
+		to, err := strconv.Atoi(ids[1])
+		if err != nil {
+			return nil, err
+		}
+		mapping[int32(from)] = int32(to)
+	}
+	return mapping, nil
+}
+
 // parseStringFlag converts string flag to int slice
 func parseStringFlag(str string) []int32 {
 	splitStr := strings.Split(str, ",")
@@ -17,6 +17,8 @@ import (
 // The wrapping is needed to ensure an error is raised if the datatype is different from expected.
 type TemplateData struct {
 	values map[string]interface{}
+	// invalidKey is the key of the last value that was missing or of a different datatype
+	invalidKey string
 }
 
 // NewTemplateData returns a new *TemplateData initialized with the provided values
@@ -53,6 +55,7 @@ func (d *TemplateData) Number(key string) (interface{}, error) {
 	if v, ok := d.values[key].(int64); ok {
 		return v, nil
 	}
+	d.invalidKey = key
 	return 0, errors.New("invalid number")
 }
 
@@ -61,6 +64,7 @@ func (d *TemplateData) String(key string) (string, error) {
 	if v, ok := d.values[key].(string); ok {
 		return v, nil
 	}
+	d.invalidKey = key
 	return "", errors.New("invalid string")
 }
 
@@ -69,6 +73,7 @@ func (d *TemplateData) Date(key string) (string, error) {
 	// Cast to float64, as JSON number on the wire is float
 	v, ok := d.values[key].(float64)
 	if !ok {
+		d.invalidKey = key
 		return "", errors.New("invalid timestamp value")
 	}
 	t := time.Unix(int64(v), 0)
@@ -98,11 +103,15 @@ func NewTemplate(tmpl *storage.MessageTemplate) (*Template, error) {
 	}, nil
 }
 
-// RenderString returns the rendered value of this template as a string or an error
+// RenderString returns the rendered value of this template as a string or a *RenderError
 func (t *Template) RenderString(data *TemplateData) (string, error) {
 	t.buffer.Reset()
+	if data == nil {
+		data = NewTemplateData(nil)
+	}
+	data.invalidKey = ""
 	if err := t.template.Execute(t.buffer, data); err != nil {
-		return "", err
+		return "", &RenderError{Type: t.tmplType, Version: t.tmplVersion, Field: data.invalidKey, Err: err}
 	}
 	return t.buffer.String(), nil
 }
@@ -116,3 +125,16 @@ func (t *Template) Version() int32 { // func to ensure immutability after creati
 func (t *Template) Type() string { // func to ensure immutability after creation
 	return t.tmplType
 }
+
+// RenderError is the error of rendering a template with data, Field is the key of the data that was missing or of a
+// different datatype than the template expects, empty if the template failed otherwise
+type RenderError struct {
+	Type    string
+	Version int32
+	Field   string
+	Err     error
+}
+
+func (e *RenderError) Error() string {
+	return e.Err.Error()
+}
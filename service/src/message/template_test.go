@@ -24,6 +24,7 @@ func TestTemplateRender(t *testing.T) {
 		Data        *message.TemplateData
 		ExpMsg      string
 		ExpErrMsg   string
+		ExpField    string
 	}{
 		{
 			Description: "valid data string",
@@ -66,12 +67,14 @@ func TestTemplateRender(t *testing.T) {
 			Template:    makeTemplate(t, "asd", 1, `{{.String "val"}}`),
 			Data:        message.NewTemplateData(map[string]interface{}{"val": 123}),
 			ExpErrMsg:   "template: 1:1:2: executing \"1\" at <.String>: error calling String: invalid string",
+			ExpField:    "val",
 		},
 		{
 			Description: "invalid data number",
 			Template:    makeTemplate(t, "asd", 1, `{{.Number "val"}}`),
 			Data:        message.NewTemplateData(map[string]interface{}{"val": "def"}),
 			ExpErrMsg:   "template: 1:1:2: executing \"1\" at <.Number>: error calling Number: invalid number",
+			ExpField:    "val",
 		},
 		{
 			Description: "additional data",
@@ -79,6 +82,13 @@ func TestTemplateRender(t *testing.T) {
 			Data:        message.NewTemplateData(map[string]interface{}{"val": "def", "numnum": 456}),
 			ExpMsg:      "def",
 		},
+		{
+			Description: "missing data",
+			Template:    makeTemplate(t, "asd", 1, `{{.String "val"}} {{.Number "num"}}`),
+			Data:        message.NewTemplateData(map[string]interface{}{"val": "def"}),
+			ExpErrMsg:   "template: 1:1:20: executing \"1\" at <.Number>: error calling Number: invalid number",
+			ExpField:    "num",
+		},
 		{
 			Description: "timestamp data",
 			Template:    makeTemplate(t, "asd", 1, `{{.Date "val"}}`),
@@ -90,6 +100,7 @@ func TestTemplateRender(t *testing.T) {
 			Template:    makeTemplate(t, "asd", 1, `{{.Date "val"}}`),
 			Data:        message.NewTemplateData(map[string]interface{}{"val": ""}),
 			ExpErrMsg:   "template: 1:1:2: executing \"1\" at <.Date>: error calling Date: invalid timestamp value",
+			ExpField:    "val",
 		},
 	} {
 		t.Run(test.Description, func(t *testing.T) {
@@ -98,6 +109,11 @@ func TestTemplateRender(t *testing.T) {
 			rendered, err := test.Template.RenderString(test.Data)
 			if test.ExpErrMsg != "" {
 				assert.EqualError(err, test.ExpErrMsg)
+				renderErr, ok := err.(*message.RenderError)
+				assert.True(ok)
+				assert.Equal("asd", renderErr.Type)
+				assert.Equal(int32(1), renderErr.Version)
+				assert.Equal(test.ExpField, renderErr.Field)
 			} else {
 				assert.Nil(err)
 				assert.Equal(test.ExpMsg, rendered)
@@ -0,0 +1,286 @@
+package notification
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"sort"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/callstats-io/go-common/log"
+)
+
+// SummaryMessageType is the message type used for the collapsed summary of held back notifications
+const SummaryMessageType = "QuietHoursSummary"
+
+// default options
+const (
+	DefaultFlushInterval = time.Minute
+)
+
+// Errors
+var (
+	ErrAppRateLimited         = errors.New("app notification rate limit exceeded")
+	ErrDestinationRateLimited = errors.New("destination notification rate limit exceeded")
+)
+
+// Notifier defines the interface of a notification destination, e.g. the flowdock client
+type Notifier interface {
+	SendAiNotificationMessage(ctx context.Context, appID int32, messageType string, renderedMsg string) error
+}
+
+// Options contains the rate limits and quiet hours a Dispatcher enforces.
+// Rates are notifications per hour, a non-positive rate disables the respective limit.
+type Options struct {
+	AppRate          int
+	AppBurst         int
+	DestinationRate  int
+	DestinationBurst int
+	QuietHours       *QuietHours
+	Timezones        *Timezones
+	FlushInterval    time.Duration
+}
+
+// heldNotifications contains the notifications of a single app held back during quiet hours
+type heldNotifications struct {
+	total  int
+	counts map[string]int
+	latest map[string]string
+}
+
+// Dispatcher rate limits notifications per app and per destination and holds back notifications during
+// the quiet hours of the app. Held back notifications are collapsed into a summary once the quiet hours end.
+type Dispatcher struct {
+	opts         *Options
+	destinations map[string]Notifier
+	names        []string
+	now          func() time.Time
+
+	lock        sync.Mutex
+	appBuckets  map[int32]*tokenBucket
+	destBuckets map[string]*tokenBucket
+	held        map[int32]*heldNotifications
+}
+
+var _ = Notifier(&Dispatcher{})
+
+// NewDispatcher returns a new Dispatcher sending notifications to the named destinations
+func NewDispatcher(opts *Options, destinations map[string]Notifier) (*Dispatcher, error) {
+	if err := registerMetrics(); err != nil {
+		return nil, err
+	}
+	if opts.FlushInterval <= 0 {
+		opts.FlushInterval = DefaultFlushInterval
+	}
+	names := make([]string, 0, len(destinations))
+	for name := range destinations {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+
+	return &Dispatcher{
+		opts:         opts,
+		destinations: destinations,
+		names:        names,
+		now:          time.Now,
+		appBuckets:   map[int32]*tokenBucket{},
+		destBuckets:  map[string]*tokenBucket{},
+		held:         map[int32]*heldNotifications{},
+	}, nil
+}
+
+// WithClock sets the time source of this dispatcher. Mainly used in tests.
+func (d *Dispatcher) WithClock(now func() time.Time) *Dispatcher {
+	d.now = now
+	return d
+}
+
+// SendAiNotificationMessage sends the notification to all destinations unless the app is within its quiet hours
+// or a rate limit is exceeded. Notifications during quiet hours are held back and nil is returned.
+// The rate limits are taken under the lock of the dispatcher, the destinations are called without it.
+func (d *Dispatcher) SendAiNotificationMessage(ctx context.Context, appID int32, messageType string, renderedMsg string) error {
+	names, err := d.take(appID, messageType, renderedMsg)
+	for _, name := range names {
+		if sendErr := d.destinations[name].SendAiNotificationMessage(ctx, appID, messageType, renderedMsg); sendErr != nil {
+			notificationCounter.WithLabelValues(name, OutcomeFailed).Inc()
+			if err == nil {
+				err = sendErr
+			}
+			continue
+		}
+		notificationCounter.WithLabelValues(name, OutcomeSent).Inc()
+	}
+	return err
+}
+
+// take holds back the notification or takes the rate limit tokens of sending it, returning the destinations
+// with a token and ErrDestinationRateLimited if any destination has none
+func (d *Dispatcher) take(appID int32, messageType string, renderedMsg string) ([]string, error) {
+	d.lock.Lock()
+	defer d.lock.Unlock()
+
+	now := d.now()
+	if d.opts.QuietHours.Contains(now.In(d.opts.Timezones.Location(appID))) {
+		d.hold(appID, messageType, renderedMsg)
+		return nil, nil
+	}
+
+	if !d.takeApp(appID, now) {
+		for _, name := range d.names {
+			notificationCounter.WithLabelValues(name, OutcomeThrottled).Inc()
+		}
+		return nil, ErrAppRateLimited
+	}
+
+	var err error
+	names := make([]string, 0, len(d.names))
+	for _, name := range d.names {
+		if !d.takeDestination(name, now) {
+			notificationCounter.WithLabelValues(name, OutcomeThrottled).Inc()
+			err = ErrDestinationRateLimited
+			continue
+		}
+		names = append(names, name)
+	}
+	return names, err
+}
+
+// QueueSize returns the number of notifications currently held back for the given app
+func (d *Dispatcher) QueueSize(appID int32) int {
+	d.lock.Lock()
+	defer d.lock.Unlock()
+	if h, ok := d.held[appID]; ok {
+		return h.total
+	}
+	return 0
+}
+
+// Run flushes held back notifications periodically until the context is done
+func (d *Dispatcher) Run(ctx context.Context) {
+	ticker := time.NewTicker(d.opts.FlushInterval)
+	defer ticker.Stop()
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+			d.Flush(ctx)
+		}
+	}
+}
+
+// Flush sends a summary of the held back notifications of every app whose quiet hours have ended.
+// Summaries are not subject to rate limits as at most one is sent per app and quiet hour window.
+// The notifications of an app are held back again if its summary could not be sent to any destination.
+func (d *Dispatcher) Flush(ctx context.Context) {
+	for appID, h := range d.due() {
+		summary := h.summary()
+		sent := len(d.names) == 0
+		for _, name := range d.names {
+			if err := d.destinations[name].SendAiNotificationMessage(ctx, appID, SummaryMessageType, summary); err != nil {
+				log.FromContext(ctx).Warn("failed to send notification summary",
+					log.String(LabelDestination, name), log.Int(LabelAppID, int(appID)), log.Error(err))
+				notificationCounter.WithLabelValues(name, OutcomeFailed).Inc()
+				continue
+			}
+			notificationCounter.WithLabelValues(name, OutcomeSummary).Inc()
+			sent = true
+		}
+		if !sent {
+			d.requeue(appID, h)
+		}
+	}
+}
+
+// due removes and returns the held back notifications of the apps whose quiet hours have ended
+func (d *Dispatcher) due() map[int32]*heldNotifications {
+	d.lock.Lock()
+	defer d.lock.Unlock()
+
+	now := d.now()
+	due := map[int32]*heldNotifications{}
+	for appID, h := range d.held {
+		if d.opts.QuietHours.Contains(now.In(d.opts.Timezones.Location(appID))) {
+			continue
+		}
+		due[appID] = h
+		delete(d.held, appID)
+		queueSizeGauge.DeleteLabelValues(strconv.Itoa(int(appID)))
+	}
+	return due
+}
+
+// requeue holds back the notifications of an unsent summary again, before those held back since
+func (d *Dispatcher) requeue(appID int32, h *heldNotifications) {
+	d.lock.Lock()
+	defer d.lock.Unlock()
+
+	if held, ok := d.held[appID]; ok {
+		h.total += held.total
+		for t, count := range held.counts {
+			h.counts[t] += count
+			h.latest[t] = held.latest[t]
+		}
+	}
+	d.held[appID] = h
+	queueSizeGauge.WithLabelValues(strconv.Itoa(int(appID))).Set(float64(h.total))
+}
+
+func (d *Dispatcher) hold(appID int32, messageType string, renderedMsg string) {
+	h, ok := d.held[appID]
+	if !ok {
+		h = &heldNotifications{counts: map[string]int{}, latest: map[string]string{}}
+		d.held[appID] = h
+	}
+	h.total++
+	h.counts[messageType]++
+	h.latest[messageType] = renderedMsg
+
+	for _, name := range d.names {
+		notificationCounter.WithLabelValues(name, OutcomeHeld).Inc()
+	}
+	queueSizeGauge.WithLabelValues(strconv.Itoa(int(appID))).Set(float64(h.total))
+}
+
+func (d *Dispatcher) takeApp(appID int32, now time.Time) bool {
+	if d.opts.AppRate <= 0 {
+		return true
+	}
+	b, ok := d.appBuckets[appID]
+	if !ok {
+		b = newTokenBucket(d.opts.AppRate, d.opts.AppBurst, now)
+		d.appBuckets[appID] = b
+	}
+	return b.take(now)
+}
+
+func (d *Dispatcher) takeDestination(name string, now time.Time) bool {
+	if d.opts.DestinationRate <= 0 {
+		return true
+	}
+	b, ok := d.destBuckets[name]
+	if !ok {
+		b = newTokenBucket(d.opts.DestinationRate, d.opts.DestinationBurst, now)
+		d.destBuckets[name] = b
+	}
+	return b.take(now)
+}
+
+// summary renders the held back notifications as a single message listing the count and latest message per type.
+// Lines are separated with a literal \n to match the rendered message templates.
+func (h *heldNotifications) summary() string {
+	types := make([]string, 0, len(h.counts))
+	for t := range h.counts {
+		types = append(types, t)
+	}
+	sort.Strings(types)
+
+	lines := []string{fmt.Sprintf("%d notifications were held back during quiet hours.", h.total)}
+	for _, t := range types {
+		lines = append(lines, fmt.Sprintf("%dx %s, latest: %s", h.counts[t], t, h.latest[t]))
+	}
+	return strings.Join(lines, `\n`)
+}
@@ -0,0 +1,65 @@
+package notification
+
+import (
+	"github.com/prometheus/client_golang/prometheus"
+)
+
+// metric labels
+const (
+	LabelDestination = "destination"
+	LabelOutcome     = "outcome"
+	LabelAppID       = "app_id"
+)
+
+// notification outcomes
+const (
+	OutcomeSent      = "sent"
+	OutcomeFailed    = "failed"
+	OutcomeThrottled = "throttled"
+	OutcomeHeld      = "held"
+	OutcomeSummary   = "summary"
+)
+
+var (
+	notificationCounter *prometheus.CounterVec
+	queueSizeGauge      *prometheus.GaugeVec
+)
+
+// registerMetrics initializes the notification metrics and registers them to Prometheus.
+// Already registered metrics are reused to allow multiple dispatchers, e.g. in tests.
+func registerMetrics() error {
+	notificationCounter = prometheus.NewCounterVec(
+		prometheus.CounterOpts{
+			Namespace: "aid",
+			Subsystem: "notification",
+			Name:      "count",
+			Help:      "Total number of notifications by destination and outcome.",
+		},
+		[]string{LabelDestination, LabelOutcome},
+	)
+	if err := prometheus.Register(notificationCounter); err != nil {
+		are, ok := err.(prometheus.AlreadyRegisteredError)
+		if !ok {
+			return err
+		}
+		notificationCounter = are.ExistingCollector.(*prometheus.CounterVec)
+	}
+
+	queueSizeGauge = prometheus.NewGaugeVec(
+		prometheus.GaugeOpts{
+			Namespace: "aid",
+			Subsystem: "notification",
+			Name:      "queue_size",
+			Help:      "Number of notifications held back during quiet hours by app.",
+		},
+		[]string{LabelAppID},
+	)
+	if err := prometheus.Register(queueSizeGauge); err != nil {
+		are, ok := err.(prometheus.AlreadyRegisteredError)
+		if !ok {
+			return err
+		}
+		queueSizeGauge = are.ExistingCollector.(*prometheus.GaugeVec)
+	}
+	return nil
+}
@@ -0,0 +1,250 @@
+package notification_test
+
+import (
+	"context"
+	"errors"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/callstats-io/ai-decision/service/src/notification"
+	"github.com/stretchr/testify/require"
+)
+
+type sentNotification struct {
+	AppID       int32
+	MessageType string
+	Message     string
+}
+
+type fakeNotifier struct {
+	sent []sentNotification
+	err  error
+}
+
+func (n *fakeNotifier) SendAiNotificationMessage(_ context.Context, appID int32, messageType string, renderedMsg string) error {
+	if n.err != nil {
+		return n.err
+	}
+	n.sent = append(n.sent, sentNotification{AppID: appID, MessageType: messageType, Message: renderedMsg})
+	return nil
+}
+
+func mustParseTimezones(t *testing.T, defaultTZ, appTZs string) *notification.Timezones {
+	tz, err := notification.ParseTimezones(defaultTZ, appTZs)
+	require.Nil(t, err)
+	return tz
+}
+
+func TestParseQuietHours(t *testing.T) {
+	for _, test := range []struct {
+		Description string
+		Value       string
+		ExpErr      bool
+		ExpNil      bool
+		Inside      []string
+		Outside     []string
+	}{
+		{Description: "empty disables quiet hours", Value: "", ExpNil: true, Outside: []string{"00:00", "12:00"}},
+		{Description: "same day window", Value: "09:00-17:30", Inside: []string{"09:00", "17:29"}, Outside: []string{"08:59", "17:30"}},
+		{Description: "window over midnight", Value: "22:00-07:00", Inside: []string{"22:00", "23:59", "00:00", "06:59"}, Outside: []string{"07:00", "21:59"}},
+		{Description: "missing end", Value: "22:00", ExpErr: true},
+		{Description: "invalid hour", Value: "25:00-07:00", ExpErr: true},
+		{Description: "invalid minute", Value: "22:00-07:60", ExpErr: true},
+		{Description: "empty window", Value: "07:00-07:00", ExpErr: true},
+	} {
+		test := test
+		t.Run(test.Description, func(t *testing.T) {
+			assert := require.New(t)
+			q, err := notification.ParseQuietHours(test.Value)
+			if test.ExpErr {
+				assert.NotNil(err)
+				return
+			}
+			assert.Nil(err)
+			assert.Equal(test.ExpNil, q == nil)
+			for _, clock := range test.Inside {
+				ts, err := time.Parse("15:04", clock)
+				assert.Nil(err)
+				assert.True(q.Contains(ts), clock)
+			}
+			for _, clock := range test.Outside {
+				ts, err := time.Parse("15:04", clock)
+				assert.Nil(err)
+				assert.False(q.Contains(ts), clock)
+			}
+		})
+	}
+}
+
+func TestParseTimezones(t *testing.T) {
+	assert := require.New(t)
+
+	tz, err := notification.ParseTimezones("", "123=Europe/Helsinki, 456=America/New_York")
+	assert.Nil(err)
+	assert.Equal("UTC", tz.Location(1).String())
+	assert.Equal("Europe/Helsinki", tz.Location(123).String())
+	assert.Equal("America/New_York", tz.Location(456).String())
+
+	_, err = notification.ParseTimezones("Not/AZone", "")
+	assert.NotNil(err)
+	_, err = notification.ParseTimezones("UTC", "abc=Europe/Helsinki")
+	assert.NotNil(err)
+	_, err = notification.ParseTimezones("UTC", "123")
+	assert.NotNil(err)
+}
+
+func TestDispatcherRateLimits(t *testing.T) {
+	now := time.Date(2018, 10, 1, 12, 0, 0, 0, time.UTC)
+	clock := func() time.Time { return now }
+
+	t.Run("per app", func(t *testing.T) {
+		assert := require.New(t)
+		dest := &fakeNotifier{}
+		d, err := notification.NewDispatcher(&notification.Options{AppRate: 1, AppBurst: 2}, map[string]notification.Notifier{"test": dest})
+		assert.Nil(err)
+		d.WithClock(clock)
+
+		assert.Nil(d.SendAiNotificationMessage(context.Background(), 1, "type", "msg1"))
+		assert.Nil(d.SendAiNotificationMessage(context.Background(), 1, "type", "msg2"))
+		assert.Equal(notification.ErrAppRateLimited, d.SendAiNotificationMessage(context.Background(), 1, "type", "msg3"))
+		// other apps have their own bucket
+		assert.Nil(d.SendAiNotificationMessage(context.Background(), 2, "type", "msg4"))
+		assert.Len(dest.sent, 3)
+
+		// a token is refilled after an hour
+		now = now.Add(time.Hour)
+		assert.Nil(d.SendAiNotificationMessage(context.Background(), 1, "type", "msg5"))
+		assert.Equal(notification.ErrAppRateLimited, d.SendAiNotificationMessage(context.Background(), 1, "type", "msg6"))
+		assert.Len(dest.sent, 4)
+	})
+
+	t.Run("per destination", func(t *testing.T) {
+		assert := require.New(t)
+		limited := &fakeNotifier{}
+		d, err := notification.NewDispatcher(&notification.Options{DestinationRate: 1, DestinationBurst: 1}, map[string]notification.Notifier{"limited": limited})
+		assert.Nil(err)
+		d.WithClock(clock)
+
+		assert.Nil(d.SendAiNotificationMessage(context.Background(), 1, "type", "msg1"))
+		assert.Equal(notification.ErrDestinationRateLimited, d.SendAiNotificationMessage(context.Background(), 2, "type", "msg2"))
+		assert.Len(limited.sent, 1)
+	})
+}
+
+func TestDispatcherQuietHours(t *testing.T) {
+	assert := require.New(t)
+
+	quietHours, err := notification.ParseQuietHours("22:00-07:00")
+	assert.Nil(err)
+	dest := &fakeNotifier{}
+	d, err := notification.NewDispatcher(&notification.Options{
+		AppRate:    1,
+		AppBurst:   1,
+		QuietHours: quietHours,
+		Timezones:  mustParseTimezones(t, "UTC", "123=Europe/Helsinki"),
+	}, map[string]notification.Notifier{"test": dest})
+	assert.Nil(err)
+
+	// 21:00 UTC is 00:00 in Helsinki, app 123 is within its quiet hours but app 456 is not
+	now := time.Date(2018, 10, 1, 21, 0, 0, 0, time.UTC)
+	d.WithClock(func() time.Time { return now })
+
+	assert.Nil(d.SendAiNotificationMessage(context.Background(), 456, "Type1", "not held"))
+	for i := 0; i < 3; i++ {
+		// held back notifications are not rate limited
+		assert.Nil(d.SendAiNotificationMessage(context.Background(), 123, "Type1", "held type1"))
+	}
+	assert.Nil(d.SendAiNotificationMessage(context.Background(), 123, "Type2", "held type2"))
+	assert.Len(dest.sent, 1)
+	assert.Equal(4, d.QueueSize(123))
+	assert.Equal(0, d.QueueSize(456))
+
+	// nothing is flushed while quiet hours continue
+	d.Flush(context.Background())
+	assert.Len(dest.sent, 1)
+	assert.Equal(4, d.QueueSize(123))
+
+	// 04:00 UTC is 07:00 in Helsinki
+	now = time.Date(2018, 10, 2, 4, 0, 0, 0, time.UTC)
+	d.Flush(context.Background())
+	assert.Equal(0, d.QueueSize(123))
+	assert.Len(dest.sent, 2)
+	summary := dest.sent[1]
+	assert.Equal(int32(123), summary.AppID)
+	assert.Equal(notification.SummaryMessageType, summary.MessageType)
+	assert.True(strings.HasPrefix(summary.Message, "4 notifications were held back during quiet hours."))
+	assert.Contains(summary.Message, "3x Type1, latest: held type1")
+	assert.Contains(summary.Message, "1x Type2, latest: held type2")
+
+	// flushing again does not resend the summary
+	d.Flush(context.Background())
+	assert.Len(dest.sent, 2)
+}
+
+func TestDispatcherFlushFailure(t *testing.T) {
+	assert := require.New(t)
+
+	quietHours, err := notification.ParseQuietHours("22:00-07:00")
+	assert.Nil(err)
+	dest := &fakeNotifier{err: errors.New("EXPECTED FLUSH TEST ERROR")}
+	d, err := notification.NewDispatcher(&notification.Options{
+		QuietHours: quietHours,
+		Timezones:  mustParseTimezones(t, "UTC", ""),
+	}, map[string]notification.Notifier{"test": dest})
+	assert.Nil(err)
+
+	now := time.Date(2018, 10, 1, 23, 0, 0, 0, time.UTC)
+	d.WithClock(func() time.Time { return now })
+	assert.Nil(d.SendAiNotificationMessage(context.Background(), 123, "Type1", "held type1"))
+	assert.Nil(d.SendAiNotificationMessage(context.Background(), 123, "Type1", "held type1"))
+
+	// the notifications are held back again if the summary is not sent to any destination
+	now = time.Date(2018, 10, 2, 8, 0, 0, 0, time.UTC)
+	d.Flush(context.Background())
+	assert.Equal(2, d.QueueSize(123))
+
+	dest.err = nil
+	d.Flush(context.Background())
+	assert.Equal(0, d.QueueSize(123))
+	assert.Len(dest.sent, 1)
+	assert.True(strings.HasPrefix(dest.sent[0].Message, "2 notifications were held back during quiet hours."))
+}
+
+// blockingNotifier blocks sending until released
+type blockingNotifier struct {
+	sending chan struct{}
+	release chan struct{}
+}
+
+func (n *blockingNotifier) SendAiNotificationMessage(context.Context, int32, string, string) error {
+	n.sending <- struct{}{}
+	<-n.release
+	return nil
+}
+
+func TestDispatcherSendWithoutLock(t *testing.T) {
+	assert := require.New(t)
+
+	dest := &blockingNotifier{sending: make(chan struct{}), release: make(chan struct{})}
+	d, err := notification.NewDispatcher(&notification.Options{}, map[string]notification.Notifier{"test": dest})
+	assert.Nil(err)
+
+	done := make(chan error, 2)
+	for _, appID := range []int32{1, 2} {
+		go func(appID int32) {
+			done <- d.SendAiNotificationMessage(context.Background(), appID, "type", "msg")
+		}(appID)
+	}
+	// both notifications are sent concurrently
+	for i := 0; i < 2; i++ {
+		select {
+		case <-dest.sending:
+		case <-time.After(5 * time.Second):
+			assert.FailNow("notifications are not sent concurrently")
+		}
+	}
+	close(dest.release)
+	assert.Nil(<-done)
+	assert.Nil(<-done)
+}
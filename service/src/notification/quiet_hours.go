@@ -0,0 +1,119 @@
+package notification
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// QuietHours defines a daily window during which notifications are held back.
+// Start and End are offsets from local midnight, a window where End is before Start wraps over midnight.
+type QuietHours struct {
+	Start time.Duration
+	End   time.Duration
+}
+
+// ParseQuietHours parses a window in the format "HH:MM-HH:MM", e.g. "22:00-07:00".
+// An empty string returns nil, which disables quiet hours.
+func ParseQuietHours(s string) (*QuietHours, error) {
+	if s == "" {
+		return nil, nil
+	}
+	parts := strings.Split(s, "-")
+	if len(parts) != 2 {
+		return nil, fmt.Errorf("invalid quiet hours %q, expected HH:MM-HH:MM", s)
+	}
+	start, err := parseClock(parts[0])
+	if err != nil {
+		return nil, err
+	}
+	end, err := parseClock(parts[1])
+	if err != nil {
+		return nil, err
+	}
+	if start == end {
+		return nil, fmt.Errorf("invalid quiet hours %q, start and end cannot be equal", s)
+	}
+	return &QuietHours{Start: start, End: end}, nil
+}
+
+func parseClock(s string) (time.Duration, error) {
+	parts := strings.Split(strings.TrimSpace(s), ":")
+	if len(parts) != 2 {
+		return 0, fmt.Errorf("invalid time of day %q, expected HH:MM", s)
+	}
+	h, err := strconv.Atoi(parts[0])
+	if err != nil || h < 0 || h > 23 {
+		return 0, fmt.Errorf("invalid hour in %q", s)
+	}
+	m, err := strconv.Atoi(parts[1])
+	if err != nil || m < 0 || m > 59 {
+		return 0, fmt.Errorf("invalid minute in %q", s)
+	}
+	return time.Duration(h)*time.Hour + time.Duration(m)*time.Minute, nil
+}
+
+// Contains returns true if the wall clock time of t falls within the quiet hours
+func (q *QuietHours) Contains(t time.Time) bool {
+	if q == nil {
+		return false
+	}
+	offset := time.Duration(t.Hour())*time.Hour + time.Duration(t.Minute())*time.Minute + time.Duration(t.Second())*time.Second
+	if q.Start < q.End {
+		return offset >= q.Start && offset < q.End
+	}
+	return offset >= q.Start || offset < q.End
+}
+
+// Timezones maps apps to the timezone their quiet hours are evaluated in
+type Timezones struct {
+	Default *time.Location
+	Apps    map[int32]*time.Location
+}
+
+// ParseTimezones returns the timezones from a default timezone name and a comma separated list of
+// app specific overrides in the format "appID=Area/Location", e.g. "123=Europe/Helsinki,456=UTC".
+func ParseTimezones(defaultTZ string, appTZs string) (*Timezones, error) {
+	if defaultTZ == "" {
+		defaultTZ = "UTC"
+	}
+	def, err := time.LoadLocation(defaultTZ)
+	if err != nil {
+		return nil, err
+	}
+	tz := &Timezones{Default: def, Apps: map[int32]*time.Location{}}
+	if appTZs == "" {
+		return tz, nil
+	}
+	for _, entry := range strings.Split(appTZs, ",") {
+		parts := strings.Split(strings.TrimSpace(entry), "=")
+		if len(parts) != 2 {
+			return nil, fmt.Errorf("invalid app timezone %q, expected appID=Area/Location", entry)
+		}
+		appID, err := strconv.Atoi(parts[0])
+		if err != nil || appID <= 0 {
+			return nil, fmt.Errorf("invalid app id in %q", entry)
+		}
+		loc, err := time.LoadLocation(parts[1])
+		if err != nil {
+			return nil, err
+		}
+		tz.Apps[int32(appID)] = loc
+	}
+	return tz, nil
+}
+
+// Location returns the timezone of the given app
+func (tz *Timezones) Location(appID int32) *time.Location {
+	if tz == nil {
+		return time.UTC
+	}
+	if loc, ok := tz.Apps[appID]; ok {
+		return loc
+	}
+	if tz.Default == nil {
+		return time.UTC
+	}
+	return tz.Default
+}
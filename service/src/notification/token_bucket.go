@@ -0,0 +1,41 @@
+package notification
+
+import "time"
+
+// tokenBucket implements a simple token bucket rate limiter.
+// Tokens are refilled continuously at the configured rate up to the burst capacity.
+type tokenBucket struct {
+	capacity float64
+	rate     float64 // tokens per second
+	tokens   float64
+	last     time.Time
+}
+
+// newTokenBucket returns a full bucket allowing perHour notifications per hour with the given burst
+func newTokenBucket(perHour, burst int, now time.Time) *tokenBucket {
+	if burst < 1 {
+		burst = 1
+	}
+	return &tokenBucket{
+		capacity: float64(burst),
+		rate:     float64(perHour) / time.Hour.Seconds(),
+		tokens:   float64(burst),
+		last:     now,
+	}
+}
+
+// take consumes a token if one is available and returns true, false otherwise
+func (b *tokenBucket) take(now time.Time) bool {
+	if elapsed := now.Sub(b.last).Seconds(); elapsed > 0 {
+		b.tokens += elapsed * b.rate
+		if b.tokens > b.capacity {
+			b.tokens = b.capacity
+		}
+		b.last = now
+	}
+	if b.tokens < 1 {
+		return false
+	}
+	b.tokens--
+	return true
+}
@@ -0,0 +1,102 @@
+package service
+
+import (
+	"context"
+
+	"github.com/callstats-io/ai-decision/service/gen/protos"
+	"github.com/callstats-io/ai-decision/service/src/grpc"
+	"github.com/callstats-io/ai-decision/service/src/storage"
+	"github.com/callstats-io/go-common/log"
+	"github.com/golang/protobuf/ptypes"
+)
+
+// AuditStorage defines the interface the audit service expects from applicable storages
+type AuditStorage interface {
+	ListAuditEvents(ctx context.Context, filter *storage.AuditFilter) ([]*storage.AuditEvent, error)
+}
+
+// AIDecisionAuditService implements the protos AIDecisionAuditServiceServer
+type AIDecisionAuditService struct {
+	auditStorage AuditStorage
+}
+
+var _ = protos.AIDecisionAuditServiceServer(&AIDecisionAuditService{})
+
+// NewAIDecisionAuditService returns a new AIDecisionAuditService or an error if initialization fails
+func NewAIDecisionAuditService(storage AuditStorage) (*AIDecisionAuditService, error) {
+	s := &AIDecisionAuditService{
+		auditStorage: storage,
+	}
+	return s, nil
+}
+
+// List lists the audit events of the mutations of templates, messages and states newest first,
+// e.g. the deletions of the messages of an app
+func (s *AIDecisionAuditService) List(req *protos.AuditEventListRequest, stream protos.AIDecisionAuditService_ListServer) error {
+	ctx := stream.Context()
+	logger := log.FromContext(ctx).With(
+		log.Int(LogKeyAppID, int(req.AppId)),
+		log.String(LogKeyActor, req.Actor),
+		log.String(LogKeyAction, req.Action),
+		log.String(LogKeyTarget, req.Target),
+	)
+	filter := &storage.AuditFilter{
+		AppID:  req.AppId,
+		Actor:  req.Actor,
+		Action: req.Action,
+		Target: req.Target,
+		Limit:  int(req.Limit),
+	}
+	if req.OccurredFrom != nil {
+		v, _ := ptypes.Timestamp(req.OccurredFrom)
+		filter.From = &v
+		logger = logger.With(log.Time(LogKeyOccurredFrom, v))
+	}
+	if req.OccurredTo != nil {
+		v, _ := ptypes.Timestamp(req.OccurredTo)
+		filter.To = &v
+		logger = logger.With(log.Time(LogKeyOccurredTo, v))
+	}
+	ctx = log.WithLogger(ctx, logger)
+
+	if err := s.validateListRequest(ctx, req); err != nil {
+		return err
+	}
+	events, err := s.auditStorage.ListAuditEvents(ctx, filter)
+	if err == storage.ErrNotFound {
+		return grpc.ErrNotFound(ctx, err)
+	} else if err != nil {
+		return storageError(ctx, err)
+	}
+	for _, event := range events {
+		if err := stream.Send(auditEventProto(event)); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+func auditEventProto(event *storage.AuditEvent) *protos.AuditEvent {
+	occurredAt, _ := ptypes.TimestampProto(event.OccurredAt)
+	return &protos.AuditEvent{
+		Id:         event.ID,
+		OccurredAt: occurredAt,
+		Actor:      event.Actor,
+		Rpc:        event.RPC,
+		AppId:      event.AppID,
+		Action:     event.Action,
+		Target:     event.Target,
+		Count:      event.Count,
+		BeforeHash: event.BeforeHash,
+		AfterHash:  event.AfterHash,
+		Reason:     event.Reason,
+	}
+}
+
+func (s *AIDecisionAuditService) validateListRequest(ctx context.Context, req *protos.AuditEventListRequest) error {
+	return validate(ctx,
+		validateNonNegativeInt("app_id", req.AppId),
+		validateNonNegativeInt("limit", req.Limit),
+		validateTimeRange("occurred_to", req.OccurredTo, "occurred_from", req.OccurredFrom),
+	)
+}
@@ -0,0 +1,117 @@
+package service_test
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/callstats-io/ai-decision/service/gen/protos"
+	"github.com/callstats-io/ai-decision/service/src/storage"
+	"github.com/golang/protobuf/ptypes"
+	"github.com/stretchr/testify/require"
+)
+
+func TestAuditList(t *testing.T) {
+	// ensure no leakage between tests
+	defer mockStorage.Reset()
+
+	from := time.Now().Add(-time.Hour).UTC()
+	fromProto, _ := ptypes.TimestampProto(from)
+
+	tests := []struct {
+		Description string
+		ExpErrorMsg string
+		Setup       func(req *protos.AuditEventListRequest) []*protos.AuditEvent
+	}{
+		{
+			Description: "valid request",
+			Setup: func(req *protos.AuditEventListRequest) []*protos.AuditEvent {
+				mockStorage.Reset()
+				mockStorage.MockAuditEvents([]*storage.AuditEvent{
+					{ID: 2, AppID: 123, Actor: "ops", RPC: "cli:delete", Action: storage.AuditMessageDelete, Target: "7",
+						Count: 1, BeforeHash: "ab", Reason: "customer request"},
+					{ID: 1, AppID: 123, Actor: "app:123", RPC: "/callstats.ai_decision.AIDecisionMessageService/Create",
+						Action: storage.AuditMessageCreate, Target: "7", Count: 1, AfterHash: "ab"},
+				})
+				return []*protos.AuditEvent{
+					{Id: 2, AppId: 123, Actor: "ops", Rpc: "cli:delete", Action: storage.AuditMessageDelete, Target: "7",
+						Count: 1, BeforeHash: "ab", Reason: "customer request"},
+					{Id: 1, AppId: 123, Actor: "app:123", Rpc: "/callstats.ai_decision.AIDecisionMessageService/Create",
+						Action: storage.AuditMessageCreate, Target: "7", Count: 1, AfterHash: "ab"},
+				}
+			},
+		},
+		{
+			Description: "no events",
+			ExpErrorMsg: "rpc error: code = NotFound desc = not found",
+			Setup: func(req *protos.AuditEventListRequest) []*protos.AuditEvent {
+				mockStorage.Reset()
+				mockStorage.MockListAuditEventsError(storage.ErrNotFound)
+				return nil
+			},
+		},
+		{
+			Description: "negative limit",
+			ExpErrorMsg: "rpc error: code = InvalidArgument desc = limit: cannot be negative",
+			Setup: func(req *protos.AuditEventListRequest) []*protos.AuditEvent {
+				req.Limit = -1
+				return nil
+			},
+		},
+		{
+			Description: "occurred to before occurred from",
+			ExpErrorMsg: "rpc error: code = InvalidArgument desc = occurred_to: cannot be before occurred_from",
+			Setup: func(req *protos.AuditEventListRequest) []*protos.AuditEvent {
+				req.OccurredTo, _ = ptypes.TimestampProto(from.Add(-time.Hour))
+				return nil
+			},
+		},
+		{
+			Description: "audit list error",
+			ExpErrorMsg: "rpc error: code = Unavailable desc = EXPECTED AUDIT LIST TEST ERROR",
+			Setup: func(req *protos.AuditEventListRequest) []*protos.AuditEvent {
+				mockStorage.Reset()
+				mockStorage.MockListAuditEventsError(errors.New("EXPECTED AUDIT LIST TEST ERROR"))
+				return nil
+			},
+		},
+	}
+
+	for _, test := range tests {
+		t.Run(test.Description, func(t *testing.T) {
+			assert := require.New(t)
+
+			// create a valid request, expect Setup to invalidate if needed
+			req := &protos.AuditEventListRequest{AppId: 123, Action: storage.AuditMessageDelete, OccurredFrom: fromProto, Limit: 10}
+			expEvents := test.Setup(req)
+
+			// exec test
+			stream, err := testAuditClient.List(context.Background(), req)
+			assert.Nil(err)
+			if test.ExpErrorMsg != "" {
+				_, err := stream.Recv()
+				assert.EqualError(err, test.ExpErrorMsg)
+				return
+			}
+			for _, exp := range expEvents {
+				resp, err := stream.Recv()
+				assert.Nil(err)
+				resp.OccurredAt = nil
+				assert.Equal(exp, resp)
+			}
+
+			// check no more values
+			_, err = stream.Recv()
+			assert.EqualError(err, "EOF")
+
+			// the request is passed as filter
+			filter := mockStorage.ListedAuditFilter()
+			assert.Equal(int32(123), filter.AppID)
+			assert.Equal(storage.AuditMessageDelete, filter.Action)
+			assert.Equal(10, filter.Limit)
+			assert.True(from.Equal(*filter.From))
+			assert.Nil(filter.To)
+		})
+	}
+}
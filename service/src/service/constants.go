@@ -11,4 +11,31 @@ const (
 	LogKeyGenerationTime     = "generationTime"
 	LogKeyGenerationTimeFrom = "generationTimeFrom"
 	LogKeyGenerationTimeTo   = "generationTimeTo"
+	LogKeyAsOf               = "asOf"
+	LogKeyDryRun             = "dryRun"
+	LogKeyDeleted            = "deleted"
+	LogKeyAudit              = "audit"
+	LogKeyPeer               = "peer"
+	LogKeyLeaseName          = "leaseName"
+	LogKeyLeaseHolder        = "leaseHolder"
+	LogKeyFencingToken       = "fencingToken"
+	LogKeyRunID              = "runID"
+	LogKeyRunStatus          = "runStatus"
+	LogKeyPipeline           = "pipeline"
+	LogKeyStartedFrom        = "startedFrom"
+	LogKeyStartedTo          = "startedTo"
+	LogKeyActor              = "actor"
+	LogKeyAction             = "action"
+	LogKeyTarget             = "target"
+	LogKeyOccurredFrom       = "occurredFrom"
+	LogKeyOccurredTo         = "occurredTo"
 )
+
+// StateChunkSize is the size of the chunks chunked states are stored and streamed in
+const StateChunkSize = 1 << 20
+
+// DefaultStateMaxSize is the maximum size of the data of states whose keywords are not registered with a maximum size
+const DefaultStateMaxSize = 256 << 20
+
+// ContentTypeJSON is the keyword content type of states that must hold valid JSON
+const ContentTypeJSON = "application/json"
@@ -0,0 +1,70 @@
+package service
+
+import (
+	"context"
+	"strconv"
+	"time"
+
+	"github.com/callstats-io/ai-decision/service/src/grpc"
+	"github.com/callstats-io/ai-decision/service/src/message"
+	"github.com/callstats-io/ai-decision/service/src/storage"
+	"google.golang.org/grpc/codes"
+)
+
+// retry delays hinted to clients on temporary storage errors
+const (
+	conflictRetryDelay    = 100 * time.Millisecond
+	unavailableRetryDelay = time.Second
+)
+
+// ReasonTemplateRenderFailed is the ErrorInfo reason of messages that cannot be rendered with their data
+const ReasonTemplateRenderFailed = "TEMPLATE_RENDER_FAILED"
+
+// ErrorInfo metadata keys of render errors
+const (
+	ErrorInfoKeyTemplateType    = "type"
+	ErrorInfoKeyTemplateVersion = "version"
+	ErrorInfoKeyField           = "field"
+)
+
+// renderError maps a render error of a template to InvalidArgument with an ErrorInfo of the template type, version
+// and the missing or invalid field of the data if known
+func renderError(ctx context.Context, err error) error {
+	renderErr, ok := err.(*message.RenderError)
+	if !ok {
+		return grpc.ErrInvalidArgument(ctx, err)
+	}
+	info := &grpc.ErrorInfo{
+		Reason: ReasonTemplateRenderFailed,
+		Domain: grpc.ErrorDomain,
+		Metadata: map[string]string{
+			ErrorInfoKeyTemplateType:    renderErr.Type,
+			ErrorInfoKeyTemplateVersion: strconv.Itoa(int(renderErr.Version)),
+		},
+	}
+	if renderErr.Field != "" {
+		info.Metadata[ErrorInfoKeyField] = renderErr.Field
+	}
+	return grpc.ErrWithInfo(ctx, codes.InvalidArgument, err, info)
+}
+
+// storageError maps a storage error to a gRPC error by the kind of the error. Temporary errors carry a retry hint,
+// mutations without an audit actor are Unauthenticated and errors of an unknown kind are Unavailable.
+func storageError(ctx context.Context, err error) error {
+	if err == storage.ErrNoAuditActor {
+		return grpc.ErrUnauthenticated(ctx, err)
+	}
+	switch storage.ErrorKind(err) {
+	case storage.ErrUniqueViolation:
+		return grpc.ErrAlreadyExists(ctx, err)
+	case storage.ErrForeignKeyViolation:
+		return grpc.ErrFailedPrecondition(ctx, err)
+	case storage.ErrSerializationFailure:
+		return grpc.ErrRetryable(ctx, codes.Aborted, err, conflictRetryDelay)
+	case storage.ErrTimeout:
+		return grpc.ErrRetryable(ctx, codes.DeadlineExceeded, err, unavailableRetryDelay)
+	case storage.ErrUnavailable:
+		return grpc.ErrRetryable(ctx, codes.Unavailable, err, unavailableRetryDelay)
+	}
+	return grpc.ErrUnavailable(ctx, err)
+}
@@ -0,0 +1,152 @@
+package service
+
+import (
+	"context"
+	"time"
+
+	"github.com/callstats-io/ai-decision/service/gen/protos"
+	"github.com/callstats-io/ai-decision/service/src/grpc"
+	"github.com/callstats-io/ai-decision/service/src/storage"
+	"github.com/callstats-io/go-common/log"
+	"github.com/golang/protobuf/ptypes"
+)
+
+// LeaseStorage defines the interface the lease service expects from applicable storages
+type LeaseStorage interface {
+	AcquireLease(ctx context.Context, lease *storage.AidLease, ttl time.Duration) error
+	RenewLease(ctx context.Context, lease *storage.AidLease, ttl time.Duration) error
+	ReleaseLease(ctx context.Context, lease *storage.AidLease) error
+}
+
+// AIDecisionLeaseService implements the protos AIDecisionLeaseServiceServer
+type AIDecisionLeaseService struct {
+	leaseStorage LeaseStorage
+}
+
+var _ = protos.AIDecisionLeaseServiceServer(&AIDecisionLeaseService{})
+
+// NewAIDecisionLeaseService returns a new AIDecisionLeaseService or an error if initialization fails
+func NewAIDecisionLeaseService(storage LeaseStorage) (*AIDecisionLeaseService, error) {
+	s := &AIDecisionLeaseService{
+		leaseStorage: storage,
+	}
+	return s, nil
+}
+
+// Acquire acquires a lease with a new fencing token unless it is held by another holder
+func (s *AIDecisionLeaseService) Acquire(ctx context.Context, req *protos.LeaseAcquireRequest) (*protos.Lease, error) {
+	ctx = log.WithLogger(ctx, log.FromContext(ctx).With(
+		log.Int(LogKeyAppID, int(req.AppId)),
+		log.String(LogKeyLeaseName, req.Name),
+		log.String(LogKeyLeaseHolder, req.Holder),
+	))
+	if err := s.validateAcquireRequest(ctx, req); err != nil {
+		return nil, err
+	}
+
+	lease := &storage.AidLease{
+		AppID:  req.AppId,
+		Name:   req.Name,
+		Holder: req.Holder,
+	}
+	err := s.leaseStorage.AcquireLease(ctx, lease, time.Duration(req.TtlSeconds)*time.Second)
+	if err == storage.ErrLeaseHeld {
+		return nil, grpc.ErrFailedPrecondition(ctx, err)
+	} else if err != nil {
+		return nil, storageError(ctx, err)
+	}
+	return leaseProto(lease), nil
+}
+
+// Renew extends a lease held with its fencing token
+func (s *AIDecisionLeaseService) Renew(ctx context.Context, req *protos.LeaseRenewRequest) (*protos.Lease, error) {
+	ctx = log.WithLogger(ctx, log.FromContext(ctx).With(
+		log.Int(LogKeyAppID, int(req.AppId)),
+		log.String(LogKeyLeaseName, req.Name),
+		log.String(LogKeyLeaseHolder, req.Holder),
+		log.Int64(LogKeyFencingToken, req.FencingToken),
+	))
+	if err := s.validateRenewRequest(ctx, req); err != nil {
+		return nil, err
+	}
+
+	lease := &storage.AidLease{
+		AppID:        req.AppId,
+		Name:         req.Name,
+		Holder:       req.Holder,
+		FencingToken: req.FencingToken,
+	}
+	err := s.leaseStorage.RenewLease(ctx, lease, time.Duration(req.TtlSeconds)*time.Second)
+	if err == storage.ErrLeaseLost {
+		return nil, grpc.ErrFailedPrecondition(ctx, err)
+	} else if err != nil {
+		return nil, storageError(ctx, err)
+	}
+	return leaseProto(lease), nil
+}
+
+// Release releases a lease held with its fencing token
+func (s *AIDecisionLeaseService) Release(ctx context.Context, req *protos.LeaseReleaseRequest) (*protos.LeaseReleaseResponse, error) {
+	ctx = log.WithLogger(ctx, log.FromContext(ctx).With(
+		log.Int(LogKeyAppID, int(req.AppId)),
+		log.String(LogKeyLeaseName, req.Name),
+		log.String(LogKeyLeaseHolder, req.Holder),
+		log.Int64(LogKeyFencingToken, req.FencingToken),
+	))
+	if err := s.validateReleaseRequest(ctx, req); err != nil {
+		return nil, err
+	}
+
+	lease := &storage.AidLease{
+		AppID:        req.AppId,
+		Name:         req.Name,
+		Holder:       req.Holder,
+		FencingToken: req.FencingToken,
+	}
+	err := s.leaseStorage.ReleaseLease(ctx, lease)
+	if err == storage.ErrLeaseLost {
+		return nil, grpc.ErrFailedPrecondition(ctx, err)
+	} else if err != nil {
+		return nil, storageError(ctx, err)
+	}
+	return &protos.LeaseReleaseResponse{}, nil
+}
+
+func leaseProto(lease *storage.AidLease) *protos.Lease {
+	expiresAt, _ := ptypes.TimestampProto(lease.ExpiresAt)
+	return &protos.Lease{
+		AppId:        lease.AppID,
+		Name:         lease.Name,
+		Holder:       lease.Holder,
+		FencingToken: lease.FencingToken,
+		ExpiresAt:    expiresAt,
+	}
+}
+
+func (s *AIDecisionLeaseService) validateAcquireRequest(ctx context.Context, req *protos.LeaseAcquireRequest) error {
+	return validate(ctx,
+		validatePositiveInt("app_id", req.AppId),
+		validateNonEmptyString("name", req.Name),
+		validateNonEmptyString("holder", req.Holder),
+		validatePositiveInt("ttl_seconds", req.TtlSeconds),
+	)
+}
+
+func (s *AIDecisionLeaseService) validateRenewRequest(ctx context.Context, req *protos.LeaseRenewRequest) error {
+	return validate(ctx,
+		validatePositiveInt("app_id", req.AppId),
+		validateNonEmptyString("name", req.Name),
+		validateNonEmptyString("holder", req.Holder),
+		validatePositiveInt64("fencing_token", req.FencingToken),
+		validatePositiveInt("ttl_seconds", req.TtlSeconds),
+	)
+}
+
+func (s *AIDecisionLeaseService) validateReleaseRequest(ctx context.Context, req *protos.LeaseReleaseRequest) error {
+	return validate(ctx,
+		validatePositiveInt("app_id", req.AppId),
+		validateNonEmptyString("name", req.Name),
+		validateNonEmptyString("holder", req.Holder),
+		validatePositiveInt64("fencing_token", req.FencingToken),
+	)
+}
@@ -0,0 +1,265 @@
+package service_test
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/callstats-io/ai-decision/service/gen/protos"
+	"github.com/callstats-io/ai-decision/service/src/storage"
+	"github.com/golang/protobuf/ptypes"
+	"github.com/stretchr/testify/require"
+)
+
+func TestLeaseAcquire(t *testing.T) {
+	// ensure no leakage between tests
+	defer mockStorage.Reset()
+
+	expiresAt := time.Now().Add(time.Minute)
+	expiresAtProto, _ := ptypes.TimestampProto(expiresAt)
+
+	tests := []struct {
+		Description string
+		ExpErrorMsg string
+		Setup       func(req *protos.LeaseAcquireRequest) *protos.Lease
+	}{
+		{
+			Description: "valid request",
+			Setup: func(req *protos.LeaseAcquireRequest) *protos.Lease {
+				mockStorage.Reset()
+				mockStorage.MockLease(&storage.AidLease{
+					AppID: req.AppId, Name: req.Name, Holder: req.Holder, FencingToken: 42, ExpiresAt: expiresAt,
+				})
+				return &protos.Lease{
+					AppId:        req.AppId,
+					Name:         req.Name,
+					Holder:       req.Holder,
+					FencingToken: 42,
+					ExpiresAt:    expiresAtProto,
+				}
+			},
+		},
+		{
+			Description: "lease held",
+			ExpErrorMsg: "rpc error: code = FailedPrecondition desc = lease is held by another holder",
+			Setup: func(req *protos.LeaseAcquireRequest) *protos.Lease {
+				mockStorage.Reset()
+				mockStorage.MockAcquireLeaseError(storage.ErrLeaseHeld)
+				return nil
+			},
+		},
+		{
+			Description: "missing app id",
+			ExpErrorMsg: "rpc error: code = InvalidArgument desc = app_id: must be a positive integer",
+			Setup: func(req *protos.LeaseAcquireRequest) *protos.Lease {
+				req.AppId = 0
+				return nil
+			},
+		},
+		{
+			Description: "missing name",
+			ExpErrorMsg: "rpc error: code = InvalidArgument desc = name: cannot be empty",
+			Setup: func(req *protos.LeaseAcquireRequest) *protos.Lease {
+				req.Name = ""
+				return nil
+			},
+		},
+		{
+			Description: "missing holder",
+			ExpErrorMsg: "rpc error: code = InvalidArgument desc = holder: cannot be empty",
+			Setup: func(req *protos.LeaseAcquireRequest) *protos.Lease {
+				req.Holder = ""
+				return nil
+			},
+		},
+		{
+			Description: "missing ttl",
+			ExpErrorMsg: "rpc error: code = InvalidArgument desc = ttl_seconds: must be a positive integer",
+			Setup: func(req *protos.LeaseAcquireRequest) *protos.Lease {
+				req.TtlSeconds = 0
+				return nil
+			},
+		},
+		{
+			Description: "lease acquire error",
+			ExpErrorMsg: "rpc error: code = Unavailable desc = EXPECTED LEASE ACQUIRE TEST ERROR",
+			Setup: func(req *protos.LeaseAcquireRequest) *protos.Lease {
+				mockStorage.Reset()
+				mockStorage.MockAcquireLeaseError(errors.New("EXPECTED LEASE ACQUIRE TEST ERROR"))
+				return nil
+			},
+		},
+	}
+
+	for _, test := range tests {
+		t.Run(test.Description, func(t *testing.T) {
+			assert := require.New(t)
+
+			// create a valid request, expect Setup to invalidate if needed
+			req := &protos.LeaseAcquireRequest{
+				AppId:      123,
+				Name:       "pipeline",
+				Holder:     "worker-1",
+				TtlSeconds: 60,
+			}
+			expLease := test.Setup(req)
+
+			// exec test
+			resp, err := testLeaseClient.Acquire(context.Background(), req)
+			if test.ExpErrorMsg != "" {
+				assert.EqualError(err, test.ExpErrorMsg)
+				return
+			}
+			assert.Nil(err)
+			assert.Equal(expLease.ExpiresAt.Seconds, resp.ExpiresAt.Seconds)
+			assert.Equal(expLease.ExpiresAt.Nanos, resp.ExpiresAt.Nanos)
+			expLease.ExpiresAt = nil
+			resp.ExpiresAt = nil
+			assert.Equal(expLease, resp)
+			assert.Equal(1, mockStorage.AcquireLeaseCalls())
+		})
+	}
+}
+
+func TestLeaseRenew(t *testing.T) {
+	// ensure no leakage between tests
+	defer mockStorage.Reset()
+
+	tests := []struct {
+		Description string
+		ExpErrorMsg string
+		Setup       func(req *protos.LeaseRenewRequest)
+	}{
+		{
+			Description: "valid request",
+			Setup: func(req *protos.LeaseRenewRequest) {
+				mockStorage.Reset()
+				mockStorage.MockLease(&storage.AidLease{
+					AppID: req.AppId, Name: req.Name, Holder: req.Holder, FencingToken: req.FencingToken, ExpiresAt: time.Now(),
+				})
+			},
+		},
+		{
+			Description: "lease lost",
+			ExpErrorMsg: "rpc error: code = FailedPrecondition desc = lease expired or acquired by another holder",
+			Setup: func(req *protos.LeaseRenewRequest) {
+				mockStorage.Reset()
+				mockStorage.MockRenewLeaseError(storage.ErrLeaseLost)
+			},
+		},
+		{
+			Description: "missing fencing token",
+			ExpErrorMsg: "rpc error: code = InvalidArgument desc = fencing_token: must be a positive integer",
+			Setup: func(req *protos.LeaseRenewRequest) {
+				req.FencingToken = 0
+			},
+		},
+		{
+			Description: "missing ttl",
+			ExpErrorMsg: "rpc error: code = InvalidArgument desc = ttl_seconds: must be a positive integer",
+			Setup: func(req *protos.LeaseRenewRequest) {
+				req.TtlSeconds = 0
+			},
+		},
+		{
+			Description: "lease renew error",
+			ExpErrorMsg: "rpc error: code = Unavailable desc = EXPECTED LEASE RENEW TEST ERROR",
+			Setup: func(req *protos.LeaseRenewRequest) {
+				mockStorage.Reset()
+				mockStorage.MockRenewLeaseError(errors.New("EXPECTED LEASE RENEW TEST ERROR"))
+			},
+		},
+	}
+
+	for _, test := range tests {
+		t.Run(test.Description, func(t *testing.T) {
+			assert := require.New(t)
+
+			// create a valid request, expect Setup to invalidate if needed
+			req := &protos.LeaseRenewRequest{
+				AppId:        123,
+				Name:         "pipeline",
+				Holder:       "worker-1",
+				FencingToken: 42,
+				TtlSeconds:   60,
+			}
+			test.Setup(req)
+
+			// exec test
+			resp, err := testLeaseClient.Renew(context.Background(), req)
+			if test.ExpErrorMsg != "" {
+				assert.EqualError(err, test.ExpErrorMsg)
+				return
+			}
+			assert.Nil(err)
+			assert.Equal(int64(42), resp.FencingToken)
+			assert.NotNil(resp.ExpiresAt)
+		})
+	}
+}
+
+func TestLeaseRelease(t *testing.T) {
+	// ensure no leakage between tests
+	defer mockStorage.Reset()
+
+	tests := []struct {
+		Description string
+		ExpErrorMsg string
+		Setup       func(req *protos.LeaseReleaseRequest)
+	}{
+		{
+			Description: "valid request",
+			Setup: func(req *protos.LeaseReleaseRequest) {
+				mockStorage.Reset()
+			},
+		},
+		{
+			Description: "lease lost",
+			ExpErrorMsg: "rpc error: code = FailedPrecondition desc = lease expired or acquired by another holder",
+			Setup: func(req *protos.LeaseReleaseRequest) {
+				mockStorage.Reset()
+				mockStorage.MockReleaseLeaseError(storage.ErrLeaseLost)
+			},
+		},
+		{
+			Description: "missing holder",
+			ExpErrorMsg: "rpc error: code = InvalidArgument desc = holder: cannot be empty",
+			Setup: func(req *protos.LeaseReleaseRequest) {
+				req.Holder = ""
+			},
+		},
+		{
+			Description: "lease release error",
+			ExpErrorMsg: "rpc error: code = Unavailable desc = EXPECTED LEASE RELEASE TEST ERROR",
+			Setup: func(req *protos.LeaseReleaseRequest) {
+				mockStorage.Reset()
+				mockStorage.MockReleaseLeaseError(errors.New("EXPECTED LEASE RELEASE TEST ERROR"))
+			},
+		},
+	}
+
+	for _, test := range tests {
+		t.Run(test.Description, func(t *testing.T) {
+			assert := require.New(t)
+
+			// create a valid request, expect Setup to invalidate if needed
+			req := &protos.LeaseReleaseRequest{
+				AppId:        123,
+				Name:         "pipeline",
+				Holder:       "worker-1",
+				FencingToken: 42,
+			}
+			test.Setup(req)
+
+			// exec test
+			_, err := testLeaseClient.Release(context.Background(), req)
+			if test.ExpErrorMsg != "" {
+				assert.EqualError(err, test.ExpErrorMsg)
+				return
+			}
+			assert.Nil(err)
+			assert.Equal(1, mockStorage.ReleaseLeaseCalls())
+		})
+	}
+}
@@ -2,39 +2,42 @@ package service
 
 import (
 	"context"
-	"fmt"
-	"strings"
 	"time"
 
 	"github.com/callstats-io/ai-decision/service/gen/protos"
-	"github.com/callstats-io/ai-decision/service/src/flowdock"
 	"github.com/callstats-io/ai-decision/service/src/grpc"
 	"github.com/callstats-io/ai-decision/service/src/message"
 	"github.com/callstats-io/ai-decision/service/src/storage"
 	"github.com/callstats-io/go-common/log"
 	"github.com/golang/protobuf/ptypes"
+	"go.opencensus.io/trace"
 )
 
 // MessageStorage defines the interface the service expects of any message storage backend
 type MessageStorage interface {
 	FetchMessageTemplates(ctx context.Context, messageType string, maxVersion int32) ([]*storage.MessageTemplate, error)
 	CreateMessage(ctx context.Context, msg *storage.Message) error
-	ListMessages(ctx context.Context, appID int32, messageType string, minVersion, maxVersion int32, from, to *time.Time) ([]*storage.Message, error)
+	ListMessages(ctx context.Context, appID int32, messageType string, minVersion, maxVersion int32, from, to *time.Time, opts ...storage.ListOption) ([]*storage.Message, error)
+}
+
+// Notifier defines the interface the service expects of any notification destination
+type Notifier interface {
+	SendAiNotificationMessage(ctx context.Context, appID int32, messageType string, renderedMsg string) error
 }
 
 // AIDecisionMessageService implements the protos AIDecisionMessageServiceServer
 type AIDecisionMessageService struct {
 	messageStorage MessageStorage
-	flowdockClient *flowdock.Client
+	notifier       Notifier
 }
 
 var _ = protos.AIDecisionMessageServiceServer(&AIDecisionMessageService{})
 
 //NewAIDecisionMessageService returns a new AIDecisionMessageService or an error if initialization fails
-func NewAIDecisionMessageService(ms MessageStorage, flowdockClient *flowdock.Client) (*AIDecisionMessageService, error) {
+func NewAIDecisionMessageService(ms MessageStorage, notifier Notifier) (*AIDecisionMessageService, error) {
 	s := &AIDecisionMessageService{
 		messageStorage: ms,
-		flowdockClient: flowdockClient,
+		notifier:       notifier,
 	}
 	return s, nil
 }
@@ -55,7 +58,7 @@ func (s *AIDecisionMessageService) Create(ctx context.Context, req *protos.Messa
 
 	templateData, err := message.UnmarshalTemplateData(req.Data)
 	if err != nil {
-		return nil, grpc.ErrInvalidArgument(ctx, fmt.Errorf("data: %s", err))
+		return nil, validate(ctx, violation("data", "%s", err))
 	}
 
 	templates, err := s.messageStorage.FetchMessageTemplates(ctx, req.Type, req.Version)
@@ -63,9 +66,11 @@ func (s *AIDecisionMessageService) Create(ctx context.Context, req *protos.Messa
 		if err == storage.ErrNotFound {
 			return nil, grpc.ErrNotFound(ctx, err)
 		}
-		return nil, grpc.ErrUnavailable(ctx, err)
+		return nil, storageError(ctx, err)
 	}
 
+	_, span := trace.StartSpan(ctx, "render")
+	span.AddAttributes(trace.Int64Attribute("templates", int64(len(templates))))
 	var renderedMsg string
 	var template *storage.MessageTemplate
 	for _, t := range templates {
@@ -74,12 +79,13 @@ func (s *AIDecisionMessageService) Create(ctx context.Context, req *protos.Messa
 			return nil, grpc.ErrFailedPrecondition(ctx, err)
 		}
 		if m, err := mt.RenderString(templateData); err != nil {
-			return nil, grpc.ErrInvalidArgument(ctx, err)
+			return nil, renderError(ctx, err)
 		} else if mt.Version() == req.Version {
 			template = t
 			renderedMsg = m // keep the rendered message for return value
 		}
 	}
+	span.End()
 
 	// a template must always exist for us to end up here (otherwise db should return a not found error)
 	// so we ignore nil-validations. Furthermore, validations should account for data validity so timestamp error is ignored.
@@ -89,20 +95,21 @@ func (s *AIDecisionMessageService) Create(ctx context.Context, req *protos.Messa
 		Template:    template,
 		GeneratedAt: genTime,
 		Data:        req.Data,
+		RunID:       req.RunId,
 	}
 
 	if err := s.messageStorage.CreateMessage(ctx, msg); err != nil {
 		if err == storage.ErrNotFound {
 			return nil, grpc.ErrNotFound(ctx, err)
 		}
-		if strings.Contains(err.Error(), "violates unique constraint") {
+		if err == storage.ErrUnknownRun {
 			return nil, grpc.ErrFailedPrecondition(ctx, err)
 		}
-		return nil, grpc.ErrUnavailable(ctx, err)
+		return nil, storageError(ctx, err)
 	}
 
-	if err := s.flowdockClient.SendAiNotificationMessage(req.AppId, req.Type, renderedMsg); err != nil {
-		logger.Warn("Error in Flowdock Send: ", log.Error(err))
+	if err := s.notifier.SendAiNotificationMessage(ctx, req.AppId, req.Type, renderedMsg); err != nil {
+		logger.Warn("Error in notification send: ", log.Error(err))
 	}
 
 	return &protos.Message{
@@ -112,6 +119,7 @@ func (s *AIDecisionMessageService) Create(ctx context.Context, req *protos.Messa
 		GenerationTime: req.GenerationTime,
 		Data:           req.Data,
 		Message:        renderedMsg,
+		RunId:          req.RunId,
 	}, nil
 }
 
@@ -122,6 +130,7 @@ func (s *AIDecisionMessageService) validateCreateRequest(ctx context.Context, re
 		validatePositiveInt("version", req.Version),
 		validateNonEmptyBytes("data", req.Data),
 		validateTimestamp("generation_time", req.GenerationTime),
+		validateNonNegativeInt("run_id", req.RunId),
 	)
 }
 
@@ -147,44 +156,53 @@ func (s *AIDecisionMessageService) List(req *protos.MessageListRequest, stream p
 	}
 	ctx = log.WithLogger(ctx, logger)
 
-	if err := s.validateListRequest(ctx, req); err != nil {
+	mask, err := s.validateListRequest(ctx, req)
+	if err != nil {
 		return err
 	}
 
-	messages, err := s.messageStorage.ListMessages(ctx, req.AppId, req.Type, req.MinVersion, req.MaxVersion, generatedAtFrom, generatedAtTo)
+	// the data is read to render the message even if only the message is sent
+	messages, err := s.messageStorage.ListMessages(ctx, req.AppId, req.Type, req.MinVersion, req.MaxVersion, generatedAtFrom, generatedAtTo,
+		mask.listOptions("data", "message")...)
 	if err == storage.ErrNotFound {
 		return grpc.ErrNotFound(ctx, err)
 	} else if err != nil {
-		return grpc.ErrUnavailable(ctx, err)
+		return storageError(ctx, err)
 	}
 
 	for _, msg := range messages {
-		// render message
-		mt, err := message.NewTemplate(msg.Template)
-		if err != nil {
-			// should never happen, likely an invalid template in db WITH a message that refers to it
-			// which would mean someone has gone and done something stupid manually
-			return grpc.ErrFailedPrecondition(ctx, err)
-		}
-		tmplData, err := message.UnmarshalTemplateData(msg.Data)
-		if err != nil {
-
-		}
-		rendered, err := mt.RenderString(tmplData)
-		if err != nil {
-			return grpc.ErrInvalidArgument(ctx, err)
+		var rendered string
+		if mask.includes("message") {
+			// render message
+			mt, err := message.NewTemplate(msg.Template)
+			if err != nil {
+				// should never happen, likely an invalid template in db WITH a message that refers to it
+				// which would mean someone has gone and done something stupid manually
+				return grpc.ErrFailedPrecondition(ctx, err)
+			}
+			tmplData, err := message.UnmarshalTemplateData(msg.Data)
+			if err != nil {
+
+			}
+			rendered, err = mt.RenderString(tmplData)
+			if err != nil {
+				return renderError(ctx, err)
+			}
 		}
 
 		// send to requester
 		genTime, _ := ptypes.TimestampProto(msg.GeneratedAt)
-		if err := stream.Send(&protos.Message{
+		resp := &protos.Message{
 			AppId:          msg.AppID,
 			Type:           msg.Template.Type,
 			Version:        msg.Template.Version,
 			Data:           msg.Data,
 			GenerationTime: genTime,
 			Message:        rendered,
-		}); err != nil {
+			RunId:          msg.RunID,
+		}
+		mask.apply(resp)
+		if err := stream.Send(resp); err != nil {
 			return err
 		}
 	}
@@ -192,6 +210,10 @@ func (s *AIDecisionMessageService) List(req *protos.MessageListRequest, stream p
 	return nil
 }
 
-func (s *AIDecisionMessageService) validateListRequest(ctx context.Context, req *protos.MessageListRequest) error {
-	return validate(ctx, validatePositiveInt("app_id", req.AppId))
+func (s *AIDecisionMessageService) validateListRequest(ctx context.Context, req *protos.MessageListRequest) (readMask, error) {
+	mask, maskErr := newReadMask("read_mask", req.ReadMask, &protos.Message{})
+	return mask, validate(ctx,
+		validatePositiveInt("app_id", req.AppId),
+		maskErr,
+	)
 }
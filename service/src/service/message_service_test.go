@@ -10,10 +10,16 @@ import (
 	"time"
 
 	"github.com/callstats-io/ai-decision/service/gen/protos"
+	sgrpc "github.com/callstats-io/ai-decision/service/src/grpc"
+	"github.com/callstats-io/ai-decision/service/src/service"
 	"github.com/callstats-io/ai-decision/service/src/storage"
 	"github.com/golang/protobuf/ptypes"
 	"github.com/golang/protobuf/ptypes/timestamp"
 	"github.com/stretchr/testify/require"
+	"google.golang.org/genproto/googleapis/rpc/errdetails"
+	"google.golang.org/genproto/protobuf/field_mask"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
 )
 
 func TestMessageCreate(t *testing.T) {
@@ -216,6 +222,70 @@ func TestMessageCreate(t *testing.T) {
 				return nil, nil
 			},
 		},
+		{
+			Description: "unknown run",
+			ExpErrorMsg: "rpc error: code = FailedPrecondition desc = unknown run",
+			Setup: func(req *protos.MessageCreateRequest) (*protos.Message, error) {
+				mockStorage.Reset()
+				mockStorage.MockSavedMessageTemplates(sharedTemplates)
+				mockStorage.MockCreateMessageError(storage.ErrUnknownRun)
+
+				// set valid data
+				req.RunId = 42
+				req.Version = int32(len(sharedTemplates))
+				req.Data, _ = json.Marshal(map[string]interface{}{
+					value1Key: 123,
+					value2Key: "awesomeness",
+				})
+				return nil, nil
+			},
+		},
+		{
+			Description: "duplicate message",
+			ExpErrorMsg: "rpc error: code = AlreadyExists desc = duplicate key value violates unique constraint \"message_uniqueness_idx\"",
+			Setup: func(req *protos.MessageCreateRequest) (*protos.Message, error) {
+				mockStorage.Reset()
+				mockStorage.MockSavedMessageTemplates(sharedTemplates)
+				mockStorage.MockCreateMessageError(&storage.Error{
+					Kind:       storage.ErrUniqueViolation,
+					Constraint: "message_uniqueness_idx",
+					Err:        errors.New(`duplicate key value violates unique constraint "message_uniqueness_idx"`),
+				})
+
+				// set valid data
+				req.Version = int32(len(sharedTemplates))
+				req.Data, _ = json.Marshal(map[string]interface{}{
+					value1Key: 123,
+					value2Key: "awesomeness",
+				})
+				return nil, nil
+			},
+		},
+		{
+			Description: "create message timeout",
+			ExpErrorMsg: "rpc error: code = DeadlineExceeded desc = EXPECTED CREATE MESSAGE TIMEOUT",
+			Setup: func(req *protos.MessageCreateRequest) (*protos.Message, error) {
+				mockStorage.Reset()
+				mockStorage.MockSavedMessageTemplates(sharedTemplates)
+				mockStorage.MockCreateMessageError(&storage.Error{Kind: storage.ErrTimeout, Err: errors.New("EXPECTED CREATE MESSAGE TIMEOUT")})
+
+				// set valid data
+				req.Version = int32(len(sharedTemplates))
+				req.Data, _ = json.Marshal(map[string]interface{}{
+					value1Key: 123,
+					value2Key: "awesomeness",
+				})
+				return nil, nil
+			},
+		},
+		{
+			Description: "negative run id",
+			ExpErrorMsg: "rpc error: code = InvalidArgument desc = run_id: cannot be negative",
+			Setup: func(req *protos.MessageCreateRequest) (*protos.Message, error) {
+				req.RunId = -1
+				return nil, nil
+			},
+		},
 	}
 
 	for _, test := range tests {
@@ -492,3 +562,120 @@ func TestMessageList(t *testing.T) {
 		})
 	}
 }
+
+func TestMessageListRetryInfo(t *testing.T) {
+	assert := require.New(t)
+	defer mockStorage.Reset()
+
+	mockStorage.MockListMessagesError(&storage.Error{Kind: storage.ErrUnavailable, Err: errors.New("EXPECTED MESSAGE LIST UNAVAILABLE")})
+	stream, err := testMessageClient.List(context.Background(), &protos.MessageListRequest{AppId: 123})
+	assert.Nil(err)
+	_, err = stream.Recv()
+	assert.EqualError(err, "rpc error: code = Unavailable desc = EXPECTED MESSAGE LIST UNAVAILABLE")
+	details := status.Convert(err).Details()
+	assert.Len(details, 1)
+	retryInfo, ok := details[0].(*errdetails.RetryInfo)
+	assert.True(ok)
+	assert.Equal(int64(1), retryInfo.RetryDelay.Seconds)
+
+	// errors of an unknown kind carry no retry hint
+	mockStorage.MockListMessagesError(errors.New("EXPECTED MESSAGE LIST TEST ERROR"))
+	stream, err = testMessageClient.List(context.Background(), &protos.MessageListRequest{AppId: 123})
+	assert.Nil(err)
+	_, err = stream.Recv()
+	assert.EqualError(err, "rpc error: code = Unavailable desc = EXPECTED MESSAGE LIST TEST ERROR")
+	assert.Empty(status.Convert(err).Details())
+}
+
+func TestMessageCreateErrorDetails(t *testing.T) {
+	assert := require.New(t)
+	defer mockStorage.Reset()
+
+	// every field violation is returned
+	_, err := testMessageClient.Create(context.Background(), &protos.MessageCreateRequest{AppId: -1, Type: "growth"})
+	assert.EqualError(err, "rpc error: code = InvalidArgument desc = app_id: must be a positive integer; "+
+		"version: must be a positive integer; data: cannot be empty; generation_time: cannot be nil")
+	details := status.Convert(err).Details()
+	assert.Len(details, 1)
+	badRequest, ok := details[0].(*errdetails.BadRequest)
+	assert.True(ok)
+	assert.Equal([]*errdetails.BadRequest_FieldViolation{
+		{Field: "app_id", Description: "must be a positive integer"},
+		{Field: "version", Description: "must be a positive integer"},
+		{Field: "data", Description: "cannot be empty"},
+		{Field: "generation_time", Description: "cannot be nil"},
+	}, badRequest.FieldViolations)
+
+	// render errors carry the template and the missing field
+	mockStorage.MockSavedMessageTemplates([]*storage.MessageTemplate{
+		{ID: 1, Type: "growth", Version: 3, Template: `{{.Number "users"}} users`, CreatedAt: time.Now()},
+	})
+	_, err = testMessageClient.Create(context.Background(), &protos.MessageCreateRequest{
+		AppId:          123,
+		Type:           "growth",
+		Version:        3,
+		Data:           []byte(`{"sessions": 5}`),
+		GenerationTime: ptypes.TimestampNow(),
+	})
+	assert.Equal(codes.InvalidArgument, status.Code(err))
+	details = status.Convert(err).Details()
+	assert.Len(details, 1)
+	info, ok := details[0].(*sgrpc.ErrorInfo)
+	assert.True(ok)
+	assert.Equal(service.ReasonTemplateRenderFailed, info.Reason)
+	assert.Equal(sgrpc.ErrorDomain, info.Domain)
+	assert.Equal(map[string]string{
+		service.ErrorInfoKeyTemplateType:    "growth",
+		service.ErrorInfoKeyTemplateVersion: "3",
+		service.ErrorInfoKeyField:           "users",
+	}, info.Metadata)
+}
+
+func TestMessageListReadMask(t *testing.T) {
+	assert := require.New(t)
+	defer mockStorage.Reset()
+
+	tmpl := &storage.MessageTemplate{ID: 1, Type: "growth", Version: 1, Template: `{{.Number "users"}} users`, CreatedAt: time.Now()}
+	mockStorage.MockSavedMessages([]*storage.Message{
+		{ID: 1, AppID: 123, TemplateID: tmpl.ID, Template: tmpl, GeneratedAt: time.Now(), Data: []byte(`{"users": 5}`)},
+	})
+
+	// messages are listed without data, so they are not rendered either
+	stream, err := testMessageClient.List(context.Background(), &protos.MessageListRequest{
+		AppId:    123,
+		ReadMask: &field_mask.FieldMask{Paths: []string{"type", "version", "generation_time"}},
+	})
+	assert.Nil(err)
+	msg, err := stream.Recv()
+	assert.Nil(err)
+	assert.Equal("growth", msg.Type)
+	assert.Equal(int32(1), msg.Version)
+	assert.NotNil(msg.GenerationTime)
+	assert.Empty(msg.Data)
+	assert.Empty(msg.Message)
+	assert.Equal(1, mockStorage.ListMessagesWithoutDataCalls())
+
+	// the data is read to render the message
+	mockStorage.Reset()
+	mockStorage.MockSavedMessages([]*storage.Message{
+		{ID: 1, AppID: 123, TemplateID: tmpl.ID, Template: tmpl, GeneratedAt: time.Now(), Data: []byte(`{"users": 5}`)},
+	})
+	stream, err = testMessageClient.List(context.Background(), &protos.MessageListRequest{
+		AppId:    123,
+		ReadMask: &field_mask.FieldMask{Paths: []string{"message"}},
+	})
+	assert.Nil(err)
+	msg, err = stream.Recv()
+	assert.Nil(err)
+	assert.Equal("5 users", msg.Message)
+	assert.Empty(msg.Data)
+	assert.Equal(0, mockStorage.ListMessagesWithoutDataCalls())
+
+	stream, err = testMessageClient.List(context.Background(), &protos.MessageListRequest{
+		AppId:    123,
+		ReadMask: &field_mask.FieldMask{Paths: []string{"rendered"}},
+	})
+	assert.Nil(err)
+	_, err = stream.Recv()
+	assert.EqualError(err, "rpc error: code = InvalidArgument desc = read_mask: unknown fields rendered of callstats.ai_decision.Message")
+}
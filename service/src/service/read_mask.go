@@ -0,0 +1,69 @@
+package service
+
+import (
+	"reflect"
+	"strings"
+
+	"github.com/callstats-io/ai-decision/service/src/storage"
+	"github.com/golang/protobuf/proto"
+	"google.golang.org/genproto/protobuf/field_mask"
+)
+
+// readMask is the set of fields of the messages of a List response to send, nil to send all fields
+type readMask map[string]bool
+
+// newReadMask returns the read mask of a List request for responses of the type of msg. The paths of the mask
+// must be top level fields of msg, nested fields are not supported.
+func newReadMask(field string, mask *field_mask.FieldMask, msg proto.Message) (readMask, error) {
+	if len(mask.GetPaths()) == 0 {
+		return nil, nil
+	}
+	fields := map[string]bool{}
+	for _, p := range proto.GetProperties(reflect.TypeOf(msg).Elem()).Prop {
+		if p.OrigName != "" {
+			fields[p.OrigName] = true
+		}
+	}
+	m := readMask{}
+	var unknown []string
+	for _, path := range mask.Paths {
+		if !fields[path] {
+			unknown = append(unknown, path)
+		}
+		m[path] = true
+	}
+	if len(unknown) > 0 {
+		return nil, violation(field, "unknown fields %s of %s", strings.Join(unknown, ", "), proto.MessageName(msg))
+	}
+	return m, nil
+}
+
+// includes returns whether a field is sent
+func (m readMask) includes(field string) bool {
+	return m == nil || m[field]
+}
+
+// listOptions returns the storage options to list without data unless one of the fields read from the data is sent
+func (m readMask) listOptions(dataFields ...string) []storage.ListOption {
+	for _, field := range dataFields {
+		if m.includes(field) {
+			return nil
+		}
+	}
+	return []storage.ListOption{storage.WithoutData}
+}
+
+// apply clears the fields of msg not in the mask
+func (m readMask) apply(msg proto.Message) {
+	if m == nil {
+		return
+	}
+	v := reflect.ValueOf(msg).Elem()
+	for _, p := range proto.GetProperties(v.Type()).Prop {
+		if p.OrigName == "" || m[p.OrigName] {
+			continue
+		}
+		f := v.FieldByName(p.Name)
+		f.Set(reflect.Zero(f.Type()))
+	}
+}
@@ -0,0 +1,209 @@
+package service
+
+import (
+	"context"
+
+	"github.com/callstats-io/ai-decision/service/gen/protos"
+	"github.com/callstats-io/ai-decision/service/src/grpc"
+	"github.com/callstats-io/ai-decision/service/src/storage"
+	"github.com/callstats-io/go-common/log"
+	"github.com/golang/protobuf/ptypes"
+)
+
+// RunStorage defines the interface the run service expects from applicable storages
+type RunStorage interface {
+	StartRun(ctx context.Context, run *storage.AidRun) error
+	FinishRun(ctx context.Context, run *storage.AidRun) error
+	ListRuns(ctx context.Context, filter *storage.RunFilter) ([]*storage.AidRun, error)
+	LastSuccessfulRuns(ctx context.Context, appID int32, pipeline string) ([]*storage.AidRun, error)
+}
+
+// runStatuses maps the run statuses of the API to the ones stored
+var runStatuses = map[protos.RunStatus]string{
+	protos.RunStatus_RUN_RUNNING:   storage.RunStatusRunning,
+	protos.RunStatus_RUN_SUCCEEDED: storage.RunStatusSucceeded,
+	protos.RunStatus_RUN_FAILED:    storage.RunStatusFailed,
+}
+
+// AIDecisionRunService implements the protos AIDecisionRunServiceServer
+type AIDecisionRunService struct {
+	runStorage RunStorage
+}
+
+var _ = protos.AIDecisionRunServiceServer(&AIDecisionRunService{})
+
+// NewAIDecisionRunService returns a new AIDecisionRunService or an error if initialization fails
+func NewAIDecisionRunService(storage RunStorage) (*AIDecisionRunService, error) {
+	s := &AIDecisionRunService{
+		runStorage: storage,
+	}
+	return s, nil
+}
+
+// Start records the start of a pipeline run
+func (s *AIDecisionRunService) Start(ctx context.Context, req *protos.RunStartRequest) (*protos.Run, error) {
+	ctx = log.WithLogger(ctx, log.FromContext(ctx).With(
+		log.Int(LogKeyAppID, int(req.AppId)),
+		log.String(LogKeyPipeline, req.Pipeline),
+	))
+	if err := s.validateStartRequest(ctx, req); err != nil {
+		return nil, err
+	}
+
+	run := &storage.AidRun{
+		AppID:    req.AppId,
+		Pipeline: req.Pipeline,
+	}
+	if err := s.runStorage.StartRun(ctx, run); err != nil {
+		return nil, storageError(ctx, err)
+	}
+	return runProto(run), nil
+}
+
+// Finish records the end of a pipeline run with its status, error and counters
+func (s *AIDecisionRunService) Finish(ctx context.Context, req *protos.RunFinishRequest) (*protos.Run, error) {
+	ctx = log.WithLogger(ctx, log.FromContext(ctx).With(
+		log.Int(LogKeyRunID, int(req.Id)),
+		log.String(LogKeyRunStatus, req.Status.String()),
+	))
+	if err := s.validateFinishRequest(ctx, req); err != nil {
+		return nil, err
+	}
+
+	run := &storage.AidRun{
+		ID:       req.Id,
+		Status:   runStatuses[req.Status],
+		Error:    req.Error,
+		Counters: req.Counters,
+	}
+	err := s.runStorage.FinishRun(ctx, run)
+	if err == storage.ErrNotFound {
+		return nil, grpc.ErrNotFound(ctx, err)
+	} else if err == storage.ErrRunFinished {
+		return nil, grpc.ErrFailedPrecondition(ctx, err)
+	} else if err != nil {
+		return nil, storageError(ctx, err)
+	}
+	return runProto(run), nil
+}
+
+// List lists pipeline runs newest first, e.g. runs that produced no messages
+func (s *AIDecisionRunService) List(req *protos.RunListRequest, stream protos.AIDecisionRunService_ListServer) error {
+	ctx := stream.Context()
+	logger := log.FromContext(ctx).With(
+		log.Int(LogKeyAppID, int(req.AppId)),
+		log.String(LogKeyPipeline, req.Pipeline),
+		log.String(LogKeyRunStatus, req.Status.String()),
+	)
+	filter := &storage.RunFilter{
+		AppID:           req.AppId,
+		Pipeline:        req.Pipeline,
+		Status:          runStatuses[req.Status],
+		WithoutMessages: req.WithoutMessages,
+	}
+	if req.StartedFrom != nil {
+		v, _ := ptypes.Timestamp(req.StartedFrom)
+		filter.StartedFrom = &v
+		logger = logger.With(log.Time(LogKeyStartedFrom, v))
+	}
+	if req.StartedTo != nil {
+		v, _ := ptypes.Timestamp(req.StartedTo)
+		filter.StartedTo = &v
+		logger = logger.With(log.Time(LogKeyStartedTo, v))
+	}
+	ctx = log.WithLogger(ctx, logger)
+
+	if err := s.validateListRequest(ctx, req); err != nil {
+		return err
+	}
+	runs, err := s.runStorage.ListRuns(ctx, filter)
+	if err == storage.ErrNotFound {
+		return grpc.ErrNotFound(ctx, err)
+	} else if err != nil {
+		return storageError(ctx, err)
+	}
+	return sendRuns(stream, runs)
+}
+
+// LastSuccessful lists the newest successful run of each app
+func (s *AIDecisionRunService) LastSuccessful(req *protos.RunLastSuccessfulRequest, stream protos.AIDecisionRunService_LastSuccessfulServer) error {
+	ctx := log.WithLogger(stream.Context(), log.FromContext(stream.Context()).With(
+		log.Int(LogKeyAppID, int(req.AppId)),
+		log.String(LogKeyPipeline, req.Pipeline),
+	))
+	if err := validate(ctx, validateNonNegativeInt("app_id", req.AppId)); err != nil {
+		return err
+	}
+	runs, err := s.runStorage.LastSuccessfulRuns(ctx, req.AppId, req.Pipeline)
+	if err == storage.ErrNotFound {
+		return grpc.ErrNotFound(ctx, err)
+	} else if err != nil {
+		return storageError(ctx, err)
+	}
+	return sendRuns(stream, runs)
+}
+
+// runSender is implemented by the streams of all RPCs listing runs
+type runSender interface {
+	Send(*protos.Run) error
+}
+
+func sendRuns(stream runSender, runs []*storage.AidRun) error {
+	for _, run := range runs {
+		if err := stream.Send(runProto(run)); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+func runProto(run *storage.AidRun) *protos.Run {
+	startedAt, _ := ptypes.TimestampProto(run.StartedAt)
+	r := &protos.Run{
+		Id:           run.ID,
+		AppId:        run.AppID,
+		Pipeline:     run.Pipeline,
+		Error:        run.Error,
+		StartedAt:    startedAt,
+		Counters:     run.Counters,
+		MessageCount: run.MessageCount,
+		StateCount:   run.StateCount,
+	}
+	for status, stored := range runStatuses {
+		if stored == run.Status {
+			r.Status = status
+		}
+	}
+	if !run.FinishedAt.IsZero() {
+		r.FinishedAt, _ = ptypes.TimestampProto(run.FinishedAt)
+	}
+	return r
+}
+
+func validateFinishedRunStatus(field string, status protos.RunStatus) error {
+	if status != protos.RunStatus_RUN_SUCCEEDED && status != protos.RunStatus_RUN_FAILED {
+		return violation(field, "must be RUN_SUCCEEDED or RUN_FAILED")
+	}
+	return nil
+}
+
+func (s *AIDecisionRunService) validateStartRequest(ctx context.Context, req *protos.RunStartRequest) error {
+	return validate(ctx,
+		validatePositiveInt("app_id", req.AppId),
+		validateNonEmptyString("pipeline", req.Pipeline),
+	)
+}
+
+func (s *AIDecisionRunService) validateFinishRequest(ctx context.Context, req *protos.RunFinishRequest) error {
+	return validate(ctx,
+		validatePositiveInt("id", req.Id),
+		validateFinishedRunStatus("status", req.Status),
+	)
+}
+
+func (s *AIDecisionRunService) validateListRequest(ctx context.Context, req *protos.RunListRequest) error {
+	return validate(ctx,
+		validateNonNegativeInt("app_id", req.AppId),
+		validateTimeRange("started_to", req.StartedTo, "started_from", req.StartedFrom),
+	)
+}
@@ -0,0 +1,374 @@
+package service_test
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/callstats-io/ai-decision/service/gen/protos"
+	"github.com/callstats-io/ai-decision/service/src/storage"
+	"github.com/golang/protobuf/ptypes"
+	"github.com/stretchr/testify/require"
+)
+
+func TestRunStart(t *testing.T) {
+	// ensure no leakage between tests
+	defer mockStorage.Reset()
+
+	startedAt := time.Now()
+	startedAtProto, _ := ptypes.TimestampProto(startedAt)
+
+	tests := []struct {
+		Description string
+		ExpErrorMsg string
+		Setup       func(req *protos.RunStartRequest) *protos.Run
+	}{
+		{
+			Description: "valid request",
+			Setup: func(req *protos.RunStartRequest) *protos.Run {
+				mockStorage.Reset()
+				mockStorage.MockSavedRuns([]*storage.AidRun{
+					{ID: 7, AppID: req.AppId, Pipeline: req.Pipeline, Status: storage.RunStatusRunning, StartedAt: startedAt},
+				})
+				return &protos.Run{
+					Id:        7,
+					AppId:     req.AppId,
+					Pipeline:  req.Pipeline,
+					Status:    protos.RunStatus_RUN_RUNNING,
+					StartedAt: startedAtProto,
+				}
+			},
+		},
+		{
+			Description: "missing app id",
+			ExpErrorMsg: "rpc error: code = InvalidArgument desc = app_id: must be a positive integer",
+			Setup: func(req *protos.RunStartRequest) *protos.Run {
+				req.AppId = 0
+				return nil
+			},
+		},
+		{
+			Description: "missing pipeline",
+			ExpErrorMsg: "rpc error: code = InvalidArgument desc = pipeline: cannot be empty",
+			Setup: func(req *protos.RunStartRequest) *protos.Run {
+				req.Pipeline = ""
+				return nil
+			},
+		},
+		{
+			Description: "run start error",
+			ExpErrorMsg: "rpc error: code = Unavailable desc = EXPECTED RUN START TEST ERROR",
+			Setup: func(req *protos.RunStartRequest) *protos.Run {
+				mockStorage.Reset()
+				mockStorage.MockStartRunError(errors.New("EXPECTED RUN START TEST ERROR"))
+				return nil
+			},
+		},
+	}
+
+	for _, test := range tests {
+		t.Run(test.Description, func(t *testing.T) {
+			assert := require.New(t)
+
+			// create a valid request, expect Setup to invalidate if needed
+			req := &protos.RunStartRequest{
+				AppId:    123,
+				Pipeline: "daily-insights",
+			}
+			expRun := test.Setup(req)
+
+			// exec test
+			resp, err := testRunClient.Start(context.Background(), req)
+			if test.ExpErrorMsg != "" {
+				assert.EqualError(err, test.ExpErrorMsg)
+				return
+			}
+			assert.Nil(err)
+			assert.Equal(expRun.StartedAt.Seconds, resp.StartedAt.Seconds)
+			assert.Equal(expRun.StartedAt.Nanos, resp.StartedAt.Nanos)
+			expRun.StartedAt = nil
+			resp.StartedAt = nil
+			assert.Equal(expRun, resp)
+			assert.Equal(1, mockStorage.StartRunCalls())
+		})
+	}
+}
+
+func TestRunFinish(t *testing.T) {
+	// ensure no leakage between tests
+	defer mockStorage.Reset()
+
+	startedAt := time.Now().Add(-time.Hour)
+	finishedAt := time.Now()
+	finishedAtProto, _ := ptypes.TimestampProto(finishedAt)
+
+	tests := []struct {
+		Description string
+		ExpErrorMsg string
+		Setup       func(req *protos.RunFinishRequest) *protos.Run
+	}{
+		{
+			Description: "valid request",
+			Setup: func(req *protos.RunFinishRequest) *protos.Run {
+				mockStorage.Reset()
+				mockStorage.MockSavedRuns([]*storage.AidRun{
+					{
+						ID:         req.Id,
+						AppID:      123,
+						Pipeline:   "daily-insights",
+						Status:     storage.RunStatusFailed,
+						Error:      req.Error,
+						Counters:   req.Counters,
+						StartedAt:  startedAt,
+						FinishedAt: finishedAt,
+					},
+				})
+				return &protos.Run{
+					Id:         req.Id,
+					AppId:      123,
+					Pipeline:   "daily-insights",
+					Status:     protos.RunStatus_RUN_FAILED,
+					Error:      "timeout",
+					Counters:   map[string]int64{"apps": 10, "messages": 0},
+					FinishedAt: finishedAtProto,
+				}
+			},
+		},
+		{
+			Description: "unknown run",
+			ExpErrorMsg: "rpc error: code = NotFound desc = not found",
+			Setup: func(req *protos.RunFinishRequest) *protos.Run {
+				mockStorage.Reset()
+				mockStorage.MockFinishRunError(storage.ErrNotFound)
+				return nil
+			},
+		},
+		{
+			Description: "run already finished",
+			ExpErrorMsg: "rpc error: code = FailedPrecondition desc = run is already finished",
+			Setup: func(req *protos.RunFinishRequest) *protos.Run {
+				mockStorage.Reset()
+				mockStorage.MockFinishRunError(storage.ErrRunFinished)
+				return nil
+			},
+		},
+		{
+			Description: "missing id",
+			ExpErrorMsg: "rpc error: code = InvalidArgument desc = id: must be a positive integer",
+			Setup: func(req *protos.RunFinishRequest) *protos.Run {
+				req.Id = 0
+				return nil
+			},
+		},
+		{
+			Description: "running status",
+			ExpErrorMsg: "rpc error: code = InvalidArgument desc = status: must be RUN_SUCCEEDED or RUN_FAILED",
+			Setup: func(req *protos.RunFinishRequest) *protos.Run {
+				req.Status = protos.RunStatus_RUN_RUNNING
+				return nil
+			},
+		},
+		{
+			Description: "run finish error",
+			ExpErrorMsg: "rpc error: code = Unavailable desc = EXPECTED RUN FINISH TEST ERROR",
+			Setup: func(req *protos.RunFinishRequest) *protos.Run {
+				mockStorage.Reset()
+				mockStorage.MockFinishRunError(errors.New("EXPECTED RUN FINISH TEST ERROR"))
+				return nil
+			},
+		},
+	}
+
+	for _, test := range tests {
+		t.Run(test.Description, func(t *testing.T) {
+			assert := require.New(t)
+
+			// create a valid request, expect Setup to invalidate if needed
+			req := &protos.RunFinishRequest{
+				Id:       7,
+				Status:   protos.RunStatus_RUN_FAILED,
+				Error:    "timeout",
+				Counters: map[string]int64{"apps": 10, "messages": 0},
+			}
+			expRun := test.Setup(req)
+
+			// exec test
+			resp, err := testRunClient.Finish(context.Background(), req)
+			if test.ExpErrorMsg != "" {
+				assert.EqualError(err, test.ExpErrorMsg)
+				return
+			}
+			assert.Nil(err)
+			assert.Equal(expRun.FinishedAt.Seconds, resp.FinishedAt.Seconds)
+			assert.Equal(expRun.FinishedAt.Nanos, resp.FinishedAt.Nanos)
+			expRun.FinishedAt = nil
+			resp.FinishedAt = nil
+			resp.StartedAt = nil
+			assert.Equal(expRun, resp)
+			assert.Equal(1, mockStorage.FinishRunCalls())
+		})
+	}
+}
+
+func TestRunList(t *testing.T) {
+	// ensure no leakage between tests
+	defer mockStorage.Reset()
+
+	tests := []struct {
+		Description string
+		ExpErrorMsg string
+		Setup       func(req *protos.RunListRequest) []*protos.Run
+	}{
+		{
+			Description: "valid request",
+			Setup: func(req *protos.RunListRequest) []*protos.Run {
+				mockStorage.Reset()
+				mockStorage.MockSavedRuns([]*storage.AidRun{
+					{ID: 2, AppID: 123, Pipeline: "daily-insights", Status: storage.RunStatusSucceeded, StateCount: 4},
+					{ID: 1, AppID: 123, Pipeline: "daily-insights", Status: storage.RunStatusFailed},
+				})
+				return []*protos.Run{
+					{Id: 2, AppId: 123, Pipeline: "daily-insights", Status: protos.RunStatus_RUN_SUCCEEDED, StateCount: 4},
+					{Id: 1, AppId: 123, Pipeline: "daily-insights", Status: protos.RunStatus_RUN_FAILED},
+				}
+			},
+		},
+		{
+			Description: "no runs",
+			ExpErrorMsg: "rpc error: code = NotFound desc = not found",
+			Setup: func(req *protos.RunListRequest) []*protos.Run {
+				mockStorage.Reset()
+				mockStorage.MockListRunsError(storage.ErrNotFound)
+				return nil
+			},
+		},
+		{
+			Description: "negative app id",
+			ExpErrorMsg: "rpc error: code = InvalidArgument desc = app_id: cannot be negative",
+			Setup: func(req *protos.RunListRequest) []*protos.Run {
+				req.AppId = -1
+				return nil
+			},
+		},
+		{
+			Description: "started to before started from",
+			ExpErrorMsg: "rpc error: code = InvalidArgument desc = started_to: cannot be before started_from",
+			Setup: func(req *protos.RunListRequest) []*protos.Run {
+				req.StartedFrom, _ = ptypes.TimestampProto(time.Now())
+				req.StartedTo, _ = ptypes.TimestampProto(time.Now().Add(-time.Hour))
+				return nil
+			},
+		},
+		{
+			Description: "run list error",
+			ExpErrorMsg: "rpc error: code = Unavailable desc = EXPECTED RUN LIST TEST ERROR",
+			Setup: func(req *protos.RunListRequest) []*protos.Run {
+				mockStorage.Reset()
+				mockStorage.MockListRunsError(errors.New("EXPECTED RUN LIST TEST ERROR"))
+				return nil
+			},
+		},
+	}
+
+	for _, test := range tests {
+		t.Run(test.Description, func(t *testing.T) {
+			assert := require.New(t)
+
+			// create a valid request, expect Setup to invalidate if needed
+			req := &protos.RunListRequest{AppId: 123, WithoutMessages: true}
+			expRuns := test.Setup(req)
+
+			// exec test
+			stream, err := testRunClient.List(context.Background(), req)
+			assert.Nil(err)
+			if test.ExpErrorMsg != "" {
+				_, err := stream.Recv()
+				assert.EqualError(err, test.ExpErrorMsg)
+				return
+			}
+			for _, exp := range expRuns {
+				resp, err := stream.Recv()
+				assert.Nil(err)
+				resp.StartedAt = nil
+				assert.Equal(exp, resp)
+			}
+
+			// check no more values
+			_, err = stream.Recv()
+			assert.EqualError(err, "EOF")
+		})
+	}
+}
+
+func TestRunLastSuccessful(t *testing.T) {
+	// ensure no leakage between tests
+	defer mockStorage.Reset()
+
+	tests := []struct {
+		Description string
+		ExpErrorMsg string
+		Setup       func(req *protos.RunLastSuccessfulRequest) []*protos.Run
+	}{
+		{
+			Description: "valid request",
+			Setup: func(req *protos.RunLastSuccessfulRequest) []*protos.Run {
+				mockStorage.Reset()
+				mockStorage.MockSavedRuns([]*storage.AidRun{
+					{ID: 5, AppID: 123, Pipeline: "daily-insights", Status: storage.RunStatusSucceeded, MessageCount: 2},
+					{ID: 3, AppID: 456, Pipeline: "daily-insights", Status: storage.RunStatusSucceeded},
+				})
+				return []*protos.Run{
+					{Id: 5, AppId: 123, Pipeline: "daily-insights", Status: protos.RunStatus_RUN_SUCCEEDED, MessageCount: 2},
+					{Id: 3, AppId: 456, Pipeline: "daily-insights", Status: protos.RunStatus_RUN_SUCCEEDED},
+				}
+			},
+		},
+		{
+			Description: "no successful runs",
+			ExpErrorMsg: "rpc error: code = NotFound desc = not found",
+			Setup: func(req *protos.RunLastSuccessfulRequest) []*protos.Run {
+				mockStorage.Reset()
+				mockStorage.MockLastSuccessfulRunsError(storage.ErrNotFound)
+				return nil
+			},
+		},
+		{
+			Description: "negative app id",
+			ExpErrorMsg: "rpc error: code = InvalidArgument desc = app_id: cannot be negative",
+			Setup: func(req *protos.RunLastSuccessfulRequest) []*protos.Run {
+				req.AppId = -1
+				return nil
+			},
+		},
+	}
+
+	for _, test := range tests {
+		t.Run(test.Description, func(t *testing.T) {
+			assert := require.New(t)
+
+			// create a valid request, expect Setup to invalidate if needed
+			req := &protos.RunLastSuccessfulRequest{Pipeline: "daily-insights"}
+			expRuns := test.Setup(req)
+
+			// exec test
+			stream, err := testRunClient.LastSuccessful(context.Background(), req)
+			assert.Nil(err)
+			if test.ExpErrorMsg != "" {
+				_, err := stream.Recv()
+				assert.EqualError(err, test.ExpErrorMsg)
+				return
+			}
+			for _, exp := range expRuns {
+				resp, err := stream.Recv()
+				assert.Nil(err)
+				resp.StartedAt = nil
+				assert.Equal(exp, resp)
+			}
+
+			// check no more values
+			_, err = stream.Recv()
+			assert.EqualError(err, "EOF")
+		})
+	}
+}
@@ -1,7 +1,13 @@
 package service
 
 import (
+	"bytes"
 	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"io"
 	"time"
 
 	"github.com/callstats-io/ai-decision/service/gen/protos"
@@ -9,18 +15,31 @@ import (
 	"github.com/callstats-io/ai-decision/service/src/storage"
 	"github.com/callstats-io/go-common/log"
 	"github.com/golang/protobuf/ptypes"
+	"google.golang.org/grpc/peer"
 )
 
 // StateStorage defines the interface state service expects from applicable storages
 type StateStorage interface {
 	SaveState(ctx context.Context, state *storage.AidAnalyticsState) error
+	SaveStateRevision(ctx context.Context, state *storage.AidAnalyticsState, expectedRevision int32) error
+	SaveStateChunks(ctx context.Context, state *storage.AidAnalyticsState, chunks [][]byte, expectedRevision int32, force bool) error
+	GetStateChunk(ctx context.Context, stateID int32, seq int32) ([]byte, error)
 	GetState(ctx context.Context, state *storage.AidAnalyticsState) error
-	ListStates(ctx context.Context, appID int32, keyword string, from, to *time.Time) ([]*storage.AidAnalyticsState, error)
+	GetLatestState(ctx context.Context, appID int32, keyword string, asOf *time.Time) (*storage.AidAnalyticsState, error)
+	ListStates(ctx context.Context, appID int32, keyword string, from, to *time.Time, opts ...storage.ListOption) ([]*storage.AidAnalyticsState, error)
+	DeleteStates(ctx context.Context, appID int32, keyword string, from, to time.Time, dryRun bool) (int, error)
+	GetKeyword(ctx context.Context, keyword string) (*storage.AidAnalyticsKeyword, error)
+	ListKeywords(ctx context.Context, appID int32) ([]*storage.KeywordUsage, error)
+	SaveCompactionPolicy(ctx context.Context, policy *storage.AidAnalyticsCompactionPolicy) error
+	ListCompactionPolicies(ctx context.Context) ([]*storage.AidAnalyticsCompactionPolicy, error)
+	Compact(ctx context.Context, keyword string, dryRun bool) ([]*storage.Compaction, error)
 }
 
 // AIDecisionStateService implements the protos AIDecisionStateServiceServer
 type AIDecisionStateService struct {
-	stateStorage StateStorage
+	stateStorage   StateStorage
+	strictKeywords bool
+	maxSize        int32
 }
 
 var _ = protos.AIDecisionStateServiceServer(&AIDecisionStateService{})
@@ -29,11 +48,26 @@ var _ = protos.AIDecisionStateServiceServer(&AIDecisionStateService{})
 func NewAIDecisionStateService(storage StateStorage) (*AIDecisionStateService, error) {
 	s := &AIDecisionStateService{
 		stateStorage: storage,
+		maxSize:      DefaultStateMaxSize,
 	}
 	return s, nil
 }
 
-// Save stores AI decision analytics state
+// WithStrictKeywords sets whether states with keywords not in the keyword registry are rejected
+func (s *AIDecisionStateService) WithStrictKeywords(strict bool) *AIDecisionStateService {
+	s.strictKeywords = strict
+	return s
+}
+
+// WithMaxSize sets the maximum size of the data of states whose keywords are not registered with a maximum size,
+// 0 if unlimited. Mainly used in tests.
+func (s *AIDecisionStateService) WithMaxSize(maxSize int32) *AIDecisionStateService {
+	s.maxSize = maxSize
+	return s
+}
+
+// Save stores AI decision analytics state.
+// Unless forced, the save is aborted if the stored revision does not match the expected revision.
 func (s *AIDecisionStateService) Save(ctx context.Context, req *protos.StateSaveRequest) (*protos.State, error) {
 	savedAt, _ := ptypes.Timestamp(req.GenerationTime)
 	ctx = log.WithLogger(ctx, log.FromContext(ctx).With(
@@ -44,25 +78,42 @@ func (s *AIDecisionStateService) Save(ctx context.Context, req *protos.StateSave
 	if err := s.validateSaveRequest(ctx, req); err != nil {
 		return nil, err
 	}
+	keyword, err := s.registeredKeyword(ctx, req.Keyword)
+	if err != nil {
+		return nil, err
+	}
+	if err := s.validateKeywordData(ctx, keyword, req.Data); err != nil {
+		return nil, err
+	}
+	schemaVersion, err := s.schemaVersion(ctx, keyword, req.SchemaVersion)
+	if err != nil {
+		return nil, err
+	}
 
 	state := &storage.AidAnalyticsState{
-		AppID:   req.AppId,
-		Keyword: req.Keyword,
-		Data:    req.Data,
-		SavedAt: savedAt,
+		AppID:         req.AppId,
+		Keyword:       req.Keyword,
+		Data:          req.Data,
+		SavedAt:       savedAt,
+		SchemaVersion: schemaVersion,
+		FencingToken:  req.FencingToken,
+		RunID:         req.RunId,
 	}
-	if err := s.stateStorage.SaveState(ctx, state); err != nil {
-		return nil, grpc.ErrUnavailable(ctx, err)
+	if req.Force {
+		err = s.stateStorage.SaveState(ctx, state)
+	} else {
+		err = s.stateStorage.SaveStateRevision(ctx, state, req.ExpectedRevision)
+	}
+	if err == storage.ErrRevisionMismatch {
+		return nil, grpc.ErrAborted(ctx, err)
+	} else if err == storage.ErrLeaseLost || err == storage.ErrUnknownRun {
+		return nil, grpc.ErrFailedPrecondition(ctx, err)
+	} else if err != nil {
+		return nil, storageError(ctx, err)
 	}
 
 	// echo state back to caller
-	savedAtProto, _ := ptypes.TimestampProto(state.SavedAt)
-	return &protos.State{
-		AppId:          state.AppID,
-		Keyword:        state.Keyword,
-		Data:           state.Data,
-		GenerationTime: savedAtProto,
-	}, nil
+	return stateProto(state, keyword), nil
 }
 
 // Get retrieves AI decision analytics state
@@ -76,6 +127,10 @@ func (s *AIDecisionStateService) Get(ctx context.Context, req *protos.StateGetRe
 	if err := s.validateGetRequest(ctx, req); err != nil {
 		return nil, err
 	}
+	keyword, err := s.registeredKeyword(ctx, req.Keyword)
+	if err != nil {
+		return nil, err
+	}
 
 	state := &storage.AidAnalyticsState{
 		AppID:   req.AppId,
@@ -85,17 +140,241 @@ func (s *AIDecisionStateService) Get(ctx context.Context, req *protos.StateGetRe
 	if err := s.stateStorage.GetState(ctx, state); err == storage.ErrNotFound {
 		return nil, grpc.ErrNotFound(ctx, err)
 	} else if err != nil {
-		return nil, grpc.ErrUnavailable(ctx, err)
+		return nil, storageError(ctx, err)
 	}
 
 	// echo state back to caller
-	savedAtProto, _ := ptypes.TimestampProto(state.SavedAt)
-	return &protos.State{
-		AppId:          state.AppID,
-		Keyword:        state.Keyword,
-		Data:           state.Data,
-		GenerationTime: savedAtProto,
-	}, nil
+	return stateProto(state, keyword), nil
+}
+
+// SaveChunked stores AI decision analytics state uploaded in chunks.
+// The data is verified against the checksum sent with the last chunk and stored in chunks of StateChunkSize.
+// Uploads exceeding the maximum size of the keyword, or DefaultStateMaxSize if it has none, are rejected.
+func (s *AIDecisionStateService) SaveChunked(stream protos.AIDecisionStateService_SaveChunkedServer) error {
+	ctx := stream.Context()
+	chunk, err := stream.Recv()
+	if err == io.EOF {
+		return grpc.ErrInvalidArgument(ctx, errors.New("state: cannot be nil"))
+	} else if err != nil {
+		return err
+	}
+	req := chunk.State
+	if req == nil {
+		return grpc.ErrInvalidArgument(ctx, errors.New("state: cannot be nil"))
+	}
+	savedAt, _ := ptypes.Timestamp(req.GenerationTime)
+	ctx = log.WithLogger(ctx, log.FromContext(ctx).With(
+		log.Int(LogKeyAppID, int(req.AppId)),
+		log.String(LogKeyKeyword, req.Keyword),
+		log.Time(LogKeyGenerationTime, savedAt),
+	))
+	if err := s.validateSaveChunkedRequest(ctx, req); err != nil {
+		return err
+	}
+	keyword, err := s.registeredKeyword(ctx, req.Keyword)
+	if err != nil {
+		return err
+	}
+	schemaVersion, err := s.schemaVersion(ctx, keyword, req.SchemaVersion)
+	if err != nil {
+		return err
+	}
+
+	// chunks are buffered until the upload is complete, the size is checked as they arrive
+	maxSize := s.maxSize
+	if keyword != nil && keyword.MaxSize > 0 {
+		maxSize = keyword.MaxSize
+	}
+	hash := sha256.New()
+	var chunks [][]byte
+	var checksum string
+	var size int
+	for {
+		size += len(chunk.Data)
+		if err := validate(ctx, validateMaxSize("data", size, maxSize)); err != nil {
+			return err
+		}
+		hash.Write(chunk.Data)
+		chunks = appendChunks(chunks, chunk.Data)
+		if chunk.Checksum != "" {
+			checksum = chunk.Checksum
+		}
+		if chunk, err = stream.Recv(); err == io.EOF {
+			break
+		} else if err != nil {
+			return err
+		}
+	}
+	sum := hex.EncodeToString(hash.Sum(nil))
+	if err := validate(ctx,
+		validateNonEmptyBytes("data", bytesOf(chunks)),
+		validateNonEmptyString("checksum", checksum),
+		validateChecksum("checksum", checksum, sum),
+	); err != nil {
+		return err
+	}
+	if keyword != nil && keyword.ContentType == ContentTypeJSON {
+		if err := s.validateKeywordData(ctx, keyword, bytes.Join(chunks, nil)); err != nil {
+			return err
+		}
+	}
+
+	state := &storage.AidAnalyticsState{
+		AppID:         req.AppId,
+		Keyword:       req.Keyword,
+		SavedAt:       savedAt,
+		Checksum:      sum,
+		SchemaVersion: schemaVersion,
+		FencingToken:  req.FencingToken,
+		RunID:         req.RunId,
+	}
+	err = s.stateStorage.SaveStateChunks(ctx, state, chunks, req.ExpectedRevision, req.Force)
+	if err == storage.ErrRevisionMismatch {
+		return grpc.ErrAborted(ctx, err)
+	} else if err == storage.ErrLeaseLost || err == storage.ErrUnknownRun {
+		return grpc.ErrFailedPrecondition(ctx, err)
+	} else if err != nil {
+		return storageError(ctx, err)
+	}
+
+	state.ChunkCount = int32(len(chunks))
+	return stream.SendAndClose(stateProto(state, keyword))
+}
+
+// GetChunked retrieves AI decision analytics state in chunks of at most StateChunkSize.
+// The first chunk carries the state without data and the last chunk the checksum of the data.
+// States not saved with SaveChunked are chunked on the fly.
+func (s *AIDecisionStateService) GetChunked(req *protos.StateGetRequest, stream protos.AIDecisionStateService_GetChunkedServer) error {
+	ctx := stream.Context()
+	savedAt, _ := ptypes.Timestamp(req.GenerationTime)
+	ctx = log.WithLogger(ctx, log.FromContext(ctx).With(
+		log.Int(LogKeyAppID, int(req.AppId)),
+		log.String(LogKeyKeyword, req.Keyword),
+		log.Time(LogKeyGenerationTime, savedAt),
+	))
+	if err := s.validateGetRequest(ctx, req); err != nil {
+		return err
+	}
+	keyword, err := s.registeredKeyword(ctx, req.Keyword)
+	if err != nil {
+		return err
+	}
+
+	state := &storage.AidAnalyticsState{
+		AppID:   req.AppId,
+		Keyword: req.Keyword,
+		SavedAt: savedAt,
+	}
+	if err := s.stateStorage.GetState(ctx, state); err == storage.ErrNotFound {
+		return grpc.ErrNotFound(ctx, err)
+	} else if err != nil {
+		return storageError(ctx, err)
+	}
+
+	// states saved with Save are held in memory already, chunked states are fetched chunk by chunk
+	inline := appendChunks(nil, state.Data)
+	count := int32(len(inline))
+	if state.ChunkCount > 0 {
+		count = state.ChunkCount
+	}
+
+	hash := sha256.New()
+	for seq := int32(0); seq == 0 || seq < count; seq++ {
+		msg := &protos.StateChunk{}
+		if seq == 0 {
+			msg.State = stateProto(state, keyword)
+			msg.State.Data = nil
+		}
+		if state.ChunkCount > 0 {
+			data, err := s.stateStorage.GetStateChunk(ctx, state.ID, seq)
+			if err == storage.ErrNotFound {
+				return grpc.ErrDataLoss(ctx, errors.New("missing state chunk"))
+			} else if err != nil {
+				return storageError(ctx, err)
+			}
+			msg.Data = data
+		} else if seq < count {
+			msg.Data = inline[seq]
+		}
+		hash.Write(msg.Data)
+
+		if seq >= count-1 {
+			msg.Checksum = hex.EncodeToString(hash.Sum(nil))
+			if state.Checksum != "" && msg.Checksum != state.Checksum {
+				return grpc.ErrDataLoss(ctx, errors.New("state checksum mismatch"))
+			}
+		}
+		if err := stream.Send(msg); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// appendChunks appends data to chunks, filling up the last chunk to StateChunkSize before starting a new one
+func appendChunks(chunks [][]byte, data []byte) [][]byte {
+	for len(data) > 0 {
+		if n := len(chunks); n == 0 || len(chunks[n-1]) == StateChunkSize {
+			chunks = append(chunks, nil)
+		}
+		last := len(chunks) - 1
+		n := StateChunkSize - len(chunks[last])
+		if n > len(data) {
+			n = len(data)
+		}
+		chunks[last] = append(chunks[last], data[:n]...)
+		data = data[n:]
+	}
+	return chunks
+}
+
+// bytesOf returns the first chunk or nil if there are no chunks
+func bytesOf(chunks [][]byte) []byte {
+	if len(chunks) == 0 {
+		return nil
+	}
+	return chunks[0]
+}
+
+// GetLatest retrieves the newest AI decision analytics state
+func (s *AIDecisionStateService) GetLatest(ctx context.Context, req *protos.StateGetLatestRequest) (*protos.State, error) {
+	ctx = log.WithLogger(ctx, log.FromContext(ctx).With(
+		log.Int(LogKeyAppID, int(req.AppId)),
+		log.String(LogKeyKeyword, req.Keyword),
+	))
+	if err := s.validateGetLatestRequest(ctx, req); err != nil {
+		return nil, err
+	}
+	return s.getLatest(ctx, req.AppId, req.Keyword, nil)
+}
+
+// GetAsOf retrieves the newest AI decision analytics state generated at or before the given time
+func (s *AIDecisionStateService) GetAsOf(ctx context.Context, req *protos.StateGetAsOfRequest) (*protos.State, error) {
+	asOf, _ := ptypes.Timestamp(req.AsOf)
+	ctx = log.WithLogger(ctx, log.FromContext(ctx).With(
+		log.Int(LogKeyAppID, int(req.AppId)),
+		log.String(LogKeyKeyword, req.Keyword),
+		log.Time(LogKeyAsOf, asOf),
+	))
+	if err := s.validateGetAsOfRequest(ctx, req); err != nil {
+		return nil, err
+	}
+	return s.getLatest(ctx, req.AppId, req.Keyword, &asOf)
+}
+
+func (s *AIDecisionStateService) getLatest(ctx context.Context, appID int32, keyword string, asOf *time.Time) (*protos.State, error) {
+	registered, err := s.registeredKeyword(ctx, keyword)
+	if err != nil {
+		return nil, err
+	}
+	state, err := s.stateStorage.GetLatestState(ctx, appID, keyword, asOf)
+	if err == storage.ErrNotFound {
+		return nil, grpc.ErrNotFound(ctx, err)
+	} else if err != nil {
+		return nil, storageError(ctx, err)
+	}
+
+	return stateProto(state, registered), nil
 }
 
 // List retrieves AI decision analytics states within a time range
@@ -118,37 +397,293 @@ func (s *AIDecisionStateService) List(req *protos.StateListRequest, stream proto
 	}
 	ctx = log.WithLogger(ctx, logger)
 
-	if err := s.validateListRequest(ctx, req); err != nil {
+	mask, err := s.validateListRequest(ctx, req)
+	if err != nil {
 		return err
 	}
-	states, err := s.stateStorage.ListStates(ctx, req.AppId, req.Keyword, savedAtFrom, savedAtTo)
+	keywords := map[string]*storage.AidAnalyticsKeyword{}
+	if req.Keyword != "" {
+		keyword, err := s.registeredKeyword(ctx, req.Keyword)
+		if err != nil {
+			return err
+		}
+		keywords[req.Keyword] = keyword
+	}
+	states, err := s.stateStorage.ListStates(ctx, req.AppId, req.Keyword, savedAtFrom, savedAtTo, mask.listOptions("data")...)
 	if err == storage.ErrNotFound {
 		return grpc.ErrNotFound(ctx, err)
 	} else if err != nil {
-		return grpc.ErrUnavailable(ctx, err)
+		return storageError(ctx, err)
+	}
+
+	for _, state := range states {
+		keyword, ok := keywords[state.Keyword]
+		if !ok {
+			// unregistered keywords are listed even in strict mode, they just lack a content type
+			keyword, err = s.stateStorage.GetKeyword(ctx, state.Keyword)
+			if err != nil && err != storage.ErrNotFound {
+				return storageError(ctx, err)
+			}
+			keywords[state.Keyword] = keyword
+		}
+		resp := stateProto(state, keyword)
+		mask.apply(resp)
+		if err := stream.Send(resp); err != nil {
+			return err
+		}
 	}
 
-	for _, s := range states {
-		genTime, _ := ptypes.TimestampProto(s.SavedAt)
-		if err := stream.Send(&protos.State{
-			AppId:          s.AppID,
-			Keyword:        s.Keyword,
-			Data:           s.Data,
-			GenerationTime: genTime,
+	return nil
+}
+
+// Delete deletes an AI decision analytics state, dry runs only check the state exists
+func (s *AIDecisionStateService) Delete(ctx context.Context, req *protos.StateDeleteRequest) (*protos.StateDeleteResponse, error) {
+	savedAt, _ := ptypes.Timestamp(req.GenerationTime)
+	ctx = log.WithLogger(ctx, log.FromContext(ctx).With(
+		log.Int(LogKeyAppID, int(req.AppId)),
+		log.String(LogKeyKeyword, req.Keyword),
+		log.Time(LogKeyGenerationTime, savedAt),
+		log.Bool(LogKeyDryRun, req.DryRun),
+	))
+	if err := s.validateDeleteRequest(ctx, req); err != nil {
+		return nil, err
+	}
+
+	deleted, err := s.deleteStates(ctx, req.AppId, req.Keyword, savedAt, savedAt, req.DryRun)
+	if err != nil {
+		return nil, err
+	}
+	if deleted == 0 {
+		return nil, grpc.ErrNotFound(ctx, storage.ErrNotFound)
+	}
+	return &protos.StateDeleteResponse{Deleted: deleted, DryRun: req.DryRun}, nil
+}
+
+// DeleteRange deletes the AI decision analytics states of a keyword within a time range, dry runs only count them
+func (s *AIDecisionStateService) DeleteRange(ctx context.Context, req *protos.StateDeleteRangeRequest) (*protos.StateDeleteResponse, error) {
+	from, _ := ptypes.Timestamp(req.GenerationTimeFrom)
+	to, _ := ptypes.Timestamp(req.GenerationTimeTo)
+	ctx = log.WithLogger(ctx, log.FromContext(ctx).With(
+		log.Int(LogKeyAppID, int(req.AppId)),
+		log.String(LogKeyKeyword, req.Keyword),
+		log.Time(LogKeyGenerationTimeFrom, from),
+		log.Time(LogKeyGenerationTimeTo, to),
+		log.Bool(LogKeyDryRun, req.DryRun),
+	))
+	if err := s.validateDeleteRangeRequest(ctx, req); err != nil {
+		return nil, err
+	}
+
+	deleted, err := s.deleteStates(ctx, req.AppId, req.Keyword, from, to, req.DryRun)
+	if err != nil {
+		return nil, err
+	}
+	return &protos.StateDeleteResponse{Deleted: deleted, DryRun: req.DryRun}, nil
+}
+
+// deleteStates deletes states and writes an audit log entry of the deletion
+func (s *AIDecisionStateService) deleteStates(ctx context.Context, appID int32, keyword string, from, to time.Time, dryRun bool) (int32, error) {
+	if _, err := s.registeredKeyword(ctx, keyword); err != nil {
+		return 0, err
+	}
+	deleted, err := s.stateStorage.DeleteStates(ctx, appID, keyword, from, to, dryRun)
+	if err != nil {
+		return 0, storageError(ctx, err)
+	}
+
+	logger := log.FromContext(ctx).With(log.Bool(LogKeyAudit, true), log.Int(LogKeyDeleted, deleted))
+	if p, ok := peer.FromContext(ctx); ok {
+		logger = logger.With(log.String(LogKeyPeer, p.Addr.String()))
+	}
+	if dryRun {
+		logger.Info("dry run of state deletion")
+	} else {
+		logger.Info("deleted states")
+	}
+	return int32(deleted), nil
+}
+
+// ListKeywords lists the keywords AI decision analytics states are saved with for an app
+func (s *AIDecisionStateService) ListKeywords(req *protos.KeywordListRequest, stream protos.AIDecisionStateService_ListKeywordsServer) error {
+	ctx := log.WithLogger(stream.Context(), log.FromContext(stream.Context()).With(
+		log.Int(LogKeyAppID, int(req.AppId)),
+	))
+	if err := validate(ctx, validatePositiveInt("app_id", req.AppId)); err != nil {
+		return err
+	}
+	usages, err := s.stateStorage.ListKeywords(ctx, req.AppId)
+	if err == storage.ErrNotFound {
+		return grpc.ErrNotFound(ctx, err)
+	} else if err != nil {
+		return storageError(ctx, err)
+	}
+
+	for _, u := range usages {
+		latest, _ := ptypes.TimestampProto(u.LatestSavedAt)
+		if err := stream.Send(&protos.Keyword{
+			Keyword:              u.Keyword,
+			ContentType:          u.ContentType,
+			SchemaVersion:        u.SchemaVersion,
+			MaxSize:              u.MaxSize,
+			Owner:                u.Owner,
+			Registered:           u.Registered,
+			StateCount:           u.StateCount,
+			LatestGenerationTime: latest,
 		}); err != nil {
 			return err
 		}
 	}
+	return nil
+}
+
+// SetCompactionPolicy creates or replaces the compaction policy of a keyword
+func (s *AIDecisionStateService) SetCompactionPolicy(ctx context.Context, req *protos.CompactionPolicy) (*protos.CompactionPolicy, error) {
+	ctx = log.WithLogger(ctx, log.FromContext(ctx).With(
+		log.String(LogKeyKeyword, req.Keyword),
+	))
+	if err := s.validateCompactionPolicy(ctx, req); err != nil {
+		return nil, err
+	}
+
+	policy := &storage.AidAnalyticsCompactionPolicy{
+		Keyword:            req.Keyword,
+		KeepLast:           req.KeepLast,
+		KeepDailyAfterDays: req.KeepDailyAfterDays,
+		DeleteAfterDays:    req.DeleteAfterDays,
+	}
+	if err := s.stateStorage.SaveCompactionPolicy(ctx, policy); err != nil {
+		return nil, storageError(ctx, err)
+	}
+	return req, nil
+}
+
+// ListCompactionPolicies lists the compaction policies of all keywords
+func (s *AIDecisionStateService) ListCompactionPolicies(req *protos.CompactionPolicyListRequest, stream protos.AIDecisionStateService_ListCompactionPoliciesServer) error {
+	ctx := stream.Context()
+	policies, err := s.stateStorage.ListCompactionPolicies(ctx)
+	if err == storage.ErrNotFound {
+		return grpc.ErrNotFound(ctx, err)
+	} else if err != nil {
+		return storageError(ctx, err)
+	}
+
+	for _, p := range policies {
+		if err := stream.Send(&protos.CompactionPolicy{
+			Keyword:            p.Keyword,
+			KeepLast:           p.KeepLast,
+			KeepDailyAfterDays: p.KeepDailyAfterDays,
+			DeleteAfterDays:    p.DeleteAfterDays,
+		}); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// Compact deletes states not kept by the compaction policies on demand and reports the deleted states per app and keyword
+func (s *AIDecisionStateService) Compact(req *protos.CompactRequest, stream protos.AIDecisionStateService_CompactServer) error {
+	ctx := log.WithLogger(stream.Context(), log.FromContext(stream.Context()).With(
+		log.String(LogKeyKeyword, req.Keyword),
+		log.Bool(LogKeyDryRun, req.DryRun),
+	))
+	compactions, err := s.stateStorage.Compact(ctx, req.Keyword, req.DryRun)
+	if err == storage.ErrNotFound {
+		return grpc.ErrNotFound(ctx, err)
+	} else if err != nil {
+		return storageError(ctx, err)
+	}
 
+	for _, c := range compactions {
+		if err := stream.Send(&protos.Compaction{
+			AppId:   c.AppID,
+			Keyword: c.Keyword,
+			Deleted: c.Deleted,
+		}); err != nil {
+			return err
+		}
+	}
 	return nil
 }
 
+// registeredKeyword returns the registered keyword, or nil if it is not registered and keywords are not strict
+func (s *AIDecisionStateService) registeredKeyword(ctx context.Context, keyword string) (*storage.AidAnalyticsKeyword, error) {
+	k, err := s.stateStorage.GetKeyword(ctx, keyword)
+	if err == storage.ErrNotFound {
+		if s.strictKeywords {
+			return nil, grpc.ErrInvalidArgument(ctx, fmt.Errorf("keyword: %q is not registered", keyword))
+		}
+		return nil, nil
+	} else if err != nil {
+		return nil, storageError(ctx, err)
+	}
+	return k, nil
+}
+
+// validateKeywordData validates data against the size and content type of a registered keyword
+func (s *AIDecisionStateService) validateKeywordData(ctx context.Context, keyword *storage.AidAnalyticsKeyword, data []byte) error {
+	if keyword == nil {
+		return nil
+	}
+	return validate(ctx,
+		validateMaxSize("data", len(data), keyword.MaxSize),
+		validateContentType("data", data, keyword.ContentType),
+	)
+}
+
+// schemaVersion returns the schema version to save a state with.
+// A requested version must match the registered version, no requested version defaults to the registered version.
+func (s *AIDecisionStateService) schemaVersion(ctx context.Context, keyword *storage.AidAnalyticsKeyword, version int32) (int32, error) {
+	if keyword == nil {
+		return version, nil
+	}
+	if version != 0 && version != keyword.SchemaVersion {
+		return 0, grpc.ErrFailedPrecondition(ctx, fmt.Errorf("schema_version: %d does not match registered version %d", version, keyword.SchemaVersion))
+	}
+	return keyword.SchemaVersion, nil
+}
+
+// stateProto converts a stored state to its protos representation, keyword is nil for unregistered keywords
+func stateProto(state *storage.AidAnalyticsState, keyword *storage.AidAnalyticsKeyword) *protos.State {
+	savedAtProto, _ := ptypes.TimestampProto(state.SavedAt)
+	s := &protos.State{
+		AppId:          state.AppID,
+		Keyword:        state.Keyword,
+		Data:           state.Data,
+		GenerationTime: savedAtProto,
+		Revision:       state.Revision,
+		Chunked:        state.ChunkCount > 0,
+		SchemaVersion:  state.SchemaVersion,
+		RunId:          state.RunID,
+	}
+	if keyword != nil {
+		s.ContentType = keyword.ContentType
+	}
+	return s
+}
+
 func (s *AIDecisionStateService) validateSaveRequest(ctx context.Context, req *protos.StateSaveRequest) error {
 	return validate(ctx,
 		validatePositiveInt("app_id", req.AppId),
 		validateNonEmptyString("keyword", req.Keyword),
 		validateNonEmptyBytes("data", req.Data),
 		validateTimestamp("generation_time", req.GenerationTime),
+		validateNonNegativeInt("expected_revision", req.ExpectedRevision),
+		validateNonNegativeInt("schema_version", req.SchemaVersion),
+		validateNonNegativeInt64("fencing_token", req.FencingToken),
+		validateNonNegativeInt("run_id", req.RunId),
+	)
+}
+
+func (s *AIDecisionStateService) validateSaveChunkedRequest(ctx context.Context, req *protos.StateSaveRequest) error {
+	return validate(ctx,
+		validatePositiveInt("state.app_id", req.AppId),
+		validateNonEmptyString("state.keyword", req.Keyword),
+		validateEmptyBytes("state.data", req.Data),
+		validateTimestamp("state.generation_time", req.GenerationTime),
+		validateNonNegativeInt("state.expected_revision", req.ExpectedRevision),
+		validateNonNegativeInt("state.schema_version", req.SchemaVersion),
+		validateNonNegativeInt64("state.fencing_token", req.FencingToken),
+		validateNonNegativeInt("state.run_id", req.RunId),
 	)
 }
 
@@ -160,6 +695,53 @@ func (s *AIDecisionStateService) validateGetRequest(ctx context.Context, req *pr
 	)
 }
 
-func (s *AIDecisionStateService) validateListRequest(ctx context.Context, req *protos.StateListRequest) error {
-	return validate(ctx, validatePositiveInt("app_id", req.AppId))
+func (s *AIDecisionStateService) validateGetLatestRequest(ctx context.Context, req *protos.StateGetLatestRequest) error {
+	return validate(ctx,
+		validatePositiveInt("app_id", req.AppId),
+		validateNonEmptyString("keyword", req.Keyword),
+	)
+}
+
+func (s *AIDecisionStateService) validateGetAsOfRequest(ctx context.Context, req *protos.StateGetAsOfRequest) error {
+	return validate(ctx,
+		validatePositiveInt("app_id", req.AppId),
+		validateNonEmptyString("keyword", req.Keyword),
+		validateTimestamp("as_of", req.AsOf),
+	)
+}
+
+func (s *AIDecisionStateService) validateDeleteRequest(ctx context.Context, req *protos.StateDeleteRequest) error {
+	return validate(ctx,
+		validatePositiveInt("app_id", req.AppId),
+		validateNonEmptyString("keyword", req.Keyword),
+		validateTimestamp("generation_time", req.GenerationTime),
+	)
+}
+
+func (s *AIDecisionStateService) validateDeleteRangeRequest(ctx context.Context, req *protos.StateDeleteRangeRequest) error {
+	return validate(ctx,
+		validatePositiveInt("app_id", req.AppId),
+		validateNonEmptyString("keyword", req.Keyword),
+		validateTimestamp("generation_time_from", req.GenerationTimeFrom),
+		validateTimestamp("generation_time_to", req.GenerationTimeTo),
+		validateTimeRange("generation_time_to", req.GenerationTimeTo, "generation_time_from", req.GenerationTimeFrom),
+	)
+}
+
+func (s *AIDecisionStateService) validateCompactionPolicy(ctx context.Context, req *protos.CompactionPolicy) error {
+	return validate(ctx,
+		validateNonEmptyString("keyword", req.Keyword),
+		validateNonNegativeInt("keep_last", req.KeepLast),
+		validateNonNegativeInt("keep_daily_after_days", req.KeepDailyAfterDays),
+		validateNonNegativeInt("delete_after_days", req.DeleteAfterDays),
+		validateGreaterThan("delete_after_days", req.DeleteAfterDays, "keep_daily_after_days", req.KeepDailyAfterDays),
+	)
+}
+
+func (s *AIDecisionStateService) validateListRequest(ctx context.Context, req *protos.StateListRequest) (readMask, error) {
+	mask, maskErr := newReadMask("read_mask", req.ReadMask, &protos.State{})
+	return mask, validate(ctx,
+		validatePositiveInt("app_id", req.AppId),
+		maskErr,
+	)
 }
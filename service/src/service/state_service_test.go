@@ -1,18 +1,25 @@
 package service_test
 
 import (
+	"bytes"
 	"context"
+	"crypto/sha256"
+	"encoding/hex"
 	"errors"
 	"fmt"
+	"io"
 	"math/rand"
+	"strings"
 	"testing"
 	"time"
 
 	"github.com/callstats-io/ai-decision/service/gen/protos"
+	"github.com/callstats-io/ai-decision/service/src/service"
 	"github.com/callstats-io/ai-decision/service/src/storage"
 	"github.com/golang/protobuf/ptypes"
 	"github.com/golang/protobuf/ptypes/timestamp"
 	"github.com/stretchr/testify/require"
+	"google.golang.org/genproto/protobuf/field_mask"
 )
 
 func TestStateSave(t *testing.T) {
@@ -20,17 +27,20 @@ func TestStateSave(t *testing.T) {
 	defer mockStorage.Reset()
 
 	tests := []struct {
-		Description string
-		ExpErrorMsg string
-		Setup       func(req *protos.StateSaveRequest) (*protos.State, error)
+		Description               string
+		ExpErrorMsg               string
+		ExpSaveStateCalls         int
+		ExpSaveStateRevisionCalls int
+		Setup                     func(req *protos.StateSaveRequest) (*protos.State, error)
 	}{
 		{
-			Description: "valid request",
+			Description:               "valid request",
+			ExpSaveStateRevisionCalls: 1,
 			Setup: func(req *protos.StateSaveRequest) (*protos.State, error) {
 				mockStorage.Reset()
 				savedAt, _ := ptypes.Timestamp(req.GenerationTime)
 				mockStorage.MockSavedStates([]*storage.AidAnalyticsState{
-					{ID: 1, AppID: req.AppId, Keyword: req.Keyword, Data: req.Data, SavedAt: savedAt},
+					{ID: 1, AppID: req.AppId, Keyword: req.Keyword, Data: req.Data, SavedAt: savedAt, Revision: 1},
 				})
 
 				// assume the 'req' to be valid by default and just return the appropriate state from it
@@ -40,9 +50,67 @@ func TestStateSave(t *testing.T) {
 					Keyword:        req.Keyword,
 					Data:           req.Data,
 					GenerationTime: req.GenerationTime,
+					Revision:       1,
+				}, nil
+			},
+		},
+		{
+			Description:               "valid request with expected revision",
+			ExpSaveStateRevisionCalls: 1,
+			Setup: func(req *protos.StateSaveRequest) (*protos.State, error) {
+				mockStorage.Reset()
+				req.ExpectedRevision = 2
+				savedAt, _ := ptypes.Timestamp(req.GenerationTime)
+				mockStorage.MockSavedStates([]*storage.AidAnalyticsState{
+					{ID: 1, AppID: req.AppId, Keyword: req.Keyword, Data: req.Data, SavedAt: savedAt, Revision: 3},
+				})
+
+				return &protos.State{
+					AppId:          req.AppId,
+					Keyword:        req.Keyword,
+					Data:           req.Data,
+					GenerationTime: req.GenerationTime,
+					Revision:       3,
+				}, nil
+			},
+		},
+		{
+			Description:       "valid forced request",
+			ExpSaveStateCalls: 1,
+			Setup: func(req *protos.StateSaveRequest) (*protos.State, error) {
+				mockStorage.Reset()
+				req.Force = true
+				savedAt, _ := ptypes.Timestamp(req.GenerationTime)
+				mockStorage.MockSavedStates([]*storage.AidAnalyticsState{
+					{ID: 1, AppID: req.AppId, Keyword: req.Keyword, Data: req.Data, SavedAt: savedAt, Revision: 5},
+				})
+
+				return &protos.State{
+					AppId:          req.AppId,
+					Keyword:        req.Keyword,
+					Data:           req.Data,
+					GenerationTime: req.GenerationTime,
+					Revision:       5,
 				}, nil
 			},
 		},
+		{
+			Description: "negative expected revision",
+			ExpErrorMsg: "rpc error: code = InvalidArgument desc = expected_revision: cannot be negative",
+			Setup: func(req *protos.StateSaveRequest) (*protos.State, error) {
+				req.ExpectedRevision = -1
+				return nil, nil
+			},
+		},
+		{
+			Description: "revision mismatch",
+			ExpErrorMsg: "rpc error: code = Aborted desc = revision mismatch",
+			Setup: func(req *protos.StateSaveRequest) (*protos.State, error) {
+				mockStorage.Reset()
+				mockStorage.MockSaveStateRevisionError(storage.ErrRevisionMismatch)
+				return &protos.State{}, nil
+			},
+		},
 		{
 			Description: "missing app id",
 			ExpErrorMsg: "rpc error: code = InvalidArgument desc = app_id: must be a positive integer",
@@ -83,11 +151,100 @@ func TestStateSave(t *testing.T) {
 				return nil, nil
 			},
 		},
+		{
+			Description:               "valid request with registered keyword",
+			ExpSaveStateRevisionCalls: 1,
+			Setup: func(req *protos.StateSaveRequest) (*protos.State, error) {
+				mockStorage.Reset()
+				mockStorage.MockSavedKeywords([]*storage.AidAnalyticsKeyword{
+					{Keyword: req.Keyword, ContentType: service.ContentTypeJSON, SchemaVersion: 2, MaxSize: 1024},
+				})
+				savedAt, _ := ptypes.Timestamp(req.GenerationTime)
+				mockStorage.MockSavedStates([]*storage.AidAnalyticsState{
+					{ID: 1, AppID: req.AppId, Keyword: req.Keyword, Data: req.Data, SavedAt: savedAt, Revision: 1, SchemaVersion: 2},
+				})
+
+				return &protos.State{
+					AppId:          req.AppId,
+					Keyword:        req.Keyword,
+					Data:           req.Data,
+					GenerationTime: req.GenerationTime,
+					Revision:       1,
+					ContentType:    service.ContentTypeJSON,
+					SchemaVersion:  2,
+				}, nil
+			},
+		},
+		{
+			Description: "data exceeds keyword max size",
+			ExpErrorMsg: "rpc error: code = InvalidArgument desc = data: exceeds maximum size of 4 bytes",
+			Setup: func(req *protos.StateSaveRequest) (*protos.State, error) {
+				mockStorage.Reset()
+				mockStorage.MockSavedKeywords([]*storage.AidAnalyticsKeyword{{Keyword: req.Keyword, MaxSize: 4}})
+				return &protos.State{}, nil
+			},
+		},
+		{
+			Description: "data not matching keyword content type",
+			ExpErrorMsg: "rpc error: code = InvalidArgument desc = data: must be valid JSON",
+			Setup: func(req *protos.StateSaveRequest) (*protos.State, error) {
+				mockStorage.Reset()
+				mockStorage.MockSavedKeywords([]*storage.AidAnalyticsKeyword{{Keyword: req.Keyword, ContentType: service.ContentTypeJSON}})
+				req.Data = []byte(`{"abc":`)
+				return &protos.State{}, nil
+			},
+		},
+		{
+			Description: "schema version mismatch",
+			ExpErrorMsg: "rpc error: code = FailedPrecondition desc = schema_version: 3 does not match registered version 2",
+			Setup: func(req *protos.StateSaveRequest) (*protos.State, error) {
+				mockStorage.Reset()
+				mockStorage.MockSavedKeywords([]*storage.AidAnalyticsKeyword{{Keyword: req.Keyword, SchemaVersion: 2}})
+				req.SchemaVersion = 3
+				return &protos.State{}, nil
+			},
+		},
+		{
+			Description: "negative schema version",
+			ExpErrorMsg: "rpc error: code = InvalidArgument desc = schema_version: cannot be negative",
+			Setup: func(req *protos.StateSaveRequest) (*protos.State, error) {
+				req.SchemaVersion = -1
+				return nil, nil
+			},
+		},
+		{
+			Description: "lease lost",
+			ExpErrorMsg: "rpc error: code = FailedPrecondition desc = lease expired or acquired by another holder",
+			Setup: func(req *protos.StateSaveRequest) (*protos.State, error) {
+				mockStorage.Reset()
+				req.FencingToken = 42
+				mockStorage.MockSaveStateRevisionError(storage.ErrLeaseLost)
+				return &protos.State{}, nil
+			},
+		},
+		{
+			Description: "negative fencing token",
+			ExpErrorMsg: "rpc error: code = InvalidArgument desc = fencing_token: cannot be negative",
+			Setup: func(req *protos.StateSaveRequest) (*protos.State, error) {
+				req.FencingToken = -1
+				return nil, nil
+			},
+		},
+		{
+			Description: "keyword get error",
+			ExpErrorMsg: "rpc error: code = Unavailable desc = EXPECTED KEYWORD GET TEST ERROR",
+			Setup: func(req *protos.StateSaveRequest) (*protos.State, error) {
+				mockStorage.Reset()
+				mockStorage.MockGetKeywordError(errors.New("EXPECTED KEYWORD GET TEST ERROR"))
+				return &protos.State{}, nil
+			},
+		},
 		{
 			Description: "state save error",
 			ExpErrorMsg: "rpc error: code = Unavailable desc = EXPECTED STATE SAVE TEST ERROR",
 			Setup: func(req *protos.StateSaveRequest) (*protos.State, error) {
 				mockStorage.Reset()
+				req.Force = true
 				mockStorage.MockSaveStateError(errors.New("EXPECTED STATE SAVE TEST ERROR"))
 
 				// assume request to be valid but don't return an expected message as we assume the request errors
@@ -130,7 +287,8 @@ func TestStateSave(t *testing.T) {
 					}
 				}
 				assert.Equal(expMessage, resp)
-				assert.Equal(1, mockStorage.SaveStateCalls())
+				assert.Equal(test.ExpSaveStateCalls, mockStorage.SaveStateCalls())
+				assert.Equal(test.ExpSaveStateRevisionCalls, mockStorage.SaveStateRevisionCalls())
 			}
 		})
 	}
@@ -260,126 +418,167 @@ func TestStateGet(t *testing.T) {
 	}
 }
 
-func TestStateList(t *testing.T) {
+func TestStateGetLatest(t *testing.T) {
 	// ensure no leakage between tests
 	defer mockStorage.Reset()
 
-	payload := []byte(`{"abc":"def"}`)
-	fixedTime := time.Now().Add(-5 * time.Minute)
-	fixedProtoTime, _ := ptypes.TimestampProto(fixedTime)
-
+	fixedTime := time.Now().Add(-time.Hour)
 	tests := []struct {
 		Description string
 		ExpErrorMsg string
-		Setup       func(req *protos.StateListRequest) (*protos.State, error)
+		Setup       func(req *protos.StateGetLatestRequest) (*protos.State, error)
 	}{
 		{
-			Description: "valid request with just app id",
-			Setup: func(req *protos.StateListRequest) (*protos.State, error) {
+			Description: "valid request",
+			Setup: func(req *protos.StateGetLatestRequest) (*protos.State, error) {
 				mockStorage.Reset()
+				payload := []byte(`{"abc":"def"}`)
 				mockStorage.MockSavedStates([]*storage.AidAnalyticsState{
-					{ID: 1, AppID: req.AppId, Keyword: req.Keyword, Data: payload, SavedAt: fixedTime},
+					{ID: 1, AppID: req.AppId, Keyword: req.Keyword, Data: payload, SavedAt: fixedTime, Revision: 2},
 				})
-				expState := &protos.State{
+				genTime, _ := ptypes.TimestampProto(fixedTime)
+				return &protos.State{
 					AppId:          req.AppId,
 					Keyword:        req.Keyword,
-					GenerationTime: fixedProtoTime,
+					GenerationTime: genTime,
 					Data:           payload,
-				}
-
-				// reset parts of request
+					Revision:       2,
+				}, nil
+			},
+		},
+		{
+			Description: "missing app id",
+			ExpErrorMsg: "rpc error: code = InvalidArgument desc = app_id: must be a positive integer",
+			Setup: func(req *protos.StateGetLatestRequest) (*protos.State, error) {
+				req.AppId = 0
+				return nil, nil
+			},
+		},
+		{
+			Description: "missing keyword",
+			ExpErrorMsg: "rpc error: code = InvalidArgument desc = keyword: cannot be empty",
+			Setup: func(req *protos.StateGetLatestRequest) (*protos.State, error) {
 				req.Keyword = ""
-				req.GenerationTimeFrom = nil
-				req.GenerationTimeTo = nil
-
-				return expState, nil
+				return nil, nil
 			},
 		},
 		{
-			Description: "valid request with app id and keyword",
-			Setup: func(req *protos.StateListRequest) (*protos.State, error) {
+			Description: "not found",
+			ExpErrorMsg: "rpc error: code = NotFound desc = not found",
+			Setup: func(req *protos.StateGetLatestRequest) (*protos.State, error) {
 				mockStorage.Reset()
-				mockStorage.MockSavedStates([]*storage.AidAnalyticsState{
-					{ID: 1, AppID: req.AppId, Keyword: req.Keyword, Data: payload, SavedAt: fixedTime},
-				})
-				expState := &protos.State{
-					AppId:          req.AppId,
-					Keyword:        req.Keyword,
-					GenerationTime: fixedProtoTime,
-					Data:           payload,
-				}
-
-				// reset parts of request
-				req.GenerationTimeFrom = nil
-				req.GenerationTimeTo = nil
-
-				return expState, nil
+				mockStorage.MockGetLatestStateError(storage.ErrNotFound)
+				return &protos.State{}, nil
 			},
 		},
 		{
-			Description: "valid request with app id, keyword and from time",
-			Setup: func(req *protos.StateListRequest) (*protos.State, error) {
+			Description: "state get latest error",
+			ExpErrorMsg: "rpc error: code = Unavailable desc = EXPECTED STATE GET LATEST TEST ERROR",
+			Setup: func(req *protos.StateGetLatestRequest) (*protos.State, error) {
 				mockStorage.Reset()
-				mockStorage.MockSavedStates([]*storage.AidAnalyticsState{
-					{ID: 1, AppID: req.AppId, Keyword: req.Keyword, Data: payload, SavedAt: fixedTime},
-				})
-				expState := &protos.State{
-					AppId:          req.AppId,
-					Keyword:        req.Keyword,
-					GenerationTime: fixedProtoTime,
-					Data:           payload,
-				}
-
-				// reset parts of request
-				req.GenerationTimeTo = nil
-
-				return expState, nil
+				mockStorage.MockGetLatestStateError(errors.New("EXPECTED STATE GET LATEST TEST ERROR"))
+				return &protos.State{}, nil
 			},
 		},
+	}
+
+	for _, test := range tests {
+		t.Run(test.Description, func(t *testing.T) {
+			assert := require.New(t)
+
+			// create a valid request, expect Setup to invalidate if needed
+			req := &protos.StateGetLatestRequest{
+				AppId:   567,
+				Keyword: fmt.Sprintf("srv-state-get-latest-kw-%d", rand.Int()),
+			}
+			expMessage, err := test.Setup(req)
+			assert.Nil(err)
+
+			// exec test
+			resp, err := testStateClient.GetLatest(context.Background(), req)
+			if test.ExpErrorMsg != "" {
+				assert.EqualError(err, test.ExpErrorMsg)
+			} else {
+				assert.Nil(err)
+				// Timestamp deep equality fails with the assertion library so validate them manually and reset to nil
+				assert.NotNil(resp.GenerationTime)
+				assert.Equal(expMessage.GenerationTime.Seconds, resp.GenerationTime.Seconds)
+				assert.Equal(expMessage.GenerationTime.Nanos, resp.GenerationTime.Nanos)
+				resp.GenerationTime = nil
+				expMessage.GenerationTime = nil
+				assert.Equal(expMessage, resp)
+				assert.Equal(1, mockStorage.GetLatestStateCalls())
+			}
+		})
+	}
+}
+
+func TestStateGetAsOf(t *testing.T) {
+	// ensure no leakage between tests
+	defer mockStorage.Reset()
+
+	fixedTime := time.Now().Add(-time.Hour)
+	tests := []struct {
+		Description string
+		ExpErrorMsg string
+		Setup       func(req *protos.StateGetAsOfRequest) (*protos.State, error)
+	}{
 		{
-			Description: "valid request with app id, keyword, from and to time",
-			Setup: func(req *protos.StateListRequest) (*protos.State, error) {
+			Description: "valid request",
+			Setup: func(req *protos.StateGetAsOfRequest) (*protos.State, error) {
 				mockStorage.Reset()
+				payload := []byte(`{"abc":"def"}`)
 				mockStorage.MockSavedStates([]*storage.AidAnalyticsState{
-					{ID: 1, AppID: req.AppId, Keyword: req.Keyword, Data: payload, SavedAt: fixedTime},
+					{ID: 1, AppID: req.AppId, Keyword: req.Keyword, Data: payload, SavedAt: fixedTime, Revision: 1},
 				})
-				expState := &protos.State{
+				genTime, _ := ptypes.TimestampProto(fixedTime)
+				return &protos.State{
 					AppId:          req.AppId,
 					Keyword:        req.Keyword,
-					GenerationTime: fixedProtoTime,
+					GenerationTime: genTime,
 					Data:           payload,
-				}
-
-				// assume the 'req' to be valid by default and just return the appropriate state from it
-
-				return expState, nil
+					Revision:       1,
+				}, nil
 			},
 		},
 		{
-			Description: "no states",
-			ExpErrorMsg: "rpc error: code = NotFound desc = not found",
-			Setup: func(req *protos.StateListRequest) (*protos.State, error) {
-				mockStorage.Reset()
-				mockStorage.MockListStatesError(storage.ErrNotFound)
+			Description: "missing app id",
+			ExpErrorMsg: "rpc error: code = InvalidArgument desc = app_id: must be a positive integer",
+			Setup: func(req *protos.StateGetAsOfRequest) (*protos.State, error) {
+				req.AppId = 0
 				return nil, nil
 			},
 		},
 		{
-			Description: "missing app id",
-			ExpErrorMsg: "rpc error: code = InvalidArgument desc = app_id: must be a positive integer",
-			Setup: func(req *protos.StateListRequest) (*protos.State, error) {
-				req.AppId = 0
+			Description: "missing keyword",
+			ExpErrorMsg: "rpc error: code = InvalidArgument desc = keyword: cannot be empty",
+			Setup: func(req *protos.StateGetAsOfRequest) (*protos.State, error) {
+				req.Keyword = ""
 				return nil, nil
 			},
 		},
 		{
-			Description: "state list error",
-			ExpErrorMsg: "rpc error: code = Unavailable desc = EXPECTED STATE LIST TEST ERROR",
-			Setup: func(req *protos.StateListRequest) (*protos.State, error) {
+			Description: "missing as of",
+			ExpErrorMsg: "rpc error: code = InvalidArgument desc = as_of: cannot be nil",
+			Setup: func(req *protos.StateGetAsOfRequest) (*protos.State, error) {
+				req.AsOf = nil
+				return nil, nil
+			},
+		},
+		{
+			Description: "invalid as of",
+			ExpErrorMsg: "rpc error: code = InvalidArgument desc = as_of: must have positive seconds",
+			Setup: func(req *protos.StateGetAsOfRequest) (*protos.State, error) {
+				req.AsOf = &timestamp.Timestamp{Nanos: 123}
+				return nil, nil
+			},
+		},
+		{
+			Description: "not found",
+			ExpErrorMsg: "rpc error: code = NotFound desc = not found",
+			Setup: func(req *protos.StateGetAsOfRequest) (*protos.State, error) {
 				mockStorage.Reset()
-				mockStorage.MockListStatesError(errors.New("EXPECTED STATE LIST TEST ERROR"))
-
-				// assume request to be valid but don't return an expected message as we assume the request errors
+				mockStorage.MockGetLatestStateError(storage.ErrNotFound)
 				return &protos.State{}, nil
 			},
 		},
@@ -389,47 +588,1086 @@ func TestStateList(t *testing.T) {
 		t.Run(test.Description, func(t *testing.T) {
 			assert := require.New(t)
 
-			genTimeFrom := *fixedProtoTime
-			genTimeFrom.Seconds -= 60
-			genTimeTo := *fixedProtoTime
-			genTimeTo.Seconds += 60
+			asOf, _ := ptypes.TimestampProto(time.Now())
 			// create a valid request, expect Setup to invalidate if needed
-			req := &protos.StateListRequest{
-				AppId:              2001,
-				Keyword:            fmt.Sprintf("kw-test-state-list-%d", rand.Int()),
-				GenerationTimeFrom: &genTimeFrom,
-				GenerationTimeTo:   &genTimeTo,
+			req := &protos.StateGetAsOfRequest{
+				AppId:   567,
+				Keyword: fmt.Sprintf("srv-state-get-as-of-kw-%d", rand.Int()),
+				AsOf:    asOf,
 			}
 			expMessage, err := test.Setup(req)
 			assert.Nil(err)
 
 			// exec test
-			stream, err := testStateClient.List(context.Background(), req)
-			assert.Nil(err)
-			resp, err := stream.Recv()
+			resp, err := testStateClient.GetAsOf(context.Background(), req)
 			if test.ExpErrorMsg != "" {
 				assert.EqualError(err, test.ExpErrorMsg)
 			} else {
 				assert.Nil(err)
-				if expMessage != nil {
-					// Timestamp deep equality fails with the assertion library so validate them manually and reset to nil
-					if expMessage.GenerationTime != nil {
-						assert.NotNil(resp.GenerationTime)
-						assert.Equal(expMessage.GenerationTime.Seconds, resp.GenerationTime.Seconds)
-						assert.Equal(expMessage.GenerationTime.Nanos, resp.GenerationTime.Nanos)
-						resp.GenerationTime = nil
-						expMessage.GenerationTime = nil
-					} else {
-						assert.Nil(resp.GenerationTime)
-					}
-				}
+				// Timestamp deep equality fails with the assertion library so validate them manually and reset to nil
+				assert.NotNil(resp.GenerationTime)
+				assert.Equal(expMessage.GenerationTime.Seconds, resp.GenerationTime.Seconds)
+				assert.Equal(expMessage.GenerationTime.Nanos, resp.GenerationTime.Nanos)
+				resp.GenerationTime = nil
+				expMessage.GenerationTime = nil
 				assert.Equal(expMessage, resp)
-				assert.Equal(1, mockStorage.ListStatesCalls())
+				assert.Equal(1, mockStorage.GetLatestStateCalls())
+			}
+		})
+	}
+}
 
-				// check no more values
-				_, err = stream.Recv()
-				assert.EqualError(err, "EOF")
+func checksum(data []byte) string {
+	sum := sha256.Sum256(data)
+	return hex.EncodeToString(sum[:])
+}
+
+func TestStateSaveChunked(t *testing.T) {
+	// ensure no leakage between tests
+	defer mockStorage.Reset()
+
+	// larger than two storage chunks, uploaded in smaller chunks
+	data := bytes.Repeat([]byte("0123456789"), service.StateChunkSize/4)
+	uploadChunkSize := service.StateChunkSize / 3
+
+	tests := []struct {
+		Description string
+		ExpErrorMsg string
+		Setup       func(chunks []*protos.StateSaveChunk) []*protos.StateSaveChunk
+	}{
+		{
+			Description: "valid request",
+			Setup: func(chunks []*protos.StateSaveChunk) []*protos.StateSaveChunk {
+				return chunks
+			},
+		},
+		{
+			Description: "upper case checksum",
+			Setup: func(chunks []*protos.StateSaveChunk) []*protos.StateSaveChunk {
+				last := chunks[len(chunks)-1]
+				last.Checksum = strings.ToUpper(last.Checksum)
+				return chunks
+			},
+		},
+		{
+			Description: "no chunks",
+			ExpErrorMsg: "rpc error: code = InvalidArgument desc = state: cannot be nil",
+			Setup: func(chunks []*protos.StateSaveChunk) []*protos.StateSaveChunk {
+				return nil
+			},
+		},
+		{
+			Description: "missing state",
+			ExpErrorMsg: "rpc error: code = InvalidArgument desc = state: cannot be nil",
+			Setup: func(chunks []*protos.StateSaveChunk) []*protos.StateSaveChunk {
+				chunks[0].State = nil
+				return chunks
+			},
+		},
+		{
+			Description: "missing keyword",
+			ExpErrorMsg: "rpc error: code = InvalidArgument desc = state.keyword: cannot be empty",
+			Setup: func(chunks []*protos.StateSaveChunk) []*protos.StateSaveChunk {
+				chunks[0].State.Keyword = ""
+				return chunks
+			},
+		},
+		{
+			Description: "data in state",
+			ExpErrorMsg: "rpc error: code = InvalidArgument desc = state.data: must be empty",
+			Setup: func(chunks []*protos.StateSaveChunk) []*protos.StateSaveChunk {
+				chunks[0].State.Data = []byte("abc")
+				return chunks
+			},
+		},
+		{
+			Description: "missing data",
+			ExpErrorMsg: "rpc error: code = InvalidArgument desc = data: cannot be empty",
+			Setup: func(chunks []*protos.StateSaveChunk) []*protos.StateSaveChunk {
+				return []*protos.StateSaveChunk{{State: chunks[0].State, Checksum: checksum(nil)}}
+			},
+		},
+		{
+			Description: "missing checksum",
+			ExpErrorMsg: "rpc error: code = InvalidArgument desc = checksum: cannot be empty",
+			Setup: func(chunks []*protos.StateSaveChunk) []*protos.StateSaveChunk {
+				chunks[len(chunks)-1].Checksum = ""
+				return chunks
+			},
+		},
+		{
+			Description: "checksum mismatch",
+			ExpErrorMsg: "rpc error: code = InvalidArgument desc = checksum: does not match data",
+			Setup: func(chunks []*protos.StateSaveChunk) []*protos.StateSaveChunk {
+				chunks[len(chunks)-1].Checksum = checksum([]byte("other"))
+				return chunks
+			},
+		},
+		{
+			Description: "data exceeds default max size",
+			ExpErrorMsg: fmt.Sprintf("rpc error: code = InvalidArgument desc = data: exceeds maximum size of %d bytes", testStateMaxSize),
+			Setup: func(chunks []*protos.StateSaveChunk) []*protos.StateSaveChunk {
+				// the keyword is not registered
+				extra := &protos.StateSaveChunk{Data: make([]byte, testStateMaxSize-len(data)+1)}
+				return append(chunks[:len(chunks)-1], extra, chunks[len(chunks)-1])
+			},
+		},
+		{
+			Description: "data exceeds keyword max size",
+			ExpErrorMsg: "rpc error: code = InvalidArgument desc = data: exceeds maximum size of 1024 bytes",
+			Setup: func(chunks []*protos.StateSaveChunk) []*protos.StateSaveChunk {
+				mockStorage.MockSavedKeywords([]*storage.AidAnalyticsKeyword{{Keyword: chunks[0].State.Keyword, MaxSize: 1024}})
+				return chunks
+			},
+		},
+		{
+			Description: "revision mismatch",
+			ExpErrorMsg: "rpc error: code = Aborted desc = revision mismatch",
+			Setup: func(chunks []*protos.StateSaveChunk) []*protos.StateSaveChunk {
+				mockStorage.MockSaveStateChunksError(storage.ErrRevisionMismatch)
+				return chunks
+			},
+		},
+		{
+			Description: "state save error",
+			ExpErrorMsg: "rpc error: code = Unavailable desc = EXPECTED STATE SAVE CHUNKS TEST ERROR",
+			Setup: func(chunks []*protos.StateSaveChunk) []*protos.StateSaveChunk {
+				mockStorage.MockSaveStateChunksError(errors.New("EXPECTED STATE SAVE CHUNKS TEST ERROR"))
+				return chunks
+			},
+		},
+	}
+
+	for _, test := range tests {
+		t.Run(test.Description, func(t *testing.T) {
+			assert := require.New(t)
+			mockStorage.Reset()
+
+			genTime, _ := ptypes.TimestampProto(time.Now())
+			savedAt, _ := ptypes.Timestamp(genTime)
+			req := &protos.StateSaveRequest{
+				AppId:          123,
+				Keyword:        fmt.Sprintf("kw-%d", rand.Int()),
+				GenerationTime: genTime,
+			}
+			mockStorage.MockSavedStates([]*storage.AidAnalyticsState{
+				{ID: 1, AppID: req.AppId, Keyword: req.Keyword, SavedAt: savedAt, Revision: 1, ChunkCount: 3},
+			})
+
+			// create a valid upload, expect Setup to invalidate if needed
+			chunks := []*protos.StateSaveChunk{{State: req}}
+			for i := 0; i < len(data); i += uploadChunkSize {
+				end := i + uploadChunkSize
+				if end > len(data) {
+					end = len(data)
+				}
+				chunks = append(chunks, &protos.StateSaveChunk{Data: data[i:end]})
+			}
+			chunks[len(chunks)-1].Checksum = checksum(data)
+			chunks = test.Setup(chunks)
+
+			// exec test
+			stream, err := testStateClient.SaveChunked(context.Background())
+			assert.Nil(err)
+			for _, chunk := range chunks {
+				// the server may reject the upload early, the error is returned by CloseAndRecv
+				if err := stream.Send(chunk); err == io.EOF {
+					break
+				} else {
+					assert.Nil(err)
+				}
+			}
+			resp, err := stream.CloseAndRecv()
+			if test.ExpErrorMsg != "" {
+				assert.EqualError(err, test.ExpErrorMsg)
+				return
+			}
+			assert.Nil(err)
+			assert.Equal(req.AppId, resp.AppId)
+			assert.Equal(req.Keyword, resp.Keyword)
+			assert.Equal(int32(1), resp.Revision)
+			assert.True(resp.Chunked)
+			assert.Empty(resp.Data)
+
+			// data is stored in chunks of StateChunkSize
+			saved := mockStorage.SavedStateChunks()
+			assert.Len(saved, 3)
+			assert.Len(saved[0], service.StateChunkSize)
+			assert.Len(saved[1], service.StateChunkSize)
+			assert.Equal(data, bytes.Join(saved, nil))
+		})
+	}
+}
+
+func TestStateGetChunked(t *testing.T) {
+	// ensure no leakage between tests
+	defer mockStorage.Reset()
+
+	data := bytes.Repeat([]byte("0123456789"), service.StateChunkSize/4)
+	storedChunks := [][]byte{data[:service.StateChunkSize], data[service.StateChunkSize : 2*service.StateChunkSize], data[2*service.StateChunkSize:]}
+
+	tests := []struct {
+		Description string
+		ExpErrorMsg string
+		ExpChunks   int
+		Setup       func(state *storage.AidAnalyticsState)
+	}{
+		{
+			Description: "chunked state",
+			ExpChunks:   3,
+			Setup: func(state *storage.AidAnalyticsState) {
+				state.ChunkCount = 3
+				state.Checksum = checksum(data)
+				mockStorage.MockSavedStateChunks(storedChunks)
+			},
+		},
+		{
+			Description: "state saved without chunks",
+			ExpChunks:   3,
+			Setup: func(state *storage.AidAnalyticsState) {
+				state.Data = data
+			},
+		},
+		{
+			Description: "checksum mismatch",
+			ExpErrorMsg: "rpc error: code = DataLoss desc = state checksum mismatch",
+			Setup: func(state *storage.AidAnalyticsState) {
+				state.ChunkCount = 3
+				state.Checksum = checksum([]byte("other"))
+				mockStorage.MockSavedStateChunks(storedChunks)
+			},
+		},
+		{
+			Description: "missing chunk",
+			ExpErrorMsg: "rpc error: code = DataLoss desc = missing state chunk",
+			Setup: func(state *storage.AidAnalyticsState) {
+				state.ChunkCount = 3
+				state.Checksum = checksum(data)
+				mockStorage.MockSavedStateChunks(storedChunks[:2])
+			},
+		},
+		{
+			Description: "chunk get error",
+			ExpErrorMsg: "rpc error: code = Unavailable desc = EXPECTED STATE GET CHUNK TEST ERROR",
+			Setup: func(state *storage.AidAnalyticsState) {
+				state.ChunkCount = 3
+				mockStorage.MockGetStateChunkError(errors.New("EXPECTED STATE GET CHUNK TEST ERROR"))
+			},
+		},
+		{
+			Description: "not found",
+			ExpErrorMsg: "rpc error: code = NotFound desc = not found",
+			Setup: func(state *storage.AidAnalyticsState) {
+				mockStorage.MockGetStateError(storage.ErrNotFound)
+			},
+		},
+	}
+
+	for _, test := range tests {
+		t.Run(test.Description, func(t *testing.T) {
+			assert := require.New(t)
+			mockStorage.Reset()
+
+			genTime, _ := ptypes.TimestampProto(time.Now())
+			savedAt, _ := ptypes.Timestamp(genTime)
+			req := &protos.StateGetRequest{
+				AppId:          567,
+				Keyword:        fmt.Sprintf("srv-state-get-chunked-kw-%d", rand.Int()),
+				GenerationTime: genTime,
 			}
+			state := &storage.AidAnalyticsState{ID: 1, AppID: req.AppId, Keyword: req.Keyword, SavedAt: savedAt, Revision: 2}
+			test.Setup(state)
+			mockStorage.MockSavedStates([]*storage.AidAnalyticsState{state})
+
+			// exec test
+			stream, err := testStateClient.GetChunked(context.Background(), req)
+			assert.Nil(err)
+			var chunks []*protos.StateChunk
+			for {
+				chunk, err := stream.Recv()
+				if err == io.EOF {
+					break
+				}
+				if test.ExpErrorMsg != "" && err != nil {
+					assert.EqualError(err, test.ExpErrorMsg)
+					return
+				}
+				assert.Nil(err)
+				chunks = append(chunks, chunk)
+			}
+			assert.Empty(test.ExpErrorMsg)
+			assert.Len(chunks, test.ExpChunks)
+
+			first := chunks[0].State
+			assert.NotNil(first)
+			assert.Equal(req.AppId, first.AppId)
+			assert.Equal(int32(2), first.Revision)
+			assert.Equal(state.ChunkCount > 0, first.Chunked)
+
+			var received []byte
+			for _, chunk := range chunks {
+				received = append(received, chunk.Data...)
+			}
+			assert.Equal(data, received)
+			assert.Equal(checksum(data), chunks[len(chunks)-1].Checksum)
 		})
 	}
 }
+
+func TestStateList(t *testing.T) {
+	// ensure no leakage between tests
+	defer mockStorage.Reset()
+
+	payload := []byte(`{"abc":"def"}`)
+	fixedTime := time.Now().Add(-5 * time.Minute)
+	fixedProtoTime, _ := ptypes.TimestampProto(fixedTime)
+
+	tests := []struct {
+		Description string
+		ExpErrorMsg string
+		Setup       func(req *protos.StateListRequest) (*protos.State, error)
+	}{
+		{
+			Description: "valid request with just app id",
+			Setup: func(req *protos.StateListRequest) (*protos.State, error) {
+				mockStorage.Reset()
+				mockStorage.MockSavedStates([]*storage.AidAnalyticsState{
+					{ID: 1, AppID: req.AppId, Keyword: req.Keyword, Data: payload, SavedAt: fixedTime},
+				})
+				expState := &protos.State{
+					AppId:          req.AppId,
+					Keyword:        req.Keyword,
+					GenerationTime: fixedProtoTime,
+					Data:           payload,
+				}
+
+				// reset parts of request
+				req.Keyword = ""
+				req.GenerationTimeFrom = nil
+				req.GenerationTimeTo = nil
+
+				return expState, nil
+			},
+		},
+		{
+			Description: "valid request with app id and keyword",
+			Setup: func(req *protos.StateListRequest) (*protos.State, error) {
+				mockStorage.Reset()
+				mockStorage.MockSavedStates([]*storage.AidAnalyticsState{
+					{ID: 1, AppID: req.AppId, Keyword: req.Keyword, Data: payload, SavedAt: fixedTime},
+				})
+				expState := &protos.State{
+					AppId:          req.AppId,
+					Keyword:        req.Keyword,
+					GenerationTime: fixedProtoTime,
+					Data:           payload,
+				}
+
+				// reset parts of request
+				req.GenerationTimeFrom = nil
+				req.GenerationTimeTo = nil
+
+				return expState, nil
+			},
+		},
+		{
+			Description: "valid request with app id, keyword and from time",
+			Setup: func(req *protos.StateListRequest) (*protos.State, error) {
+				mockStorage.Reset()
+				mockStorage.MockSavedStates([]*storage.AidAnalyticsState{
+					{ID: 1, AppID: req.AppId, Keyword: req.Keyword, Data: payload, SavedAt: fixedTime},
+				})
+				expState := &protos.State{
+					AppId:          req.AppId,
+					Keyword:        req.Keyword,
+					GenerationTime: fixedProtoTime,
+					Data:           payload,
+				}
+
+				// reset parts of request
+				req.GenerationTimeTo = nil
+
+				return expState, nil
+			},
+		},
+		{
+			Description: "valid request with app id, keyword, from and to time",
+			Setup: func(req *protos.StateListRequest) (*protos.State, error) {
+				mockStorage.Reset()
+				mockStorage.MockSavedStates([]*storage.AidAnalyticsState{
+					{ID: 1, AppID: req.AppId, Keyword: req.Keyword, Data: payload, SavedAt: fixedTime},
+				})
+				expState := &protos.State{
+					AppId:          req.AppId,
+					Keyword:        req.Keyword,
+					GenerationTime: fixedProtoTime,
+					Data:           payload,
+				}
+
+				// assume the 'req' to be valid by default and just return the appropriate state from it
+
+				return expState, nil
+			},
+		},
+		{
+			Description: "valid request with registered keyword",
+			Setup: func(req *protos.StateListRequest) (*protos.State, error) {
+				mockStorage.Reset()
+				mockStorage.MockSavedKeywords([]*storage.AidAnalyticsKeyword{
+					{Keyword: req.Keyword, ContentType: service.ContentTypeJSON, SchemaVersion: 2},
+				})
+				mockStorage.MockSavedStates([]*storage.AidAnalyticsState{
+					{ID: 1, AppID: req.AppId, Keyword: req.Keyword, Data: payload, SavedAt: fixedTime, SchemaVersion: 2},
+				})
+				expState := &protos.State{
+					AppId:          req.AppId,
+					Keyword:        req.Keyword,
+					GenerationTime: fixedProtoTime,
+					Data:           payload,
+					ContentType:    service.ContentTypeJSON,
+					SchemaVersion:  2,
+				}
+
+				// list by app id only to look up the keyword per state
+				req.Keyword = ""
+
+				return expState, nil
+			},
+		},
+		{
+			Description: "no states",
+			ExpErrorMsg: "rpc error: code = NotFound desc = not found",
+			Setup: func(req *protos.StateListRequest) (*protos.State, error) {
+				mockStorage.Reset()
+				mockStorage.MockListStatesError(storage.ErrNotFound)
+				return nil, nil
+			},
+		},
+		{
+			Description: "missing app id",
+			ExpErrorMsg: "rpc error: code = InvalidArgument desc = app_id: must be a positive integer",
+			Setup: func(req *protos.StateListRequest) (*protos.State, error) {
+				req.AppId = 0
+				return nil, nil
+			},
+		},
+		{
+			Description: "state list error",
+			ExpErrorMsg: "rpc error: code = Unavailable desc = EXPECTED STATE LIST TEST ERROR",
+			Setup: func(req *protos.StateListRequest) (*protos.State, error) {
+				mockStorage.Reset()
+				mockStorage.MockListStatesError(errors.New("EXPECTED STATE LIST TEST ERROR"))
+
+				// assume request to be valid but don't return an expected message as we assume the request errors
+				return &protos.State{}, nil
+			},
+		},
+	}
+
+	for _, test := range tests {
+		t.Run(test.Description, func(t *testing.T) {
+			assert := require.New(t)
+
+			genTimeFrom := *fixedProtoTime
+			genTimeFrom.Seconds -= 60
+			genTimeTo := *fixedProtoTime
+			genTimeTo.Seconds += 60
+			// create a valid request, expect Setup to invalidate if needed
+			req := &protos.StateListRequest{
+				AppId:              2001,
+				Keyword:            fmt.Sprintf("kw-test-state-list-%d", rand.Int()),
+				GenerationTimeFrom: &genTimeFrom,
+				GenerationTimeTo:   &genTimeTo,
+			}
+			expMessage, err := test.Setup(req)
+			assert.Nil(err)
+
+			// exec test
+			stream, err := testStateClient.List(context.Background(), req)
+			assert.Nil(err)
+			resp, err := stream.Recv()
+			if test.ExpErrorMsg != "" {
+				assert.EqualError(err, test.ExpErrorMsg)
+			} else {
+				assert.Nil(err)
+				if expMessage != nil {
+					// Timestamp deep equality fails with the assertion library so validate them manually and reset to nil
+					if expMessage.GenerationTime != nil {
+						assert.NotNil(resp.GenerationTime)
+						assert.Equal(expMessage.GenerationTime.Seconds, resp.GenerationTime.Seconds)
+						assert.Equal(expMessage.GenerationTime.Nanos, resp.GenerationTime.Nanos)
+						resp.GenerationTime = nil
+						expMessage.GenerationTime = nil
+					} else {
+						assert.Nil(resp.GenerationTime)
+					}
+				}
+				assert.Equal(expMessage, resp)
+				assert.Equal(1, mockStorage.ListStatesCalls())
+
+				// check no more values
+				_, err = stream.Recv()
+				assert.EqualError(err, "EOF")
+			}
+		})
+	}
+}
+
+func TestStateStrictKeywords(t *testing.T) {
+	// ensure no leakage between tests
+	defer mockStorage.Reset()
+	mockStorage.Reset()
+
+	strictService, err := service.NewAIDecisionStateService(mockStorage)
+	require.Nil(t, err)
+	strictService = strictService.WithStrictKeywords(true)
+
+	genTime, _ := ptypes.TimestampProto(time.Now())
+	_, err = strictService.Save(context.Background(), &protos.StateSaveRequest{
+		AppId:          123,
+		Keyword:        "kw-unregistered",
+		GenerationTime: genTime,
+		Data:           []byte(`{"abc": "def"}`),
+	})
+	require.EqualError(t, err, `rpc error: code = InvalidArgument desc = keyword: "kw-unregistered" is not registered`)
+
+	_, err = strictService.Get(context.Background(), &protos.StateGetRequest{
+		AppId:          123,
+		Keyword:        "kw-unregistered",
+		GenerationTime: genTime,
+	})
+	require.EqualError(t, err, `rpc error: code = InvalidArgument desc = keyword: "kw-unregistered" is not registered`)
+
+	_, err = strictService.GetLatest(context.Background(), &protos.StateGetLatestRequest{
+		AppId:   123,
+		Keyword: "kw-unregistered",
+	})
+	require.EqualError(t, err, `rpc error: code = InvalidArgument desc = keyword: "kw-unregistered" is not registered`)
+}
+
+func TestStateListKeywords(t *testing.T) {
+	// ensure no leakage between tests
+	defer mockStorage.Reset()
+
+	fixedTime := time.Now().Add(-5 * time.Minute)
+	fixedProtoTime, _ := ptypes.TimestampProto(fixedTime)
+
+	tests := []struct {
+		Description string
+		ExpErrorMsg string
+		Setup       func(req *protos.KeywordListRequest) ([]*protos.Keyword, error)
+	}{
+		{
+			Description: "valid request",
+			Setup: func(req *protos.KeywordListRequest) ([]*protos.Keyword, error) {
+				mockStorage.Reset()
+				mockStorage.MockSavedKeywordUsages([]*storage.KeywordUsage{
+					{
+						AidAnalyticsKeyword: storage.AidAnalyticsKeyword{
+							Keyword:       "latest_dates",
+							ContentType:   service.ContentTypeJSON,
+							SchemaVersion: 1,
+							MaxSize:       1024,
+							Owner:         "ai-decision-pipeline",
+						},
+						Registered:    true,
+						StateCount:    3,
+						LatestSavedAt: fixedTime,
+					},
+					{
+						AidAnalyticsKeyword: storage.AidAnalyticsKeyword{Keyword: "kw-unregistered"},
+						StateCount:          1,
+						LatestSavedAt:       fixedTime,
+					},
+				})
+				return []*protos.Keyword{
+					{
+						Keyword:              "latest_dates",
+						ContentType:          service.ContentTypeJSON,
+						SchemaVersion:        1,
+						MaxSize:              1024,
+						Owner:                "ai-decision-pipeline",
+						Registered:           true,
+						StateCount:           3,
+						LatestGenerationTime: fixedProtoTime,
+					},
+					{
+						Keyword:              "kw-unregistered",
+						StateCount:           1,
+						LatestGenerationTime: fixedProtoTime,
+					},
+				}, nil
+			},
+		},
+		{
+			Description: "no keywords",
+			ExpErrorMsg: "rpc error: code = NotFound desc = not found",
+			Setup: func(req *protos.KeywordListRequest) ([]*protos.Keyword, error) {
+				mockStorage.Reset()
+				mockStorage.MockListKeywordsError(storage.ErrNotFound)
+				return nil, nil
+			},
+		},
+		{
+			Description: "missing app id",
+			ExpErrorMsg: "rpc error: code = InvalidArgument desc = app_id: must be a positive integer",
+			Setup: func(req *protos.KeywordListRequest) ([]*protos.Keyword, error) {
+				req.AppId = 0
+				return nil, nil
+			},
+		},
+		{
+			Description: "keyword list error",
+			ExpErrorMsg: "rpc error: code = Unavailable desc = EXPECTED KEYWORD LIST TEST ERROR",
+			Setup: func(req *protos.KeywordListRequest) ([]*protos.Keyword, error) {
+				mockStorage.Reset()
+				mockStorage.MockListKeywordsError(errors.New("EXPECTED KEYWORD LIST TEST ERROR"))
+				return nil, nil
+			},
+		},
+	}
+
+	for _, test := range tests {
+		t.Run(test.Description, func(t *testing.T) {
+			assert := require.New(t)
+
+			// create a valid request, expect Setup to invalidate if needed
+			req := &protos.KeywordListRequest{AppId: 2001}
+			expKeywords, err := test.Setup(req)
+			assert.Nil(err)
+
+			// exec test
+			stream, err := testStateClient.ListKeywords(context.Background(), req)
+			assert.Nil(err)
+			if test.ExpErrorMsg != "" {
+				_, err := stream.Recv()
+				assert.EqualError(err, test.ExpErrorMsg)
+				return
+			}
+			for _, exp := range expKeywords {
+				resp, err := stream.Recv()
+				assert.Nil(err)
+				// Timestamp deep equality fails with the assertion library so validate them manually and reset to nil
+				assert.Equal(exp.LatestGenerationTime.Seconds, resp.LatestGenerationTime.Seconds)
+				assert.Equal(exp.LatestGenerationTime.Nanos, resp.LatestGenerationTime.Nanos)
+				exp.LatestGenerationTime = nil
+				resp.LatestGenerationTime = nil
+				assert.Equal(exp, resp)
+			}
+
+			// check no more values
+			_, err = stream.Recv()
+			assert.EqualError(err, "EOF")
+		})
+	}
+}
+
+func TestStateSetCompactionPolicy(t *testing.T) {
+	// ensure no leakage between tests
+	defer mockStorage.Reset()
+
+	tests := []struct {
+		Description string
+		ExpErrorMsg string
+		Setup       func(req *protos.CompactionPolicy)
+	}{
+		{
+			Description: "valid request",
+			Setup: func(req *protos.CompactionPolicy) {
+				mockStorage.Reset()
+			},
+		},
+		{
+			Description: "missing keyword",
+			ExpErrorMsg: "rpc error: code = InvalidArgument desc = keyword: cannot be empty",
+			Setup: func(req *protos.CompactionPolicy) {
+				req.Keyword = ""
+			},
+		},
+		{
+			Description: "negative keep last",
+			ExpErrorMsg: "rpc error: code = InvalidArgument desc = keep_last: cannot be negative",
+			Setup: func(req *protos.CompactionPolicy) {
+				req.KeepLast = -1
+			},
+		},
+		{
+			Description: "delete before keeping daily",
+			ExpErrorMsg: "rpc error: code = InvalidArgument desc = delete_after_days: must be greater than keep_daily_after_days",
+			Setup: func(req *protos.CompactionPolicy) {
+				req.DeleteAfterDays = 7
+			},
+		},
+		{
+			Description: "policy save error",
+			ExpErrorMsg: "rpc error: code = Unavailable desc = EXPECTED POLICY SAVE TEST ERROR",
+			Setup: func(req *protos.CompactionPolicy) {
+				mockStorage.Reset()
+				mockStorage.MockSaveCompactionPolicyError(errors.New("EXPECTED POLICY SAVE TEST ERROR"))
+			},
+		},
+		{
+			Description: "policy save without an audit actor",
+			ExpErrorMsg: "rpc error: code = Unauthenticated desc = mutation without an audit actor",
+			Setup: func(req *protos.CompactionPolicy) {
+				mockStorage.Reset()
+				mockStorage.MockSaveCompactionPolicyError(storage.ErrNoAuditActor)
+			},
+		},
+	}
+
+	for _, test := range tests {
+		t.Run(test.Description, func(t *testing.T) {
+			assert := require.New(t)
+
+			// create a valid request, expect Setup to invalidate if needed
+			req := &protos.CompactionPolicy{
+				Keyword:            "latest_dates",
+				KeepLast:           10,
+				KeepDailyAfterDays: 7,
+				DeleteAfterDays:    90,
+			}
+			test.Setup(req)
+
+			// exec test
+			resp, err := testStateClient.SetCompactionPolicy(context.Background(), req)
+			if test.ExpErrorMsg != "" {
+				assert.EqualError(err, test.ExpErrorMsg)
+				return
+			}
+			assert.Nil(err)
+			expPolicy := &protos.CompactionPolicy{
+				Keyword:            "latest_dates",
+				KeepLast:           10,
+				KeepDailyAfterDays: 7,
+				DeleteAfterDays:    90,
+			}
+			assert.Equal(expPolicy, resp)
+			assert.Equal(1, mockStorage.SaveCompactionPolicyCalls())
+			assert.Len(mockStorage.SavedCompactionPolicies(), 1)
+			assert.Equal(&storage.AidAnalyticsCompactionPolicy{
+				Keyword:            "latest_dates",
+				KeepLast:           10,
+				KeepDailyAfterDays: 7,
+				DeleteAfterDays:    90,
+			}, mockStorage.SavedCompactionPolicies()[0])
+
+			// saved policies are listed
+			stream, err := testStateClient.ListCompactionPolicies(context.Background(), &protos.CompactionPolicyListRequest{})
+			assert.Nil(err)
+			policy, err := stream.Recv()
+			assert.Nil(err)
+			assert.Equal(expPolicy, policy)
+			_, err = stream.Recv()
+			assert.EqualError(err, "EOF")
+		})
+	}
+}
+
+func TestStateCompact(t *testing.T) {
+	// ensure no leakage between tests
+	defer mockStorage.Reset()
+
+	tests := []struct {
+		Description     string
+		ExpErrorMsg     string
+		ExpCompactions  []*protos.Compaction
+		MockCompactions []*storage.Compaction
+		MockError       error
+	}{
+		{
+			Description: "valid request",
+			MockCompactions: []*storage.Compaction{
+				{AppID: 1, Keyword: "latest_dates", Deleted: 3},
+				{AppID: 2, Keyword: "latest_dates", Deleted: 1},
+			},
+			ExpCompactions: []*protos.Compaction{
+				{AppId: 1, Keyword: "latest_dates", Deleted: 3},
+				{AppId: 2, Keyword: "latest_dates", Deleted: 1},
+			},
+		},
+		{
+			Description: "nothing to compact",
+		},
+		{
+			Description: "no policies",
+			ExpErrorMsg: "rpc error: code = NotFound desc = not found",
+			MockError:   storage.ErrNotFound,
+		},
+		{
+			Description: "compact error",
+			ExpErrorMsg: "rpc error: code = Unavailable desc = EXPECTED COMPACT TEST ERROR",
+			MockError:   errors.New("EXPECTED COMPACT TEST ERROR"),
+		},
+	}
+
+	for _, test := range tests {
+		t.Run(test.Description, func(t *testing.T) {
+			assert := require.New(t)
+			mockStorage.Reset()
+			mockStorage.MockCompactions(test.MockCompactions)
+			mockStorage.MockCompactError(test.MockError)
+
+			// exec test
+			stream, err := testStateClient.Compact(context.Background(), &protos.CompactRequest{Keyword: "latest_dates", DryRun: true})
+			assert.Nil(err)
+			if test.ExpErrorMsg != "" {
+				_, err := stream.Recv()
+				assert.EqualError(err, test.ExpErrorMsg)
+				return
+			}
+			for _, exp := range test.ExpCompactions {
+				resp, err := stream.Recv()
+				assert.Nil(err)
+				assert.Equal(exp, resp)
+			}
+			_, err = stream.Recv()
+			assert.EqualError(err, "EOF")
+			assert.Equal(1, mockStorage.CompactCalls())
+		})
+	}
+}
+
+func TestStateDelete(t *testing.T) {
+	// ensure no leakage between tests
+	defer mockStorage.Reset()
+
+	tests := []struct {
+		Description string
+		ExpErrorMsg string
+		Setup       func(req *protos.StateDeleteRequest) *protos.StateDeleteResponse
+	}{
+		{
+			Description: "valid request",
+			Setup: func(req *protos.StateDeleteRequest) *protos.StateDeleteResponse {
+				mockStorage.Reset()
+				mockStorage.MockDeletedStates(1)
+				return &protos.StateDeleteResponse{Deleted: 1}
+			},
+		},
+		{
+			Description: "valid dry run",
+			Setup: func(req *protos.StateDeleteRequest) *protos.StateDeleteResponse {
+				mockStorage.Reset()
+				mockStorage.MockDeletedStates(1)
+				req.DryRun = true
+				return &protos.StateDeleteResponse{Deleted: 1, DryRun: true}
+			},
+		},
+		{
+			Description: "no state",
+			ExpErrorMsg: "rpc error: code = NotFound desc = not found",
+			Setup: func(req *protos.StateDeleteRequest) *protos.StateDeleteResponse {
+				mockStorage.Reset()
+				return nil
+			},
+		},
+		{
+			Description: "missing app id",
+			ExpErrorMsg: "rpc error: code = InvalidArgument desc = app_id: must be a positive integer",
+			Setup: func(req *protos.StateDeleteRequest) *protos.StateDeleteResponse {
+				req.AppId = 0
+				return nil
+			},
+		},
+		{
+			Description: "missing keyword",
+			ExpErrorMsg: "rpc error: code = InvalidArgument desc = keyword: cannot be empty",
+			Setup: func(req *protos.StateDeleteRequest) *protos.StateDeleteResponse {
+				req.Keyword = ""
+				return nil
+			},
+		},
+		{
+			Description: "missing generation time",
+			ExpErrorMsg: "rpc error: code = InvalidArgument desc = generation_time: cannot be nil",
+			Setup: func(req *protos.StateDeleteRequest) *protos.StateDeleteResponse {
+				req.GenerationTime = nil
+				return nil
+			},
+		},
+		{
+			Description: "state delete error",
+			ExpErrorMsg: "rpc error: code = Unavailable desc = EXPECTED STATE DELETE TEST ERROR",
+			Setup: func(req *protos.StateDeleteRequest) *protos.StateDeleteResponse {
+				mockStorage.Reset()
+				mockStorage.MockDeleteStatesError(errors.New("EXPECTED STATE DELETE TEST ERROR"))
+				return nil
+			},
+		},
+	}
+
+	for _, test := range tests {
+		t.Run(test.Description, func(t *testing.T) {
+			assert := require.New(t)
+
+			genTime, _ := ptypes.TimestampProto(time.Now())
+			// create a valid request, expect Setup to invalidate if needed
+			req := &protos.StateDeleteRequest{
+				AppId:          123,
+				Keyword:        fmt.Sprintf("kw-%d", rand.Int()),
+				GenerationTime: genTime,
+			}
+			expResp := test.Setup(req)
+
+			// exec test
+			resp, err := testStateClient.Delete(context.Background(), req)
+			if test.ExpErrorMsg != "" {
+				assert.EqualError(err, test.ExpErrorMsg)
+				return
+			}
+			assert.Nil(err)
+			assert.Equal(expResp, resp)
+			assert.Equal(1, mockStorage.DeleteStatesCalls())
+		})
+	}
+}
+
+func TestStateDeleteRange(t *testing.T) {
+	// ensure no leakage between tests
+	defer mockStorage.Reset()
+
+	tests := []struct {
+		Description string
+		ExpErrorMsg string
+		Setup       func(req *protos.StateDeleteRangeRequest) *protos.StateDeleteResponse
+	}{
+		{
+			Description: "valid request",
+			Setup: func(req *protos.StateDeleteRangeRequest) *protos.StateDeleteResponse {
+				mockStorage.Reset()
+				mockStorage.MockDeletedStates(3)
+				return &protos.StateDeleteResponse{Deleted: 3}
+			},
+		},
+		{
+			Description: "valid request without states",
+			Setup: func(req *protos.StateDeleteRangeRequest) *protos.StateDeleteResponse {
+				mockStorage.Reset()
+				return &protos.StateDeleteResponse{}
+			},
+		},
+		{
+			Description: "valid dry run",
+			Setup: func(req *protos.StateDeleteRangeRequest) *protos.StateDeleteResponse {
+				mockStorage.Reset()
+				mockStorage.MockDeletedStates(3)
+				req.DryRun = true
+				return &protos.StateDeleteResponse{Deleted: 3, DryRun: true}
+			},
+		},
+		{
+			Description: "missing generation time from",
+			ExpErrorMsg: "rpc error: code = InvalidArgument desc = generation_time_from: cannot be nil",
+			Setup: func(req *protos.StateDeleteRangeRequest) *protos.StateDeleteResponse {
+				req.GenerationTimeFrom = nil
+				return nil
+			},
+		},
+		{
+			Description: "missing generation time to",
+			ExpErrorMsg: "rpc error: code = InvalidArgument desc = generation_time_to: cannot be nil",
+			Setup: func(req *protos.StateDeleteRangeRequest) *protos.StateDeleteResponse {
+				req.GenerationTimeTo = nil
+				return nil
+			},
+		},
+		{
+			Description: "reversed time range",
+			ExpErrorMsg: "rpc error: code = InvalidArgument desc = generation_time_to: cannot be before generation_time_from",
+			Setup: func(req *protos.StateDeleteRangeRequest) *protos.StateDeleteResponse {
+				req.GenerationTimeFrom, req.GenerationTimeTo = req.GenerationTimeTo, req.GenerationTimeFrom
+				return nil
+			},
+		},
+		{
+			Description: "state delete error",
+			ExpErrorMsg: "rpc error: code = Unavailable desc = EXPECTED STATE DELETE TEST ERROR",
+			Setup: func(req *protos.StateDeleteRangeRequest) *protos.StateDeleteResponse {
+				mockStorage.Reset()
+				mockStorage.MockDeleteStatesError(errors.New("EXPECTED STATE DELETE TEST ERROR"))
+				return nil
+			},
+		},
+	}
+
+	for _, test := range tests {
+		t.Run(test.Description, func(t *testing.T) {
+			assert := require.New(t)
+
+			genTimeFrom, _ := ptypes.TimestampProto(time.Now().Add(-time.Hour))
+			genTimeTo, _ := ptypes.TimestampProto(time.Now())
+			// create a valid request, expect Setup to invalidate if needed
+			req := &protos.StateDeleteRangeRequest{
+				AppId:              123,
+				Keyword:            fmt.Sprintf("kw-%d", rand.Int()),
+				GenerationTimeFrom: genTimeFrom,
+				GenerationTimeTo:   genTimeTo,
+			}
+			expResp := test.Setup(req)
+
+			// exec test
+			resp, err := testStateClient.DeleteRange(context.Background(), req)
+			if test.ExpErrorMsg != "" {
+				assert.EqualError(err, test.ExpErrorMsg)
+				return
+			}
+			assert.Nil(err)
+			assert.Equal(expResp, resp)
+			assert.Equal(1, mockStorage.DeleteStatesCalls())
+		})
+	}
+}
+
+func TestStateListReadMask(t *testing.T) {
+	assert := require.New(t)
+	defer mockStorage.Reset()
+
+	savedAt := time.Now().Add(-5 * time.Minute)
+	mockStorage.MockSavedStates([]*storage.AidAnalyticsState{
+		{ID: 1, AppID: 123, Keyword: "forecast", SavedAt: savedAt, Revision: 2},
+	})
+
+	// states are listed without data
+	stream, err := testStateClient.List(context.Background(), &protos.StateListRequest{
+		AppId:    123,
+		ReadMask: &field_mask.FieldMask{Paths: []string{"keyword", "generation_time"}},
+	})
+	assert.Nil(err)
+	state, err := stream.Recv()
+	assert.Nil(err)
+	assert.Equal("forecast", state.Keyword)
+	assert.Equal(savedAt.Unix(), state.GenerationTime.Seconds)
+	assert.Zero(state.AppId)
+	assert.Zero(state.Revision)
+	_, err = stream.Recv()
+	assert.EqualError(err, "EOF")
+	assert.Equal(1, mockStorage.ListStatesWithoutDataCalls())
+
+	// data is read if it is in the mask
+	mockStorage.Reset()
+	mockStorage.MockSavedStates([]*storage.AidAnalyticsState{
+		{ID: 1, AppID: 123, Keyword: "forecast", SavedAt: savedAt, Data: []byte(`{"abc":"def"}`)},
+	})
+	stream, err = testStateClient.List(context.Background(), &protos.StateListRequest{
+		AppId:    123,
+		ReadMask: &field_mask.FieldMask{Paths: []string{"data"}},
+	})
+	assert.Nil(err)
+	state, err = stream.Recv()
+	assert.Nil(err)
+	assert.Equal(`{"abc":"def"}`, string(state.Data))
+	assert.Empty(state.Keyword)
+	assert.Equal(1, mockStorage.ListStatesCalls())
+	assert.Equal(0, mockStorage.ListStatesWithoutDataCalls())
+
+	// unknown and nested fields are rejected
+	stream, err = testStateClient.List(context.Background(), &protos.StateListRequest{
+		AppId:    123,
+		ReadMask: &field_mask.FieldMask{Paths: []string{"data", "size", "generation_time.seconds"}},
+	})
+	assert.Nil(err)
+	_, err = stream.Recv()
+	assert.EqualError(err, "rpc error: code = InvalidArgument desc = read_mask: "+
+		"unknown fields size, generation_time.seconds of callstats.ai_decision.State")
+}
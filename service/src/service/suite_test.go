@@ -16,12 +16,18 @@ import (
 	"google.golang.org/grpc"
 )
 
+// testStateMaxSize is the maximum size of states of unregistered keywords in tests, above the chunked test state
+const testStateMaxSize = 3 * service.StateChunkSize
+
 var (
 	testCtx, testCtxCancel = context.WithCancel(context.Background())
 	testServer             *sgrpc.Server
 	testClientConn         *grpc.ClientConn
 	testMessageClient      protos.AIDecisionMessageServiceClient
 	testStateClient        protos.AIDecisionStateServiceClient
+	testLeaseClient        protos.AIDecisionLeaseServiceClient
+	testRunClient          protos.AIDecisionRunServiceClient
+	testAuditClient        protos.AIDecisionAuditServiceClient
 	mockStorage            *mocks.Storage
 )
 
@@ -38,7 +44,15 @@ func suiteSetup() {
 	mustBeNil(err)
 	aiDecisionStateService, err := service.NewAIDecisionStateService(mockStorage)
 	mustBeNil(err)
-	testServer, err = sgrpc.NewServer(testCtx, aiDecisionMessageService, aiDecisionStateService)
+	aiDecisionStateService.WithMaxSize(testStateMaxSize)
+	aiDecisionLeaseService, err := service.NewAIDecisionLeaseService(mockStorage)
+	mustBeNil(err)
+	aiDecisionRunService, err := service.NewAIDecisionRunService(mockStorage)
+	mustBeNil(err)
+	aiDecisionAuditService, err := service.NewAIDecisionAuditService(mockStorage)
+	mustBeNil(err)
+	testServer, err = sgrpc.NewServer(testCtx, nil, aiDecisionMessageService, aiDecisionStateService, aiDecisionLeaseService,
+		aiDecisionRunService, aiDecisionAuditService)
 	mustBeNil(err)
 
 	testServerListener, err := net.Listen("tcp", fmt.Sprintf("localhost:0"))
@@ -56,6 +70,9 @@ func suiteSetup() {
 	mustBeNil(err)
 	testMessageClient = protos.NewAIDecisionMessageServiceClient(testClientConn)
 	testStateClient = protos.NewAIDecisionStateServiceClient(testClientConn)
+	testLeaseClient = protos.NewAIDecisionLeaseServiceClient(testClientConn)
+	testRunClient = protos.NewAIDecisionRunServiceClient(testClientConn)
+	testAuditClient = protos.NewAIDecisionAuditServiceClient(testClientConn)
 }
 
 func suiteTeardown() {
@@ -2,50 +2,143 @@ package service
 
 import (
 	"context"
+	"encoding/json"
 	"fmt"
+	"strings"
 
 	"github.com/callstats-io/ai-decision/service/src/grpc"
 	"github.com/golang/protobuf/ptypes/timestamp"
+	"google.golang.org/genproto/googleapis/rpc/errdetails"
 )
 
 func validatePositiveInt(field string, val int32) error {
 	if val <= 0 {
-		return fmt.Errorf("%s: must be a positive integer", field)
+		return violation(field, "must be a positive integer")
+	}
+	return nil
+}
+
+func validateNonNegativeInt(field string, val int32) error {
+	if val < 0 {
+		return violation(field, "cannot be negative")
+	}
+	return nil
+}
+
+// validateGreaterThan validates val is greater than other if both are set
+func validateGreaterThan(field string, val int32, otherField string, other int32) error {
+	if val > 0 && other > 0 && val <= other {
+		return violation(field, "must be greater than %s", otherField)
+	}
+	return nil
+}
+
+func validatePositiveInt64(field string, val int64) error {
+	if val <= 0 {
+		return violation(field, "must be a positive integer")
+	}
+	return nil
+}
+
+func validateNonNegativeInt64(field string, val int64) error {
+	if val < 0 {
+		return violation(field, "cannot be negative")
 	}
 	return nil
 }
 
 func validateNonEmptyString(field string, val string) error {
 	if val == "" {
-		return fmt.Errorf("%s: cannot be empty", field)
+		return violation(field, "cannot be empty")
 	}
 	return nil
 
 }
 func validateNonEmptyBytes(field string, data []byte) error {
 	if len(data) == 0 {
-		return fmt.Errorf("%s: cannot be empty", field)
+		return violation(field, "cannot be empty")
 	}
 	return nil
 
 }
+func validateEmptyBytes(field string, data []byte) error {
+	if len(data) != 0 {
+		return violation(field, "must be empty")
+	}
+	return nil
+}
+
+func validateChecksum(field string, checksum, expected string) error {
+	if checksum != "" && !strings.EqualFold(checksum, expected) {
+		return violation(field, "does not match data")
+	}
+	return nil
+}
+
 func validateTimestamp(field string, gt *timestamp.Timestamp) error {
 	if gt == nil {
-		return fmt.Errorf("%s: cannot be nil", field)
+		return violation(field, "cannot be nil")
 	}
 	if gt.Seconds <= 0 {
-		return fmt.Errorf("%s: must have positive seconds", field)
+		return violation(field, "must have positive seconds")
 	}
 	return nil
 
 }
 
-// validate all errors are nil or return first error
+// fieldViolation is the error of a validation of a request field
+type fieldViolation struct {
+	field       string
+	description string
+}
+
+func (v *fieldViolation) Error() string {
+	return v.field + ": " + v.description
+}
+
+func violation(field string, format string, args ...interface{}) error {
+	return &fieldViolation{field: field, description: fmt.Sprintf(format, args...)}
+}
+
+// validate all errors are nil or return all of them as field violations of a BadRequest
 func validate(ctx context.Context, errors ...error) error {
+	var violations []*errdetails.BadRequest_FieldViolation
 	for _, err := range errors {
-		if err != nil {
-			return grpc.ErrInvalidArgument(ctx, err)
+		switch v := err.(type) {
+		case nil:
+		case *fieldViolation:
+			violations = append(violations, &errdetails.BadRequest_FieldViolation{Field: v.field, Description: v.description})
+		default:
+			violations = append(violations, &errdetails.BadRequest_FieldViolation{Description: err.Error()})
 		}
 	}
+	if len(violations) == 0 {
+		return nil
+	}
+	return grpc.ErrBadRequest(ctx, violations)
+}
+
+func validateMaxSize(field string, size int, maxSize int32) error {
+	if maxSize > 0 && size > int(maxSize) {
+		return violation(field, "exceeds maximum size of %d bytes", maxSize)
+	}
+	return nil
+}
+
+func validateContentType(field string, data []byte, contentType string) error {
+	if contentType == ContentTypeJSON && !json.Valid(data) {
+		return violation(field, "must be valid JSON")
+	}
+	return nil
+}
+
+// validateTimeRange validates the end of a time range is not before its start, nil timestamps are validated separately
+func validateTimeRange(field string, to *timestamp.Timestamp, fromField string, from *timestamp.Timestamp) error {
+	if to == nil || from == nil {
+		return nil
+	}
+	if to.Seconds < from.Seconds || (to.Seconds == from.Seconds && to.Nanos < from.Nanos) {
+		return violation(field, "cannot be before %s", fromField)
+	}
 	return nil
 }
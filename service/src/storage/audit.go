@@ -0,0 +1,161 @@
+package storage
+
+import (
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"fmt"
+	"time"
+
+	"github.com/callstats-io/go-common/postgres"
+	"github.com/go-pg/pg/orm"
+)
+
+// AuditActorSystem is the actor of the mutations made by the service itself, e.g. scheduled compactions
+const AuditActorSystem = "system"
+
+// Audit identifies who makes the mutations of a context, with which RPC and why
+type Audit struct {
+	Actor  string // e.g. the subject of the token of a call
+	RPC    string // full method of the call, or cli:<flag> for the command line
+	Reason string
+}
+
+type auditKey struct{}
+
+// WithAudit returns a copy of the context the mutations of which are audited with the actor, RPC and reason of audit
+func WithAudit(ctx context.Context, audit Audit) context.Context {
+	return context.WithValue(ctx, auditKey{}, audit)
+}
+
+// AuditFromContext returns the audit of the mutations of a context, empty if not set
+func AuditFromContext(ctx context.Context) Audit {
+	audit, _ := ctx.Value(auditKey{}).(Audit)
+	return audit
+}
+
+// checkAudit returns ErrNoAuditActor if the mutations of a context are audited without an actor, e.g. the mutations
+// of calls whose token names no actor, which must not be recorded as made by nobody
+func checkAudit(ctx context.Context) error {
+	if audit, ok := ctx.Value(auditKey{}).(Audit); ok && audit.Actor == "" {
+		return ErrNoAuditActor
+	}
+	return nil
+}
+
+// auditHash returns the hex encoded SHA-256 of an uncompressed payload, as the checksums of chunked states
+func auditHash(data []byte) string {
+	sum := sha256.Sum256(data)
+	return hex.EncodeToString(sum[:])
+}
+
+// stateHash returns the hash of the uncompressed data of a state, the checksum of chunked states
+func stateHash(state *AidAnalyticsState) string {
+	if state.ChunkCount > 0 {
+		return state.Checksum
+	}
+	return auditHash(state.Data)
+}
+
+// stateTarget returns the audited target of a state, its keyword and saved at time as stored
+func stateTarget(keyword string, savedAt time.Time) string {
+	return keyword + "@" + dbTime(savedAt).UTC().Format(time.RFC3339Nano)
+}
+
+// messageTarget returns the audited target of a message, its id
+func messageTarget(id int32) string {
+	return fmt.Sprint(id)
+}
+
+// templateTarget returns the audited target of a message template, its type and version
+func templateTarget(tmpl *MessageTemplate) string {
+	return fmt.Sprintf("%s:%d", tmpl.Type, tmpl.Version)
+}
+
+// policyHash returns the hash of the rules of a compaction policy
+func policyHash(policy *AidAnalyticsCompactionPolicy) string {
+	return auditHash([]byte(fmt.Sprintf("keep_last=%d,keep_daily_after_days=%d,delete_after_days=%d",
+		policy.KeepLast, policy.KeepDailyAfterDays, policy.DeleteAfterDays)))
+}
+
+// newAuditEvent returns an event of an action on a single row made with the audit of the context
+func newAuditEvent(ctx context.Context, appID int32, action, target string) *AuditEvent {
+	audit := AuditFromContext(ctx)
+	return &AuditEvent{
+		Actor:  audit.Actor,
+		RPC:    audit.RPC,
+		Reason: audit.Reason,
+		AppID:  appID,
+		Action: action,
+		Target: target,
+		Count:  1,
+	}
+}
+
+// insertAuditEvents inserts audit events, in the transaction of their mutation
+func insertAuditEvents(tx orm.DB, events ...*AuditEvent) error {
+	if len(events) == 0 {
+		return nil
+	}
+	_, err := tx.Model(&events).Returning("*").Insert()
+	return err
+}
+
+// storedStateHash returns the hash of a stored state locking it until the end of the transaction, empty if the
+// state does not exist yet
+func storedStateHash(tx orm.DB, state *AidAnalyticsState) (string, error) {
+	stored := &AidAnalyticsState{}
+	if _, err := tx.QueryOne(stored, `
+		SELECT data, codec, chunk_count, checksum FROM aid_analytics_states
+		WHERE app_id = ? AND keyword = ? AND saved_at = ?
+		FOR UPDATE`, state.AppID, state.Keyword, state.SavedAt); err != nil {
+		if err == postgres.ErrNoRows {
+			return "", nil
+		}
+		return "", err
+	}
+	if err := decodeState(stored); err != nil {
+		return "", err
+	}
+	return stateHash(stored), nil
+}
+
+// ListAuditEvents fetches the audit events matching the filter, newest first.
+// Returns ErrNotFound if there are no matching events.
+func (s *Postgres) ListAuditEvents(ctx context.Context, filter *AuditFilter) ([]*AuditEvent, error) {
+	db, err := s.readDB(ctx, filter.AppID)
+	if err != nil {
+		return nil, err
+	}
+
+	var events []*AuditEvent
+	query := db.Model(&events)
+	if filter.AppID != 0 {
+		query = query.Where("app_id = ?", filter.AppID)
+	}
+	if filter.Actor != "" {
+		query = query.Where("actor = ?", filter.Actor)
+	}
+	if filter.Action != "" {
+		query = query.Where("action = ?", filter.Action)
+	}
+	if filter.Target != "" {
+		query = query.Where("target = ?", filter.Target)
+	}
+	if filter.From != nil {
+		query = query.Where("occurred_at >= ?", filter.From)
+	}
+	if filter.To != nil {
+		query = query.Where("occurred_at <= ?", filter.To)
+	}
+	if filter.Limit > 0 {
+		query = query.Limit(filter.Limit)
+	}
+	if err := query.Order("occurred_at DESC", "id DESC").Select(); err != nil {
+		return nil, classify(err)
+	}
+	if len(events) == 0 {
+		return nil, ErrNotFound
+	}
+	return events, nil
+}
@@ -0,0 +1,173 @@
+package storage
+
+import (
+	"context"
+	"errors"
+	"time"
+
+	"github.com/callstats-io/go-common/log"
+	"github.com/callstats-io/go-common/postgres"
+	"github.com/go-pg/pg"
+)
+
+// errDryRun rolls back the compaction transaction of dry runs
+var errDryRun = errors.New("dry run")
+
+// compactQuery deletes the states of a keyword not kept by a compaction policy.
+// Parameters are the keyword, keep last, keep daily after days, delete after days and the current time.
+// The newest keep last states per app are always kept. Older states are deleted if older than delete after days,
+// if older than keep daily after days and not the newest state of their day, or otherwise if keep last is set.
+const compactQuery = `
+	WITH ranked AS (
+		SELECT id, saved_at,
+			row_number() OVER (PARTITION BY app_id, keyword ORDER BY saved_at DESC) AS rank,
+			row_number() OVER (PARTITION BY app_id, keyword, date_trunc('day', saved_at) ORDER BY saved_at DESC) AS day_rank
+		FROM aid_analytics_states
+		WHERE keyword = ?0
+	), deleted AS (
+		DELETE FROM aid_analytics_states s
+		USING ranked r
+		WHERE s.id = r.id
+		AND (?1 = 0 OR r.rank > ?1)
+		AND (
+			(?3 > 0 AND r.saved_at < ?4::timestamptz - ?3 * interval '1 day')
+			OR (?2 > 0 AND r.saved_at < ?4::timestamptz - ?2 * interval '1 day' AND r.day_rank > 1)
+			OR (?1 > 0 AND (?2 = 0 OR r.saved_at >= ?4::timestamptz - ?2 * interval '1 day'))
+		)
+		RETURNING s.app_id, s.keyword
+	)
+	SELECT app_id, keyword, count(*) AS deleted FROM deleted GROUP BY app_id, keyword ORDER BY app_id`
+
+// SaveCompactionPolicy creates or replaces the compaction policy of a keyword.
+// The save is audited in the same transaction with the hashes of the rules of the policy before and after the save.
+func (s *Postgres) SaveCompactionPolicy(ctx context.Context, policy *AidAnalyticsCompactionPolicy) error {
+	if err := checkAudit(ctx); err != nil {
+		return err
+	}
+	db, err := s.db(ctx)
+	if err != nil {
+		return err
+	}
+
+	policy.UpdatedAt = time.Now()
+	err = db.RunInTransaction(func(tx *pg.Tx) error {
+		event := newAuditEvent(ctx, 0, AuditCompactionPolicySave, policy.Keyword)
+		event.AfterHash = policyHash(policy)
+		stored := &AidAnalyticsCompactionPolicy{Keyword: policy.Keyword}
+		if err := tx.Model(stored).WherePK().For("UPDATE").Select(); err == nil {
+			event.BeforeHash = policyHash(stored)
+		} else if err != postgres.ErrNoRows {
+			return err
+		}
+		if _, err := tx.Model(policy).
+			OnConflict("(keyword) DO UPDATE").
+			Set("keep_last = EXCLUDED.keep_last, keep_daily_after_days = EXCLUDED.keep_daily_after_days, delete_after_days = EXCLUDED.delete_after_days").
+			Set("updated_at = EXCLUDED.updated_at").
+			Insert(); err != nil {
+			return err
+		}
+		return insertAuditEvents(tx, event)
+	})
+	return classify(err)
+}
+
+// ListCompactionPolicies fetches the compaction policies of all keywords in byte order of the keywords, as by Go,
+// or ErrNotFound if there are none
+func (s *Postgres) ListCompactionPolicies(ctx context.Context) ([]*AidAnalyticsCompactionPolicy, error) {
+	db, err := s.db(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	var policies []*AidAnalyticsCompactionPolicy
+	if err := db.Model(&policies).OrderExpr(`keyword COLLATE "C"`).Select(); err != nil {
+		return nil, classify(err)
+	}
+	if len(policies) == 0 {
+		return nil, ErrNotFound
+	}
+	return policies, nil
+}
+
+// Compact deletes the states not kept by the compaction policies of all keywords, or just keyword if not empty.
+// Dry runs report the states that would be deleted without deleting them.
+// The compaction of every app is audited with the number of deleted states in the transaction of its keyword.
+// Returns ErrNotFound if there is no policy to compact by.
+func (s *Postgres) Compact(ctx context.Context, keyword string, dryRun bool) ([]*Compaction, error) {
+	if !dryRun {
+		if err := checkAudit(ctx); err != nil {
+			return nil, err
+		}
+	}
+	db, err := s.db(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	var policies []*AidAnalyticsCompactionPolicy
+	query := db.Model(&policies).Order("keyword")
+	if keyword != "" {
+		query = query.Where("keyword = ?", keyword)
+	}
+	if err := query.Select(); err != nil {
+		return nil, classify(err)
+	}
+	if len(policies) == 0 {
+		return nil, ErrNotFound
+	}
+
+	now := time.Now()
+	compactions := []*Compaction{}
+	for _, policy := range policies {
+		var deleted []*Compaction
+		err := db.RunInTransaction(func(tx *pg.Tx) error {
+			if _, err := tx.Query(&deleted, compactQuery,
+				policy.Keyword, policy.KeepLast, policy.KeepDailyAfterDays, policy.DeleteAfterDays, now); err != nil {
+				return err
+			}
+			if dryRun {
+				return errDryRun
+			}
+			events := make([]*AuditEvent, len(deleted))
+			for i, c := range deleted {
+				events[i] = newAuditEvent(ctx, c.AppID, AuditStateCompact, c.Keyword)
+				events[i].Count = c.Deleted
+			}
+			return insertAuditEvents(tx, events...)
+		})
+		if err != nil && err != errDryRun {
+			return compactions, classify(err)
+		}
+		if !dryRun {
+			s.writes.record(0)
+			for _, c := range deleted {
+				compactedCounter.WithLabelValues(c.Keyword).Add(float64(c.Deleted))
+			}
+		}
+		compactions = append(compactions, deleted...)
+	}
+	return compactions, nil
+}
+
+// RunCompaction compacts states by their compaction policies every interval until the context is done.
+// Compactions are audited as made by AuditActorSystem.
+func (s *Postgres) RunCompaction(ctx context.Context, interval time.Duration) {
+	ctx = WithAudit(ctx, Audit{Actor: AuditActorSystem, Reason: "scheduled compaction"})
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+	for {
+		compactions, err := s.Compact(ctx, "", false)
+		for _, c := range compactions {
+			log.FromContext(ctx).Info("compacted states",
+				log.Int("appID", int(c.AppID)), log.String("keyword", c.Keyword), log.Int("deleted", int(c.Deleted)))
+		}
+		if err != nil && err != ErrNotFound {
+			log.FromContext(ctx).Warn("failed to compact states", log.Error(err))
+		}
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+		}
+	}
+}
@@ -0,0 +1,79 @@
+package storage_test
+
+import (
+	"context"
+	"fmt"
+	"math/rand"
+	"testing"
+	"time"
+
+	"github.com/callstats-io/ai-decision/service/src/storage"
+	"github.com/callstats-io/go-common/testutil"
+	"github.com/stretchr/testify/require"
+)
+
+func TestCompact(t *testing.T) {
+	assert := require.New(t)
+	s := storage.NewPostgres(testPostgresClient)
+	keyword := fmt.Sprintf("kw-compact-%d", rand.Int())
+	now := time.Now()
+	oldDay := now.Truncate(24 * time.Hour).Add(-10 * 24 * time.Hour)
+
+	assert.Nil(testutil.WithDeadlineContext(5*time.Second, func(ctx context.Context) {
+		_, err := s.Compact(ctx, keyword, false)
+		assert.Equal(storage.ErrNotFound, err)
+
+		assert.Nil(s.SaveCompactionPolicy(ctx, &storage.AidAnalyticsCompactionPolicy{
+			Keyword:            keyword,
+			KeepLast:           2,
+			KeepDailyAfterDays: 7,
+			DeleteAfterDays:    30,
+		}))
+		for _, savedAt := range []time.Time{
+			now.Add(-1 * time.Hour),       // kept as one of the last 2
+			now.Add(-2 * time.Hour),       // kept as one of the last 2
+			now.Add(-3 * time.Hour),       // deleted by keep last
+			oldDay.Add(12 * time.Hour),    // kept as newest of its day
+			oldDay.Add(10 * time.Hour),    // deleted by keep daily
+			now.Add(-40 * 24 * time.Hour), // deleted by delete after
+		} {
+			state := &storage.AidAnalyticsState{AppID: 123, Keyword: keyword, SavedAt: savedAt, Data: []byte(`{}`)}
+			assert.Nil(s.SaveState(ctx, state))
+		}
+
+		// dry runs do not delete
+		compactions, err := s.Compact(ctx, keyword, true)
+		assert.Nil(err)
+		assert.Equal([]*storage.Compaction{{AppID: 123, Keyword: keyword, Deleted: 3}}, compactions)
+		states, err := s.ListStates(ctx, 123, keyword, nil, nil)
+		assert.Nil(err)
+		assert.Len(states, 6)
+
+		compactions, err = s.Compact(ctx, keyword, false)
+		assert.Nil(err)
+		assert.Equal([]*storage.Compaction{{AppID: 123, Keyword: keyword, Deleted: 3}}, compactions)
+		states, err = s.ListStates(ctx, 123, keyword, nil, nil)
+		assert.Nil(err)
+		assert.Len(states, 3)
+
+		// the compaction of the app is audited once with its number of deleted states, dry runs are not audited
+		events, err := s.ListAuditEvents(ctx, &storage.AuditFilter{Action: storage.AuditStateCompact, Target: keyword})
+		assert.Nil(err)
+		assert.Len(events, 1)
+		assert.Equal(int32(123), events[0].AppID)
+		assert.Equal(int32(3), events[0].Count)
+
+		// compacted states stay compacted
+		compactions, err = s.Compact(ctx, keyword, false)
+		assert.Nil(err)
+		assert.Empty(compactions)
+
+		policies, err := s.ListCompactionPolicies(ctx)
+		assert.Nil(err)
+		var found bool
+		for _, p := range policies {
+			found = found || p.Keyword == keyword
+		}
+		assert.True(found)
+	}))
+}
@@ -0,0 +1,257 @@
+package storage
+
+import (
+	"bytes"
+	"compress/gzip"
+	"context"
+	"fmt"
+	"io/ioutil"
+	"time"
+
+	"github.com/callstats-io/go-common/log"
+)
+
+// Codecs recorded per row in the codec column of compressed tables
+const (
+	CodecNone = "none"
+	CodecGzip = "gzip"
+)
+
+// compression defaults
+const (
+	// DefaultCompressionThreshold is the payload size in bytes from which payloads are compressed
+	DefaultCompressionThreshold = 1024
+	// DefaultRecompressionBatchSize is the number of rows read at once by the recompression job
+	DefaultRecompressionBatchSize = 100
+)
+
+// table names used in metric labels
+const (
+	tableStates      = "aid_analytics_states"
+	tableStateChunks = "aid_analytics_state_chunks"
+	tableMessages    = "messages"
+)
+
+// compress returns the codec and the compressed payload if the payload is at least threshold bytes and
+// compresses into fewer bytes. Otherwise the payload is returned as is with CodecNone.
+// A non-positive threshold disables compression.
+func compress(data []byte, threshold int) (string, []byte, error) {
+	if threshold <= 0 || len(data) < threshold {
+		return CodecNone, data, nil
+	}
+	var buf bytes.Buffer
+	w := gzip.NewWriter(&buf)
+	if _, err := w.Write(data); err != nil {
+		return "", nil, err
+	}
+	if err := w.Close(); err != nil {
+		return "", nil, err
+	}
+	if buf.Len() >= len(data) {
+		return CodecNone, data, nil
+	}
+	return CodecGzip, buf.Bytes(), nil
+}
+
+// decompress returns the original payload of data stored with the given codec
+func decompress(codec string, data []byte) ([]byte, error) {
+	switch codec {
+	case "", CodecNone:
+		return data, nil
+	case CodecGzip:
+		r, err := gzip.NewReader(bytes.NewReader(data))
+		if err != nil {
+			return nil, err
+		}
+		defer r.Close()
+		return ioutil.ReadAll(r)
+	default:
+		return nil, fmt.Errorf("unknown codec %q", codec)
+	}
+}
+
+// encode compresses data according to the storage threshold and records the payload sizes of the table
+func (s *Postgres) encode(table string, data []byte) (string, []byte, error) {
+	codec, stored, err := compress(data, s.compressionThreshold)
+	if err != nil {
+		return "", nil, err
+	}
+	payloadWrittenBytesCounter.WithLabelValues(table, SizeLogical).Add(float64(len(data)))
+	payloadWrittenBytesCounter.WithLabelValues(table, SizeStored).Add(float64(len(stored)))
+	return codec, stored, nil
+}
+
+// decodeState decompresses the data of a state read from postgres
+func decodeState(state *AidAnalyticsState) error {
+	data, err := decompress(state.Codec, state.Data)
+	if err != nil {
+		return err
+	}
+	state.Data = data
+	return nil
+}
+
+// decodeMessage decompresses the data of a message read from postgres
+func decodeMessage(msg *Message) error {
+	data, err := decompress(msg.Codec, msg.Data)
+	if err != nil {
+		return err
+	}
+	msg.Data = data
+	return nil
+}
+
+// Recompress compresses the payloads of existing states, state chunks and messages stored uncompressed.
+// Rows are processed in batches of batchSize ordered by id. Returns the number of recompressed rows.
+func (s *Postgres) Recompress(ctx context.Context, batchSize int) (int, error) {
+	if s.compressionThreshold <= 0 {
+		return 0, nil
+	}
+	total := 0
+	for _, recompress := range []func(context.Context, int) (int, error){
+		s.recompressStates,
+		s.recompressStateChunks,
+		s.recompressMessages,
+	} {
+		n, err := recompress(ctx, batchSize)
+		total += n
+		if err != nil {
+			return total, classify(err)
+		}
+	}
+	return total, nil
+}
+
+func (s *Postgres) recompressStates(ctx context.Context, batchSize int) (int, error) {
+	db, err := s.db(ctx)
+	if err != nil {
+		return 0, err
+	}
+	count := 0
+	for lastID := int32(0); ; {
+		var states []*AidAnalyticsState
+		if err := db.Model(&states).
+			Where("id > ? AND codec = ? AND octet_length(data) >= ?", lastID, CodecNone, s.compressionThreshold).
+			Order("id").Limit(batchSize).Select(); err != nil {
+			return count, err
+		}
+		if len(states) == 0 {
+			return count, nil
+		}
+		for _, state := range states {
+			lastID = state.ID
+			codec, data, err := compress(state.Data, s.compressionThreshold)
+			if err != nil {
+				return count, err
+			}
+			if codec == CodecNone {
+				continue
+			}
+			// only update rows not changed in between
+			res, err := db.Model((*AidAnalyticsState)(nil)).
+				Set("data = ?, codec = ?", data, codec).
+				Where("id = ? AND codec = ? AND revision = ?", state.ID, CodecNone, state.Revision).
+				Update()
+			if err != nil {
+				return count, err
+			}
+			count += res.RowsAffected()
+			recompressedCounter.WithLabelValues(tableStates).Add(float64(res.RowsAffected()))
+		}
+	}
+}
+
+func (s *Postgres) recompressStateChunks(ctx context.Context, batchSize int) (int, error) {
+	db, err := s.db(ctx)
+	if err != nil {
+		return 0, err
+	}
+	count := 0
+	for lastStateID, lastSeq := int32(0), int32(-1); ; {
+		var chunks []*AidAnalyticsStateChunk
+		if err := db.Model(&chunks).
+			Where("(state_id, seq) > (?, ?) AND codec = ? AND octet_length(data) >= ?", lastStateID, lastSeq, CodecNone, s.compressionThreshold).
+			Order("state_id", "seq").Limit(batchSize).Select(); err != nil {
+			return count, err
+		}
+		if len(chunks) == 0 {
+			return count, nil
+		}
+		for _, chunk := range chunks {
+			lastStateID, lastSeq = chunk.StateID, chunk.Seq
+			codec, data, err := compress(chunk.Data, s.compressionThreshold)
+			if err != nil {
+				return count, err
+			}
+			if codec == CodecNone {
+				continue
+			}
+			res, err := db.Model((*AidAnalyticsStateChunk)(nil)).
+				Set("data = ?, codec = ?", data, codec).
+				Where("state_id = ? AND seq = ? AND codec = ?", chunk.StateID, chunk.Seq, CodecNone).
+				Update()
+			if err != nil {
+				return count, err
+			}
+			count += res.RowsAffected()
+			recompressedCounter.WithLabelValues(tableStateChunks).Add(float64(res.RowsAffected()))
+		}
+	}
+}
+
+func (s *Postgres) recompressMessages(ctx context.Context, batchSize int) (int, error) {
+	db, err := s.db(ctx)
+	if err != nil {
+		return 0, err
+	}
+	count := 0
+	for lastID := int32(0); ; {
+		var messages []*Message
+		if err := db.Model(&messages).
+			Where("id > ? AND codec = ? AND octet_length(data) >= ?", lastID, CodecNone, s.compressionThreshold).
+			Order("id").Limit(batchSize).Select(); err != nil {
+			return count, err
+		}
+		if len(messages) == 0 {
+			return count, nil
+		}
+		for _, msg := range messages {
+			lastID = msg.ID
+			codec, data, err := compress(msg.Data, s.compressionThreshold)
+			if err != nil {
+				return count, err
+			}
+			if codec == CodecNone {
+				continue
+			}
+			res, err := db.Model((*Message)(nil)).
+				Set("data = ?, codec = ?", data, codec).
+				Where("id = ? AND codec = ?", msg.ID, CodecNone).
+				Update()
+			if err != nil {
+				return count, err
+			}
+			count += res.RowsAffected()
+			recompressedCounter.WithLabelValues(tableMessages).Add(float64(res.RowsAffected()))
+		}
+	}
+}
+
+// RunRecompression recompresses existing rows every interval until the context is done
+func (s *Postgres) RunRecompression(ctx context.Context, interval time.Duration, batchSize int) {
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+	for {
+		n, err := s.Recompress(ctx, batchSize)
+		if err != nil {
+			log.FromContext(ctx).Warn("failed to recompress payloads", log.Int("recompressed", n), log.Error(err))
+		} else if n > 0 {
+			log.FromContext(ctx).Info("recompressed payloads", log.Int("recompressed", n))
+		}
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+		}
+	}
+}
@@ -0,0 +1,75 @@
+package storage_test
+
+import (
+	"bytes"
+	"context"
+	"fmt"
+	"math/rand"
+	"testing"
+	"time"
+
+	"github.com/callstats-io/ai-decision/service/src/storage"
+	"github.com/callstats-io/go-common/testutil"
+	"github.com/stretchr/testify/require"
+)
+
+func TestStateCompression(t *testing.T) {
+	assert := require.New(t)
+	s := storage.NewPostgres(testPostgresClient).WithCompressionThreshold(100)
+	payload := bytes.Repeat([]byte(`{"val1":"abc"}`), 100)
+
+	assert.Nil(testutil.WithDeadlineContext(time.Second, func(ctx context.Context) {
+		state := &storage.AidAnalyticsState{AppID: 123, Keyword: fmt.Sprintf("kw-compress-%d", rand.Int()), SavedAt: time.Now(), Data: payload}
+		assert.Nil(s.SaveState(ctx, state))
+		assert.Equal(storage.CodecGzip, state.Codec)
+		assert.Equal(payload, state.Data)
+
+		// stored compressed
+		storedState := &storage.AidAnalyticsState{ID: state.ID}
+		assert.Nil(testPostgresDB.Select(storedState))
+		assert.Equal(storage.CodecGzip, storedState.Codec)
+		assert.True(len(storedState.Data) < len(payload))
+
+		// read decompressed
+		readState := &storage.AidAnalyticsState{AppID: state.AppID, Keyword: state.Keyword, SavedAt: state.SavedAt}
+		assert.Nil(s.GetState(ctx, readState))
+		assert.Equal(payload, readState.Data)
+
+		// small payloads are stored as is
+		small := &storage.AidAnalyticsState{AppID: 123, Keyword: fmt.Sprintf("kw-compress-%d", rand.Int()), SavedAt: time.Now(), Data: []byte(`{"val1":"abc"}`)}
+		assert.Nil(s.SaveState(ctx, small))
+		assert.Equal(storage.CodecNone, small.Codec)
+	}))
+}
+
+func TestRecompress(t *testing.T) {
+	assert := require.New(t)
+	payload := bytes.Repeat([]byte(`{"val1":"abc"}`), 100)
+
+	// rows written with compression disabled
+	state := &storage.AidAnalyticsState{AppID: 123, Keyword: fmt.Sprintf("kw-recompress-%d", rand.Int()), SavedAt: time.Now(), Data: payload}
+	assert.Nil(testutil.WithDeadlineContext(time.Second, func(ctx context.Context) {
+		assert.Nil(storage.NewPostgres(testPostgresClient).WithCompressionThreshold(0).SaveState(ctx, state))
+	}))
+	assert.Equal(storage.CodecNone, state.Codec)
+
+	s := storage.NewPostgres(testPostgresClient).WithCompressionThreshold(100)
+	assert.Nil(testutil.WithDeadlineContext(5*time.Second, func(ctx context.Context) {
+		n, err := s.Recompress(ctx, 2)
+		assert.Nil(err)
+		assert.True(n >= 1)
+
+		storedState := &storage.AidAnalyticsState{ID: state.ID}
+		assert.Nil(testPostgresDB.Select(storedState))
+		assert.Equal(storage.CodecGzip, storedState.Codec)
+
+		readState := &storage.AidAnalyticsState{AppID: state.AppID, Keyword: state.Keyword, SavedAt: state.SavedAt}
+		assert.Nil(s.GetState(ctx, readState))
+		assert.Equal(payload, readState.Data)
+
+		// nothing left to recompress
+		n, err = s.Recompress(ctx, 2)
+		assert.Nil(err)
+		assert.Equal(0, n)
+	}))
+}
@@ -0,0 +1,441 @@
+package storage_test
+
+import (
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"fmt"
+	"math/rand"
+	"testing"
+	"time"
+
+	"github.com/callstats-io/ai-decision/service/src/storage"
+	"github.com/callstats-io/go-common/log"
+	"github.com/callstats-io/go-common/postgres/migrations"
+	"github.com/callstats-io/go-common/testutil"
+	"github.com/stretchr/testify/require"
+
+	service_migrations "github.com/callstats-io/ai-decision/service/migrations"
+)
+
+// conformanceStorage defines the storage methods both backends must implement with the same semantics
+type conformanceStorage interface {
+	FetchMessageTemplates(ctx context.Context, mType string, maxVersion int32) ([]*storage.MessageTemplate, error)
+	CreateMessageTemplate(ctx context.Context, tmpl *storage.MessageTemplate) error
+	CreateMessage(ctx context.Context, msg *storage.Message) error
+	ListMessages(ctx context.Context, appID int32, mType string, minVersion, maxVersion int32, from, to *time.Time, opts ...storage.ListOption) ([]*storage.Message, error)
+	SaveState(ctx context.Context, state *storage.AidAnalyticsState) error
+	SaveStateRevision(ctx context.Context, state *storage.AidAnalyticsState, expectedRevision int32) error
+	SaveStateChunks(ctx context.Context, state *storage.AidAnalyticsState, chunks [][]byte, expectedRevision int32, force bool) error
+	GetStateChunk(ctx context.Context, stateID int32, seq int32) ([]byte, error)
+	GetState(ctx context.Context, state *storage.AidAnalyticsState) error
+	GetLatestState(ctx context.Context, appID int32, keyword string, asOf *time.Time) (*storage.AidAnalyticsState, error)
+	ListStates(ctx context.Context, appID int32, keyword string, from, to *time.Time, opts ...storage.ListOption) ([]*storage.AidAnalyticsState, error)
+	DeleteStates(ctx context.Context, appID int32, keyword string, from, to time.Time, dryRun bool) (int, error)
+	SaveCompactionPolicy(ctx context.Context, policy *storage.AidAnalyticsCompactionPolicy) error
+	GetKeyword(ctx context.Context, keyword string) (*storage.AidAnalyticsKeyword, error)
+	ListKeywords(ctx context.Context, appID int32) ([]*storage.KeywordUsage, error)
+	AcquireLease(ctx context.Context, lease *storage.AidLease, ttl time.Duration) error
+	StartRun(ctx context.Context, run *storage.AidRun) error
+	FinishRun(ctx context.Context, run *storage.AidRun) error
+	ListRuns(ctx context.Context, filter *storage.RunFilter) ([]*storage.AidRun, error)
+	ListAuditEvents(ctx context.Context, filter *storage.AuditFilter) ([]*storage.AuditEvent, error)
+}
+
+// conformanceBackends returns both storage backends, the memory storage seeded by the migrations
+func conformanceBackends(t *testing.T) map[string]conformanceStorage {
+	statements, err := service_migrations.UpStatements(log.RootLogger(), &migrations.Options{
+		RootRole: pgRootRole,
+		Meta: map[string]interface{}{
+			service_migrations.MetaKeyReadRole: pgRootRole,
+		},
+	})
+	require.Nil(t, err)
+	memory := storage.NewMemory()
+	require.Nil(t, memory.Seed(statements))
+
+	return map[string]conformanceStorage{
+		"postgres": storage.NewPostgres(testPostgresClient),
+		"memory":   memory,
+	}
+}
+
+// runConformance runs test against both backends, with an app id and name unique to the run
+func runConformance(t *testing.T, test func(t *testing.T, ctx context.Context, s conformanceStorage, appID int32, name string)) {
+	for backend, s := range conformanceBackends(t) {
+		t.Run(backend, func(t *testing.T) {
+			appID := rand.Int31n(1<<30) + 1
+			name := fmt.Sprintf("conformance-%d", rand.Int())
+			require.Nil(t, testutil.WithDeadlineContext(5*time.Second, func(ctx context.Context) {
+				test(t, ctx, s, appID, name)
+			}))
+		})
+	}
+}
+
+func TestConformanceSeededTemplates(t *testing.T) {
+	backends := conformanceBackends(t)
+	require.Nil(t, testutil.WithDeadlineContext(5*time.Second, func(ctx context.Context) {
+		for _, mType := range []string{"MidtermTrend15daysUp", "MidtermOQFluctuationImmediatelyHigh", "MidtermPrediction15daysDown"} {
+			expected, err := backends["postgres"].FetchMessageTemplates(ctx, mType, 0)
+			require.Nil(t, err)
+			templates, err := backends["memory"].FetchMessageTemplates(ctx, mType, 0)
+			require.Nil(t, err)
+			require.Len(t, templates, len(expected), mType)
+			for i, tmpl := range templates {
+				require.Equal(t, expected[i].Version, tmpl.Version, mType)
+				require.Equal(t, expected[i].Template, tmpl.Template, mType)
+			}
+		}
+
+		expected, err := backends["postgres"].GetKeyword(ctx, "latest_dates")
+		require.Nil(t, err)
+		keyword, err := backends["memory"].GetKeyword(ctx, "latest_dates")
+		require.Nil(t, err)
+		require.Equal(t, expected.ContentType, keyword.ContentType)
+		require.Equal(t, expected.MaxSize, keyword.MaxSize)
+		require.Equal(t, expected.Owner, keyword.Owner)
+	}))
+}
+
+func TestMemorySeedUnsupportedStatements(t *testing.T) {
+	for _, statement := range []string{
+		"DELETE FROM message_templates WHERE type = 'MidtermTrend15daysUp'",
+		"UPDATE aid_analytics_keywords SET max_size = 1024 WHERE keyword = 'latest_dates'",
+		"UPDATE message_templates SET template = 'x' WHERE type = 'a' AND version = 2",
+		"INSERT INTO message_templates (type, version, template) SELECT type, 9, template FROM message_templates",
+	} {
+		err := storage.NewMemory().Seed([]string{"CREATE TABLE message_templates (id SERIAL)", statement})
+		require.NotNil(t, err, statement)
+	}
+	require.Nil(t, storage.NewMemory().Seed([]string{
+		"GRANT SELECT ON message_templates TO reader; INSERT INTO messages (app_id) VALUES (1)",
+	}))
+}
+
+func TestConformanceMessages(t *testing.T) {
+	runConformance(t, func(t *testing.T, ctx context.Context, s conformanceStorage, appID int32, name string) {
+		assert := require.New(t)
+
+		tmpls := []*storage.MessageTemplate{
+			{Type: name, Version: 1, Template: "v1"},
+			{Type: name, Version: 2, Template: "v2"},
+			{Type: name + "-other", Version: 1, Template: "other"},
+		}
+		for _, tmpl := range tmpls {
+			assert.Nil(s.CreateMessageTemplate(ctx, tmpl))
+			assert.True(tmpl.ID > 0)
+		}
+		err := s.CreateMessageTemplate(ctx, &storage.MessageTemplate{Type: name, Version: 1, Template: "v1"})
+		assert.NotNil(err)
+		assert.Contains(err.Error(), "message_template_versions_idx")
+
+		templates, err := s.FetchMessageTemplates(ctx, name, 1)
+		assert.Nil(err)
+		assert.Len(templates, 1)
+		assert.Equal("v1", templates[0].Template)
+		_, err = s.FetchMessageTemplates(ctx, name+"-missing", 0)
+		assert.Equal(storage.ErrNotFound, err)
+
+		now := time.Now()
+		msgs := []*storage.Message{
+			{AppID: appID, TemplateID: tmpls[0].ID, GeneratedAt: now.Add(-2 * time.Hour), Data: []byte(`{"n":1}`)},
+			{AppID: appID, TemplateID: tmpls[1].ID, GeneratedAt: now.Add(-time.Hour), Data: []byte(`{"n":2}`)},
+			{AppID: appID, TemplateID: tmpls[2].ID, GeneratedAt: now, Data: []byte(`{"n":3}`)},
+		}
+		for _, msg := range msgs {
+			assert.Nil(s.CreateMessage(ctx, msg))
+			assert.True(msg.ID > 0)
+		}
+
+		err = s.CreateMessage(ctx, &storage.Message{AppID: appID, TemplateID: tmpls[0].ID, GeneratedAt: msgs[0].GeneratedAt, Data: []byte(`{}`)})
+		assert.Equal(storage.ErrUniqueViolation, storage.ErrorKind(err))
+		assert.Contains(err.(*storage.Error).Constraint, "message_uniqueness_idx")
+		assert.Contains(err.Error(), "message_uniqueness_idx")
+		err = s.CreateMessage(ctx, &storage.Message{AppID: appID, TemplateID: -1, GeneratedAt: now, Data: []byte(`{}`)})
+		assert.Equal(storage.ErrForeignKeyViolation, storage.ErrorKind(err))
+		assert.Equal("messages_template_id_fkey", err.(*storage.Error).Constraint)
+		assert.Contains(err.Error(), "messages_template_id_fkey")
+		err = s.CreateMessage(ctx, &storage.Message{AppID: appID, GeneratedAt: now, Data: []byte(`{}`)})
+		assert.NotNil(err)
+		assert.Contains(err.Error(), "template_id")
+		assert.Equal(storage.ErrUnknownRun, s.CreateMessage(ctx, &storage.Message{AppID: appID, TemplateID: tmpls[0].ID, GeneratedAt: now, Data: []byte(`{}`), RunID: -1}))
+
+		from, to := now.Add(-90*time.Minute), now.Add(-30*time.Minute)
+		for _, test := range []struct {
+			Description string
+			Type        string
+			MinVersion  int32
+			MaxVersion  int32
+			From, To    *time.Time
+			ExpData     []string
+		}{
+			{Description: "all", ExpData: []string{`{"n":1}`, `{"n":2}`, `{"n":3}`}},
+			{Description: "by type", Type: name, ExpData: []string{`{"n":1}`, `{"n":2}`}},
+			{Description: "by min version", Type: name, MinVersion: 2, ExpData: []string{`{"n":2}`}},
+			{Description: "by max version", MaxVersion: 1, ExpData: []string{`{"n":1}`, `{"n":3}`}},
+			{Description: "from", From: &from, ExpData: []string{`{"n":2}`, `{"n":3}`}},
+			{Description: "to", To: &to, ExpData: []string{`{"n":1}`, `{"n":2}`}},
+			{Description: "inclusive range", From: &msgs[1].GeneratedAt, To: &msgs[1].GeneratedAt, ExpData: []string{`{"n":2}`}},
+		} {
+			messages, err := s.ListMessages(ctx, appID, test.Type, test.MinVersion, test.MaxVersion, test.From, test.To)
+			assert.Nil(err, test.Description)
+			data := []string{}
+			for _, msg := range messages {
+				assert.NotNil(msg.Template, test.Description)
+				assert.Equal(msg.TemplateID, msg.Template.ID, test.Description)
+				data = append(data, string(msg.Data))
+			}
+			assert.ElementsMatch(test.ExpData, data, test.Description)
+		}
+		_, err = s.ListMessages(ctx, appID, name+"-missing", 0, 0, nil, nil)
+		assert.Equal(storage.ErrNotFound, err)
+
+		// messages are ordered by generation time, type and version
+		tied := &storage.Message{AppID: appID, TemplateID: tmpls[0].ID, GeneratedAt: msgs[2].GeneratedAt, Data: []byte(`{"n":4}`)}
+		assert.Nil(s.CreateMessage(ctx, tied))
+		ordered, err := s.ListMessages(ctx, appID, "", 0, 0, nil, nil)
+		assert.Nil(err)
+		data := []string{}
+		for _, msg := range ordered {
+			data = append(data, string(msg.Data))
+		}
+		assert.Equal([]string{`{"n":1}`, `{"n":2}`, `{"n":4}`, `{"n":3}`}, data)
+
+		// messages are listed with their templates but without data
+		messages, err := s.ListMessages(ctx, appID, name, 0, 0, nil, nil, storage.WithoutData)
+		assert.Nil(err)
+		assert.Len(messages, 3)
+		for _, msg := range messages {
+			assert.NotNil(msg.Template)
+			assert.False(msg.GeneratedAt.IsZero())
+			assert.Empty(msg.Data)
+		}
+	})
+}
+
+func TestConformanceAudit(t *testing.T) {
+	runConformance(t, func(t *testing.T, ctx context.Context, s conformanceStorage, appID int32, name string) {
+		assert := require.New(t)
+		ctx = storage.WithAudit(ctx, storage.Audit{Actor: "tester", RPC: "test", Reason: name})
+		hash := func(data string) string {
+			sum := sha256.Sum256([]byte(data))
+			return hex.EncodeToString(sum[:])
+		}
+
+		tmpl := &storage.MessageTemplate{Type: name, Version: 1, Template: "v1"}
+		assert.Nil(s.CreateMessageTemplate(ctx, tmpl))
+		msg := &storage.Message{AppID: appID, TemplateID: tmpl.ID, GeneratedAt: time.Now(), Data: []byte(`{"n":1}`)}
+		assert.Nil(s.CreateMessage(ctx, msg))
+		savedAt := time.Now().Truncate(time.Millisecond)
+		for _, data := range []string{`{"v":1}`, `{"v":2}`} {
+			assert.Nil(s.SaveState(ctx, &storage.AidAnalyticsState{AppID: appID, Keyword: name, SavedAt: savedAt, Data: []byte(data)}))
+		}
+		// failed mutations are not audited
+		state := &storage.AidAnalyticsState{AppID: appID, Keyword: name, SavedAt: savedAt, Data: []byte(`{"v":3}`)}
+		assert.Equal(storage.ErrRevisionMismatch, s.SaveStateRevision(ctx, state, 1))
+		deleted, err := s.DeleteStates(ctx, appID, name, savedAt, savedAt, false)
+		assert.Nil(err)
+		assert.Equal(1, deleted)
+
+		events, err := s.ListAuditEvents(ctx, &storage.AuditFilter{AppID: appID})
+		assert.Nil(err)
+		stateTarget := name + "@" + savedAt.UTC().Format(time.RFC3339Nano)
+		expected := []storage.AuditEvent{
+			{Action: storage.AuditStateDelete, Target: stateTarget, BeforeHash: hash(`{"v":2}`)},
+			{Action: storage.AuditStateSave, Target: stateTarget, BeforeHash: hash(`{"v":1}`), AfterHash: hash(`{"v":2}`)},
+			{Action: storage.AuditStateSave, Target: stateTarget, AfterHash: hash(`{"v":1}`)},
+			{Action: storage.AuditMessageCreate, Target: fmt.Sprint(msg.ID), AfterHash: hash(`{"n":1}`)},
+		}
+		assert.Len(events, len(expected))
+		for i, exp := range expected {
+			assert.Equal(exp.Action, events[i].Action, i)
+			assert.Equal(exp.Target, events[i].Target, i)
+			assert.Equal(exp.BeforeHash, events[i].BeforeHash, i)
+			assert.Equal(exp.AfterHash, events[i].AfterHash, i)
+			assert.Equal(appID, events[i].AppID, i)
+			assert.Equal(int32(1), events[i].Count, i)
+			assert.Equal("tester", events[i].Actor, i)
+			assert.Equal("test", events[i].RPC, i)
+			assert.Equal(name, events[i].Reason, i)
+		}
+
+		// templates are audited without an app
+		events, err = s.ListAuditEvents(ctx, &storage.AuditFilter{Action: storage.AuditTemplateCreate, Target: name + ":1", Limit: 1})
+		assert.Nil(err)
+		assert.Len(events, 1)
+		assert.Equal(int32(0), events[0].AppID)
+		assert.Equal(hash("v1"), events[0].AfterHash)
+
+		_, err = s.ListAuditEvents(ctx, &storage.AuditFilter{AppID: appID, Actor: "someone else"})
+		assert.Equal(storage.ErrNotFound, err)
+
+		// compaction policies are audited by keyword without an app, with the hashes of their rules
+		policy := &storage.AidAnalyticsCompactionPolicy{Keyword: name, KeepLast: 7}
+		assert.Nil(s.SaveCompactionPolicy(ctx, policy))
+		policy.KeepLast = 3
+		assert.Nil(s.SaveCompactionPolicy(ctx, policy))
+		events, err = s.ListAuditEvents(ctx, &storage.AuditFilter{Action: storage.AuditCompactionPolicySave, Target: name})
+		assert.Nil(err)
+		assert.Len(events, 2)
+		assert.Equal(int32(0), events[0].AppID)
+		assert.Equal(events[1].AfterHash, events[0].BeforeHash)
+		assert.NotEqual(events[0].BeforeHash, events[0].AfterHash)
+		assert.Equal("", events[1].BeforeHash)
+
+		// mutations audited without an actor fail and are not made
+		anonymous := storage.WithAudit(ctx, storage.Audit{RPC: "test"})
+		state = &storage.AidAnalyticsState{AppID: appID, Keyword: name, SavedAt: savedAt, Data: []byte(`{"v":4}`)}
+		assert.Equal(storage.ErrNoAuditActor, s.SaveState(anonymous, state))
+		assert.Equal(storage.ErrNotFound, s.GetState(ctx, &storage.AidAnalyticsState{AppID: appID, Keyword: name, SavedAt: savedAt}))
+		assert.Equal(storage.ErrNoAuditActor, s.CreateMessage(anonymous, &storage.Message{
+			AppID: appID, TemplateID: tmpl.ID, GeneratedAt: time.Now(), Data: []byte(`{"n":2}`),
+		}))
+		assert.Equal(storage.ErrNoAuditActor, s.SaveCompactionPolicy(anonymous, policy))
+		_, err = s.DeleteStates(anonymous, appID, name, savedAt, savedAt, false)
+		assert.Equal(storage.ErrNoAuditActor, err)
+		events, err = s.ListAuditEvents(ctx, &storage.AuditFilter{AppID: appID})
+		assert.Nil(err)
+		assert.Len(events, len(expected))
+	})
+}
+
+func TestConformanceStates(t *testing.T) {
+	runConformance(t, func(t *testing.T, ctx context.Context, s conformanceStorage, appID int32, name string) {
+		assert := require.New(t)
+
+		now := time.Now()
+		state := &storage.AidAnalyticsState{AppID: appID, Keyword: name, SavedAt: now, Data: []byte(`{"v":1}`)}
+		assert.Nil(s.SaveState(ctx, state))
+		assert.Equal(int32(1), state.Revision)
+
+		// saving at the same time replaces the state and increments the revision
+		state = &storage.AidAnalyticsState{AppID: appID, Keyword: name, SavedAt: now, Data: []byte(`{"v":2}`)}
+		assert.Nil(s.SaveState(ctx, state))
+		assert.Equal(int32(2), state.Revision)
+		assert.Equal(storage.ErrRevisionMismatch, s.SaveStateRevision(ctx, state, 1))
+		assert.Equal(storage.ErrRevisionMismatch, s.SaveStateRevision(ctx, state, 0))
+		assert.Nil(s.SaveStateRevision(ctx, state, 2))
+		assert.Equal(int32(3), state.Revision)
+		missing := &storage.AidAnalyticsState{AppID: appID, Keyword: name, SavedAt: now.Add(time.Minute), Data: []byte(`{}`)}
+		assert.Equal(storage.ErrRevisionMismatch, s.SaveStateRevision(ctx, missing, 1))
+
+		got := &storage.AidAnalyticsState{AppID: appID, Keyword: name, SavedAt: now}
+		assert.Nil(s.GetState(ctx, got))
+		assert.Equal(state.ID, got.ID)
+		assert.Equal(`{"v":2}`, string(got.Data))
+		assert.Equal(int32(3), got.Revision)
+		assert.Equal(storage.ErrNotFound, s.GetState(ctx, missing))
+
+		// chunked states replace the data of the state with chunks
+		chunked := &storage.AidAnalyticsState{AppID: appID, Keyword: name, SavedAt: now.Add(-time.Hour), Data: []byte(`ignored`)}
+		assert.Nil(s.SaveStateChunks(ctx, chunked, [][]byte{[]byte("ab"), []byte("cd")}, 0, true))
+		assert.Equal(int32(2), chunked.ChunkCount)
+		assert.Empty(chunked.Data)
+		chunk, err := s.GetStateChunk(ctx, chunked.ID, 1)
+		assert.Nil(err)
+		assert.Equal("cd", string(chunk))
+		_, err = s.GetStateChunk(ctx, chunked.ID, 2)
+		assert.Equal(storage.ErrNotFound, err)
+
+		older := &storage.AidAnalyticsState{AppID: appID, Keyword: name, SavedAt: now.Add(-2 * time.Hour), Data: []byte(`{"v":0}`)}
+		assert.Nil(s.SaveState(ctx, older))
+		other := &storage.AidAnalyticsState{AppID: appID, Keyword: name + "-other", SavedAt: now, Data: []byte(`{}`)}
+		assert.Nil(s.SaveState(ctx, other))
+
+		latest, err := s.GetLatestState(ctx, appID, name, nil)
+		assert.Nil(err)
+		assert.Equal(state.ID, latest.ID)
+		asOf := now.Add(-time.Minute)
+		latest, err = s.GetLatestState(ctx, appID, name, &asOf)
+		assert.Nil(err)
+		assert.Equal(chunked.ID, latest.ID)
+		_, err = s.GetLatestState(ctx, appID, name+"-missing", nil)
+		assert.Equal(storage.ErrNotFound, err)
+
+		from := now.Add(-90 * time.Minute)
+		states, err := s.ListStates(ctx, appID, name, &from, nil)
+		assert.Nil(err)
+		assert.Len(states, 2)
+		// states are ordered by generation time and keyword
+		states, err = s.ListStates(ctx, appID, "", nil, nil)
+		assert.Nil(err)
+		assert.Len(states, 4)
+		for i, exp := range []*storage.AidAnalyticsState{older, chunked, state, other} {
+			assert.Equal(exp.ID, states[i].ID)
+		}
+		states, err = s.ListStates(ctx, appID, name, &from, nil, storage.WithoutData)
+		assert.Nil(err)
+		assert.Len(states, 2)
+		for _, st := range states {
+			assert.Equal(name, st.Keyword)
+			assert.False(st.SavedAt.IsZero())
+			assert.Empty(st.Data)
+		}
+
+		usages, err := s.ListKeywords(ctx, appID)
+		assert.Nil(err)
+		assert.Len(usages, 2)
+		assert.Equal(name, usages[0].Keyword)
+		assert.Equal(int32(3), usages[0].StateCount)
+		assert.True(usages[0].LatestSavedAt.Equal(state.SavedAt))
+		assert.False(usages[0].Registered)
+		assert.Equal(name+"-other", usages[1].Keyword)
+
+		// both ends of the range are inclusive
+		deleted, err := s.DeleteStates(ctx, appID, name, older.SavedAt, chunked.SavedAt, true)
+		assert.Nil(err)
+		assert.Equal(2, deleted)
+		deleted, err = s.DeleteStates(ctx, appID, name, older.SavedAt, chunked.SavedAt, false)
+		assert.Nil(err)
+		assert.Equal(2, deleted)
+		_, err = s.GetStateChunk(ctx, chunked.ID, 0)
+		assert.Equal(storage.ErrNotFound, err)
+		states, err = s.ListStates(ctx, appID, name, nil, nil)
+		assert.Nil(err)
+		assert.Len(states, 1)
+	})
+}
+
+func TestConformanceLeasesAndRuns(t *testing.T) {
+	runConformance(t, func(t *testing.T, ctx context.Context, s conformanceStorage, appID int32, name string) {
+		assert := require.New(t)
+
+		lease := &storage.AidLease{AppID: appID, Name: name, Holder: "worker-1"}
+		assert.Nil(s.AcquireLease(ctx, lease, time.Minute))
+		assert.Equal(storage.ErrLeaseHeld, s.AcquireLease(ctx, &storage.AidLease{AppID: appID, Name: name, Holder: "worker-2"}, time.Minute))
+		state := &storage.AidAnalyticsState{AppID: appID, Keyword: name, SavedAt: time.Now(), Data: []byte(`{}`), FencingToken: lease.FencingToken}
+		assert.Nil(s.SaveState(ctx, state))
+		state.FencingToken = lease.FencingToken + 1000
+		assert.Equal(storage.ErrLeaseLost, s.SaveState(ctx, state))
+
+		first := &storage.AidRun{AppID: appID, Pipeline: name}
+		assert.Nil(s.StartRun(ctx, first))
+		second := &storage.AidRun{AppID: appID, Pipeline: name}
+		assert.Nil(s.StartRun(ctx, second))
+		assert.Equal(storage.RunStatusRunning, second.Status)
+		assert.Nil(s.SaveState(ctx, &storage.AidAnalyticsState{AppID: appID, Keyword: name, SavedAt: time.Now(), Data: []byte(`{}`), RunID: first.ID}))
+		assert.Equal(storage.ErrUnknownRun, s.SaveState(ctx, &storage.AidAnalyticsState{AppID: appID, Keyword: name, SavedAt: time.Now(), Data: []byte(`{}`), RunID: -1}))
+
+		first.Status = storage.RunStatusSucceeded
+		first.Counters = map[string]int64{"states": 1}
+		assert.Nil(s.FinishRun(ctx, first))
+		assert.False(first.FinishedAt.IsZero())
+		assert.Equal(storage.ErrRunFinished, s.FinishRun(ctx, first))
+		assert.Equal(storage.ErrNotFound, s.FinishRun(ctx, &storage.AidRun{ID: -1, Status: storage.RunStatusFailed}))
+
+		runs, err := s.ListRuns(ctx, &storage.RunFilter{AppID: appID})
+		assert.Nil(err)
+		assert.Len(runs, 2)
+		assert.Equal(second.ID, runs[0].ID)
+		assert.Equal(first.ID, runs[1].ID)
+		assert.Equal(int32(1), runs[1].StateCount)
+		assert.Equal(int64(1), runs[1].Counters["states"])
+		runs, err = s.ListRuns(ctx, &storage.RunFilter{AppID: appID, WithoutMessages: true})
+		assert.Nil(err)
+		assert.Len(runs, 1)
+		assert.Equal(first.ID, runs[0].ID)
+		_, err = s.ListRuns(ctx, &storage.RunFilter{AppID: appID, Status: storage.RunStatusFailed})
+		assert.Equal(storage.ErrNotFound, err)
+	})
+}
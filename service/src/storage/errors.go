@@ -1,8 +1,143 @@
 package storage
 
-import "errors"
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"net"
+	"strings"
+
+	"github.com/go-pg/pg"
+)
 
 // Errors
 var (
-	ErrNotFound = errors.New("not found")
+	ErrNotFound         = errors.New("not found")
+	ErrRevisionMismatch = errors.New("revision mismatch")
+	ErrLeaseHeld        = errors.New("lease is held by another holder")
+	ErrLeaseLost        = errors.New("lease expired or acquired by another holder")
+	ErrUnknownRun       = errors.New("unknown run")
+	ErrRunFinished      = errors.New("run is already finished")
+	ErrNoAuditActor     = errors.New("mutation without an audit actor")
+)
+
+// Kinds of database errors, returned as the Kind of an *Error, see ErrorKind
+var (
+	ErrUniqueViolation      = errors.New("unique violation")
+	ErrForeignKeyViolation  = errors.New("foreign key violation")
+	ErrSerializationFailure = errors.New("serialization failure")
+	ErrTimeout              = errors.New("timeout")
+	ErrUnavailable          = errors.New("unavailable")
+)
+
+// postgres error codes and code prefixes of error classes, see https://www.postgresql.org/docs/10/static/errcodes-appendix.html
+const (
+	uniqueViolation       = "23505"
+	foreignKeyViolation   = "23503"
+	serializationFailure  = "40001"
+	deadlockDetected      = "40P01"
+	queryCanceled         = "57014" // also statement_timeout
+	lockNotAvailable      = "55P03" // also lock_timeout
+	connectionException   = "08"
+	insufficientResources = "53"  // e.g. too_many_connections
+	operatorIntervention  = "57P" // e.g. admin_shutdown or cannot_connect_now
+)
+
+// messages of go-pg connection pool errors, the errors are internal to go-pg
+const (
+	poolTimeoutMessage    = "pg: connection pool timeout"
+	databaseClosedMessage = "pg: database is closed"
 )
+
+// Error is a classified database error. The message is the message of the database error.
+type Error struct {
+	// Kind is the kind of the error, e.g. ErrUniqueViolation
+	Kind error
+	// Constraint is the name of the violated constraint of constraint violations
+	Constraint string
+	// Err is the database error, nil for errors of the in-memory storage
+	Err error
+}
+
+func (e *Error) Error() string {
+	if e.Err == nil {
+		return fmt.Sprintf("%s of %s", e.Kind, e.Constraint)
+	}
+	return e.Err.Error()
+}
+
+// Temporary returns true if the operation may succeed when retried
+func (e *Error) Temporary() bool {
+	return e.Kind == ErrSerializationFailure || e.Kind == ErrTimeout || e.Kind == ErrUnavailable
+}
+
+// ErrorKind returns the kind of a classified database error, otherwise the error itself.
+// Storage errors can then be compared alike, e.g. ErrorKind(err) == ErrUniqueViolation or ErrorKind(err) == ErrNotFound.
+func ErrorKind(err error) error {
+	if e, ok := err.(*Error); ok {
+		return e.Kind
+	}
+	return err
+}
+
+// classify returns database errors of a known kind as *Error, all other errors are returned as is
+func classify(err error) error {
+	switch err {
+	case nil:
+		return nil
+	case context.DeadlineExceeded:
+		return &Error{Kind: ErrTimeout, Err: err}
+	case io.EOF, io.ErrUnexpectedEOF:
+		return &Error{Kind: ErrUnavailable, Err: err}
+	}
+	switch e := err.(type) {
+	case *Error:
+		return err
+	case pg.Error:
+		code := e.Field('C')
+		switch {
+		case code == uniqueViolation:
+			return &Error{Kind: ErrUniqueViolation, Constraint: e.Field('n'), Err: err}
+		case code == foreignKeyViolation:
+			return &Error{Kind: ErrForeignKeyViolation, Constraint: e.Field('n'), Err: err}
+		case code == serializationFailure || code == deadlockDetected:
+			return &Error{Kind: ErrSerializationFailure, Err: err}
+		case code == queryCanceled || code == lockNotAvailable:
+			return &Error{Kind: ErrTimeout, Err: err}
+		case strings.HasPrefix(code, connectionException) || strings.HasPrefix(code, insufficientResources) ||
+			strings.HasPrefix(code, operatorIntervention):
+			return &Error{Kind: ErrUnavailable, Err: err}
+		}
+	case net.Error:
+		if e.Timeout() {
+			return &Error{Kind: ErrTimeout, Err: err}
+		}
+		return &Error{Kind: ErrUnavailable, Err: err}
+	}
+	switch err.Error() {
+	case poolTimeoutMessage:
+		return &Error{Kind: ErrTimeout, Err: err}
+	case databaseClosedMessage:
+		return &Error{Kind: ErrUnavailable, Err: err}
+	}
+	return err
+}
+
+// errConnect is the error of failing to get a database connection, the cause is logged
+var errConnect = errors.New("failed to connect to database")
+
+// connectionError returns the error of failing to get a database connection with the kind of its cause,
+// ErrTimeout if the cause is a timeout and ErrUnavailable otherwise
+func connectionError(cause error) error {
+	if ErrorKind(classify(cause)) == ErrTimeout {
+		return &Error{Kind: ErrTimeout, Err: errConnect}
+	}
+	return &Error{Kind: ErrUnavailable, Err: errConnect}
+}
+
+// isRunForeignKeyViolation returns true if err is a postgres violation of a run_id foreign key
+func isRunForeignKeyViolation(err error) bool {
+	pgErr, ok := err.(pg.Error)
+	return ok && pgErr.Field('C') == foreignKeyViolation && strings.HasSuffix(pgErr.Field('n'), "_run_id_fkey")
+}
@@ -0,0 +1,55 @@
+package storage_test
+
+import (
+	"context"
+	"fmt"
+	"math/rand"
+	"testing"
+	"time"
+
+	"github.com/callstats-io/ai-decision/service/src/storage"
+	"github.com/callstats-io/go-common/postgres"
+	"github.com/callstats-io/go-common/testutil"
+	"github.com/stretchr/testify/require"
+)
+
+type timeoutConnectionClient struct{}
+
+func (c *timeoutConnectionClient) DB(ctx context.Context) (*postgres.DB, error) {
+	return nil, context.DeadlineExceeded
+}
+
+func (c *timeoutConnectionClient) Close() {}
+
+func TestErrorKinds(t *testing.T) {
+	assert := require.New(t)
+	s := storage.NewPostgres(testPostgresClient)
+	mType := fmt.Sprintf("errors-%d", rand.Int())
+
+	assert.Nil(testutil.WithDeadlineContext(5*time.Second, func(ctx context.Context) {
+		assert.Nil(s.CreateMessageTemplate(ctx, &storage.MessageTemplate{Type: mType, Version: 1, Template: "v1"}))
+		err := s.CreateMessageTemplate(ctx, &storage.MessageTemplate{Type: mType, Version: 1, Template: "v1"})
+		assert.Equal(storage.ErrUniqueViolation, storage.ErrorKind(err))
+		assert.Equal("message_template_versions_idx", err.(*storage.Error).Constraint)
+		assert.False(err.(*storage.Error).Temporary())
+
+		err = s.CreateMessage(ctx, &storage.Message{AppID: 123, TemplateID: -1, GeneratedAt: time.Now(), Data: []byte(`{}`)})
+		assert.Equal(storage.ErrForeignKeyViolation, storage.ErrorKind(err))
+		assert.Equal("messages_template_id_fkey", err.(*storage.Error).Constraint)
+
+		_, err = storage.NewPostgres(&badConnectionClient{}).GetKeyword(ctx, mType)
+		assert.EqualError(err, "failed to connect to database")
+		assert.Equal(storage.ErrUnavailable, storage.ErrorKind(err))
+		assert.True(err.(*storage.Error).Temporary())
+
+		_, err = storage.NewPostgres(&timeoutConnectionClient{}).GetKeyword(ctx, mType)
+		assert.Equal(storage.ErrTimeout, storage.ErrorKind(err))
+
+		_, err = storage.NewPostgres(testPostgresClosedConnClient).GetKeyword(ctx, mType)
+		assert.Equal(storage.ErrUnavailable, storage.ErrorKind(err))
+
+		// unclassified errors are their own kind
+		_, err = s.GetKeyword(ctx, mType)
+		assert.Equal(storage.ErrNotFound, storage.ErrorKind(err))
+	}))
+}
@@ -0,0 +1,34 @@
+package storage
+
+import (
+	"context"
+	"time"
+)
+
+// StateFreshness fetches the newest saved_at of the states of every app and keyword saved after since, if provided,
+// so that only the partitions of recent states are scanned. Returns ErrNotFound if there are no such states.
+func (s *Postgres) StateFreshness(ctx context.Context, since *time.Time) ([]*StateFreshness, error) {
+	db, err := s.readDB(ctx, 0)
+	if err != nil {
+		return nil, err
+	}
+
+	var freshness []*StateFreshness
+	// a literal bound, so that partitions of older states are excluded when planning
+	where := ""
+	if since != nil {
+		where = "WHERE saved_at > ?"
+	}
+	if _, err := db.Query(&freshness, `
+		SELECT app_id, keyword, max(saved_at) AS latest_saved_at
+		FROM aid_analytics_states
+		`+where+`
+		GROUP BY app_id, keyword
+		ORDER BY app_id, keyword`, since); err != nil {
+		return nil, classify(err)
+	}
+	if len(freshness) == 0 {
+		return nil, ErrNotFound
+	}
+	return freshness, nil
+}
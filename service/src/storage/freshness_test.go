@@ -0,0 +1,54 @@
+package storage_test
+
+import (
+	"context"
+	"fmt"
+	"math/rand"
+	"testing"
+	"time"
+
+	"github.com/callstats-io/ai-decision/service/src/storage"
+	"github.com/callstats-io/go-common/testutil"
+	"github.com/stretchr/testify/require"
+)
+
+func TestStateFreshness(t *testing.T) {
+	assert := require.New(t)
+	s := storage.NewPostgres(testPostgresClient)
+	appID := rand.Int31n(100000) + 200000
+	keyword := fmt.Sprintf("kw-freshness-%d", rand.Int())
+
+	assert.Nil(testutil.WithDeadlineContext(time.Second, func(ctx context.Context) {
+		for i := 0; i < 3; i++ {
+			state := &storage.AidAnalyticsState{AppID: appID, Keyword: keyword, SavedAt: time.Unix(int64(1000+i), 0), Data: []byte(`{}`)}
+			assert.Nil(s.SaveState(ctx, state))
+		}
+
+		freshness, err := s.StateFreshness(ctx, nil)
+		assert.Nil(err)
+		found := false
+		for _, f := range freshness {
+			if f.AppID == appID && f.Keyword == keyword {
+				found = true
+				assert.Equal(time.Unix(1002, 0).UTC(), f.LatestSavedAt.UTC())
+			}
+		}
+		assert.True(found)
+
+		// only states saved after since are scanned
+		since := time.Unix(1001, 0)
+		freshness, err = s.StateFreshness(ctx, &since)
+		assert.Nil(err)
+		for _, f := range freshness {
+			assert.True(f.LatestSavedAt.After(since))
+		}
+		since = time.Unix(1002, 0)
+		freshness, err = s.StateFreshness(ctx, &since)
+		if err != storage.ErrNotFound {
+			assert.Nil(err)
+		}
+		for _, f := range freshness {
+			assert.False(f.AppID == appID && f.Keyword == keyword)
+		}
+	}))
+}
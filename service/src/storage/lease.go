@@ -0,0 +1,89 @@
+package storage
+
+import (
+	"context"
+	"time"
+
+	"github.com/callstats-io/go-common/postgres"
+	"github.com/go-pg/pg/orm"
+)
+
+// AcquireLease acquires a lease for its holder with a new fencing token.
+// Returns ErrLeaseHeld if the lease is held by another holder and has not expired.
+func (s *Postgres) AcquireLease(ctx context.Context, lease *AidLease, ttl time.Duration) error {
+	db, err := s.db(ctx)
+	if err != nil {
+		return err
+	}
+
+	if _, err := db.QueryOne(lease, `
+		INSERT INTO aid_leases (app_id, name, holder, fencing_token, expires_at)
+		VALUES (?app_id, ?name, ?holder, nextval('aid_lease_fencing_tokens'), now() + ? * interval '1 millisecond')
+		ON CONFLICT (app_id, name) DO UPDATE
+		SET holder = EXCLUDED.holder, fencing_token = EXCLUDED.fencing_token, expires_at = EXCLUDED.expires_at
+		WHERE aid_leases.expires_at <= now() OR aid_leases.holder = EXCLUDED.holder
+		RETURNING *`, ttl.Nanoseconds()/int64(time.Millisecond), lease); err != nil {
+		if err == postgres.ErrNoRows {
+			return ErrLeaseHeld
+		}
+		return classify(err)
+	}
+	return nil
+}
+
+// RenewLease extends a lease held with its fencing token.
+// Returns ErrLeaseLost if the lease expired or was acquired by another holder.
+func (s *Postgres) RenewLease(ctx context.Context, lease *AidLease, ttl time.Duration) error {
+	db, err := s.db(ctx)
+	if err != nil {
+		return err
+	}
+
+	if _, err := db.QueryOne(lease, `
+		UPDATE aid_leases SET expires_at = now() + ? * interval '1 millisecond'
+		WHERE app_id = ?app_id AND name = ?name AND holder = ?holder AND fencing_token = ?fencing_token
+		AND expires_at > now()
+		RETURNING *`, ttl.Nanoseconds()/int64(time.Millisecond), lease); err != nil {
+		if err == postgres.ErrNoRows {
+			return ErrLeaseLost
+		}
+		return classify(err)
+	}
+	return nil
+}
+
+// ReleaseLease releases a lease held with its fencing token.
+// Returns ErrLeaseLost if the lease was acquired by another holder.
+func (s *Postgres) ReleaseLease(ctx context.Context, lease *AidLease) error {
+	db, err := s.db(ctx)
+	if err != nil {
+		return err
+	}
+
+	res, err := db.Model(lease).
+		Where("app_id = ?app_id AND name = ?name AND holder = ?holder AND fencing_token = ?fencing_token").
+		Delete()
+	if err != nil {
+		return classify(err)
+	}
+	if res.RowsAffected() == 0 {
+		return ErrLeaseLost
+	}
+	return nil
+}
+
+// checkFencingToken returns ErrLeaseLost unless the fencing token is of an unexpired lease of the app.
+// The lease is locked until the end of the transaction so it cannot be acquired by another holder in between.
+func checkFencingToken(db orm.DB, appID int32, fencingToken int64) error {
+	lease := &AidLease{}
+	if err := db.Model(lease).
+		Where("app_id = ? AND fencing_token = ? AND expires_at > now()", appID, fencingToken).
+		For("SHARE").
+		Select(); err != nil {
+		if err == postgres.ErrNoRows {
+			return ErrLeaseLost
+		}
+		return err
+	}
+	return nil
+}
@@ -0,0 +1,50 @@
+package storage_test
+
+import (
+	"context"
+	"fmt"
+	"math/rand"
+	"testing"
+	"time"
+
+	"github.com/callstats-io/ai-decision/service/src/storage"
+	"github.com/callstats-io/go-common/testutil"
+	"github.com/stretchr/testify/require"
+)
+
+func TestLeases(t *testing.T) {
+	assert := require.New(t)
+	s := storage.NewPostgres(testPostgresClient)
+	name := fmt.Sprintf("lease-%d", rand.Int())
+
+	assert.Nil(testutil.WithDeadlineContext(5*time.Second, func(ctx context.Context) {
+		lease := &storage.AidLease{AppID: 123, Name: name, Holder: "worker-1"}
+		assert.Nil(s.AcquireLease(ctx, lease, time.Minute))
+		assert.True(lease.FencingToken > 0)
+		assert.True(lease.ExpiresAt.After(time.Now()))
+
+		// held by another holder
+		other := &storage.AidLease{AppID: 123, Name: name, Holder: "worker-2"}
+		assert.Equal(storage.ErrLeaseHeld, s.AcquireLease(ctx, other, time.Minute))
+
+		// saves with the fencing token succeed while the lease is held
+		state := &storage.AidAnalyticsState{AppID: 123, Keyword: name, SavedAt: time.Now(), Data: []byte(`{}`), FencingToken: lease.FencingToken}
+		assert.Nil(s.SaveState(ctx, state))
+
+		renewed := &storage.AidLease{AppID: 123, Name: name, Holder: "worker-1", FencingToken: lease.FencingToken}
+		assert.Nil(s.RenewLease(ctx, renewed, time.Millisecond))
+		assert.Equal(lease.FencingToken, renewed.FencingToken)
+		time.Sleep(50 * time.Millisecond)
+
+		// expired leases can neither be renewed nor saved with, but acquired by others with a greater token
+		assert.Equal(storage.ErrLeaseLost, s.RenewLease(ctx, renewed, time.Minute))
+		assert.Equal(storage.ErrLeaseLost, s.SaveState(ctx, state))
+		assert.Nil(s.AcquireLease(ctx, other, time.Minute))
+		assert.True(other.FencingToken > lease.FencingToken)
+		assert.Equal(storage.ErrLeaseLost, s.SaveState(ctx, state))
+
+		assert.Equal(storage.ErrLeaseLost, s.ReleaseLease(ctx, lease))
+		assert.Nil(s.ReleaseLease(ctx, other))
+		assert.Nil(s.AcquireLease(ctx, lease, time.Minute))
+	}))
+}
@@ -0,0 +1,569 @@
+package storage
+
+import (
+	"context"
+	"fmt"
+	"sort"
+	"sync"
+	"time"
+)
+
+// Constraint violations of the in-memory storage, classified like the postgres errors of the same constraints
+var (
+	errDuplicateTemplate = &Error{Kind: ErrUniqueViolation, Constraint: "message_template_versions_idx"}
+	errDuplicateMessage  = &Error{Kind: ErrUniqueViolation, Constraint: "message_uniqueness_idx"}
+	errDuplicateKeyword  = &Error{Kind: ErrUniqueViolation, Constraint: "aid_analytics_keywords_pkey"}
+	errUnknownTemplate   = &Error{Kind: ErrForeignKeyViolation, Constraint: "messages_template_id_fkey"}
+)
+
+// errNotNull returns the not-null violation of a column
+func errNotNull(column string) error {
+	return fmt.Errorf("%s: cannot be null", column)
+}
+
+// messageKey identifies a message by the columns of message_uniqueness_idx
+type messageKey struct {
+	appID      int32
+	generated  int64
+	templateID int32
+}
+
+// stateKey identifies a state by the columns of aid_analytics_states_keyword_idx
+type stateKey struct {
+	appID   int32
+	saved   int64
+	keyword string
+}
+
+// leaseKey identifies a lease by its primary key
+type leaseKey struct {
+	appID int32
+	name  string
+}
+
+// Memory defines an in-memory storage with the same uniqueness, ordering and filtering semantics as Postgres.
+// Its contents are lost on restart, it is meant for running the service locally and for tests.
+type Memory struct {
+	lock sync.Mutex
+
+	templates []*MessageTemplate
+	messages  []*Message
+	states    []*AidAnalyticsState
+	chunks    map[int32][][]byte
+	keywords  map[string]*AidAnalyticsKeyword
+	policies  map[string]*AidAnalyticsCompactionPolicy
+	leases    map[leaseKey]*AidLease
+	runs      []*AidRun
+	audit     []*AuditEvent
+
+	// last used ids, like the postgres serial sequences
+	templateSeq     int32
+	messageSeq      int32
+	stateSeq        int32
+	runSeq          int32
+	fencingTokenSeq int64
+	auditSeq        int64
+}
+
+// NewMemory returns a new empty in-memory storage, see Seed
+func NewMemory() *Memory {
+	return &Memory{
+		chunks:   map[int32][][]byte{},
+		keywords: map[string]*AidAnalyticsKeyword{},
+		policies: map[string]*AidAnalyticsCompactionPolicy{},
+		leases:   map[leaseKey]*AidLease{},
+	}
+}
+
+// dbTime returns t at the microsecond precision of postgres timestamps
+func dbTime(t time.Time) time.Time {
+	return t.Truncate(time.Microsecond)
+}
+
+func copyBytes(data []byte) []byte {
+	if data == nil {
+		return nil
+	}
+	return append([]byte{}, data...)
+}
+
+// FetchMessageTemplates returns all message templates matching to a given type up to the specified version.
+// If maxVersion is zero, all versions are returned.
+func (s *Memory) FetchMessageTemplates(ctx context.Context, mType string, maxVersion int32) ([]*MessageTemplate, error) {
+	s.lock.Lock()
+	defer s.lock.Unlock()
+
+	templates := []*MessageTemplate{}
+	for _, t := range s.templates {
+		if t.Type == mType && (maxVersion <= 0 || t.Version <= maxVersion) {
+			tmpl := *t
+			templates = append(templates, &tmpl)
+		}
+	}
+	if len(templates) == 0 {
+		return nil, ErrNotFound
+	}
+	return templates, nil
+}
+
+// CreateMessageTemplate adds a new message template and audits it
+func (s *Memory) CreateMessageTemplate(ctx context.Context, tmpl *MessageTemplate) error {
+	if err := checkAudit(ctx); err != nil {
+		return err
+	}
+	s.lock.Lock()
+	defer s.lock.Unlock()
+
+	if err := s.insertTemplate(tmpl); err != nil {
+		return err
+	}
+	event := newAuditEvent(ctx, 0, AuditTemplateCreate, templateTarget(tmpl))
+	event.AfterHash = auditHash([]byte(tmpl.Template))
+	s.insertAuditEvents(event)
+	return nil
+}
+
+func (s *Memory) insertTemplate(tmpl *MessageTemplate) error {
+	switch {
+	case tmpl.Type == "":
+		return errNotNull("type")
+	case tmpl.Version == 0:
+		return errNotNull("version")
+	case tmpl.Template == "":
+		return errNotNull("template")
+	}
+	for _, t := range s.templates {
+		if t.Type == tmpl.Type && t.Version == tmpl.Version {
+			return errDuplicateTemplate
+		}
+	}
+
+	s.templateSeq++
+	tmpl.ID = s.templateSeq
+	if tmpl.CreatedAt.IsZero() {
+		tmpl.CreatedAt = dbTime(time.Now())
+	}
+	stored := *tmpl
+	s.templates = append(s.templates, &stored)
+	return nil
+}
+
+func (s *Memory) template(id int32) *MessageTemplate {
+	for _, t := range s.templates {
+		if t.ID == id {
+			return t
+		}
+	}
+	return nil
+}
+
+// CreateMessage adds a new message and audits it. The message validation is expected to be performed before calling this function.
+// Returns ErrUnknownRun if the message is tagged with a run that does not exist.
+func (s *Memory) CreateMessage(ctx context.Context, msg *Message) error {
+	if err := checkAudit(ctx); err != nil {
+		return err
+	}
+	s.lock.Lock()
+	defer s.lock.Unlock()
+
+	switch {
+	case msg.AppID == 0:
+		return errNotNull("app_id")
+	case msg.TemplateID == 0:
+		return errNotNull("template_id")
+	case msg.GeneratedAt.IsZero():
+		return errNotNull("generated_at")
+	case len(msg.Data) == 0:
+		return errNotNull("data")
+	}
+	if s.template(msg.TemplateID) == nil {
+		return errUnknownTemplate
+	}
+	if msg.RunID != 0 && s.run(msg.RunID) == nil {
+		return ErrUnknownRun
+	}
+	key := messageKey{appID: msg.AppID, generated: dbTime(msg.GeneratedAt).UnixNano(), templateID: msg.TemplateID}
+	for _, m := range s.messages {
+		if (messageKey{appID: m.AppID, generated: m.GeneratedAt.UnixNano(), templateID: m.TemplateID}) == key {
+			return errDuplicateMessage
+		}
+	}
+
+	s.messageSeq++
+	msg.ID = s.messageSeq
+	msg.GeneratedAt = dbTime(msg.GeneratedAt)
+	msg.Codec = CodecNone
+	stored := *msg
+	stored.Template = nil
+	stored.Data = copyBytes(msg.Data)
+	s.messages = append(s.messages, &stored)
+
+	event := newAuditEvent(ctx, msg.AppID, AuditMessageCreate, messageTarget(msg.ID))
+	event.AfterHash = auditHash(msg.Data)
+	s.insertAuditEvents(event)
+	return nil
+}
+
+// ListMessages fetches all message by app id.
+// If message type is provided, all messages must additionally have the type of template
+// If minVersion and/or maxVersion are provided, all messages must additionally be within the specified range (0 = beginning/end)
+// If from and/or to are provided, all messages must additionally be within the specified range (nil = beginning/end)
+// The data of the messages is not returned WithoutData.
+// Messages are ordered by generation time, type and version.
+func (s *Memory) ListMessages(ctx context.Context, appID int32, mType string, minVersion, maxVersion int32, from, to *time.Time, opts ...ListOption) ([]*Message, error) {
+	s.lock.Lock()
+	defer s.lock.Unlock()
+
+	var messages []*Message
+	for _, m := range s.messages {
+		tmpl := s.template(m.TemplateID)
+		switch {
+		case m.AppID != appID:
+			continue
+		case mType != "" && tmpl.Type != mType:
+			continue
+		case from != nil && m.GeneratedAt.Before(*from):
+			continue
+		case to != nil && m.GeneratedAt.After(*to):
+			continue
+		case minVersion != 0 && tmpl.Version < minVersion:
+			continue
+		case maxVersion != 0 && tmpl.Version > maxVersion:
+			continue
+		}
+		msg := *m
+		msg.Data = nil
+		if withData(opts) {
+			msg.Data = copyBytes(m.Data)
+		}
+		t := *tmpl
+		msg.Template = &t
+		messages = append(messages, &msg)
+	}
+	if len(messages) == 0 {
+		return nil, ErrNotFound
+	}
+	sort.Slice(messages, func(i, j int) bool {
+		a, b := messages[i], messages[j]
+		switch {
+		case !a.GeneratedAt.Equal(b.GeneratedAt):
+			return a.GeneratedAt.Before(b.GeneratedAt)
+		case a.Template.Type != b.Template.Type:
+			return a.Template.Type < b.Template.Type
+		default:
+			return a.Template.Version < b.Template.Version
+		}
+	})
+	return messages, nil
+}
+
+// SaveState saves the provided state.
+// If a conflicting state existed, it is overridden by the new state and its revision is incremented.
+func (s *Memory) SaveState(ctx context.Context, state *AidAnalyticsState) error {
+	return s.SaveStateChunks(ctx, state, nil, 0, true)
+}
+
+// SaveStateRevision saves the provided state if the stored revision matches the expected revision.
+// An expected revision of 0 expects the state not to exist yet.
+// Returns ErrRevisionMismatch if the stored revision does not match.
+func (s *Memory) SaveStateRevision(ctx context.Context, state *AidAnalyticsState, expectedRevision int32) error {
+	return s.SaveStateChunks(ctx, state, nil, expectedRevision, false)
+}
+
+// SaveStateChunks saves the provided state and its data chunks, see Postgres.SaveStateChunks
+func (s *Memory) SaveStateChunks(ctx context.Context, state *AidAnalyticsState, chunks [][]byte, expectedRevision int32, force bool) error {
+	if err := checkAudit(ctx); err != nil {
+		return err
+	}
+	s.lock.Lock()
+	defer s.lock.Unlock()
+
+	state.ChunkCount = int32(len(chunks))
+	if len(chunks) > 0 {
+		state.Data = []byte{}
+	}
+	switch {
+	case state.AppID == 0:
+		return errNotNull("app_id")
+	case state.Keyword == "":
+		return errNotNull("keyword")
+	case state.SavedAt.IsZero():
+		return errNotNull("saved_at")
+	}
+	if state.FencingToken > 0 && !s.holdsLease(state.AppID, state.FencingToken) {
+		return ErrLeaseLost
+	}
+	if state.RunID != 0 && s.run(state.RunID) == nil {
+		return ErrUnknownRun
+	}
+
+	state.SavedAt = dbTime(state.SavedAt)
+	state.Codec = CodecNone
+	stored := s.state(state.AppID, state.Keyword, state.SavedAt)
+	event := newAuditEvent(ctx, state.AppID, AuditStateSave, stateTarget(state.Keyword, state.SavedAt))
+	event.AfterHash = stateHash(state)
+	if stored != nil {
+		event.BeforeHash = stateHash(stored)
+	}
+	switch {
+	case stored == nil && (force || expectedRevision == 0):
+		s.stateSeq++
+		state.ID = s.stateSeq
+		if state.Revision == 0 || !force {
+			state.Revision = 1
+		}
+		stored = &AidAnalyticsState{}
+		s.states = append(s.states, stored)
+	case stored != nil && (force || stored.Revision == expectedRevision && expectedRevision != 0):
+		state.ID = stored.ID
+		state.Revision = stored.Revision + 1
+	default:
+		return ErrRevisionMismatch
+	}
+	*stored = *state
+	stored.Data = copyBytes(state.Data)
+	stored.FencingToken = 0
+
+	delete(s.chunks, state.ID)
+	if len(chunks) > 0 {
+		stateChunks := make([][]byte, len(chunks))
+		for i, chunk := range chunks {
+			stateChunks[i] = copyBytes(chunk)
+		}
+		s.chunks[state.ID] = stateChunks
+	}
+	s.insertAuditEvents(event)
+	return nil
+}
+
+func (s *Memory) state(appID int32, keyword string, savedAt time.Time) *AidAnalyticsState {
+	key := stateKey{appID: appID, saved: dbTime(savedAt).UnixNano(), keyword: keyword}
+	for _, st := range s.states {
+		if (stateKey{appID: st.AppID, saved: st.SavedAt.UnixNano(), keyword: st.Keyword}) == key {
+			return st
+		}
+	}
+	return nil
+}
+
+// copyState copies a stored state to dst, keeping the fencing token of dst as it is not stored
+func copyState(dst, stored *AidAnalyticsState) {
+	fencingToken := dst.FencingToken
+	*dst = *stored
+	dst.Data = copyBytes(stored.Data)
+	dst.FencingToken = fencingToken
+}
+
+// GetStateChunk returns the data of a single chunk of a chunked state by state id and sequence number
+func (s *Memory) GetStateChunk(ctx context.Context, stateID int32, seq int32) ([]byte, error) {
+	s.lock.Lock()
+	defer s.lock.Unlock()
+
+	chunks := s.chunks[stateID]
+	if seq < 0 || int(seq) >= len(chunks) {
+		return nil, ErrNotFound
+	}
+	return copyBytes(chunks[seq]), nil
+}
+
+// GetState returns a state by app id, keyword and timestamp.
+func (s *Memory) GetState(ctx context.Context, state *AidAnalyticsState) error {
+	s.lock.Lock()
+	defer s.lock.Unlock()
+
+	stored := s.state(state.AppID, state.Keyword, state.SavedAt)
+	if stored == nil {
+		return ErrNotFound
+	}
+	copyState(state, stored)
+	return nil
+}
+
+// GetLatestState returns the newest state by app id and keyword.
+// If asOf is provided, the newest state saved at or before asOf is returned.
+func (s *Memory) GetLatestState(ctx context.Context, appID int32, keyword string, asOf *time.Time) (*AidAnalyticsState, error) {
+	s.lock.Lock()
+	defer s.lock.Unlock()
+
+	var latest *AidAnalyticsState
+	for _, st := range s.states {
+		if st.AppID != appID || st.Keyword != keyword || (asOf != nil && st.SavedAt.After(*asOf)) {
+			continue
+		}
+		if latest == nil || st.SavedAt.After(latest.SavedAt) {
+			latest = st
+		}
+	}
+	if latest == nil {
+		return nil, ErrNotFound
+	}
+	state := &AidAnalyticsState{}
+	copyState(state, latest)
+	return state, nil
+}
+
+// ListStates fetches all state by app id.
+// If keyword is provided, all states must additionally match the keyword
+// If from and/or to are provided, all states must additionally be within the specified range (nil = beginning/end)
+// The data of the states is not returned WithoutData.
+// States are ordered by generation time and keyword.
+func (s *Memory) ListStates(ctx context.Context, appID int32, keyword string, from, to *time.Time, opts ...ListOption) ([]*AidAnalyticsState, error) {
+	s.lock.Lock()
+	defer s.lock.Unlock()
+
+	var states []*AidAnalyticsState
+	for _, st := range s.states {
+		switch {
+		case st.AppID != appID:
+			continue
+		case keyword != "" && st.Keyword != keyword:
+			continue
+		case from != nil && st.SavedAt.Before(*from):
+			continue
+		case to != nil && st.SavedAt.After(*to):
+			continue
+		}
+		state := &AidAnalyticsState{}
+		copyState(state, st)
+		if !withData(opts) {
+			state.Data = nil
+		}
+		states = append(states, state)
+	}
+	if len(states) == 0 {
+		return nil, ErrNotFound
+	}
+	sort.Slice(states, func(i, j int) bool {
+		if !states[i].SavedAt.Equal(states[j].SavedAt) {
+			return states[i].SavedAt.Before(states[j].SavedAt)
+		}
+		return states[i].Keyword < states[j].Keyword
+	})
+	return states, nil
+}
+
+// DeleteStates deletes the states of an app and keyword saved within a time range, both ends inclusive.
+// Chunks of chunked states are deleted with their states. Dry runs count the states without deleting them.
+// Returns the number of deleted states.
+func (s *Memory) DeleteStates(ctx context.Context, appID int32, keyword string, from, to time.Time, dryRun bool) (int, error) {
+	if !dryRun {
+		if err := checkAudit(ctx); err != nil {
+			return 0, err
+		}
+	}
+	s.lock.Lock()
+	defer s.lock.Unlock()
+
+	deleted := s.deleteStates(dryRun, func(st *AidAnalyticsState) bool {
+		return st.AppID == appID && st.Keyword == keyword && !st.SavedAt.Before(from) && !st.SavedAt.After(to)
+	})
+	if !dryRun {
+		for _, st := range deleted {
+			event := newAuditEvent(ctx, appID, AuditStateDelete, stateTarget(st.Keyword, st.SavedAt))
+			event.BeforeHash = stateHash(st)
+			s.insertAuditEvents(event)
+		}
+	}
+	return len(deleted), nil
+}
+
+// deleteStates deletes the states matching the filter with their chunks and returns them.
+// Dry runs return the matching states without deleting them.
+func (s *Memory) deleteStates(dryRun bool, filter func(*AidAnalyticsState) bool) []*AidAnalyticsState {
+	var kept, deleted []*AidAnalyticsState
+	for _, st := range s.states {
+		if filter(st) {
+			deleted = append(deleted, st)
+		} else {
+			kept = append(kept, st)
+		}
+	}
+	if !dryRun {
+		s.states = kept
+		for _, st := range deleted {
+			delete(s.chunks, st.ID)
+		}
+	}
+	return deleted
+}
+
+// GetKeyword returns a registered keyword or ErrNotFound if the keyword is not registered
+func (s *Memory) GetKeyword(ctx context.Context, keyword string) (*AidAnalyticsKeyword, error) {
+	s.lock.Lock()
+	defer s.lock.Unlock()
+
+	k, ok := s.keywords[keyword]
+	if !ok {
+		return nil, ErrNotFound
+	}
+	registered := *k
+	return &registered, nil
+}
+
+// ListKeywords fetches all keywords states are saved with by app id, including unregistered keywords.
+func (s *Memory) ListKeywords(ctx context.Context, appID int32) ([]*KeywordUsage, error) {
+	s.lock.Lock()
+	defer s.lock.Unlock()
+
+	byKeyword := map[string]*KeywordUsage{}
+	var usages []*KeywordUsage
+	for _, st := range s.states {
+		if st.AppID != appID {
+			continue
+		}
+		usage, ok := byKeyword[st.Keyword]
+		if !ok {
+			usage = &KeywordUsage{AidAnalyticsKeyword: AidAnalyticsKeyword{Keyword: st.Keyword}}
+			if k, ok := s.keywords[st.Keyword]; ok {
+				usage.AidAnalyticsKeyword = *k
+				usage.Registered = true
+			}
+			byKeyword[st.Keyword] = usage
+			usages = append(usages, usage)
+		}
+		usage.StateCount++
+		if st.SavedAt.After(usage.LatestSavedAt) {
+			usage.LatestSavedAt = st.SavedAt
+		}
+	}
+	if len(usages) == 0 {
+		return nil, ErrNotFound
+	}
+	sort.Slice(usages, func(i, j int) bool { return usages[i].Keyword < usages[j].Keyword })
+	return usages, nil
+}
+
+// StateFreshness fetches the newest saved_at of the states of every app and keyword saved after since, if provided.
+// Returns ErrNotFound if there are no such states.
+func (s *Memory) StateFreshness(ctx context.Context, since *time.Time) ([]*StateFreshness, error) {
+	s.lock.Lock()
+	defer s.lock.Unlock()
+
+	byKey := map[stateKey]*StateFreshness{}
+	var freshness []*StateFreshness
+	for _, st := range s.states {
+		if since != nil && !st.SavedAt.After(*since) {
+			continue
+		}
+		key := stateKey{appID: st.AppID, keyword: st.Keyword}
+		f, ok := byKey[key]
+		if !ok {
+			f = &StateFreshness{AppID: st.AppID, Keyword: st.Keyword}
+			byKey[key] = f
+			freshness = append(freshness, f)
+		}
+		if st.SavedAt.After(f.LatestSavedAt) {
+			f.LatestSavedAt = st.SavedAt
+		}
+	}
+	if len(freshness) == 0 {
+		return nil, ErrNotFound
+	}
+	sort.Slice(freshness, func(i, j int) bool {
+		if freshness[i].AppID != freshness[j].AppID {
+			return freshness[i].AppID < freshness[j].AppID
+		}
+		return freshness[i].Keyword < freshness[j].Keyword
+	})
+	return freshness, nil
+}
@@ -0,0 +1,53 @@
+package storage
+
+import (
+	"context"
+	"time"
+)
+
+// insertAuditEvents appends audit events, with the lock of their mutation held
+func (s *Memory) insertAuditEvents(events ...*AuditEvent) {
+	for _, event := range events {
+		s.auditSeq++
+		event.ID = s.auditSeq
+		event.OccurredAt = dbTime(time.Now())
+		stored := *event
+		s.audit = append(s.audit, &stored)
+	}
+}
+
+// ListAuditEvents fetches the audit events matching the filter, newest first.
+// Returns ErrNotFound if there are no matching events.
+func (s *Memory) ListAuditEvents(ctx context.Context, filter *AuditFilter) ([]*AuditEvent, error) {
+	s.lock.Lock()
+	defer s.lock.Unlock()
+
+	var events []*AuditEvent
+	// events are appended in the order they occurred
+	for i := len(s.audit) - 1; i >= 0; i-- {
+		e := s.audit[i]
+		switch {
+		case filter.AppID != 0 && e.AppID != filter.AppID:
+			continue
+		case filter.Actor != "" && e.Actor != filter.Actor:
+			continue
+		case filter.Action != "" && e.Action != filter.Action:
+			continue
+		case filter.Target != "" && e.Target != filter.Target:
+			continue
+		case filter.From != nil && e.OccurredAt.Before(*filter.From):
+			continue
+		case filter.To != nil && e.OccurredAt.After(*filter.To):
+			continue
+		}
+		event := *e
+		events = append(events, &event)
+		if filter.Limit > 0 && len(events) == filter.Limit {
+			break
+		}
+	}
+	if len(events) == 0 {
+		return nil, ErrNotFound
+	}
+	return events, nil
+}
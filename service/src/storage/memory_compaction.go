@@ -0,0 +1,142 @@
+package storage
+
+import (
+	"context"
+	"sort"
+	"time"
+)
+
+// SaveCompactionPolicy creates or replaces the compaction policy of a keyword and audits it
+func (s *Memory) SaveCompactionPolicy(ctx context.Context, policy *AidAnalyticsCompactionPolicy) error {
+	if err := checkAudit(ctx); err != nil {
+		return err
+	}
+	s.lock.Lock()
+	defer s.lock.Unlock()
+
+	event := newAuditEvent(ctx, 0, AuditCompactionPolicySave, policy.Keyword)
+	event.AfterHash = policyHash(policy)
+	if previous, ok := s.policies[policy.Keyword]; ok {
+		event.BeforeHash = policyHash(previous)
+	}
+	policy.UpdatedAt = dbTime(time.Now())
+	stored := *policy
+	s.policies[policy.Keyword] = &stored
+	s.insertAuditEvents(event)
+	return nil
+}
+
+// ListCompactionPolicies fetches the compaction policies of all keywords or ErrNotFound if there are none
+func (s *Memory) ListCompactionPolicies(ctx context.Context) ([]*AidAnalyticsCompactionPolicy, error) {
+	s.lock.Lock()
+	defer s.lock.Unlock()
+
+	policies := s.compactionPolicies("")
+	if len(policies) == 0 {
+		return nil, ErrNotFound
+	}
+	return policies, nil
+}
+
+// compactionPolicies returns copies of the policies of all keywords, or just keyword if not empty, ordered by keyword
+func (s *Memory) compactionPolicies(keyword string) []*AidAnalyticsCompactionPolicy {
+	var policies []*AidAnalyticsCompactionPolicy
+	for _, p := range s.policies {
+		if keyword == "" || p.Keyword == keyword {
+			policy := *p
+			policies = append(policies, &policy)
+		}
+	}
+	sort.Slice(policies, func(i, j int) bool { return policies[i].Keyword < policies[j].Keyword })
+	return policies
+}
+
+// Compact deletes the states not kept by the compaction policies of all keywords, or just keyword if not empty.
+// The states are kept by the same rules as in Postgres.Compact, days start at midnight UTC.
+// Dry runs report the states that would be deleted without deleting them.
+// Returns ErrNotFound if there is no policy to compact by.
+func (s *Memory) Compact(ctx context.Context, keyword string, dryRun bool) ([]*Compaction, error) {
+	if !dryRun {
+		if err := checkAudit(ctx); err != nil {
+			return nil, err
+		}
+	}
+	s.lock.Lock()
+	defer s.lock.Unlock()
+
+	policies := s.compactionPolicies(keyword)
+	if len(policies) == 0 {
+		return nil, ErrNotFound
+	}
+
+	now := time.Now()
+	compactions := []*Compaction{}
+	for _, policy := range policies {
+		compacted := s.deleteStates(dryRun, s.compactionFilter(policy, now))
+		byApp := map[int32]*Compaction{}
+		var deleted []*Compaction
+		for _, st := range compacted {
+			c, ok := byApp[st.AppID]
+			if !ok {
+				c = &Compaction{AppID: st.AppID, Keyword: st.Keyword}
+				byApp[st.AppID] = c
+				deleted = append(deleted, c)
+			}
+			c.Deleted++
+		}
+		sort.Slice(deleted, func(i, j int) bool { return deleted[i].AppID < deleted[j].AppID })
+		if !dryRun {
+			for _, c := range deleted {
+				compactedCounter.WithLabelValues(c.Keyword).Add(float64(c.Deleted))
+				event := newAuditEvent(ctx, c.AppID, AuditStateCompact, c.Keyword)
+				event.Count = c.Deleted
+				s.insertAuditEvents(event)
+			}
+		}
+		compactions = append(compactions, deleted...)
+	}
+	return compactions, nil
+}
+
+// compactionFilter returns a filter matching the states of the policy keyword not kept by the policy at now
+func (s *Memory) compactionFilter(policy *AidAnalyticsCompactionPolicy, now time.Time) func(*AidAnalyticsState) bool {
+	type dayKey struct {
+		appID int32
+		day   int64
+	}
+	// newest states first, ranked per app and per app and day
+	var states []*AidAnalyticsState
+	for _, st := range s.states {
+		if st.Keyword == policy.Keyword {
+			states = append(states, st)
+		}
+	}
+	sort.SliceStable(states, func(i, j int) bool { return states[i].SavedAt.After(states[j].SavedAt) })
+	rank := map[*AidAnalyticsState]int32{}
+	dayRank := map[*AidAnalyticsState]int32{}
+	appCount := map[int32]int32{}
+	dayCount := map[dayKey]int32{}
+	for _, st := range states {
+		day := dayKey{appID: st.AppID, day: st.SavedAt.UTC().Truncate(24 * time.Hour).Unix()}
+		appCount[st.AppID]++
+		dayCount[day]++
+		rank[st] = appCount[st.AppID]
+		dayRank[st] = dayCount[day]
+	}
+
+	daysAgo := func(days int32) time.Time { return now.Add(-time.Duration(days) * 24 * time.Hour) }
+	return func(st *AidAnalyticsState) bool {
+		r, ok := rank[st]
+		if !ok || (policy.KeepLast > 0 && r <= policy.KeepLast) {
+			return false
+		}
+		switch {
+		case policy.DeleteAfterDays > 0 && st.SavedAt.Before(daysAgo(policy.DeleteAfterDays)):
+			return true
+		case policy.KeepDailyAfterDays > 0 && st.SavedAt.Before(daysAgo(policy.KeepDailyAfterDays)) && dayRank[st] > 1:
+			return true
+		default:
+			return policy.KeepLast > 0 && (policy.KeepDailyAfterDays == 0 || !st.SavedAt.Before(daysAgo(policy.KeepDailyAfterDays)))
+		}
+	}
+}
@@ -0,0 +1,74 @@
+package storage
+
+import (
+	"context"
+	"time"
+)
+
+// AcquireLease acquires a lease for its holder with a new fencing token.
+// Returns ErrLeaseHeld if the lease is held by another holder and has not expired.
+func (s *Memory) AcquireLease(ctx context.Context, lease *AidLease, ttl time.Duration) error {
+	s.lock.Lock()
+	defer s.lock.Unlock()
+
+	key := leaseKey{appID: lease.AppID, name: lease.Name}
+	now := time.Now()
+	if held, ok := s.leases[key]; ok && held.ExpiresAt.After(now) && held.Holder != lease.Holder {
+		return ErrLeaseHeld
+	}
+	s.fencingTokenSeq++
+	lease.FencingToken = s.fencingTokenSeq
+	lease.ExpiresAt = dbTime(now.Add(ttl))
+	stored := *lease
+	s.leases[key] = &stored
+	return nil
+}
+
+// RenewLease extends a lease held with its fencing token.
+// Returns ErrLeaseLost if the lease expired or was acquired by another holder.
+func (s *Memory) RenewLease(ctx context.Context, lease *AidLease, ttl time.Duration) error {
+	s.lock.Lock()
+	defer s.lock.Unlock()
+
+	held := s.heldLease(lease)
+	now := time.Now()
+	if held == nil || !held.ExpiresAt.After(now) {
+		return ErrLeaseLost
+	}
+	held.ExpiresAt = dbTime(now.Add(ttl))
+	*lease = *held
+	return nil
+}
+
+// ReleaseLease releases a lease held with its fencing token.
+// Returns ErrLeaseLost if the lease was acquired by another holder.
+func (s *Memory) ReleaseLease(ctx context.Context, lease *AidLease) error {
+	s.lock.Lock()
+	defer s.lock.Unlock()
+
+	if s.heldLease(lease) == nil {
+		return ErrLeaseLost
+	}
+	delete(s.leases, leaseKey{appID: lease.AppID, name: lease.Name})
+	return nil
+}
+
+// heldLease returns the stored lease matching the holder and fencing token of lease, or nil
+func (s *Memory) heldLease(lease *AidLease) *AidLease {
+	held, ok := s.leases[leaseKey{appID: lease.AppID, name: lease.Name}]
+	if !ok || held.Holder != lease.Holder || held.FencingToken != lease.FencingToken {
+		return nil
+	}
+	return held
+}
+
+// holdsLease returns true if the fencing token is of an unexpired lease of the app
+func (s *Memory) holdsLease(appID int32, fencingToken int64) bool {
+	now := time.Now()
+	for _, lease := range s.leases {
+		if lease.AppID == appID && lease.FencingToken == fencingToken && lease.ExpiresAt.After(now) {
+			return true
+		}
+	}
+	return false
+}
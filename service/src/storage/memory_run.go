@@ -0,0 +1,154 @@
+package storage
+
+import (
+	"context"
+	"sort"
+	"time"
+)
+
+// StartRun creates a new running run
+func (s *Memory) StartRun(ctx context.Context, run *AidRun) error {
+	s.lock.Lock()
+	defer s.lock.Unlock()
+
+	if run.AppID == 0 {
+		return errNotNull("app_id")
+	}
+	if run.Pipeline == "" {
+		return errNotNull("pipeline")
+	}
+	s.runSeq++
+	run.ID = s.runSeq
+	run.Status = RunStatusRunning
+	if run.StartedAt.IsZero() {
+		run.StartedAt = dbTime(time.Now())
+	}
+	s.runs = append(s.runs, copyRun(run))
+	return nil
+}
+
+// FinishRun sets the status, error and counters of a running run and marks it finished.
+// Returns ErrNotFound if the run does not exist and ErrRunFinished if it is already finished.
+func (s *Memory) FinishRun(ctx context.Context, run *AidRun) error {
+	s.lock.Lock()
+	defer s.lock.Unlock()
+
+	stored := s.run(run.ID)
+	if stored == nil {
+		return ErrNotFound
+	}
+	if stored.Status != RunStatusRunning {
+		return ErrRunFinished
+	}
+	stored.Status = run.Status
+	stored.Error = run.Error
+	stored.Counters = copyRun(run).Counters
+	stored.FinishedAt = dbTime(time.Now())
+	*run = *copyRun(stored)
+	return nil
+}
+
+// ListRuns fetches the runs matching the filter with their message and state counts, newest first.
+// Returns ErrNotFound if there are no matching runs.
+func (s *Memory) ListRuns(ctx context.Context, filter *RunFilter) ([]*AidRun, error) {
+	s.lock.Lock()
+	defer s.lock.Unlock()
+
+	var runs []*AidRun
+	for _, r := range s.filterRuns(filter.AppID, filter.Pipeline) {
+		switch {
+		case filter.Status != "" && r.Status != filter.Status:
+			continue
+		case filter.StartedFrom != nil && r.StartedAt.Before(*filter.StartedFrom):
+			continue
+		case filter.StartedTo != nil && r.StartedAt.After(*filter.StartedTo):
+			continue
+		case filter.WithoutMessages && (r.Status == RunStatusRunning || r.MessageCount > 0):
+			continue
+		}
+		runs = append(runs, r)
+	}
+	if len(runs) == 0 {
+		return nil, ErrNotFound
+	}
+	sort.Slice(runs, func(i, j int) bool {
+		if !runs[i].StartedAt.Equal(runs[j].StartedAt) {
+			return runs[i].StartedAt.After(runs[j].StartedAt)
+		}
+		return runs[i].ID > runs[j].ID
+	})
+	return runs, nil
+}
+
+// LastSuccessfulRuns fetches the newest successful run of each app, or just of appID if not 0.
+// Returns ErrNotFound if there are no successful runs.
+func (s *Memory) LastSuccessfulRuns(ctx context.Context, appID int32, pipeline string) ([]*AidRun, error) {
+	s.lock.Lock()
+	defer s.lock.Unlock()
+
+	byApp := map[int32]*AidRun{}
+	var runs []*AidRun
+	for _, r := range s.filterRuns(appID, pipeline) {
+		if r.Status != RunStatusSucceeded {
+			continue
+		}
+		last, ok := byApp[r.AppID]
+		if !ok {
+			runs = append(runs, r)
+		} else if !r.FinishedAt.After(last.FinishedAt) {
+			continue
+		}
+		byApp[r.AppID] = r
+	}
+	if len(runs) == 0 {
+		return nil, ErrNotFound
+	}
+	for i, r := range runs {
+		runs[i] = byApp[r.AppID]
+	}
+	sort.Slice(runs, func(i, j int) bool { return runs[i].AppID < runs[j].AppID })
+	return runs, nil
+}
+
+// filterRuns returns copies of the runs of appID and pipeline, if not empty, with their message and state counts
+func (s *Memory) filterRuns(appID int32, pipeline string) []*AidRun {
+	var runs []*AidRun
+	for _, r := range s.runs {
+		if (appID != 0 && r.AppID != appID) || (pipeline != "" && r.Pipeline != pipeline) {
+			continue
+		}
+		run := copyRun(r)
+		for _, m := range s.messages {
+			if m.RunID == run.ID {
+				run.MessageCount++
+			}
+		}
+		for _, st := range s.states {
+			if st.RunID == run.ID {
+				run.StateCount++
+			}
+		}
+		runs = append(runs, run)
+	}
+	return runs
+}
+
+func (s *Memory) run(id int32) *AidRun {
+	for _, r := range s.runs {
+		if r.ID == id {
+			return r
+		}
+	}
+	return nil
+}
+
+func copyRun(run *AidRun) *AidRun {
+	r := *run
+	if run.Counters != nil {
+		r.Counters = make(map[string]int64, len(run.Counters))
+		for k, v := range run.Counters {
+			r.Counters[k] = v
+		}
+	}
+	return &r
+}
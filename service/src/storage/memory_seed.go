@@ -0,0 +1,272 @@
+package storage
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+	"time"
+	"unicode"
+)
+
+// sqlToken is a token of a seed statement
+type sqlToken struct {
+	text   string
+	quoted bool // text is the unescaped content of a string literal
+}
+
+// is returns true if the token is the keyword or punctuation s, ignoring case
+func (t sqlToken) is(s string) bool {
+	return !t.quoted && strings.EqualFold(t.text, s)
+}
+
+// seededTables are the tables the rows of which are seeded
+var seededTables = []string{"message_templates", "aid_analytics_keywords"}
+
+// schemaStatements are the first keywords of statements that do not change the rows of tables
+var schemaStatements = []string{"CREATE", "ALTER", "DROP", "GRANT", "REVOKE", "COMMENT", "SELECT"}
+
+// Seed applies the message template and keyword rows of SQL statements, e.g. the statements of the migrations.
+// Inserts of values into message_templates and aid_analytics_keywords and updates of message templates by type are
+// applied. Any other statement changing the rows of these tables fails the seed, so that the memory storage cannot
+// silently drift from postgres. Statements not touching these tables are ignored.
+func (s *Memory) Seed(statements []string) error {
+	s.lock.Lock()
+	defer s.lock.Unlock()
+
+	for _, statement := range statements {
+		tokens, err := tokenizeSQL(statement)
+		if err != nil {
+			return err
+		}
+		for len(tokens) > 0 {
+			end := 0
+			for end < len(tokens) && !tokens[end].is(";") {
+				end++
+			}
+			if err := s.seed(tokens[:end]); err != nil {
+				return err
+			}
+			if end < len(tokens) {
+				end++
+			}
+			tokens = tokens[end:]
+		}
+	}
+	return nil
+}
+
+// seed applies a single tokenized statement
+func (s *Memory) seed(tokens []sqlToken) error {
+	switch {
+	case len(tokens) == 0 || isAny(tokens[0], schemaStatements):
+		return nil
+	case len(tokens) > 3 && tokens[0].is("INSERT") && tokens[1].is("INTO") && isAny(tokens[2], seededTables):
+		columns, rows, err := parseInsert(tokens[3:])
+		if err != nil {
+			return fmt.Errorf("failed to seed %s: %v", tokens[2].text, err)
+		}
+		if tokens[2].is("message_templates") {
+			return s.seedTemplates(columns, rows)
+		}
+		return s.seedKeywords(columns, rows)
+	case len(tokens) == 10 && tokens[0].is("UPDATE") && tokens[1].is("message_templates") && tokens[2].is("SET") &&
+		tokens[3].is("template") && tokens[4].is("=") && tokens[5].quoted &&
+		tokens[6].is("WHERE") && tokens[7].is("type") && tokens[8].is("=") && tokens[9].quoted:
+		for _, t := range s.templates {
+			if t.Type == tokens[9].text {
+				t.Template = tokens[5].text
+			}
+		}
+		return nil
+	}
+	for _, t := range tokens {
+		if isAny(t, seededTables) {
+			return fmt.Errorf("failed to seed %s: unsupported statement %s", t.text, statementText(tokens))
+		}
+	}
+	return nil
+}
+
+// isAny returns true if the token is any of the keywords or names
+func isAny(t sqlToken, words []string) bool {
+	for _, w := range words {
+		if t.is(w) {
+			return true
+		}
+	}
+	return false
+}
+
+// statementText returns a tokenized statement as text, e.g. for errors
+func statementText(tokens []sqlToken) string {
+	words := make([]string, len(tokens))
+	for i, t := range tokens {
+		words[i] = t.text
+		if t.quoted {
+			words[i] = "'" + strings.Replace(t.text, "'", "''", -1) + "'"
+		}
+	}
+	text := strings.Join(words, " ")
+	if len(text) > 200 {
+		text = text[:200] + "..."
+	}
+	return text
+}
+
+func (s *Memory) seedTemplates(columns []string, rows [][]sqlToken) error {
+	for _, row := range rows {
+		tmpl := &MessageTemplate{}
+		for i, column := range columns {
+			var err error
+			switch column {
+			case "type":
+				tmpl.Type = row[i].text
+			case "version":
+				tmpl.Version, err = parseInt32(row[i])
+			case "template":
+				tmpl.Template = row[i].text
+			}
+			if err != nil {
+				return err
+			}
+		}
+		if err := s.insertTemplate(tmpl); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+func (s *Memory) seedKeywords(columns []string, rows [][]sqlToken) error {
+	for _, row := range rows {
+		k := &AidAnalyticsKeyword{SchemaVersion: 1, CreatedAt: dbTime(time.Now())}
+		for i, column := range columns {
+			var err error
+			switch column {
+			case "keyword":
+				k.Keyword = row[i].text
+			case "content_type":
+				k.ContentType = row[i].text
+			case "schema_version":
+				k.SchemaVersion, err = parseInt32(row[i])
+			case "max_size":
+				k.MaxSize, err = parseInt32(row[i])
+			case "owner":
+				k.Owner = row[i].text
+			}
+			if err != nil {
+				return err
+			}
+		}
+		if _, ok := s.keywords[k.Keyword]; ok {
+			return errDuplicateKeyword
+		}
+		s.keywords[k.Keyword] = k
+	}
+	return nil
+}
+
+func parseInt32(t sqlToken) (int32, error) {
+	n, err := strconv.ParseInt(t.text, 10, 32)
+	if err != nil {
+		return 0, fmt.Errorf("invalid integer %q", t.text)
+	}
+	return int32(n), nil
+}
+
+// parseInsert parses the column list and the value rows following the table of an insert statement
+func parseInsert(tokens []sqlToken) ([]string, [][]sqlToken, error) {
+	columns, tokens, err := parseList(tokens)
+	if err != nil {
+		return nil, nil, err
+	}
+	names := make([]string, len(columns))
+	for i, c := range columns {
+		names[i] = strings.ToLower(c.text)
+	}
+	if len(tokens) == 0 || !tokens[0].is("VALUES") {
+		return nil, nil, fmt.Errorf("expected VALUES")
+	}
+	tokens = tokens[1:]
+
+	var rows [][]sqlToken
+	for {
+		var row []sqlToken
+		if row, tokens, err = parseList(tokens); err != nil {
+			return nil, nil, err
+		}
+		if len(row) != len(names) {
+			return nil, nil, fmt.Errorf("expected %d values, got %d", len(names), len(row))
+		}
+		rows = append(rows, row)
+		if len(tokens) == 0 {
+			return names, rows, nil
+		}
+		if !tokens[0].is(",") {
+			return nil, nil, fmt.Errorf("unexpected %q", tokens[0].text)
+		}
+		tokens = tokens[1:]
+	}
+}
+
+// parseList parses a parenthesized, comma separated list of single tokens and returns the remaining tokens
+func parseList(tokens []sqlToken) ([]sqlToken, []sqlToken, error) {
+	if len(tokens) == 0 || !tokens[0].is("(") {
+		return nil, nil, fmt.Errorf("expected (")
+	}
+	var list []sqlToken
+	for i := 1; i+1 < len(tokens); i += 2 {
+		list = append(list, tokens[i])
+		switch {
+		case tokens[i+1].is(")"):
+			return list, tokens[i+2:], nil
+		case !tokens[i+1].is(","):
+			return nil, nil, fmt.Errorf("unexpected %q", tokens[i+1].text)
+		}
+	}
+	return nil, nil, fmt.Errorf("expected )")
+}
+
+// tokenizeSQL splits a statement into string literals, words, numbers and punctuation, skipping comments
+func tokenizeSQL(statement string) ([]sqlToken, error) {
+	var tokens []sqlToken
+	runes := []rune(statement)
+	for i := 0; i < len(runes); {
+		r := runes[i]
+		switch {
+		case unicode.IsSpace(r):
+			i++
+		case r == '-' && i+1 < len(runes) && runes[i+1] == '-':
+			for i < len(runes) && runes[i] != '\n' {
+				i++
+			}
+		case r == '\'':
+			var text []rune
+			for i++; ; i++ {
+				if i >= len(runes) {
+					return nil, fmt.Errorf("unterminated string literal")
+				}
+				if runes[i] == '\'' {
+					if i+1 < len(runes) && runes[i+1] == '\'' {
+						i++
+					} else {
+						break
+					}
+				}
+				text = append(text, runes[i])
+			}
+			i++
+			tokens = append(tokens, sqlToken{text: string(text), quoted: true})
+		case r == '_' || unicode.IsLetter(r) || unicode.IsDigit(r):
+			start := i
+			for i < len(runes) && (runes[i] == '_' || unicode.IsLetter(runes[i]) || unicode.IsDigit(runes[i])) {
+				i++
+			}
+			tokens = append(tokens, sqlToken{text: string(runes[start:i])})
+		default:
+			i++
+			tokens = append(tokens, sqlToken{text: string(r)})
+		}
+	}
+	return tokens, nil
+}
@@ -23,14 +23,35 @@ func CheckExistingMessageIDs(ctx context.Context, postgresClient postgres.Client
 	return ids
 }
 
-// DeleteMessageByID deletes messages from postgres
+// DeleteMessageByID deletes messages from postgres. Every deleted message is audited in the same transaction
+// with the hash of its data, see WithAudit.
 func DeleteMessageByID(ctx context.Context, postgresClient postgres.Client, ids []int32) {
 	logger := log.FromContext(ctx)
 	logger.Debug("Deleting message of ids" + string(ids))
 
+	if err := checkAudit(ctx); err != nil {
+		panic(err)
+	}
 	deleteIds := pg.In(ids)
 	db, err := postgresClient.DB(ctx)
-	_, err = db.Model((*Message)(nil)).Where("id IN (?)", deleteIds).Delete()
+	if err != nil {
+		panic(err)
+	}
+	err = db.RunInTransaction(func(tx *pg.Tx) error {
+		var messages []*Message
+		if _, err := tx.Query(&messages, "DELETE FROM messages WHERE id IN (?) RETURNING *", deleteIds); err != nil {
+			return err
+		}
+		events := make([]*AuditEvent, len(messages))
+		for i, msg := range messages {
+			if err := decodeMessage(msg); err != nil {
+				return err
+			}
+			events[i] = newAuditEvent(ctx, msg.AppID, AuditMessageDelete, messageTarget(msg.ID))
+			events[i].BeforeHash = auditHash(msg.Data)
+		}
+		return insertAuditEvents(tx, events...)
+	})
 	logger.Info("messages deleted")
 	if err != nil {
 		panic(err)
@@ -23,8 +23,22 @@ func TestDeleteMessagesByID(t *testing.T) {
 	_, err = testPostgresDB.Model(&createdMessages).Returning("*").Insert()
 	require.Nil(t, err)
 	checkMessages(t, testCtx, testPostgresClient, []int32{1, 2, 3})
-	storage.DeleteMessageByID(testCtx, testPostgresClient, []int32{1, 3})
+	deletionCtx := storage.WithAudit(testCtx, storage.Audit{Actor: "ops", RPC: "cli:delete", Reason: "customer request"})
+	storage.DeleteMessageByID(deletionCtx, testPostgresClient, []int32{1, 3})
 	checkMessages(t, testCtx, testPostgresClient, []int32{2})
+
+	// every deleted message is audited with the hash of its data
+	events, err := storage.NewPostgres(testPostgresClient).ListAuditEvents(testCtx, &storage.AuditFilter{AppID: 123, Action: storage.AuditMessageDelete})
+	require.Nil(t, err)
+	require.Len(t, events, 2)
+	targets := []string{}
+	for _, e := range events {
+		require.Equal(t, "ops", e.Actor)
+		require.Equal(t, "customer request", e.Reason)
+		require.Len(t, e.BeforeHash, 64)
+		targets = append(targets, e.Target)
+	}
+	require.ElementsMatch(t, []string{"1", "3"}, targets)
 	storage.DeleteMessageByID(testCtx, testPostgresClient, []int32{2})
 	checkMessages(t, testCtx, testPostgresClient, emptyIDs)
 }
@@ -0,0 +1,161 @@
+package storage
+
+import (
+	"context"
+	"sync"
+	"time"
+
+	"github.com/callstats-io/go-common/postgres"
+	"github.com/prometheus/client_golang/prometheus"
+)
+
+// metric labels
+const (
+	LabelTable   = "table"
+	LabelSize    = "size"
+	LabelKeyword = "keyword"
+	LabelRole    = "role"
+	LabelReason  = "reason"
+	LabelState   = "state"
+)
+
+// connection roles, reads go to the read-only role if a read replica is configured
+const (
+	RoleReadWrite = "read_write"
+	RoleReadOnly  = "read_only"
+)
+
+// reasons reads are routed to a role
+const (
+	ReasonReplica     = "replica"
+	ReasonStaleness   = "staleness"
+	ReasonUnavailable = "unavailable"
+)
+
+// pool connection states
+const (
+	StateTotal = "total"
+	StateFree  = "free"
+)
+
+// payload sizes, logical is the size before and stored the size after compression
+const (
+	SizeLogical = "logical"
+	SizeStored  = "stored"
+)
+
+var (
+	payloadWrittenBytesCounter = prometheus.NewCounterVec(
+		prometheus.CounterOpts{
+			Namespace: "aid",
+			Subsystem: "storage",
+			Name:      "payload_written_bytes",
+			Help:      "Total number of payload bytes written by table and size (logical or stored), not the size at rest.",
+		},
+		[]string{LabelTable, LabelSize},
+	)
+	recompressedCounter = prometheus.NewCounterVec(
+		prometheus.CounterOpts{
+			Namespace: "aid",
+			Subsystem: "storage",
+			Name:      "recompressed_count",
+			Help:      "Total number of existing rows compressed by the recompression job by table.",
+		},
+		[]string{LabelTable},
+	)
+	compactedCounter = prometheus.NewCounterVec(
+		prometheus.CounterOpts{
+			Namespace: "aid",
+			Subsystem: "storage",
+			Name:      "compacted_count",
+			Help:      "Total number of states deleted by compaction by keyword.",
+		},
+		[]string{LabelKeyword},
+	)
+	droppedPartitionsCounter = prometheus.NewCounterVec(
+		prometheus.CounterOpts{
+			Namespace: "aid",
+			Subsystem: "storage",
+			Name:      "dropped_partitions_count",
+			Help:      "Total number of monthly partitions dropped by retention by table.",
+		},
+		[]string{LabelTable},
+	)
+	readCounter = prometheus.NewCounterVec(
+		prometheus.CounterOpts{
+			Namespace: "aid",
+			Subsystem: "storage",
+			Name:      "reads_count",
+			Help:      "Total number of read connections by role and the reason for routing the read to the role.",
+		},
+		[]string{LabelRole, LabelReason},
+	)
+	poolStats = &poolCollector{
+		clients: map[string]postgres.Client{},
+		connections: prometheus.NewDesc("aid_storage_pool_connections",
+			"Number of connections in the postgres connection pool by role and state (total or free).",
+			[]string{LabelRole, LabelState}, nil),
+		hits: prometheus.NewDesc("aid_storage_pool_hits",
+			"Total number of times a free connection was found in the postgres connection pool by role.",
+			[]string{LabelRole}, nil),
+		misses: prometheus.NewDesc("aid_storage_pool_misses",
+			"Total number of times a free connection was not found in the postgres connection pool by role.",
+			[]string{LabelRole}, nil),
+		timeouts: prometheus.NewDesc("aid_storage_pool_timeouts",
+			"Total number of times waiting for a connection of the postgres connection pool timed out by role.",
+			[]string{LabelRole}, nil),
+	}
+)
+
+func init() {
+	prometheus.MustRegister(payloadWrittenBytesCounter, recompressedCounter, compactedCounter, droppedPartitionsCounter, readCounter, poolStats)
+}
+
+// poolCollectTimeout limits getting the connection of a role while collecting pool stats
+const poolCollectTimeout = time.Second
+
+// poolCollector collects the connection pool stats of the postgres client of each role
+type poolCollector struct {
+	lock        sync.Mutex
+	clients     map[string]postgres.Client
+	connections *prometheus.Desc
+	hits        *prometheus.Desc
+	misses      *prometheus.Desc
+	timeouts    *prometheus.Desc
+}
+
+// add collects the pool stats of the client as role, replacing the previous client of the role
+func (c *poolCollector) add(role string, client postgres.Client) {
+	c.lock.Lock()
+	defer c.lock.Unlock()
+	c.clients[role] = client
+}
+
+// Describe implements prometheus.Collector
+func (c *poolCollector) Describe(ch chan<- *prometheus.Desc) {
+	ch <- c.connections
+	ch <- c.hits
+	ch <- c.misses
+	ch <- c.timeouts
+}
+
+// Collect implements prometheus.Collector, roles without a connection are skipped
+func (c *poolCollector) Collect(ch chan<- prometheus.Metric) {
+	c.lock.Lock()
+	defer c.lock.Unlock()
+
+	for role, client := range c.clients {
+		ctx, cancel := context.WithTimeout(context.Background(), poolCollectTimeout)
+		db, err := client.DB(ctx)
+		cancel()
+		if err != nil {
+			continue
+		}
+		stats := db.PoolStats()
+		ch <- prometheus.MustNewConstMetric(c.connections, prometheus.GaugeValue, float64(stats.TotalConns), role, StateTotal)
+		ch <- prometheus.MustNewConstMetric(c.connections, prometheus.GaugeValue, float64(stats.FreeConns), role, StateFree)
+		ch <- prometheus.MustNewConstMetric(c.hits, prometheus.CounterValue, float64(stats.Hits), role)
+		ch <- prometheus.MustNewConstMetric(c.misses, prometheus.CounterValue, float64(stats.Misses), role)
+		ch <- prometheus.MustNewConstMetric(c.timeouts, prometheus.CounterValue, float64(stats.Timeouts), role)
+	}
+}
@@ -15,6 +15,18 @@ type Storage struct {
 	mockedMessageTemplates   []*storage.MessageTemplate
 	mockedMessages           []*storage.Message
 	mockedAidAnalyticsStates []*storage.AidAnalyticsState
+	mockedStateChunks        [][]byte
+	mockedKeywords           []*storage.AidAnalyticsKeyword
+	mockedKeywordUsages      []*storage.KeywordUsage
+	mockedPolicies           []*storage.AidAnalyticsCompactionPolicy
+	mockedCompactions        []*storage.Compaction
+	mockedDeleted            int
+	mockedLease              *storage.AidLease
+	mockedRuns               []*storage.AidRun
+	mockedAuditEvents        []*storage.AuditEvent
+	auditFilter              *storage.AuditFilter
+	listedFrom               *time.Time
+	lastAudit                storage.Audit
 }
 
 // NewMockedStorage returns a new initilized storage mock
@@ -35,6 +47,28 @@ func (s *Storage) Reset() {
 	s.mockedMessageTemplates = []*storage.MessageTemplate{}
 	s.mockedMessages = []*storage.Message{}
 	s.mockedAidAnalyticsStates = []*storage.AidAnalyticsState{}
+	s.mockedStateChunks = nil
+	s.mockedKeywords = nil
+	s.mockedKeywordUsages = nil
+	s.mockedPolicies = nil
+	s.mockedCompactions = nil
+	s.mockedDeleted = 0
+	s.mockedLease = nil
+	s.mockedRuns = nil
+	s.mockedAuditEvents = nil
+	s.auditFilter = nil
+	s.listedFrom = nil
+	s.lastAudit = storage.Audit{}
+}
+
+// ListStatesWithoutDataCalls returns the number of ListStates calls without data
+func (s *Storage) ListStatesWithoutDataCalls() int {
+	return s.calls("ListStatesWithoutData")
+}
+
+// ListMessagesWithoutDataCalls returns the number of ListMessages calls without data
+func (s *Storage) ListMessagesWithoutDataCalls() int {
+	return s.calls("ListMessagesWithoutData")
 }
 
 // FetchMessageTemplatesCalls returns the number of FetchMessageTemplates calls
@@ -57,16 +91,76 @@ func (s *Storage) SaveStateCalls() int {
 	return s.calls("SaveState")
 }
 
+// SaveStateRevisionCalls returns the number of SaveStateRevision calls
+func (s *Storage) SaveStateRevisionCalls() int {
+	return s.calls("SaveStateRevision")
+}
+
+// SaveStateChunksCalls returns the number of SaveStateChunks calls
+func (s *Storage) SaveStateChunksCalls() int {
+	return s.calls("SaveStateChunks")
+}
+
 // GetStateCalls returns the number of GetState calls
 func (s *Storage) GetStateCalls() int {
 	return s.calls("GetState")
 }
 
+// GetLatestStateCalls returns the number of GetLatestState calls
+func (s *Storage) GetLatestStateCalls() int {
+	return s.calls("GetLatestState")
+}
+
 // ListStatesCalls returns the number of ListStates calls
 func (s *Storage) ListStatesCalls() int {
 	return s.calls("ListStates")
 }
 
+// DeleteStatesCalls returns the number of DeleteStates calls
+func (s *Storage) DeleteStatesCalls() int {
+	return s.calls("DeleteStates")
+}
+
+// GetKeywordCalls returns the number of GetKeyword calls
+func (s *Storage) GetKeywordCalls() int {
+	return s.calls("GetKeyword")
+}
+
+// ListKeywordsCalls returns the number of ListKeywords calls
+func (s *Storage) ListKeywordsCalls() int {
+	return s.calls("ListKeywords")
+}
+
+// SaveCompactionPolicyCalls returns the number of SaveCompactionPolicy calls
+func (s *Storage) SaveCompactionPolicyCalls() int {
+	return s.calls("SaveCompactionPolicy")
+}
+
+// CompactCalls returns the number of Compact calls
+func (s *Storage) CompactCalls() int {
+	return s.calls("Compact")
+}
+
+// AcquireLeaseCalls returns the number of AcquireLease calls
+func (s *Storage) AcquireLeaseCalls() int {
+	return s.calls("AcquireLease")
+}
+
+// ReleaseLeaseCalls returns the number of ReleaseLease calls
+func (s *Storage) ReleaseLeaseCalls() int {
+	return s.calls("ReleaseLease")
+}
+
+// StartRunCalls returns the number of StartRun calls
+func (s *Storage) StartRunCalls() int {
+	return s.calls("StartRun")
+}
+
+// FinishRunCalls returns the number of FinishRun calls
+func (s *Storage) FinishRunCalls() int {
+	return s.calls("FinishRun")
+}
+
 // ListMessagesCalls returns the number of ListMessages calls
 func (s *Storage) ListMessagesCalls() int {
 	return s.calls("ListMessages")
@@ -97,16 +191,106 @@ func (s *Storage) MockSaveStateError(err error) {
 	s.mockError("SaveState", err)
 }
 
+// MockSaveStateRevisionError sets the SaveStateRevision mocked error
+func (s *Storage) MockSaveStateRevisionError(err error) {
+	s.mockError("SaveStateRevision", err)
+}
+
+// MockSaveStateChunksError sets the SaveStateChunks mocked error
+func (s *Storage) MockSaveStateChunksError(err error) {
+	s.mockError("SaveStateChunks", err)
+}
+
+// MockGetStateChunkError sets the GetStateChunk mocked error
+func (s *Storage) MockGetStateChunkError(err error) {
+	s.mockError("GetStateChunk", err)
+}
+
 // MockGetStateError sets the GetState mocked error
 func (s *Storage) MockGetStateError(err error) {
 	s.mockError("GetState", err)
 }
 
+// MockGetLatestStateError sets the GetLatestState mocked error
+func (s *Storage) MockGetLatestStateError(err error) {
+	s.mockError("GetLatestState", err)
+}
+
 // MockListStatesError sets the ListStates mocked error
 func (s *Storage) MockListStatesError(err error) {
 	s.mockError("ListStates", err)
 }
 
+// MockDeleteStatesError sets the DeleteStates mocked error
+func (s *Storage) MockDeleteStatesError(err error) {
+	s.mockError("DeleteStates", err)
+}
+
+// MockGetKeywordError sets the GetKeyword mocked error
+func (s *Storage) MockGetKeywordError(err error) {
+	s.mockError("GetKeyword", err)
+}
+
+// MockListKeywordsError sets the ListKeywords mocked error
+func (s *Storage) MockListKeywordsError(err error) {
+	s.mockError("ListKeywords", err)
+}
+
+// MockSaveCompactionPolicyError sets the SaveCompactionPolicy mocked error
+func (s *Storage) MockSaveCompactionPolicyError(err error) {
+	s.mockError("SaveCompactionPolicy", err)
+}
+
+// MockListCompactionPoliciesError sets the ListCompactionPolicies mocked error
+func (s *Storage) MockListCompactionPoliciesError(err error) {
+	s.mockError("ListCompactionPolicies", err)
+}
+
+// MockCompactError sets the Compact mocked error
+func (s *Storage) MockCompactError(err error) {
+	s.mockError("Compact", err)
+}
+
+// MockAcquireLeaseError sets the AcquireLease mocked error
+func (s *Storage) MockAcquireLeaseError(err error) {
+	s.mockError("AcquireLease", err)
+}
+
+// MockRenewLeaseError sets the RenewLease mocked error
+func (s *Storage) MockRenewLeaseError(err error) {
+	s.mockError("RenewLease", err)
+}
+
+// MockReleaseLeaseError sets the ReleaseLease mocked error
+func (s *Storage) MockReleaseLeaseError(err error) {
+	s.mockError("ReleaseLease", err)
+}
+
+// MockStartRunError sets the StartRun mocked error
+func (s *Storage) MockStartRunError(err error) {
+	s.mockError("StartRun", err)
+}
+
+// MockFinishRunError sets the FinishRun mocked error
+func (s *Storage) MockFinishRunError(err error) {
+	s.mockError("FinishRun", err)
+}
+
+// MockListRunsError sets the ListRuns mocked error
+func (s *Storage) MockListRunsError(err error) {
+	s.mockError("ListRuns", err)
+}
+
+// MockLastSuccessfulRunsError sets the LastSuccessfulRuns mocked error
+func (s *Storage) MockLastSuccessfulRunsError(err error) {
+	s.mockError("LastSuccessfulRuns", err)
+}
+
+// MockListAuditEventsError sets the ListAuditEvents mocked error
+func (s *Storage) MockListAuditEventsError(err error) {
+	s.mockError("ListAuditEvents", err)
+}
+
 // MockSavedMessageTemplates sets the message templates stored in mock
 func (s *Storage) MockSavedMessageTemplates(states []*storage.MessageTemplate) {
 	s.mockedMessageTemplates = states
@@ -122,6 +306,71 @@ func (s *Storage) MockSavedStates(states []*storage.AidAnalyticsState) {
 	s.mockedAidAnalyticsStates = states
 }
 
+// MockSavedStateChunks sets the mocked chunks to be returned by calls to GetStateChunk
+func (s *Storage) MockSavedStateChunks(chunks [][]byte) {
+	s.mockedStateChunks = chunks
+}
+
+// SavedStateChunks returns the chunks passed to the last SaveStateChunks call
+func (s *Storage) SavedStateChunks() [][]byte {
+	return s.mockedStateChunks
+}
+
+// MockDeletedStates sets the number of states returned by calls to DeleteStates
+func (s *Storage) MockDeletedStates(deleted int) {
+	s.mockedDeleted = deleted
+}
+
+// MockSavedKeywords sets the registered keywords returned by calls to GetKeyword
+func (s *Storage) MockSavedKeywords(keywords []*storage.AidAnalyticsKeyword) {
+	s.mockedKeywords = keywords
+}
+
+// MockSavedKeywordUsages sets the keyword usages returned by calls to ListKeywords
+func (s *Storage) MockSavedKeywordUsages(usages []*storage.KeywordUsage) {
+	s.mockedKeywordUsages = usages
+}
+
+// MockCompactions sets the compactions returned by calls to Compact
+func (s *Storage) MockCompactions(compactions []*storage.Compaction) {
+	s.mockedCompactions = compactions
+}
+
+// SavedCompactionPolicies returns the policies saved with SaveCompactionPolicy
+func (s *Storage) SavedCompactionPolicies() []*storage.AidAnalyticsCompactionPolicy {
+	return s.mockedPolicies
+}
+
+// MockLease sets the lease returned by calls to AcquireLease and RenewLease
+func (s *Storage) MockLease(lease *storage.AidLease) {
+	s.mockedLease = lease
+}
+
+// MockSavedRuns sets the runs returned by calls to StartRun, FinishRun, ListRuns and LastSuccessfulRuns
+func (s *Storage) MockSavedRuns(runs []*storage.AidRun) {
+	s.mockedRuns = runs
+}
+
+// MockAuditEvents sets the audit events returned by calls to ListAuditEvents
+func (s *Storage) MockAuditEvents(events []*storage.AuditEvent) {
+	s.mockedAuditEvents = events
+}
+
+// ListedAuditFilter returns the filter of the last ListAuditEvents call
+func (s *Storage) ListedAuditFilter() *storage.AuditFilter {
+	return s.auditFilter
+}
+
+// ListedFrom returns the from bound of the last ListMessages or ListStates call
+func (s *Storage) ListedFrom() *time.Time {
+	return s.listedFrom
+}
+
+// LastAudit returns the audit of the context of the last mutating call, see storage.WithAudit
+func (s *Storage) LastAudit() storage.Audit {
+	return s.lastAudit
+}
+
 // FetchMessageTemplates returns all mocked message templates for a given type up to max version
 func (s *Storage) FetchMessageTemplates(ctx context.Context, mType string, maxVersion int32) ([]*storage.MessageTemplate, error) {
 	s.called("FetchMessageTemplates")
@@ -142,6 +391,7 @@ func (s *Storage) FetchMessageTemplates(ctx context.Context, mType string, maxVe
 // CreateMessage returns an error if mocked
 func (s *Storage) CreateMessage(ctx context.Context, msg *storage.Message) error {
 	s.called("CreateMessage")
+	s.audited(ctx)
 	if err := s.mockedErrors["CreateMessage"]; err != nil {
 		return err
 	}
@@ -150,8 +400,12 @@ func (s *Storage) CreateMessage(ctx context.Context, msg *storage.Message) error
 }
 
 // ListMessages returns an error if mocked
-func (s *Storage) ListMessages(ctx context.Context, appID int32, keyword string, minVersion, maxVersion int32, from, to *time.Time) ([]*storage.Message, error) {
+func (s *Storage) ListMessages(ctx context.Context, appID int32, keyword string, minVersion, maxVersion int32, from, to *time.Time, opts ...storage.ListOption) ([]*storage.Message, error) {
 	s.called("ListMessages")
+	s.listedFrom = from
+	if withoutData(opts) {
+		s.called("ListMessagesWithoutData")
+	}
 	if err := s.mockedErrors["ListMessages"]; err != nil {
 		return nil, err
 	}
@@ -161,6 +415,7 @@ func (s *Storage) ListMessages(ctx context.Context, appID int32, keyword string,
 // CreateMessageTemplate returns an error if mocked
 func (s *Storage) CreateMessageTemplate(ctx context.Context, tmpl *storage.MessageTemplate) error {
 	s.called("CreateMessageTemplate")
+	s.audited(ctx)
 	if err := s.mockedErrors["CreateMessageTemplate"]; err != nil {
 		return err
 	}
@@ -172,6 +427,7 @@ func (s *Storage) CreateMessageTemplate(ctx context.Context, tmpl *storage.Messa
 // SaveState returns an error if mocked
 func (s *Storage) SaveState(ctx context.Context, state *storage.AidAnalyticsState) error {
 	s.called("SaveState")
+	s.audited(ctx)
 	if err := s.mockedErrors["SaveState"]; err != nil {
 		return err
 	}
@@ -179,6 +435,41 @@ func (s *Storage) SaveState(ctx context.Context, state *storage.AidAnalyticsStat
 	return nil
 }
 
+// SaveStateRevision returns an error if mocked
+func (s *Storage) SaveStateRevision(ctx context.Context, state *storage.AidAnalyticsState, expectedRevision int32) error {
+	s.called("SaveStateRevision")
+	s.audited(ctx)
+	if err := s.mockedErrors["SaveStateRevision"]; err != nil {
+		return err
+	}
+	s.copy(s.mockedAidAnalyticsStates[0], state)
+	return nil
+}
+
+// SaveStateChunks stores the chunks in the mock or returns an error if mocked
+func (s *Storage) SaveStateChunks(ctx context.Context, state *storage.AidAnalyticsState, chunks [][]byte, expectedRevision int32, force bool) error {
+	s.called("SaveStateChunks")
+	s.audited(ctx)
+	if err := s.mockedErrors["SaveStateChunks"]; err != nil {
+		return err
+	}
+	s.mockedStateChunks = chunks
+	s.copy(s.mockedAidAnalyticsStates[0], state)
+	return nil
+}
+
+// GetStateChunk returns a mocked chunk or an error if mocked
+func (s *Storage) GetStateChunk(ctx context.Context, stateID int32, seq int32) ([]byte, error) {
+	s.called("GetStateChunk")
+	if err := s.mockedErrors["GetStateChunk"]; err != nil {
+		return nil, err
+	}
+	if int(seq) >= len(s.mockedStateChunks) {
+		return nil, storage.ErrNotFound
+	}
+	return s.mockedStateChunks[seq], nil
+}
+
 // GetState returns an error if mocked
 func (s *Storage) GetState(ctx context.Context, state *storage.AidAnalyticsState) error {
 	s.called("GetState")
@@ -189,15 +480,167 @@ func (s *Storage) GetState(ctx context.Context, state *storage.AidAnalyticsState
 	return nil
 }
 
+// GetLatestState returns the first mocked state or an error if mocked
+func (s *Storage) GetLatestState(ctx context.Context, appID int32, keyword string, asOf *time.Time) (*storage.AidAnalyticsState, error) {
+	s.called("GetLatestState")
+	if err := s.mockedErrors["GetLatestState"]; err != nil {
+		return nil, err
+	}
+	state := &storage.AidAnalyticsState{}
+	s.copy(s.mockedAidAnalyticsStates[0], state)
+	return state, nil
+}
+
 // ListStates returns an error if mocked
-func (s *Storage) ListStates(ctx context.Context, appID int32, keyword string, from, to *time.Time) ([]*storage.AidAnalyticsState, error) {
+func (s *Storage) ListStates(ctx context.Context, appID int32, keyword string, from, to *time.Time, opts ...storage.ListOption) ([]*storage.AidAnalyticsState, error) {
 	s.called("ListStates")
+	s.listedFrom = from
+	if withoutData(opts) {
+		s.called("ListStatesWithoutData")
+	}
 	if err := s.mockedErrors["ListStates"]; err != nil {
 		return nil, err
 	}
 	return s.mockedAidAnalyticsStates, nil
 }
 
+// DeleteStates returns the mocked number of deleted states or an error if mocked
+func (s *Storage) DeleteStates(ctx context.Context, appID int32, keyword string, from, to time.Time, dryRun bool) (int, error) {
+	s.called("DeleteStates")
+	s.audited(ctx)
+	if err := s.mockedErrors["DeleteStates"]; err != nil {
+		return 0, err
+	}
+	return s.mockedDeleted, nil
+}
+
+// GetKeyword returns a mocked keyword, ErrNotFound if the keyword is not mocked or an error if mocked
+func (s *Storage) GetKeyword(ctx context.Context, keyword string) (*storage.AidAnalyticsKeyword, error) {
+	s.called("GetKeyword")
+	if err := s.mockedErrors["GetKeyword"]; err != nil {
+		return nil, err
+	}
+	for _, k := range s.mockedKeywords {
+		if k.Keyword == keyword {
+			return k, nil
+		}
+	}
+	return nil, storage.ErrNotFound
+}
+
+// ListKeywords returns the mocked keyword usages or an error if mocked
+func (s *Storage) ListKeywords(ctx context.Context, appID int32) ([]*storage.KeywordUsage, error) {
+	s.called("ListKeywords")
+	if err := s.mockedErrors["ListKeywords"]; err != nil {
+		return nil, err
+	}
+	return s.mockedKeywordUsages, nil
+}
+
+// SaveCompactionPolicy stores the policy in the mock or returns an error if mocked
+func (s *Storage) SaveCompactionPolicy(ctx context.Context, policy *storage.AidAnalyticsCompactionPolicy) error {
+	s.called("SaveCompactionPolicy")
+	s.audited(ctx)
+	if err := s.mockedErrors["SaveCompactionPolicy"]; err != nil {
+		return err
+	}
+	s.mockedPolicies = append(s.mockedPolicies, policy)
+	return nil
+}
+
+// ListCompactionPolicies returns the saved policies or an error if mocked
+func (s *Storage) ListCompactionPolicies(ctx context.Context) ([]*storage.AidAnalyticsCompactionPolicy, error) {
+	s.called("ListCompactionPolicies")
+	if err := s.mockedErrors["ListCompactionPolicies"]; err != nil {
+		return nil, err
+	}
+	return s.mockedPolicies, nil
+}
+
+// Compact returns the mocked compactions or an error if mocked
+func (s *Storage) Compact(ctx context.Context, keyword string, dryRun bool) ([]*storage.Compaction, error) {
+	s.called("Compact")
+	s.audited(ctx)
+	if err := s.mockedErrors["Compact"]; err != nil {
+		return nil, err
+	}
+	return s.mockedCompactions, nil
+}
+
+// AcquireLease returns the mocked lease or an error if mocked
+func (s *Storage) AcquireLease(ctx context.Context, lease *storage.AidLease, ttl time.Duration) error {
+	s.called("AcquireLease")
+	if err := s.mockedErrors["AcquireLease"]; err != nil {
+		return err
+	}
+	s.copy(s.mockedLease, lease)
+	return nil
+}
+
+// RenewLease returns the mocked lease or an error if mocked
+func (s *Storage) RenewLease(ctx context.Context, lease *storage.AidLease, ttl time.Duration) error {
+	s.called("RenewLease")
+	if err := s.mockedErrors["RenewLease"]; err != nil {
+		return err
+	}
+	s.copy(s.mockedLease, lease)
+	return nil
+}
+
+// ReleaseLease returns an error if mocked
+func (s *Storage) ReleaseLease(ctx context.Context, lease *storage.AidLease) error {
+	s.called("ReleaseLease")
+	return s.mockedErrors["ReleaseLease"]
+}
+
+// StartRun returns the first mocked run or an error if mocked
+func (s *Storage) StartRun(ctx context.Context, run *storage.AidRun) error {
+	s.called("StartRun")
+	if err := s.mockedErrors["StartRun"]; err != nil {
+		return err
+	}
+	s.copy(s.mockedRuns[0], run)
+	return nil
+}
+
+// FinishRun returns the first mocked run or an error if mocked
+func (s *Storage) FinishRun(ctx context.Context, run *storage.AidRun) error {
+	s.called("FinishRun")
+	if err := s.mockedErrors["FinishRun"]; err != nil {
+		return err
+	}
+	s.copy(s.mockedRuns[0], run)
+	return nil
+}
+
+// ListRuns returns the mocked runs or an error if mocked
+func (s *Storage) ListRuns(ctx context.Context, filter *storage.RunFilter) ([]*storage.AidRun, error) {
+	s.called("ListRuns")
+	if err := s.mockedErrors["ListRuns"]; err != nil {
+		return nil, err
+	}
+	return s.mockedRuns, nil
+}
+
+// LastSuccessfulRuns returns the mocked runs or an error if mocked
+func (s *Storage) LastSuccessfulRuns(ctx context.Context, appID int32, pipeline string) ([]*storage.AidRun, error) {
+	s.called("LastSuccessfulRuns")
+	if err := s.mockedErrors["LastSuccessfulRuns"]; err != nil {
+		return nil, err
+	}
+	return s.mockedRuns, nil
+}
+
+// ListAuditEvents returns the mocked audit events or an error if mocked
+func (s *Storage) ListAuditEvents(ctx context.Context, filter *storage.AuditFilter) ([]*storage.AuditEvent, error) {
+	s.called("ListAuditEvents")
+	s.auditFilter = filter
+	if err := s.mockedErrors["ListAuditEvents"]; err != nil {
+		return nil, err
+	}
+	return s.mockedAuditEvents, nil
+}
+
 // calls returns the number of calls made to the given method since last reset
 func (s *Storage) calls(method string) int {
 	return s.mockCallCounts[method]
@@ -211,6 +654,19 @@ func (s *Storage) called(method string) {
 	s.mockCallCounts[method] = s.mockCallCounts["method"] + 1
 }
 
+func (s *Storage) audited(ctx context.Context) {
+	s.lastAudit = storage.AuditFromContext(ctx)
+}
+
+func withoutData(opts []storage.ListOption) bool {
+	for _, opt := range opts {
+		if opt == storage.WithoutData {
+			return true
+		}
+	}
+	return false
+}
+
 func (s *Storage) copy(src, dst interface{}) {
 	data, _ := json.Marshal(src)
 	json.Unmarshal(data, dst)
@@ -19,13 +19,180 @@ type Message struct {
 	Template    *MessageTemplate `pg:",fk:Template"`
 	GeneratedAt time.Time
 	Data        []byte
+	Codec       string // codec Data is stored with, Data is always decompressed when read
+	RunID       int32  // 0 if created outside a run
 }
 
 // AidAnalyticsState defines the structure of a message as stored in postgres
 type AidAnalyticsState struct {
-	ID      int32
+	ID         int32
+	AppID      int32
+	Keyword    string
+	Data       []byte `sql:",notnull"` // empty for chunked states
+	SavedAt    time.Time
+	Revision   int32
+	ChunkCount int32
+	Checksum   string
+	Codec      string // codec Data is stored with, Data is always decompressed when read
+
+	SchemaVersion int32
+
+	// FencingToken of the lease the state is saved under, 0 if saved without a lease
+	FencingToken int64 `sql:"-"`
+
+	RunID int32 // 0 if saved outside a run
+}
+
+// AidAnalyticsStateChunk defines the structure of a chunk of a chunked state as stored in postgres
+type AidAnalyticsStateChunk struct {
+	StateID int32 `sql:",pk"`
+	Seq     int32 `sql:",pk,notnull"`
+	Data    []byte
+	Codec   string
+}
+
+// AidAnalyticsKeyword defines the structure of a registered state keyword as stored in postgres
+type AidAnalyticsKeyword struct {
+	Keyword       string `sql:",pk"`
+	ContentType   string
+	SchemaVersion int32
+	MaxSize       int32 // 0 if unlimited
+	Owner         string
+	CreatedAt     time.Time
+}
+
+// KeywordUsage defines the use of a keyword by an app, joined with the registered keyword if any
+type KeywordUsage struct {
+	AidAnalyticsKeyword
+	Registered    bool
+	StateCount    int32
+	LatestSavedAt time.Time
+}
+
+// StateFreshness defines when states were last saved for an app and keyword
+type StateFreshness struct {
+	AppID         int32
+	Keyword       string
+	LatestSavedAt time.Time
+}
+
+// AidAnalyticsCompactionPolicy defines how the state history of a keyword is compacted as stored in postgres.
+// Rules set to 0 are disabled.
+type AidAnalyticsCompactionPolicy struct {
+	Keyword            string `sql:",pk"`
+	KeepLast           int32  `sql:",notnull"` // newest states per app always kept, states beyond are deleted unless kept daily
+	KeepDailyAfterDays int32  `sql:",notnull"` // states older than this are thinned to the newest state per day
+	DeleteAfterDays    int32  `sql:",notnull"` // states older than this are deleted
+	UpdatedAt          time.Time
+}
+
+// Compaction defines the number of states deleted by compacting the states of an app and keyword
+type Compaction struct {
 	AppID   int32
 	Keyword string
-	Data    []byte
-	SavedAt time.Time
+	Deleted int32
+}
+
+// AidLease defines the structure of a named per-app lease as stored in postgres
+type AidLease struct {
+	AppID        int32  `sql:",pk"`
+	Name         string `sql:",pk"`
+	Holder       string
+	FencingToken int64
+	ExpiresAt    time.Time
+}
+
+// Run statuses
+const (
+	RunStatusRunning   = "running"
+	RunStatusSucceeded = "succeeded"
+	RunStatusFailed    = "failed"
+)
+
+// AidRun defines the structure of an analytics pipeline run as stored in postgres
+type AidRun struct {
+	ID         int32
+	AppID      int32
+	Pipeline   string
+	Status     string
+	Error      string
+	Counters   map[string]int64
+	StartedAt  time.Time
+	FinishedAt time.Time // zero while running
+
+	// number of messages and states tagged with the run, only set when listing runs
+	MessageCount int32 `sql:"-"`
+	StateCount   int32 `sql:"-"`
+}
+
+// ListOption changes what ListMessages and ListStates select, by default messages and states are listed with their data
+type ListOption int
+
+// List options
+const (
+	// WithoutData lists messages and states without their data, which is then not read from Postgres at all
+	WithoutData ListOption = iota + 1
+)
+
+// withData returns true unless the options include WithoutData
+func withData(opts []ListOption) bool {
+	for _, opt := range opts {
+		if opt == WithoutData {
+			return false
+		}
+	}
+	return true
+}
+
+// RunFilter defines the runs to list, zero values include all runs
+type RunFilter struct {
+	AppID           int32
+	Pipeline        string
+	Status          string
+	StartedFrom     *time.Time
+	StartedTo       *time.Time
+	WithoutMessages bool // only finished runs that produced no messages
+}
+
+// Audited actions
+const (
+	AuditTemplateCreate       = "template.create"
+	AuditTemplateImport       = "template.import"
+	AuditMessageCreate        = "message.create"
+	AuditMessageDelete        = "message.delete"
+	AuditMessageImport        = "message.import"
+	AuditStateSave            = "state.save"
+	AuditStateDelete          = "state.delete"
+	AuditStateCompact         = "state.compact"
+	AuditStateImport          = "state.import"
+	AuditCompactionPolicySave = "compaction_policy.save"
+	AuditPartitionDrop        = "partition.drop"
+)
+
+// AuditEvent defines a mutation of templates, messages, states, compaction policies or partitions as stored in the
+// append-only audit_events table.
+// Events are written in the transaction of their mutation, see WithAudit for their actor, RPC and reason.
+type AuditEvent struct {
+	ID         int64
+	OccurredAt time.Time
+	Actor      string
+	RPC        string
+	AppID      int32 `sql:",notnull"` // 0 for templates, policies, partitions and compactions of all apps
+	Action     string
+	Target     string // template type:version, message id, state keyword@saved_at, keyword or partition, empty for imports
+	Count      int32  // number of mutated rows, e.g. the deleted states of compactions or the imported rows of an app
+	BeforeHash string // hex encoded SHA-256 of the payload before the mutation, empty if created
+	AfterHash  string // hex encoded SHA-256 of the payload after the mutation, empty if deleted
+	Reason     string
+}
+
+// AuditFilter defines the audit events to list, zero values include all events
+type AuditFilter struct {
+	AppID  int32
+	Actor  string
+	Action string
+	Target string
+	From   *time.Time
+	To     *time.Time
+	Limit  int // newest events to list, 0 for all
 }
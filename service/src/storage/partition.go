@@ -0,0 +1,185 @@
+package storage
+
+import (
+	"context"
+	"fmt"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/callstats-io/go-common/log"
+	"github.com/go-pg/pg"
+	"github.com/go-pg/pg/orm"
+)
+
+// partitionedTables are the tables partitioned by month of their time column, messages by generated_at and states by saved_at
+var partitionedTables = []string{tableMessages, tableStates}
+
+// PartitionName returns the name of the monthly partition of a table holding the rows of the UTC month of t,
+// e.g. messages_y2018m06
+func PartitionName(table string, t time.Time) string {
+	t = t.UTC()
+	return fmt.Sprintf("%s_y%04dm%02d", table, t.Year(), t.Month())
+}
+
+// partitionMonth returns the month of a partition of a table parsed from its name
+func partitionMonth(table, name string) (time.Time, bool) {
+	var year, month int
+	if !strings.HasPrefix(name, table+"_") {
+		return time.Time{}, false
+	}
+	if _, err := fmt.Sscanf(name[len(table)+1:], "y%4dm%2d", &year, &month); err != nil || month < 1 || month > 12 {
+		return time.Time{}, false
+	}
+	return time.Date(year, time.Month(month), 1, 0, 0, 0, 0, time.UTC), true
+}
+
+// monthStart returns the start of the UTC month of t
+func monthStart(t time.Time) time.Time {
+	t = t.UTC()
+	return time.Date(t.Year(), t.Month(), 1, 0, 0, 0, 0, time.UTC)
+}
+
+// partitionSet remembers the partitions known to exist to create each partition once
+type partitionSet struct {
+	lock  sync.Mutex
+	names map[string]bool
+}
+
+func newPartitionSet() *partitionSet {
+	return &partitionSet{names: map[string]bool{}}
+}
+
+func (p *partitionSet) has(name string) bool {
+	p.lock.Lock()
+	defer p.lock.Unlock()
+	return p.names[name]
+}
+
+func (p *partitionSet) set(name string, exists bool) {
+	p.lock.Lock()
+	defer p.lock.Unlock()
+	if exists {
+		p.names[name] = true
+	} else {
+		delete(p.names, name)
+	}
+}
+
+// partition returns the partition of a table rows at t are inserted into, creating the partition if it does not exist.
+// Rows without a time are inserted into the table itself to fail its not-null constraint.
+func (s *Postgres) partition(db orm.DB, table string, t time.Time) (string, error) {
+	if t.IsZero() {
+		return table, nil
+	}
+	name := PartitionName(table, t)
+	if s.partitions.has(name) {
+		return name, nil
+	}
+	if _, err := db.Exec("SELECT aid_create_partition(?, ?)", table, monthStart(t).Format("2006-01-02")); err != nil {
+		return "", err
+	}
+	s.partitions.set(name, true)
+	return name, nil
+}
+
+// EnsurePartitions creates the partitions of all partitioned tables for the month of t and the following month
+// if they do not exist yet, so that inserts around the turn of the month do not have to create them.
+func (s *Postgres) EnsurePartitions(ctx context.Context, t time.Time) error {
+	db, err := s.db(ctx)
+	if err != nil {
+		return err
+	}
+
+	month := monthStart(t)
+	for _, table := range partitionedTables {
+		for _, m := range []time.Time{month, month.AddDate(0, 1, 0)} {
+			if _, err := s.partition(db, table, m); err != nil {
+				return classify(err)
+			}
+		}
+	}
+	return nil
+}
+
+// DropPartitions drops the partitions of all partitioned tables of months before the month of before,
+// removing their rows at once instead of deleting them row by row. Chunks of dropped states are deleted with them.
+// Rows stored in the partitioned tables themselves are kept. Every drop is audited in the same transaction with the
+// number of rows of the partition. Returns the names of the dropped partitions.
+func (s *Postgres) DropPartitions(ctx context.Context, before time.Time) ([]string, error) {
+	if err := checkAudit(ctx); err != nil {
+		return nil, err
+	}
+	db, err := s.db(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	var dropped []string
+	for _, table := range partitionedTables {
+		var names pg.Strings
+		if _, err := db.Query(&names, `
+			SELECT c.relname FROM pg_inherits i
+			JOIN pg_class c ON c.oid = i.inhrelid
+			WHERE i.inhparent = ?::regclass
+			ORDER BY c.relname`, table); err != nil {
+			return dropped, classify(err)
+		}
+		for _, name := range names {
+			month, ok := partitionMonth(table, name)
+			if !ok || !month.Before(monthStart(before)) {
+				continue
+			}
+			err := db.RunInTransaction(func(tx *pg.Tx) error {
+				event := newAuditEvent(ctx, 0, AuditPartitionDrop, name)
+				if _, err := tx.QueryOne(pg.Scan(&event.Count), "SELECT count(*) FROM ?", pg.F(name)); err != nil {
+					return err
+				}
+				if _, err := tx.Exec("SELECT aid_drop_partition(?, ?)", table, month.Format("2006-01-02")); err != nil {
+					return err
+				}
+				return insertAuditEvents(tx, event)
+			})
+			if err != nil {
+				return dropped, classify(err)
+			}
+			s.partitions.set(name, false)
+			droppedPartitionsCounter.WithLabelValues(table).Inc()
+			dropped = append(dropped, name)
+		}
+	}
+	if len(dropped) > 0 {
+		// reads of all apps must not see the dropped rows on a lagging replica
+		s.writes.record(0)
+	}
+	return dropped, nil
+}
+
+// RunPartitionMaintenance creates the partitions of the current and the next month every interval until the context is done.
+// If retention months is positive, partitions of months older than the retention are dropped, audited as made by
+// AuditActorSystem.
+func (s *Postgres) RunPartitionMaintenance(ctx context.Context, interval time.Duration, retentionMonths int) {
+	ctx = WithAudit(ctx, Audit{Actor: AuditActorSystem, Reason: "partition retention"})
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+	for {
+		now := time.Now()
+		if err := s.EnsurePartitions(ctx, now); err != nil {
+			log.FromContext(ctx).Warn("failed to create partitions", log.Error(err))
+		}
+		if retentionMonths > 0 {
+			dropped, err := s.DropPartitions(ctx, monthStart(now).AddDate(0, -retentionMonths, 0))
+			for _, name := range dropped {
+				log.FromContext(ctx).Info("dropped partition", log.String("partition", name))
+			}
+			if err != nil {
+				log.FromContext(ctx).Warn("failed to drop partitions", log.Error(err))
+			}
+		}
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+		}
+	}
+}
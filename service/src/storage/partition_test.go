@@ -0,0 +1,125 @@
+package storage_test
+
+import (
+	"context"
+	"fmt"
+	"math/rand"
+	"testing"
+	"time"
+
+	"github.com/callstats-io/ai-decision/service/src/storage"
+	"github.com/callstats-io/go-common/testutil"
+	"github.com/go-pg/pg"
+	"github.com/stretchr/testify/require"
+)
+
+func TestPartitionName(t *testing.T) {
+	assert := require.New(t)
+
+	assert.Equal("messages_y2018m06", storage.PartitionName("messages", time.Date(2018, 6, 30, 23, 0, 0, 0, time.UTC)))
+	// partitions are by UTC month
+	helsinki := time.FixedZone("EET", 2*60*60)
+	assert.Equal("aid_analytics_states_y2018m06", storage.PartitionName("aid_analytics_states", time.Date(2018, 7, 1, 1, 0, 0, 0, helsinki)))
+}
+
+func TestPartitionedInserts(t *testing.T) {
+	assert := require.New(t)
+	s := storage.NewPostgres(testPostgresClient)
+	mType := fmt.Sprintf("partition-%d", rand.Int())
+	tmpl := &storage.MessageTemplate{Type: mType, Version: 1, Template: "{.String \"val1\"}"}
+	_, err := testPostgresDB.Model(tmpl).Returning("*").Insert()
+	assert.Nil(err)
+	generatedAt := time.Date(2017, 3, 31, 23, 59, 0, 0, time.UTC)
+
+	assert.Nil(testutil.WithDeadlineContext(5*time.Second, func(ctx context.Context) {
+		msg := &storage.Message{AppID: 123, TemplateID: tmpl.ID, GeneratedAt: generatedAt, Data: []byte(`{"val1":"abc"}`)}
+		assert.Nil(s.CreateMessage(ctx, msg))
+		assert.True(msg.ID > 0)
+		var count int
+		_, err := testPostgresDB.QueryOne(pg.Scan(&count), "SELECT count(*) FROM ONLY messages_y2017m03 WHERE id = ?", msg.ID)
+		assert.Nil(err)
+		assert.Equal(1, count)
+
+		// uniqueness is kept within the partition
+		err = s.CreateMessage(ctx, &storage.Message{AppID: 123, TemplateID: tmpl.ID, GeneratedAt: generatedAt, Data: []byte(`{"val1":"abc"}`)})
+		assert.NotNil(err)
+		assert.Contains(err.Error(), "message_uniqueness_idx")
+
+		messages, err := s.ListMessages(ctx, 123, mType, 0, 0, &generatedAt, nil)
+		assert.Nil(err)
+		assert.Len(messages, 1)
+		assert.Equal(msg.ID, messages[0].ID)
+
+		state := &storage.AidAnalyticsState{AppID: 123, Keyword: mType, SavedAt: generatedAt, Data: []byte(`{}`)}
+		assert.Nil(s.SaveState(ctx, state))
+		assert.Equal(int32(1), state.Revision)
+		assert.Nil(s.SaveState(ctx, state))
+		assert.Equal(int32(2), state.Revision)
+		assert.Equal(storage.ErrRevisionMismatch, s.SaveStateRevision(ctx, state, 0))
+		assert.Nil(s.SaveStateRevision(ctx, state, 2))
+		assert.Equal(int32(3), state.Revision)
+	}))
+}
+
+func TestDropPartitions(t *testing.T) {
+	assert := require.New(t)
+	s := storage.NewPostgres(testPostgresClient)
+	keyword := fmt.Sprintf("partition-%d", rand.Int())
+	old := time.Date(2001, 1, 15, 0, 0, 0, 0, time.UTC)
+	kept := time.Date(2001, 2, 1, 0, 0, 0, 0, time.UTC)
+
+	assert.Nil(testutil.WithDeadlineContext(5*time.Second, func(ctx context.Context) {
+		assert.Nil(s.EnsurePartitions(ctx, old))
+		// creating existing partitions is a no-op
+		assert.Nil(s.EnsurePartitions(ctx, old))
+
+		chunked := &storage.AidAnalyticsState{AppID: 123, Keyword: keyword, SavedAt: old}
+		assert.Nil(s.SaveStateChunks(ctx, chunked, [][]byte{[]byte(`{"a":`), []byte(`1}`)}, 0, true))
+		state := &storage.AidAnalyticsState{AppID: 123, Keyword: keyword, SavedAt: kept, Data: []byte(`{}`)}
+		assert.Nil(s.SaveState(ctx, state))
+
+		dropCtx := storage.WithAudit(ctx, storage.Audit{Actor: storage.AuditActorSystem, Reason: keyword})
+		dropped, err := s.DropPartitions(dropCtx, kept)
+		assert.Nil(err)
+		assert.Equal([]string{"messages_y2001m01", "aid_analytics_states_y2001m01"}, dropped)
+		events, err := s.ListAuditEvents(ctx, &storage.AuditFilter{Action: storage.AuditPartitionDrop, Target: "aid_analytics_states_y2001m01", Limit: 1})
+		assert.Nil(err)
+		assert.Equal(storage.AuditActorSystem, events[0].Actor)
+		assert.Equal(keyword, events[0].Reason)
+		assert.True(events[0].Count > 0)
+
+		states, err := s.ListStates(ctx, 123, keyword, nil, nil)
+		assert.Nil(err)
+		assert.Len(states, 1)
+		assert.Equal(state.ID, states[0].ID)
+		_, err = s.GetStateChunk(ctx, chunked.ID, 0)
+		assert.Equal(storage.ErrNotFound, err)
+
+		// dropped partitions are created again when needed
+		assert.Nil(s.SaveState(ctx, &storage.AidAnalyticsState{AppID: 123, Keyword: keyword, SavedAt: old, Data: []byte(`{}`)}))
+		dropped, err = s.DropPartitions(ctx, kept)
+		assert.Nil(err)
+		assert.Equal([]string{"aid_analytics_states_y2001m01"}, dropped)
+	}))
+}
+
+func TestStateChunksReferenceStates(t *testing.T) {
+	assert := require.New(t)
+	s := storage.NewPostgres(testPostgresClient)
+	keyword := fmt.Sprintf("partition-%d", rand.Int())
+
+	assert.Nil(testutil.WithDeadlineContext(5*time.Second, func(ctx context.Context) {
+		state := &storage.AidAnalyticsState{AppID: 123, Keyword: keyword, SavedAt: time.Now()}
+		assert.Nil(s.SaveStateChunks(ctx, state, [][]byte{[]byte(`{}`)}, 0, true))
+
+		// chunks of states that do not exist are rejected by the check replacing the dropped foreign key
+		_, err := testPostgresDB.Model(&storage.AidAnalyticsStateChunk{StateID: -state.ID, Seq: 0, Data: []byte(`{}`)}).Insert()
+		pgErr, ok := err.(pg.Error)
+		assert.True(ok, "%v", err)
+		assert.Equal("23503", pgErr.Field('C'))
+		assert.Equal("aid_check_state_chunk_state", pgErr.Field('n'))
+
+		_, err = testPostgresDB.Model(&storage.AidAnalyticsStateChunk{StateID: state.ID, Seq: 1, Data: []byte(`{}`)}).Insert()
+		assert.Nil(err)
+	}))
+}
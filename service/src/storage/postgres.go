@@ -2,25 +2,43 @@ package storage
 
 import (
 	"context"
-	"errors"
+	"reflect"
 	"time"
 
 	"github.com/callstats-io/go-common/log"
 	"github.com/callstats-io/go-common/postgres"
+	"github.com/go-pg/pg"
+	"github.com/go-pg/pg/orm"
 )
 
 // Postgres defines a postgres backed storage
 type Postgres struct {
-	pgClient postgres.Client
+	pgClient             postgres.Client
+	readClient           postgres.Client // nil if reads use pgClient, see WithReadReplica
+	maxStaleness         time.Duration
+	writes               *writeTracker
+	partitions           *partitionSet
+	compressionThreshold int
 }
 
 // NewPostgres returns a new postgres backed storage with the specified client
 func NewPostgres(pgClient postgres.Client) *Postgres {
+	poolStats.add(RoleReadWrite, pgClient)
 	return &Postgres{
-		pgClient: pgClient,
+		pgClient:             pgClient,
+		writes:               newWriteTracker(),
+		partitions:           newPartitionSet(),
+		compressionThreshold: DefaultCompressionThreshold,
 	}
 }
 
+// WithCompressionThreshold sets the payload size in bytes from which state and message payloads are compressed.
+// A non-positive threshold disables compression of new payloads, existing payloads are still decompressed on read.
+func (s *Postgres) WithCompressionThreshold(threshold int) *Postgres {
+	s.compressionThreshold = threshold
+	return s
+}
+
 // FetchMessageTemplates returns all message templates matching to a given type up to the specified version.
 // If maxVersion is zero, all versions are returned.
 func (s *Postgres) FetchMessageTemplates(ctx context.Context, mType string, maxVersion int32) ([]*MessageTemplate, error) {
@@ -35,7 +53,7 @@ func (s *Postgres) FetchMessageTemplates(ctx context.Context, mType string, maxV
 		query = query.Where("version <= ?", maxVersion)
 	}
 	if err := query.Select(); err != nil {
-		return nil, err
+		return nil, classify(err)
 	}
 
 	if len(templates) == 0 {
@@ -47,14 +65,42 @@ func (s *Postgres) FetchMessageTemplates(ctx context.Context, mType string, maxV
 }
 
 // CreateMessage adds a new message to postgres. The message validation is expected to be performed before calling this function.
+// The message is inserted into the partition of the month it was generated in and audited in the same transaction.
+// Returns ErrUnknownRun if the message is tagged with a run that does not exist.
 func (s *Postgres) CreateMessage(ctx context.Context, msg *Message) error {
+	if err := checkAudit(ctx); err != nil {
+		return err
+	}
 	db, err := s.db(ctx)
 	if err != nil {
 		return err
 	}
-	if _, err := db.Model(msg).Returning("*").Insert(); err != nil {
+	partition, err := s.partition(db, tableMessages, msg.GeneratedAt)
+	if err != nil {
+		return classify(err)
+	}
+	data := msg.Data
+	if msg.Codec, msg.Data, err = s.encode(tableMessages, data); err != nil {
 		return err
 	}
+	err = db.RunInTransaction(func(tx *pg.Tx) error {
+		if _, err := tx.QueryOne(msg, `
+			INSERT INTO ? (app_id, template_id, generated_at, data, codec, run_id)
+			VALUES (?app_id, ?template_id, ?generated_at, ?data, ?codec, ?run_id)
+			RETURNING *`, pg.F(partition), msg); err != nil {
+			return err
+		}
+		event := newAuditEvent(ctx, msg.AppID, AuditMessageCreate, messageTarget(msg.ID))
+		event.AfterHash = auditHash(data)
+		return insertAuditEvents(tx, event)
+	})
+	msg.Data = data
+	if isRunForeignKeyViolation(err) {
+		return ErrUnknownRun
+	} else if err != nil {
+		return classify(err)
+	}
+	s.writes.record(msg.AppID)
 	return nil
 }
 
@@ -62,15 +108,24 @@ func (s *Postgres) CreateMessage(ctx context.Context, msg *Message) error {
 // If message type is provided, all messages must additionally have the type of template
 // If minVersion and/or maxVersion are provided, all messages must additionally be within the specified range (0 = beginning/end)
 // If from and/or to are provided, all messages must additionally be within the specified range (nil = beginning/end)
-func (s *Postgres) ListMessages(ctx context.Context, appID int32, mType string, minVersion, maxVersion int32, from, to *time.Time) ([]*Message, error) {
-	db, err := s.db(ctx)
+// The data of the messages is not selected WithoutData.
+// Messages are ordered by generation time, type and version, types in byte order as by Go.
+func (s *Postgres) ListMessages(ctx context.Context, appID int32, mType string, minVersion, maxVersion int32, from, to *time.Time, opts ...ListOption) ([]*Message, error) {
+	db, err := s.readDB(ctx, appID)
 	if err != nil {
 		return nil, err
 	}
 
+	withData := withData(opts)
 	var messages []*Message
-	query := db.Model(&messages).
-		Column("message.*", "Template").
+	query := db.Model(&messages)
+	if withData {
+		query = query.Column("message.*")
+	} else {
+		query = query.Column(columnsWithoutData((*Message)(nil), "message")...)
+	}
+	query = query.
+		Column("Template").
 		Relation("Template").
 		Where("app_id = ?", appID)
 	if mType != "" {
@@ -88,49 +143,212 @@ func (s *Postgres) ListMessages(ctx context.Context, appID int32, mType string,
 	if maxVersion != 0 {
 		query = query.Where("version <= ?", maxVersion)
 	}
+	query = query.Order("message.generated_at").OrderExpr(`"template"."type" COLLATE "C"`).Order("template.version")
 	if err := query.Select(); err != nil {
-		return nil, err
+		return nil, classify(err)
 	}
 	if len(messages) == 0 {
 		return nil, ErrNotFound
 	}
+	if !withData {
+		return messages, nil
+	}
+	for _, msg := range messages {
+		if err := decodeMessage(msg); err != nil {
+			return nil, err
+		}
+	}
 	return messages, nil
 }
 
 // CreateMessageTemplate adds a new message template to postgres. The message template validation is expected to be performed before calling this function.
+// The template is audited in the same transaction.
 func (s *Postgres) CreateMessageTemplate(ctx context.Context, tmpl *MessageTemplate) error {
-	db, err := s.db(ctx)
-	if err != nil {
+	if err := checkAudit(ctx); err != nil {
 		return err
 	}
-	if _, err := db.Model(tmpl).Returning("*").Insert(); err != nil {
+	db, err := s.db(ctx)
+	if err != nil {
 		return err
 	}
-	return nil
+	err = db.RunInTransaction(func(tx *pg.Tx) error {
+		if _, err := tx.Model(tmpl).Returning("*").Insert(); err != nil {
+			return err
+		}
+		event := newAuditEvent(ctx, 0, AuditTemplateCreate, templateTarget(tmpl))
+		event.AfterHash = auditHash([]byte(tmpl.Template))
+		return insertAuditEvents(tx, event)
+	})
+	return classify(err)
 }
 
 // SaveState saves the provided state to postgres.
 // The message validation is expected to be performed before calling this function.
-// If a conflicting state existed, it is overridden by the new state.
+// If a conflicting state existed, it is overridden by the new state and its revision is incremented.
 func (s *Postgres) SaveState(ctx context.Context, state *AidAnalyticsState) error {
+	return s.SaveStateChunks(ctx, state, nil, 0, true)
+}
+
+// SaveStateRevision saves the provided state to postgres if the stored revision matches the expected revision.
+// An expected revision of 0 expects the state not to exist yet.
+// The message validation is expected to be performed before calling this function.
+// Returns ErrRevisionMismatch if the stored revision does not match.
+func (s *Postgres) SaveStateRevision(ctx context.Context, state *AidAnalyticsState, expectedRevision int32) error {
+	return s.SaveStateChunks(ctx, state, nil, expectedRevision, false)
+}
+
+// SaveStateChunks saves the provided state and its data chunks to postgres in a single transaction.
+// If chunks are provided, the data of the state itself is left empty. Chunks of a previously saved state are replaced.
+// Unless forced, the stored revision must match the expected revision, see SaveStateRevision.
+// Data and chunks above the compression threshold are stored compressed.
+// States with a fencing token are only saved while the lease is held, otherwise ErrLeaseLost is returned.
+// New states are inserted into the partition of the month they are saved at.
+// The save is audited in the same transaction with the hashes of the data of the state before and after the save.
+// Returns ErrUnknownRun if the state is tagged with a run that does not exist.
+func (s *Postgres) SaveStateChunks(ctx context.Context, state *AidAnalyticsState, chunks [][]byte, expectedRevision int32, force bool) error {
+	if err := checkAudit(ctx); err != nil {
+		return err
+	}
 	db, err := s.db(ctx)
 	if err != nil {
 		return err
 	}
-	query := db.Model(state).
-		OnConflict("ON CONSTRAINT aid_analytics_states_keyword_idx DO UPDATE").
-		Set("keyword = EXCLUDED.keyword, data = EXCLUDED.data").
-		Returning("*")
-	if _, err := query.Insert(); err != nil {
+	partition, err := s.partition(db, tableStates, state.SavedAt)
+	if err != nil {
+		return classify(err)
+	}
+
+	state.ChunkCount = int32(len(chunks))
+	if len(chunks) > 0 {
+		state.Data = []byte{}
+	}
+	event := newAuditEvent(ctx, state.AppID, AuditStateSave, stateTarget(state.Keyword, state.SavedAt))
+	event.AfterHash = stateHash(state)
+	data := state.Data
+	if state.Codec, state.Data, err = s.encode(tableStates, data); err != nil {
 		return err
 	}
+	rows := make([]*AidAnalyticsStateChunk, len(chunks))
+	for i, chunk := range chunks {
+		rows[i] = &AidAnalyticsStateChunk{Seq: int32(i)}
+		if rows[i].Codec, rows[i].Data, err = s.encode(tableStateChunks, chunk); err != nil {
+			return err
+		}
+	}
+	// the state is returned with its original data
+	defer func() { state.Data = data }()
+
+	err = db.RunInTransaction(func(tx *pg.Tx) error {
+		if state.FencingToken > 0 {
+			if err := checkFencingToken(tx, state.AppID, state.FencingToken); err != nil {
+				return err
+			}
+		}
+		var err error
+		if event.BeforeHash, err = storedStateHash(tx, state); err != nil {
+			return err
+		}
+		if force {
+			err = upsertState(tx, partition, state)
+		} else {
+			err = saveStateRevision(tx, partition, state, expectedRevision)
+		}
+		if isRunForeignKeyViolation(err) {
+			return ErrUnknownRun
+		} else if err != nil {
+			return err
+		}
+
+		if _, err := tx.Model((*AidAnalyticsStateChunk)(nil)).Where("state_id = ?", state.ID).Delete(); err != nil {
+			return err
+		}
+		if len(rows) > 0 {
+			for _, row := range rows {
+				row.StateID = state.ID
+			}
+			if _, err := tx.Model(&rows).Insert(); err != nil {
+				return err
+			}
+		}
+		return insertAuditEvents(tx, event)
+	})
+	if err != nil {
+		return classify(err)
+	}
+	s.writes.record(state.AppID)
 	return nil
 }
 
+// insertStateQuery inserts a state into a partition, unset columns with defaults get their defaults
+const insertStateQuery = `
+	INSERT INTO ? AS s (app_id, keyword, data, saved_at, revision, chunk_count, checksum, codec, schema_version, run_id)
+	VALUES (?app_id, ?keyword, ?data, ?saved_at, COALESCE(?revision, 1), COALESCE(?chunk_count, 0), ?checksum,
+		COALESCE(?codec, 'none'), COALESCE(?schema_version, 0), ?run_id)`
+
+func upsertState(db orm.DB, partition string, state *AidAnalyticsState) error {
+	if _, err := db.QueryOne(state, insertStateQuery+`
+		ON CONFLICT (app_id, saved_at, keyword) DO UPDATE
+		SET keyword = EXCLUDED.keyword, data = EXCLUDED.data, chunk_count = EXCLUDED.chunk_count, checksum = EXCLUDED.checksum,
+			codec = EXCLUDED.codec, schema_version = EXCLUDED.schema_version, run_id = EXCLUDED.run_id, revision = s.revision + 1
+		RETURNING *`, pg.F(partition), state); err != nil {
+		return err
+	}
+	return nil
+}
+
+func saveStateRevision(db orm.DB, partition string, state *AidAnalyticsState, expectedRevision int32) error {
+	if expectedRevision == 0 {
+		state.Revision = 0
+		if _, err := db.QueryOne(state, insertStateQuery+`
+			ON CONFLICT (app_id, saved_at, keyword) DO NOTHING
+			RETURNING *`, pg.F(partition), state); err != nil {
+			if err == postgres.ErrNoRows {
+				return ErrRevisionMismatch
+			}
+			return err
+		}
+		return nil
+	}
+
+	res, err := db.Model(state).
+		Set("data = ?data, codec = ?codec, chunk_count = ?chunk_count, checksum = ?checksum, schema_version = ?schema_version").
+		Set("run_id = ?run_id").
+		Set("revision = revision + 1").
+		Where("app_id = ?app_id AND keyword = ?keyword AND saved_at = ?saved_at").
+		Where("revision = ?", expectedRevision).
+		Returning("*").
+		Update()
+	if err != nil {
+		return err
+	}
+	if res.RowsAffected() == 0 {
+		return ErrRevisionMismatch
+	}
+	return nil
+}
+
+// GetStateChunk returns the data of a single chunk of a chunked state by state id and sequence number
+func (s *Postgres) GetStateChunk(ctx context.Context, stateID int32, seq int32) ([]byte, error) {
+	// the app of the chunk is unknown, read from the primary after writes of any app
+	db, err := s.readDB(ctx, 0)
+	if err != nil {
+		return nil, err
+	}
+
+	chunk := &AidAnalyticsStateChunk{StateID: stateID, Seq: seq}
+	if err := db.Select(chunk); err != nil {
+		if err == postgres.ErrNoRows {
+			return nil, ErrNotFound
+		}
+		return nil, classify(err)
+	}
+	return decompress(chunk.Codec, chunk.Data)
+}
+
 // GetState returns a state by app id, keyword and timestamp.
 // The message validation is expected to be performed before calling this function.
 func (s *Postgres) GetState(ctx context.Context, state *AidAnalyticsState) error {
-	db, err := s.db(ctx)
+	db, err := s.readDB(ctx, state.AppID)
 	if err != nil {
 		return err
 	}
@@ -139,22 +357,53 @@ func (s *Postgres) GetState(ctx context.Context, state *AidAnalyticsState) error
 		if err == postgres.ErrNoRows {
 			return ErrNotFound
 		}
-		return err
+		return classify(err)
 	}
-	return nil
+	return decodeState(state)
+}
+
+// GetLatestState returns the newest state by app id and keyword.
+// If asOf is provided, the newest state saved at or before asOf is returned.
+func (s *Postgres) GetLatestState(ctx context.Context, appID int32, keyword string, asOf *time.Time) (*AidAnalyticsState, error) {
+	db, err := s.readDB(ctx, appID)
+	if err != nil {
+		return nil, err
+	}
+
+	state := &AidAnalyticsState{}
+	query := db.Model(state).Where("app_id = ? AND keyword = ?", appID, keyword)
+	if asOf != nil {
+		query = query.Where("saved_at <= ?", asOf)
+	}
+	if err := query.Order("saved_at DESC").Limit(1).Select(); err != nil {
+		if err == postgres.ErrNoRows {
+			return nil, ErrNotFound
+		}
+		return nil, classify(err)
+	}
+	if err := decodeState(state); err != nil {
+		return nil, err
+	}
+	return state, nil
 }
 
 // ListStates fetches all state by app id.
 // If keyword is provided, all states must additionally match the keyword
 // If from and/or to are provided, all states must additionally be within the specified range (nil = beginning/end)
-func (s *Postgres) ListStates(ctx context.Context, appID int32, keyword string, from, to *time.Time) ([]*AidAnalyticsState, error) {
-	db, err := s.db(ctx)
+// The data of the states is not selected WithoutData.
+// States are ordered by generation time and keyword, keywords in byte order as by Go.
+func (s *Postgres) ListStates(ctx context.Context, appID int32, keyword string, from, to *time.Time, opts ...ListOption) ([]*AidAnalyticsState, error) {
+	db, err := s.readDB(ctx, appID)
 	if err != nil {
 		return nil, err
 	}
 
+	withData := withData(opts)
 	var states []*AidAnalyticsState
 	query := db.Model(&states).Where("app_id = ?", appID)
+	if !withData {
+		query = query.Column(columnsWithoutData((*AidAnalyticsState)(nil), "aid_analytics_state")...)
+	}
 	if keyword != "" {
 		query = query.Where("keyword = ?", keyword)
 	}
@@ -164,20 +413,128 @@ func (s *Postgres) ListStates(ctx context.Context, appID int32, keyword string,
 	if to != nil {
 		query = query.Where("saved_at <= ?", to)
 	}
-	if err := query.Select(); err != nil {
-		return nil, err
+	if err := query.Order("saved_at").OrderExpr(`keyword COLLATE "C"`).Select(); err != nil {
+		return nil, classify(err)
 	}
 	if len(states) == 0 {
 		return nil, ErrNotFound
 	}
+	if !withData {
+		return states, nil
+	}
+	for _, state := range states {
+		if err := decodeState(state); err != nil {
+			return nil, err
+		}
+	}
 	return states, nil
 }
 
+// DeleteStates deletes the states of an app and keyword saved within a time range, both ends inclusive.
+// Chunks of chunked states are deleted with their states. Dry runs count the states without deleting them.
+// Every deleted state is audited in the same transaction with the hash of its data.
+// Returns the number of deleted states.
+func (s *Postgres) DeleteStates(ctx context.Context, appID int32, keyword string, from, to time.Time, dryRun bool) (int, error) {
+	db, err := s.db(ctx)
+	if err != nil {
+		return 0, err
+	}
+
+	if dryRun {
+		n, err := db.Model((*AidAnalyticsState)(nil)).
+			Where("app_id = ? AND keyword = ?", appID, keyword).
+			Where("saved_at >= ? AND saved_at <= ?", from, to).
+			Count()
+		return n, classify(err)
+	}
+	if err := checkAudit(ctx); err != nil {
+		return 0, err
+	}
+	var deleted []*AidAnalyticsState
+	err = db.RunInTransaction(func(tx *pg.Tx) error {
+		if _, err := tx.Query(&deleted, `
+			DELETE FROM aid_analytics_states
+			WHERE app_id = ? AND keyword = ? AND saved_at >= ? AND saved_at <= ?
+			RETURNING *`, appID, keyword, from, to); err != nil {
+			return err
+		}
+		events := make([]*AuditEvent, len(deleted))
+		for i, state := range deleted {
+			if err := decodeState(state); err != nil {
+				return err
+			}
+			events[i] = newAuditEvent(ctx, appID, AuditStateDelete, stateTarget(state.Keyword, state.SavedAt))
+			events[i].BeforeHash = stateHash(state)
+		}
+		return insertAuditEvents(tx, events...)
+	})
+	if err != nil {
+		return 0, classify(err)
+	}
+	s.writes.record(appID)
+	return len(deleted), nil
+}
+
+// GetKeyword returns a registered keyword or ErrNotFound if the keyword is not registered
+func (s *Postgres) GetKeyword(ctx context.Context, keyword string) (*AidAnalyticsKeyword, error) {
+	db, err := s.db(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	k := &AidAnalyticsKeyword{Keyword: keyword}
+	if err := db.Select(k); err != nil {
+		if err == postgres.ErrNoRows {
+			return nil, ErrNotFound
+		}
+		return nil, classify(err)
+	}
+	return k, nil
+}
+
+// ListKeywords fetches all keywords states are saved with by app id, including unregistered keywords.
+// Keywords are ordered in byte order as by Go.
+func (s *Postgres) ListKeywords(ctx context.Context, appID int32) ([]*KeywordUsage, error) {
+	db, err := s.readDB(ctx, appID)
+	if err != nil {
+		return nil, err
+	}
+
+	var usages []*KeywordUsage
+	if _, err := db.Query(&usages, `
+		SELECT s.keyword, k.content_type, k.schema_version, k.max_size, k.owner, k.created_at,
+			k.keyword IS NOT NULL AS registered, count(*) AS state_count, max(s.saved_at) AS latest_saved_at
+		FROM aid_analytics_states s
+		LEFT JOIN aid_analytics_keywords k ON k.keyword = s.keyword
+		WHERE s.app_id = ?
+		GROUP BY s.keyword, k.keyword
+		ORDER BY s.keyword COLLATE "C"`, appID); err != nil {
+		return nil, classify(err)
+	}
+	if len(usages) == 0 {
+		return nil, ErrNotFound
+	}
+	return usages, nil
+}
+
+// columnsWithoutData returns the columns of the table of a model, qualified by the alias of the table, except the
+// data column, to select rows without their payload
+func columnsWithoutData(model interface{}, alias string) []string {
+	table := orm.GetTable(reflect.TypeOf(model).Elem())
+	columns := make([]string, 0, len(table.Fields))
+	for _, f := range table.Fields {
+		if f.SQLName != "data" {
+			columns = append(columns, alias+"."+f.SQLName)
+		}
+	}
+	return columns
+}
+
 func (s *Postgres) db(ctx context.Context) (*postgres.DB, error) {
 	db, err := s.pgClient.DB(ctx)
 	if err != nil {
 		log.FromContext(ctx).Error("failed to get db connection", log.Error(err))
-		return nil, errors.New("failed to connect to database")
+		return nil, connectionError(err)
 	}
-	return db, nil
+	return traced(ctx, db), nil
 }
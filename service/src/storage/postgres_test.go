@@ -208,7 +208,7 @@ func TestCreateMessage(t *testing.T) {
 		{
 			Description: "postgres fail template does not exist",
 			Message:     storage.Message{AppID: 123, TemplateID: -1, GeneratedAt: time.Now(), Data: []byte(`{"val1":"abc"}`)},
-			ExpErrMsg:   "insert or update on table \"messages\" violates foreign key constraint \"messages_template_id_fkey\"",
+			ExpErrMsg:   fmt.Sprintf("insert or update on table \"%s\" violates foreign key constraint \"messages_template_id_fkey\"", storage.PartitionName("messages", time.Now())),
 			Storage:     storage.NewPostgres(testPostgresClient),
 		},
 		{
@@ -496,6 +496,156 @@ func TestCreateAidAnalyticsState(t *testing.T) {
 		})
 	}
 }
+func TestSaveStateRevision(t *testing.T) {
+	assert := require.New(t)
+	s := storage.NewPostgres(testPostgresClient)
+	newState := func() *storage.AidAnalyticsState {
+		return &storage.AidAnalyticsState{AppID: 123, Keyword: "kw-rev", SavedAt: time.Unix(1000, 0), Data: []byte(`{"val1":"abc"}`)}
+	}
+	keyword := fmt.Sprintf("kw-rev-%d", rand.Int())
+
+	assert.Nil(testutil.WithDeadlineContext(time.Second, func(ctx context.Context) {
+		// first save expects the state not to exist
+		state := newState()
+		state.Keyword = keyword
+		assert.Nil(s.SaveStateRevision(ctx, state, 0))
+		assert.Equal(int32(1), state.Revision)
+
+		// saving again without a revision fails as the state exists
+		state = newState()
+		state.Keyword = keyword
+		assert.Equal(storage.ErrRevisionMismatch, s.SaveStateRevision(ctx, state, 0))
+
+		// saving with the current revision increments it
+		state = newState()
+		state.Keyword = keyword
+		state.Data = []byte(`{"val1":"abcd"}`)
+		assert.Nil(s.SaveStateRevision(ctx, state, 1))
+		assert.Equal(int32(2), state.Revision)
+		assert.Equal([]byte(`{"val1":"abcd"}`), state.Data)
+
+		// saving with a stale revision fails
+		state = newState()
+		state.Keyword = keyword
+		assert.Equal(storage.ErrRevisionMismatch, s.SaveStateRevision(ctx, state, 1))
+
+		// forced saves ignore and increment the revision
+		state = newState()
+		state.Keyword = keyword
+		assert.Nil(s.SaveState(ctx, state))
+		assert.Equal(int32(3), state.Revision)
+
+		storedState := &storage.AidAnalyticsState{ID: state.ID}
+		assert.Nil(testPostgresDB.Select(storedState))
+		assert.Equal(state, storedState)
+	}))
+}
+
+func TestSaveStateChunks(t *testing.T) {
+	assert := require.New(t)
+	s := storage.NewPostgres(testPostgresClient)
+	keyword := fmt.Sprintf("kw-chunks-%d", rand.Int())
+	savedAt := time.Unix(2000, 0)
+
+	assert.Nil(testutil.WithDeadlineContext(time.Second, func(ctx context.Context) {
+		state := &storage.AidAnalyticsState{AppID: 123, Keyword: keyword, SavedAt: savedAt, Checksum: "abc"}
+		assert.Nil(s.SaveStateChunks(ctx, state, [][]byte{[]byte("chunk0"), []byte("chunk1")}, 0, false))
+		assert.Equal(int32(1), state.Revision)
+		assert.Equal(int32(2), state.ChunkCount)
+		assert.Equal("abc", state.Checksum)
+		assert.Equal([]byte{}, state.Data)
+
+		data, err := s.GetStateChunk(ctx, state.ID, 1)
+		assert.Nil(err)
+		assert.Equal([]byte("chunk1"), data)
+		_, err = s.GetStateChunk(ctx, state.ID, 2)
+		assert.Equal(storage.ErrNotFound, err)
+
+		// stale revisions leave the chunks untouched
+		stale := &storage.AidAnalyticsState{AppID: 123, Keyword: keyword, SavedAt: savedAt, Checksum: "def"}
+		assert.Equal(storage.ErrRevisionMismatch, s.SaveStateChunks(ctx, stale, [][]byte{[]byte("other")}, 0, false))
+		data, err = s.GetStateChunk(ctx, state.ID, 0)
+		assert.Nil(err)
+		assert.Equal([]byte("chunk0"), data)
+
+		// saving without chunks removes the previous chunks
+		state = &storage.AidAnalyticsState{AppID: 123, Keyword: keyword, SavedAt: savedAt, Data: []byte(`{"val1":"abc"}`)}
+		assert.Nil(s.SaveState(ctx, state))
+		assert.Equal(int32(2), state.Revision)
+		assert.Equal(int32(0), state.ChunkCount)
+		assert.Equal("", state.Checksum)
+		_, err = s.GetStateChunk(ctx, state.ID, 0)
+		assert.Equal(storage.ErrNotFound, err)
+	}))
+}
+
+func TestDeleteStates(t *testing.T) {
+	assert := require.New(t)
+	s := storage.NewPostgres(testPostgresClient)
+	keyword := fmt.Sprintf("kw-delete-%d", rand.Int())
+
+	assert.Nil(testutil.WithDeadlineContext(time.Second, func(ctx context.Context) {
+		for i := 0; i < 3; i++ {
+			state := &storage.AidAnalyticsState{AppID: 123, Keyword: keyword, SavedAt: time.Unix(int64(1000+i), 0), Data: []byte(`{}`)}
+			assert.Nil(s.SaveState(ctx, state))
+		}
+
+		// dry runs only count
+		n, err := s.DeleteStates(ctx, 123, keyword, time.Unix(1000, 0), time.Unix(1001, 0), true)
+		assert.Nil(err)
+		assert.Equal(2, n)
+		states, err := s.ListStates(ctx, 123, keyword, nil, nil)
+		assert.Nil(err)
+		assert.Len(states, 3)
+
+		n, err = s.DeleteStates(ctx, 123, keyword, time.Unix(1001, 0), time.Unix(1001, 0), false)
+		assert.Nil(err)
+		assert.Equal(1, n)
+		n, err = s.DeleteStates(ctx, 123, keyword, time.Unix(1000, 0), time.Unix(1002, 0), false)
+		assert.Nil(err)
+		assert.Equal(2, n)
+		_, err = s.ListStates(ctx, 123, keyword, nil, nil)
+		assert.Equal(storage.ErrNotFound, err)
+	}))
+}
+
+func TestKeywords(t *testing.T) {
+	assert := require.New(t)
+	s := storage.NewPostgres(testPostgresClient)
+	appID := rand.Int31n(100000) + 100000
+	keyword := fmt.Sprintf("kw-keywords-%d", rand.Int())
+
+	assert.Nil(testutil.WithDeadlineContext(time.Second, func(ctx context.Context) {
+		// seeded by migrations
+		k, err := s.GetKeyword(ctx, "latest_dates")
+		assert.Nil(err)
+		assert.Equal("application/json", k.ContentType)
+		assert.Equal(int32(1), k.SchemaVersion)
+
+		_, err = s.GetKeyword(ctx, keyword)
+		assert.Equal(storage.ErrNotFound, err)
+
+		_, err = s.ListKeywords(ctx, appID)
+		assert.Equal(storage.ErrNotFound, err)
+
+		for i, kw := range []string{"latest_dates", keyword, keyword} {
+			state := &storage.AidAnalyticsState{AppID: appID, Keyword: kw, SavedAt: time.Unix(int64(1000+i), 0), Data: []byte(`{}`)}
+			assert.Nil(s.SaveState(ctx, state))
+		}
+		usages, err := s.ListKeywords(ctx, appID)
+		assert.Nil(err)
+		assert.Len(usages, 2)
+		assert.Equal(keyword, usages[0].Keyword)
+		assert.False(usages[0].Registered)
+		assert.Equal(int32(2), usages[0].StateCount)
+		assert.Equal(time.Unix(1002, 0).UTC(), usages[0].LatestSavedAt.UTC())
+		assert.Equal("latest_dates", usages[1].Keyword)
+		assert.True(usages[1].Registered)
+		assert.Equal("ai-decision-pipeline", usages[1].Owner)
+		assert.Equal(int32(1), usages[1].StateCount)
+	}))
+}
+
 func TestGetAnalyticsState(t *testing.T) {
 	const (
 		app1       = int32(1000)
@@ -580,6 +730,107 @@ func TestGetAnalyticsState(t *testing.T) {
 		})
 	}
 }
+func TestGetLatestAnalyticsState(t *testing.T) {
+	const (
+		app1 = int32(1100)
+		app2 = int32(1101)
+	)
+	keyword := fmt.Sprintf("kw-tgls-%d", rand.Int())
+	timeOldest := time.Now().Add(-10 * time.Minute).Truncate(time.Microsecond)
+	timeNewest := time.Now().Add(-5 * time.Minute).Truncate(time.Microsecond)
+	timeNewestApp2 := time.Now().Add(-time.Minute).Truncate(time.Microsecond)
+	payload := []byte(`{"val1":"abc"}`)
+
+	createdStates := []*storage.AidAnalyticsState{
+		{AppID: app1, Keyword: keyword, SavedAt: timeOldest, Data: payload},
+		{AppID: app1, Keyword: keyword, SavedAt: timeNewest, Data: payload},
+		{AppID: app2, Keyword: keyword, SavedAt: timeNewestApp2, Data: payload},
+	}
+	_, err := testPostgresDB.Model(&createdStates).Returning("*").Insert()
+	require.Nil(t, err)
+
+	between := timeOldest.Add(time.Minute)
+	before := timeOldest.Add(-time.Minute)
+	for _, test := range []struct {
+		Description string
+		AppID       int32
+		Keyword     string
+		AsOf        *time.Time
+		ExpState    *storage.AidAnalyticsState
+		ExpErrMsg   string
+		Storage     *storage.Postgres
+	}{
+		{
+			Description: "latest state",
+			AppID:       app1,
+			Keyword:     keyword,
+			ExpState:    createdStates[1],
+			Storage:     storage.NewPostgres(testPostgresClient),
+		},
+		{
+			Description: "state as of exact time",
+			AppID:       app1,
+			Keyword:     keyword,
+			AsOf:        &timeOldest,
+			ExpState:    createdStates[0],
+			Storage:     storage.NewPostgres(testPostgresClient),
+		},
+		{
+			Description: "state as of time in between",
+			AppID:       app1,
+			Keyword:     keyword,
+			AsOf:        &between,
+			ExpState:    createdStates[0],
+			Storage:     storage.NewPostgres(testPostgresClient),
+		},
+		{
+			Description: "no state before as of",
+			AppID:       app1,
+			Keyword:     keyword,
+			AsOf:        &before,
+			ExpErrMsg:   storage.ErrNotFound.Error(),
+			Storage:     storage.NewPostgres(testPostgresClient),
+		},
+		{
+			Description: "state with keyword does not exist",
+			AppID:       app1,
+			Keyword:     keyword + "-other",
+			ExpErrMsg:   storage.ErrNotFound.Error(),
+			Storage:     storage.NewPostgres(testPostgresClient),
+		},
+		{
+			Description: "fail if unable to connect",
+			AppID:       app1,
+			Keyword:     keyword,
+			ExpErrMsg:   "failed to connect to database",
+			Storage:     storage.NewPostgres(&badConnectionClient{}),
+		},
+		{
+			Description: "fail if query error",
+			AppID:       app1,
+			Keyword:     keyword,
+			ExpErrMsg:   "pg: database is closed",
+			Storage:     storage.NewPostgres(testPostgresClosedConnClient),
+		},
+	} {
+		t.Run(test.Description, func(t *testing.T) {
+			assert := require.New(t)
+
+			assert.Nil(testutil.WithDeadlineContext(time.Second, func(ctx context.Context) {
+				state, err := test.Storage.GetLatestState(ctx, test.AppID, test.Keyword, test.AsOf)
+				if test.ExpErrMsg != "" {
+					assert.NotNil(err)
+					assert.Contains(err.Error(), test.ExpErrMsg)
+				} else {
+					assert.Nil(err)
+					assert.Equal(test.ExpState.ID, state.ID)
+					assert.True(test.ExpState.SavedAt.Equal(state.SavedAt))
+				}
+			}))
+		})
+	}
+}
+
 func TestListAidAnalyticsStates(t *testing.T) {
 	const (
 		app1          = int32(567)
@@ -0,0 +1,98 @@
+package storage
+
+import (
+	"context"
+	"sync"
+	"time"
+
+	"github.com/callstats-io/go-common/log"
+	"github.com/callstats-io/go-common/postgres"
+)
+
+// maxTrackedWrites is the number of tracked apps from which writes older than the max staleness are pruned
+const maxTrackedWrites = 1024
+
+// WithReadReplica sends List and Get reads to the read replica of readClient, e.g. connected with the read-only role.
+// Reads of an app written to by this storage within maxStaleness go to the primary to read its own writes,
+// reads of all apps go to the primary after writes of any app. A non-positive max staleness always reads the replica.
+func (s *Postgres) WithReadReplica(readClient postgres.Client, maxStaleness time.Duration) *Postgres {
+	poolStats.add(RoleReadOnly, readClient)
+	s.readClient = readClient
+	s.maxStaleness = maxStaleness
+	s.writes.setMaxAge(maxStaleness)
+	return s
+}
+
+// readDB returns a connection for reads of an app, or of all apps if appID is 0.
+// Falls back to the primary if the app was written to within the max staleness or the replica is unavailable.
+func (s *Postgres) readDB(ctx context.Context, appID int32) (*postgres.DB, error) {
+	if s.readClient == nil {
+		return s.db(ctx)
+	}
+	if s.maxStaleness > 0 && s.writes.since(appID, time.Now().Add(-s.maxStaleness)) {
+		readCounter.WithLabelValues(RoleReadWrite, ReasonStaleness).Inc()
+		return s.db(ctx)
+	}
+	db, err := s.readClient.DB(ctx)
+	if err != nil {
+		log.FromContext(ctx).Warn("failed to get read replica connection, reading from primary", log.Error(err))
+		readCounter.WithLabelValues(RoleReadWrite, ReasonUnavailable).Inc()
+		return s.db(ctx)
+	}
+	readCounter.WithLabelValues(RoleReadOnly, ReasonReplica).Inc()
+	return traced(ctx, db), nil
+}
+
+// writeTracker tracks the time of the last write of each app within the max age
+type writeTracker struct {
+	lock    sync.Mutex
+	maxAge  time.Duration // writes are not tracked if not positive
+	apps    map[int32]time.Time
+	allApps time.Time // last write of all apps at once, e.g. compaction
+	anyApp  time.Time // last write of any app
+}
+
+func newWriteTracker() *writeTracker {
+	return &writeTracker{apps: map[int32]time.Time{}}
+}
+
+func (w *writeTracker) setMaxAge(maxAge time.Duration) {
+	w.lock.Lock()
+	defer w.lock.Unlock()
+	w.maxAge = maxAge
+}
+
+// record records a write of an app now, or of all apps if appID is 0
+func (w *writeTracker) record(appID int32) {
+	w.lock.Lock()
+	defer w.lock.Unlock()
+
+	if w.maxAge <= 0 {
+		return
+	}
+	now := time.Now()
+	w.anyApp = now
+	if appID == 0 {
+		w.allApps = now
+		return
+	}
+	if len(w.apps) >= maxTrackedWrites {
+		for id, t := range w.apps {
+			if now.Sub(t) > w.maxAge {
+				delete(w.apps, id)
+			}
+		}
+	}
+	w.apps[appID] = now
+}
+
+// since returns true if the app, or any app if appID is 0, was written to after t
+func (w *writeTracker) since(appID int32, t time.Time) bool {
+	w.lock.Lock()
+	defer w.lock.Unlock()
+
+	if appID == 0 {
+		return w.anyApp.After(t)
+	}
+	return w.allApps.After(t) || w.apps[appID].After(t)
+}
@@ -0,0 +1,76 @@
+package storage_test
+
+import (
+	"context"
+	"fmt"
+	"math/rand"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/callstats-io/ai-decision/service/src/storage"
+	"github.com/callstats-io/go-common/postgres"
+	"github.com/callstats-io/go-common/testutil"
+	"github.com/stretchr/testify/require"
+)
+
+// countingClient counts the connections got from the wrapped client
+type countingClient struct {
+	postgres.Client
+	count int32
+}
+
+func (c *countingClient) DB(ctx context.Context) (*postgres.DB, error) {
+	atomic.AddInt32(&c.count, 1)
+	return c.Client.DB(ctx)
+}
+
+func (c *countingClient) reset() int32 {
+	return atomic.SwapInt32(&c.count, 0)
+}
+
+func TestReadReplica(t *testing.T) {
+	assert := require.New(t)
+	primary := &countingClient{Client: testPostgresClient}
+	replica := &countingClient{Client: testPostgresClient}
+	s := storage.NewPostgres(primary).WithReadReplica(replica, 100*time.Millisecond)
+	keyword := fmt.Sprintf("replica-%d", rand.Int())
+
+	assert.Nil(testutil.WithDeadlineContext(5*time.Second, func(ctx context.Context) {
+		state := &storage.AidAnalyticsState{AppID: 123, Keyword: keyword, SavedAt: time.Now(), Data: []byte(`{}`)}
+		assert.Nil(s.SaveState(ctx, state))
+		assert.Equal(int32(1), primary.reset())
+
+		// the written app and all apps are read from the primary within the max staleness
+		_, err := s.GetLatestState(ctx, 123, keyword, nil)
+		assert.Nil(err)
+		_, err = s.StateFreshness(ctx, nil)
+		assert.Nil(err)
+		assert.Equal(int32(2), primary.reset())
+		assert.Equal(int32(0), replica.reset())
+
+		// other apps are read from the replica
+		_, err = s.ListStates(ctx, 124, keyword, nil, nil)
+		assert.Equal(storage.ErrNotFound, err)
+		assert.Equal(int32(0), primary.reset())
+		assert.Equal(int32(1), replica.reset())
+
+		time.Sleep(150 * time.Millisecond)
+		_, err = s.GetLatestState(ctx, 123, keyword, nil)
+		assert.Nil(err)
+		assert.Equal(int32(0), primary.reset())
+		assert.Equal(int32(1), replica.reset())
+	}))
+}
+
+func TestReadReplicaUnavailable(t *testing.T) {
+	assert := require.New(t)
+	primary := &countingClient{Client: testPostgresClient}
+	s := storage.NewPostgres(primary).WithReadReplica(&badConnectionClient{}, 0)
+
+	assert.Nil(testutil.WithDeadlineContext(5*time.Second, func(ctx context.Context) {
+		_, err := s.ListStates(ctx, 123, fmt.Sprintf("replica-%d", rand.Int()), nil, nil)
+		assert.Equal(storage.ErrNotFound, err)
+		assert.Equal(int32(1), primary.reset())
+	}))
+}
@@ -0,0 +1,130 @@
+package storage
+
+import (
+	"context"
+
+	"github.com/go-pg/pg/orm"
+)
+
+// runCountColumns are the message and state counts selected with runs
+var runCountColumns = []string{
+	"(SELECT count(*) FROM messages m WHERE m.run_id = aid_run.id) AS message_count",
+	"(SELECT count(*) FROM aid_analytics_states s WHERE s.run_id = aid_run.id) AS state_count",
+}
+
+// StartRun creates a new running run
+func (s *Postgres) StartRun(ctx context.Context, run *AidRun) error {
+	db, err := s.db(ctx)
+	if err != nil {
+		return err
+	}
+
+	run.Status = RunStatusRunning
+	if _, err := db.Model(run).Returning("*").Insert(); err != nil {
+		return classify(err)
+	}
+	s.writes.record(run.AppID)
+	return nil
+}
+
+// FinishRun sets the status, error and counters of a running run and marks it finished.
+// Returns ErrNotFound if the run does not exist and ErrRunFinished if it is already finished.
+func (s *Postgres) FinishRun(ctx context.Context, run *AidRun) error {
+	db, err := s.db(ctx)
+	if err != nil {
+		return err
+	}
+
+	res, err := db.Model(run).
+		Set("status = ?status, error = ?error, counters = ?counters, finished_at = now()").
+		Where("id = ?id AND status = ?", RunStatusRunning).
+		Returning("*").
+		Update()
+	if err != nil {
+		return classify(err)
+	}
+	if res.RowsAffected() == 0 {
+		n, err := db.Model((*AidRun)(nil)).Where("id = ?", run.ID).Count()
+		if err != nil {
+			return classify(err)
+		}
+		if n == 0 {
+			return ErrNotFound
+		}
+		return ErrRunFinished
+	}
+	s.writes.record(run.AppID)
+	return nil
+}
+
+// ListRuns fetches the runs matching the filter with their message and state counts, newest first.
+// Returns ErrNotFound if there are no matching runs.
+func (s *Postgres) ListRuns(ctx context.Context, filter *RunFilter) ([]*AidRun, error) {
+	db, err := s.readDB(ctx, filter.AppID)
+	if err != nil {
+		return nil, err
+	}
+
+	var runs []*AidRun
+	query := db.Model(&runs).Column("aid_run.*")
+	for _, c := range runCountColumns {
+		query = query.ColumnExpr(c)
+	}
+	query = filterRuns(query, filter.AppID, filter.Pipeline)
+	if filter.Status != "" {
+		query = query.Where("status = ?", filter.Status)
+	}
+	if filter.StartedFrom != nil {
+		query = query.Where("started_at >= ?", filter.StartedFrom)
+	}
+	if filter.StartedTo != nil {
+		query = query.Where("started_at <= ?", filter.StartedTo)
+	}
+	if filter.WithoutMessages {
+		query = query.
+			Where("status <> ?", RunStatusRunning).
+			Where("NOT EXISTS (SELECT 1 FROM messages m WHERE m.run_id = aid_run.id)")
+	}
+	if err := query.Order("started_at DESC", "id DESC").Select(); err != nil {
+		return nil, classify(err)
+	}
+	if len(runs) == 0 {
+		return nil, ErrNotFound
+	}
+	return runs, nil
+}
+
+// LastSuccessfulRuns fetches the newest successful run of each app, or just of appID if not 0.
+// Returns ErrNotFound if there are no successful runs.
+func (s *Postgres) LastSuccessfulRuns(ctx context.Context, appID int32, pipeline string) ([]*AidRun, error) {
+	db, err := s.readDB(ctx, appID)
+	if err != nil {
+		return nil, err
+	}
+
+	var runs []*AidRun
+	query := db.Model(&runs).ColumnExpr("DISTINCT ON (app_id) aid_run.*")
+	for _, c := range runCountColumns {
+		query = query.ColumnExpr(c)
+	}
+	query = filterRuns(query, appID, pipeline).
+		Where("status = ?", RunStatusSucceeded).
+		Order("app_id", "finished_at DESC")
+	if err := query.Select(); err != nil {
+		return nil, classify(err)
+	}
+	if len(runs) == 0 {
+		return nil, ErrNotFound
+	}
+	return runs, nil
+}
+
+func filterRuns(query *orm.Query, appID int32, pipeline string) *orm.Query {
+	if appID != 0 {
+		query = query.Where("app_id = ?", appID)
+	}
+	if pipeline != "" {
+		query = query.Where("pipeline = ?", pipeline)
+	}
+	return query
+}
@@ -0,0 +1,69 @@
+package storage_test
+
+import (
+	"context"
+	"fmt"
+	"math/rand"
+	"testing"
+	"time"
+
+	"github.com/callstats-io/ai-decision/service/src/storage"
+	"github.com/callstats-io/go-common/testutil"
+	"github.com/stretchr/testify/require"
+)
+
+func TestRuns(t *testing.T) {
+	assert := require.New(t)
+	s := storage.NewPostgres(testPostgresClient)
+	pipeline := fmt.Sprintf("pipeline-%d", rand.Int())
+	tmpl := &storage.MessageTemplate{Type: pipeline, Version: 1, Template: "{.String \"val1\"}"}
+	_, err := testPostgresDB.Model(tmpl).Returning("*").Insert()
+	assert.Nil(err)
+
+	assert.Nil(testutil.WithDeadlineContext(5*time.Second, func(ctx context.Context) {
+		failed := &storage.AidRun{AppID: 123, Pipeline: pipeline}
+		assert.Nil(s.StartRun(ctx, failed))
+		assert.True(failed.ID > 0)
+		assert.Equal(storage.RunStatusRunning, failed.Status)
+		failed.Status = storage.RunStatusFailed
+		failed.Error = "timeout"
+		assert.Nil(s.FinishRun(ctx, failed))
+		assert.False(failed.FinishedAt.IsZero())
+		assert.Equal(storage.ErrRunFinished, s.FinishRun(ctx, failed))
+		assert.Equal(storage.ErrNotFound, s.FinishRun(ctx, &storage.AidRun{ID: -1, Status: storage.RunStatusFailed}))
+
+		succeeded := &storage.AidRun{AppID: 123, Pipeline: pipeline}
+		assert.Nil(s.StartRun(ctx, succeeded))
+		msg := &storage.Message{AppID: 123, TemplateID: tmpl.ID, GeneratedAt: time.Now(), Data: []byte(`{"val1":"abc"}`), RunID: succeeded.ID}
+		assert.Nil(s.CreateMessage(ctx, msg))
+		state := &storage.AidAnalyticsState{AppID: 123, Keyword: pipeline, SavedAt: time.Now(), Data: []byte(`{}`), RunID: succeeded.ID}
+		assert.Nil(s.SaveState(ctx, state))
+		succeeded.Status = storage.RunStatusSucceeded
+		succeeded.Counters = map[string]int64{"messages": 1}
+		assert.Nil(s.FinishRun(ctx, succeeded))
+
+		// messages and states can only be tagged with existing runs
+		assert.Equal(storage.ErrUnknownRun, s.CreateMessage(ctx, &storage.Message{AppID: 123, TemplateID: tmpl.ID, GeneratedAt: time.Now(), Data: []byte(`{"val1":"abc"}`), RunID: -1}))
+
+		runs, err := s.ListRuns(ctx, &storage.RunFilter{AppID: 123, Pipeline: pipeline})
+		assert.Nil(err)
+		assert.Len(runs, 2)
+		assert.Equal(succeeded.ID, runs[0].ID)
+		assert.Equal(int32(1), runs[0].MessageCount)
+		assert.Equal(int32(1), runs[0].StateCount)
+		assert.Equal(map[string]int64{"messages": 1}, runs[0].Counters)
+
+		runs, err = s.ListRuns(ctx, &storage.RunFilter{Pipeline: pipeline, WithoutMessages: true})
+		assert.Nil(err)
+		assert.Len(runs, 1)
+		assert.Equal(failed.ID, runs[0].ID)
+
+		runs, err = s.LastSuccessfulRuns(ctx, 0, pipeline)
+		assert.Nil(err)
+		assert.Len(runs, 1)
+		assert.Equal(succeeded.ID, runs[0].ID)
+
+		_, err = s.LastSuccessfulRuns(ctx, 456, pipeline)
+		assert.Equal(storage.ErrNotFound, err)
+	}))
+}
@@ -0,0 +1,47 @@
+package storage
+
+import (
+	"context"
+	"strings"
+	"time"
+
+	"github.com/callstats-io/ai-decision/service/src/tracing"
+	"github.com/callstats-io/go-common/postgres"
+	"github.com/go-pg/pg"
+	"go.opencensus.io/trace"
+)
+
+// maxTracedStatement is the length statements are truncated to in spans, inserts carry the inserted data
+const maxTracedStatement = 1024
+
+// traced returns a copy of the db recording its queries as client spans of the span of the context if tracing is enabled
+func traced(ctx context.Context, db *postgres.DB) *postgres.DB {
+	if !tracing.Enabled() {
+		return db
+	}
+	withHook := db.DB.WithContext(ctx)
+	withHook.OnQueryProcessed(func(event *pg.QueryProcessedEvent) {
+		statement, _ := event.UnformattedQuery()
+		status := trace.Status{}
+		if event.Error != nil && event.Error != pg.ErrNoRows {
+			status = trace.Status{Code: trace.StatusCodeUnknown, Message: event.Error.Error()}
+		}
+		if len(statement) > maxTracedStatement {
+			statement = statement[:maxTracedStatement]
+		}
+		tracing.RecordSpan(ctx, queryOperation(statement), event.StartTime, time.Now(), status, map[string]interface{}{
+			"db.system":    "postgresql",
+			"db.statement": statement,
+		})
+	})
+	return &postgres.DB{DB: withHook}
+}
+
+// queryOperation returns the first keyword of a statement as the name of its span, e.g. SELECT
+func queryOperation(statement string) string {
+	fields := strings.Fields(statement)
+	if len(fields) == 0 {
+		return "postgres"
+	}
+	return strings.ToUpper(fields[0])
+}
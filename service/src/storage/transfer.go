@@ -0,0 +1,264 @@
+package storage
+
+import (
+	"context"
+	"io"
+	"sort"
+	"time"
+
+	"github.com/go-pg/pg"
+	"github.com/go-pg/pg/orm"
+)
+
+// TransferFilter defines the messages and states to export, zero values include all.
+// Message templates are always exported as messages refer to them.
+type TransferFilter struct {
+	AppIDs []int32
+	From   *time.Time // generated_at of messages and saved_at of states, inclusive
+	To     *time.Time // exclusive
+}
+
+// TransferCounts defines the number of rows exported or imported per table
+type TransferCounts struct {
+	Templates int
+	Messages  int
+	States    int
+}
+
+// copyJSONLines are the COPY options to read and write one JSON value per line as is:
+// with quote and delimiter characters JSON never contains unescaped, CSV neither quotes nor escapes the values
+const copyJSONLines = `WITH (FORMAT csv, QUOTE E'\x01', DELIMITER E'\x02')`
+
+// Export writes message templates, messages and states matching the filter to w as JSON lines of
+// {"table": ..., "row": ...}, states with their chunks as "chunks". Payloads are written as stored, with their codec.
+// Runs are not exported. All rows are read from the same snapshot.
+func (s *Postgres) Export(ctx context.Context, w io.Writer, filter *TransferFilter) (*TransferCounts, error) {
+	db, err := s.db(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	counts := &TransferCounts{}
+	err = db.RunInTransaction(func(tx *pg.Tx) error {
+		if _, err := tx.Exec("SET TRANSACTION ISOLATION LEVEL REPEATABLE READ READ ONLY"); err != nil {
+			return err
+		}
+
+		res, err := tx.CopyTo(w, `
+			COPY (SELECT json_build_object('table', 'message_templates', 'row', t) FROM message_templates t ORDER BY t.id)
+			TO STDOUT `+copyJSONLines)
+		if err != nil {
+			return err
+		}
+		counts.Templates = res.RowsAffected()
+
+		where, params := transferWhere(filter, "generated_at")
+		res, err = tx.CopyTo(w, `
+			COPY (SELECT json_build_object('table', 'messages', 'row', m) FROM messages m `+where+` ORDER BY m.id)
+			TO STDOUT `+copyJSONLines, params...)
+		if err != nil {
+			return err
+		}
+		counts.Messages = res.RowsAffected()
+
+		where, params = transferWhere(filter, "saved_at")
+		res, err = tx.CopyTo(w, `
+			COPY (SELECT json_build_object('table', 'aid_analytics_states', 'row', s, 'chunks', (
+				SELECT coalesce(json_agg(c ORDER BY c.seq), '[]') FROM aid_analytics_state_chunks c WHERE c.state_id = s.id
+			)) FROM aid_analytics_states s `+where+` ORDER BY s.id)
+			TO STDOUT `+copyJSONLines, params...)
+		if err != nil {
+			return err
+		}
+		counts.States = res.RowsAffected()
+		return nil
+	})
+	if err != nil {
+		return nil, classify(err)
+	}
+	return counts, nil
+}
+
+// transferWhere returns the condition and its parameters of the rows of the filter by the time column
+func transferWhere(filter *TransferFilter, column string) (string, []interface{}) {
+	where := "WHERE true"
+	var params []interface{}
+	if len(filter.AppIDs) > 0 {
+		where += " AND app_id IN (?)"
+		params = append(params, pg.In(filter.AppIDs))
+	}
+	if filter.From != nil {
+		where += " AND " + column + " >= ?"
+		params = append(params, filter.From)
+	}
+	if filter.To != nil {
+		where += " AND " + column + " < ?"
+		params = append(params, filter.To)
+	}
+	return where, params
+}
+
+// Import reads JSON lines written by Export from r and inserts them with COPY, mapping the app ids of messages and states
+// by appIDs. Apps not in appIDs keep their ids. Message templates are matched by type and version, existing templates are
+// kept. Messages and states get new ids, rows that already exist and messages of unknown templates are skipped.
+// All rows are imported in one transaction, audited with the number of imported templates and the number of imported
+// messages and states of every app.
+func (s *Postgres) Import(ctx context.Context, r io.Reader, appIDs map[int32]int32) (*TransferCounts, error) {
+	if err := checkAudit(ctx); err != nil {
+		return nil, err
+	}
+	db, err := s.db(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	counts := &TransferCounts{}
+	err = db.RunInTransaction(func(tx *pg.Tx) error {
+		if _, err := tx.Exec(`
+			CREATE TEMP TABLE aid_import (line JSONB) ON COMMIT DROP;
+			CREATE TEMP TABLE aid_import_apps (app_id INTEGER PRIMARY KEY, new_app_id INTEGER NOT NULL) ON COMMIT DROP`); err != nil {
+			return err
+		}
+		if _, err := tx.CopyFrom(r, "COPY aid_import FROM STDIN "+copyJSONLines); err != nil {
+			return err
+		}
+		for appID, newAppID := range appIDs {
+			if _, err := tx.Exec("INSERT INTO aid_import_apps VALUES (?, ?)", appID, newAppID); err != nil {
+				return err
+			}
+		}
+
+		res, err := tx.Exec(`
+			INSERT INTO message_templates (type, version, template, created_at)
+			SELECT t.type, t.version, t.template, t.created_at
+			FROM aid_import i, jsonb_populate_record(NULL::message_templates, i.line->'row') t
+			WHERE i.line->>'table' = 'message_templates'
+			ON CONFLICT (type, version) DO NOTHING`)
+		if err != nil {
+			return err
+		}
+		counts.Templates = res.RowsAffected()
+		var events []*AuditEvent
+		if counts.Templates > 0 {
+			event := newAuditEvent(ctx, 0, AuditTemplateImport, "")
+			event.Count = int32(counts.Templates)
+			events = append(events, event)
+		}
+		if _, err := tx.Exec(`
+			CREATE TEMP TABLE aid_import_templates ON COMMIT DROP AS
+			SELECT t.id AS id, mt.id AS new_id
+			FROM aid_import i, jsonb_populate_record(NULL::message_templates, i.line->'row') t
+			JOIN message_templates mt ON mt.type = t.type AND mt.version = t.version
+			WHERE i.line->>'table' = 'message_templates'`); err != nil {
+			return err
+		}
+
+		messages, err := importPartitioned(tx, tableMessages, "generated_at", `
+			WITH inserted AS (
+				INSERT INTO ?0 (app_id, template_id, generated_at, data, codec)
+				SELECT coalesce(a.new_app_id, m.app_id), t.new_id, m.generated_at, m.data, m.codec
+				FROM aid_import i
+				CROSS JOIN jsonb_populate_record(NULL::messages, i.line->'row') m
+				JOIN aid_import_templates t ON t.id = m.template_id
+				LEFT JOIN aid_import_apps a ON a.app_id = m.app_id
+				WHERE i.line->>'table' = 'messages' AND m.generated_at >= ?1 AND m.generated_at < ?2
+				ON CONFLICT DO NOTHING
+				RETURNING app_id
+			)
+			SELECT app_id, count(*) FROM inserted GROUP BY app_id`)
+		if err != nil {
+			return err
+		}
+		counts.Messages, events = importEvents(ctx, events, AuditMessageImport, messages)
+
+		// chunks are inserted for the inserted states, found by the unique key of the state
+		states, err := importPartitioned(tx, tableStates, "saved_at", `
+			WITH rows AS (
+				SELECT s.*, coalesce(a.new_app_id, s.app_id) AS new_app_id, i.line->'chunks' AS chunks
+				FROM aid_import i
+				CROSS JOIN jsonb_populate_record(NULL::aid_analytics_states, i.line->'row') s
+				LEFT JOIN aid_import_apps a ON a.app_id = s.app_id
+				WHERE i.line->>'table' = 'aid_analytics_states' AND s.saved_at >= ?1 AND s.saved_at < ?2
+			), inserted AS (
+				INSERT INTO ?0 (app_id, keyword, data, saved_at, revision, chunk_count, checksum, codec, schema_version)
+				SELECT new_app_id, keyword, data, saved_at, revision, chunk_count, checksum, codec, schema_version FROM rows
+				ON CONFLICT DO NOTHING
+				RETURNING id, app_id, keyword, saved_at
+			), chunks AS (
+				INSERT INTO aid_analytics_state_chunks (state_id, seq, data, codec)
+				SELECT ins.id, c.seq, c.data, c.codec
+				FROM inserted ins
+				JOIN rows r ON r.new_app_id = ins.app_id AND r.keyword = ins.keyword AND r.saved_at = ins.saved_at
+				CROSS JOIN jsonb_populate_recordset(NULL::aid_analytics_state_chunks, coalesce(r.chunks, '[]')) c
+			)
+			SELECT app_id, count(*) FROM inserted GROUP BY app_id`)
+		if err != nil {
+			return err
+		}
+		counts.States, events = importEvents(ctx, events, AuditStateImport, states)
+		return insertAuditEvents(tx, events...)
+	})
+	if err != nil {
+		return nil, classify(err)
+	}
+	if counts.Messages > 0 || counts.States > 0 {
+		s.writes.record(0)
+	}
+	return counts, nil
+}
+
+// importCount is the number of rows of an app inserted by an import
+type importCount struct {
+	AppID int32
+	Count int32
+}
+
+// importPartitioned inserts the imported rows of a partitioned table month by month into the partition of the month
+// with the insert query, which is formatted with the partition and the start and end of the month and returns the
+// number of inserted rows per app. Returns the number of rows per app inserted in all months.
+func importPartitioned(tx orm.DB, table, column, insertQuery string) (map[int32]int32, error) {
+	var months pg.Strings
+	if _, err := tx.Query(&months, `
+		SELECT DISTINCT to_char((line->'row'->>?0)::timestamptz AT TIME ZONE 'UTC', 'YYYY-MM-01')
+		FROM aid_import WHERE line->>'table' = ?1`, column, table); err != nil {
+		return nil, err
+	}
+
+	total := map[int32]int32{}
+	for _, m := range months {
+		month, err := time.Parse("2006-01-02", m)
+		if err != nil {
+			return total, err
+		}
+		var partition string
+		if _, err := tx.QueryOne(pg.Scan(&partition), "SELECT aid_create_partition(?, ?)", table, m); err != nil {
+			return total, err
+		}
+		var inserted []*importCount
+		if _, err := tx.Query(&inserted, insertQuery, pg.F(partition), month, month.AddDate(0, 1, 0)); err != nil {
+			return total, err
+		}
+		for _, c := range inserted {
+			total[c.AppID] += c.Count
+		}
+	}
+	return total, nil
+}
+
+// importEvents appends the audit events of the rows of apps imported by the action to events in order of the apps.
+// Returns the number of rows imported of all apps and the events.
+func importEvents(ctx context.Context, events []*AuditEvent, action string, imported map[int32]int32) (int, []*AuditEvent) {
+	appIDs := make([]int32, 0, len(imported))
+	for appID := range imported {
+		appIDs = append(appIDs, appID)
+	}
+	sort.Slice(appIDs, func(i, j int) bool { return appIDs[i] < appIDs[j] })
+	total := 0
+	for _, appID := range appIDs {
+		event := newAuditEvent(ctx, appID, action, "")
+		event.Count = imported[appID]
+		events = append(events, event)
+		total += int(imported[appID])
+	}
+	return total, events
+}
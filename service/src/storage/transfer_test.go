@@ -0,0 +1,88 @@
+package storage_test
+
+import (
+	"bytes"
+	"context"
+	"fmt"
+	"math/rand"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/callstats-io/ai-decision/service/src/storage"
+	"github.com/callstats-io/go-common/testutil"
+	"github.com/stretchr/testify/require"
+)
+
+func TestExportImport(t *testing.T) {
+	assert := require.New(t)
+	s := storage.NewPostgres(testPostgresClient).WithCompressionThreshold(0)
+	mType := fmt.Sprintf("transfer-%d", rand.Int())
+	appID := rand.Int31()
+	newAppID := rand.Int31()
+	tmpl := &storage.MessageTemplate{Type: mType, Version: 1, Template: "{.String \"val1\"}"}
+	_, err := testPostgresDB.Model(tmpl).Returning("*").Insert()
+	assert.Nil(err)
+	from := time.Date(2016, 1, 1, 0, 0, 0, 0, time.UTC)
+	to := time.Date(2016, 3, 1, 0, 0, 0, 0, time.UTC)
+
+	assert.Nil(testutil.WithDeadlineContext(5*time.Second, func(ctx context.Context) {
+		for _, generatedAt := range []time.Time{from, from.AddDate(0, 1, 0), to} {
+			assert.Nil(s.CreateMessage(ctx, &storage.Message{AppID: appID, TemplateID: tmpl.ID, GeneratedAt: generatedAt, Data: []byte(`{"val1":"abc"}`)}))
+		}
+		// not exported
+		assert.Nil(s.CreateMessage(ctx, &storage.Message{AppID: appID + 1, TemplateID: tmpl.ID, GeneratedAt: from, Data: []byte(`{"val1":"abc"}`)}))
+		chunked := &storage.AidAnalyticsState{AppID: appID, Keyword: mType, SavedAt: from}
+		assert.Nil(s.SaveStateChunks(ctx, chunked, [][]byte{[]byte(`{"a":`), []byte(`"\n"}`)}, 0, true))
+
+		var buf bytes.Buffer
+		counts, err := s.Export(ctx, &buf, &storage.TransferFilter{AppIDs: []int32{appID}, From: &from, To: &to})
+		assert.Nil(err)
+		assert.Equal(2, counts.Messages)
+		assert.Equal(1, counts.States)
+		assert.True(counts.Templates > 0)
+		assert.Equal(counts.Templates+counts.Messages+counts.States, strings.Count(buf.String(), "\n"))
+		exported := buf.String()
+
+		importCtx := storage.WithAudit(ctx, storage.Audit{Actor: "ops", RPC: "cli:import", Reason: mType})
+		counts, err = s.Import(importCtx, strings.NewReader(exported), map[int32]int32{appID: newAppID})
+		assert.Nil(err)
+		assert.Equal(0, counts.Templates) // templates exist already
+		assert.Equal(2, counts.Messages)
+		assert.Equal(1, counts.States)
+		events, err := s.ListAuditEvents(ctx, &storage.AuditFilter{AppID: newAppID})
+		assert.Nil(err)
+		assert.Len(events, 2)
+		for _, event := range events {
+			assert.Equal("ops", event.Actor)
+			assert.Equal(mType, event.Reason)
+		}
+		counted := map[string]int32{events[0].Action: events[0].Count, events[1].Action: events[1].Count}
+		assert.Equal(map[string]int32{storage.AuditMessageImport: 2, storage.AuditStateImport: 1}, counted)
+
+		messages, err := s.ListMessages(ctx, newAppID, mType, 0, 0, nil, nil)
+		assert.Nil(err)
+		assert.Len(messages, 2)
+		assert.Equal(tmpl.ID, messages[0].TemplateID)
+		assert.Equal([]byte(`{"val1":"abc"}`), messages[0].Data)
+		states, err := s.ListStates(ctx, newAppID, mType, nil, nil)
+		assert.Nil(err)
+		assert.Len(states, 1)
+		assert.Equal(int32(2), states[0].ChunkCount)
+		chunk, err := s.GetStateChunk(ctx, states[0].ID, 1)
+		assert.Nil(err)
+		assert.Equal([]byte(`"\n"}`), chunk)
+
+		// existing rows are skipped
+		counts, err = s.Import(importCtx, strings.NewReader(exported), map[int32]int32{appID: newAppID})
+		assert.Nil(err)
+		assert.Equal(0, counts.Messages)
+		assert.Equal(0, counts.States)
+		events, err = s.ListAuditEvents(ctx, &storage.AuditFilter{AppID: newAppID})
+		assert.Nil(err)
+		assert.Len(events, 2)
+
+		_, err = s.Import(storage.WithAudit(ctx, storage.Audit{RPC: "cli:import"}), strings.NewReader(exported), nil)
+		assert.Equal(storage.ErrNoAuditActor, err)
+	}))
+}
@@ -0,0 +1,62 @@
+package tracing
+
+import (
+	"encoding/json"
+	"io"
+	"sync"
+	"time"
+
+	"contrib.go.opencensus.io/exporter/ocagent"
+	"go.opencensus.io/trace"
+)
+
+// StdoutExporter writes each span as a line of JSON, e.g. to verify tracing locally
+type StdoutExporter struct {
+	lock    sync.Mutex
+	encoder *json.Encoder
+}
+
+// NewStdoutExporter returns an exporter writing spans to w
+func NewStdoutExporter(w io.Writer) *StdoutExporter {
+	return &StdoutExporter{encoder: json.NewEncoder(w)}
+}
+
+type stdoutSpan struct {
+	TraceID       string                 `json:"traceId"`
+	SpanID        string                 `json:"spanId"`
+	ParentSpanID  string                 `json:"parentSpanId,omitempty"`
+	Name          string                 `json:"name"`
+	Kind          int                    `json:"kind"`
+	Start         time.Time              `json:"start"`
+	End           time.Time              `json:"end"`
+	Attributes    map[string]interface{} `json:"attributes,omitempty"`
+	StatusCode    int32                  `json:"statusCode,omitempty"`
+	StatusMessage string                 `json:"statusMessage,omitempty"`
+}
+
+// ExportSpan writes the span
+func (e *StdoutExporter) ExportSpan(sd *trace.SpanData) {
+	span := &stdoutSpan{
+		TraceID:       sd.TraceID.String(),
+		SpanID:        sd.SpanID.String(),
+		Name:          sd.Name,
+		Kind:          sd.SpanKind,
+		Start:         sd.StartTime,
+		End:           sd.EndTime,
+		Attributes:    sd.Attributes,
+		StatusCode:    sd.Code,
+		StatusMessage: sd.Message,
+	}
+	if sd.ParentSpanID != (trace.SpanID{}) {
+		span.ParentSpanID = sd.ParentSpanID.String()
+	}
+	e.lock.Lock()
+	defer e.lock.Unlock()
+	e.encoder.Encode(span)
+}
+
+// NewAgentExporter returns an exporter sending spans in batches to the OpenCensus agent at the address, e.g. the
+// opencensus receiver of an OpenTelemetry Collector. It connects in the background and drops spans until connected.
+func NewAgentExporter(address, serviceName string) (*ocagent.Exporter, error) {
+	return ocagent.NewExporter(ocagent.WithInsecure(), ocagent.WithAddress(address), ocagent.WithServiceName(serviceName))
+}
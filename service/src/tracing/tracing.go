@@ -0,0 +1,110 @@
+// Package tracing traces calls through the gRPC services, Postgres and the notifiers with OpenCensus. The
+// OpenTelemetry Go SDK, its OTLP exporter and its OpenCensus bridge need a newer Go and gRPC than the service is built
+// with, so spans are recorded with its predecessor instead and the service does not export OTLP itself: the trace
+// context of incoming calls is continued from the W3C traceparent of the OpenTelemetry clients, and spans are
+// exported to stdout or to an OpenCensus agent, e.g. an OpenTelemetry Collector converting them to OTLP.
+package tracing
+
+import (
+	"context"
+	"crypto/rand"
+	"net/http"
+	"sync"
+	"time"
+
+	"go.opencensus.io/plugin/ochttp"
+	"go.opencensus.io/plugin/ochttp/propagation/tracecontext"
+	"go.opencensus.io/trace"
+	"google.golang.org/grpc/metadata"
+)
+
+// W3C trace context headers, also the gRPC metadata keys of the trace context of a call
+const (
+	TraceparentHeader = "traceparent"
+	TracestateHeader  = "tracestate"
+)
+
+var (
+	lock     sync.RWMutex
+	exporter trace.Exporter
+)
+
+// Start registers the exporter of spans and samples all traces, except those the caller did not sample
+func Start(e trace.Exporter) {
+	lock.Lock()
+	defer lock.Unlock()
+	exporter = e
+	trace.RegisterExporter(e)
+	trace.ApplyConfig(trace.Config{DefaultSampler: sampler})
+}
+
+// Stop unregisters the exporter of spans and flushes its buffered spans, if any
+func Stop() {
+	lock.Lock()
+	defer lock.Unlock()
+	if exporter == nil {
+		return
+	}
+	trace.UnregisterExporter(exporter)
+	if flusher, ok := exporter.(interface{ Flush() }); ok {
+		flusher.Flush()
+	}
+	exporter = nil
+}
+
+func currentExporter() trace.Exporter {
+	lock.RLock()
+	defer lock.RUnlock()
+	return exporter
+}
+
+// Enabled returns true if an exporter is registered
+func Enabled() bool {
+	return currentExporter() != nil
+}
+
+// sampler samples new traces and follows the sampling decision of remote parents
+func sampler(p trace.SamplingParameters) trace.SamplingDecision {
+	return trace.SamplingDecision{Sample: !p.HasRemoteParent || p.ParentContext.IsSampled()}
+}
+
+// FromMetadata returns the span context of the caller from the traceparent and tracestate metadata of a gRPC call
+func FromMetadata(md metadata.MD) (trace.SpanContext, bool) {
+	header := http.Header{}
+	for _, key := range []string{TraceparentHeader, TracestateHeader} {
+		for _, value := range md[key] {
+			header.Add(key, value)
+		}
+	}
+	format := &tracecontext.HTTPFormat{}
+	return format.SpanContextFromRequest(&http.Request{Header: header})
+}
+
+// NewTransport returns an HTTP transport tracing requests as client spans of the span of their context, the trace
+// context is sent to the server as a traceparent header
+func NewTransport() http.RoundTripper {
+	return &ochttp.Transport{Propagation: &tracecontext.HTTPFormat{}}
+}
+
+// RecordSpan records a client span of an operation that has already ended as a child of the span of the context,
+// e.g. a query reported by a hook after it was processed. OpenCensus starts spans at the current time, so the span is
+// passed to the exporter directly. Nothing is recorded if the span of the context is not sampled.
+func RecordSpan(ctx context.Context, name string, start, end time.Time, status trace.Status, attributes map[string]interface{}) {
+	e := currentExporter()
+	parent := trace.FromContext(ctx)
+	if e == nil || parent == nil || !parent.SpanContext().IsSampled() {
+		return
+	}
+	sd := &trace.SpanData{
+		SpanContext:  parent.SpanContext(),
+		ParentSpanID: parent.SpanContext().SpanID,
+		SpanKind:     trace.SpanKindClient,
+		Name:         name,
+		StartTime:    start,
+		EndTime:      end,
+		Attributes:   attributes,
+		Status:       status,
+	}
+	rand.Read(sd.SpanID[:])
+	e.ExportSpan(sd)
+}
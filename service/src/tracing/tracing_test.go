@@ -0,0 +1,178 @@
+package tracing_test
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/callstats-io/ai-decision/service/src/tracing"
+	"github.com/stretchr/testify/require"
+	"go.opencensus.io/trace"
+	"google.golang.org/grpc/metadata"
+)
+
+// memoryExporter collects exported spans
+type memoryExporter struct {
+	lock  sync.Mutex
+	spans []*trace.SpanData
+}
+
+func (e *memoryExporter) ExportSpan(sd *trace.SpanData) {
+	e.lock.Lock()
+	defer e.lock.Unlock()
+	e.spans = append(e.spans, sd)
+}
+
+// withExporter runs fn with tracing to a memory exporter and returns the exported spans
+func withExporter(fn func()) []*trace.SpanData {
+	exporter := &memoryExporter{}
+	tracing.Start(exporter)
+	defer tracing.Stop()
+	fn()
+	return exporter.spans
+}
+
+func TestFromMetadata(t *testing.T) {
+	assert := require.New(t)
+
+	sc, ok := tracing.FromMetadata(metadata.Pairs(tracing.TraceparentHeader, "00-4bf92f3577b34da6a3ce929d0e0e4736-00f067aa0ba902b7-01"))
+	assert.True(ok)
+	assert.True(sc.IsSampled())
+	assert.Equal("4bf92f3577b34da6a3ce929d0e0e4736", sc.TraceID.String())
+	assert.Equal("00f067aa0ba902b7", sc.SpanID.String())
+
+	sc, ok = tracing.FromMetadata(metadata.Pairs(tracing.TraceparentHeader, "00-4bf92f3577b34da6a3ce929d0e0e4736-00f067aa0ba902b7-00"))
+	assert.True(ok)
+	assert.False(sc.IsSampled())
+
+	for _, invalid := range []string{
+		"",
+		"00-4bf92f3577b34da6a3ce929d0e0e4736-00f067aa0ba902b7",
+		"00-4bf92f3577b34da6a3ce929d0e0e47-00f067aa0ba902b7-01",
+		"00-xyz92f3577b34da6a3ce929d0e0e4736-00f067aa0ba902b7-01",
+	} {
+		_, ok := tracing.FromMetadata(metadata.Pairs(tracing.TraceparentHeader, invalid))
+		assert.False(ok, invalid)
+	}
+	_, ok = tracing.FromMetadata(nil)
+	assert.False(ok)
+}
+
+func TestSpans(t *testing.T) {
+	assert := require.New(t)
+	remote, _ := tracing.FromMetadata(metadata.Pairs(tracing.TraceparentHeader, "00-4bf92f3577b34da6a3ce929d0e0e4736-00f067aa0ba902b7-01"))
+
+	start := time.Unix(1, 0)
+	spans := withExporter(func() {
+		assert.True(tracing.Enabled())
+		ctx, server := trace.StartSpanWithRemoteParent(context.Background(), "server", remote, trace.WithSpanKind(trace.SpanKindServer))
+		tracing.RecordSpan(ctx, "SELECT", start, start.Add(time.Millisecond), trace.Status{Code: trace.StatusCodeUnknown, Message: "failed"},
+			map[string]interface{}{"db.system": "postgresql"})
+		server.End()
+	})
+	assert.False(tracing.Enabled())
+	assert.Len(spans, 2)
+	query, server := spans[0], spans[1]
+	assert.Equal("server", server.Name)
+	assert.Equal(remote.TraceID, server.TraceID)
+	assert.Equal(remote.SpanID, server.ParentSpanID)
+	assert.Equal("SELECT", query.Name)
+	assert.Equal(trace.SpanKindClient, query.SpanKind)
+	assert.Equal(remote.TraceID, query.TraceID)
+	assert.Equal(server.SpanID, query.ParentSpanID)
+	assert.NotEqual(server.SpanID, query.SpanID)
+	assert.Equal(start, query.StartTime)
+	assert.Equal(start.Add(time.Millisecond), query.EndTime)
+	assert.Equal("failed", query.Status.Message)
+	assert.Equal(map[string]interface{}{"db.system": "postgresql"}, query.Attributes)
+
+	// new traces are sampled, unsampled traces of callers are not continued
+	unsampled, _ := tracing.FromMetadata(metadata.Pairs(tracing.TraceparentHeader, "00-4bf92f3577b34da6a3ce929d0e0e4736-00f067aa0ba902b7-00"))
+	spans = withExporter(func() {
+		ctx, span := trace.StartSpanWithRemoteParent(context.Background(), "unsampled", unsampled)
+		tracing.RecordSpan(ctx, "SELECT", start, start, trace.Status{}, nil)
+		span.End()
+		_, span = trace.StartSpan(context.Background(), "root")
+		span.End()
+	})
+	assert.Len(spans, 1)
+	assert.Equal("root", spans[0].Name)
+	assert.Equal(trace.SpanID{}, spans[0].ParentSpanID)
+	assert.NotEqual(remote.TraceID, spans[0].TraceID)
+
+	// queries without a span are not recorded
+	spans = withExporter(func() {
+		tracing.RecordSpan(context.Background(), "SELECT", start, start, trace.Status{}, nil)
+	})
+	assert.Empty(spans)
+}
+
+func TestTransport(t *testing.T) {
+	assert := require.New(t)
+	var traceparent string
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		traceparent = r.Header.Get(tracing.TraceparentHeader)
+	}))
+	defer server.Close()
+
+	var parent *trace.Span
+	spans := withExporter(func() {
+		var ctx context.Context
+		ctx, parent = trace.StartSpan(context.Background(), "parent")
+		request, err := http.NewRequest("POST", server.URL, nil)
+		assert.Nil(err)
+		resp, err := (&http.Client{Transport: tracing.NewTransport()}).Do(request.WithContext(ctx))
+		assert.Nil(err)
+		resp.Body.Close()
+		parent.End()
+	})
+	assert.Len(spans, 2)
+	client := spans[0]
+	assert.Equal(trace.SpanKindClient, client.SpanKind)
+	assert.Equal(parent.SpanContext().SpanID, client.ParentSpanID)
+	assert.Equal(int64(http.StatusOK), client.Attributes["http.status_code"])
+	sc, ok := tracing.FromMetadata(metadata.Pairs(tracing.TraceparentHeader, traceparent))
+	assert.True(ok)
+	assert.Equal(client.SpanContext.SpanID, sc.SpanID)
+}
+
+func TestStdoutExporter(t *testing.T) {
+	assert := require.New(t)
+	remote, _ := tracing.FromMetadata(metadata.Pairs(tracing.TraceparentHeader, "00-4bf92f3577b34da6a3ce929d0e0e4736-00f067aa0ba902b7-01"))
+	out := &bytes.Buffer{}
+	exporter := tracing.NewStdoutExporter(out)
+	start := time.Unix(1, 0).UTC()
+	exporter.ExportSpan(&trace.SpanData{
+		SpanContext:  remote,
+		ParentSpanID: trace.SpanID{1},
+		Name:         "SELECT",
+		SpanKind:     trace.SpanKindClient,
+		StartTime:    start,
+		EndTime:      start.Add(time.Second),
+		Attributes:   map[string]interface{}{"db.system": "postgresql"},
+		Status:       trace.Status{Code: trace.StatusCodeUnknown, Message: "failed"},
+	})
+	exporter.ExportSpan(&trace.SpanData{SpanContext: remote, Name: "server"})
+
+	decoder := json.NewDecoder(out)
+	var span map[string]interface{}
+	assert.Nil(decoder.Decode(&span))
+	assert.Equal("4bf92f3577b34da6a3ce929d0e0e4736", span["traceId"])
+	assert.Equal("00f067aa0ba902b7", span["spanId"])
+	assert.Equal("0100000000000000", span["parentSpanId"])
+	assert.Equal(float64(trace.SpanKindClient), span["kind"])
+	assert.Equal("1970-01-01T00:00:01Z", span["start"])
+	assert.Equal(map[string]interface{}{"db.system": "postgresql"}, span["attributes"])
+	assert.Equal(float64(trace.StatusCodeUnknown), span["statusCode"])
+	assert.Equal("failed", span["statusMessage"])
+	span = nil
+	assert.Nil(decoder.Decode(&span))
+	assert.Equal("server", span["name"])
+	assert.NotContains(span, "parentSpanId")
+	assert.NotContains(span, "statusCode")
+}
@@ -8,6 +8,8 @@ from src.Grpc.conversions import \
     dictToGrpcdata, \
     grpcdataToDict
 from datetime import datetime, timezone
+from google.protobuf.field_mask_pb2 import FieldMask
+import hashlib
 from src.Grpc.ConnectionClient import ConnectionClient, DataServiceError
 import logging
 
@@ -22,20 +24,32 @@ logger = logging.getLogger('root')
 
 DEFAULT_DT = datetime(1971, 1, 1, tzinfo=timezone.utc)
 DEFAULT_APPID = 1
+STATE_CHUNK_SIZE = 1 << 20
 
 
 class AidServiceClient(ConnectionClient):
     """
     A gRPC client. Handles the protocols.
     """
-    def __init__(self, address):
+    def __init__(self, address, authToken=None):
+        """
+        input:
+            address: String "<ip addr or name>:<port>" of the service
+            authToken: String, JWT authorizing the calls, see
+                AID_SERVICE_AUTH_TOKEN. None only works if the service
+                does not authorize calls
+        """
         super(AidServiceClient, self).__init__(
             address,
             connection_timeout=2,
-            max_retries=0)
+            max_retries=0,
+            authToken=authToken)
+        # messages and states are tagged with the run started by StartRun
+        self.runID = None
 
     # AIDecisionStateServiceStub
-    def SaveState(self, keyword, state, dt=None, appID=None):
+    def SaveState(self, keyword, state, dt=None, appID=None,
+                  expectedRevision=None, fencingToken=None):
         """
         Save arbitrary state, no checking is performed.
         input:
@@ -44,6 +58,11 @@ class AidServiceClient(ConnectionClient):
                 Method caller responsible for usage of later retrieved state
             dt: Datetime, None if unused
             appID: int, None if unused
+            expectedRevision: int, revision of the state to overwrite,
+                0 if the state should not exist yet.
+                None overwrites any existing state (last write wins)
+            fencingToken: int, token of the lease held for the app,
+                see AcquireLease. None saves without a lease
         returns:
             Exception, None if no error
         """
@@ -57,6 +76,10 @@ class AidServiceClient(ConnectionClient):
                 keyword=keyword,
                 data=dictToGrpcdata(state),
                 generation_time=datetimeToGrpctimestamp(dt),
+                expected_revision=expectedRevision or 0,
+                force=expectedRevision is None,
+                fencing_token=fencingToken or 0,
+                run_id=self.runID or 0,
             )
         except (TypeError) as e:
             err = DataServiceError('SaveStateRequest', e)
@@ -108,6 +131,591 @@ class AidServiceClient(ConnectionClient):
             return None
         return grpcdataToDict(res.data)
 
+    def GetLatestState(self, keyword, appID=None):
+        """
+        Get the newest state saved with SaveState, regardless of its time.
+        input:
+            keyword: String, make sure its unique
+            appID: int, None if unused
+        returns:
+            Dict (defined by caller itself in SaveState), None if error
+        """
+        if appID is None:
+            appID = DEFAULT_APPID
+        try:
+            request = ai_decision_service_pb2.StateGetLatestRequest(
+                app_id=appID,
+                keyword=keyword,
+            )
+        except (TypeError) as e:
+            err = DataServiceError('StateGetLatestRequest', e)
+            logger.error(err)
+            return None, err
+
+        service = self.getService(
+            ai_decision_service_pb2_grpc.AIDecisionStateServiceStub)
+        res, e = self.send(
+            service.GetLatest,
+            request,
+            'GetLatestState',
+            reliable=True)
+        if e is not None or not res:
+            return None
+        return grpcdataToDict(res.data)
+
+    def GetStateAsOf(self, keyword, dt, appID=None):
+        """
+        Get the newest state saved with SaveState at or before a time.
+        input:
+            keyword: String, make sure its unique
+            dt: Datetime
+            appID: int, None if unused
+        returns:
+            Dict (defined by caller itself in SaveState), None if error
+        """
+        if appID is None:
+            appID = DEFAULT_APPID
+        try:
+            request = ai_decision_service_pb2.StateGetAsOfRequest(
+                app_id=appID,
+                keyword=keyword,
+                as_of=datetimeToGrpctimestamp(dt),
+            )
+        except (TypeError) as e:
+            err = DataServiceError('StateGetAsOfRequest', e)
+            logger.error(err)
+            return None, err
+
+        service = self.getService(
+            ai_decision_service_pb2_grpc.AIDecisionStateServiceStub)
+        res, e = self.send(
+            service.GetAsOf,
+            request,
+            'GetStateAsOf',
+            reliable=True)
+        if e is not None or not res:
+            return None
+        return grpcdataToDict(res.data)
+
+    def SaveStateChunked(self, keyword, data, dt=None, appID=None,
+                         expectedRevision=None, chunkSize=STATE_CHUNK_SIZE,
+                         fencingToken=None):
+        """
+        Save a large binary state, e.g. a fitted model, in chunks.
+        input:
+            keyword: String, make sure its unique
+            data: bytes, the state to save
+            dt: Datetime, None if unused
+            appID: int, None if unused
+            expectedRevision: int, see SaveState
+            chunkSize: int, size of the uploaded chunks in bytes
+            fencingToken: int, see SaveState
+        returns:
+            Exception, None if no error
+        """
+        if dt is None:
+            dt = DEFAULT_DT
+        if appID is None:
+            appID = DEFAULT_APPID
+        try:
+            chunks = [ai_decision_service_pb2.StateSaveChunk(
+                state=ai_decision_service_pb2.StateSaveRequest(
+                    app_id=appID,
+                    keyword=keyword,
+                    generation_time=datetimeToGrpctimestamp(dt),
+                    expected_revision=expectedRevision or 0,
+                    force=expectedRevision is None,
+                    fencing_token=fencingToken or 0,
+                    run_id=self.runID or 0,
+                ))]
+            for i in range(0, len(data), chunkSize):
+                chunks.append(ai_decision_service_pb2.StateSaveChunk(
+                    data=data[i:i + chunkSize]))
+            chunks[-1].checksum = hashlib.sha256(data).hexdigest()
+        except (TypeError) as e:
+            err = DataServiceError('StateSaveChunk', e)
+            logger.error(err)
+            return err
+
+        service = self.getService(
+            ai_decision_service_pb2_grpc.AIDecisionStateServiceStub)
+        res, e = self.send(
+            service.SaveChunked,
+            chunks,
+            'SaveStateChunked',
+            reliable=True)
+        return e
+
+    def GetStateChunked(self, keyword, dt=None, appID=None):
+        """
+        Get a binary state saved with SaveStateChunked (or SaveState).
+        The data is verified against the checksum sent by the service.
+        input:
+            keyword: String, make sure its unique
+            dt: Datetime, None if unused
+            appID: int, None if unused
+        returns:
+            bytes, None if error
+        """
+        if dt is None:
+            dt = DEFAULT_DT
+        if appID is None:
+            appID = DEFAULT_APPID
+        try:
+            request = ai_decision_service_pb2.StateGetRequest(
+                app_id=appID,
+                keyword=keyword,
+                generation_time=datetimeToGrpctimestamp(dt),
+            )
+        except (TypeError) as e:
+            err = DataServiceError('StateGetRequest', e)
+            logger.error(err)
+            return None
+
+        service = self.getService(
+            ai_decision_service_pb2_grpc.AIDecisionStateServiceStub)
+        res, e = self.send(
+            service.GetChunked,
+            request,
+            'GetStateChunked',
+            reliable=True)
+        if e is not None or not res:
+            return None
+
+        # grpc errors are only raised when the generator is accessed
+        try:
+            data = bytearray()
+            checksum = ''
+            for chunk in res:
+                data.extend(chunk.data)
+                if chunk.checksum:
+                    checksum = chunk.checksum
+        except Exception as e:
+            logger.error(e)
+            return None
+        if checksum != hashlib.sha256(data).hexdigest():
+            logger.error('GetStateChunked: checksum mismatch')
+            return None
+        return bytes(data)
+
+    def DeleteState(self, keyword, dt=None, appID=None, dryRun=False):
+        """
+        Delete a state, e.g. a corrupted model.
+        input:
+            keyword: String
+            dt: Datetime, None if unused
+            appID: int, None if unused
+            dryRun: bool, only check the state exists without deleting it
+        returns:
+            int, number of deleted states, None if error
+        """
+        if dt is None:
+            dt = DEFAULT_DT
+        if appID is None:
+            appID = DEFAULT_APPID
+        try:
+            request = ai_decision_service_pb2.StateDeleteRequest(
+                app_id=appID,
+                keyword=keyword,
+                generation_time=datetimeToGrpctimestamp(dt),
+                dry_run=dryRun,
+            )
+        except (TypeError) as e:
+            err = DataServiceError('StateDeleteRequest', e)
+            logger.error(err)
+            return None
+
+        service = self.getService(
+            ai_decision_service_pb2_grpc.AIDecisionStateServiceStub)
+        res, e = self.send(
+            service.Delete,
+            request,
+            'DeleteState',
+            reliable=True)
+        if e is not None or not res:
+            return None
+        return res.deleted
+
+    def DeleteStateRange(self, keyword, start, end, appID=None,
+                         dryRun=False):
+        """
+        Delete the states of a keyword generated within a time frame.
+        input:
+            keyword: String
+            start: Datetime, the start of the time frame, inclusive
+            end: Datetime, the end of the time frame, inclusive
+            appID: int, None if unused
+            dryRun: bool, only count the states without deleting them
+        returns:
+            int, number of deleted states, None if error
+        """
+        if appID is None:
+            appID = DEFAULT_APPID
+        try:
+            request = ai_decision_service_pb2.StateDeleteRangeRequest(
+                app_id=appID,
+                keyword=keyword,
+                generation_time_from=datetimeToGrpctimestamp(start),
+                generation_time_to=datetimeToGrpctimestamp(end),
+                dry_run=dryRun,
+            )
+        except (TypeError) as e:
+            err = DataServiceError('StateDeleteRangeRequest', e)
+            logger.error(err)
+            return None
+
+        service = self.getService(
+            ai_decision_service_pb2_grpc.AIDecisionStateServiceStub)
+        res, e = self.send(
+            service.DeleteRange,
+            request,
+            'DeleteStateRange',
+            reliable=True)
+        if e is not None or not res:
+            return None
+        return res.deleted
+
+    def ListKeywords(self, appID=None):
+        """
+        List the keywords states are saved with for an app.
+        input:
+            appID: int, None if unused
+        returns:
+            list of dict, None if error
+            contains dict:
+                'keyword': String
+                'contentType': String, empty if not registered
+                'schemaVersion': int, 0 if not registered
+                'maxSize': int, maximum state size in bytes, 0 if unlimited
+                'owner': String, empty if not registered
+                'registered': bool, whether the keyword is registered
+                'stateCount': int, number of states saved with the keyword
+                'dt': Datetime object, generation time of the newest state
+        """
+        if appID is None:
+            appID = DEFAULT_APPID
+        try:
+            request = ai_decision_service_pb2.KeywordListRequest(app_id=appID)
+        except (TypeError) as e:
+            err = DataServiceError('KeywordListRequest', e)
+            logger.error(err)
+            return None
+
+        service = self.getService(
+            ai_decision_service_pb2_grpc.AIDecisionStateServiceStub)
+        res, e = self.send(
+            service.ListKeywords,
+            request,
+            'ListKeywords',
+            reliable=True)
+        if e is not None or not res:
+            return None
+
+        # grpc errors are only raised when the generator is accessed
+        try:
+            return [{
+                'keyword': k.keyword,
+                'contentType': k.content_type,
+                'schemaVersion': k.schema_version,
+                'maxSize': k.max_size,
+                'owner': k.owner,
+                'registered': k.registered,
+                'stateCount': k.state_count,
+                'dt': grpctimestampToDatetime(k.latest_generation_time),
+            } for k in res]
+        except Exception as e:
+            logger.error(e)
+            return None
+
+    # AIDecisionLeaseServiceStub
+    def AcquireLease(self, name, holder, ttl, appID=None):
+        """
+        Acquire a named lease for the app, e.g. to serialize pipeline runs.
+        input:
+            name: String, name of the lease
+            holder: String, identifies the caller
+            ttl: int, seconds until the lease expires unless renewed
+            appID: int, None if unused
+        returns:
+            int, fencing token to pass to RenewLease, ReleaseLease and
+                SaveState, None if error or held by another holder
+        """
+        if appID is None:
+            appID = DEFAULT_APPID
+        try:
+            request = ai_decision_service_pb2.LeaseAcquireRequest(
+                app_id=appID,
+                name=name,
+                holder=holder,
+                ttl_seconds=ttl,
+            )
+        except (TypeError) as e:
+            err = DataServiceError('LeaseAcquireRequest', e)
+            logger.error(err)
+            return None
+
+        service = self.getService(
+            ai_decision_service_pb2_grpc.AIDecisionLeaseServiceStub)
+        res, e = self.send(
+            service.Acquire,
+            request,
+            'AcquireLease',
+            reliable=True)
+        if e is not None or not res:
+            return None
+        return res.fencing_token
+
+    def RenewLease(self, name, holder, fencingToken, ttl, appID=None):
+        """
+        Extend a lease acquired with AcquireLease.
+        input:
+            name: String, name of the lease
+            holder: String, identifies the caller
+            fencingToken: int, as returned by AcquireLease
+            ttl: int, seconds until the lease expires unless renewed
+            appID: int, None if unused
+        returns:
+            Exception, None if no error
+        """
+        if appID is None:
+            appID = DEFAULT_APPID
+        try:
+            request = ai_decision_service_pb2.LeaseRenewRequest(
+                app_id=appID,
+                name=name,
+                holder=holder,
+                fencing_token=fencingToken,
+                ttl_seconds=ttl,
+            )
+        except (TypeError) as e:
+            err = DataServiceError('LeaseRenewRequest', e)
+            logger.error(err)
+            return err
+
+        service = self.getService(
+            ai_decision_service_pb2_grpc.AIDecisionLeaseServiceStub)
+        res, e = self.send(
+            service.Renew,
+            request,
+            'RenewLease',
+            reliable=True)
+        return e
+
+    def ReleaseLease(self, name, holder, fencingToken, appID=None):
+        """
+        Release a lease acquired with AcquireLease.
+        input:
+            name: String, name of the lease
+            holder: String, identifies the caller
+            fencingToken: int, as returned by AcquireLease
+            appID: int, None if unused
+        returns:
+            Exception, None if no error
+        """
+        if appID is None:
+            appID = DEFAULT_APPID
+        try:
+            request = ai_decision_service_pb2.LeaseReleaseRequest(
+                app_id=appID,
+                name=name,
+                holder=holder,
+                fencing_token=fencingToken,
+            )
+        except (TypeError) as e:
+            err = DataServiceError('LeaseReleaseRequest', e)
+            logger.error(err)
+            return err
+
+        service = self.getService(
+            ai_decision_service_pb2_grpc.AIDecisionLeaseServiceStub)
+        res, e = self.send(
+            service.Release,
+            request,
+            'ReleaseLease',
+            reliable=True)
+        return e
+
+    # AIDecisionRunServiceStub
+    def StartRun(self, pipeline, appID=None):
+        """
+        Start a pipeline run. Messages created and states saved until
+        FinishRun are tagged with the run.
+        input:
+            pipeline: String, name of the pipeline
+            appID: int, None if unused
+        returns:
+            int, id of the run, None if error
+        """
+        if appID is None:
+            appID = DEFAULT_APPID
+        try:
+            request = ai_decision_service_pb2.RunStartRequest(
+                app_id=appID,
+                pipeline=pipeline,
+            )
+        except (TypeError) as e:
+            err = DataServiceError('RunStartRequest', e)
+            logger.error(err)
+            return None
+
+        service = self.getService(
+            ai_decision_service_pb2_grpc.AIDecisionRunServiceStub)
+        res, e = self.send(
+            service.Start,
+            request,
+            'StartRun',
+            reliable=True)
+        if e is not None or not res:
+            return None
+        self.runID = res.id
+        return res.id
+
+    def FinishRun(self, error=None, counters=None):
+        """
+        Finish the run started with StartRun.
+        input:
+            error: String, None if the run succeeded
+            counters: Dict of String to int, e.g. number of processed apps
+        returns:
+            Exception, None if no error
+        """
+        try:
+            status = ai_decision_service_pb2.RUN_SUCCEEDED
+            if error is not None:
+                status = ai_decision_service_pb2.RUN_FAILED
+            request = ai_decision_service_pb2.RunFinishRequest(
+                id=self.runID or 0,
+                status=status,
+                error=error or '',
+                counters=counters or {},
+            )
+        except (TypeError, ValueError) as e:
+            err = DataServiceError('RunFinishRequest', e)
+            logger.error(err)
+            return err
+
+        service = self.getService(
+            ai_decision_service_pb2_grpc.AIDecisionRunServiceStub)
+        res, e = self.send(
+            service.Finish,
+            request,
+            'FinishRun',
+            reliable=True)
+        if e is None:
+            self.runID = None
+        return e
+
+    def ListRuns(self, appID=None, pipeline='', start=None, end=None,
+                 withoutMessages=False):
+        """
+        List pipeline runs, newest first.
+        input:
+            appID: int, None lists the runs of all apps
+            pipeline: String, empty for all pipelines
+            start: Datetime, the start of the time frame to query, can be None
+            end: Datetime, the end of the time frame to query, can be None
+            withoutMessages: bool, only list finished runs that created
+                no messages
+        returns:
+            list of dict, None if error
+            contains dict: see _runToDict
+        """
+        try:
+            request = ai_decision_service_pb2.RunListRequest(
+                app_id=appID or 0,
+                pipeline=pipeline,
+                started_from=datetimeToGrpctimestamp(start),
+                started_to=datetimeToGrpctimestamp(end),
+                without_messages=withoutMessages,
+            )
+        except (TypeError) as e:
+            err = DataServiceError('RunListRequest', e)
+            logger.error(err)
+            return None
+
+        service = self.getService(
+            ai_decision_service_pb2_grpc.AIDecisionRunServiceStub)
+        res, e = self.send(
+            service.List,
+            request,
+            'ListRuns',
+            reliable=True)
+        if e is not None or not res:
+            return None
+
+        # grpc errors are only raised when the generator is accessed
+        try:
+            return [self._runToDict(r) for r in res]
+        except Exception as e:
+            logger.error(e)
+            return None
+
+    def LastSuccessfulRuns(self, appID=None, pipeline=''):
+        """
+        List the newest successful run of each app.
+        input:
+            appID: int, None lists the runs of all apps
+            pipeline: String, empty for all pipelines
+        returns:
+            list of dict, None if error
+            contains dict: see _runToDict
+        """
+        try:
+            request = ai_decision_service_pb2.RunLastSuccessfulRequest(
+                app_id=appID or 0,
+                pipeline=pipeline,
+            )
+        except (TypeError) as e:
+            err = DataServiceError('RunLastSuccessfulRequest', e)
+            logger.error(err)
+            return None
+
+        service = self.getService(
+            ai_decision_service_pb2_grpc.AIDecisionRunServiceStub)
+        res, e = self.send(
+            service.LastSuccessful,
+            request,
+            'LastSuccessfulRuns',
+            reliable=True)
+        if e is not None or not res:
+            return None
+
+        # grpc errors are only raised when the generator is accessed
+        try:
+            return [self._runToDict(r) for r in res]
+        except Exception as e:
+            logger.error(e)
+            return None
+
+    def _runToDict(self, run):
+        """
+        returns:
+            dict:
+                'id': int
+                'appID': int
+                'pipeline': String
+                'status': String, RUN_RUNNING, RUN_SUCCEEDED or RUN_FAILED
+                'error': String, empty if none
+                'counters': Dict of String to int
+                'start': Datetime object
+                'end': Datetime object, None if still running
+                'messageCount': int, number of messages created in the run
+                'stateCount': int, number of states saved in the run
+        """
+        return {
+            'id': run.id,
+            'appID': run.app_id,
+            'pipeline': run.pipeline,
+            'status': ai_decision_service_pb2.RunStatus.Name(run.status),
+            'error': run.error,
+            'counters': dict(run.counters),
+            'start': grpctimestampToDatetime(run.started_at),
+            'end': grpctimestampToDatetime(run.finished_at)
+            if run.HasField('finished_at') else None,
+            'messageCount': run.message_count,
+            'stateCount': run.state_count,
+        }
+
     # AIDecisionMessageServiceStub
     def _CreateMessage(self, dt, appID, type, version, data):
         """
@@ -129,6 +737,7 @@ class AidServiceClient(ConnectionClient):
                 version=version,
                 data=dictToGrpcdata(data),
                 generation_time=datetimeToGrpctimestamp(dt),
+                run_id=self.runID or 0,
             )
         except (TypeError) as e:
             info = 'MessageCreateRequest ({} v{})'.format(type, version)
@@ -150,7 +759,7 @@ class AidServiceClient(ConnectionClient):
     def ListMessages(self, appID,
                      type="",
                      minVersion=0, maxVersion=0,
-                     start=None, end=None):
+                     start=None, end=None, fields=None):
         """
         Get a stream of messages.
         input:
@@ -160,6 +769,10 @@ class AidServiceClient(ConnectionClient):
             maxVersion: int, maximum version of messages
             start: Datetime, the start of the time frame to query, can be None
             end: Datetime, the end of the time frame to query, can be None
+            fields: list of String, the fields of the messages to return,
+                e.g. ['type', 'generation_time'], None returns all fields.
+                The data is not read if neither 'data' nor 'message' are
+                listed
         returns:
             generator, if error occured it is handled only after this generator
                 is accessed
@@ -168,7 +781,8 @@ class AidServiceClient(ConnectionClient):
                 'appID', int
                 'type', String, type of message template used
                 'version', int, version of the message template
-                'data': dict, data for the message, entries message specific
+                'data': dict, data for the message, entries message specific,
+                    None if not in fields
                 'dt': Datetime object, time of generation of message
         """
         try:
@@ -178,7 +792,8 @@ class AidServiceClient(ConnectionClient):
                 min_version=minVersion,
                 max_version=maxVersion,
                 generation_time_from=datetimeToGrpctimestamp(start),
-                generation_time_to=datetimeToGrpctimestamp(end)
+                generation_time_to=datetimeToGrpctimestamp(end),
+                read_mask=FieldMask(paths=fields or []),
             )
         except (TypeError) as e:
             err = DataServiceError('MessageListRequest', e)
@@ -205,8 +820,10 @@ class AidServiceClient(ConnectionClient):
                     'appID': rawEntry.app_id,
                     'type': rawEntry.type,
                     'version': rawEntry.version,
-                    'data': grpcdataToDict(rawEntry.data),
+                    'data': grpcdataToDict(rawEntry.data)
+                    if rawEntry.data else None,
                     'dt': grpctimestampToDatetime(rawEntry.generation_time),
+                    'runID': rawEntry.run_id,
                 }
                 yield entry
         except Exception as e:
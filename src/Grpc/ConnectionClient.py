@@ -1,12 +1,18 @@
 import grpc
 import time
 import logging
+from src.Grpc.conversions import grpcerrorToDetails
 
 logger = logging.getLogger('root')
 
 
 class DataServiceError(BaseException):
     def __init__(self, endpoint_name, grpc_error, additional_info=""):
+        # field violations, ErrorInfo and RetryInfo of the error,
+        # see grpcerrorToDetails
+        self.details = grpcerrorToDetails(grpc_error)
+        if self.details:
+            additional_info = '{} {}'.format(additional_info, self.details)
         super(DataServiceError,
               self).__init__('data_service.{}: {} ({}) {}'.format(
                   endpoint_name, grpc_error.details(),
@@ -18,18 +24,24 @@ class ConnectionClient(object):
     A gRPC client. It handles the connection level, i.e.
     establishment and reliability.
     """
-    def __init__(self, address, connection_timeout, max_retries):
+    def __init__(self, address, connection_timeout, max_retries,
+                 authToken=None):
         """
         input:
             address: String "<ip addr or name>:<port>" of the server
             connection_timeout: int, seconds, timeout of connection to server
             max_retries: int, how often to retry if fails,
                 0 means retrying indefinitely
+            authToken: String, JWT sent as the authorization metadata of
+                each call, None sends calls without a token
         """
         self._address = address
         self._connection_timeout = connection_timeout
         self._connection = None
         self._max_retries = max_retries
+        self._metadata = None
+        if authToken:
+            self._metadata = (('authorization', 'Bearer ' + authToken),)
 
     @property
     def connection(self):
@@ -85,6 +97,8 @@ class ConnectionClient(object):
         while True:
             retries += 1
             try:
+                if self._metadata:
+                    return method(request, metadata=self._metadata), None
                 return method(request), None
             except (grpc.RpcError) as e:
                 if reliable and hasattr(e, 'code'):
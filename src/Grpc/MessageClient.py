@@ -9,8 +9,10 @@ class MessageClient(StateClient):
                      'unsuppress': {},
                      'date': {}
                  },
-                 load_state=False):
-        super(MessageClient, self).__init__(address, flags, load_state)
+                 load_state=False,
+                 authToken=None):
+        super(MessageClient, self).__init__(address, flags, load_state,
+                                            authToken)
         self._initialize_message_dict()
 
     def _initialize_message_dict(self):
@@ -27,8 +27,8 @@ class StateClient(AidServiceClient):
         return datetime.datetime.strptime(
             date, '%d-%m-%Y').replace(tzinfo=datetime.timezone.utc)
 
-    def __init__(self, address, flags, load_state):
-        super(StateClient, self).__init__(address)
+    def __init__(self, address, flags, load_state, authToken=None):
+        super(StateClient, self).__init__(address, authToken)
         self._default_date = DEFAULT_DT
         self._set_flags(flags)
         self._set_latest_dates(load_state)
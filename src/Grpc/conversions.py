@@ -1,4 +1,5 @@
 from google.protobuf.timestamp_pb2 import Timestamp
+from google.rpc import error_details_pb2, status_pb2
 import json
 from datetime import timezone, datetime
 import logging
@@ -6,6 +7,8 @@ import logging
 logger = logging.getLogger('root')
 
 DATETIME_FORMAT = '%Y-%m-%dT%H:%M:%S.%f %z'
+# trailing metadata key of the google.rpc.Status of an error with details
+GRPC_STATUS_DETAILS_KEY = 'grpc-status-details-bin'
 
 
 # Conversion functions
@@ -80,3 +83,42 @@ def grpcdataToDict(grpcdata):
     datastr = grpcdata.decode('utf-8')
     dic = json.loads(datastr, object_hook=json_deserial)
     return dic
+
+
+def grpcerrorToDetails(grpc_error):
+    """
+    Converts the details of a gRPC error into a Dictionary with the keys:
+        field_violations: list of (field, description) of an invalid request
+        reason, domain, metadata: ErrorInfo of the error, e.g. reason
+            TEMPLATE_RENDER_FAILED with the type, version and field of
+            the template in metadata
+        retry_delay: float, seconds to wait before retrying the request
+    Keys are only set if the error carries the detail.
+    """
+    details = {}
+    if not hasattr(grpc_error, 'trailing_metadata'):
+        return details
+    for (key, value) in grpc_error.trailing_metadata() or ():
+        if key != GRPC_STATUS_DETAILS_KEY:
+            continue
+        status = status_pb2.Status()
+        status.ParseFromString(value)
+        for detail in status.details:
+            if detail.Is(error_details_pb2.BadRequest.DESCRIPTOR):
+                badRequest = error_details_pb2.BadRequest()
+                detail.Unpack(badRequest)
+                details['field_violations'] = [
+                    (v.field, v.description)
+                    for v in badRequest.field_violations]
+            elif detail.Is(error_details_pb2.ErrorInfo.DESCRIPTOR):
+                errorInfo = error_details_pb2.ErrorInfo()
+                detail.Unpack(errorInfo)
+                details['reason'] = errorInfo.reason
+                details['domain'] = errorInfo.domain
+                details['metadata'] = dict(errorInfo.metadata)
+            elif detail.Is(error_details_pb2.RetryInfo.DESCRIPTOR):
+                retryInfo = error_details_pb2.RetryInfo()
+                detail.Unpack(retryInfo)
+                details['retry_delay'] = \
+                    retryInfo.retry_delay.ToTimedelta().total_seconds()
+    return details
@@ -23,6 +23,8 @@ class EnvConfig:
         self._parse_app_ids()
         self._port_prometheus = int(os.getenv('PORT_PROMETHEUS'))
         self._aid_service_grpc_address = os.getenv('AID_SERVICE_GRPC_ADDR')
+        # JWT authorizing the calls of the pipeline to the service
+        self._aid_service_auth_token = os.getenv('AID_SERVICE_AUTH_TOKEN')
         self._crs_grpc_address = os.getenv('CRS_GRPC_ADDR')
         self._supported_terms = ['midterm', 'shortterm']
         self._supported_metric_names = [
@@ -87,6 +89,10 @@ class EnvConfig:
     def aid_service_grpc_address(self):
         return self._aid_service_grpc_address
 
+    @property
+    def aid_service_auth_token(self):
+        return self._aid_service_auth_token
+
     @property
     def crs_grpc_address(self):
         return self._crs_grpc_address
@@ -11,7 +11,10 @@ from src.Grpc.conversions import \
     datetimeToGrpctimestamp, \
     grpctimestampToDatetime, \
     dictToGrpcdata, \
-    grpcdataToDict
+    grpcdataToDict, \
+    grpcerrorToDetails, \
+    GRPC_STATUS_DETAILS_KEY
+from google.rpc import error_details_pb2, status_pb2
 
 from testfixtures import LogCapture
 
@@ -143,6 +146,76 @@ def test_grpc_state():
         client.GetState(dt=TEST_DT, keyword='test')
     assert 'No logging captured' in str(logs)
 
+    with LogCapture() as logs:
+        client.SaveState(
+            keyword='test', state={'state': 'this'}, expectedRevision=1)
+    assert 'No logging captured' in str(logs)
+
+    with LogCapture() as logs:
+        client.GetLatestState(keyword='test')
+    assert 'No logging captured' in str(logs)
+    with LogCapture() as logs:
+        client.GetStateAsOf(dt=TEST_DT, keyword='test')
+    assert 'No logging captured' in str(logs)
+
+    with LogCapture() as logs:
+        client.SaveStateChunked(keyword='test', data=b'0123456789',
+                                chunkSize=3)
+    assert 'No logging captured' in str(logs)
+    with LogCapture() as logs:
+        client.GetStateChunked(keyword='test')
+    assert 'No logging captured' in str(logs)
+    with LogCapture() as logs:
+        client.ListKeywords()
+    assert 'No logging captured' in str(logs)
+
+    with LogCapture() as logs:
+        client.DeleteState(dt=TEST_DT, keyword='test', dryRun=True)
+    assert 'No logging captured' in str(logs)
+    with LogCapture() as logs:
+        client.DeleteStateRange(keyword='test', start=TEST_DT, end=TEST_DT,
+                                dryRun=True)
+    assert 'No logging captured' in str(logs)
+
+
+def test_grpc_lease():
+    """ tests if the lease requests are accepted by gRPC protocols """
+    client = prepare_message_client()
+
+    with LogCapture() as logs:
+        client.AcquireLease(name='pipeline', holder='worker-1', ttl=60)
+    assert 'No logging captured' in str(logs)
+    with LogCapture() as logs:
+        client.RenewLease(name='pipeline', holder='worker-1', fencingToken=1,
+                          ttl=60)
+    assert 'No logging captured' in str(logs)
+    with LogCapture() as logs:
+        client.ReleaseLease(name='pipeline', holder='worker-1',
+                            fencingToken=1)
+    assert 'No logging captured' in str(logs)
+    with LogCapture() as logs:
+        client.SaveState(keyword='test', state={'state': 'this'},
+                         fencingToken=1)
+    assert 'No logging captured' in str(logs)
+
+
+def test_grpc_run():
+    """ tests if the run requests are accepted by gRPC protocols """
+    client = prepare_message_client()
+
+    with LogCapture() as logs:
+        client.StartRun(pipeline='daily-insights')
+    assert 'No logging captured' in str(logs)
+    with LogCapture() as logs:
+        client.FinishRun(counters={'apps': 1})
+    assert 'No logging captured' in str(logs)
+    with LogCapture() as logs:
+        client.ListRuns(withoutMessages=True)
+    assert 'No logging captured' in str(logs)
+    with LogCapture() as logs:
+        client.LastSuccessfulRuns(pipeline='daily-insights')
+    assert 'No logging captured' in str(logs)
+
 
 def test_grpc_create_message():
     """ tests if the messages are accepted by gRPC protocols """
@@ -484,6 +557,39 @@ def test_grpc_conversions():
     assert dic == grpcdataToDict(grpcdata)
 
 
+class MockRpcError(object):
+    def __init__(self, *details):
+        status = status_pb2.Status(code=3, message='invalid argument')
+        for detail in details:
+            status.details.add().Pack(detail)
+        self._metadata = ((GRPC_STATUS_DETAILS_KEY,
+                           status.SerializeToString()),)
+
+    def trailing_metadata(self):
+        return self._metadata
+
+
+def test_grpc_error_details():
+    badRequest = error_details_pb2.BadRequest()
+    badRequest.field_violations.add(
+        field='app_id', description='must be a positive integer')
+    badRequest.field_violations.add(
+        field='keyword', description='cannot be empty')
+    errorInfo = error_details_pb2.ErrorInfo(
+        reason='TEMPLATE_RENDER_FAILED', domain='ai-decision.callstats.io',
+        metadata={'type': 'growth', 'version': '3', 'field': 'users'})
+    details = grpcerrorToDetails(MockRpcError(badRequest, errorInfo))
+    assert details == {
+        'field_violations': [('app_id', 'must be a positive integer'),
+                             ('keyword', 'cannot be empty')],
+        'reason': 'TEMPLATE_RENDER_FAILED',
+        'domain': 'ai-decision.callstats.io',
+        'metadata': {'type': 'growth', 'version': '3', 'field': 'users'},
+    }
+    assert grpcerrorToDetails(MockRpcError()) == {}
+    assert grpcerrorToDetails(TypeError()) == {}
+
+
 def test_grpc_unreliable():
     client = MessageClient('notexistent:5432')
     client._connection_timeout = 0
@@ -518,6 +624,24 @@ def test_grpc_reliable():
     assert '(UNAVAILABLE) too many retries' in str(logs)
 
 
+def test_grpc_auth_token():
+    calls = []
+
+    def method(request, **kwargs):
+        calls.append(kwargs)
+        return 'response'
+
+    client = MessageClient('notexistent:5432', authToken='token')
+    assert ('response', None) == client.send(method, None, 'SaveState')
+    assert [{'metadata': (('authorization', 'Bearer token'),)}] == calls
+
+    # calls without a token carry no metadata
+    calls.clear()
+    client = MessageClient('notexistent:5432')
+    assert ('response', None) == client.send(method, None, 'SaveState')
+    assert [{}] == calls
+
+
 def test_grpc_manual_date():
     client = prepare_message_client()
     assert client._latest_dates[str(TEST_APPID)] == {}, client._latest_dates